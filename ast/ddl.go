@@ -15,6 +15,7 @@ package ast
 
 import (
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -398,6 +399,7 @@ type CreateTableStmt struct {
 	Cols        []*ColumnDef
 	Constraints []*Constraint
 	Options     []*TableOption
+	Partition   *PartitionOptions
 }
 
 // Accept implements Node Accept interface.
@@ -443,6 +445,7 @@ type DropTableStmt struct {
 
 	IfExists bool
 	Tables   []*TableName
+	IsView   bool
 }
 
 // Accept implements Node Accept interface.
@@ -462,6 +465,43 @@ func (n *DropTableStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CreateViewStmt is a statement to create a view.
+// See https://dev.mysql.com/doc/refman/5.7/en/create-view.html
+// ALTER VIEW is parsed as a CreateViewStmt with IsAlter set.
+// See https://dev.mysql.com/doc/refman/5.7/en/alter-view.html
+type CreateViewStmt struct {
+	ddlNode
+
+	OrReplace bool
+	IsAlter   bool
+	ViewName  *TableName
+	Cols      []model.CIStr
+	Select    ResultSetNode
+	// Definer is nil if the definer is the current user.
+	Definer  *auth.UserIdentity
+	Security string
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateViewStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateViewStmt)
+	node, ok := n.ViewName.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.ViewName = node.(*TableName)
+	node, ok = n.Select.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Select = node.(ResultSetNode)
+	return v.Leave(n)
+}
+
 // RenameTableStmt is a statement to rename a table.
 // See http://dev.mysql.com/doc/refman/5.7/en/rename-table.html
 type RenameTableStmt struct {
@@ -637,6 +677,26 @@ type TableOption struct {
 	UintValue uint64
 }
 
+// PartitionDefinition defines a single partition.
+type PartitionDefinition struct {
+	Name model.CIStr
+	// LessThan is the upper bound of a range partition, it is empty if MaxValue is true.
+	LessThan []ExprNode
+	MaxValue bool
+}
+
+// PartitionOptions specifies the partition options.
+type PartitionOptions struct {
+	Tp model.PartitionType
+	// Expr is the partitioning expression for RANGE and HASH partitioning.
+	Expr ExprNode
+	// ColumnNames are the columns for KEY partitioning.
+	ColumnNames []*ColumnName
+	// Num is the number of partitions given by the PARTITIONS clause, 0 if not specified.
+	Num         uint64
+	Definitions []*PartitionDefinition
+}
+
 // ColumnPositionType is the type for ColumnPosition.
 type ColumnPositionType int
 
@@ -690,6 +750,9 @@ const (
 	AlterTableRenameTable
 	AlterTableAlterColumn
 	AlterTableLock
+	AlterTableAddPartitions
+	AlterTableDropPartition
+	AlterTableTruncatePartition
 
 // TODO: Add more actions
 )
@@ -719,6 +782,8 @@ type AlterTableSpec struct {
 	OldColumnName *ColumnName
 	Position      *ColumnPosition
 	LockType      LockType
+	// PartDefinitions are the partitions to add for AlterTableAddPartitions.
+	PartDefinitions []*PartitionDefinition
 }
 
 // Accept implements Node Accept interface.
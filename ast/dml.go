@@ -30,6 +30,7 @@ var (
 
 	_ Node = &Assignment{}
 	_ Node = &ByItem{}
+	_ Node = &CommonTableExpression{}
 	_ Node = &FieldList{}
 	_ Node = &GroupByClause{}
 	_ Node = &HavingClause{}
@@ -43,6 +44,7 @@ var (
 	_ Node = &TableSource{}
 	_ Node = &UnionSelectList{}
 	_ Node = &WildCardField{}
+	_ Node = &WithClause{}
 )
 
 // JoinType is join type, including cross/left/right/full.
@@ -117,6 +119,9 @@ type TableName struct {
 	TableInfo *model.TableInfo
 
 	IndexHints []*IndexHint
+
+	// CTE is the common table expression this name refers to, it is set by the name resolver.
+	CTE *CommonTableExpression
 }
 
 // IndexHintType is the type for index hint use, ignore or force.
@@ -241,6 +246,60 @@ func (n *TableSource) GetResultFields() []*ResultField {
 	return n.Source.GetResultFields()
 }
 
+// CommonTableExpression represents a named subquery defined in a WITH clause.
+type CommonTableExpression struct {
+	node
+
+	// Name is the name used to reference the common table expression.
+	Name model.CIStr
+	// ColNameList is the optional column name list.
+	ColNameList []model.CIStr
+	// Query is a SelectStmt or an UnionStmt.
+	Query ResultSetNode
+	// IsRecursive is set by the name resolver if Query references the common table expression itself.
+	IsRecursive bool
+}
+
+// Accept implements Node Accept interface.
+func (n *CommonTableExpression) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CommonTableExpression)
+	node, ok := n.Query.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Query = node.(ResultSetNode)
+	return v.Leave(n)
+}
+
+// WithClause represents the WITH clause of a select or union statement.
+type WithClause struct {
+	node
+
+	IsRecursive bool
+	CTEs        []*CommonTableExpression
+}
+
+// Accept implements Node Accept interface.
+func (n *WithClause) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WithClause)
+	for i, cte := range n.CTEs {
+		node, ok := cte.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.CTEs[i] = node.(*CommonTableExpression)
+	}
+	return v.Leave(n)
+}
+
 // SelectLockType is the lock type for SelectStmt.
 type SelectLockType int
 
@@ -392,6 +451,8 @@ func (n *ByItem) Accept(v Visitor) (Node, bool) {
 type GroupByClause struct {
 	node
 	Items []*ByItem
+	// Rollup indicates the WITH ROLLUP modifier, which adds super-aggregate rows for every prefix of Items.
+	Rollup bool
 }
 
 // Accept implements Node Accept interface.
@@ -462,6 +523,8 @@ type SelectStmt struct {
 	dmlNode
 	resultSetNode
 
+	// With is the optional WITH clause.
+	With *WithClause
 	// SelectStmtOpts wraps around select hints and switches.
 	*SelectStmtOpts
 	// Distinct represents whether the select has distinct option.
@@ -484,6 +547,8 @@ type SelectStmt struct {
 	LockTp SelectLockType
 	// TableHints represents the level Optimizer Hint
 	TableHints []*TableOptimizerHint
+	// SelectIntoOpt is the INTO clause which writes the result set to a file.
+	SelectIntoOpt *SelectIntoOption
 }
 
 // Accept implements Node Accept interface.
@@ -494,6 +559,14 @@ func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 	}
 
 	n = newNode.(*SelectStmt)
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
+
 	if n.TableHints != nil && len(n.TableHints) != 0 {
 		newHints := make([]*TableOptimizerHint, len(n.TableHints))
 		for i, hint := range n.TableHints {
@@ -595,6 +668,7 @@ type UnionStmt struct {
 	dmlNode
 	resultSetNode
 
+	With       *WithClause
 	Distinct   bool
 	SelectList *UnionSelectList
 	OrderBy    *OrderByClause
@@ -608,6 +682,13 @@ func (n *UnionStmt) Accept(v Visitor) (Node, bool) {
 		return v.Leave(newNode)
 	}
 	n = newNode.(*UnionStmt)
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
 	if n.SelectList != nil {
 		node, ok := n.SelectList.Accept(v)
 		if !ok {
@@ -698,14 +779,31 @@ func (n *LoadDataStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
-// FieldsClause represents fields references clause in load data statement.
+// SelectIntoType is the type of SELECT ... INTO target.
+type SelectIntoType int
+
+// SelectInto types.
+const (
+	SelectIntoOutfile SelectIntoType = iota + 1
+)
+
+// SelectIntoOption represents the INTO clause of select statement.
+// See https://dev.mysql.com/doc/refman/5.7/en/select-into.html
+type SelectIntoOption struct {
+	Tp         SelectIntoType
+	FileName   string
+	FieldsInfo *FieldsClause
+	LinesInfo  *LinesClause
+}
+
+// FieldsClause represents fields references clause in load data and select into outfile statement.
 type FieldsClause struct {
 	Terminated string
 	Enclosed   byte
 	Escaped    byte
 }
 
-// LinesClause represents lines references clause in load data statement.
+// LinesClause represents lines references clause in load data and select into outfile statement.
 type LinesClause struct {
 	Starting   string
 	Terminated string
@@ -964,6 +1062,7 @@ const (
 	ShowStatsHistograms
 	ShowStatsBuckets
 	ShowPlugins
+	ShowCreateView
 )
 
 // ShowStmt is a statement to provide information about databases, tables, columns and so on.
@@ -978,7 +1077,8 @@ type ShowStmt struct {
 	Column *ColumnName // Used for `desc table column`.
 	Flag   int         // Some flag parsed from sql, such as FULL.
 	Full   bool
-	User   *auth.UserIdentity // Used for show grants.
+	User   *auth.UserIdentity   // Used for show grants.
+	Roles  []*auth.RoleIdentity // Used for show grants using roles.
 
 	// GlobalScope is used by show variables
 	GlobalScope bool
@@ -39,6 +39,7 @@ var (
 	_ ExprNode = &PatternRegexpExpr{}
 	_ ExprNode = &PositionExpr{}
 	_ ExprNode = &RowExpr{}
+	_ ExprNode = &SetCollationExpr{}
 	_ ExprNode = &SubqueryExpr{}
 	_ ExprNode = &UnaryOperationExpr{}
 	_ ExprNode = &ValueExpr{}
@@ -604,6 +605,30 @@ func (n *ParenthesesExpr) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// SetCollationExpr is the expression for the COLLATE clause, e.g. "a COLLATE utf8mb4_bin".
+type SetCollationExpr struct {
+	exprNode
+	// Expr is the expression to be set collation.
+	Expr ExprNode
+	// Collate is the name of the collation.
+	Collate string
+}
+
+// Accept implements Node Accept interface.
+func (n *SetCollationExpr) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SetCollationExpr)
+	node, ok := n.Expr.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Expr = node.(ExprNode)
+	return v.Leave(n)
+}
+
 // PositionExpr is the expression for order by and group by position.
 // MySQL use position expression started from 1, it looks a little confused inner.
 // maybe later we will use 0 at first.
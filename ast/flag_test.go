@@ -105,6 +105,14 @@ func (ts *testFlagSuite) TestFlag(c *C) {
 			"sum(a)",
 			ast.FlagHasAggregateFunc | ast.FlagHasReference,
 		},
+		{
+			"row_number() over ()",
+			ast.FlagHasWindowFunc,
+		},
+		{
+			"sum(a) over (order by count(b))",
+			ast.FlagHasWindowFunc | ast.FlagHasReference | ast.FlagHasAggregateFunc,
+		},
 		{
 			"(select 1) as a",
 			ast.FlagHasSubquery,
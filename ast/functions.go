@@ -22,6 +22,12 @@ var (
 	_ FuncNode = &AggregateFuncExpr{}
 	_ FuncNode = &FuncCallExpr{}
 	_ FuncNode = &FuncCastExpr{}
+	_ FuncNode = &WindowFuncExpr{}
+
+	_ Node = &FrameBound{}
+	_ Node = &FrameClause{}
+	_ Node = &PartitionByClause{}
+	_ Node = &WindowSpec{}
 )
 
 // List scalar function names.
@@ -285,18 +291,29 @@ const (
 	ValidatePasswordStrength = "validate_password_strength"
 
 	// json functions
-	JSONType     = "json_type"
-	JSONExtract  = "json_extract"
-	JSONUnquote  = "json_unquote"
-	JSONArray    = "json_array"
-	JSONObject   = "json_object"
-	JSONMerge    = "json_merge"
-	JSONValid    = "json_valid"
-	JSONSet      = "json_set"
-	JSONInsert   = "json_insert"
-	JSONReplace  = "json_replace"
-	JSONRemove   = "json_remove"
-	JSONContains = "json_contains"
+	JSONType          = "json_type"
+	JSONExtract       = "json_extract"
+	JSONUnquote       = "json_unquote"
+	JSONArray         = "json_array"
+	JSONObject        = "json_object"
+	JSONMerge         = "json_merge"
+	JSONMergePatch    = "json_merge_patch"
+	JSONMergePreserve = "json_merge_preserve"
+	JSONValid         = "json_valid"
+	JSONSet           = "json_set"
+	JSONInsert        = "json_insert"
+	JSONReplace       = "json_replace"
+	JSONRemove        = "json_remove"
+	JSONArrayAppend   = "json_array_append"
+	JSONArrayInsert   = "json_array_insert"
+	JSONContains      = "json_contains"
+	JSONContainsPath  = "json_contains_path"
+	JSONKeys          = "json_keys"
+	JSONLength        = "json_length"
+	JSONDepth         = "json_depth"
+	JSONSearch        = "json_search"
+	JSONQuote         = "json_quote"
+	JSONPretty        = "json_pretty"
 )
 
 // FuncCallExpr is for function expression.
@@ -405,6 +422,8 @@ const (
 	AggFuncMin = "min"
 	// AggFuncGroupConcat is the name of group_concat function.
 	AggFuncGroupConcat = "group_concat"
+	// AggFuncGrouping is the name of grouping function.
+	AggFuncGrouping = "grouping"
 )
 
 // AggregateFuncExpr represents aggregate function expression.
@@ -436,3 +455,206 @@ func (n *AggregateFuncExpr) Accept(v Visitor) (Node, bool) {
 	}
 	return v.Leave(n)
 }
+
+const (
+	// WindowFuncRowNumber is the name of row_number function.
+	WindowFuncRowNumber = "row_number"
+	// WindowFuncRank is the name of rank function.
+	WindowFuncRank = "rank"
+	// WindowFuncDenseRank is the name of dense_rank function.
+	WindowFuncDenseRank = "dense_rank"
+	// WindowFuncLag is the name of lag function.
+	WindowFuncLag = "lag"
+	// WindowFuncLead is the name of lead function.
+	WindowFuncLead = "lead"
+	// WindowFuncFirstValue is the name of first_value function.
+	WindowFuncFirstValue = "first_value"
+	// WindowFuncLastValue is the name of last_value function.
+	WindowFuncLastValue = "last_value"
+)
+
+// WindowFuncs is the set of the non-aggregate window function names.
+var WindowFuncs = map[string]struct{}{
+	WindowFuncRowNumber:  {},
+	WindowFuncRank:       {},
+	WindowFuncDenseRank:  {},
+	WindowFuncLag:        {},
+	WindowFuncLead:       {},
+	WindowFuncFirstValue: {},
+	WindowFuncLastValue:  {},
+}
+
+// WindowFuncExpr represents window function expression.
+// See https://dev.mysql.com/doc/refman/8.0/en/window-functions-usage.html
+type WindowFuncExpr struct {
+	funcNode
+	// F is the function name.
+	F string
+	// Args is the function args.
+	Args []ExprNode
+	// Distinct is true if the aggregate function only aggregates distinct values.
+	Distinct bool
+	// Spec is the specification of the window.
+	Spec WindowSpec
+}
+
+// Accept implements Node Accept interface.
+func (n *WindowFuncExpr) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WindowFuncExpr)
+	for i, val := range n.Args {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Args[i] = node.(ExprNode)
+	}
+	node, ok := n.Spec.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Spec = *node.(*WindowSpec)
+	return v.Leave(n)
+}
+
+// WindowSpec is the specification of a window.
+type WindowSpec struct {
+	node
+
+	PartitionBy *PartitionByClause
+	OrderBy     *OrderByClause
+	Frame       *FrameClause
+}
+
+// Accept implements Node Accept interface.
+func (n *WindowSpec) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WindowSpec)
+	if n.PartitionBy != nil {
+		node, ok := n.PartitionBy.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.PartitionBy = node.(*PartitionByClause)
+	}
+	if n.OrderBy != nil {
+		node, ok := n.OrderBy.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.OrderBy = node.(*OrderByClause)
+	}
+	if n.Frame != nil {
+		node, ok := n.Frame.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Frame = node.(*FrameClause)
+	}
+	return v.Leave(n)
+}
+
+// PartitionByClause represents partition by clause of a window.
+type PartitionByClause struct {
+	node
+
+	Items []*ByItem
+}
+
+// Accept implements Node Accept interface.
+func (n *PartitionByClause) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*PartitionByClause)
+	for i, val := range n.Items {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Items[i] = node.(*ByItem)
+	}
+	return v.Leave(n)
+}
+
+// FrameType is the type of window frame.
+type FrameType int
+
+// Window frame types.
+const (
+	Rows FrameType = iota
+	Ranges
+)
+
+// FrameClause represents frame clause of a window.
+type FrameClause struct {
+	node
+
+	Type  FrameType
+	Start FrameBound
+	End   FrameBound
+}
+
+// Accept implements Node Accept interface.
+func (n *FrameClause) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*FrameClause)
+	node, ok := n.Start.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Start = *node.(*FrameBound)
+	node, ok = n.End.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.End = *node.(*FrameBound)
+	return v.Leave(n)
+}
+
+// BoundType is the type of window frame bound.
+type BoundType int
+
+// Frame bound types.
+const (
+	Following BoundType = iota
+	Preceding
+	CurrentRow
+)
+
+// FrameBound represents a frame bound of a window.
+type FrameBound struct {
+	node
+
+	Type      BoundType
+	UnBounded bool
+	// Expr is the offset of the bound, it's nil when UnBounded is true or Type is CurrentRow.
+	Expr ExprNode
+}
+
+// Accept implements Node Accept interface.
+func (n *FrameBound) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*FrameBound)
+	if n.Expr != nil {
+		node, ok := n.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Expr = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
@@ -27,6 +27,11 @@ func (ts *testFunctionsSuite) TestFunctionsVisitorCover(c *C) {
 		&AggregateFuncExpr{Args: []ExprNode{&ValueExpr{}}},
 		&FuncCallExpr{Args: []ExprNode{&ValueExpr{}}},
 		&FuncCastExpr{Expr: &ValueExpr{}},
+		&WindowFuncExpr{Args: []ExprNode{&ValueExpr{}}, Spec: WindowSpec{
+			PartitionBy: &PartitionByClause{Items: []*ByItem{{Expr: &ValueExpr{}}}},
+			OrderBy:     &OrderByClause{Items: []*ByItem{{Expr: &ValueExpr{}}}},
+			Frame:       &FrameClause{Start: FrameBound{Expr: &ValueExpr{}}},
+		}},
 	}
 
 	for _, stmt := range stmts {
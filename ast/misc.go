@@ -37,7 +37,9 @@ var (
 	_ StmtNode = &ExplainStmt{}
 	_ StmtNode = &GrantStmt{}
 	_ StmtNode = &PrepareStmt{}
+	_ StmtNode = &ReleaseSavepointStmt{}
 	_ StmtNode = &RollbackStmt{}
+	_ StmtNode = &SavepointStmt{}
 	_ StmtNode = &SetPwdStmt{}
 	_ StmtNode = &SetStmt{}
 	_ StmtNode = &UseStmt{}
@@ -87,7 +89,8 @@ type AuthOption struct {
 	ByAuthString bool
 	AuthString   string
 	HashString   string
-	// TODO: support auth_plugin
+	// AuthPlugin is the authentication plugin specified by IDENTIFIED WITH, it's empty if not specified.
+	AuthPlugin string
 }
 
 // ExplainStmt is a statement to provide information about how is SQL statement executed
@@ -237,10 +240,14 @@ func (n *CommitStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
-// RollbackStmt is a statement to roll back the current transaction.
+// RollbackStmt is a statement to roll back the current transaction,
+// or to roll back to a savepoint if SavepointName is not empty.
 // See https://dev.mysql.com/doc/refman/5.7/en/commit.html
+// and https://dev.mysql.com/doc/refman/5.7/en/savepoint.html
 type RollbackStmt struct {
 	stmtNode
+
+	SavepointName string
 }
 
 // Accept implements Node Accept interface.
@@ -253,6 +260,42 @@ func (n *RollbackStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// SavepointStmt is a statement to set a named savepoint in the current transaction.
+// See https://dev.mysql.com/doc/refman/5.7/en/savepoint.html
+type SavepointStmt struct {
+	stmtNode
+
+	Name string
+}
+
+// Accept implements Node Accept interface.
+func (n *SavepointStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SavepointStmt)
+	return v.Leave(n)
+}
+
+// ReleaseSavepointStmt is a statement to remove a named savepoint from the current transaction.
+// See https://dev.mysql.com/doc/refman/5.7/en/savepoint.html
+type ReleaseSavepointStmt struct {
+	stmtNode
+
+	Name string
+}
+
+// Accept implements Node Accept interface.
+func (n *ReleaseSavepointStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*ReleaseSavepointStmt)
+	return v.Leave(n)
+}
+
 // UseStmt is a statement to use the DBName database as the current database.
 // See https://dev.mysql.com/doc/refman/5.7/en/use.html
 type UseStmt struct {
@@ -458,11 +501,14 @@ func (u *UserSpec) SecurityString() string {
 
 // CreateUserStmt creates user account.
 // See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
+// It is also used for the CREATE ROLE statement.
+// See https://dev.mysql.com/doc/refman/8.0/en/create-role.html
 type CreateUserStmt struct {
 	stmtNode
 
-	IfNotExists bool
-	Specs       []*UserSpec
+	IsCreateRole bool
+	IfNotExists  bool
+	Specs        []*UserSpec
 }
 
 // Accept implements Node Accept interface.
@@ -478,7 +524,11 @@ func (n *CreateUserStmt) Accept(v Visitor) (Node, bool) {
 // SecureText implements SensitiveStatement interface.
 func (n *CreateUserStmt) SecureText() string {
 	var buf bytes.Buffer
-	buf.WriteString("create user")
+	if n.IsCreateRole {
+		buf.WriteString("create role")
+	} else {
+		buf.WriteString("create user")
+	}
 	for _, user := range n.Specs {
 		buf.WriteString(" ")
 		buf.WriteString(user.SecurityString())
@@ -519,11 +569,14 @@ func (n *AlterUserStmt) Accept(v Visitor) (Node, bool) {
 
 // DropUserStmt creates user account.
 // See http://dev.mysql.com/doc/refman/5.7/en/drop-user.html
+// It is also used for the DROP ROLE statement.
+// See https://dev.mysql.com/doc/refman/8.0/en/drop-role.html
 type DropUserStmt struct {
 	stmtNode
 
-	IfExists bool
-	UserList []*auth.UserIdentity
+	IsDropRole bool
+	IfExists   bool
+	UserList   []*auth.UserIdentity
 }
 
 // Accept implements Node Accept interface.
@@ -719,6 +772,95 @@ func (n *GrantStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// GrantRoleStmt is the struct for GRANT role statement.
+// See https://dev.mysql.com/doc/refman/8.0/en/grant.html#grant-roles
+type GrantRoleStmt struct {
+	stmtNode
+
+	Roles []*auth.RoleIdentity
+	Users []*auth.UserIdentity
+}
+
+// Accept implements Node Accept interface.
+func (n *GrantRoleStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*GrantRoleStmt)
+	return v.Leave(n)
+}
+
+// RevokeRoleStmt is the struct for REVOKE role statement.
+// See https://dev.mysql.com/doc/refman/8.0/en/revoke.html
+type RevokeRoleStmt struct {
+	stmtNode
+
+	Roles []*auth.RoleIdentity
+	Users []*auth.UserIdentity
+}
+
+// Accept implements Node Accept interface.
+func (n *RevokeRoleStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*RevokeRoleStmt)
+	return v.Leave(n)
+}
+
+// SetRoleStmtType is the type of the roles in SET ROLE and SET DEFAULT ROLE statements.
+type SetRoleStmtType int
+
+// SetRole statement types.
+const (
+	SetRoleDefault SetRoleStmtType = iota
+	SetRoleNone
+	SetRoleAll
+	SetRoleAllExcept
+	SetRoleRegular
+)
+
+// SetRoleStmt is the struct for SET ROLE statement.
+// See https://dev.mysql.com/doc/refman/8.0/en/set-role.html
+type SetRoleStmt struct {
+	stmtNode
+
+	SetRoleOpt SetRoleStmtType
+	RoleList   []*auth.RoleIdentity
+}
+
+// Accept implements Node Accept interface.
+func (n *SetRoleStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SetRoleStmt)
+	return v.Leave(n)
+}
+
+// SetDefaultRoleStmt is the struct for SET DEFAULT ROLE statement.
+// See https://dev.mysql.com/doc/refman/8.0/en/set-default-role.html
+type SetDefaultRoleStmt struct {
+	stmtNode
+
+	SetRoleOpt SetRoleStmtType
+	RoleList   []*auth.RoleIdentity
+	UserList   []*auth.UserIdentity
+}
+
+// Accept implements Node Accept interface.
+func (n *SetDefaultRoleStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SetDefaultRoleStmt)
+	return v.Leave(n)
+}
+
 // Ident is the table identifier composed of schema name and table name.
 type Ident struct {
 	Schema model.CIStr
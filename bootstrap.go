@@ -49,6 +49,7 @@ const (
 		Create_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Drop_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Process_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
+		File_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Grant_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		References_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Alter_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
@@ -65,6 +66,9 @@ const (
 		Create_user_priv		ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Event_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Trigger_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
+		Account_locked			ENUM('N','Y') NOT NULL DEFAULT 'N',
+		plugin				CHAR(64) NOT NULL DEFAULT 'mysql_native_password',
+		authentication_string		TEXT,
 		PRIMARY KEY (Host, User));`
 	// CreateDBPrivTable is the SQL statement creates DB scope privilege table in system db.
 	CreateDBPrivTable = `CREATE TABLE if not exists mysql.db (
@@ -99,7 +103,7 @@ const (
 		Table_name	CHAR(64),
 		Grantor		CHAR(77),
 		Timestamp	Timestamp DEFAULT CURRENT_TIMESTAMP,
-		Table_priv	SET('Select','Insert','Update','Delete','Create','Drop','Grant', 'Index','Alter'),
+		Table_priv	SET('Select','Insert','Update','Delete','Create','Drop','Grant', 'Index','Alter','Create View','Show View'),
 		Column_priv	SET('Select','Insert','Update'),
 		PRIMARY KEY (Host, DB, User, Table_name));`
 	// CreateColumnPrivTable is the SQL statement creates column scope privilege table in system db.
@@ -112,6 +116,23 @@ const (
 		Timestamp	Timestamp DEFAULT CURRENT_TIMESTAMP,
 		Column_priv	SET('Select','Insert','Update'),
 		PRIMARY KEY (Host, DB, User, Table_name, Column_name));`
+	// CreateRoleEdgesTable is the SQL statement creates the table of the roles granted to users in system db.
+	// The role FROM_USER@FROM_HOST is granted to the user TO_USER@TO_HOST.
+	CreateRoleEdgesTable = `CREATE TABLE if not exists mysql.role_edges (
+		FROM_HOST	CHAR(60),
+		FROM_USER	CHAR(16),
+		TO_HOST		CHAR(60),
+		TO_USER		CHAR(16),
+		WITH_ADMIN_OPTION	ENUM('N','Y') NOT NULL DEFAULT 'N',
+		PRIMARY KEY (FROM_HOST, FROM_USER, TO_HOST, TO_USER));`
+	// CreateDefaultRolesTable is the SQL statement creates the table of the default roles of users in system db.
+	// The roles in it are activated when the user connects.
+	CreateDefaultRolesTable = `CREATE TABLE if not exists mysql.default_roles (
+		HOST			CHAR(60),
+		USER			CHAR(16),
+		DEFAULT_ROLE_HOST	CHAR(60),
+		DEFAULT_ROLE_USER	CHAR(16),
+		PRIMARY KEY (HOST, USER, DEFAULT_ROLE_HOST, DEFAULT_ROLE_USER));`
 	// CreateGloablVariablesTable is the SQL statement creates global variable table in system db.
 	// TODO: MySQL puts GLOBAL_VARIABLES table in INFORMATION_SCHEMA db.
 	// INFORMATION_SCHEMA is a virtual db in TiDB. So we put this table in system db.
@@ -226,6 +247,10 @@ const (
 	version13 = 13
 	version14 = 14
 	version15 = 15
+	version16 = 16
+	version17 = 17
+	version18 = 18
+	version19 = 19
 )
 
 func checkBootstrapped(s Session) (bool, error) {
@@ -338,6 +363,22 @@ func upgrade(s Session) {
 		upgradeToVer15(s)
 	}
 
+	if ver < version16 {
+		upgradeToVer16(s)
+	}
+
+	if ver < version17 {
+		upgradeToVer17(s)
+	}
+
+	if ver < version18 {
+		upgradeToVer18(s)
+	}
+
+	if ver < version19 {
+		upgradeToVer19(s)
+	}
+
 	updateBootstrapVer(s)
 	_, err = s.Execute("COMMIT")
 
@@ -536,6 +577,27 @@ func upgradeToVer15(s Session) {
 	}
 }
 
+func upgradeToVer16(s Session) {
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN `File_priv` enum('N','Y') CHARACTER SET utf8 NOT NULL DEFAULT 'N' AFTER `Process_priv`", infoschema.ErrColumnExists)
+	// Keep the users who could do everything before able to write files.
+	mustExecute(s, "UPDATE mysql.user SET File_priv='Y' WHERE Super_priv='Y'")
+}
+
+func upgradeToVer17(s Session) {
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN `Account_locked` enum('N','Y') CHARACTER SET utf8 NOT NULL DEFAULT 'N' AFTER `Trigger_priv`", infoschema.ErrColumnExists)
+	mustExecute(s, CreateRoleEdgesTable)
+	mustExecute(s, CreateDefaultRolesTable)
+}
+
+func upgradeToVer18(s Session) {
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN `plugin` CHAR(64) NOT NULL DEFAULT 'mysql_native_password' AFTER `Account_locked`", infoschema.ErrColumnExists)
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN `authentication_string` TEXT AFTER `plugin`", infoschema.ErrColumnExists)
+}
+
+func upgradeToVer19(s Session) {
+	mustExecute(s, "ALTER TABLE mysql.tables_priv MODIFY COLUMN `Table_priv` SET('Select','Insert','Update','Delete','Create','Drop','Grant', 'Index','Alter','Create View','Show View')")
+}
+
 // updateBootstrapVer updates bootstrap version variable in mysql.TiDB table.
 func updateBootstrapVer(s Session) {
 	// Update bootstrap version.
@@ -568,6 +630,9 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateDBPrivTable)
 	mustExecute(s, CreateTablePrivTable)
 	mustExecute(s, CreateColumnPrivTable)
+	// Create role tables.
+	mustExecute(s, CreateRoleEdgesTable)
+	mustExecute(s, CreateDefaultRolesTable)
 	// Create global system variable table.
 	mustExecute(s, CreateGloablVariablesTable)
 	// Create TiDB table.
@@ -591,7 +656,7 @@ func doDMLWorks(s Session) {
 
 	// Insert a default user with empty password.
 	mustExecute(s, `INSERT INTO mysql.user VALUES
-		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`)
+		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "N", "mysql_native_password", "")`)
 
 	// Init global system variables table.
 	values := make([]string, 0, len(variable.SysVars))
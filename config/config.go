@@ -50,6 +50,9 @@ type Log struct {
 	File logutil.FileLogConfig `toml:"file" json:"file"`
 
 	SlowThreshold int `toml:"slow-threshold" json:"slow-threshold"`
+	// SlowQueryFile is the file the slow queries are written to, it's rotated with the limits of File.
+	// The slow queries are written to the general log if it's empty.
+	SlowQueryFile string `toml:"slow-query-file" json:"slow-query-file"`
 
 	QueryLogMaxLen int `toml:"query-log-max-len" json:"query-log-max-len"`
 }
@@ -60,6 +63,11 @@ type Security struct {
 	SSLCA          string `toml:"ssl-ca" json:"ssl-ca"`
 	SSLCert        string `toml:"ssl-cert" json:"ssl-cert"`
 	SSLKey         string `toml:"ssl-key" json:"ssl-key"`
+	// DefaultAuthPlugin is the authentication plugin advertised in the initial handshake.
+	DefaultAuthPlugin string `toml:"default-authentication-plugin" json:"default-authentication-plugin"`
+	// RSAPrivateKey is the path of the RSA private key used by caching_sha2_password and sha256_password
+	// to exchange the password on insecure connections, a key is generated when it's first needed if it's empty.
+	RSAPrivateKey string `toml:"rsa-private-key" json:"rsa-private-key"`
 }
 
 // Status is the status section of the config.
@@ -104,6 +112,9 @@ var defaultConf = Config{
 		SlowThreshold:  300,
 		QueryLogMaxLen: 2048,
 	},
+	Security: Security{
+		DefaultAuthPlugin: "mysql_native_password",
+	},
 	Status: Status{
 		ReportStatus:    true,
 		StatusPort:      10080,
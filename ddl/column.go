@@ -14,19 +14,24 @@
 package ddl
 
 import (
+	"math"
+	"strings"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -265,8 +270,9 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 	} else if mysql.HasNotNullFlag(columnInfo.Flag) {
 		colMeta.defaultVal = table.GetZeroValue(columnInfo)
 	}
+	colMeta.defaultVal = tablecodec.EncodeCharset(colMeta.defaultVal, t.Meta().StorageType(columnInfo))
 	for _, col := range t.Meta().Columns {
-		colMeta.oldColMap[col.ID] = &col.FieldType
+		colMeta.oldColMap[col.ID] = t.Meta().StorageType(col)
 	}
 
 	for {
@@ -330,7 +336,7 @@ func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []
 		newRow := make([]types.Datum, 0, len(rowColumns)+1)
 		for colID, val := range rowColumns {
 			newColumnIDs = append(newColumnIDs, colID)
-			newRow = append(newRow, val)
+			newRow = append(newRow, tablecodec.EncodeCharset(val, colMeta.oldColMap[colID]))
 		}
 		newColumnIDs = append(newColumnIDs, colMeta.colID)
 		newRow = append(newRow, colMeta.defaultVal)
@@ -348,7 +354,8 @@ func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []
 }
 
 type columnMeta struct {
-	colID      int64
+	colID int64
+	// defaultVal is the default value in the stored form.
 	defaultVal types.Datum
 	oldColMap  map[int64]*types.FieldType
 }
@@ -398,15 +405,596 @@ func (d *ddl) onModifyColumn(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	newCol := &model.ColumnInfo{}
 	oldColName := &model.CIStr{}
 	pos := &ast.ColumnPosition{}
-	err := job.DecodeArgs(newCol, oldColName, pos)
+	var (
+		needReorg     bool
+		strictSQLMode bool
+		changingColID int64
+	)
+	err := job.DecodeArgs(newCol, oldColName, pos, &needReorg, &strictSQLMode, &changingColID)
 	if err != nil {
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
 	}
 
+	if needReorg {
+		m := &modifyColumnInfo{
+			newCol:        newCol,
+			oldColName:    oldColName,
+			pos:           pos,
+			strictSQLMode: strictSQLMode,
+			changingColID: changingColID,
+		}
+		return d.doModifyColumnWithReorg(t, job, m)
+	}
 	return d.doModifyColumn(t, job, newCol, oldColName, pos)
 }
 
+const (
+	changingColumnPrefix = "_Col$_"
+	changingIndexPrefix  = "_Idx$_"
+)
+
+// modifyColumnInfo is the arguments of a MODIFY COLUMN job that converts the data.
+type modifyColumnInfo struct {
+	newCol        *model.ColumnInfo
+	oldColName    *model.CIStr
+	pos           *ast.ColumnPosition
+	strictSQLMode bool
+	// changingColID is the ID of the column that the data is converted to.
+	changingColID int64
+}
+
+// setJobArgs saves the arguments to the job, removedIdxIDs are the IDs of the indices to be deleted
+// when the job is finished.
+func (m *modifyColumnInfo) setJobArgs(job *model.Job, removedIdxIDs []int64) {
+	job.Args = []interface{}{m.newCol, m.oldColName, m.pos, true, m.strictSQLMode, m.changingColID, removedIdxIDs}
+}
+
+// doModifyColumnWithReorg modifies a column whose data must be converted. A hidden changing column and
+// the hidden changing indices are added, the data is converted to them by reorganization, then they
+// replace the old column and indices, which are dropped as usual.
+// How does DML work in the meantime?
+// The changing column is written with the value converted from the old column. After the replacement,
+// the old column is written with the value converted back from the new column until it is dropped.
+func (d *ddl) doModifyColumnWithReorg(t *meta.Meta, job *model.Job, m *modifyColumnInfo) (ver int64, _ error) {
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	// The old column keeps its ID until it's dropped.
+	oldCol := findColByID(tblInfo.Columns, m.newCol.ID)
+	if oldCol == nil {
+		job.State = model.JobCancelled
+		return ver, infoschema.ErrColumnNotExists.GenByArgs(m.oldColName, tblInfo.Name)
+	}
+	if job.State == model.JobRollback {
+		return d.rollbackModifyColumn(t, job, tblInfo, m)
+	}
+	if oldCol.State != model.StatePublic {
+		return d.dropModifiedColumn(t, job, tblInfo, oldCol, m)
+	}
+
+	changingCol := findColByID(tblInfo.Columns, m.changingColID)
+	if changingCol == nil {
+		if err = checkModifyColumnPosition(tblInfo, m.oldColName, m.pos); err != nil {
+			job.State = model.JobCancelled
+			return ver, errors.Trace(err)
+		}
+		changingCol = addChangingColumn(tblInfo, oldCol, m.newCol)
+		m.changingColID = changingCol.ID
+		m.setJobArgs(job, nil)
+	}
+	changingIdxs := findIndicesByColName(tblInfo.Indices, changingCol.Name.L)
+
+	originalState := changingCol.State
+	switch changingCol.State {
+	case model.StateNone:
+		// none -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		setColumnAndIndicesState(changingCol, changingIdxs, model.StateDeleteOnly)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteOnly:
+		// delete only -> write only
+		job.SchemaState = model.StateWriteOnly
+		setColumnAndIndicesState(changingCol, changingIdxs, model.StateWriteOnly)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		setColumnAndIndicesState(changingCol, changingIdxs, model.StateWriteReorganization)
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteReorganization:
+		// reorganization -> replace the old column
+		var tbl table.Table
+		tbl, err = d.getTable(job.SchemaID, tblInfo)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		var reorgInfo *reorgInfo
+		reorgInfo, err = d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			if err == nil {
+				// Get the first handle of this table.
+				err = iterateSnapshotRows(d.store, tbl, reorgInfo.SnapshotVer, math.MinInt64,
+					func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+						reorgInfo.Handle = h
+						return false, nil
+					})
+				return ver, errors.Trace(t.UpdateDDLReorgHandle(reorgInfo.Job, reorgInfo.Handle))
+			}
+			// If we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return ver, errors.Trace(err)
+		}
+
+		err = d.runReorgJob(job, func() error {
+			return d.changeColumnData(tbl, oldCol, changingCol, changingIdxs, reorgInfo, job, m.strictSQLMode)
+		})
+		if err != nil {
+			if errWaitReorgTimeout.Equal(err) {
+				// if timeout, we should return, check for the owner and re-wait job done.
+				return ver, nil
+			}
+			if kv.ErrKeyExists.Equal(err) || isDataConversionError(err) {
+				log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
+				ver, err = convertModifyColumn2RollbackJob(t, job, tblInfo, changingCol, changingIdxs, err)
+			}
+			return ver, errors.Trace(err)
+		}
+
+		replaceModifiedColumn(tblInfo, oldCol, changingCol, m)
+		job.SchemaState = model.StateWriteOnly
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", changingCol.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+// dropModifiedColumn drops the old column and indices replaced by the changing ones.
+func (d *ddl) dropModifiedColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, oldCol *model.ColumnInfo,
+	m *modifyColumnInfo) (ver int64, err error) {
+	oldIdxs := findIndicesByColName(tblInfo.Indices, oldCol.Name.L)
+	originalState := oldCol.State
+	switch oldCol.State {
+	case model.StateWriteOnly:
+		// write only -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		setColumnAndIndicesState(oldCol, oldIdxs, model.StateDeleteOnly)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		job.SchemaState = model.StateDeleteReorganization
+		setColumnAndIndicesState(oldCol, oldIdxs, model.StateDeleteReorganization)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		idxIDs := removeColumnAndIndices(tblInfo, oldCol, oldIdxs)
+		job.SchemaState = model.StatePublic
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		// Finish this job.
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+		m.setJobArgs(job, idxIDs)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", oldCol.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+// convertModifyColumn2RollbackJob rolls back the job if the data can't be converted. Like a rolled back
+// ADD INDEX job, the changing column and indices become delete only, then they are dropped.
+func convertModifyColumn2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, changingCol *model.ColumnInfo,
+	changingIdxs []*model.IndexInfo, err error) (ver int64, _ error) {
+	job.State = model.JobRollback
+	originalState := changingCol.State
+	setColumnAndIndicesState(changingCol, changingIdxs, model.StateDeleteOnly)
+	job.SchemaState = model.StateDeleteOnly
+	ver, err1 := updateTableInfo(t, job, tblInfo, originalState)
+	if err1 != nil {
+		return ver, errors.Trace(err1)
+	}
+	return ver, errors.Trace(err)
+}
+
+func (d *ddl) rollbackModifyColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, m *modifyColumnInfo) (ver int64, err error) {
+	changingCol := findColByID(tblInfo.Columns, m.changingColID)
+	if changingCol == nil {
+		job.State = model.JobRollbackDone
+		return ver, nil
+	}
+	changingIdxs := findIndicesByColName(tblInfo.Indices, changingCol.Name.L)
+	originalState := changingCol.State
+	switch changingCol.State {
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		job.SchemaState = model.StateDeleteReorganization
+		setColumnAndIndicesState(changingCol, changingIdxs, model.StateDeleteReorganization)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		idxIDs := removeColumnAndIndices(tblInfo, changingCol, changingIdxs)
+		job.SchemaState = model.StateNone
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		job.State = model.JobRollbackDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+		m.setJobArgs(job, idxIDs)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", changingCol.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+// isDataConversionError checks whether err is caused by a value that can't be converted to the new type.
+func isDataConversionError(err error) bool {
+	tErr, ok := errors.Cause(err).(*terror.Error)
+	if !ok {
+		return false
+	}
+	return tErr.Class() == terror.ClassTypes || table.ErrTruncateWrongValue.Equal(err)
+}
+
+// checkModifyColumnPosition checks the relative column of the new position exists.
+func checkModifyColumnPosition(tblInfo *model.TableInfo, oldName *model.CIStr, pos *ast.ColumnPosition) error {
+	if pos.Tp != ast.ColumnPositionAfter {
+		return nil
+	}
+	if oldName.L == pos.RelativeColumn.Name.L {
+		return infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
+	}
+	relative := findCol(tblInfo.Columns, pos.RelativeColumn.Name.L)
+	if relative == nil || relative.State != model.StatePublic {
+		return infoschema.ErrColumnNotExists.GenByArgs(pos.RelativeColumn, tblInfo.Name)
+	}
+	return nil
+}
+
+// addChangingColumn adds the hidden changing column of newCol as the last column, and a hidden changing
+// index for every index of oldCol.
+func addChangingColumn(tblInfo *model.TableInfo, oldCol, newCol *model.ColumnInfo) *model.ColumnInfo {
+	changingCol := newCol.Clone()
+	changingCol.ID = allocateColumnID(tblInfo)
+	changingCol.Name = model.NewCIStr(changingColumnPrefix + oldCol.Name.O)
+	changingCol.Offset = len(tblInfo.Columns)
+	changingCol.State = model.StateNone
+	// The rows always have the value of the changing column after reorganization.
+	changingCol.OriginDefaultValue = nil
+	changingCol.ChangeStateInfo = &model.ChangeStateInfo{DependencyColumnOffset: oldCol.Offset}
+	tblInfo.Columns = append(tblInfo.Columns, changingCol)
+
+	for _, idx := range findIndicesByColName(tblInfo.Indices, oldCol.Name.L) {
+		changingIdx := idx.Clone()
+		changingIdx.ID = allocateIndexID(tblInfo)
+		changingIdx.Name = model.NewCIStr(changingIndexPrefix + idx.Name.O)
+		changingIdx.State = model.StateNone
+		for _, ic := range changingIdx.Columns {
+			if ic.Name.L != oldCol.Name.L {
+				continue
+			}
+			ic.Name = changingCol.Name
+			ic.Offset = changingCol.Offset
+			if !types.IsTypePrefixable(changingCol.Tp) || (changingCol.Flen > 0 && ic.Length >= changingCol.Flen) {
+				ic.Length = types.UnspecifiedLength
+			}
+		}
+		tblInfo.Indices = append(tblInfo.Indices, changingIdx)
+	}
+	return changingCol
+}
+
+// replaceModifiedColumn makes the changing column and indices public in place of the old ones, the old
+// column and indices become hidden and write only.
+func replaceModifiedColumn(tblInfo *model.TableInfo, oldCol, changingCol *model.ColumnInfo, m *modifyColumnInfo) {
+	oldIdxs := findIndicesByColName(tblInfo.Indices, oldCol.Name.L)
+	changingIdxs := findIndicesByColName(tblInfo.Indices, changingCol.Name.L)
+	renameIndexColumns(oldIdxs, oldCol.Name, model.NewCIStr(changingColumnPrefix+oldCol.Name.O))
+	renameIndexColumns(changingIdxs, changingCol.Name, m.newCol.Name)
+	oldCol.Name, changingCol.Name = changingCol.Name, m.newCol.Name
+	for _, idx := range changingIdxs {
+		originIdx := findIndexByName(strings.TrimPrefix(idx.Name.L, strings.ToLower(changingIndexPrefix)), oldIdxs)
+		idx.Name, originIdx.Name = originIdx.Name, idx.Name
+	}
+
+	// Put the new column in the place of the old one, or in the new position.
+	cols := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if col == changingCol {
+			continue
+		} else if col == oldCol {
+			if m.pos.Tp == ast.ColumnPositionNone {
+				cols = append(cols, changingCol)
+			}
+			continue
+		}
+		cols = append(cols, col)
+	}
+	switch m.pos.Tp {
+	case ast.ColumnPositionFirst:
+		cols = append([]*model.ColumnInfo{changingCol}, cols...)
+	case ast.ColumnPositionAfter:
+		position := len(cols)
+		if relative := findCol(cols, m.pos.RelativeColumn.Name.L); relative != nil {
+			for i, col := range cols {
+				if col == relative {
+					position = i + 1
+				}
+			}
+		}
+		cols = append(cols[:position], append([]*model.ColumnInfo{changingCol}, cols[position:]...)...)
+	}
+	cols = append(cols, oldCol)
+	offsets := make(map[string]int, len(cols))
+	for i, col := range cols {
+		col.Offset = i
+		offsets[col.Name.L] = i
+	}
+	tblInfo.Columns = cols
+	for _, idx := range tblInfo.Indices {
+		for _, ic := range idx.Columns {
+			ic.Offset = offsets[ic.Name.L]
+		}
+	}
+
+	changingCol.ChangeStateInfo = nil
+	oldCol.ChangeStateInfo = &model.ChangeStateInfo{DependencyColumnOffset: changingCol.Offset, Replaced: true}
+	setColumnAndIndicesState(changingCol, changingIdxs, model.StatePublic)
+	setColumnAndIndicesState(oldCol, oldIdxs, model.StateWriteOnly)
+}
+
+// removeColumnAndIndices removes the column and indices from the table, and returns the IDs of the indices.
+func removeColumnAndIndices(tblInfo *model.TableInfo, col *model.ColumnInfo, idxs []*model.IndexInfo) []int64 {
+	cols := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, c := range tblInfo.Columns {
+		if c != col {
+			cols = append(cols, c)
+		}
+	}
+	tblInfo.Columns = cols
+
+	idxIDs := make([]int64, 0, len(idxs))
+	indices := make([]*model.IndexInfo, 0, len(tblInfo.Indices))
+	for _, idx := range tblInfo.Indices {
+		if findIndexByName(idx.Name.L, idxs) != nil {
+			idxIDs = append(idxIDs, idx.ID)
+			continue
+		}
+		indices = append(indices, idx)
+	}
+	tblInfo.Indices = indices
+	return idxIDs
+}
+
+func setColumnAndIndicesState(col *model.ColumnInfo, idxs []*model.IndexInfo, state model.SchemaState) {
+	col.State = state
+	for _, idx := range idxs {
+		idx.State = state
+	}
+}
+
+func renameIndexColumns(idxs []*model.IndexInfo, from, to model.CIStr) {
+	for _, idx := range idxs {
+		for _, ic := range idx.Columns {
+			if ic.Name.L == from.L {
+				ic.Name = to
+			}
+		}
+	}
+}
+
+func findIndicesByColName(indices []*model.IndexInfo, colName string) []*model.IndexInfo {
+	var idxs []*model.IndexInfo
+	for _, idx := range indices {
+		for _, ic := range idx.Columns {
+			if ic.Name.L == colName {
+				idxs = append(idxs, idx)
+				break
+			}
+		}
+	}
+	return idxs
+}
+
+func findColByID(cols []*model.ColumnInfo, id int64) *model.ColumnInfo {
+	for _, col := range cols {
+		if col.ID == id {
+			return col
+		}
+	}
+	return nil
+}
+
+// changeColumnData converts the data of oldCol to changingCol in reorganization state, and adds the entries of
+// the changing indices. It's like addTableColumn, except the value is converted from the old column and the
+// conversion honors the SQL mode of the statement.
+func (d *ddl) changeColumnData(t table.Table, oldCol, changingCol *model.ColumnInfo, changingIdxs []*model.IndexInfo,
+	reorgInfo *reorgInfo, job *model.Job, strictSQLMode bool) error {
+	seekHandle := reorgInfo.Handle
+	version := reorgInfo.SnapshotVer
+	count := job.GetRowCount()
+	ctx := d.newContext()
+	sc := ctx.GetSessionVars().StmtCtx
+	sc.TruncateAsWarning = !strictSQLMode
+	sc.OverflowAsWarning = !strictSQLMode
+
+	colMeta := &changingColumnMeta{
+		oldCol:      oldCol,
+		changingCol: changingCol,
+		colMap:      make(map[int64]*types.FieldType),
+	}
+	for _, col := range t.Meta().Columns {
+		colMeta.colMap[col.ID] = t.Meta().StorageType(col)
+	}
+	for _, col := range t.WritableCols() {
+		if col != nil && col.ID == changingCol.ID {
+			colMeta.genExpr = col.GeneratedEvalExpr
+		}
+	}
+	for _, idxInfo := range changingIdxs {
+		colMeta.indices = append(colMeta.indices, tables.NewIndex(t.Meta(), idxInfo))
+	}
+
+	handles := make([]int64, 0, defaultBatchCnt)
+	for {
+		startTime := time.Now()
+		handles = handles[:0]
+		err := iterateSnapshotRows(d.store, t, version, seekHandle,
+			func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+				handles = append(handles, h)
+				if len(handles) == defaultBatchCnt {
+					return false, nil
+				}
+				return true, nil
+			})
+		if err != nil {
+			return errors.Trace(err)
+		} else if len(handles) == 0 {
+			return nil
+		}
+
+		count += int64(len(handles))
+		seekHandle = handles[len(handles)-1] + 1
+		sub := time.Since(startTime).Seconds()
+		err = d.backfillChangingColumn(ctx, t, colMeta, handles, reorgInfo)
+		if err != nil {
+			log.Warnf("[ddl] changed column data for %v rows failed, take time %v", count, sub)
+			return errors.Trace(err)
+		}
+
+		d.setReorgRowCount(count)
+		batchHandleDataHistogram.WithLabelValues(batchModifyCol).Observe(sub)
+		log.Infof("[ddl] changed column data for %v rows, take time %v", count, sub)
+	}
+}
+
+type changingColumnMeta struct {
+	oldCol      *model.ColumnInfo
+	changingCol *model.ColumnInfo
+	// genExpr is the new generation expression if the changing column is generated.
+	genExpr expression.Expression
+	colMap  map[int64]*types.FieldType
+	indices []table.Index
+}
+
+func (d *ddl) backfillChangingColumn(ctx context.Context, t table.Table, colMeta *changingColumnMeta, handles []int64,
+	reorgInfo *reorgInfo) error {
+	var endIdx int
+	for len(handles) > 0 {
+		if len(handles) >= defaultSmallBatchCnt {
+			endIdx = defaultSmallBatchCnt
+		} else {
+			endIdx = len(handles)
+		}
+
+		err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+			if err := d.isReorgRunnable(); err != nil {
+				return errors.Trace(err)
+			}
+
+			for _, handle := range handles[:endIdx] {
+				if err := backfillChangingColumnForRow(ctx, t, colMeta, handle, txn); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			return errors.Trace(reorgInfo.UpdateHandle(txn, handles[endIdx-1]+1))
+		})
+
+		if err != nil {
+			return errors.Trace(err)
+		}
+		handles = handles[endIdx:]
+	}
+
+	return nil
+}
+
+// backfillChangingColumnForRow converts the value of the row to the changing column, or calculates it by the
+// new generation expression for a generated column, and adds the entries of the changing indices.
+func backfillChangingColumnForRow(ctx context.Context, t table.Table, colMeta *changingColumnMeta, handle int64,
+	txn kv.Transaction) error {
+	rowKey := t.RecordKey(handle)
+	rowVal, err := txn.Get(rowKey)
+	if err != nil {
+		if kv.ErrNotExist.Equal(err) {
+			// If row doesn't exist, skip it.
+			return nil
+		}
+		return errors.Trace(err)
+	}
+
+	rowColumns, err := tablecodec.DecodeRow(rowVal, colMeta.colMap, time.UTC)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, ok := rowColumns[colMeta.changingCol.ID]; ok {
+		// The column is already written by update or insert statement, skip it.
+		return nil
+	}
+
+	row, err := tables.RowWithVirtualColumns(ctx, t, handle, rowColumns)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var newVal types.Datum
+	if colMeta.genExpr != nil {
+		newVal, err = colMeta.genExpr.Eval(row)
+		if err == nil {
+			newVal, err = table.CastValue(ctx, newVal, colMeta.changingCol)
+		}
+	} else {
+		newVal, err = table.CastValue(ctx, row[colMeta.oldCol.Offset], colMeta.changingCol)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	row[colMeta.changingCol.Offset] = newVal
+
+	// The value of a virtual generated column isn't stored, only its indices are added.
+	if !colMeta.changingCol.IsGenerated() || colMeta.changingCol.GeneratedStored {
+		rowColumns[colMeta.changingCol.ID] = newVal
+		colIDs := make([]int64, 0, len(rowColumns))
+		vals := make([]types.Datum, 0, len(rowColumns))
+		for colID, val := range rowColumns {
+			colIDs = append(colIDs, colID)
+			vals = append(vals, tablecodec.EncodeCharset(val, colMeta.colMap[colID]))
+		}
+		newRowVal, err := tablecodec.EncodeRow(vals, colIDs, time.UTC)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = txn.Set(rowKey, newRowVal); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	for _, idx := range colMeta.indices {
+		idxVals := make([]types.Datum, 0, len(idx.Meta().Columns))
+		for _, ic := range idx.Meta().Columns {
+			idxVals = append(idxVals, row[ic.Offset])
+		}
+		dupHandle, err := idx.Create(txn, idxVals, handle)
+		if err != nil {
+			if kv.ErrKeyExists.Equal(err) && dupHandle == handle {
+				// Index already exists, skip it.
+				continue
+			}
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // doModifyColumn updates the column information and reorders all columns.
 func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
 	tblInfo, err := getTableInfo(t, job, job.SchemaID)
@@ -929,7 +929,8 @@ func (s *testColumnSuite) colDefStrToFieldType(c *C, str string) *types.FieldTyp
 	stmt, err := parser.New().ParseOneStmt(sqlA, "", "")
 	c.Assert(err, IsNil)
 	colDef := stmt.(*ast.AlterTableStmt).Specs[0].NewColumn
-	col, _, err := buildColumnAndConstraint(nil, 0, colDef)
+	chs, coll := getDefaultCharsetAndCollate()
+	col, _, err := buildColumnAndConstraint(nil, 0, colDef, chs, coll)
 	c.Assert(err, IsNil)
 	return &col.FieldType
 }
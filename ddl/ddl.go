@@ -76,7 +76,8 @@ var (
 	errUnsupportedModifyColumn = terror.ClassDDL.New(codeUnsupportedModifyColumn, "unsupported modify column %s")
 	errUnsupportedPKHandle     = terror.ClassDDL.New(codeUnsupportedDropPKHandle,
 		"unsupported drop integer primary key")
-	errUnsupportedCharset = terror.ClassDDL.New(codeUnsupportedCharset, "unsupported charset %s collate %s")
+	errUnsupportedCharset  = terror.ClassDDL.New(codeUnsupportedCharset, "unsupported charset %s collate %s")
+	errUnsupportedAddIndex = terror.ClassDDL.New(codeUnsupportedAddIndex, "unsupported add index on %s")
 
 	errBlobKeyWithoutLength = terror.ClassDDL.New(codeBlobKeyWithoutLength, "index for BLOB/TEXT column must specificate a key length")
 	errIncorrectPrefixKey   = terror.ClassDDL.New(codeIncorrectPrefixKey, "Incorrect prefix key; the used key part isn't a string, the used length is longer than the key part, or the storage engine doesn't support unique prefix keys")
@@ -139,6 +140,33 @@ var (
 	ErrWrongColumnName = terror.ClassDDL.New(codeWrongColumnName, mysql.MySQLErrName[mysql.ErrWrongColumnName])
 	// ErrWrongNameForIndex returns for wrong index name.
 	ErrWrongNameForIndex = terror.ClassDDL.New(codeWrongNameForIndex, mysql.MySQLErrName[mysql.ErrWrongNameForIndex])
+	// ErrWrongObject returns for a table which is not the expected kind, such as dropping a base table with DROP VIEW.
+	ErrWrongObject = terror.ClassDDL.New(codeWrongObject, mysql.MySQLErrName[mysql.ErrWrongObject])
+
+	// ErrPartitionsMustBeDefined returns for a RANGE partitioned table without partition definitions.
+	ErrPartitionsMustBeDefined = terror.ClassDDL.New(codePartitionsMustBeDefined, mysql.MySQLErrName[mysql.ErrPartitionsMustBeDefined])
+	// ErrPartitionMaxvalue returns for MAXVALUE which is not in the last partition definition.
+	ErrPartitionMaxvalue = terror.ClassDDL.New(codePartitionMaxvalue, mysql.MySQLErrName[mysql.ErrPartitionMaxvalue])
+	// ErrRangeNotIncreasing returns for VALUES LESS THAN values which are not strictly increasing.
+	ErrRangeNotIncreasing = terror.ClassDDL.New(codeRangeNotIncreasing, mysql.MySQLErrName[mysql.ErrRangeNotIncreasing])
+	// ErrSameNamePartition returns for duplicate partition names.
+	ErrSameNamePartition = terror.ClassDDL.New(codeSameNamePartition, mysql.MySQLErrName[mysql.ErrSameNamePartition])
+	// ErrUniqueKeyNeedAllFieldsInPf returns for a unique key which doesn't include all the partitioning columns.
+	ErrUniqueKeyNeedAllFieldsInPf = terror.ClassDDL.New(codeUniqueKeyNeedAllFieldsInPf, mysql.MySQLErrName[mysql.ErrUniqueKeyNeedAllFieldsInPf])
+	// ErrPartitionRequiresValues returns for a RANGE partition definition without VALUES LESS THAN.
+	ErrPartitionRequiresValues = terror.ClassDDL.New(codePartitionRequiresValues, mysql.MySQLErrName[mysql.ErrPartitionRequiresValues])
+	// ErrPartitionWrongValues returns for VALUES LESS THAN in a partition definition which isn't RANGE.
+	ErrPartitionWrongValues = terror.ClassDDL.New(codePartitionWrongValues, mysql.MySQLErrName[mysql.ErrPartitionWrongValues])
+	// ErrPartitionMgmtOnNonpartitioned returns for partition management on a table which is not partitioned.
+	ErrPartitionMgmtOnNonpartitioned = terror.ClassDDL.New(codePartitionMgmtOnNonpartitioned, mysql.MySQLErrName[mysql.ErrPartitionMgmtOnNonpartitioned])
+	// ErrDropPartitionNonExistent returns for dropping or truncating a partition which doesn't exist.
+	ErrDropPartitionNonExistent = terror.ClassDDL.New(codeDropPartitionNonExistent, mysql.MySQLErrName[mysql.ErrDropPartitionNonExistent])
+	// ErrDropLastPartition returns for dropping the only partition of a table.
+	ErrDropLastPartition = terror.ClassDDL.New(codeDropLastPartition, mysql.MySQLErrName[mysql.ErrDropLastPartition])
+	// ErrOnlyOnRangeListPartition returns for adding or dropping partitions of a table which isn't RANGE partitioned.
+	ErrOnlyOnRangeListPartition = terror.ClassDDL.New(codeOnlyOnRangeListPartition, mysql.MySQLErrName[mysql.ErrOnlyOnRangeListPartition])
+	// ErrPartitionFunctionIsNotAllowed returns for a partitioning expression which isn't supported.
+	ErrPartitionFunctionIsNotAllowed = terror.ClassDDL.New(codePartitionFunctionIsNotAllowed, mysql.MySQLErrName[mysql.ErrPartitionFunctionIsNotAllowed])
 )
 
 // DDL is responsible for updating schema in data store and maintaining in-memory InfoSchema cache.
@@ -146,8 +174,9 @@ type DDL interface {
 	CreateSchema(ctx context.Context, name model.CIStr, charsetInfo *ast.CharsetOpt) error
 	DropSchema(ctx context.Context, schema model.CIStr) error
 	CreateTable(ctx context.Context, ident ast.Ident, cols []*ast.ColumnDef,
-		constrs []*ast.Constraint, options []*ast.TableOption) error
+		constrs []*ast.Constraint, options []*ast.TableOption, partition *ast.PartitionOptions) error
 	CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ident) error
+	CreateView(ctx context.Context, ident ast.Ident, cols []*model.ColumnInfo, viewInfo *model.ViewInfo, orReplace bool) error
 	DropTable(ctx context.Context, tableIdent ast.Ident) (err error)
 	CreateIndex(ctx context.Context, tableIdent ast.Ident, unique bool, indexName model.CIStr,
 		columnNames []*ast.IndexColName, indexOption *ast.IndexOption) error
@@ -538,6 +567,7 @@ const (
 	codeUnsupportedDropPKHandle     = 204
 	codeUnsupportedCharset          = 205
 	codeUnsupportedModifyPrimaryKey = 206
+	codeUnsupportedAddIndex         = 207
 
 	codeFileNotFound                 = 1017
 	codeErrorOnRename                = 1025
@@ -565,6 +595,20 @@ const (
 	codeJSONUsedAsKey                = 3152
 	codeWrongNameForIndex            = terror.ErrCode(mysql.ErrWrongNameForIndex)
 	codeErrTooLongIndexComment       = terror.ErrCode(mysql.ErrTooLongIndexComment)
+	codeWrongObject                  = terror.ErrCode(mysql.ErrWrongObject)
+
+	codePartitionsMustBeDefined       = terror.ErrCode(mysql.ErrPartitionsMustBeDefined)
+	codePartitionMaxvalue             = terror.ErrCode(mysql.ErrPartitionMaxvalue)
+	codeRangeNotIncreasing            = terror.ErrCode(mysql.ErrRangeNotIncreasing)
+	codeSameNamePartition             = terror.ErrCode(mysql.ErrSameNamePartition)
+	codeUniqueKeyNeedAllFieldsInPf    = terror.ErrCode(mysql.ErrUniqueKeyNeedAllFieldsInPf)
+	codePartitionRequiresValues       = terror.ErrCode(mysql.ErrPartitionRequiresValues)
+	codePartitionWrongValues          = terror.ErrCode(mysql.ErrPartitionWrongValues)
+	codePartitionMgmtOnNonpartitioned = terror.ErrCode(mysql.ErrPartitionMgmtOnNonpartitioned)
+	codeDropPartitionNonExistent      = terror.ErrCode(mysql.ErrDropPartitionNonExistent)
+	codeDropLastPartition             = terror.ErrCode(mysql.ErrDropLastPartition)
+	codeOnlyOnRangeListPartition      = terror.ErrCode(mysql.ErrOnlyOnRangeListPartition)
+	codePartitionFunctionIsNotAllowed = terror.ErrCode(mysql.ErrPartitionFunctionIsNotAllowed)
 )
 
 func init() {
@@ -595,6 +639,20 @@ func init() {
 		codeWrongNameForIndex:            mysql.ErrWrongNameForIndex,
 		codeTooManyFields:                mysql.ErrTooManyFields,
 		codeErrTooLongIndexComment:       mysql.ErrTooLongIndexComment,
+		codeWrongObject:                  mysql.ErrWrongObject,
+
+		codePartitionsMustBeDefined:       mysql.ErrPartitionsMustBeDefined,
+		codePartitionMaxvalue:             mysql.ErrPartitionMaxvalue,
+		codeRangeNotIncreasing:            mysql.ErrRangeNotIncreasing,
+		codeSameNamePartition:             mysql.ErrSameNamePartition,
+		codeUniqueKeyNeedAllFieldsInPf:    mysql.ErrUniqueKeyNeedAllFieldsInPf,
+		codePartitionRequiresValues:       mysql.ErrPartitionRequiresValues,
+		codePartitionWrongValues:          mysql.ErrPartitionWrongValues,
+		codePartitionMgmtOnNonpartitioned: mysql.ErrPartitionMgmtOnNonpartitioned,
+		codeDropPartitionNonExistent:      mysql.ErrDropPartitionNonExistent,
+		codeDropLastPartition:             mysql.ErrDropLastPartition,
+		codeOnlyOnRangeListPartition:      mysql.ErrOnlyOnRangeListPartition,
+		codePartitionFunctionIsNotAllowed: mysql.ErrPartitionFunctionIsNotAllowed,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassDDL] = ddlMySQLErrCodes
 }
@@ -56,9 +56,12 @@ func (d *ddl) CreateSchema(ctx context.Context, schema model.CIStr, charsetInfo
 		Name: schema,
 	}
 	if charsetInfo != nil {
-		dbInfo.Charset = charsetInfo.Chs
-		dbInfo.Collate = charsetInfo.Col
-	} else {
+		dbInfo.Charset, dbInfo.Collate, err = resolveCharsetAndCollate(charsetInfo.Chs, charsetInfo.Col)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(dbInfo.Charset) == 0 {
 		dbInfo.Charset, dbInfo.Collate = getDefaultCharsetAndCollate()
 	}
 
@@ -107,12 +110,56 @@ func checkTooLongTable(table model.CIStr) error {
 }
 
 func getDefaultCharsetAndCollate() (string, string) {
-	// TODO: TableDefaultCharset-->DatabaseDefaultCharset-->SystemDefaultCharset.
-	// TODO: Change TableOption parser to parse collate.
-	// This is a tmp solution.
 	return "utf8", "utf8_bin"
 }
 
+// resolveCharsetAndCollate completes the charset and collation specified by the CHARSET and COLLATE
+// clauses. The charset is derived from the collation if only the collation is specified, and the
+// default collation of the charset is used if only the charset is specified. It returns empty
+// strings if neither of them is specified.
+func resolveCharsetAndCollate(chs, coll string) (string, string, error) {
+	chs, coll = strings.ToLower(chs), strings.ToLower(coll)
+	if len(chs) == 0 && len(coll) == 0 {
+		return "", "", nil
+	}
+	if len(chs) == 0 {
+		collation, err := charset.GetCollationByName(coll)
+		if err != nil {
+			return "", "", errUnsupportedCharset.GenByArgs(chs, coll)
+		}
+		chs = collation.CharsetName
+	}
+	if !charset.ValidCharsetAndCollation(chs, coll) {
+		return "", "", errUnsupportedCharset.GenByArgs(chs, coll)
+	}
+	if len(coll) == 0 {
+		var err error
+		coll, err = charset.GetDefaultCollation(chs)
+		if err != nil {
+			return "", "", errors.Trace(err)
+		}
+	}
+	return chs, coll, nil
+}
+
+// getTableCharsetAndCollate returns the default charset and collation of the columns in the table.
+// They are the ones of the table if specified, otherwise the ones of the database, and the ones of
+// the server at last.
+func getTableCharsetAndCollate(tblCharset, tblCollate string, dbInfo *model.DBInfo) (string, string, error) {
+	chs, coll, err := resolveCharsetAndCollate(tblCharset, tblCollate)
+	if err != nil || len(chs) != 0 {
+		return chs, coll, errors.Trace(err)
+	}
+	if dbInfo != nil {
+		chs, coll, err = resolveCharsetAndCollate(dbInfo.Charset, dbInfo.Collate)
+		if err != nil || len(chs) != 0 {
+			return chs, coll, errors.Trace(err)
+		}
+	}
+	chs, coll = getDefaultCharsetAndCollate()
+	return chs, coll, nil
+}
+
 func setColumnFlagWithConstraint(colMap map[string]*table.Column, v *ast.Constraint) {
 	switch v.Tp {
 	case ast.ConstraintPrimaryKey:
@@ -158,11 +205,11 @@ func setColumnFlagWithConstraint(colMap map[string]*table.Column, v *ast.Constra
 }
 
 func buildColumnsAndConstraints(ctx context.Context, colDefs []*ast.ColumnDef,
-	constraints []*ast.Constraint) ([]*table.Column, []*ast.Constraint, error) {
+	constraints []*ast.Constraint, tblCharset, tblCollate string) ([]*table.Column, []*ast.Constraint, error) {
 	var cols []*table.Column
 	colMap := map[string]*table.Column{}
 	for i, colDef := range colDefs {
-		col, cts, err := buildColumnAndConstraint(ctx, i, colDef)
+		col, cts, err := buildColumnAndConstraint(ctx, i, colDef, tblCharset, tblCollate)
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
@@ -178,13 +225,24 @@ func buildColumnsAndConstraints(ctx context.Context, colDefs []*ast.ColumnDef,
 	return cols, constraints, nil
 }
 
-func setCharsetCollationFlenDecimal(tp *types.FieldType) error {
+// setCharsetCollationFlenDecimal completes the charset, collation, flen and decimal of the column type.
+// The string column without the CHARSET and COLLATE clauses uses the default charset and collation
+// of the table.
+func setCharsetCollationFlenDecimal(tp *types.FieldType, tblCharset, tblCollate string) error {
 	tp.Charset = strings.ToLower(tp.Charset)
 	tp.Collate = strings.ToLower(tp.Collate)
 	if len(tp.Charset) == 0 {
 		switch tp.Tp {
 		case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeEnum, mysql.TypeSet:
-			tp.Charset, tp.Collate = getDefaultCharsetAndCollate()
+			if len(tp.Collate) != 0 {
+				var err error
+				tp.Charset, tp.Collate, err = resolveCharsetAndCollate(tp.Charset, tp.Collate)
+				if err != nil {
+					return errors.Trace(err)
+				}
+			} else {
+				tp.Charset, tp.Collate = tblCharset, tblCollate
+			}
 		default:
 			tp.Charset = charset.CharsetBin
 			tp.Collate = charset.CharsetBin
@@ -218,8 +276,8 @@ func setCharsetCollationFlenDecimal(tp *types.FieldType) error {
 }
 
 func buildColumnAndConstraint(ctx context.Context, offset int,
-	colDef *ast.ColumnDef) (*table.Column, []*ast.Constraint, error) {
-	err := setCharsetCollationFlenDecimal(colDef.Tp)
+	colDef *ast.ColumnDef, tblCharset, tblCollate string) (*table.Column, []*ast.Constraint, error) {
+	err := setCharsetCollationFlenDecimal(colDef.Tp, tblCharset, tblCollate)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
@@ -582,7 +640,8 @@ func checkConstraintNames(constraints []*ast.Constraint) error {
 
 func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constraints []*ast.Constraint, ctx context.Context) (tbInfo *model.TableInfo, err error) {
 	tbInfo = &model.TableInfo{
-		Name: tableName,
+		Name:    tableName,
+		Version: model.CurrLatestTableInfoVersion,
 	}
 	tbInfo.ID, err = d.genGlobalID()
 	if err != nil {
@@ -708,10 +767,17 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 	tblInfo.Name = ident.Name
 	tblInfo.AutoIncID = 0
 	tblInfo.ForeignKeys = nil
+	tblInfo.Version = model.CurrLatestTableInfoVersion
 	tblInfo.ID, err = d.genGlobalID()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if tblInfo.Partition != nil {
+		tblInfo.Partition = tblInfo.Partition.Clone()
+		if err = d.allocPartitionIDs(tblInfo.Partition.Definitions); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	job := &model.Job{
 		SchemaID:   schema.ID,
 		TableID:    tblInfo.ID,
@@ -725,8 +791,65 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 	return errors.Trace(err)
 }
 
+// CreateView creates a view with the columns of its SELECT statement. If orReplace is true,
+// an existing view with the same name is replaced and keeps its table ID.
+func (d *ddl) CreateView(ctx context.Context, ident ast.Ident, cols []*model.ColumnInfo, viewInfo *model.ViewInfo, orReplace bool) (err error) {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	if err = checkTooLongTable(ident.Name); err != nil {
+		return errors.Trace(err)
+	}
+	if len(cols) > TableColumnCountLimit {
+		return errTooManyFields
+	}
+
+	tbInfo := &model.TableInfo{
+		Name:    ident.Name,
+		Charset: mysql.DefaultCharset,
+		Collate: mysql.DefaultCollationName,
+		Columns: cols,
+		View:    viewInfo,
+	}
+	oldTbl, err := is.TableByName(ident.Schema, ident.Name)
+	if err == nil {
+		if !orReplace {
+			return infoschema.ErrTableExists.GenByArgs(ident)
+		}
+		if !oldTbl.Meta().IsView() {
+			return ErrWrongObject.GenByArgs(ident.Schema, ident.Name, "VIEW")
+		}
+		tbInfo.ID = oldTbl.Meta().ID
+	} else {
+		orReplace = false
+		tbInfo.ID, err = d.genGlobalID()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for i, col := range cols {
+		col.ID = int64(i + 1)
+		col.Offset = i
+		col.State = model.StatePublic
+	}
+	tbInfo.MaxColumnID = int64(len(cols))
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tbInfo.ID,
+		Type:       model.ActionCreateView,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{tbInfo, orReplace},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
-	constraints []*ast.Constraint, options []*ast.TableOption) (err error) {
+	constraints []*ast.Constraint, options []*ast.TableOption, partition *ast.PartitionOptions) (err error) {
 	is := d.GetInformationSchema()
 	schema, ok := is.SchemaByName(ident.Schema)
 	if !ok {
@@ -751,7 +874,12 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 		return errors.Trace(err)
 	}
 
-	cols, newConstraints, err := buildColumnsAndConstraints(ctx, colDefs, constraints)
+	tblCharset, tblCollate := getCharsetAndCollateInTableOption(options)
+	tblCharset, tblCollate, err = getTableCharsetAndCollate(tblCharset, tblCollate, schema)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cols, newConstraints, err := buildColumnsAndConstraints(ctx, colDefs, constraints, tblCharset, tblCollate)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -765,6 +893,12 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if partition != nil {
+		tbInfo.Partition, err = d.buildTablePartitionInfo(ctx, partition, tbInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -775,6 +909,7 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 	}
 
 	handleTableOptions(options, tbInfo)
+	tbInfo.Charset, tbInfo.Collate = tblCharset, tblCollate
 	err = d.doDDLJob(ctx, job)
 	if err == nil {
 		if tbInfo.AutoIncID > 1 {
@@ -809,6 +944,19 @@ func (d *ddl) handleAutoIncID(tbInfo *model.TableInfo, schemaID int64) error {
 	return nil
 }
 
+// getCharsetAndCollateInTableOption gets the charset and collation specified in the table options.
+func getCharsetAndCollateInTableOption(options []*ast.TableOption) (chs, coll string) {
+	for _, op := range options {
+		switch op.Tp {
+		case ast.TableOptionCharset:
+			chs = op.StrValue
+		case ast.TableOptionCollate:
+			coll = op.StrValue
+		}
+	}
+	return chs, coll
+}
+
 // handleTableOptions updates tableInfo according to table options.
 func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) {
 	for _, op := range options {
@@ -825,6 +973,14 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) {
 	}
 }
 
+// checkTableNotView returns ErrWrongObject if the table is a view, which can't be changed as a base table.
+func checkTableNotView(ident ast.Ident, t table.Table) error {
+	if t.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ident.Schema, ident.Name, "BASE TABLE")
+	}
+	return nil
+}
+
 func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.AlterTableSpec) (err error) {
 	// Only handle valid specs, AlterTableLock is ignored.
 	validSpecs := make([]*ast.AlterTableSpec, 0, len(specs))
@@ -835,6 +991,13 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 		validSpecs = append(validSpecs, spec)
 	}
 
+	// The errors of the table which doesn't exist are returned by the schema changes.
+	if t, err1 := d.GetInformationSchema().TableByName(ident.Schema, ident.Name); err1 == nil {
+		if err1 = checkTableNotView(ident, t); err1 != nil {
+			return errors.Trace(err1)
+		}
+	}
+
 	if len(validSpecs) != 1 {
 		// TODO: Hanlde len(validSpecs) == 0.
 		// Now we only allow one schema changing at the same time.
@@ -876,6 +1039,12 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 			err = d.RenameTable(ctx, ident, newIdent)
 		case ast.AlterTableDropPrimaryKey:
 			err = ErrUnsupportedModifyPrimaryKey.GenByArgs("drop")
+		case ast.AlterTableAddPartitions:
+			err = d.AddTablePartitions(ctx, ident, spec)
+		case ast.AlterTableDropPartition:
+			err = d.DropTablePartition(ctx, ident, model.NewCIStr(spec.Name))
+		case ast.AlterTableTruncatePartition:
+			err = d.TruncateTablePartition(ctx, ident, model.NewCIStr(spec.Name))
 		default:
 			// Nothing to do now.
 		}
@@ -948,7 +1117,11 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 	// Ingore table constraints now, maybe return error later.
 	// We use length(t.Cols()) as the default offset firstly, we will change the
 	// column's offset later.
-	col, _, err = buildColumnAndConstraint(ctx, len(t.Cols()), spec.NewColumn)
+	tblCharset, tblCollate, err := getTableCharsetAndCollate(t.Meta().Charset, t.Meta().Collate, schema)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	col, _, err = buildColumnAndConstraint(ctx, len(t.Cols()), spec.NewColumn, tblCharset, tblCollate)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -1020,7 +1193,7 @@ func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr)
 }
 
 // modifiable checks if the 'origin' type can be modified to 'to' type with out the need to
-// change or check existing data in the table.
+// change or check existing data in the table, otherwise the data is converted by reorganization.
 // It returns true if the two types has the same Charset and Collation, the same sign, both are
 // integer types or string types, and new Flen and Decimal must be greater than or equal to origin.
 func modifiable(origin *types.FieldType, to *types.FieldType) error {
@@ -1185,17 +1358,29 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 		Name:               spec.NewColumn.Name.Name,
 	})
 
-	err = setCharsetCollationFlenDecimal(&newCol.FieldType)
+	tblCharset, tblCollate, err := getTableCharsetAndCollate(t.Meta().Charset, t.Meta().Collate, schema)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	err = modifiable(&col.FieldType, &newCol.FieldType)
+	err = setCharsetCollationFlenDecimal(&newCol.FieldType, tblCharset, tblCollate)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	// If the type can't be modified in place, the data is converted to a new column by reorganization.
+	needReorg := modifiable(&col.FieldType, &newCol.FieldType) != nil
 	if err = setDefaultAndComment(ctx, newCol, spec.NewColumn.Options); err != nil {
 		return nil, errors.Trace(err)
 	}
+	// If the generation expression or the type of a generated column changes, the values of the stored
+	// generated column and the indices on the generated column are calculated again by reorganization.
+	if newCol.IsGenerated() && (needReorg || col.GeneratedExprString != newCol.GeneratedExprString) {
+		needReorg = newCol.GeneratedStored || len(findIndicesByColName(t.Meta().Indices, col.Name.L)) > 0
+	}
+	if needReorg {
+		if err = checkModifyColumnWithReorg(t.Meta(), col); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 
 	// Copy index related options to the new spec.
 	indexFlags := col.FieldType.Flag & (mysql.PriKeyFlag | mysql.UniqueKeyFlag | mysql.MultipleKeyFlag)
@@ -1224,14 +1409,28 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 		TableID:    t.Meta().ID,
 		Type:       model.ActionModifyColumn,
 		BinlogInfo: &model.HistoryInfo{},
-		Args:       []interface{}{&newCol, originalColName, spec.Position},
+		Args:       []interface{}{&newCol, originalColName, spec.Position, needReorg, ctx.GetSessionVars().StrictSQLMode},
 	}
 	return job, nil
 }
 
-// ChangeColumn renames an existing column and modifies the column's definition,
-// currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// checkModifyColumnWithReorg checks whether the data of col can be converted to a new column.
+func checkModifyColumnWithReorg(tblInfo *model.TableInfo, col *table.Column) error {
+	if tblInfo.Partition != nil {
+		return errUnsupportedModifyColumn.GenByArgs("type of a partitioned table")
+	}
+	if tblInfo.PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
+		return errUnsupportedModifyColumn.GenByArgs("type of the primary key handle")
+	}
+	for _, c := range tblInfo.Columns {
+		if _, ok := c.Dependences[col.Name.L]; ok {
+			return errUnsupportedModifyColumn.GenByArgs("type of a column referenced by generated columns")
+		}
+	}
+	return nil
+}
+
+// ChangeColumn renames an existing column and modifies the column's definition.
 func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	if len(spec.NewColumn.Name.Schema.O) != 0 && ident.Schema.L != spec.NewColumn.Name.Schema.L {
 		return ErrWrongDBName.GenByArgs(spec.NewColumn.Name.Schema.O)
@@ -1256,8 +1455,7 @@ func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.Alter
 	return errors.Trace(err)
 }
 
-// ModifyColumn does modification on an existing column, currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// ModifyColumn does modification on an existing column.
 func (d *ddl) ModifyColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	if len(spec.NewColumn.Name.Schema.O) != 0 && ident.Schema.L != spec.NewColumn.Name.Schema.L {
 		return ErrWrongDBName.GenByArgs(spec.NewColumn.Name.Schema.O)
@@ -1355,16 +1553,133 @@ func (d *ddl) TruncateTable(ctx context.Context, ti ast.Ident) error {
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
+	if err = checkTableNotView(ti, tb); err != nil {
+		return errors.Trace(err)
+	}
 	newTableID, err := d.genGlobalID()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	// The partitions get new physical table IDs too.
+	var newPartitionIDs []int64
+	if pi := tb.Meta().Partition; pi != nil {
+		newDefs := pi.Clone().Definitions
+		if err = d.allocPartitionIDs(newDefs); err != nil {
+			return errors.Trace(err)
+		}
+		for _, def := range newDefs {
+			newPartitionIDs = append(newPartitionIDs, def.ID)
+		}
+	}
 	job := &model.Job{
 		SchemaID:   schema.ID,
 		TableID:    tb.Meta().ID,
 		Type:       model.ActionTruncateTable,
 		BinlogInfo: &model.HistoryInfo{},
-		Args:       []interface{}{newTableID},
+		Args:       []interface{}{newTableID, newPartitionIDs},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// AddTablePartitions appends new partitions to a RANGE partitioned table.
+func (d *ddl) AddTablePartitions(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+	tblInfo := t.Meta()
+	if tblInfo.Partition == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	partInfo := &model.PartitionInfo{Type: tblInfo.Partition.Type, Expr: tblInfo.Partition.Expr}
+	partInfo.Definitions, err = buildPartitionDefinitions(ctx, tblInfo.Partition.Type, spec.PartDefinitions)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = checkAddPartition(tblInfo, partInfo); err != nil {
+		return errors.Trace(err)
+	}
+	if err = d.allocPartitionIDs(partInfo.Definitions); err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionAddTablePartition,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{partInfo},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// DropTablePartition drops a partition of a RANGE partitioned table, the data of the partition is
+// deleted in the background.
+func (d *ddl) DropTablePartition(ctx context.Context, ident ast.Ident, partName model.CIStr) error {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+	if _, err = checkDropPartition(t.Meta(), partName.L); err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionDropTablePartition,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{partName.L},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// TruncateTablePartition removes all the rows of a partition, the partition gets a new physical
+// table ID and the old data is deleted in the background.
+func (d *ddl) TruncateTablePartition(ctx context.Context, ident ast.Ident, partName model.CIStr) error {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+	pi := t.Meta().Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	if pi.FindPartition(partName.L) == -1 {
+		return ErrDropPartitionNonExistent.GenByArgs("TRUNCATE")
+	}
+	newPartitionID, err := d.genGlobalID()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionTruncateTablePartition,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{partName.L, newPartitionID},
 	}
 	err = d.doDDLJob(ctx, job)
 	err = d.callHookOnChanged(err)
@@ -1427,6 +1742,14 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
+	if err = checkTableNotView(ti, t); err != nil {
+		return errors.Trace(err)
+	}
+
+	if t.Meta().Partition != nil {
+		// The index of a partitioned table needs to be filled partition by partition.
+		return errors.Trace(errUnsupportedAddIndex.GenByArgs("partitioned table"))
+	}
 
 	// Deal with anonymous index.
 	if len(indexName.L) == 0 {
@@ -1549,6 +1872,9 @@ func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
+	if err = checkTableNotView(ti, t); err != nil {
+		return errors.Trace(err)
+	}
 
 	if indexInfo := findIndexByName(indexName.L, t.Meta().Indices); indexInfo == nil {
 		return ErrCantDropFieldOrKey.Gen("index %s doesn't exist", indexName)
@@ -14,6 +14,7 @@
 package ddl_test
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -98,6 +100,61 @@ func (s *testStateChangeSuite) TestTwoStates(c *C) {
 	// TODO: Add more DDL statements.
 }
 
+// TestModifyColumnReplacedStates tests the DML in the states after the old column of MODIFY COLUMN is
+// replaced, when the new values can't be converted back to the old column.
+func (s *testStateChangeSuite) TestModifyColumnReplacedStates(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := s.se.Execute("create table t_replaced (c1 int, c2 int)")
+	c.Assert(err, IsNil)
+	defer s.se.Execute("drop table t_replaced")
+	_, err = s.se.Execute("insert into t_replaced values (1, 1)")
+	c.Assert(err, IsNil)
+	se, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	defer se.Close()
+	_, err = se.Execute("use test_db_state")
+	c.Assert(err, IsNil)
+
+	callback := &ddl.TestDDLCallback{}
+	var (
+		checkErr error
+		reorged  bool
+		states   []model.SchemaState
+	)
+	callback.OnJobUpdatedExported = func(job *model.Job) {
+		if job.SchemaState == model.StateWriteReorganization {
+			reorged = true
+			return
+		}
+		if !reorged || checkErr != nil {
+			return
+		}
+		switch job.SchemaState {
+		case model.StateWriteOnly, model.StateDeleteOnly:
+			if checkErr = s.dom.Reload(); checkErr != nil {
+				return
+			}
+			states = append(states, job.SchemaState)
+			id := len(states) + 1
+			if _, checkErr = se.Execute(fmt.Sprintf("insert into t_replaced values (%d, 'abc')", id)); checkErr != nil {
+				return
+			}
+			_, checkErr = se.Execute(fmt.Sprintf("update t_replaced set c2 = 'xyz%d' where c1 = 1", id))
+		}
+	}
+	d := s.dom.DDL()
+	d.SetHook(callback)
+	defer d.SetHook(&ddl.TestDDLCallback{})
+	_, err = s.se.Execute("alter table t_replaced modify c2 varchar(20)")
+	c.Assert(err, IsNil)
+	c.Assert(errors.ErrorStack(checkErr), Equals, "")
+	c.Assert(states, DeepEquals, []model.SchemaState{model.StateWriteOnly, model.StateDeleteOnly})
+
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test_db_state")
+	tk.MustQuery("select c1, c2 from t_replaced order by c1").Check(testkit.Rows("1 xyz3", "2 abc", "3 abc"))
+}
+
 func (s *testStateChangeSuite) test(c *C, tableName, alterTableSQL string, testInfo *testExecInfo) {
 	defer testleak.AfterTest(c)()
 	_, err := s.se.Execute(`create table t (
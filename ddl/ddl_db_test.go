@@ -40,6 +40,7 @@ import (
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -899,12 +900,82 @@ func (s *testDBSuite) TestChangeColumn(c *C) {
 	s.testErrorCode(c, sql, tmysql.ErrWrongTableName)
 	sql = "alter table t3 change aa a bigint not null"
 	s.testErrorCode(c, sql, tmysql.ErrUnknown)
-	sql = "alter table t3 modify en enum('a', 'z', 'b', 'c') not null default 'a'"
-	s.testErrorCode(c, sql, tmysql.ErrUnknown)
+	s.mustExec(c, "alter table t3 modify en enum('a', 'z', 'b', 'c') not null default 'a'")
+	s.tk.MustQuery("select en from t3").Check(testkit.Rows("a", "a", "a"))
 
 	s.tk.MustExec("drop table t3")
 }
 
+func (s *testDBSuite) TestModifyColumnWithReorg(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.mustExec(c, "create table t_modify (c1 int, c2 varchar(20), c3 int, index idx_c2 (c2), index idx_c3_c2 (c3, c2))")
+	defer s.mustExec(c, "drop table t_modify")
+
+	num := defaultBatchSize + 10
+	for i := 0; i < num; i++ {
+		s.mustExec(c, "insert into t_modify values (?, ?, ?)", i, strconv.Itoa(i), i)
+	}
+
+	done := make(chan error, 1)
+	sessionExecInGoroutine(c, s.store, "alter table t_modify modify c2 bigint", done)
+
+	ticker := time.NewTicker(s.lease / 2)
+	defer ticker.Stop()
+	step := 10
+LOOP:
+	for {
+		select {
+		case err := <-done:
+			if err == nil {
+				break LOOP
+			}
+			c.Assert(err, IsNil, Commentf("err:%v", errors.ErrorStack(err)))
+		case <-ticker.C:
+			// delete, update and add some rows in every state.
+			for i := num; i < num+step; i++ {
+				n := rand.Intn(num)
+				s.mustExec(c, "delete from t_modify where c1 = ?", n)
+				s.mustExec(c, "update t_modify set c2 = c1 + 1 where c1 = ?", n+1)
+				s.mustExec(c, "insert into t_modify values (?, ?, ?)", i, strconv.Itoa(i), i)
+			}
+			num += step
+		}
+	}
+
+	s.mustExec(c, "admin check table t_modify")
+	rows := s.mustQuery(c, "select count(*) from t_modify where c2 = c1 or c2 = c1 + 1")
+	count := s.mustQuery(c, "select count(*) from t_modify")
+	matchRows(c, rows, count)
+	s.tk.MustQuery("select c1, c2 from t_modify use index(idx_c2) where c2 = 5 and c1 = 5").Check(testkit.Rows("5 5"))
+	t := s.testGetTable(c, "t_modify")
+	c.Assert(t.Meta().Columns, HasLen, 3)
+	c.Assert(t.Meta().Columns[1].Name.O, Equals, "c2")
+	c.Assert(t.Meta().Columns[1].Tp, Equals, tmysql.TypeLonglong)
+	c.Assert(t.Meta().Indices, HasLen, 2)
+	for _, idx := range t.Meta().Indices {
+		c.Assert(idx.State, Equals, model.StatePublic)
+	}
+	c.Assert(t.Meta().Indices[0].Name.O, Equals, "idx_c2")
+	c.Assert(t.Meta().Indices[1].Columns[1].Offset, Equals, 1)
+
+	// The job is rolled back if the data can't be converted, the old column and indices are kept.
+	s.mustExec(c, "create table t_modify_fail (c1 int, c2 varchar(10), unique key uk_c2 (c2))")
+	defer s.mustExec(c, "drop table t_modify_fail")
+	s.mustExec(c, "insert into t_modify_fail values (1, '1'), (2, '01')")
+	_, err := s.tk.Exec("alter table t_modify_fail modify c2 int")
+	c.Assert(kv.ErrKeyExists.Equal(err), IsTrue, Commentf("err:%v", err))
+	s.mustExec(c, "insert into t_modify_fail values (3, 'abc')")
+	s.testErrorCode(c, "alter table t_modify_fail modify c2 varchar(2)", tmysql.ErrDataTooLong)
+	s.mustExec(c, "admin check table t_modify_fail")
+	t = s.testGetTable(c, "t_modify_fail")
+	c.Assert(t.Meta().Columns, HasLen, 2)
+	c.Assert(t.Meta().Columns[1].Tp, Equals, tmysql.TypeVarchar)
+	c.Assert(t.Meta().Indices, HasLen, 1)
+	c.Assert(t.Meta().Indices[0].Name.O, Equals, "uk_c2")
+	s.tk.MustQuery("select c2 from t_modify_fail order by c1").Check(testkit.Rows("1", "01", "abc"))
+}
+
 func (s *testDBSuite) TestAlterColumn(c *C) {
 	s.tk = testkit.NewTestKit(c, s.store)
 	s.tk.MustExec("use " + s.schemaName)
@@ -1417,6 +1488,63 @@ func (s *testDBSuite) TestChangeColumnPosition(c *C) {
 	c.Assert(createSQL, Equals, strings.Join(exceptedSQL, "\n"))
 }
 
+func (s *testDBSuite) TestGeneratedColumnWithReorg(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.mustExec(c, "create table t_gen (c1 int, c2 int as (c1 + 1) virtual, c3 int as (c1 * 2) stored)")
+	defer s.mustExec(c, "drop table t_gen")
+
+	num := defaultBatchSize + 10
+	for i := 0; i < num; i++ {
+		s.mustExec(c, "insert into t_gen(c1) values (?)", i)
+	}
+
+	// The values of the virtual generated column are calculated when the index is added, and when the
+	// generation expressions are modified.
+	for _, sql := range []string{
+		"alter table t_gen add index idx_c2 (c2)",
+		"alter table t_gen modify c2 int as (c1 + 2) virtual",
+		"alter table t_gen modify c3 bigint as (c1 * 3) stored",
+	} {
+		done := make(chan error, 1)
+		sessionExecInGoroutine(c, s.store, sql, done)
+
+		ticker := time.NewTicker(s.lease / 2)
+		step := 10
+	LOOP:
+		for {
+			select {
+			case err := <-done:
+				if err == nil {
+					break LOOP
+				}
+				c.Assert(err, IsNil, Commentf("err:%v", errors.ErrorStack(err)))
+			case <-ticker.C:
+				// delete, update and add some rows in every state.
+				for i := num; i < num+step; i++ {
+					n := rand.Intn(num)
+					s.mustExec(c, "delete from t_gen where c1 = ?", n)
+					s.mustExec(c, "update t_gen set c1 = ? where c1 = ?", i+step, n+1)
+					s.mustExec(c, "insert into t_gen(c1) values (?)", i)
+				}
+				num += 2 * step
+			}
+		}
+		ticker.Stop()
+		s.mustExec(c, "admin check table t_gen")
+	}
+
+	rows := s.mustQuery(c, "select count(*) from t_gen where c2 = c1 + 2 and c3 = c1 * 3")
+	count := s.mustQuery(c, "select count(*) from t_gen")
+	matchRows(c, rows, count)
+	t := s.testGetTable(c, "t_gen")
+	c.Assert(t.Meta().Columns, HasLen, 3)
+	c.Assert(t.Meta().Columns[1].GeneratedExprString, Equals, "c1+2")
+	c.Assert(t.Meta().Columns[2].Tp, Equals, tmysql.TypeLonglong)
+	c.Assert(t.Meta().Indices, HasLen, 1)
+	c.Assert(t.Meta().Indices[0].State, Equals, model.StatePublic)
+}
+
 func (s *testDBSuite) TestGeneratedColumnDDL(c *C) {
 	s.tk = testkit.NewTestKit(c, s.store)
 	s.tk.MustExec("use test")
@@ -1513,3 +1641,75 @@ func (s *testDBSuite) TestComment(c *C) {
 
 	s.tk.MustExec("drop table if exists ct, ct1")
 }
+
+func (s *testDBSuite) TestLegacyCollationIndex(c *C) {
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_legacy, t_new")
+	s.tk.MustExec("create table t_legacy (id int primary key, a varchar(10) collate utf8_general_ci, b varchar(10) charset latin1, c int, index ia(a), index ib(b), index ica(c, a))")
+	s.tk.MustExec("create table t_new like t_legacy")
+
+	// Simulate a table created before the sort keys and the column charsets are stored.
+	tbl := s.testGetTable(c, "t_legacy")
+	c.Assert(tbl.Meta().Version, Equals, model.CurrLatestTableInfoVersion)
+	db, ok := sessionctx.GetDomain(s.tk.Se.(context.Context)).InfoSchema().SchemaByName(model.NewCIStr(s.schemaName))
+	c.Assert(ok, IsTrue)
+	tblInfo := tbl.Meta().Clone()
+	tblInfo.Version = model.TableInfoVersion0
+	err := kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		_, err1 := m.GenSchemaVersion()
+		c.Assert(err1, IsNil)
+		return errors.Trace(m.UpdateTable(db.ID, tblInfo))
+	})
+	c.Assert(err, IsNil)
+	tbl = s.testGetTable(c, "t_legacy")
+	c.Assert(tbl.Meta().Version, Equals, model.TableInfoVersion0)
+
+	for _, name := range []string{"t_legacy", "t_new"} {
+		s.tk.MustExec("insert into " + name + " values (1, 'abc', 'café', 1), (2, 'ABD', '€', 1), (3, 'abe', 'a', 2)")
+	}
+	// The existing indexes keep the binary index keys and the strings stored in UTF-8.
+	c.Assert(s.firstIndexValue(c, tbl, "ia"), BytesEquals, []byte("ABD"))
+	c.Assert(s.firstIndexValue(c, tbl, "ib"), BytesEquals, []byte("a"))
+	s.tk.MustExec("alter table t_legacy add index ia2(a)")
+	tbl = s.testGetTable(c, "t_legacy")
+	c.Assert(s.firstIndexValue(c, tbl, "ia2"), BytesEquals, []byte("ABD"))
+	newTbl := s.testGetTable(c, "t_new")
+	c.Assert(s.firstIndexValue(c, newTbl, "ia"), BytesEquals, charset.GetCollator("utf8_general_ci").Key("abc"))
+
+	for _, name := range []string{"t_legacy", "t_new"} {
+		s.tk.MustQuery("select id from " + name + " use index (ia) where a = 'ABC'").Check(testkit.Rows("1"))
+		s.tk.MustQuery("select id from " + name + " use index (ia) where a in ('ABC', 'abd')").Check(testkit.Rows("1", "2"))
+		s.tk.MustQuery("select a from " + name + " use index (ia) order by a").Check(testkit.Rows("abc", "ABD", "abe"))
+		s.tk.MustQuery("select id from " + name + " use index (ica) where c = 1 and a > 'ABC'").Check(testkit.Rows("2"))
+		s.tk.MustQuery("select b from " + name + " use index (ib) where b = '€'").Check(testkit.Rows("€"))
+		s.tk.MustQuery("select b from " + name + " where id = 1").Check(testkit.Rows("café"))
+		s.tk.MustQuery("select t1.id from t_new t1 join " + name + " t2 on t1.a = t2.a where t2.id = 2").Check(testkit.Rows("2"))
+		s.tk.MustExec("update " + name + " set a = 'ABC', b = concat(b, 'é') where id = 3")
+		s.tk.MustQuery("select id from " + name + " use index (ia) where a = 'abc' order by id").Check(testkit.Rows("1", "3"))
+		s.tk.MustExec("admin check table " + name)
+	}
+	s.tk.MustExec("drop table t_legacy, t_new")
+}
+
+// firstIndexValue returns the bytes of the first column in the first index key of the index.
+func (s *testDBSuite) firstIndexValue(c *C, tbl table.Table, idxName string) []byte {
+	var idxInfo *model.IndexInfo
+	for _, idx := range tbl.Meta().Indices {
+		if idx.Name.L == idxName {
+			idxInfo = idx
+		}
+	}
+	c.Assert(idxInfo, NotNil)
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	it, err := txn.Seek(tablecodec.EncodeTableIndexPrefix(tbl.Meta().ID, idxInfo.ID))
+	c.Assert(err, IsNil)
+	defer it.Close()
+	c.Assert(it.Valid(), IsTrue)
+	vals, err := tablecodec.DecodeIndexKey(it.Key())
+	c.Assert(err, IsNil)
+	return vals[0].GetBytes()
+}
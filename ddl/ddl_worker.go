@@ -125,8 +125,15 @@ func (d *ddl) updateDDLJob(t *meta.Meta, job *model.Job, updateTS uint64) error
 // finishDDLJob deletes the finished DDL job in the ddl queue and puts it to history queue.
 // If the DDL job need to handle in background, it will prepare a background job.
 func (d *ddl) finishDDLJob(t *meta.Meta, job *model.Job) (err error) {
+	if job.Type == model.ActionModifyColumn && job.State == model.JobRollbackDone {
+		// The args of a rolled back job are changed by its last run, but not encoded yet.
+		if _, err = job.Encode(true); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	switch job.Type {
-	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable, model.ActionDropIndex:
+	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable, model.ActionDropIndex,
+		model.ActionDropTablePartition, model.ActionTruncateTablePartition, model.ActionModifyColumn:
 		if job.Version <= currentVersion {
 			err = d.delRangeManager.addDelRangeJob(job)
 		} else {
@@ -263,6 +270,8 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		ver, err = d.onCreateTable(t, job)
 	case model.ActionDropTable:
 		ver, err = d.onDropTable(t, job)
+	case model.ActionCreateView:
+		ver, err = d.onCreateView(t, job)
 	case model.ActionAddColumn:
 		ver, err = d.onAddColumn(t, job)
 	case model.ActionDropColumn:
@@ -281,6 +290,12 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		ver, err = d.onTruncateTable(t, job)
 	case model.ActionRenameTable:
 		ver, err = d.onRenameTable(t, job)
+	case model.ActionAddTablePartition:
+		ver, err = d.onAddTablePartition(t, job)
+	case model.ActionDropTablePartition:
+		ver, err = d.onDropTablePartition(t, job)
+	case model.ActionTruncateTablePartition:
+		ver, err = d.onTruncateTablePartition(t, job)
 	case model.ActionSetDefaultValue:
 		ver, err = d.onSetDefaultValue(t, job)
 	default:
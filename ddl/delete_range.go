@@ -22,6 +22,7 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/ngaut/pools"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl/util"
 	"github.com/pingcap/tidb/kv"
@@ -229,20 +230,69 @@ func insertJobIntoDeleteRangeTable(ctx context.Context, job *model.Job) error {
 			}
 		}
 	case model.ActionDropTable, model.ActionTruncateTable:
-		tableID := job.TableID
-		startKey := tablecodec.EncodeTablePrefix(tableID)
-		endKey := tablecodec.EncodeTablePrefix(tableID + 1)
-		return doInsert(s, job.ID, tableID, startKey, endKey, now)
+		// The data of a partitioned table is in its partitions.
+		var startKey kv.Key
+		var partitionIDs []int64
+		if err := job.DecodeArgs(&startKey, &partitionIDs); err != nil {
+			return errors.Trace(err)
+		}
+		for _, tableID := range append([]int64{job.TableID}, partitionIDs...) {
+			startKey = tablecodec.EncodeTablePrefix(tableID)
+			endKey := tablecodec.EncodeTablePrefix(tableID + 1)
+			if err := doInsert(s, job.ID, tableID, startKey, endKey, now); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	case model.ActionDropTablePartition, model.ActionTruncateTablePartition:
+		var physicalTableID int64
+		if err := job.DecodeArgs(&physicalTableID); err != nil {
+			return errors.Trace(err)
+		}
+		startKey := tablecodec.EncodeTablePrefix(physicalTableID)
+		endKey := tablecodec.EncodeTablePrefix(physicalTableID + 1)
+		return doInsert(s, job.ID, physicalTableID, startKey, endKey, now)
+	case model.ActionModifyColumn:
+		// The indices of the old or the changing column are deleted if the data is converted.
+		var (
+			newCol        model.ColumnInfo
+			oldColName    model.CIStr
+			pos           ast.ColumnPosition
+			needReorg     bool
+			strictSQLMode bool
+			changingColID int64
+			indexIDs      []int64
+		)
+		if err := job.DecodeArgs(&newCol, &oldColName, &pos, &needReorg, &strictSQLMode, &changingColID, &indexIDs); err != nil {
+			return errors.Trace(err)
+		}
+		for _, indexID := range indexIDs {
+			startKey := tablecodec.EncodeTableIndexPrefix(job.TableID, indexID)
+			endKey := tablecodec.EncodeTableIndexPrefix(job.TableID, indexID+1)
+			if err := doInsert(s, job.ID, indexID, startKey, endKey, now); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	case model.ActionDropIndex:
 		tableID := job.TableID
 		var indexName interface{}
 		var indexID int64
-		if err := job.DecodeArgs(&indexName, &indexID); err != nil {
+		var partitionIDs []int64
+		if err := job.DecodeArgs(&indexName, &indexID, &partitionIDs); err != nil {
 			return errors.Trace(err)
 		}
-		startKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID)
-		endKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID+1)
-		return doInsert(s, job.ID, indexID, startKey, endKey, now)
+		if len(partitionIDs) == 0 {
+			startKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID)
+			endKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID+1)
+			return doInsert(s, job.ID, indexID, startKey, endKey, now)
+		}
+		// The element ID of every range must be unique in the job, so the partition ID is used.
+		for _, pid := range partitionIDs {
+			startKey := tablecodec.EncodeTableIndexPrefix(pid, indexID)
+			endKey := tablecodec.EncodeTableIndexPrefix(pid, indexID+1)
+			if err := doInsert(s, job.ID, pid, startKey, endKey, now); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
 	return nil
 }
@@ -389,7 +389,7 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 			job.State = model.JobDone
 		}
 		job.BinlogInfo.AddTableInfo(ver, tblInfo)
-		job.Args = append(job.Args, indexInfo.ID)
+		job.Args = append(job.Args, indexInfo.ID, getPartitionIDs(tblInfo))
 		d.asyncNotifyEvent(&Event{Tp: model.ActionDropIndex, TableInfo: tblInfo, IndexInfo: indexInfo})
 	default:
 		err = ErrInvalidTableState.Gen("invalid table state %v", tblInfo.State)
@@ -435,10 +435,15 @@ func (w *worker) fetchRowColVals(txn kv.Transaction, t table.Table, colMap map[i
 func (w *worker) getIndexRecord(t table.Table, colMap map[int64]*types.FieldType, rawRecord []byte, idxRecord *indexRecord) error {
 	cols := t.Cols()
 	idxInfo := w.index.Meta()
+	// The map is reused by the rows, the values of the last row must not be left.
+	for id := range w.rowMap {
+		delete(w.rowMap, id)
+	}
 	_, err := tablecodec.DecodeRowWithMap(rawRecord, colMap, time.UTC, w.rowMap)
 	if err != nil {
 		return errors.Trace(err)
 	}
+	var row []types.Datum
 	idxVal := make([]types.Datum, len(idxInfo.Columns))
 	for j, v := range idxInfo.Columns {
 		col := cols[v.Offset]
@@ -450,6 +455,17 @@ func (w *worker) getIndexRecord(t table.Table, colMap map[int64]*types.FieldType
 			}
 			continue
 		}
+		// The value of a virtual generated column isn't stored, it's calculated from the other columns.
+		if col.IsVirtualGenerated() {
+			if row == nil {
+				row, err = tables.RowWithVirtualColumns(w.ctx, t, idxRecord.handle, w.rowMap)
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
+			idxVal[j] = row[col.Offset]
+			continue
+		}
 		idxColumnVal := w.rowMap[col.ID]
 		if _, ok := w.rowMap[col.ID]; ok {
 			idxVal[j] = idxColumnVal
@@ -540,9 +556,19 @@ type handleInfo struct {
 func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo *reorgInfo, job *model.Job) error {
 	cols := t.Cols()
 	colMap := make(map[int64]*types.FieldType)
+	hasVirtualCol := false
 	for _, v := range indexInfo.Columns {
 		col := cols[v.Offset]
-		colMap[col.ID] = &col.FieldType
+		colMap[col.ID] = t.Meta().StorageType(col.ToInfo())
+		hasVirtualCol = hasVirtualCol || col.IsVirtualGenerated()
+	}
+	if hasVirtualCol {
+		// The values of the virtual generated columns are calculated from the other columns.
+		for _, col := range t.WritableCols() {
+			if col != nil {
+				colMap[col.ID] = t.Meta().StorageType(col.ToInfo())
+			}
+		}
 	}
 	workerCnt := defaultWorkers
 	taskBatch := int64(defaultTaskHandleCnt)
@@ -0,0 +1,356 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// buildTablePartitionInfo builds the partition info of a table from its PARTITION BY clause.
+// KEY partitioning on a single column is stored as HASH partitioning on the column.
+func (d *ddl) buildTablePartitionInfo(ctx context.Context, s *ast.PartitionOptions, tbInfo *model.TableInfo) (*model.PartitionInfo, error) {
+	expr := s.Expr
+	pi := &model.PartitionInfo{Type: s.Tp}
+	if expr != nil {
+		pi.Expr = expr.Text()
+	} else {
+		if len(s.ColumnNames) != 1 {
+			return nil, errors.Trace(ErrPartitionFunctionIsNotAllowed)
+		}
+		expr = &ast.ColumnNameExpr{Name: s.ColumnNames[0]}
+		pi.Expr = fmt.Sprintf("`%s`", s.ColumnNames[0].Name.O)
+	}
+	partExpr, err := buildPartitionExpr(ctx, expr, tbInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = checkPartitionKeysConstraint(tbInfo, partExpr); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if len(s.Definitions) == 0 {
+		if s.Tp == model.PartitionTypeRange {
+			return nil, ErrPartitionsMustBeDefined.GenByArgs("RANGE")
+		}
+		num := s.Num
+		if num == 0 {
+			num = 1
+		}
+		for i := uint64(0); i < num; i++ {
+			pi.Definitions = append(pi.Definitions, model.PartitionDefinition{Name: model.NewCIStr(fmt.Sprintf("p%d", i))})
+		}
+	} else {
+		pi.Definitions, err = buildPartitionDefinitions(ctx, s.Tp, s.Definitions)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err = checkPartitionDefinitions(pi); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = d.allocPartitionIDs(pi.Definitions); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return pi, nil
+}
+
+// buildPartitionExpr builds the partitioning expression on the columns of the table, the expression
+// must refer to some columns and return an integer.
+func buildPartitionExpr(ctx context.Context, expr ast.ExprNode, tbInfo *model.TableInfo) (expression.Expression, error) {
+	partExpr, err := expression.RewriteAstExpr(expr, expression.TableInfo2Schema(tbInfo), ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if partExpr.GetType().EvalType() != types.ETInt || len(expression.ExtractColumns(partExpr)) == 0 {
+		return nil, errors.Trace(ErrPartitionFunctionIsNotAllowed)
+	}
+	return partExpr, nil
+}
+
+// buildPartitionDefinitions evaluates the VALUES LESS THAN clauses of the partition definitions.
+func buildPartitionDefinitions(ctx context.Context, tp model.PartitionType, defs []*ast.PartitionDefinition) ([]model.PartitionDefinition, error) {
+	sc := ctx.GetSessionVars().StmtCtx
+	partDefs := make([]model.PartitionDefinition, 0, len(defs))
+	for _, def := range defs {
+		partDef := model.PartitionDefinition{Name: def.Name}
+		if tp != model.PartitionTypeRange {
+			if def.MaxValue || len(def.LessThan) > 0 {
+				return nil, ErrPartitionWrongValues.GenByArgs("RANGE", "LESS THAN")
+			}
+			partDefs = append(partDefs, partDef)
+			continue
+		}
+		if def.MaxValue {
+			partDef.LessThan = model.PartitionMaxValue
+			partDefs = append(partDefs, partDef)
+			continue
+		}
+		if len(def.LessThan) != 1 {
+			return nil, ErrPartitionRequiresValues.GenByArgs("RANGE", "LESS THAN")
+		}
+		v, err := expression.EvalAstExpr(def.LessThan[0], ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if v.IsNull() {
+			return nil, errors.Trace(ErrPartitionFunctionIsNotAllowed)
+		}
+		bound, err := v.ToInt64(sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		partDef.LessThan = strconv.FormatInt(bound, 10)
+		partDefs = append(partDefs, partDef)
+	}
+	return partDefs, nil
+}
+
+// checkPartitionDefinitions checks that the partition names are unique, and the upper bounds of
+// RANGE partitions are strictly increasing with MAXVALUE only in the last one.
+func checkPartitionDefinitions(pi *model.PartitionInfo) error {
+	names := make(map[string]struct{}, len(pi.Definitions))
+	var prev int64
+	for i, def := range pi.Definitions {
+		if _, ok := names[def.Name.L]; ok {
+			return ErrSameNamePartition.GenByArgs(def.Name.O)
+		}
+		names[def.Name.L] = struct{}{}
+		if pi.Type != model.PartitionTypeRange {
+			continue
+		}
+		if def.LessThan == model.PartitionMaxValue {
+			if i != len(pi.Definitions)-1 {
+				return errors.Trace(ErrPartitionMaxvalue)
+			}
+			continue
+		}
+		bound, err := strconv.ParseInt(def.LessThan, 10, 64)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if i > 0 && bound <= prev {
+			return errors.Trace(ErrRangeNotIncreasing)
+		}
+		prev = bound
+	}
+	return nil
+}
+
+// checkPartitionKeysConstraint checks that every primary key and unique key includes all the
+// columns of the partitioning expression, so a key is unique if it's unique in its partition.
+func checkPartitionKeysConstraint(tbInfo *model.TableInfo, partExpr expression.Expression) error {
+	partCols := expression.ExtractColumns(partExpr)
+	if tbInfo.PKIsHandle {
+		for _, col := range partCols {
+			if colInfo := findCol(tbInfo.Columns, col.ColName.L); colInfo == nil || !mysql.HasPriKeyFlag(colInfo.Flag) {
+				return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("PRIMARY KEY")
+			}
+		}
+	}
+	for _, idx := range tbInfo.Indices {
+		if !idx.Unique && !idx.Primary {
+			continue
+		}
+		for _, col := range partCols {
+			if findIndexColumn(idx, col.ColName.L) {
+				continue
+			}
+			if idx.Primary {
+				return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("PRIMARY KEY")
+			}
+			return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("UNIQUE INDEX")
+		}
+	}
+	return nil
+}
+
+func findIndexColumn(idx *model.IndexInfo, colName string) bool {
+	for _, idxCol := range idx.Columns {
+		if idxCol.Name.L == colName {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *ddl) allocPartitionIDs(defs []model.PartitionDefinition) error {
+	for i := range defs {
+		id, err := d.genGlobalID()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defs[i].ID = id
+	}
+	return nil
+}
+
+// getPartitionIDs returns the physical table IDs of the partitions of a table.
+func getPartitionIDs(tblInfo *model.TableInfo) []int64 {
+	if tblInfo.Partition == nil {
+		return nil
+	}
+	ids := make([]int64, 0, len(tblInfo.Partition.Definitions))
+	for _, def := range tblInfo.Partition.Definitions {
+		ids = append(ids, def.ID)
+	}
+	return ids
+}
+
+// checkAddPartition checks that the partitions in partInfo can be appended to the table.
+func checkAddPartition(tblInfo *model.TableInfo, partInfo *model.PartitionInfo) error {
+	pi := tblInfo.Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	if pi.Type != model.PartitionTypeRange {
+		return errors.Trace(ErrOnlyOnRangeListPartition.GenByArgs("ADD"))
+	}
+	newPi := pi.Clone()
+	newPi.Definitions = append(newPi.Definitions, partInfo.Definitions...)
+	return errors.Trace(checkPartitionDefinitions(newPi))
+}
+
+// checkDropPartition checks that the partition can be dropped and returns its offset.
+func checkDropPartition(tblInfo *model.TableInfo, partName string) (int, error) {
+	pi := tblInfo.Partition
+	if pi == nil {
+		return -1, errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	if pi.Type != model.PartitionTypeRange {
+		return -1, errors.Trace(ErrOnlyOnRangeListPartition.GenByArgs("DROP"))
+	}
+	idx := pi.FindPartition(partName)
+	if idx == -1 {
+		return -1, ErrDropPartitionNonExistent.GenByArgs("DROP")
+	}
+	if len(pi.Definitions) == 1 {
+		return -1, errors.Trace(ErrDropLastPartition)
+	}
+	return idx, nil
+}
+
+func (d *ddl) onAddTablePartition(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	partInfo := &model.PartitionInfo{}
+	if err := job.DecodeArgs(partInfo); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = checkAddPartition(tblInfo, partInfo); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo.Partition.Definitions = append(tblInfo.Partition.Definitions, partInfo.Definitions...)
+
+	ver, err = updateVersionAndTableInfo(t, job, tblInfo)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
+// onDropTablePartition removes the partition from the table, the data of the partition is deleted
+// by the delete-range worker.
+func (d *ddl) onDropTablePartition(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var partName string
+	if err := job.DecodeArgs(&partName); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	idx, err := checkDropPartition(tblInfo, partName)
+	if err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	defs := tblInfo.Partition.Definitions
+	physicalTableID := defs[idx].ID
+	tblInfo.Partition.Definitions = append(defs[:idx:idx], defs[idx+1:]...)
+
+	ver, err = updateVersionAndTableInfo(t, job, tblInfo)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StateNone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	job.Args = []interface{}{physicalTableID}
+	return ver, nil
+}
+
+// onTruncateTablePartition gives the partition a new physical table ID, the data of the old one
+// is deleted by the delete-range worker.
+func (d *ddl) onTruncateTablePartition(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var (
+		partName       string
+		newPartitionID int64
+	)
+	if err := job.DecodeArgs(&partName, &newPartitionID); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	pi := tblInfo.Partition
+	if pi == nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	idx := pi.FindPartition(partName)
+	if idx == -1 {
+		job.State = model.JobCancelled
+		return ver, ErrDropPartitionNonExistent.GenByArgs("TRUNCATE")
+	}
+	physicalTableID := pi.Definitions[idx].ID
+	pi.Definitions[idx].ID = newPartitionID
+
+	ver, err = updateVersionAndTableInfo(t, job, tblInfo)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	job.Args = []interface{}{physicalTableID}
+	return ver, nil
+}
+
+func updateVersionAndTableInfo(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo) (int64, error) {
+	ver, err := updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	return ver, errors.Trace(t.UpdateTable(job.SchemaID, tblInfo))
+}
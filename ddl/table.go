@@ -71,6 +71,59 @@ func (d *ddl) onCreateTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	}
 }
 
+func (d *ddl) onCreateView(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tbInfo := &model.TableInfo{}
+	var orReplace bool
+	if err := job.DecodeArgs(tbInfo, &orReplace); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tbInfo.State = model.StateNone
+	if orReplace {
+		oldTbInfo, err := t.GetTable(schemaID, tbInfo.ID)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		// The view may be dropped by another DDL job, create it again then.
+		orReplace = oldTbInfo != nil
+	}
+	if !orReplace {
+		err := checkTableNotExists(t, job, schemaID, tbInfo.Name.L)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+	}
+
+	ver, err := updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	switch tbInfo.State {
+	case model.StateNone:
+		// none -> public
+		job.SchemaState = model.StatePublic
+		tbInfo.State = model.StatePublic
+		if orReplace {
+			err = t.UpdateTable(schemaID, tbInfo)
+		} else {
+			err = t.CreateTable(schemaID, tbInfo)
+		}
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		// Finish this job.
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tbInfo)
+		return ver, nil
+	default:
+		return ver, ErrInvalidTableState.Gen("invalid view state %v", tbInfo.State)
+	}
+}
+
 func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	schemaID := job.SchemaID
 	tableID := job.TableID
@@ -122,7 +175,7 @@ func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		job.State = model.JobDone
 		job.BinlogInfo.AddTableInfo(ver, tblInfo)
 		startKey := tablecodec.EncodeTablePrefix(tableID)
-		job.Args = append(job.Args, startKey)
+		job.Args = append(job.Args, startKey, getPartitionIDs(tblInfo))
 		d.asyncNotifyEvent(&Event{Tp: model.ActionDropTable, TableInfo: tblInfo})
 	default:
 		err = ErrInvalidTableState.Gen("invalid table state %v", tblInfo.State)
@@ -195,8 +248,11 @@ func getTableInfo(t *meta.Meta, job *model.Job, schemaID int64) (*model.TableInf
 func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	schemaID := job.SchemaID
 	tableID := job.TableID
-	var newTableID int64
-	err := job.DecodeArgs(&newTableID)
+	var (
+		newTableID      int64
+		newPartitionIDs []int64
+	)
+	err := job.DecodeArgs(&newTableID, &newPartitionIDs)
 	if err != nil {
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
@@ -205,6 +261,14 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	if err != nil {
 		return ver, errors.Trace(err)
 	}
+	oldPartitionIDs := getPartitionIDs(tblInfo)
+	if len(oldPartitionIDs) != len(newPartitionIDs) {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(errInvalidDDLJob.Gen("the number of partitions is changed"))
+	}
+	for i := range oldPartitionIDs {
+		tblInfo.Partition.Definitions[i].ID = newPartitionIDs[i]
+	}
 
 	err = t.DropTable(schemaID, tableID, true)
 	if err != nil {
@@ -225,7 +289,7 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	job.State = model.JobDone
 	job.BinlogInfo.AddTableInfo(ver, tblInfo)
 	startKey := tablecodec.EncodeTablePrefix(tableID)
-	job.Args = []interface{}{startKey}
+	job.Args = []interface{}{startKey, oldPartitionIDs}
 	return ver, nil
 }
 
@@ -0,0 +1,327 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dagexec
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/distsql/xeval"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/expression/aggregation"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-tipb"
+)
+
+type dagContext struct {
+	snap      Snapshot
+	keyRanges []kv.KeyRange
+	evalCtx   *evalContext
+}
+
+// Build builds the executors of the DAG request which read the snapshot in the key ranges,
+// the key ranges must be in ascending order.
+func Build(snap Snapshot, dagReq *tipb.DAGRequest, keyRanges []kv.KeyRange) (Executor, error) {
+	ctx := &dagContext{
+		snap:      snap,
+		keyRanges: keyRanges,
+		evalCtx: &evalContext{
+			sc:       xeval.FlagsToStatementContext(dagReq.Flags),
+			timeZone: time.FixedZone("UTC", int(dagReq.TimeZoneOffset)),
+		},
+	}
+	var src Executor
+	for _, executor := range dagReq.Executors {
+		curr, err := buildExec(ctx, executor)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		curr.SetSrcExec(src)
+		src = curr
+	}
+	return src, nil
+}
+
+// NewTableScan returns the executor which scans the rows of the columns in the key ranges.
+func NewTableScan(snap Snapshot, columns []*tipb.ColumnInfo, keyRanges []kv.KeyRange) Executor {
+	evalCtx := &evalContext{}
+	evalCtx.setColumnInfo(columns)
+	return &tableScanExec{
+		TableScan: &tipb.TableScan{Columns: columns},
+		kvRanges:  keyRanges,
+		colIDs:    evalCtx.colIDs,
+		fieldTps:  evalCtx.fieldTps,
+		snap:      snap,
+	}
+}
+
+// NewIndexScan returns the executor which scans the encoded values of the first colsLen
+// columns of the index in the key ranges.
+func NewIndexScan(snap Snapshot, colsLen int, keyRanges []kv.KeyRange) Executor {
+	return &indexScanExec{
+		IndexScan: &tipb.IndexScan{Desc: false},
+		kvRanges:  keyRanges,
+		colsLen:   colsLen,
+		snap:      snap,
+	}
+}
+
+func buildExec(ctx *dagContext, curr *tipb.Executor) (Executor, error) {
+	var currExec Executor
+	var err error
+	switch curr.GetTp() {
+	case tipb.ExecType_TypeTableScan:
+		currExec = buildTableScan(ctx, curr)
+	case tipb.ExecType_TypeIndexScan:
+		currExec = buildIndexScan(ctx, curr)
+	case tipb.ExecType_TypeSelection:
+		currExec, err = buildSelection(ctx, curr)
+	case tipb.ExecType_TypeAggregation:
+		currExec, err = buildAggregation(ctx, curr)
+	case tipb.ExecType_TypeTopN:
+		currExec, err = buildTopN(ctx, curr)
+	case tipb.ExecType_TypeLimit:
+		currExec = &limitExec{limit: curr.Limit.GetLimit()}
+	default:
+		// TODO: Support other types.
+		err = errors.Errorf("this exec type %v doesn't support yet.", curr.GetTp())
+	}
+
+	return currExec, errors.Trace(err)
+}
+
+func buildTableScan(ctx *dagContext, executor *tipb.Executor) *tableScanExec {
+	columns := executor.TblScan.Columns
+	ctx.evalCtx.setColumnInfo(columns)
+
+	return &tableScanExec{
+		TableScan: executor.TblScan,
+		kvRanges:  scanRanges(ctx.keyRanges, executor.TblScan.Desc),
+		colIDs:    ctx.evalCtx.colIDs,
+		fieldTps:  ctx.evalCtx.fieldTps,
+		snap:      ctx.snap,
+	}
+}
+
+func buildIndexScan(ctx *dagContext, executor *tipb.Executor) *indexScanExec {
+	columns := executor.IdxScan.Columns
+	ctx.evalCtx.setColumnInfo(columns)
+	length := len(columns)
+	pkStatus := pkColNotExists
+	// The PKHandle column info has been collected in ctx.
+	if columns[length-1].GetPkHandle() {
+		if mysql.HasUnsignedFlag(uint(columns[length-1].GetFlag())) {
+			pkStatus = pkColIsUnsigned
+		} else {
+			pkStatus = pkColIsSigned
+		}
+		columns = columns[:length-1]
+	} else if columns[length-1].ColumnId == model.ExtraHandleID {
+		pkStatus = pkColIsSigned
+		columns = columns[:length-1]
+	}
+
+	return &indexScanExec{
+		IndexScan: executor.IdxScan,
+		kvRanges:  scanRanges(ctx.keyRanges, executor.IdxScan.Desc),
+		colsLen:   len(columns),
+		snap:      ctx.snap,
+		pkStatus:  pkStatus,
+	}
+}
+
+// scanRanges returns the key ranges in the scan order.
+func scanRanges(keyRanges []kv.KeyRange, desc bool) []kv.KeyRange {
+	if !desc {
+		return keyRanges
+	}
+	ranges := make([]kv.KeyRange, len(keyRanges))
+	for i, ran := range keyRanges {
+		ranges[len(keyRanges)-i-1] = ran
+	}
+	return ranges
+}
+
+func buildSelection(ctx *dagContext, executor *tipb.Executor) (*selectionExec, error) {
+	var err error
+	var relatedColOffsets []int
+	pbConds := executor.Selection.Conditions
+	for _, cond := range pbConds {
+		relatedColOffsets, err = extractOffsetsInExpr(cond, relatedColOffsets)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	conds, err := convertToExprs(ctx.evalCtx.sc, ctx.evalCtx.fieldTps, pbConds)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &selectionExec{
+		evalCtx:           ctx.evalCtx,
+		relatedColOffsets: relatedColOffsets,
+		conditions:        conds,
+		row:               make([]types.Datum, len(ctx.evalCtx.columnInfos)),
+	}, nil
+}
+
+func buildAggregation(ctx *dagContext, executor *tipb.Executor) (*aggregateExec, error) {
+	length := len(executor.Aggregation.AggFunc)
+	aggs := make([]aggregation.Aggregation, 0, length)
+	var err error
+	var relatedColOffsets []int
+	for _, expr := range executor.Aggregation.AggFunc {
+		var aggExpr aggregation.Aggregation
+		aggExpr, err = aggregation.NewDistAggFunc(expr, ctx.evalCtx.fieldTps, ctx.evalCtx.sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		aggs = append(aggs, aggExpr)
+		relatedColOffsets, err = extractOffsetsInExpr(expr, relatedColOffsets)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	for _, item := range executor.Aggregation.GroupBy {
+		relatedColOffsets, err = extractOffsetsInExpr(item, relatedColOffsets)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	groupBys, err := convertToExprs(ctx.evalCtx.sc, ctx.evalCtx.fieldTps, executor.Aggregation.GetGroupBy())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &aggregateExec{
+		evalCtx:           ctx.evalCtx,
+		aggExprs:          aggs,
+		groupByExprs:      groupBys,
+		groups:            make(map[string]struct{}),
+		relatedColOffsets: relatedColOffsets,
+		row:               make([]types.Datum, len(ctx.evalCtx.columnInfos)),
+	}, nil
+}
+
+func buildTopN(ctx *dagContext, executor *tipb.Executor) (*topNExec, error) {
+	topN := executor.TopN
+	var err error
+	var relatedColOffsets []int
+	pbConds := make([]*tipb.Expr, len(topN.OrderBy))
+	for i, item := range topN.OrderBy {
+		relatedColOffsets, err = extractOffsetsInExpr(item.Expr, relatedColOffsets)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pbConds[i] = item.Expr
+	}
+	heap := &topNHeap{
+		totalCount: int(topN.Limit),
+		topNSorter: topNSorter{
+			orderByItems: topN.OrderBy,
+			sc:           ctx.evalCtx.sc,
+		},
+	}
+	conds, err := convertToExprs(ctx.evalCtx.sc, ctx.evalCtx.fieldTps, pbConds)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &topNExec{
+		heap:              heap,
+		evalCtx:           ctx.evalCtx,
+		relatedColOffsets: relatedColOffsets,
+		orderByExprs:      conds,
+		row:               make([]types.Datum, len(ctx.evalCtx.columnInfos)),
+	}, nil
+}
+
+type evalContext struct {
+	colIDs      map[int64]int
+	columnInfos []*tipb.ColumnInfo
+	fieldTps    []*types.FieldType
+	sc          *variable.StatementContext
+	timeZone    *time.Location
+}
+
+func (e *evalContext) setColumnInfo(cols []*tipb.ColumnInfo) {
+	e.columnInfos = make([]*tipb.ColumnInfo, len(cols))
+	copy(e.columnInfos, cols)
+
+	e.colIDs = make(map[int64]int)
+	e.fieldTps = make([]*types.FieldType, 0, len(e.columnInfos))
+	for i, col := range e.columnInfos {
+		ft := distsql.FieldTypeFromPBColumn(col)
+		e.fieldTps = append(e.fieldTps, ft)
+		e.colIDs[col.GetColumnId()] = i
+	}
+}
+
+// decodeRelatedColumnVals decodes data to Datum slice according to the row information.
+func (e *evalContext) decodeRelatedColumnVals(relatedColOffsets []int, value [][]byte, row []types.Datum) error {
+	var err error
+	for _, offset := range relatedColOffsets {
+		row[offset], err = tablecodec.DecodeColumnValue(value[offset], e.fieldTps[offset], e.timeZone)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func convertToExprs(sc *variable.StatementContext, fieldTps []*types.FieldType, pbExprs []*tipb.Expr) ([]expression.Expression, error) {
+	exprs := make([]expression.Expression, 0, len(pbExprs))
+	for _, expr := range pbExprs {
+		e, err := expression.PBToExpr(expr, fieldTps, sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		exprs = append(exprs, e)
+	}
+	return exprs, nil
+}
+
+// extractOffsetsInExpr appends the offsets of the columns referred by expr to collector.
+func extractOffsetsInExpr(expr *tipb.Expr, collector []int) ([]int, error) {
+	if expr == nil {
+		return collector, nil
+	}
+	if expr.GetTp() == tipb.ExprType_ColumnRef {
+		_, idx, err := codec.DecodeInt(expr.Val)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, offset := range collector {
+			if offset == int(idx) {
+				return collector, nil
+			}
+		}
+		return append(collector, int(idx)), nil
+	}
+	var err error
+	for _, child := range expr.Children {
+		collector, err = extractOffsetsInExpr(child, collector)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return collector, nil
+}
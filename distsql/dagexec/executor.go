@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package mocktikv
+package dagexec
 
 import (
 	"bytes"
@@ -19,7 +19,6 @@ import (
 	"sort"
 
 	"github.com/juju/errors"
-	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/expression/aggregation"
 	"github.com/pingcap/tidb/kv"
@@ -32,25 +31,35 @@ import (
 	"github.com/pingcap/tipb/go-tipb"
 )
 
-type executor interface {
-	SetSrcExec(executor)
+// Snapshot is the snapshot of the key-value pairs read by the scan executors.
+type Snapshot interface {
+	// Get returns the value of the key, the value is nil if the key doesn't exist.
+	Get(key kv.Key) ([]byte, error)
+	// Scan returns the first key-value pair in [startKey, endKey), or the last one if desc is true.
+	// The key is nil if there is no pair in the range.
+	Scan(startKey, endKey kv.Key, desc bool) (kv.Key, []byte, error)
+}
+
+// Executor is an executor of DAG requests, Next returns the encoded column values of the next row,
+// the row is nil if there is no more row.
+type Executor interface {
+	SetSrcExec(Executor)
 	Next() ([][]byte, error)
 }
 
 type tableScanExec struct {
 	*tipb.TableScan
-	colIDs         map[int64]int
-	kvRanges       []kv.KeyRange
-	startTS        uint64
-	isolationLevel kvrpcpb.IsolationLevel
-	mvccStore      MVCCStore
-	cursor         int
-	seekKey        []byte
-
-	src executor
+	colIDs   map[int64]int
+	fieldTps []*types.FieldType
+	kvRanges []kv.KeyRange
+	snap     Snapshot
+	cursor   int
+	seekKey  kv.Key
+
+	src Executor
 }
 
-func (e *tableScanExec) SetSrcExec(exec executor) {
+func (e *tableScanExec) SetSrcExec(exec Executor) {
 	e.src = exec
 }
 
@@ -64,6 +73,9 @@ func (e *tableScanExec) Next() (value [][]byte, err error) {
 			}
 			e.seekKey = nil
 			e.cursor++
+			if value == nil {
+				continue
+			}
 			return value, nil
 		}
 
@@ -83,7 +95,7 @@ func (e *tableScanExec) Next() (value [][]byte, err error) {
 }
 
 func (e *tableScanExec) getRowFromPoint(ran kv.KeyRange) ([][]byte, error) {
-	val, err := e.mvccStore.Get(ran.StartKey, e.startTS, e.isolationLevel)
+	val, err := e.snap.Get(ran.StartKey)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -94,7 +106,7 @@ func (e *tableScanExec) getRowFromPoint(ran kv.KeyRange) ([][]byte, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	row, err := getRowData(e.Columns, e.colIDs, handle, val)
+	row, err := cutRowData(e.Columns, e.colIDs, e.fieldTps, handle, val)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -102,53 +114,47 @@ func (e *tableScanExec) getRowFromPoint(ran kv.KeyRange) ([][]byte, error) {
 }
 
 func (e *tableScanExec) getRowFromRange(ran kv.KeyRange) ([][]byte, error) {
-	if e.seekKey == nil {
-		if e.Desc {
-			e.seekKey = ran.EndKey
-		} else {
-			e.seekKey = ran.StartKey
-		}
-	}
-	var pairs []Pair
-	var pair Pair
-	if e.Desc {
-		pairs = e.mvccStore.ReverseScan(ran.StartKey, e.seekKey, 1, e.startTS, e.isolationLevel)
-	} else {
-		pairs = e.mvccStore.Scan(e.seekKey, ran.EndKey, 1, e.startTS, e.isolationLevel)
-	}
-	if len(pairs) > 0 {
-		pair = pairs[0]
-	}
-	if pair.Err != nil {
-		// TODO: Handle lock error.
-		return nil, errors.Trace(pair.Err)
+	key, value, err := scanNext(e.snap, ran, e.seekKey, e.Desc)
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
-	if pair.Key == nil {
+	if key == nil {
 		return nil, nil
 	}
 	if e.Desc {
-		if bytes.Compare(pair.Key, ran.StartKey) < 0 {
-			return nil, nil
-		}
-		e.seekKey = []byte(tablecodec.TruncateToRowKeyLen(kv.Key(pair.Key)))
+		e.seekKey = append(kv.Key(nil), tablecodec.TruncateToRowKeyLen(key)...)
 	} else {
-		if bytes.Compare(pair.Key, ran.EndKey) >= 0 {
-			return nil, nil
-		}
-		e.seekKey = []byte(kv.Key(pair.Key).PrefixNext())
+		e.seekKey = key.PrefixNext()
 	}
 
-	handle, err := tablecodec.DecodeRowKey(pair.Key)
+	handle, err := tablecodec.DecodeRowKey(key)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	row, err := getRowData(e.Columns, e.colIDs, handle, pair.Value)
+	row, err := cutRowData(e.Columns, e.colIDs, e.fieldTps, handle, value)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	return row, nil
 }
 
+// scanNext returns the next key-value pair in the range from seekKey, seekKey is nil
+// if the range is not scanned yet.
+func scanNext(snap Snapshot, ran kv.KeyRange, seekKey kv.Key, desc bool) (kv.Key, []byte, error) {
+	if desc {
+		if seekKey == nil {
+			seekKey = ran.EndKey
+		}
+		key, value, err := snap.Scan(ran.StartKey, seekKey, true)
+		return key, value, errors.Trace(err)
+	}
+	if seekKey == nil {
+		seekKey = ran.StartKey
+	}
+	key, value, err := snap.Scan(seekKey, ran.EndKey, false)
+	return key, value, errors.Trace(err)
+}
+
 const (
 	pkColNotExists = iota
 	pkColIsSigned
@@ -157,19 +163,17 @@ const (
 
 type indexScanExec struct {
 	*tipb.IndexScan
-	colsLen        int
-	kvRanges       []kv.KeyRange
-	startTS        uint64
-	isolationLevel kvrpcpb.IsolationLevel
-	mvccStore      MVCCStore
-	cursor         int
-	seekKey        []byte
-	pkStatus       int
-
-	src executor
+	colsLen  int
+	kvRanges []kv.KeyRange
+	snap     Snapshot
+	cursor   int
+	seekKey  kv.Key
+	pkStatus int
+
+	src Executor
 }
 
-func (e *indexScanExec) SetSrcExec(exec executor) {
+func (e *indexScanExec) SetSrcExec(exec Executor) {
 	e.src = exec
 }
 
@@ -192,43 +196,20 @@ func (e *indexScanExec) Next() (value [][]byte, err error) {
 }
 
 func (e *indexScanExec) getRowFromRange(ran kv.KeyRange) ([][]byte, error) {
-	if e.seekKey == nil {
-		if e.Desc {
-			e.seekKey = ran.EndKey
-		} else {
-			e.seekKey = ran.StartKey
-		}
-	}
-	var pairs []Pair
-	var pair Pair
-	if e.Desc {
-		pairs = e.mvccStore.ReverseScan(ran.StartKey, e.seekKey, 1, e.startTS, e.isolationLevel)
-	} else {
-		pairs = e.mvccStore.Scan(e.seekKey, ran.EndKey, 1, e.startTS, e.isolationLevel)
-	}
-	if len(pairs) > 0 {
-		pair = pairs[0]
-	}
-	if pair.Err != nil {
-		// TODO: Handle lock error.
-		return nil, errors.Trace(pair.Err)
+	key, value, err := scanNext(e.snap, ran, e.seekKey, e.Desc)
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
-	if pair.Key == nil {
+	if key == nil {
 		return nil, nil
 	}
 	if e.Desc {
-		if bytes.Compare(pair.Key, ran.StartKey) < 0 {
-			return nil, nil
-		}
-		e.seekKey = pair.Key
+		e.seekKey = append(kv.Key(nil), key...)
 	} else {
-		if bytes.Compare(pair.Key, ran.EndKey) >= 0 {
-			return nil, nil
-		}
-		e.seekKey = []byte(kv.Key(pair.Key).PrefixNext())
+		e.seekKey = key.PrefixNext()
 	}
 
-	values, b, err := tablecodec.CutIndexKeyNew(pair.Key, e.colsLen)
+	values, b, err := tablecodec.CutIndexKeyNew(key, e.colsLen)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -236,24 +217,22 @@ func (e *indexScanExec) getRowFromRange(ran kv.KeyRange) ([][]byte, error) {
 		if e.pkStatus != pkColNotExists {
 			values = append(values, b)
 		}
-	} else {
-		handle, err := decodeHandle(pair.Value)
+	} else if e.pkStatus != pkColNotExists {
+		handle, err := decodeHandle(value)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		if e.pkStatus != pkColNotExists {
-			var handleDatum types.Datum
-			if e.pkStatus == pkColIsUnsigned {
-				handleDatum = types.NewUintDatum(uint64(handle))
-			} else {
-				handleDatum = types.NewIntDatum(handle)
-			}
-			handleBytes, err := codec.EncodeValue(b, handleDatum)
-			if err != nil {
-				return nil, errors.Trace(err)
-			}
-			values = append(values, handleBytes)
+		var handleDatum types.Datum
+		if e.pkStatus == pkColIsUnsigned {
+			handleDatum = types.NewUintDatum(uint64(handle))
+		} else {
+			handleDatum = types.NewIntDatum(handle)
+		}
+		handleBytes, err := codec.EncodeValue(nil, handleDatum)
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
+		values = append(values, handleBytes)
 	}
 
 	return values, nil
@@ -265,10 +244,10 @@ type selectionExec struct {
 	row               []types.Datum
 	evalCtx           *evalContext
 
-	src executor
+	src Executor
 }
 
-func (e *selectionExec) SetSrcExec(exec executor) {
+func (e *selectionExec) SetSrcExec(exec Executor) {
 	e.src = exec
 }
 
@@ -318,12 +297,10 @@ func (e *selectionExec) Next() (value [][]byte, err error) {
 	}
 }
 
-type aggCtxsMapper map[string][]*aggregation.AggEvaluateContext
-
 type aggregateExec struct {
 	evalCtx           *evalContext
 	aggExprs          []aggregation.Aggregation
-	aggCtxsMap        aggCtxsMapper
+	aggCtxsMap        map[string][]*aggregation.AggEvaluateContext
 	groupByExprs      []expression.Expression
 	relatedColOffsets []int
 	row               []types.Datum
@@ -333,46 +310,32 @@ type aggregateExec struct {
 	executed          bool
 	currGroupIdx      int
 
-	src executor
+	src Executor
 }
 
-func (e *aggregateExec) SetSrcExec(exec executor) {
+func (e *aggregateExec) SetSrcExec(exec Executor) {
 	e.src = exec
 }
 
-func (e *aggregateExec) innerNext() (bool, error) {
-	values, err := e.src.Next()
-	if err != nil {
-		return false, errors.Trace(err)
-	}
-	if values == nil {
-		return false, nil
-	}
-	err = e.aggregate(values)
-	if err != nil {
-		return false, errors.Trace(err)
-	}
-	return true, nil
-}
-
 func (e *aggregateExec) Next() (value [][]byte, err error) {
-	if e.aggCtxsMap == nil {
-		e.aggCtxsMap = make(aggCtxsMapper, 0)
-	}
 	if !e.executed {
+		e.aggCtxsMap = make(map[string][]*aggregation.AggEvaluateContext)
 		for {
-			hasMore, err := e.innerNext()
+			values, err := e.src.Next()
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
-			if !hasMore {
+			if values == nil {
 				break
 			}
+			if err = e.aggregate(values); err != nil {
+				return nil, errors.Trace(err)
+			}
 		}
 		e.executed = true
 	}
 
-	if e.currGroupIdx >= len(e.groups) {
+	if e.currGroupIdx >= len(e.groupKeys) {
 		return nil, nil
 	}
 	gk := e.groupKeys[e.currGroupIdx]
@@ -399,26 +362,20 @@ func (e *aggregateExec) getGroupKey() ([]byte, [][]byte, error) {
 	if length == 0 {
 		return nil, nil, nil
 	}
-	bufLen := 0
-	vals := make([]types.Datum, 0, length)
+	var buf []byte
 	row := make([][]byte, 0, length)
 	for _, item := range e.groupByExprs {
 		v, err := item.Eval(e.row)
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
-		vals = append(vals, v)
 		b, err := codec.EncodeValue(nil, v)
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
-		bufLen += len(b)
+		buf = append(buf, b...)
 		row = append(row, b)
 	}
-	buf := make([]byte, 0, bufLen)
-	for _, col := range row {
-		buf = append(buf, col...)
-	}
 	return buf, row, nil
 }
 
@@ -450,14 +407,13 @@ func (e *aggregateExec) aggregate(value [][]byte) error {
 }
 
 func (e *aggregateExec) getContexts(groupKey []byte) []*aggregation.AggEvaluateContext {
-	groupKeyString := string(groupKey)
-	aggCtxs, ok := e.aggCtxsMap[groupKeyString]
+	aggCtxs, ok := e.aggCtxsMap[string(groupKey)]
 	if !ok {
 		aggCtxs = make([]*aggregation.AggEvaluateContext, 0, len(e.aggExprs))
 		for _, agg := range e.aggExprs {
 			aggCtxs = append(aggCtxs, agg.CreateContext())
 		}
-		e.aggCtxsMap[groupKeyString] = aggCtxs
+		e.aggCtxsMap[string(groupKey)] = aggCtxs
 	}
 	return aggCtxs
 }
@@ -471,45 +427,36 @@ type topNExec struct {
 	cursor            int
 	executed          bool
 
-	src executor
+	src Executor
 }
 
-func (e *topNExec) SetSrcExec(src executor) {
+func (e *topNExec) SetSrcExec(src Executor) {
 	e.src = src
 }
 
-func (e *topNExec) innerNext() (bool, error) {
-	value, err := e.src.Next()
-	if err != nil {
-		return false, errors.Trace(err)
-	}
-	if value == nil {
-		return false, nil
-	}
-	err = e.evalTopN(value)
-	if err != nil {
-		return false, errors.Trace(err)
-	}
-	return true, nil
-}
-
 func (e *topNExec) Next() (value [][]byte, err error) {
 	if !e.executed {
 		for {
-			hasMore, err := e.innerNext()
+			value, err = e.src.Next()
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
-			if !hasMore {
+			if value == nil {
 				break
 			}
+			if err = e.evalTopN(value); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		sort.Sort(&e.heap.topNSorter)
+		if e.heap.err != nil {
+			return nil, errors.Trace(e.heap.err)
 		}
 		e.executed = true
 	}
 	if e.cursor >= len(e.heap.rows) {
 		return nil, nil
 	}
-	sort.Sort(&e.heap.topNSorter)
 	row := e.heap.rows[e.cursor]
 	e.cursor++
 
@@ -520,7 +467,7 @@ func (e *topNExec) Next() (value [][]byte, err error) {
 // And this function will check if this record can replace one of the old records.
 func (e *topNExec) evalTopN(value [][]byte) error {
 	newRow := &sortRow{
-		key: make([]types.Datum, len(value)),
+		key: make([]types.Datum, len(e.orderByExprs)),
 	}
 	err := e.evalCtx.decodeRelatedColumnVals(e.relatedColOffsets, value, e.row)
 	if err != nil {
@@ -531,12 +478,11 @@ func (e *topNExec) evalTopN(value [][]byte) error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+		expression.SetDatumCollation(expr, &newRow.key[i])
 	}
 
 	if e.heap.tryToAddRow(newRow) {
-		for _, val := range value {
-			newRow.data = append(newRow.data, val)
-		}
+		newRow.data = value
 	}
 	return errors.Trace(e.heap.err)
 }
@@ -545,10 +491,10 @@ type limitExec struct {
 	limit  uint64
 	cursor uint64
 
-	src executor
+	src Executor
 }
 
-func (e *limitExec) SetSrcExec(src executor) {
+func (e *limitExec) SetSrcExec(src Executor) {
 	e.src = src
 }
 
@@ -568,16 +514,9 @@ func (e *limitExec) Next() (value [][]byte, err error) {
 	return value, nil
 }
 
-func hasColVal(data [][]byte, colIDs map[int64]int, id int64) bool {
-	offset, ok := colIDs[id]
-	if ok && data[offset] != nil {
-		return true
-	}
-	return false
-}
-
-// getRowData decodes raw byte slice to row data.
-func getRowData(columns []*tipb.ColumnInfo, colIDs map[int64]int, handle int64, value []byte) ([][]byte, error) {
+// cutRowData cuts the raw row data into the values of the columns, the handle and the missing columns are filled.
+// The strings of the columns in the transcoded charsets are decoded to UTF-8.
+func cutRowData(columns []*tipb.ColumnInfo, colIDs map[int64]int, fieldTps []*types.FieldType, handle int64, value []byte) ([][]byte, error) {
 	values, err := tablecodec.CutRowNew(value, colIDs)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -604,7 +543,11 @@ func getRowData(columns []*tipb.ColumnInfo, colIDs map[int64]int, handle int64,
 			values[offset] = handleData
 			continue
 		}
-		if hasColVal(values, colIDs, id) {
+		if values[offset] != nil {
+			values[offset], err = tablecodec.DecodeCharsetValue(values[offset], fieldTps[offset])
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
 			continue
 		}
 		if len(col.DefaultVal) > 0 {
@@ -621,18 +564,6 @@ func getRowData(columns []*tipb.ColumnInfo, colIDs map[int64]int, handle int64,
 	return values, nil
 }
 
-func convertToExprs(sc *variable.StatementContext, fieldTps []*types.FieldType, pbExprs []*tipb.Expr) ([]expression.Expression, error) {
-	exprs := make([]expression.Expression, 0, len(pbExprs))
-	for _, expr := range pbExprs {
-		e, err := expression.PBToExpr(expr, fieldTps, sc)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		exprs = append(exprs, e)
-	}
-	return exprs, nil
-}
-
 func decodeHandle(data []byte) (int64, error) {
 	var h int64
 	buf := bytes.NewBuffer(data)
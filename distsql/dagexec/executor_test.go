@@ -0,0 +1,166 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dagexec
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-tipb"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testExecutorSuite{})
+
+type testExecutorSuite struct{}
+
+// memSnapshot is a Snapshot of the key-value pairs sorted by the keys.
+type memSnapshot struct {
+	keys   []kv.Key
+	values [][]byte
+}
+
+func (s *memSnapshot) Get(key kv.Key) ([]byte, error) {
+	for i, k := range s.keys {
+		if k.Cmp(key) == 0 {
+			return s.values[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memSnapshot) Scan(startKey, endKey kv.Key, desc bool) (kv.Key, []byte, error) {
+	for i := range s.keys {
+		if desc {
+			i = len(s.keys) - i - 1
+		}
+		if s.keys[i].Cmp(startKey) >= 0 && s.keys[i].Cmp(endKey) < 0 {
+			return s.keys[i], s.values[i], nil
+		}
+	}
+	return nil, nil, nil
+}
+
+const tableID = 1
+
+// newTableSnapshot returns the snapshot of a table whose rows are (handle, handle*10).
+func newTableSnapshot(c *C, handles ...int64) *memSnapshot {
+	snap := &memSnapshot{}
+	for _, h := range handles {
+		value, err := tablecodec.EncodeRow([]types.Datum{types.NewIntDatum(h * 10)}, []int64{2}, time.UTC)
+		c.Assert(err, IsNil)
+		snap.keys = append(snap.keys, tablecodec.EncodeRowKeyWithHandle(tableID, h))
+		snap.values = append(snap.values, value)
+	}
+	return snap
+}
+
+func tableColumns() []*tipb.ColumnInfo {
+	return []*tipb.ColumnInfo{
+		{ColumnId: 1, Tp: int32(mysql.TypeLonglong), PkHandle: true},
+		{ColumnId: 2, Tp: int32(mysql.TypeLonglong)},
+	}
+}
+
+func handleRange(start, end int64) kv.KeyRange {
+	return kv.KeyRange{
+		StartKey: tablecodec.EncodeRowKeyWithHandle(tableID, start),
+		EndKey:   tablecodec.EncodeRowKeyWithHandle(tableID, end),
+	}
+}
+
+// fetchColumn returns the int values of the column at offset in the rows of the executor.
+func fetchColumn(c *C, e Executor, offset int) []int64 {
+	var vals []int64
+	for {
+		row, err := e.Next()
+		c.Assert(err, IsNil)
+		if row == nil {
+			return vals
+		}
+		_, d, err := codec.DecodeOne(row[offset])
+		c.Assert(err, IsNil)
+		vals = append(vals, d.GetInt64())
+	}
+}
+
+func (s *testExecutorSuite) TestTableScan(c *C) {
+	defer testleak.AfterTest(c)()
+	snap := newTableSnapshot(c, 1, 2, 4, 5)
+	ranges := []kv.KeyRange{handleRange(2, 3), handleRange(3, 4), handleRange(4, 10)}
+	for i := range ranges[:2] {
+		ranges[i].EndKey = ranges[i].StartKey.PrefixNext()
+	}
+	tests := []struct {
+		desc    bool
+		handles []int64
+	}{
+		// The missing row of a point range is skipped.
+		{false, []int64{2, 4, 5}},
+		{true, []int64{5, 4, 2}},
+	}
+	for _, tt := range tests {
+		dagReq := &tipb.DAGRequest{
+			Executors: []*tipb.Executor{{
+				Tp:      tipb.ExecType_TypeTableScan,
+				TblScan: &tipb.TableScan{TableId: tableID, Columns: tableColumns(), Desc: tt.desc},
+			}},
+		}
+		e, err := Build(snap, dagReq, ranges)
+		c.Assert(err, IsNil)
+		c.Assert(fetchColumn(c, e, 0), DeepEquals, tt.handles, Commentf("desc %v", tt.desc))
+	}
+
+	e := NewTableScan(snap, tableColumns(), []kv.KeyRange{handleRange(0, 10)})
+	c.Assert(fetchColumn(c, e, 1), DeepEquals, []int64{10, 20, 40, 50})
+}
+
+func (s *testExecutorSuite) TestTopNAndLimit(c *C) {
+	defer testleak.AfterTest(c)()
+	snap := newTableSnapshot(c, 3, 1, 4, 2)
+	colRef := &tipb.Expr{Tp: tipb.ExprType_ColumnRef, Val: codec.EncodeInt(nil, 1)}
+	dagReq := &tipb.DAGRequest{
+		Executors: []*tipb.Executor{
+			{
+				Tp:      tipb.ExecType_TypeTableScan,
+				TblScan: &tipb.TableScan{TableId: tableID, Columns: tableColumns()},
+			},
+			{
+				Tp: tipb.ExecType_TypeTopN,
+				TopN: &tipb.TopN{
+					OrderBy: []*tipb.ByItem{{Expr: colRef, Desc: true}},
+					Limit:   3,
+				},
+			},
+			{
+				Tp:    tipb.ExecType_TypeLimit,
+				Limit: &tipb.Limit{Limit: 2},
+			},
+		},
+	}
+	e, err := Build(snap, dagReq, []kv.KeyRange{handleRange(0, 10)})
+	c.Assert(err, IsNil)
+	c.Assert(fetchColumn(c, e, 1), DeepEquals, []int64{40, 30})
+}
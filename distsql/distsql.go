@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/goroutine_pool"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
@@ -277,7 +278,7 @@ const (
 
 // FieldTypeFromPBColumn creates a types.FieldType from tipb.ColumnInfo.
 func FieldTypeFromPBColumn(col *tipb.ColumnInfo) *types.FieldType {
-	return &types.FieldType{
+	ft := &types.FieldType{
 		Tp:      byte(col.GetTp()),
 		Flag:    uint(col.Flag),
 		Flen:    int(col.GetColumnLen()),
@@ -285,15 +286,26 @@ func FieldTypeFromPBColumn(col *tipb.ColumnInfo) *types.FieldType {
 		Elems:   col.Elems,
 		Collate: mysql.Collations[uint8(col.GetCollation())],
 	}
+	// The charset is needed to decode the stored strings.
+	if cs, _, err := charset.GetCharsetInfoByID(int(col.GetCollation())); err == nil {
+		ft.Charset = cs
+	}
+	return ft
 }
 
-func columnToProto(c *model.ColumnInfo) *tipb.ColumnInfo {
+func columnToProto(c *model.ColumnInfo, tblInfo *model.TableInfo) *tipb.ColumnInfo {
+	// The coprocessor decodes the stored strings by the storage type of the column.
+	flag := tblInfo.StorageType(c).Flag
+	// The virtual generated columns aren't stored in the rows, they are read as NULL and calculated later.
+	if c.IsGenerated() && !c.GeneratedStored {
+		flag &^= mysql.NotNullFlag
+	}
 	pc := &tipb.ColumnInfo{
 		ColumnId:  c.ID,
 		Collation: collationToProto(c.FieldType.Collate),
 		ColumnLen: int32(c.FieldType.Flen),
 		Decimal:   int32(c.FieldType.Decimal),
-		Flag:      int32(c.Flag),
+		Flag:      int32(flag),
 		Elems:     c.Elems,
 	}
 	pc.Tp = int32(c.FieldType.Tp)
@@ -308,14 +320,14 @@ func collationToProto(c string) int32 {
 	return int32(mysql.DefaultCollationID)
 }
 
-// ColumnsToProto converts a slice of model.ColumnInfo to a slice of tipb.ColumnInfo.
-func ColumnsToProto(columns []*model.ColumnInfo, pkIsHandle bool) []*tipb.ColumnInfo {
+// ColumnsToProto converts a slice of model.ColumnInfo of the table to a slice of tipb.ColumnInfo.
+func ColumnsToProto(columns []*model.ColumnInfo, tblInfo *model.TableInfo) []*tipb.ColumnInfo {
 	cols := make([]*tipb.ColumnInfo, 0, len(columns))
 	for _, c := range columns {
-		col := columnToProto(c)
+		col := columnToProto(c, tblInfo)
 		// TODO: Here `PkHandle`'s meaning is changed, we will change it to `IsHandle` when tikv's old select logic
 		// is abandoned.
-		if (pkIsHandle && mysql.HasPriKeyFlag(c.Flag)) || c.ID == model.ExtraHandleID {
+		if (tblInfo.PKIsHandle && mysql.HasPriKeyFlag(c.Flag)) || c.ID == model.ExtraHandleID {
 			col.PkHandle = true
 		} else {
 			col.PkHandle = false
@@ -334,13 +346,13 @@ func IndexToProto(t *model.TableInfo, idx *model.IndexInfo) *tipb.IndexInfo {
 	}
 	cols := make([]*tipb.ColumnInfo, 0, len(idx.Columns)+1)
 	for _, c := range idx.Columns {
-		cols = append(cols, columnToProto(t.Columns[c.Offset]))
+		cols = append(cols, columnToProto(t.Columns[c.Offset], t))
 	}
 	if t.PKIsHandle {
 		// Coprocessor needs to know PKHandle column info, so we need to append it.
 		for _, col := range t.Columns {
 			if mysql.HasPriKeyFlag(col.Flag) {
-				colPB := columnToProto(col)
+				colPB := columnToProto(col, t)
 				colPB.PkHandle = true
 				cols = append(cols, colPB)
 				break
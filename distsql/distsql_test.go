@@ -42,24 +42,41 @@ func (s *testDistsqlSuite) TestColumnToProto(c *C) {
 	// Make sure the Flag is set in tipb.ColumnInfo
 	tp := types.NewFieldType(mysql.TypeLong)
 	tp.Flag = 10
+	tp.Charset = "utf8"
 	tp.Collate = "utf8_bin"
 	col := &model.ColumnInfo{
 		FieldType: *tp,
 	}
-	pc := columnToProto(col)
+	tbInfo := &model.TableInfo{Version: model.CurrLatestTableInfoVersion}
+	pc := columnToProto(col, tbInfo)
 	c.Assert(pc.GetFlag(), Equals, int32(10))
 	ntp := FieldTypeFromPBColumn(pc)
 	c.Assert(ntp, DeepEquals, tp)
 
 	cols := []*model.ColumnInfo{col, col}
-	pcs := ColumnsToProto(cols, false)
+	pcs := ColumnsToProto(cols, tbInfo)
 	for _, v := range pcs {
 		c.Assert(v.GetFlag(), Equals, int32(10))
 	}
-	pcs = ColumnsToProto(cols, true)
+	tbInfo.PKIsHandle = true
+	pcs = ColumnsToProto(cols, tbInfo)
 	for _, v := range pcs {
 		c.Assert(v.GetFlag(), Equals, int32(10))
 	}
+
+	// The strings of the tables before TableInfoVersion1 are stored in UTF-8.
+	tp = types.NewFieldType(mysql.TypeVarchar)
+	tp.Charset = "latin1"
+	tp.Collate = "latin1_bin"
+	col = &model.ColumnInfo{
+		FieldType: *tp,
+	}
+	pc = columnToProto(col, &model.TableInfo{Version: model.TableInfoVersion0})
+	ntp = FieldTypeFromPBColumn(pc)
+	c.Assert(mysql.HasLegacyStorageFlag(ntp.Flag), IsTrue)
+	c.Assert(types.IsTranscodedStr(ntp), IsFalse)
+	pc = columnToProto(col, tbInfo)
+	c.Assert(types.IsTranscodedStr(FieldTypeFromPBColumn(pc)), IsTrue)
 }
 
 func (s *testDistsqlSuite) TestIndexToProto(c *C) {
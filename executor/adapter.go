@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/slowlog"
 )
 
 type processinfoSetter interface {
@@ -43,6 +44,7 @@ type recordSet struct {
 	stmt        *statement
 	processinfo processinfoSetter
 	err         error
+	closed      bool
 }
 
 func (a *recordSet) Fields() ([]*ast.ResultField, error) {
@@ -82,6 +84,11 @@ func (a *recordSet) Next() (*ast.Row, error) {
 }
 
 func (a *recordSet) Close() error {
+	// The record set may be closed more than once, the statement should be finished only once.
+	if a.closed {
+		return nil
+	}
+	a.closed = true
 	err := a.executor.Close()
 	a.stmt.logSlowQuery()
 	if a.processinfo != nil {
@@ -110,6 +117,110 @@ func (a *statement) IsPrepared() bool {
 	return a.isPreparedStmt
 }
 
+// secureText returns the statement text with the password information hidden.
+func (a *statement) secureText() string {
+	sql := a.OriginText()
+	if simple, ok := a.plan.(*plan.Simple); ok && simple.Statement != nil {
+		if ss, ok := simple.Statement.(ast.SensitiveStmtNode); ok {
+			// Use SecureText to avoid leak password information.
+			sql = ss.SecureText()
+		}
+	}
+	return sql
+}
+
+// StmtEventName returns the performance_schema event name of the statement node, same as MySQL.
+func StmtEventName(node ast.StmtNode) string {
+	var name string
+	switch x := node.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+		name = "select"
+	case *ast.InsertStmt:
+		if x.IsReplace {
+			name = "replace"
+		} else {
+			name = "insert"
+		}
+	case *ast.UpdateStmt:
+		name = "update"
+	case *ast.DeleteStmt:
+		name = "delete"
+	case *ast.LoadDataStmt:
+		name = "load"
+	case *ast.CreateDatabaseStmt:
+		name = "create_db"
+	case *ast.DropDatabaseStmt:
+		name = "drop_db"
+	case *ast.CreateTableStmt:
+		name = "create_table"
+	case *ast.DropTableStmt:
+		name = "drop_table"
+	case *ast.CreateViewStmt:
+		name = "create_view"
+	case *ast.RenameTableStmt:
+		name = "rename_table"
+	case *ast.CreateIndexStmt:
+		name = "create_index"
+	case *ast.DropIndexStmt:
+		name = "drop_index"
+	case *ast.AlterTableStmt:
+		name = "alter_table"
+	case *ast.TruncateTableStmt:
+		name = "truncate"
+	case *ast.ShowStmt:
+		name = "show"
+	case *ast.ExplainStmt:
+		name = "explain"
+	case *ast.PrepareStmt:
+		name = "prepare_sql"
+	case *ast.ExecuteStmt:
+		name = "execute_sql"
+	case *ast.DeallocateStmt:
+		name = "dealloc_sql"
+	case *ast.BeginStmt:
+		name = "begin"
+	case *ast.CommitStmt:
+		name = "commit"
+	case *ast.RollbackStmt:
+		name = "rollback"
+	case *ast.SavepointStmt:
+		name = "savepoint"
+	case *ast.ReleaseSavepointStmt:
+		name = "release_savepoint"
+	case *ast.UseStmt:
+		name = "change_db"
+	case *ast.FlushStmt:
+		name = "flush"
+	case *ast.KillStmt:
+		name = "kill"
+	case *ast.SetStmt:
+		name = "set_option"
+	case *ast.SetPwdStmt:
+		name = "set_password"
+	case *ast.CreateUserStmt:
+		name = "create_user"
+	case *ast.AlterUserStmt:
+		name = "alter_user"
+	case *ast.DropUserStmt:
+		name = "drop_user"
+	case *ast.DoStmt:
+		name = "do"
+	case *ast.GrantStmt, *ast.GrantRoleStmt:
+		name = "grant"
+	case *ast.RevokeStmt, *ast.RevokeRoleStmt:
+		name = "revoke"
+	case *ast.SetRoleStmt:
+		name = "set_role"
+	case *ast.SetDefaultRoleStmt:
+		name = "set_default_role"
+	case *ast.AnalyzeTableStmt:
+		name = "analyze"
+	default:
+		name = "other"
+	}
+	return "statement/sql/" + name
+}
+
 // Exec implements the ast.Statement Exec interface.
 // This function builds an Executor from a plan. If the Executor doesn't return result,
 // like the INSERT, UPDATE statements, it executes in this function, if the Executor returns
@@ -147,15 +258,8 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 	var pi processinfoSetter
 	if raw, ok := ctx.(processinfoSetter); ok {
 		pi = raw
-		sql := a.OriginText()
-		if simple, ok := a.plan.(*plan.Simple); ok && simple.Statement != nil {
-			if ss, ok := simple.Statement.(ast.SensitiveStmtNode); ok {
-				// Use SecureText to avoid leak password information.
-				sql = ss.SecureText()
-			}
-		}
 		// Update processinfo, ShowProcess() will use it.
-		pi.SetProcessInfo(sql)
+		pi.SetProcessInfo(a.secureText())
 	}
 	// Fields or Schema are only used for statements that return result set.
 	if e.Schema().Len() == 0 {
@@ -259,11 +363,12 @@ func (a *statement) buildExecutor(ctx context.Context) (Executor, error) {
 func (a *statement) logSlowQuery() {
 	cfg := config.GetGlobalConfig()
 	costTime := time.Since(a.startTime)
-	sql := a.text
+	sql := a.secureText()
 	if len(sql) > cfg.Log.QueryLogMaxLen {
 		sql = sql[:cfg.Log.QueryLogMaxLen] + fmt.Sprintf("(len:%d)", len(sql))
 	}
-	connID := a.ctx.GetSessionVars().ConnectionID
+	sessVars := a.ctx.GetSessionVars()
+	connID := sessVars.ConnectionID
 	logEntry := log.WithFields(log.Fields{
 		"connectionId": connID,
 		"costTime":     costTime,
@@ -271,8 +376,32 @@ func (a *statement) logSlowQuery() {
 	})
 	if costTime < time.Duration(cfg.Log.SlowThreshold)*time.Millisecond {
 		logEntry.WithField("type", "query").Debugf("query")
-	} else {
+		return
+	}
+	if !slowlog.Enabled() {
 		logEntry.WithField("type", "slow-query").Warnf("slow-query")
+		return
+	}
+	sc := sessVars.StmtCtx
+	entry := &slowlog.Entry{
+		Time:         time.Now(),
+		ConnID:       connID,
+		QueryTime:    costTime,
+		LockTime:     sc.LockTime(),
+		RowsSent:     sc.FoundRows(),
+		RowsExamined: sc.ExaminedRows(),
+		TxnStartTS:   sessVars.TxnCtx.StartTS,
+		DB:           sessVars.CurrentDB,
+		IndexNames:   plan.UsedIndexNames(a.plan),
+		Plan:         plan.ExplainTree(a.plan),
+		Query:        sql,
+	}
+	if sessVars.User != nil {
+		entry.User, entry.Host = sessVars.User.Username, sessVars.User.Hostname
+	}
+	entry.CopTasks, entry.CopProcessTime, entry.CopWaitTime = sc.CopTaskStats()
+	if err := slowlog.Write(entry); err != nil {
+		log.Errorf("[%d] write slow query log error: %v", connID, errors.ErrorStack(err))
 	}
 }
 
@@ -14,12 +14,18 @@
 package executor
 
 import (
+	"io/ioutil"
+	"os"
+	"unsafe"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/expression/aggregation"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/mvmap"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -41,13 +47,37 @@ type HashAggExec struct {
 	groupMap      *mvmap.MVMap
 	groupIterator *mvmap.Iterator
 	GroupByItems  []expression.Expression
+	// groupingSets is not empty for GROUP BY ... WITH ROLLUP, every row is aggregated into a group of every grouping
+	// set, and the group keys are prefixed by the index of the grouping set.
+	groupingSets []*plan.GroupingSet
+
+	// memTracker tracks the memory used by the groups. When the memory quota of the statement is exceeded,
+	// the rows of the groups not in memory are written into partition files by the group key, and the
+	// partitions are aggregated one at a time after the groups in memory are returned.
+	memTracker   *memory.Tracker
+	spillDir     string
+	partitions   *partitionFiles
+	partitionIdx int
+	rowBuf       []byte
 }
 
+// aggCtxMemUsage is the estimated memory used by the context of an aggregate function.
+const aggCtxMemUsage = int64(unsafe.Sizeof(aggregation.AggEvaluateContext{}))
+
 // Close implements the Executor Close interface.
 func (e *HashAggExec) Close() error {
 	e.groupMap = nil
 	e.groupIterator = nil
 	e.aggCtxsMap = nil
+	if e.partitions != nil {
+		terror.Log(errors.Trace(e.partitions.close()))
+		terror.Log(errors.Trace(os.RemoveAll(e.spillDir)))
+		e.partitions = nil
+	}
+	if e.memTracker != nil {
+		e.memTracker.Detach()
+		e.memTracker = nil
+	}
 	return errors.Trace(e.children[0].Close())
 }
 
@@ -57,6 +87,10 @@ func (e *HashAggExec) Open() error {
 	e.groupMap = mvmap.NewMVMap()
 	e.groupIterator = e.groupMap.NewIterator()
 	e.aggCtxsMap = make(aggCtxsMapper, 0)
+	e.partitions = nil
+	e.partitionIdx = 0
+	e.memTracker = memory.NewTracker("HashAgg", -1)
+	e.memTracker.AttachTo(e.ctx.GetSessionVars().StmtCtx.MemTracker)
 	return errors.Trace(e.children[0].Open())
 }
 
@@ -83,17 +117,63 @@ func (e *HashAggExec) Next() (Row, error) {
 		e.executed = true
 	}
 	groupKey, _ := e.groupIterator.Next()
-	if groupKey == nil {
-		return nil, nil
+	for groupKey == nil {
+		if e.partitions == nil || e.partitionIdx >= spillPartitions {
+			return nil, nil
+		}
+		if err := e.aggregatePartition(e.partitionIdx); err != nil {
+			return nil, errors.Trace(err)
+		}
+		e.partitionIdx++
+		groupKey, _ = e.groupIterator.Next()
 	}
 	retRow := make([]types.Datum, 0, len(e.AggFuncs))
 	aggCtxs := e.getContexts(groupKey)
 	for i, af := range e.AggFuncs {
 		retRow = append(retRow, af.GetResult(aggCtxs[i]))
 	}
+	if len(e.groupingSets) > 0 {
+		_, setIdx, err := codec.DecodeInt(groupKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for i, d := range e.groupingSets[setIdx].Outputs {
+			retRow[i] = d
+		}
+	}
 	return retRow, nil
 }
 
+// getGroupKeys returns the group keys of row in all the grouping sets.
+func (e *HashAggExec) getGroupKeys(row Row) ([][]byte, error) {
+	if len(e.groupingSets) == 0 {
+		groupKey, err := e.getGroupKey(row)
+		return [][]byte{groupKey}, errors.Trace(err)
+	}
+	vals := make([]types.Datum, 0, len(e.GroupByItems))
+	for _, item := range e.GroupByItems {
+		v, err := item.Eval(row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		vals = append(vals, v)
+	}
+	groupKeys := make([][]byte, 0, len(e.groupingSets))
+	setVals := make([]types.Datum, 0, len(vals))
+	for i, set := range e.groupingSets {
+		setVals = setVals[:0]
+		for _, idx := range set.Items {
+			setVals = append(setVals, vals[idx])
+		}
+		groupKey, err := codec.EncodeValue(codec.EncodeInt(nil, int64(i)), setVals...)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		groupKeys = append(groupKeys, groupKey)
+	}
+	return groupKeys, nil
+}
+
 func (e *HashAggExec) getGroupKey(row Row) ([]byte, error) {
 	if e.aggType == plan.FinalAgg && !plan.UseDAGPlanBuilder(e.ctx) {
 		val, err := e.GroupByItems[0].Eval(row)
@@ -131,21 +211,101 @@ func (e *HashAggExec) innerNext() (ret bool, err error) {
 		return false, nil
 	}
 	e.executed = true
-	groupKey, err := e.getGroupKey(srcRow)
+	groupKeys, err := e.getGroupKeys(srcRow)
 	if err != nil {
 		return false, errors.Trace(err)
 	}
+	for _, groupKey := range groupKeys {
+		if err = e.aggregateRow(groupKey, srcRow); err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+	return true, nil
+}
+
+// aggregateRow updates the group of groupKey by row, or spills the row if the group isn't in memory.
+func (e *HashAggExec) aggregateRow(groupKey []byte, row Row) error {
+	var err error
 	if e.groupMap.Get(groupKey) == nil {
-		e.groupMap.Put(groupKey, []byte{})
+		if e.partitions != nil {
+			e.rowBuf, err = encodeRowValues(e.rowBuf[:0], row, e.ctx.GetSessionVars().GetTimeZone())
+			if err != nil {
+				return errors.Trace(err)
+			}
+			return errors.Trace(e.partitions.write(e.partitions.partition(groupKey), groupKey, e.rowBuf))
+		}
+		e.putGroup(groupKey)
+		if e.hasGby && e.memTracker.Exceeded() {
+			if err = e.startSpill(); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
+	return errors.Trace(e.updateGroup(groupKey, row))
+}
+
+func (e *HashAggExec) putGroup(groupKey []byte) {
+	e.groupMap.Put(groupKey, []byte{})
+	// The group key is stored in both the group map and the context map.
+	e.memTracker.Consume(int64(2*len(groupKey)) + aggCtxMemUsage*int64(len(e.AggFuncs)))
+}
+
+func (e *HashAggExec) updateGroup(groupKey []byte, row Row) error {
 	aggCtxs := e.getContexts(groupKey)
 	for i, af := range e.AggFuncs {
-		err = af.Update(aggCtxs[i], e.sc, srcRow)
+		err := af.Update(aggCtxs[i], e.sc, row)
 		if err != nil {
-			return false, errors.Trace(err)
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// startSpill creates the partition files, the groups already in memory keep being aggregated in memory.
+func (e *HashAggExec) startSpill() error {
+	var err error
+	e.spillDir, err = ioutil.TempDir("", "tidb-agg-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.partitions, err = newPartitionFiles(e.spillDir, "agg", spillPartitions)
+	if err != nil {
+		terror.Log(errors.Trace(os.RemoveAll(e.spillDir)))
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// aggregatePartition replaces the groups in memory with the groups of the rows in partition idx.
+func (e *HashAggExec) aggregatePartition(idx int) error {
+	e.groupMap = mvmap.NewMVMap()
+	e.groupIterator = e.groupMap.NewIterator()
+	e.aggCtxsMap = make(aggCtxsMapper, 0)
+	e.memTracker.Consume(-e.memTracker.BytesConsumed())
+	reader, err := e.partitions.reader(idx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	schema, loc := e.children[0].Schema(), e.ctx.GetSessionVars().GetTimeZone()
+	for {
+		groupKey, value, ok, err := reader.next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !ok {
+			return nil
+		}
+		row, err := decodeRowValues(value, schema, loc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if e.groupMap.Get(groupKey) == nil {
+			e.putGroup(groupKey)
+		}
+		if err = e.updateGroup(groupKey, row); err != nil {
+			return errors.Trace(err)
 		}
 	}
-	return true, nil
 }
 
 func (e *HashAggExec) getContexts(groupKey []byte) []*aggregation.AggEvaluateContext {
@@ -14,11 +14,14 @@
 package executor_test
 
 import (
+	"fmt"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 )
 
@@ -275,7 +278,7 @@ func (s *testSuite) TestAggregation(c *C) {
 
 	result = tk.MustQuery("select count(*) from information_schema.columns")
 	// When adding new memory table in information_schema, please update this variable.
-	columnCountOfAllInformationSchemaTables := "742"
+	columnCountOfAllInformationSchemaTables := "800"
 	result.Check(testkit.Rows(columnCountOfAllInformationSchemaTables))
 
 	tk.MustExec("drop table if exists t1")
@@ -349,6 +352,76 @@ func (s *testSuite) TestAggPushDown(c *C) {
 	tk.MustQuery("select a, count(b) from (select * from t union all select * from tt) k group by a").Check(testkit.Rows("1 2", "2 1"))
 }
 
+func (s *testSuite) TestHashAggSpill(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(20), c double)")
+	tk.MustExec("begin")
+	for i := 0; i < 500; i++ {
+		tk.MustExec(fmt.Sprintf("insert t values (%d, 'b%d', %d.5)", i%200, i%7, i))
+	}
+	tk.MustExec("insert t values (null, null, null)")
+	tk.MustExec("commit")
+
+	queries := []string{
+		"select a, count(*), sum(c), max(b) from t group by a order by a",
+		"select b, count(distinct a), avg(c) from t group by b order by b",
+		"select count(*), sum(a) from t",
+		"select distinct a % 50 from t order by 1",
+	}
+	var expected [][][]interface{}
+	for _, sql := range queries {
+		expected = append(expected, tk.MustQuery(sql).Rows())
+	}
+	tk.MustExec("set @@tidb_mem_quota_query = 1024")
+	for i, sql := range queries {
+		tk.MustQuery(sql).Check(expected[i])
+	}
+}
+
+func (s *testSuite) TestGroupByRollup(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t2, t3")
+	tk.MustExec("create table t (a int, b varchar(10), c int)")
+	tk.MustExec("insert t values (1, 'x', 10), (1, 'y', 20), (2, 'x', 30), (2, null, 40), (null, 'z', 50)")
+
+	tk.MustQuery("select a, b, sum(c) from t group by a, b with rollup order by grouping(a), a, grouping(b), b").Check(testkit.Rows(
+		"<nil> z 50", "<nil> <nil> 50",
+		"1 x 10", "1 y 20", "1 <nil> 30",
+		"2 <nil> 40", "2 x 30", "2 <nil> 70",
+		"<nil> <nil> 150"))
+	tk.MustQuery("select a, b, grouping(a), grouping(b), grouping(a, b) from t group by a, b with rollup order by grouping(a, b), a, b").Check(testkit.Rows(
+		"<nil> z 0 0 0", "1 x 0 0 0", "1 y 0 0 0", "2 <nil> 0 0 0", "2 x 0 0 0",
+		"<nil> <nil> 0 1 1", "1 <nil> 0 1 1", "2 <nil> 0 1 1",
+		"<nil> <nil> 1 1 3"))
+	// The aggregate functions still aggregate the rolled up columns.
+	tk.MustQuery("select a, sum(a), count(*) from t group by a with rollup order by grouping(a), a").Check(testkit.Rows(
+		"<nil> <nil> 1", "1 2 2", "2 4 2", "<nil> 6 5"))
+	tk.MustQuery("select a + 1 as x, count(*) from t group by x with rollup having grouping(x) = 1").Check(testkit.Rows("<nil> 5"))
+	tk.MustQuery("select a, count(*) from t group by a with rollup having a = 1").Check(testkit.Rows("1 2"))
+	tk.MustQuery("select count(*) from t group by 'x' with rollup").Check(testkit.Rows("5", "5"))
+	tk.MustQuery("select a, count(*) from t where a > 5 group by a with rollup").Check(testkit.Rows())
+
+	tk.MustExec("create table t2 (a int primary key, d int)")
+	tk.MustExec("insert t2 values (1, 100), (2, 200)")
+	tk.MustQuery("select t.a, count(*), sum(t2.d) from t join t2 on t.a = t2.a group by t.a with rollup order by grouping(t.a), t.a").Check(testkit.Rows(
+		"1 2 200", "2 2 400", "<nil> 4 600"))
+	// The aggregation pushed down across the join groups by the base grouping set.
+	tk.MustExec("create table t3 (a int, e int)")
+	tk.MustExec("insert t3 values (1, 1), (1, 2), (2, 3)")
+	tk.MustExec("set @@tidb_opt_agg_push_down = 1")
+	tk.MustQuery("select t.a, t.b, sum(t.c), max(t3.e) from t join t3 on t.a = t3.a group by t.a, t.b with rollup order by grouping(t.a), t.a, grouping(t.b), t.b").Check(testkit.Rows(
+		"1 x 20 2", "1 y 40 2", "1 <nil> 60 2", "2 <nil> 40 3", "2 x 30 3", "2 <nil> 70 3", "<nil> <nil> 130 3"))
+	tk.MustExec("set @@tidb_opt_agg_push_down = 0")
+
+	_, err := tk.Exec("select grouping(a) from t group by a")
+	c.Assert(terror.ErrorEqual(err, plan.ErrInvalidGroupFuncUse), IsTrue)
+	_, err = tk.Exec("select grouping(c) from t group by a with rollup")
+	c.Assert(terror.ErrorEqual(err, plan.ErrFieldInGroupingNotGroupBy), IsTrue)
+}
+
 func (s *testSuite) TestHaving(c *C) {
 	tk := testkit.NewTestKitWithInit(c, s.store)
 
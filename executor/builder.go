@@ -43,6 +43,8 @@ type executorBuilder struct {
 	priority int
 	// err is set when there is error happened during Executor building process.
 	err error
+	// cteWorkTables maps the id of RecursiveCTE plan to its work table.
+	cteWorkTables map[int]*cteWorkTable
 }
 
 func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema, priority int) *executorBuilder {
@@ -73,6 +75,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildInsert(v)
 	case *plan.LoadData:
 		return b.buildLoadData(v)
+	case *plan.SelectInto:
+		return b.buildSelectInto(v)
 	case *plan.Limit:
 		return b.buildLimit(v)
 	case *plan.Prepare:
@@ -95,6 +99,10 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildTopN(v)
 	case *plan.Union:
 		return b.buildUnion(v)
+	case *plan.RecursiveCTE:
+		return b.buildRecursiveCTE(v)
+	case *plan.CTEWorkTable:
+		return b.buildCTEWorkTable(v)
 	case *plan.Update:
 		return b.buildUpdate(v)
 	case *plan.PhysicalUnionScan:
@@ -111,6 +119,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildSelection(v)
 	case *plan.PhysicalAggregation:
 		return b.buildAggregation(v)
+	case *plan.PhysicalWindow:
+		return b.buildWindow(v)
 	case *plan.Projection:
 		return b.buildProjection(v)
 	case *plan.PhysicalMemTable:
@@ -258,6 +268,7 @@ func (b *executorBuilder) buildShow(v *plan.Show) Executor {
 		Table:        v.Table,
 		Column:       v.Column,
 		User:         v.User,
+		Roles:        v.Roles,
 		Flag:         v.Flag,
 		Full:         v.Full,
 		GlobalScope:  v.GlobalScope,
@@ -265,6 +276,7 @@ func (b *executorBuilder) buildShow(v *plan.Show) Executor {
 	}
 	if e.Tp == ast.ShowGrants && e.User == nil {
 		e.User = e.ctx.GetSessionVars().User
+		e.Roles = e.ctx.GetSessionVars().ActiveRoles
 	}
 	return e
 }
@@ -381,7 +393,19 @@ func (b *executorBuilder) buildRevoke(revoke *ast.RevokeStmt) Executor {
 }
 
 func (b *executorBuilder) buildDDL(v *plan.DDL) Executor {
-	return &DDLExec{Statement: v.Statement, ctx: b.ctx, is: b.is}
+	return &DDLExec{Statement: v.Statement, ctx: b.ctx, is: b.is, viewCols: v.ViewCols, viewSelectCols: v.ViewSelectCols}
+}
+
+func (b *executorBuilder) buildSelectInto(v *plan.SelectInto) Executor {
+	src := b.build(v.TargetPlan)
+	if b.err != nil {
+		return nil
+	}
+	return &SelectIntoExec{
+		baseExecutor:  newBaseExecutor(v.Schema(), b.ctx, src),
+		intoOpt:       v.IntoOpt,
+		splitByRegion: b.ctx.GetSessionVars().OutfileSplitByRegion,
+	}
 }
 
 func (b *executorBuilder) buildExplain(v *plan.Explain) Executor {
@@ -599,11 +623,23 @@ func (b *executorBuilder) buildAggregation(v *plan.PhysicalAggregation) Executor
 		sc:           b.ctx.GetSessionVars().StmtCtx,
 		AggFuncs:     v.AggFuncs,
 		GroupByItems: v.GroupByItems,
+		groupingSets: v.GroupingSets,
 		aggType:      v.AggType,
 		hasGby:       v.HasGby,
 	}
 }
 
+func (b *executorBuilder) buildWindow(v *plan.PhysicalWindow) Executor {
+	return &WindowExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
+		sc:           b.ctx.GetSessionVars().StmtCtx,
+		windowFunc:   v.WindowFuncDesc,
+		partitionBy:  v.PartitionBy,
+		orderBy:      v.OrderBy,
+		frame:        v.Frame,
+	}
+}
+
 func (b *executorBuilder) buildSelection(v *plan.Selection) Executor {
 	exec := &SelectionExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
@@ -853,6 +889,40 @@ func (b *executorBuilder) buildUnion(v *plan.Union) Executor {
 	return e
 }
 
+func (b *executorBuilder) buildRecursiveCTE(v *plan.RecursiveCTE) Executor {
+	workTable := &cteWorkTable{}
+	if b.cteWorkTables == nil {
+		b.cteWorkTables = make(map[int]*cteWorkTable)
+	}
+	b.cteWorkTables[v.ID()] = workTable
+	seedExec := b.build(v.Children()[0])
+	if b.err != nil {
+		return nil
+	}
+	recursiveExec := b.build(v.Children()[1])
+	if b.err != nil {
+		return nil
+	}
+	return &RecursiveCTEExec{
+		baseExecutor:      newBaseExecutor(v.Schema(), b.ctx, seedExec, recursiveExec),
+		distinct:          v.Distinct,
+		maxRecursionDepth: b.ctx.GetSessionVars().CTEMaxRecursionDepth,
+		workTable:         workTable,
+	}
+}
+
+func (b *executorBuilder) buildCTEWorkTable(v *plan.CTEWorkTable) Executor {
+	workTable, ok := b.cteWorkTables[v.CTEID]
+	if !ok {
+		b.err = ErrBuildExecutor.Gen("cannot find the work table of recursive CTE %d", v.CTEID)
+		return nil
+	}
+	return &CTEWorkTableExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		workTable:    workTable,
+	}
+}
+
 func (b *executorBuilder) buildUpdate(v *plan.Update) Executor {
 	tblID2table := make(map[int64]table.Table)
 	for id := range v.Schema().TblID2Handle {
@@ -922,7 +992,7 @@ func (b *executorBuilder) buildTableScanForAnalyze(tblInfo *model.TableInfo, pk
 			Tp: tipb.ExecType_TypeTableScan,
 			TblScan: &tipb.TableScan{
 				TableId: tblInfo.ID,
-				Columns: distsql.ColumnsToProto(cols, tblInfo.PKIsHandle),
+				Columns: distsql.ColumnsToProto(cols, tblInfo),
 			},
 		})
 		b.err = setPBColumnsDefaultValue(b.ctx, e.dagPB.Executors[0].TblScan.Columns, cols)
@@ -980,7 +1050,7 @@ func (b *executorBuilder) buildIndexScanForAnalyze(tblInfo *model.TableInfo, idx
 			IdxScan: &tipb.IndexScan{
 				TableId: tblInfo.ID,
 				IndexId: idxInfo.ID,
-				Columns: distsql.ColumnsToProto(cols, tblInfo.PKIsHandle),
+				Columns: distsql.ColumnsToProto(cols, tblInfo),
 			},
 		})
 		b.err = setPBColumnsDefaultValue(b.ctx, e.dagPB.Executors[0].IdxScan.Columns, cols)
@@ -1055,7 +1125,7 @@ func (b *executorBuilder) buildAnalyzeColumnsPushdown(task plan.AnalyzeColumnsTa
 		BucketSize:  maxBucketSize,
 		SampleSize:  maxRegionSampleSize,
 		SketchSize:  maxSketchSize,
-		ColumnsInfo: distsql.ColumnsToProto(cols, task.TableInfo.PKIsHandle),
+		ColumnsInfo: distsql.ColumnsToProto(cols, task.TableInfo),
 	}
 	b.err = setPBColumnsDefaultValue(b.ctx, e.analyzePB.ColReq.ColumnsInfo, cols)
 	return e
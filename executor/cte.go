@@ -0,0 +1,176 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/codec"
+)
+
+// cteWorkTable holds the rows produced by the last iteration of a recursive CTE.
+type cteWorkTable struct {
+	rows []Row
+}
+
+// RecursiveCTEExec represents a recursive common table expression executor.
+// It returns the rows of the seed part first, then executes the recursive part repeatedly.
+// Every iteration reads the rows produced by the last iteration from the work table,
+// until an iteration produces no new rows.
+type RecursiveCTEExec struct {
+	baseExecutor
+
+	distinct          bool
+	maxRecursionDepth int
+	workTable         *cteWorkTable
+
+	// produced stores the rows produced by the current iteration.
+	produced []Row
+	// seen stores the encoded rows that have been returned, it's used when distinct is true.
+	seen           map[string]struct{}
+	iteration      int
+	seedDone       bool
+	recursiveOpen  bool
+	recursiveFinal bool
+}
+
+// Open implements the Executor Open interface.
+func (e *RecursiveCTEExec) Open() error {
+	e.produced = nil
+	e.seen = make(map[string]struct{})
+	e.iteration = 0
+	e.seedDone = false
+	e.recursiveOpen = false
+	e.recursiveFinal = false
+	e.workTable.rows = nil
+	return errors.Trace(e.children[0].Open())
+}
+
+// Next implements the Executor Next interface.
+func (e *RecursiveCTEExec) Next() (Row, error) {
+	for !e.seedDone {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			e.seedDone = true
+			break
+		}
+		ok, err := e.addRow(row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if ok {
+			return row, nil
+		}
+	}
+	for !e.recursiveFinal {
+		if !e.recursiveOpen {
+			if len(e.produced) == 0 {
+				e.recursiveFinal = true
+				break
+			}
+			e.iteration++
+			if e.iteration > e.maxRecursionDepth {
+				return nil, ErrCTEMaxRecursionDepth.GenByArgs(e.maxRecursionDepth)
+			}
+			e.workTable.rows, e.produced = e.produced, nil
+			if err := e.children[1].Open(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			e.recursiveOpen = true
+		}
+		row, err := e.children[1].Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			e.recursiveOpen = false
+			if err = e.children[1].Close(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			continue
+		}
+		// The result types are decided by the seed part, so we need to do conversion.
+		for j := range row {
+			row[j], err = row[j].ConvertTo(e.ctx.GetSessionVars().StmtCtx, e.schema.Columns[j].RetType)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		ok, err := e.addRow(row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if ok {
+			return row, nil
+		}
+	}
+	return nil, nil
+}
+
+// addRow adds the row to the result of the current iteration, it returns false if the row is a duplicate.
+func (e *RecursiveCTEExec) addRow(row Row) (bool, error) {
+	if e.distinct {
+		key, err := codec.EncodeValue(nil, row...)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if _, ok := e.seen[string(key)]; ok {
+			return false, nil
+		}
+		e.seen[string(key)] = struct{}{}
+	}
+	e.produced = append(e.produced, row)
+	return true, nil
+}
+
+// Close implements the Executor Close interface.
+func (e *RecursiveCTEExec) Close() error {
+	e.produced = nil
+	e.seen = nil
+	e.workTable.rows = nil
+	if e.recursiveOpen {
+		e.recursiveOpen = false
+		if err := e.children[1].Close(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(e.children[0].Close())
+}
+
+// CTEWorkTableExec represents the reference of a recursive CTE in its recursive part.
+// It returns the rows produced by the last iteration.
+type CTEWorkTableExec struct {
+	baseExecutor
+
+	workTable *cteWorkTable
+	cursor    int
+}
+
+// Open implements the Executor Open interface.
+func (e *CTEWorkTableExec) Open() error {
+	e.cursor = 0
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *CTEWorkTableExec) Next() (Row, error) {
+	if e.cursor >= len(e.workTable.rows) {
+		return nil, nil
+	}
+	row := e.workTable.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
@@ -19,10 +19,12 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/perfschema"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
@@ -36,6 +38,10 @@ type DDLExec struct {
 	ctx       context.Context
 	is        infoschema.InfoSchema
 	done      bool
+	// viewCols are the columns of the view to create, it's only used by CREATE VIEW.
+	viewCols []*model.ColumnInfo
+	// viewSelectCols are the names of the columns selected for viewCols.
+	viewSelectCols []model.CIStr
 }
 
 // Schema implements the Executor Schema interface.
@@ -56,6 +62,8 @@ func (e *DDLExec) Next() (Row, error) {
 		err = e.executeCreateDatabase(x)
 	case *ast.CreateTableStmt:
 		err = e.executeCreateTable(x)
+	case *ast.CreateViewStmt:
+		err = e.executeCreateView(x)
 	case *ast.CreateIndexStmt:
 		err = e.executeCreateIndex(x)
 	case *ast.DropDatabaseStmt:
@@ -97,6 +105,10 @@ func (e *DDLExec) Open() error {
 
 func (e *DDLExec) executeTruncateTable(s *ast.TruncateTableStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
+	// The tables of performance_schema are in memory, they aren't truncated by DDL.
+	if strings.EqualFold(ident.Schema.L, perfschema.Name) {
+		return errors.Trace(perfschema.TruncateTable(ident.Name.O))
+	}
 	err := sessionctx.GetDomain(e.ctx).DDL().TruncateTable(e.ctx, ident)
 	return errors.Trace(err)
 }
@@ -138,7 +150,7 @@ func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	var err error
 	if s.ReferTable == nil {
-		err = sessionctx.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options)
+		err = sessionctx.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options, s.Partition)
 	} else {
 		referIdent := ast.Ident{Schema: s.ReferTable.Schema, Name: s.ReferTable.Name}
 		err = sessionctx.GetDomain(e.ctx).DDL().CreateTableWithLike(e.ctx, ident, referIdent)
@@ -152,6 +164,30 @@ func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	return errors.Trace(err)
 }
 
+func (e *DDLExec) executeCreateView(s *ast.CreateViewStmt) error {
+	ident := ast.Ident{Schema: s.ViewName.Schema, Name: s.ViewName.Name}
+	if s.IsAlter {
+		tbl, err := e.is.TableByName(ident.Schema, ident.Name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !tbl.Meta().IsView() {
+			return ddl.ErrWrongObject.GenByArgs(ident.Schema, ident.Name, "VIEW")
+		}
+	}
+	definer := s.Definer
+	if definer == nil {
+		definer = e.ctx.GetSessionVars().User
+	}
+	security := s.Security
+	if security == "" {
+		security = "DEFINER"
+	}
+	viewInfo := &model.ViewInfo{Definer: definer, Security: security, SelectStmt: s.Select.Text(), SelectCols: e.viewSelectCols}
+	err := sessionctx.GetDomain(e.ctx).DDL().CreateView(e.ctx, ident, e.viewCols, viewInfo, s.OrReplace)
+	return errors.Trace(err)
+}
+
 func (e *DDLExec) executeCreateIndex(s *ast.CreateIndexStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := sessionctx.GetDomain(e.ctx).DDL().CreateIndex(e.ctx, ident, s.Unique, model.NewCIStr(s.IndexName), s.IndexColNames, s.IndexOption)
@@ -194,13 +230,20 @@ func (e *DDLExec) executeDropTable(s *ast.DropTableStmt) error {
 			notExistTables = append(notExistTables, fullti.String())
 			continue
 		}
-		_, err := e.is.TableByName(tn.Schema, tn.Name)
+		tbl, err := e.is.TableByName(tn.Schema, tn.Name)
 		if err != nil && infoschema.ErrTableNotExists.Equal(err) {
 			notExistTables = append(notExistTables, fullti.String())
 			continue
 		} else if err != nil {
 			return errors.Trace(err)
 		}
+		if s.IsView && !tbl.Meta().IsView() {
+			return ddl.ErrWrongObject.GenByArgs(tn.Schema, tn.Name, "VIEW")
+		}
+		if !s.IsView && tbl.Meta().IsView() {
+			notExistTables = append(notExistTables, fullti.String())
+			continue
+		}
 
 		err = sessionctx.GetDomain(e.ctx).DDL().DropTable(e.ctx, fullti)
 		if infoschema.ErrDatabaseNotExists.Equal(err) || infoschema.ErrTableNotExists.Equal(err) {
@@ -15,12 +15,19 @@ package executor_test
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -122,6 +129,188 @@ func (s *testSuite) TestCreateDropTable(c *C) {
 	tk.MustExec("drop table drop_test")
 }
 
+func (s *testSuite) TestCreateView(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_view_base")
+	tk.MustExec("create table t_view_base (a int primary key, b int, c varchar(10))")
+	tk.MustExec("insert into t_view_base values (1, 10, 'x'), (2, 20, 'y'), (3, 30, 'z')")
+
+	tk.MustExec("create view v1 as select a, b + 1 as b1 from t_view_base where a > 1")
+	tk.MustQuery("select * from v1").Check(testkit.Rows("2 21", "3 31"))
+	tk.MustQuery("select v1.b1 from v1 where a = 3").Check(testkit.Rows("31"))
+	tk.MustQuery("select x.a, t.c from v1 x join t_view_base t on x.a = t.a order by x.a desc").Check(testkit.Rows("3 z", "2 y"))
+	tk.MustQuery("select count(*), sum(b1) from v1").Check(testkit.Rows("2 52"))
+	tk.MustQuery("select a from t_view_base where a in (select a from v1) order by a").Check(testkit.Rows("2", "3"))
+
+	// The column list renames the columns, views can be built on views.
+	tk.MustExec("create view v2 (x, y) as select a, b1 from v1 union all select a, b from t_view_base where a = 1")
+	tk.MustQuery("select * from v2 order by x").Check(testkit.Rows("1 10", "2 21", "3 31"))
+	tk.MustQuery("select y from test.v2 where x = 2").Check(testkit.Rows("21"))
+
+	_, err := tk.Exec("create view v1 as select 1")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTableExists), IsTrue)
+	_, err = tk.Exec("create view v3 (x) as select a, b from t_view_base")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewWrongList), IsTrue)
+	_, err = tk.Exec("create view v3 as select a, a from t_view_base")
+	c.Assert(terror.ErrorEqual(err, plan.ErrDupFieldName), IsTrue)
+	_, err = tk.Exec("create or replace view t_view_base as select 1")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrWrongObject), IsTrue)
+	_, err = tk.Exec("create or replace view v1 as select * from v2")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewRecursive), IsTrue)
+	_, err = tk.Exec("alter view v_not_exists as select 1")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTableNotExists), IsTrue)
+
+	// OR REPLACE and ALTER VIEW change the definition.
+	tk.MustExec("create or replace view v1 as select a, b as b1 from t_view_base")
+	tk.MustQuery("select * from v1").Check(testkit.Rows("1 10", "2 20", "3 30"))
+	tk.MustQuery("select * from v2 order by x").Check(testkit.Rows("1 10", "1 10", "2 20", "3 30"))
+	tk.MustExec("alter view v1 as select a, c as b1 from t_view_base where a = 2")
+	tk.MustQuery("select * from v1").Check(testkit.Rows("2 y"))
+
+	tk.MustQuery("show create view v1").Check(testkit.Rows(
+		"v1 CREATE ALGORITHM=UNDEFINED SQL SECURITY DEFINER VIEW `v1` (`a`, `b1`) AS select a, c as b1 from t_view_base where a = 2 utf8 utf8_bin"))
+	tk.MustQuery("show full tables like 'v%'").Check(testkit.Rows("v1 VIEW", "v2 VIEW"))
+	tk.MustQuery("select table_name, view_definition, security_type from information_schema.views where table_schema = 'test' order by table_name").Check(testkit.Rows(
+		"v1 select a, c as b1 from t_view_base where a = 2 DEFINER",
+		"v2 select a, b1 from v1 union all select a, b from t_view_base where a = 1 DEFINER"))
+	tk.MustQuery("select table_type from information_schema.tables where table_schema = 'test' and table_name = 'v2'").Check(testkit.Rows("VIEW"))
+	rs, err := tk.Exec("show create view t_view_base")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(terror.ErrorEqual(err, ddl.ErrWrongObject), IsTrue)
+
+	// Views are read-only.
+	_, err = tk.Exec("insert into v1 values (4, 'w')")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonInsertableTable), IsTrue)
+	_, err = tk.Exec("update v1 set b1 = 'w'")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonUpdatableTable), IsTrue)
+	_, err = tk.Exec("delete from v1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonUpdatableTable), IsTrue)
+	_, err = tk.Exec("load data local infile '/tmp/nonexistence.csv' into table v1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonInsertableTable), IsTrue)
+	for _, sql := range []string{
+		"alter table v1 add column d int",
+		"alter table v1 drop column a",
+		"create index idx on v1 (a)",
+		"drop index idx on v1",
+		"truncate table v1",
+		"analyze table v1",
+		"admin check table v1",
+	} {
+		_, err = tk.Exec(sql)
+		c.Assert(terror.ErrorEqual(err, ddl.ErrWrongObject), IsTrue, Commentf("for %s", sql))
+	}
+	tk.MustQuery("select * from v1").Check(testkit.Rows("2 y"))
+
+	// The columns of `select *` are kept after new columns are added to the base table.
+	tk.MustExec("create view v3 as select * from t_view_base where a = 1")
+	tk.MustExec("create view v4 (x, y, z) as select * from t_view_base where a = 1")
+	tk.MustExec("alter table t_view_base add column d int default 5")
+	tk.MustQuery("select * from v3").Check(testkit.Rows("1 10 x"))
+	tk.MustQuery("select z, x from v4").Check(testkit.Rows("x 1"))
+	tk.MustExec("alter table t_view_base drop column d")
+
+	// The view becomes invalid if its base table changes.
+	tk.MustExec("alter table t_view_base drop column c")
+	_, err = tk.Exec("select * from v1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewInvalid), IsTrue)
+
+	_, err = tk.Exec("drop view t_view_base")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrWrongObject), IsTrue)
+	_, err = tk.Exec("drop table v1")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTableDropExists), IsTrue)
+	_, err = tk.Exec("select * from v4")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewInvalid), IsTrue)
+	tk.MustExec("drop view v1, v2, v3, v4")
+	tk.MustExec("drop view if exists v1")
+	_, err = tk.Exec("drop view v1")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTableDropExists), IsTrue)
+	tk.MustExec("drop table t_view_base")
+}
+
+func (s *testSuite) TestPartitionedTable(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_range, t_hash")
+	tk.MustExec(`create table t_range (a int primary key, b int) partition by range (a) (
+		partition p0 values less than (10),
+		partition p1 values less than (20),
+		partition p2 values less than (30))`)
+	tk.MustExec("insert into t_range values (1, 1), (11, 11), (21, 21), (25, 25)")
+	tk.MustQuery("select * from t_range order by a").Check(testkit.Rows("1 1", "11 11", "21 21", "25 25"))
+	tk.MustQuery("select * from t_range where a = 11").Check(testkit.Rows("11 11"))
+	tk.MustQuery("select * from t_range where a > 5 and a < 22 order by a").Check(testkit.Rows("11 11", "21 21"))
+	tk.MustQuery("select count(*) from t_range where a >= 30").Check(testkit.Rows("0"))
+	_, err := tk.Exec("insert into t_range values (30, 30)")
+	c.Assert(terror.ErrorEqual(err, table.ErrNoPartitionForGivenValue), IsTrue)
+
+	// Rows are moved between the partitions by UPDATE.
+	tk.MustExec("update t_range set a = 5 where a = 25")
+	tk.MustQuery("select * from t_range where a < 10 order by a").Check(testkit.Rows("1 1", "5 25"))
+	tk.MustExec("update t_range set b = b + 1 where a > 10")
+	tk.MustQuery("select * from t_range order by a").Check(testkit.Rows("1 1", "5 25", "11 12", "21 22"))
+	tk.MustExec("delete from t_range where a = 11")
+	tk.MustQuery("select * from t_range order by a").Check(testkit.Rows("1 1", "5 25", "21 22"))
+	_, err = tk.Exec("insert into t_range values (21, 0)")
+	c.Assert(terror.ErrorEqual(err, kv.ErrKeyExists), IsTrue)
+
+	tk.MustExec("begin")
+	tk.MustExec("insert into t_range values (12, 12)")
+	tk.MustExec("delete from t_range where a = 1")
+	tk.MustQuery("select * from t_range order by a").Check(testkit.Rows("5 25", "12 12", "21 22"))
+	tk.MustExec("rollback")
+	tk.MustQuery("select * from t_range order by a").Check(testkit.Rows("1 1", "5 25", "21 22"))
+
+	tk.MustExec("create table t_hash (a int, b int) partition by hash (a) partitions 3")
+	tk.MustExec("insert into t_hash values (1, 1), (2, 2), (3, 3), (-4, 4), (null, 5)")
+	tk.MustQuery("select * from t_hash where a = 2").Check(testkit.Rows("2 2"))
+	tk.MustQuery("select b from t_hash where a is null").Check(testkit.Rows("5"))
+	tk.MustQuery("select sum(b) from t_hash").Check(testkit.Rows("15"))
+	tk.MustQuery(`select partition_name, partition_method, partition_expression, table_rows from information_schema.partitions
+		where table_schema = 'test' and table_name = 't_hash'`).Check(testkit.Rows("p0 HASH a 0", "p1 HASH a 0", "p2 HASH a 0"))
+	tk.MustQuery(`select partition_name, partition_ordinal_position, partition_description from information_schema.partitions
+		where table_schema = 'test' and table_name = 't_range'`).Check(testkit.Rows("p0 1 10", "p1 2 20", "p2 3 30"))
+	tk.MustQuery("show create table t_range").Check(testkit.Rows("t_range CREATE TABLE `t_range` (\n" +
+		"  `a` int(11) NOT NULL,\n" +
+		"  `b` int(11) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`a`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin\n" +
+		"PARTITION BY RANGE (a)\n" +
+		"(PARTITION `p0` VALUES LESS THAN (10),\n" +
+		" PARTITION `p1` VALUES LESS THAN (20),\n" +
+		" PARTITION `p2` VALUES LESS THAN (30))"))
+
+	// Partition management.
+	tk.MustExec("alter table t_range add partition (partition p3 values less than (40), partition p4 values less than maxvalue)")
+	tk.MustExec("insert into t_range values (30, 30), (100, 100)")
+	tk.MustExec("alter table t_range truncate partition p0")
+	tk.MustQuery("select a from t_range order by a").Check(testkit.Rows("21", "30", "100"))
+	tk.MustExec("alter table t_range drop partition p3")
+	tk.MustQuery("select a from t_range order by a").Check(testkit.Rows("21", "100"))
+	tk.MustExec("insert into t_range values (35, 35)")
+	tk.MustQuery("select a from t_range where a > 25 order by a").Check(testkit.Rows("35", "100"))
+	_, err = tk.Exec("alter table t_range add partition (partition p5 values less than (50))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionMaxvalue), IsTrue)
+	_, err = tk.Exec("alter table t_range drop partition p9")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrDropPartitionNonExistent), IsTrue)
+	_, err = tk.Exec("alter table t_hash drop partition p0")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrOnlyOnRangeListPartition), IsTrue)
+	tk.MustExec("truncate table t_range")
+	tk.MustQuery("select count(*) from t_range").Check(testkit.Rows("0"))
+
+	tk.MustExec("drop table if exists t_err")
+	_, err = tk.Exec("create table t_err (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (5))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrRangeNotIncreasing), IsTrue)
+	_, err = tk.Exec("create table t_err (a int, b int, unique key (b)) partition by hash (a) partitions 2")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrUniqueKeyNeedAllFieldsInPf), IsTrue)
+	_, err = tk.Exec("create table t_err (a int) partition by range (a) (partition p0 values less than (10), partition p0 values less than (20))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrSameNamePartition), IsTrue)
+	_, err = tk.Exec("create table t_err (a int) partition by range (a)")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionsMustBeDefined), IsTrue)
+	tk.MustExec("drop table t_range, t_hash")
+}
+
 func (s *testSuite) TestCreateDropIndex(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -180,11 +369,7 @@ func (s *testSuite) TestAlterTableModifyColumn(c *C) {
 	c.Assert(err, NotNil)
 	tk.MustExec("alter table mc modify column c1 bigint")
 
-	_, err = tk.Exec("alter table mc modify column c2 blob")
-	c.Assert(err, NotNil)
-
-	_, err = tk.Exec("alter table mc modify column c2 varchar(8)")
-	c.Assert(err, NotNil)
+	tk.MustExec("alter table mc modify column c2 varchar(8)")
 	tk.MustExec("alter table mc modify column c2 varchar(11)")
 	tk.MustExec("alter table mc modify column c2 text(13)")
 	tk.MustExec("alter table mc modify column c2 text")
@@ -194,6 +379,104 @@ func (s *testSuite) TestAlterTableModifyColumn(c *C) {
 	c.Assert(createSQL, Equals, expected)
 }
 
+func (s *testSuite) TestAlterTableModifyColumnWithData(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists mc")
+	tk.MustExec("create table mc(a int primary key, b varchar(255), c bigint, index idx_b (b))")
+	tk.MustExec("insert into mc values (1, '10', 100), (2, '-20', 3000000000), (3, 'x', 5)")
+
+	// The conversion fails in strict mode.
+	_, err := tk.Exec("alter table mc modify b int")
+	c.Assert(err, NotNil)
+	tk.MustExec("update mc set b = '30' where a = 3")
+	tk.MustExec("alter table mc modify b int")
+	tk.MustQuery("select b from mc use index(idx_b) where b > 0 order by b").Check(testkit.Rows("10", "30"))
+	tk.MustExec("insert into mc values (4, 40, 6)")
+	tk.MustQuery("select a from mc where b = 40").Check(testkit.Rows("4"))
+	tk.MustExec("admin check table mc")
+
+	_, err = tk.Exec("alter table mc modify c int")
+	c.Assert(err, NotNil)
+	tk.MustExec("set sql_mode=''")
+	tk.MustExec("alter table mc modify c int")
+	tk.MustQuery("select c from mc order by a").Check(testkit.Rows("100", "2147483647", "5", "6"))
+
+	// Rename and move the column.
+	tk.MustExec("alter table mc change b bb varchar(3) first")
+	tk.MustQuery("select * from mc order by a").Check(testkit.Rows("10 1 100", "-20 2 2147483647", "30 3 5", "40 4 6"))
+	tk.MustQuery("show create table mc").Check(testkit.Rows("mc CREATE TABLE `mc` (\n" +
+		"  `bb` varchar(3) DEFAULT NULL,\n" +
+		"  `a` int(11) NOT NULL,\n" +
+		"  `c` int(11) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`a`),\n" +
+		"  KEY `idx_b` (`bb`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	tk.MustExec("admin check table mc")
+
+	_, err = tk.Exec("alter table mc modify a varchar(10)")
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "unsupported modify column"), IsTrue)
+	tk.MustExec("drop table mc")
+}
+
+func (s *testSuite) TestGeneratedColumnIndexAndModify(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists gc")
+	tk.MustExec("create table gc(a int, b int as (a * 2) virtual, c int as (a + 1) stored)")
+	tk.MustExec("insert into gc(a) values (1), (2), (3)")
+
+	// Index the virtual generated column, the values are calculated by the backfill.
+	tk.MustExec("alter table gc add index idx_b (b)")
+	tk.MustExec("alter table gc add unique index idx_bc (b, c)")
+	tk.MustExec("admin check table gc")
+
+	// The DML statements keep the indices on the virtual generated column consistent.
+	tk.MustExec("insert into gc(a) values (4), (8)")
+	tk.MustExec("update gc set a = 5 where a = 1")
+	tk.MustExec("delete from gc where a = 2")
+	tk.MustExec("admin check table gc")
+	tk.MustExec("alter table gc add unique index idx_a (a)")
+	tk.MustExec("replace into gc(a) values (3)")
+	tk.MustExec("insert into gc(a) values (4) on duplicate key update a = 6")
+	tk.MustExec("admin check table gc")
+	tk.MustQuery("select * from gc order by a").Check(testkit.Rows("3 6 4", "5 10 6", "6 12 7", "8 16 9"))
+
+	// Modify the generation expressions, the values are calculated again by reorganization.
+	tk.MustExec("alter table gc modify column b int as (a * 3) virtual")
+	tk.MustExec("admin check table gc")
+	tk.MustQuery("select b from gc order by a").Check(testkit.Rows("9", "15", "18", "24"))
+	tk.MustExec("alter table gc modify column c bigint as (b + 1) stored")
+	tk.MustExec("admin check table gc")
+	tk.MustQuery("select c from gc order by a").Check(testkit.Rows("10", "16", "19", "25"))
+	tk.MustExec("insert into gc(a) values (7)")
+	tk.MustQuery("select b, c from gc where a = 7").Check(testkit.Rows("21 22"))
+	tk.MustExec("admin check table gc")
+
+	// A stored generated value different from the calculated one is found by admin check.
+	tbl, err := sessionctx.GetDomain(tk.Se).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("gc"))
+	c.Assert(err, IsNil)
+	tk.MustExec("begin")
+	ctx := tk.Se.(context.Context)
+	var handle int64
+	err = tbl.IterRecords(ctx, tbl.FirstKey(), tbl.Cols(), func(h int64, rec []types.Datum, cols []*table.Column) (bool, error) {
+		if rec[0].GetInt64() == 7 {
+			handle = h
+			return false, nil
+		}
+		return true, nil
+	})
+	c.Assert(err, IsNil)
+	err = tbl.UpdateRecord(ctx, handle, types.MakeDatums(7, 21, 22), types.MakeDatums(7, 21, 100), []bool{false, false, true})
+	c.Assert(err, IsNil)
+	tk.MustExec("commit")
+	_, err = tk.Exec("admin check table gc")
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "generated column c"), IsTrue, Commentf("err %v", err))
+	tk.MustExec("drop table gc")
+}
+
 func (s *testSuite) TestDefaultDBAfterDropCurDB(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 
@@ -152,11 +152,11 @@ func tableHandlesToKVRanges(tid int64, handles []int64) []kv.KeyRange {
 }
 
 // indexValuesToKVRanges will convert the index datums to kv ranges.
-func indexValuesToKVRanges(tid, idxID int64, values [][]types.Datum) ([]kv.KeyRange, error) {
+func indexValuesToKVRanges(tid, idxID int64, values [][]types.Datum, fieldTypes []*types.FieldType) ([]kv.KeyRange, error) {
 	krs := make([]kv.KeyRange, 0, len(values))
 	for _, vals := range values {
 		// TODO: We don't process the case that equal key has different types.
-		valKey, err := codec.EncodeKey(nil, vals...)
+		valKey, err := codec.EncodeKey(nil, tablecodec.CollationKeys(vals, fieldTypes)...)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -176,14 +176,14 @@ func indexRangesToKVRanges(sc *variable.StatementContext, tid, idxID int64, rang
 			return nil, errors.Trace(err)
 		}
 
-		low, err := codec.EncodeKey(nil, ran.LowVal...)
+		low, err := codec.EncodeKey(nil, tablecodec.CollationKeys(ran.LowVal, fieldTypes)...)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
 		if ran.LowExclude {
 			low = []byte(kv.Key(low).PrefixNext())
 		}
-		high, err := codec.EncodeKey(nil, ran.HighVal...)
+		high, err := codec.EncodeKey(nil, tablecodec.CollationKeys(ran.HighVal, fieldTypes)...)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -707,10 +707,7 @@ func (e *XSelectIndexExec) doIndexRequest() (distsql.SelectResult, error) {
 		selIdxReq.Aggregates = e.aggFuncs
 		selIdxReq.GroupBy = e.byItems
 	}
-	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
-	for i, v := range e.index.Columns {
-		fieldTypes[i] = &(e.table.Cols()[v.Offset].FieldType)
-	}
+	fieldTypes := tablecodec.IndexStorageTypes(e.table.Meta(), e.index)
 	sv := e.ctx.GetSessionVars()
 	sc := sv.StmtCtx
 	keyRanges, err := indexRangesToKVRanges(sc, e.table.Meta().ID, e.index.ID, e.ranges, fieldTypes)
@@ -879,7 +876,7 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 	selTableReq.TableInfo = &tipb.TableInfo{
 		TableId: e.table.Meta().ID,
 	}
-	selTableReq.TableInfo.Columns = distsql.ColumnsToProto(e.columns, e.table.Meta().PKIsHandle)
+	selTableReq.TableInfo.Columns = distsql.ColumnsToProto(e.columns, e.table.Meta())
 	err := setPBColumnsDefaultValue(e.ctx, selTableReq.TableInfo.Columns, e.columns)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -958,7 +955,7 @@ func (e *XSelectTableExec) doRequest() error {
 	selReq.TableInfo = &tipb.TableInfo{
 		TableId: e.tableInfo.ID,
 	}
-	selReq.TableInfo.Columns = distsql.ColumnsToProto(e.Columns, e.tableInfo.PKIsHandle)
+	selReq.TableInfo.Columns = distsql.ColumnsToProto(e.Columns, e.tableInfo)
 	err := setPBColumnsDefaultValue(e.ctx, selReq.TableInfo.Columns, e.Columns)
 	if err != nil {
 		return errors.Trace(err)
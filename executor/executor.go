@@ -16,6 +16,7 @@ package executor
 import (
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -35,11 +36,13 @@ import (
 
 var (
 	_ Executor = &CheckTableExec{}
+	_ Executor = &CTEWorkTableExec{}
 	_ Executor = &ExistsExec{}
 	_ Executor = &HashAggExec{}
 	_ Executor = &LimitExec{}
 	_ Executor = &MaxOneRowExec{}
 	_ Executor = &ProjectionExec{}
+	_ Executor = &RecursiveCTEExec{}
 	_ Executor = &SelectionExec{}
 	_ Executor = &SelectLockExec{}
 	_ Executor = &ShowDDLExec{}
@@ -50,6 +53,7 @@ var (
 	_ Executor = &TableScanExec{}
 	_ Executor = &TopNExec{}
 	_ Executor = &UnionExec{}
+	_ Executor = &WindowExec{}
 )
 
 // Error instances.
@@ -66,6 +70,12 @@ var (
 	ErrBuildExecutor        = terror.ClassExecutor.New(codeErrBuildExec, "Failed to build executor")
 	ErrBatchInsertFail      = terror.ClassExecutor.New(codeBatchInsertFail, "Batch insert failed, please clean the table and try again.")
 	ErrWrongValueCountOnRow = terror.ClassExecutor.New(codeWrongValueCountOnRow, "Column count doesn't match value count at row %d")
+	ErrCTEMaxRecursionDepth = terror.ClassExecutor.New(codeCTEMaxRecursionDepth, mysql.MySQLErrName[mysql.ErrCTEMaxRecursionDepth])
+	ErrFileExists           = terror.ClassExecutor.New(codeFileExists, mysql.MySQLErrName[mysql.ErrFileExists])
+	ErrSavepointNotExists   = terror.ClassExecutor.New(codeSavepointNotExists, mysql.MySQLErrName[mysql.ErrSpDoesNotExist])
+	ErrUnknownAuthID        = terror.ClassExecutor.New(codeUnknownAuthID, mysql.MySQLErrName[mysql.ErrUnknownAuthID])
+	ErrRoleNotGranted       = terror.ClassExecutor.New(codeRoleNotGranted, mysql.MySQLErrName[mysql.ErrRoleNotGranted])
+	ErrPluginIsNotLoaded    = terror.ClassExecutor.New(codePluginIsNotLoaded, mysql.MySQLErrName[mysql.ErrPluginIsNotLoaded])
 )
 
 // Error codes.
@@ -83,6 +93,12 @@ const (
 	CodePasswordNoMatch      terror.ErrCode = 1133 // MySQL error code
 	CodeCannotUser           terror.ErrCode = 1396 // MySQL error code
 	codeWrongValueCountOnRow terror.ErrCode = 1136 // MySQL error code
+	codeCTEMaxRecursionDepth terror.ErrCode = 3636 // MySQL error code
+	codeFileExists           terror.ErrCode = 1086 // MySQL error code
+	codeSavepointNotExists   terror.ErrCode = 1305 // MySQL error code
+	codeUnknownAuthID        terror.ErrCode = 3523 // MySQL error code
+	codeRoleNotGranted       terror.ErrCode = 3530 // MySQL error code
+	codePluginIsNotLoaded    terror.ErrCode = 1524 // MySQL error code
 )
 
 // Row represents a result set row, it may be returned from a table, a join, or a projection.
@@ -226,6 +242,10 @@ func (e *CheckTableExec) Next() (Row, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		err = inspectkv.CompareGeneratedColumns(e.ctx, e.ctx.Txn(), tb)
+		if err != nil {
+			return nil, errors.Errorf("%v err:%v", t.Name, err)
+		}
 		for _, idx := range tb.Indices() {
 			txn := e.ctx.Txn()
 			err = inspectkv.CompareIndexData(txn, tb, idx)
@@ -276,7 +296,9 @@ func (e *SelectLockExec) Next() (Row, error) {
 		for _, col := range cols {
 			handle := row[col.Index].GetInt64()
 			lockKey := tablecodec.EncodeRowKeyWithHandle(id, handle)
+			start := time.Now()
 			err = txn.LockKeys(lockKey)
+			e.ctx.GetSessionVars().StmtCtx.AddLockTime(time.Since(start))
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
@@ -362,6 +384,12 @@ func init() {
 		CodeCannotUser:           mysql.ErrCannotUser,
 		CodePasswordNoMatch:      mysql.ErrPasswordNoMatch,
 		codeWrongValueCountOnRow: mysql.ErrWrongValueCountOnRow,
+		codeCTEMaxRecursionDepth: mysql.ErrCTEMaxRecursionDepth,
+		codeFileExists:           mysql.ErrFileExists,
+		codeSavepointNotExists:   mysql.ErrSpDoesNotExist,
+		codeUnknownAuthID:        mysql.ErrUnknownAuthID,
+		codeRoleNotGranted:       mysql.ErrRoleNotGranted,
+		codePluginIsNotLoaded:    mysql.ErrPluginIsNotLoaded,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExecutor] = tableMySQLErrCodes
 }
@@ -507,6 +535,7 @@ func (e *TableScanExec) Next() (Row, error) {
 			return nil, errors.Trace(err)
 		}
 		e.seekHandle = handle + 1
+		e.ctx.GetSessionVars().StmtCtx.AddExaminedRows(1)
 		return row, nil
 	}
 }
@@ -530,6 +559,7 @@ func (e *TableScanExec) nextForInfoSchema() (Row, error) {
 	}
 	row := e.virtualTableRows[e.virtualTableCursor]
 	e.virtualTableCursor++
+	e.ctx.GetSessionVars().StmtCtx.AddExaminedRows(1)
 	return row, nil
 }
 
@@ -555,12 +585,21 @@ func (e *TableScanExec) seekRange(handle int64) (inRange bool) {
 func (e *TableScanExec) getRow(handle int64) (Row, error) {
 	columns := make([]*table.Column, e.schema.Len())
 	for i, v := range e.columns {
+		// The extra handle column isn't stored in the row, it's filled with the handle below.
+		if v.ID == model.ExtraHandleID {
+			continue
+		}
 		columns[i] = table.ToColumn(v)
 	}
 	row, err := e.t.RowWithCols(e.ctx, handle, columns)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	for i, v := range e.columns {
+		if v.ID == model.ExtraHandleID {
+			row[i].SetInt64(handle)
+		}
+	}
 
 	return row, nil
 }
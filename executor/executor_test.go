@@ -16,7 +16,9 @@ package executor_test
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -26,6 +28,7 @@ import (
 	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/pd/pkg/logutil"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
@@ -40,7 +43,10 @@ import (
 	"github.com/pingcap/tidb/store/tikv"
 	mocktikv "github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -105,10 +111,14 @@ func (s *testSuite) SetUpTest(c *C) {
 func (s *testSuite) TearDownTest(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
-	r := tk.MustQuery("show tables")
+	r := tk.MustQuery("show full tables")
 	for _, tb := range r.Rows() {
 		tableName := tb[0]
-		tk.MustExec(fmt.Sprintf("drop table %v", tableName))
+		if tb[1] == "VIEW" {
+			tk.MustExec(fmt.Sprintf("drop view %v", tableName))
+		} else {
+			tk.MustExec(fmt.Sprintf("drop table %v", tableName))
+		}
 	}
 	testleak.AfterTest(c)()
 }
@@ -684,6 +694,38 @@ func (s *testSuite) TestOrderBy(c *C) {
 	tk.MustQuery("select c1, c2 from t order by binary c3").Check(testkit.Rows("1 2", "2 1"))
 }
 
+func (s *testSuite) TestSortSpill(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(20), c datetime, d decimal(10, 2), e time)")
+	for i := 0; i < 30; i++ {
+		v := (i * 7) % 30
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, 'str%d', '2017-01-%02d 10:00:00', %d.5, '10:%02d:00')", v, v%5, v%28+1, v, v))
+	}
+	tk.MustExec("insert into t values (null, null, null, null, null)")
+	queries := []string{
+		"select * from t order by a",
+		"select * from t order by b desc, a",
+		"select * from t order by c, a desc",
+		"select * from t order by e desc",
+		"select a, d from t order by a limit 5",
+		"select a, d from t order by d desc limit 5, 20",
+		"select a, b from t order by a limit 28, 10",
+	}
+	expected := make([][][]interface{}, 0, len(queries))
+	for _, sql := range queries {
+		expected = append(expected, tk.MustQuery(sql).Rows())
+	}
+	// The sort spills when either the sort memory quota or the memory quota of the statement is exceeded.
+	for _, quota := range []string{"tidb_mem_quota_sort", "tidb_mem_quota_query"} {
+		tk.MustExec("set @@" + quota + " = 1")
+		for i, sql := range queries {
+			tk.MustQuery(sql).Check(expected[i])
+		}
+		tk.MustExec("set @@" + quota + " = -1")
+	}
+}
+
 func (s *testSuite) TestSelectErrorRow(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -894,6 +936,122 @@ func (s *testSuite) TestUnion(c *C) {
 	tk.MustQuery("select c from t1 union (select c from t2) order by c").Check(testkit.Rows("73", "930"))
 }
 
+func (s *testSuite) TestCommonTableExpression(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, emp")
+	tk.MustExec("create table t(a int, b int)")
+	tk.MustExec("insert t values (1, 1), (2, 4), (3, 9)")
+
+	tk.MustQuery("with cte as (select a, b from t where a > 1) select * from cte order by a").Check(testkit.Rows("2 4", "3 9"))
+	tk.MustQuery("with cte(x, y) as (select a, b from t) select x + y from cte where x = 3").Check(testkit.Rows("12"))
+	tk.MustQuery("with c1 as (select a from t), c2 as (select a from c1 where a < 3) select * from c2 order by a").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("with cte as (select a from t) select c1.a, c2.a from cte c1 join cte c2 on c1.a + 1 = c2.a order by c1.a").Check(testkit.Rows("1 2", "2 3"))
+	tk.MustQuery("with t as (select 10 as a) select * from t").Check(testkit.Rows("10"))
+	tk.MustQuery("select * from t where a in (with cte as (select 2 as x) select x from cte)").Check(testkit.Rows("2 4"))
+	tk.MustExec("insert t with cte as (select a + 10 as a, b from t) select * from cte")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("6"))
+
+	tk.MustQuery("with recursive cte(n) as (select 1 union all select n + 1 from cte where n < 5) select * from cte").Check(testkit.Rows("1", "2", "3", "4", "5"))
+	tk.MustQuery("with recursive cte(n) as (select 1 union all select n + 1 from cte where n < 5) select sum(n) from cte").Check(testkit.Rows("15"))
+	tk.MustQuery("with recursive fib(n, a, b) as (select 1, 0, 1 union all select n + 1, b, a + b from fib where n < 10) select a from fib where n = 10").Check(testkit.Rows("34"))
+	tk.MustQuery("with recursive cte(n) as (select 1 union select n % 3 + 1 from cte) select * from cte order by n").Check(testkit.Rows("1", "2", "3"))
+	tk.MustQuery("with recursive cte(n) as (select 1 union all (select n + 1 from cte where n < 100) limit 3) select * from cte").Check(testkit.Rows("1", "2", "3"))
+	tk.MustQuery("with recursive cte(n) as (select 1 union all select 2 union all select n + 2 from cte where n < 5) select * from cte order by n").Check(testkit.Rows("1", "2", "3", "4", "5", "6"))
+
+	tk.MustExec("create table emp(id int, name varchar(10), manager int)")
+	tk.MustExec("insert emp values (1, 'a', null), (2, 'b', 1), (3, 'c', 2), (4, 'd', 1), (5, 'e', 3)")
+	tk.MustQuery(`with recursive sub(id, name, lvl) as (
+		select id, name, 0 from emp where id = 2
+		union all
+		select e.id, e.name, s.lvl + 1 from emp e join sub s on e.manager = s.id)
+		select * from sub order by id`).Check(testkit.Rows("2 b 0", "3 c 1", "5 e 2"))
+
+	tk.MustExec("set @@cte_max_recursion_depth = 10")
+	tk.MustQuery("with recursive cte(n) as (select 1 union all select n + 1 from cte where n < 10) select count(*) from cte").Check(testkit.Rows("10"))
+	rs, err := tk.Exec("with recursive cte(n) as (select 1 union all select n + 1 from cte where n < 20) select * from cte")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(terror.ErrorEqual(err, executor.ErrCTEMaxRecursionDepth), IsTrue)
+	c.Assert(rs.Close(), IsNil)
+	tk.MustExec("set @@cte_max_recursion_depth = default")
+
+	_, err = tk.Exec("with cte as (select 1), cte as (select 2) select * from cte")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonUniqTable), IsTrue)
+	_, err = tk.Exec("with cte(x, y) as (select 1) select * from cte")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewWrongList), IsTrue)
+	_, err = tk.Exec("with recursive cte(n) as (select n from cte) select * from cte")
+	c.Assert(terror.ErrorEqual(err, plan.ErrCTERecursiveRequiresUnion), IsTrue)
+	_, err = tk.Exec("with recursive cte(n) as (select n from cte union all select 1) select * from cte")
+	c.Assert(terror.ErrorEqual(err, plan.ErrCTERecursiveRequiresNonRecursiveFirst), IsTrue)
+	_, err = tk.Exec("with recursive cte(n) as (select 1 union all select count(*) from cte) select * from cte")
+	c.Assert(terror.ErrorEqual(err, plan.ErrCTERecursiveForbidsAggregation), IsTrue)
+	_, err = tk.Exec("with recursive cte(n) as (select 1 union all select c1.n + 1 from cte c1, cte c2 where c1.n < 3) select * from cte")
+	c.Assert(terror.ErrorEqual(err, plan.ErrCTERecursiveRequiresSingleReference), IsTrue)
+}
+
+func (s *testSuite) TestWindowFunction(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int, c int)")
+	tk.MustExec("insert t values (1, 1, 10), (1, 2, 20), (1, 2, 30), (1, 4, 40), (2, 1, 50), (2, 3, 60), (3, null, 70)")
+
+	tk.MustQuery("select a, b, row_number() over (partition by a order by b, c) from t order by a, b, c").Check(testkit.Rows(
+		"1 1 1", "1 2 2", "1 2 3", "1 4 4", "2 1 1", "2 3 2", "3 <nil> 1"))
+	tk.MustQuery("select a, b, rank() over (partition by a order by b), dense_rank() over (partition by a order by b) from t order by a, b, c").Check(testkit.Rows(
+		"1 1 1 1", "1 2 2 2", "1 2 2 2", "1 4 4 3", "2 1 1 1", "2 3 2 2", "3 <nil> 1 1"))
+	tk.MustQuery("select c, lag(c) over (order by c), lead(c, 2, -1) over (order by c) from t order by c").Check(testkit.Rows(
+		"10 <nil> 30", "20 10 40", "30 20 50", "40 30 60", "50 40 70", "60 50 -1", "70 60 -1"))
+	tk.MustQuery("select c, first_value(c) over (partition by a order by c), last_value(c) over (partition by a order by c) from t where a < 3 order by c").Check(testkit.Rows(
+		"10 10 10", "20 10 20", "30 10 30", "40 10 40", "50 50 50", "60 50 60"))
+
+	// The default frame of an ordered window contains the peers of the current row.
+	tk.MustQuery("select b, sum(c) over (partition by a order by b) from t where a = 1 order by c").Check(testkit.Rows(
+		"1 10", "2 60", "2 60", "4 100"))
+	tk.MustQuery("select c, sum(c) over (), count(*) over (partition by a) from t order by c").Check(testkit.Rows(
+		"10 280 4", "20 280 4", "30 280 4", "40 280 4", "50 280 2", "60 280 2", "70 280 1"))
+	tk.MustQuery("select c, sum(c) over (order by c rows between 1 preceding and 1 following) from t order by c").Check(testkit.Rows(
+		"10 30", "20 60", "30 90", "40 120", "50 150", "60 180", "70 130"))
+	tk.MustQuery("select c, avg(c) over (order by c rows 2 preceding) from t order by c").Check(testkit.Rows(
+		"10 10.0000", "20 15.0000", "30 20.0000", "40 30.0000", "50 40.0000", "60 50.0000", "70 60.0000"))
+	tk.MustQuery("select c, max(c) over (order by c rows between current row and unbounded following) from t order by c limit 2").Check(testkit.Rows(
+		"10 70", "20 70"))
+	tk.MustQuery("select b, count(*) over (order by b range between 1 preceding and 1 following) from t order by c").Check(testkit.Rows(
+		"1 4", "2 5", "2 5", "4 2", "1 4", "3 4", "<nil> 1"))
+	tk.MustQuery("select b, count(*) over (order by b desc range between current row and 1 following) from t order by c").Check(testkit.Rows(
+		"1 2", "2 4", "2 4", "4 2", "1 2", "3 3", "<nil> 1"))
+
+	// Window functions are evaluated after aggregation and can be used in order by clause.
+	tk.MustQuery("select a, sum(c), rank() over (order by sum(c) desc) from t group by a order by a").Check(testkit.Rows(
+		"1 100 2", "2 110 1", "3 70 3"))
+	tk.MustQuery("select a, c from t order by row_number() over (order by c desc) limit 3").Check(testkit.Rows(
+		"3 70", "2 60", "2 50"))
+	tk.MustQuery("select a, row_number() over (order by c) rn from t order by rn desc limit 1").Check(testkit.Rows("3 7"))
+	tk.MustQuery("select distinct a, count(*) over (partition by a) from t order by a").Check(testkit.Rows("1 4", "2 2", "3 1"))
+
+	_, err := tk.Exec("select a from t where row_number() over () > 1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowInvalidWindowFuncUse), IsTrue)
+	_, err = tk.Exec("select a from t group by row_number() over ()")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowInvalidWindowFuncUse), IsTrue)
+	_, err = tk.Exec("select sum(row_number() over ()) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowInvalidWindowFuncUse), IsTrue)
+	_, err = tk.Exec("select sum(c) over (rows between unbounded following and current row) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameStartIllegal), IsTrue)
+	_, err = tk.Exec("select sum(c) over (rows between current row and unbounded preceding) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameEndIllegal), IsTrue)
+	_, err = tk.Exec("select sum(c) over (rows 1.5 preceding) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameIllegal), IsTrue)
+	_, err = tk.Exec("select sum(c) over (order by a, b range 1 preceding) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowRangeFrameOrderType), IsTrue)
+	_, err = tk.Exec("select a, row_number() over () from t having a > 1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNotSupportedYet), IsTrue)
+	_, err = tk.Exec("select lag(c, -1) over () from t")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select rank(a) over () from t")
+	c.Assert(err, NotNil)
+}
+
 func (s *testSuite) TestIn(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
@@ -2238,3 +2396,281 @@ func (s *testSuite) TestSubqueryInValues(c *C) {
 	tk.MustExec("insert into t (id, name) value ((select gid from t1) ,'asd')")
 	tk.MustQuery("select * from t").Check(testkit.Rows("1 asd"))
 }
+
+func (s *testSuite) TestPerfSchemaStatementEvents(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key)")
+	tk.MustExec("insert t values (1), (2)")
+	tk.MustQuery("select * from t where a > 0").Check(testkit.Rows("1", "2"))
+	_, err := tk.Exec("insert t values (1)")
+	c.Assert(err, NotNil)
+
+	historySQL := "select event_name, sql_text, current_schema, mysql_errno, returned_sqlstate, errors, rows_affected, rows_sent, rows_examined " +
+		"from performance_schema.events_statements_history where thread_id = connection_id() order by event_id desc limit 3"
+	tk.MustQuery(historySQL).Check(testkit.Rows(
+		"statement/sql/insert insert t values (1) test 1062 23000 1 0 0 0",
+		"statement/sql/select select * from t where a > 0 test 0 00000 0 0 2 2",
+		"statement/sql/insert insert t values (1), (2) test 0 00000 0 2 0 0",
+	))
+	tk.MustQuery("select sql_text, timer_wait > 0 from performance_schema.events_statements_current where thread_id = connection_id()").Check(
+		testkit.Rows("select sql_text, timer_wait > 0 from performance_schema.events_statements_current where thread_id = connection_id() <nil>"))
+	tk.MustQuery("select count(*) from performance_schema.events_statements_history_long").Check(testkit.Rows("0"))
+
+	// The disabled consumers don't collect events.
+	tk.MustExec("update performance_schema.setup_consumers set enabled = 'NO' where name = 'events_statements_history'")
+	tk.MustExec("update performance_schema.setup_consumers set enabled = 'YES' where name = 'events_statements_history_long'")
+	tk.MustExec("delete from t")
+	tk.MustQuery("select sql_text from performance_schema.events_statements_history_long").Check(testkit.Rows("delete from t"))
+	tk.MustQuery("select sql_text from performance_schema.events_statements_history where thread_id = connection_id() order by event_id desc limit 1").Check(
+		testkit.Rows("update performance_schema.setup_consumers set enabled = 'NO' where name = 'events_statements_history'"))
+	tk.MustExec("update performance_schema.setup_consumers set enabled = 'NO' where name = 'events_statements_history_long'")
+	tk.MustExec("update performance_schema.setup_consumers set enabled = 'YES' where name = 'events_statements_history'")
+	tk.MustQuery("select enabled from performance_schema.setup_consumers where name like 'events_statements%'").Check(
+		testkit.Rows("YES", "YES", "NO"))
+
+	// The statements which fail to parse or compile are recorded too.
+	_, err = tk.Exec("select * from nosuch_event")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("selec 1")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select event_name, sql_text, mysql_errno, errors from performance_schema.events_statements_history " +
+		"where thread_id = connection_id() order by event_id desc limit 2").Check(testkit.Rows(
+		"statement/sql/error selec 1 1105 1",
+		"statement/sql/select select * from nosuch_event 1146 1",
+	))
+	tk.MustQuery("select digest_text, count_star, sum_errors from performance_schema.events_statements_summary_by_digest " +
+		"where digest_text like '%nosuch_event%'").Check(testkit.Rows("select * from nosuch_event 1 1"))
+}
+
+func (s *testSuite) TestPerfSchemaStatementsSummaryByDigest(c *C) {
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("truncate table performance_schema.events_statements_summary_by_digest")
+	tk.MustExec("insert t values (1, 1), (2, 2)")
+	tk.MustExec("insert t values (3, 3)")
+	tk.MustQuery("select b from t where a in (1, 2)").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select b from t where a in (3)").Check(testkit.Rows("3"))
+	_, err := tk.Exec("insert t values (1, 1)")
+	c.Assert(err, NotNil)
+
+	tk.MustQuery("select schema_name, digest_text, count_star, sum_errors, sum_rows_affected, sum_rows_sent, " +
+		"min_timer_wait <= avg_timer_wait, avg_timer_wait <= max_timer_wait, first_seen <= last_seen " +
+		"from performance_schema.events_statements_summary_by_digest where digest_text like '% t %' order by digest_text").Check(testkit.Rows(
+		"test insert t values (...) 3 1 3 0 1 1 1",
+		"test select b from t where a in (...) 2 0 0 3 1 1 1",
+	))
+	normalized, digest := parser.NormalizeDigest("select b from t where a in (1, 2)")
+	tk.MustQuery("select digest_text from performance_schema.events_statements_summary_by_digest where digest = ?", digest).Check(
+		testkit.Rows(normalized))
+
+	tk.MustExec("use performance_schema")
+	tk.MustExec("truncate table events_statements_summary_by_digest")
+	// The truncate statement itself is summarized after the table is truncated.
+	tk.MustQuery("select digest_text from events_statements_summary_by_digest").Check(testkit.Rows("truncate table events_statements_summary_by_digest"))
+	_, err = tk.Exec("truncate table events_statements_current")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestSlowQuery(c *C) {
+	dir, err := ioutil.TempDir("", "executor_slow_query_test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	cfg := config.GetGlobalConfig()
+	oriFile, oriThreshold := cfg.Log.SlowQueryFile, cfg.Log.SlowThreshold
+	cfg.Log.SlowQueryFile, cfg.Log.SlowThreshold = filepath.Join(dir, "tidb-slow.log"), 0
+	c.Assert(slowlog.InitLogger(cfg.Log.SlowQueryFile, &cfg.Log.File), IsNil)
+	defer func() {
+		cfg.Log.SlowQueryFile, cfg.Log.SlowThreshold = oriFile, oriThreshold
+		c.Assert(slowlog.InitLogger(oriFile, &cfg.Log.File), IsNil)
+	}()
+
+	tk := testkit.NewTestKitWithInit(c, s.store)
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, index idx_a (a))")
+	tk.MustExec("insert t values (1, 1), (2, 2), (1, 3)")
+	tk.MustQuery("select b from t where a = 1 order by b").Check(testkit.Rows("1", "3"))
+	tk.MustQuery("select db, index_names, rows_sent, rows_examined, cop_tasks, txn_start_ts > 0, query_time > 0, " +
+		"plan like '%IndexScan%' from information_schema.slow_query where conn_id = connection_id() and query like 'select b from t%'").Check(
+		testkit.Rows("test t:idx_a 2 4 2 1 1 1"))
+	tk.MustQuery("select index_names, rows_sent, cop_tasks, plan like 'Insert%' from information_schema.slow_query " +
+		"where conn_id = connection_id() and query like 'insert t values%'").Check(testkit.Rows(" 0 0 1"))
+
+	// The slow queries aren't written to the file if it's not set.
+	c.Assert(slowlog.InitLogger("", nil), IsNil)
+	tk.MustExec("select * from t")
+	tk.MustQuery("select count(*) from information_schema.slow_query where conn_id = connection_id() and query = 'select * from t'").Check(
+		testkit.Rows("0"))
+}
+
+func (s *testSuite) TestCICollation(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec(`create table t (id int primary key, a varchar(20) charset utf8mb4 collate utf8mb4_general_ci,
+		b varchar(20) charset utf8mb4 collate utf8mb4_unicode_ci, c varchar(20), unique index ua(a), index ib(b))`)
+	tk.MustExec("insert into t values (1, 'abc', 'straße', 'abc'), (2, 'Déf', 'Œuvre', 'Déf')")
+	// The unique index is case insensitive.
+	_, err := tk.Exec("insert into t values (3, 'ABC ', 'x', 'x')")
+	c.Assert(kv.ErrKeyExists.Equal(err), IsTrue)
+
+	tk.MustQuery("select id from t where a = 'ABC'").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t use index () where a = 'ABC'").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t where a = 'def'").Check(testkit.Rows("2"))
+	tk.MustQuery("select id from t where a in ('abc', 'ABC', 'aBc')").Check(testkit.Rows("1"))
+	tk.MustQuery("select a from t where a > 'B'").Check(testkit.Rows("Déf"))
+	tk.MustQuery("select id from t where c = 'ABC'").Check(testkit.Rows())
+	tk.MustQuery("select id from t where b = 'STRASSE'").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t use index () where b = 'oeuvre'").Check(testkit.Rows("2"))
+	// The like function is case sensitive.
+	tk.MustQuery("select id from t where a like 'AB%'").Check(testkit.Rows())
+	tk.MustQuery("select id from t where a like 'ab%'").Check(testkit.Rows("1"))
+
+	tk.MustExec("insert into t values (3, 'b', 'A', 'b'), (4, 'C', 'c', 'C')")
+	tk.MustQuery("select a from t order by a").Check(testkit.Rows("abc", "b", "C", "Déf"))
+	tk.MustQuery("select c from t order by c").Check(testkit.Rows("C", "Déf", "abc", "b"))
+	tk.MustQuery("select b from t order by b limit 2").Check(testkit.Rows("A", "c"))
+	tk.MustQuery("select strcmp(a, 'B'), greatest(a, 'B') from t where id = 1").Check(testkit.Rows("-1 B"))
+	tk.MustQuery("select upper(a) = 'déf' from t where id = 2").Check(testkit.Rows("1"))
+	tk.MustExec("admin check table t")
+
+	tk.MustQuery("show collation like 'utf8mb4%'").Check(testkit.Rows(
+		"utf8mb4_general_ci utf8mb4 45  Yes 1",
+		"utf8mb4_bin utf8mb4 46 Yes Yes 1",
+		"utf8mb4_unicode_ci utf8mb4 224  Yes 1"))
+	tk.MustQuery("select count(*) from information_schema.collations").Check(testkit.Rows("13"))
+}
+
+func (s *testSuite) TestCollationWithoutCharset(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	// The charset is derived from the collation of the column.
+	tk.MustExec("create table t (id int, a varchar(20) collate utf8mb4_general_ci, unique index ua(a))")
+	tk.MustExec("insert into t values (1, 'abc')")
+	_, err := tk.Exec("insert into t values (2, 'ABC')")
+	c.Assert(kv.ErrKeyExists.Equal(err), IsTrue)
+	tk.MustQuery("select id from t where a = 'ABC'").Check(testkit.Rows("1"))
+
+	// The COLLATE clause of the expression takes precedence over the collation of the column.
+	tk.MustQuery("select id from t where a = 'ABC' collate utf8mb4_bin").Check(testkit.Rows())
+	tk.MustQuery("select id from t use index () where a = 'ABC' collate utf8mb4_bin").Check(testkit.Rows())
+	tk.MustQuery("select id from t where a collate utf8mb4_bin = 'abc'").Check(testkit.Rows("1"))
+	tk.MustQuery("select 'a' = 'A' collate utf8mb4_general_ci, 'a' collate utf8_bin = 'A'").Check(testkit.Rows("1 0"))
+	_, err = tk.Exec("select * from t where a = 'ABC' collate unknown_ci")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("select * from t where id collate utf8mb4_bin = 1")
+	c.Assert(err, NotNil)
+
+	// The columns without the CHARSET and COLLATE clauses use the default collation of the table.
+	tk.MustExec("create table t1 (id int, a varchar(20), unique index ua(a)) collate utf8mb4_general_ci")
+	tk.MustExec("insert into t1 values (1, 'abc')")
+	_, err = tk.Exec("insert into t1 values (2, 'ABC')")
+	c.Assert(kv.ErrKeyExists.Equal(err), IsTrue)
+	tk.MustQuery("select id from t1 where a = 'ABC'").Check(testkit.Rows("1"))
+	tk.MustExec("alter table t1 add column b varchar(20)")
+	tk.MustExec("update t1 set b = 'def'")
+	tk.MustQuery("select id from t1 where b = 'DEF'").Check(testkit.Rows("1"))
+	tk.MustQuery("show create table t1").Check(testkit.Rows("t1 CREATE TABLE `t1` (\n" +
+		"  `id` int(11) DEFAULT NULL,\n" +
+		"  `a` varchar(20) DEFAULT NULL,\n" +
+		"  `b` varchar(20) DEFAULT NULL,\n" +
+		"  UNIQUE KEY `ua` (`a`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci"))
+
+	// And the default collation of the database if the table doesn't specify one.
+	tk.MustExec("drop database if exists test_ci")
+	tk.MustExec("create database test_ci collate utf8mb4_general_ci")
+	tk.MustExec("create table test_ci.t (a varchar(20))")
+	tk.MustExec("insert into test_ci.t values ('abc')")
+	tk.MustQuery("select a from test_ci.t where a = 'ABC'").Check(testkit.Rows("abc"))
+	tk.MustExec("drop database test_ci")
+}
+
+func (s *testSuite) TestGBKCharset(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a varchar(10) charset gbk, b varchar(10) charset gb18030 collate gb18030_chinese_ci, index ia(a))")
+	tk.MustExec("insert into t values (1, '中文', 'abc'), (2, '啊', 'ABD'), (3, '吧', '😀')")
+	tk.MustQuery("select a from t order by a").Check(testkit.Rows("啊", "吧", "中文"))
+	tk.MustQuery("select a from t use index (ia) where a > '啊' order by a").Check(testkit.Rows("吧", "中文"))
+	tk.MustQuery("select id from t where b = 'ABC'").Check(testkit.Rows("1"))
+	tk.MustQuery("select b from t where id = 3").Check(testkit.Rows("😀"))
+	// The bytes of the strings in gbk are used by the byte functions.
+	tk.MustQuery("select length(a), bit_length(a), hex(a) from t where id = 1").Check(testkit.Rows("4 32 D6D0CEC4"))
+	tk.MustQuery("select length(convert('中文' using gbk)), hex(convert('中文' using gbk)), hex('中文')").Check(testkit.Rows("4 D6D0CEC4 E4B8ADE69687"))
+
+	// The characters which can't be represented in the charset are rejected in strict mode.
+	tk.MustExec("set sql_mode = 'STRICT_TRANS_TABLES'")
+	_, err := tk.Exec("insert into t values (4, '😀', '')")
+	c.Assert(table.ErrTruncateWrongValue.Equal(err), IsTrue)
+	// The euro sign is 0x80 in Windows-936, but it isn't in gbk.
+	_, err = tk.Exec("insert into t values (4, '€', '')")
+	c.Assert(table.ErrTruncateWrongValue.Equal(err), IsTrue)
+	tk.MustExec("set sql_mode = ''")
+	tk.MustExec("insert into t values (4, 'a😀b', '')")
+	tk.MustQuery("select a from t where id = 4").Check(testkit.Rows("a?b"))
+	tk.MustExec("update t set a = concat('c', unhex('F09F9880')) where id = 4")
+	tk.MustQuery("select a from t where id = 4").Check(testkit.Rows("c?"))
+
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1 (a varchar(10) charset latin1)")
+	tk.MustExec("insert into t1 values ('café'), ('中')")
+	tk.MustQuery("select a from t1").Check(testkit.Rows("café", "?"))
+
+	// The columns use the default collation of the table charset.
+	tk.MustExec("drop table if exists t2")
+	tk.MustExec("create table t2 (a varchar(10), b varchar(10) charset gb18030) charset gbk")
+	tk.MustQuery("select column_name, character_set_name, collation_name from information_schema.columns where table_name = 't2'").Check(testkit.Rows(
+		"a gbk gbk_chinese_ci", "b gb18030 gb18030_chinese_ci"))
+	tk.MustExec("insert into t2 values ('abc', 'abc')")
+	tk.MustQuery("select count(*) from t2 where a = 'ABC' and b = 'ABC'").Check(testkit.Rows("1"))
+	tk.MustQuery("show character set like 'gb%'").Check(testkit.Rows(
+		"gbk GBK Simplified Chinese gbk_chinese_ci 2",
+		"gb18030 China National Standard GB18030 gb18030_chinese_ci 4"))
+}
+
+func (s *testSuite) TestCharsetStorage(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a varchar(10) charset gbk, b varchar(10) charset latin1, c varchar(10), index ia(a), index ib(b))")
+	tk.MustExec("insert into t values (1, '中文', 'café', '中文'), (2, '啊', '€', 'a')")
+
+	// The strings are stored in the charset of their columns.
+	is := sessionctx.GetDomain(tk.Se.(context.Context)).InfoSchema()
+	tb, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	data, err := txn.Get(tablecodec.EncodeRowKeyWithHandle(tb.Meta().ID, 1))
+	c.Assert(err, IsNil)
+	cols := make(map[int64]*types.FieldType)
+	for _, col := range tb.Cols() {
+		cols[col.ID] = types.NewFieldType(mysql.TypeBlob)
+	}
+	row, err := tablecodec.DecodeRow(data, cols, nil)
+	c.Assert(err, IsNil)
+	d1 := row[tb.Cols()[1].ID]
+	c.Assert(d1.GetBytes(), BytesEquals, []byte("\xd6\xd0\xce\xc4"))
+	d2 := row[tb.Cols()[2].ID]
+	c.Assert(d2.GetBytes(), BytesEquals, []byte("caf\xe9"))
+	d3 := row[tb.Cols()[3].ID]
+	c.Assert(d3.GetBytes(), BytesEquals, []byte("中文"))
+	c.Assert(txn.Rollback(), IsNil)
+
+	tk.MustQuery("select a, b, c from t where id = 1").Check(testkit.Rows("中文 café 中文"))
+	tk.MustQuery("select a from t use index (ia) order by a").Check(testkit.Rows("啊", "中文"))
+	tk.MustQuery("select b from t use index (ib) where b > 'a' order by b").Check(testkit.Rows("café", "€"))
+	tk.MustQuery("select id from t ignore index (ib) where b = '€'").Check(testkit.Rows("2"))
+	tk.MustExec("update t set a = concat(a, '字') where id = 1")
+	tk.MustQuery("select a from t where id = 1").Check(testkit.Rows("中文字"))
+	tk.MustExec("alter table t add column d varchar(10) charset gbk default '默认'")
+	tk.MustQuery("select d from t where id = 2").Check(testkit.Rows("默认"))
+	tk.MustExec("admin check table t")
+	tk.MustExec("delete from t where id = 1")
+	tk.MustQuery("select a from t").Check(testkit.Rows("啊"))
+	tk.MustExec("admin check table t")
+}
@@ -26,7 +26,6 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
-	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -74,17 +73,17 @@ func (e *GrantExec) Next() (Row, error) {
 			return nil, errors.Trace(err)
 		}
 		if !exists {
-			pwd := ""
-			if user.AuthOpt != nil {
-				if user.AuthOpt.ByAuthString {
-					pwd = auth.EncodePassword(user.AuthOpt.AuthString)
-				} else {
-					pwd = auth.EncodePassword(user.AuthOpt.HashString)
-				}
+			plugin := mysql.AuthName
+			if user.AuthOpt != nil && user.AuthOpt.AuthPlugin != "" {
+				plugin = user.AuthOpt.AuthPlugin
+			}
+			if err = checkAuthPlugin(plugin); err != nil {
+				return nil, errors.Trace(err)
 			}
+			pwd, authString := encodePassword(plugin, user.AuthOpt)
 
-			user := fmt.Sprintf(`("%s", "%s", "%s")`, user.User.Hostname, user.User.Username, pwd)
-			sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password) VALUES %s;`, mysql.SystemDB, mysql.UserTable, user)
+			user := fmt.Sprintf(`("%s", "%s", "%s", "%s", "%s")`, user.User.Hostname, user.User.Username, pwd, plugin, authString)
+			sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password, plugin, authentication_string) VALUES %s;`, mysql.SystemDB, mysql.UserTable, user)
 			_, err := e.ctx.(sqlexec.SQLExecutor).Execute(sql)
 			if err != nil {
 				return nil, errors.Trace(err)
@@ -14,15 +14,17 @@
 package executor
 
 import (
+	"io/ioutil"
+	"os"
 	"sync"
 	"sync/atomic"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
-	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/mvmap"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -66,6 +68,13 @@ type HashJoinExec struct {
 
 	// Channels for output.
 	resultCh chan *execResult
+
+	// memTracker tracks the memory used by the hash table. When the memory quota of the statement is exceeded,
+	// the join falls back to a grace hash join: both tables are partitioned into temporary files by the hash
+	// of the join key, and the partitions are joined one at a time.
+	memTracker      *memory.Tracker
+	spillDir        string
+	smallPartitions *partitionFiles
 }
 
 // hashJoinCtx holds the variables needed to do a hash join in one of many concurrent goroutines.
@@ -86,6 +95,10 @@ func (e *HashJoinExec) Close() error {
 		<-e.closeCh
 	}
 	e.rows = nil
+	if e.memTracker != nil {
+		e.memTracker.Detach()
+		e.memTracker = nil
+	}
 	return nil
 }
 
@@ -100,6 +113,9 @@ func (e *HashJoinExec) Open() error {
 	}
 	e.prepared = false
 	e.cursor = 0
+	e.smallPartitions = nil
+	e.memTracker = memory.NewTracker("HashJoin", -1)
+	e.memTracker.AttachTo(e.ctx.GetSessionVars().StmtCtx.MemTracker)
 	err := e.smallExec.Open()
 	if err != nil {
 		return errors.Trace(err)
@@ -240,14 +256,32 @@ func (e *HashJoinExec) prepare() error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if e.smallPartitions != nil {
+			err = e.smallPartitions.write(e.smallPartitions.partition(joinKey), joinKey, buffer)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
 		e.hashTable.Put(joinKey, buffer)
+		e.memTracker.Consume(int64(len(joinKey) + len(buffer)))
+		if e.memTracker.Exceeded() {
+			if err = e.spillHashTable(); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
 
 	e.resultCh = make(chan *execResult, e.concurrency)
 
-	for i := 0; i < e.concurrency; i++ {
+	if e.smallPartitions != nil {
 		e.wg.Add(1)
-		go e.runJoinWorker(i)
+		go e.runSpilledJoinWorker()
+	} else {
+		for i := 0; i < e.concurrency; i++ {
+			e.wg.Add(1)
+			go e.runJoinWorker(i)
+		}
 	}
 	go e.waitJoinWorkersAndCloseResultChan()
 
@@ -256,28 +290,195 @@ func (e *HashJoinExec) prepare() error {
 }
 
 func (e *HashJoinExec) encodeRow(b []byte, row Row) ([]byte, error) {
-	loc := e.ctx.GetSessionVars().GetTimeZone()
-	for _, datum := range row {
-		tmp, err := tablecodec.EncodeValue(datum, loc)
+	return encodeRowValues(b, row, e.ctx.GetSessionVars().GetTimeZone())
+}
+
+func (e *HashJoinExec) decodeRow(data []byte) (Row, error) {
+	return decodeRowValues(data, e.smallExec.Schema(), e.ctx.GetSessionVars().GetTimeZone())
+}
+
+// spillHashTable moves the rows in the hash table into partition files of the small table,
+// the rows fetched afterwards are written to the partition files directly.
+func (e *HashJoinExec) spillHashTable() error {
+	var err error
+	e.spillDir, err = ioutil.TempDir("", "tidb-join-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.smallPartitions, err = newPartitionFiles(e.spillDir, "small", spillPartitions)
+	if err != nil {
+		terror.Log(errors.Trace(os.RemoveAll(e.spillDir)))
+		e.smallPartitions = nil
+		return errors.Trace(err)
+	}
+	it := e.hashTable.NewIterator()
+	for {
+		key, value := it.Next()
+		if key == nil {
+			break
+		}
+		err = e.smallPartitions.write(e.smallPartitions.partition(key), key, value)
 		if err != nil {
-			return nil, errors.Trace(err)
+			terror.Log(errors.Trace(e.smallPartitions.close()))
+			terror.Log(errors.Trace(os.RemoveAll(e.spillDir)))
+			e.smallPartitions = nil
+			return errors.Trace(err)
 		}
-		b = append(b, tmp...)
 	}
-	return b, nil
+	e.hashTable = nil
+	e.memTracker.Consume(-e.memTracker.BytesConsumed())
+	return nil
 }
 
-func (e *HashJoinExec) decodeRow(data []byte) (Row, error) {
-	values := make([]types.Datum, e.smallExec.Schema().Len())
-	err := codec.SetRawValues(data, values)
+// runSpilledJoinWorker does the grace hash join after the small table is spilled. It partitions the
+// rows of the big table the same way as the small table, then builds a hash table for every partition
+// of the small table in turn and probes it with the same partition of the big table.
+func (e *HashJoinExec) runSpilledJoinWorker() {
+	var bigPartitions *partitionFiles
+	defer func() {
+		terror.Log(errors.Trace(e.smallPartitions.close()))
+		if bigPartitions != nil {
+			terror.Log(errors.Trace(bigPartitions.close()))
+		}
+		terror.Log(errors.Trace(os.RemoveAll(e.spillDir)))
+		e.wg.Done()
+	}()
+	bigPartitions, err := newPartitionFiles(e.spillDir, "big", spillPartitions)
+	if err == nil {
+		err = e.partitionBigTable(bigPartitions)
+	} else {
+		// Drain the big table so that the fetching goroutine can exit.
+		terror.Log(e.partitionBigTable(nil))
+		bigPartitions = nil
+	}
 	if err != nil {
-		return nil, errors.Trace(err)
+		e.resultCh <- &execResult{err: errors.Trace(err)}
+		return
 	}
-	err = decodeRawValues(values, e.smallExec.Schema(), e.ctx.GetSessionVars().GetTimeZone())
+	for i := 0; i < spillPartitions; i++ {
+		if e.finished.Load().(bool) {
+			return
+		}
+		if err = e.joinPartition(i, bigPartitions); err != nil {
+			e.resultCh <- &execResult{err: errors.Trace(err)}
+			return
+		}
+	}
+}
+
+// partitionBigTable writes all the rows of the big table into partitions by the hash of their join keys.
+// Rows with null join keys can't match any row, they are put into the first partition.
+// It always reads the big table to the end, so the fetching goroutine won't be blocked.
+func (e *HashJoinExec) partitionBigTable(partitions *partitionFiles) error {
+	var (
+		firstErr error
+		buffer   []byte
+	)
+	ctx := e.hashJoinContexts[0]
+	for result := range e.mergeBigTableResultCh() {
+		if partitions == nil || firstErr != nil || e.finished.Load().(bool) {
+			continue
+		}
+		if result.err != nil {
+			firstErr = errors.Trace(result.err)
+			continue
+		}
+		for _, row := range result.rows {
+			hasNull, joinKey, err := getJoinKey(e.bigHashKey, row, ctx.datumBuffer, ctx.hashKeyBuffer[0:0:cap(ctx.hashKeyBuffer)])
+			if err == nil {
+				buffer, err = e.encodeRow(buffer[:0], row)
+			}
+			if err == nil {
+				idx := 0
+				if !hasNull {
+					idx = partitions.partition(joinKey)
+				}
+				err = partitions.write(idx, joinKey, buffer)
+			}
+			if err != nil {
+				firstErr = errors.Trace(err)
+				break
+			}
+		}
+	}
+	return firstErr
+}
+
+// mergeBigTableResultCh merges the channels the big table rows are sent to into one channel.
+func (e *HashJoinExec) mergeBigTableResultCh() <-chan *execResult {
+	merged := make(chan *execResult, e.concurrency)
+	var wg sync.WaitGroup
+	for _, ch := range e.bigTableResultCh {
+		wg.Add(1)
+		go func(ch chan *execResult) {
+			defer wg.Done()
+			for result := range ch {
+				merged <- result
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+	return merged
+}
+
+// joinPartition joins the partition idx of the small table and the big table.
+func (e *HashJoinExec) joinPartition(idx int, bigPartitions *partitionFiles) error {
+	smallReader, err := e.smallPartitions.reader(idx)
 	if err != nil {
-		return nil, errors.Trace(err)
+		return errors.Trace(err)
+	}
+	e.hashTable = mvmap.NewMVMap()
+	var consumed int64
+	defer func() {
+		e.hashTable = nil
+		e.memTracker.Consume(-consumed)
+	}()
+	for {
+		key, value, ok, err1 := smallReader.next()
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if !ok {
+			break
+		}
+		e.hashTable.Put(key, value)
+		e.memTracker.Consume(int64(len(key) + len(value)))
+		consumed += int64(len(key) + len(value))
+	}
+
+	bigReader, err := bigPartitions.reader(idx)
+	if err != nil {
+		return errors.Trace(err)
 	}
-	return values, nil
+	maxRowsCnt := 1000
+	result := &execResult{rows: make([]Row, 0, maxRowsCnt)}
+	for !e.finished.Load().(bool) {
+		_, value, ok, err1 := bigReader.next()
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if !ok {
+			break
+		}
+		bigRow, err1 := decodeRowValues(value, e.bigExec.Schema(), e.ctx.GetSessionVars().GetTimeZone())
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if !e.joinOneBigRow(e.hashJoinContexts[0], bigRow, result) {
+			return errors.Trace(result.err)
+		}
+		if len(result.rows) >= maxRowsCnt {
+			e.resultCh <- result
+			result = &execResult{rows: make([]Row, 0, maxRowsCnt)}
+		}
+	}
+	if len(result.rows) != 0 {
+		e.resultCh <- result
+	}
+	return nil
 }
 
 func (e *HashJoinExec) waitJoinWorkersAndCloseResultChan() {
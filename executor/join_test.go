@@ -705,6 +705,37 @@ func (s *testSuite) TestJoinLeak(c *C) {
 	result.Close()
 }
 
+func (s *testSuite) TestHashJoinSpill(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int, b varchar(20))")
+	tk.MustExec("create table t2 (a int, c double)")
+	tk.MustExec("begin")
+	for i := 0; i < 300; i++ {
+		tk.MustExec(fmt.Sprintf("insert t1 values (%d, 'b%d')", i%100, i))
+		tk.MustExec(fmt.Sprintf("insert t2 values (%d, %d.5)", i%150, i))
+	}
+	tk.MustExec("insert t1 values (null, 'null')")
+	tk.MustExec("insert t2 values (null, 0)")
+	tk.MustExec("commit")
+
+	queries := []string{
+		"select t1.a, t1.b, t2.c from t1 join t2 on t1.a = t2.a order by t1.b, t2.c",
+		"select t1.a, t1.b, t2.c from t1 left join t2 on t1.a = t2.a and t2.c > 100 order by t1.b, t2.c",
+		"select t1.a, t1.b, t2.c from t1 right join t2 on t1.a = t2.a order by t2.c, t1.b",
+		"select count(*) from t1 where a in (select a from t2)",
+	}
+	var expected [][][]interface{}
+	for _, sql := range queries {
+		expected = append(expected, tk.MustQuery(sql).Rows())
+	}
+	tk.MustExec("set @@tidb_mem_quota_query = 1024")
+	for i, sql := range queries {
+		tk.MustQuery(sql).Check(expected[i])
+	}
+}
+
 func (s *testSuite) TestHashJoinExecEncodeDecodeRow(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
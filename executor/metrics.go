@@ -77,6 +77,8 @@ const (
 	CreateTable = "CreateTable"
 	// CreateUser represents create user statements.
 	CreateUser = "CreateUser"
+	// CreateView represents create view statements.
+	CreateView = "CreateView"
 	// Delete represents delete statements.
 	Delete = "Delete"
 	// DropDatabase represents drop database statements.
@@ -128,6 +130,8 @@ func StatementLabel(node ast.StmtNode, p plan.Plan, isExpensive *bool) string {
 		return CreateTable
 	case *ast.CreateUserStmt:
 		return CreateUser
+	case *ast.CreateViewStmt:
+		return CreateView
 	case *ast.DeleteStmt:
 		return getDeleteStmtLabel(x, p, isExpensive)
 	case *ast.DropDatabaseStmt:
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
@@ -117,6 +118,7 @@ func (e *TableReaderExecutor) Next() (Row, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		e.ctx.GetSessionVars().StmtCtx.AddExaminedRows(1)
 		return rowData, nil
 	}
 }
@@ -238,16 +240,14 @@ func (e *IndexReaderExecutor) Next() (Row, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		e.ctx.GetSessionVars().StmtCtx.AddExaminedRows(1)
 		return rowData, nil
 	}
 }
 
 // Open implements the Executor Open interface.
 func (e *IndexReaderExecutor) Open() error {
-	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
-	for i, v := range e.index.Columns {
-		fieldTypes[i] = &(e.table.Cols()[v.Offset].FieldType)
-	}
+	fieldTypes := tablecodec.IndexStorageTypes(e.table.Meta(), e.index)
 	var builder requestBuilder
 	kvReq, err := builder.SetIndexRanges(e.ctx.GetSessionVars().StmtCtx, e.tableID, e.index.ID, e.ranges, fieldTypes).
 		SetDAGRequest(e.dagPB).
@@ -269,8 +269,9 @@ func (e *IndexReaderExecutor) Open() error {
 
 // doRequestForDatums constructs kv ranges by datums. It is used by index look up executor.
 func (e *IndexReaderExecutor) doRequestForDatums(values [][]types.Datum, goCtx goctx.Context) error {
+	fieldTypes := tablecodec.IndexStorageTypes(e.table.Meta(), e.index)
 	var builder requestBuilder
-	kvReq, err := builder.SetIndexValues(e.tableID, e.index.ID, values).
+	kvReq, err := builder.SetIndexValues(e.tableID, e.index.ID, values, fieldTypes).
 		SetDAGRequest(e.dagPB).
 		SetDesc(e.desc).
 		SetKeepOrder(e.keepOrder).
@@ -463,16 +464,14 @@ func (e *IndexLookUpExecutor) open(kvRanges []kv.KeyRange) error {
 }
 
 func (e *IndexLookUpExecutor) indexRangesToKVRanges() ([]kv.KeyRange, error) {
-	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
-	for i, v := range e.index.Columns {
-		fieldTypes[i] = &(e.table.Cols()[v.Offset].FieldType)
-	}
+	fieldTypes := tablecodec.IndexStorageTypes(e.table.Meta(), e.index)
 	return indexRangesToKVRanges(e.ctx.GetSessionVars().StmtCtx, e.tableID, e.index.ID, e.ranges, fieldTypes)
 }
 
 // doRequestForDatums constructs kv ranges by datums. It is used by index look up join.
 func (e *IndexLookUpExecutor) doRequestForDatums(values [][]types.Datum, goCtx goctx.Context) error {
-	kvRanges, err := indexValuesToKVRanges(e.tableID, e.index.ID, values)
+	fieldTypes := tablecodec.IndexStorageTypes(e.table.Meta(), e.index)
+	kvRanges, err := indexValuesToKVRanges(e.tableID, e.index.ID, values, fieldTypes)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -594,6 +593,7 @@ func (e *IndexLookUpExecutor) Next() (Row, error) {
 			return nil, errors.Trace(err)
 		}
 		if row != nil {
+			e.ctx.GetSessionVars().StmtCtx.AddExaminedRows(1)
 			return row, nil
 		}
 		e.resultCurr = nil
@@ -627,11 +627,11 @@ func (builder *requestBuilder) SetTableHandles(tid int64, handles []int64) *requ
 	return builder
 }
 
-func (builder *requestBuilder) SetIndexValues(tid, idxID int64, values [][]types.Datum) *requestBuilder {
+func (builder *requestBuilder) SetIndexValues(tid, idxID int64, values [][]types.Datum, fieldTypes []*types.FieldType) *requestBuilder {
 	if builder.err != nil {
 		return builder
 	}
-	builder.Request.KeyRanges, builder.err = indexValuesToKVRanges(tid, idxID, values)
+	builder.Request.KeyRanges, builder.err = indexValuesToKVRanges(tid, idxID, values, fieldTypes)
 	return builder
 }
 
@@ -677,6 +677,7 @@ func (builder *requestBuilder) SetFromSessionVars(sv *variable.SessionVars) *req
 	builder.Request.Concurrency = sv.DistSQLScanConcurrency
 	builder.Request.IsolationLevel = getIsolationLevel(sv)
 	builder.Request.NotFillCache = sv.StmtCtx.NotFillCache
+	builder.Request.CopStats = sv.StmtCtx
 	return builder
 }
 
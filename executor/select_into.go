@@ -0,0 +1,276 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/terror"
+)
+
+var _ Executor = &SelectIntoExec{}
+
+// SelectIntoExec represents a SELECT ... INTO OUTFILE executor.
+// It writes the rows of its child executor to a file on the tidb-server host.
+type SelectIntoExec struct {
+	baseExecutor
+
+	intoOpt *ast.SelectIntoOption
+	// splitByRegion indicates whether to write the data of every region into a separate file.
+	splitByRegion bool
+	done          bool
+}
+
+// Next implements the Executor Next interface.
+func (e *SelectIntoExec) Next() (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+
+	var (
+		rows uint64
+		err  error
+	)
+	if reader, ok := e.children[0].(*TableReaderExecutor); ok && e.splitByRegion {
+		rows, err = e.exportByRegion(reader)
+	} else {
+		rows, err = e.export()
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(rows)
+	return nil, nil
+}
+
+// export writes all the rows of the child executor into a single file.
+func (e *SelectIntoExec) export() (uint64, error) {
+	w, err := newOutfileWriter(e.intoOpt.FileName, e.intoOpt)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer terror.Call(w.close)
+
+	var rows uint64
+	for {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return rows, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		if err = w.writeRow(row); err != nil {
+			return rows, errors.Trace(err)
+		}
+		rows++
+	}
+	return rows, errors.Trace(w.flush())
+}
+
+// exportByRegion writes the partial result of every region into a separate file, the files are
+// named by the OUTFILE path with a sequence number suffix and written by concurrent workers.
+func (e *SelectIntoExec) exportByRegion(reader *TableReaderExecutor) (uint64, error) {
+	var (
+		wg       sync.WaitGroup
+		rows     uint64
+		mu       sync.Mutex
+		firstErr error
+	)
+	getErr := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr
+	}
+	setErr := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+
+	taskCh := make(chan *outfileTask)
+	concurrency := e.ctx.GetSessionVars().DistSQLScanConcurrency
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range taskCh {
+				if getErr() != nil {
+					terror.Call(task.result.Close)
+					continue
+				}
+				n, err := e.exportPartialResult(reader, task)
+				atomic.AddUint64(&rows, n)
+				if err != nil {
+					setErr(errors.Trace(err))
+				}
+			}
+		}()
+	}
+
+	for seq := 0; getErr() == nil; seq++ {
+		result, err := reader.result.Next()
+		if err != nil {
+			setErr(errors.Trace(err))
+			break
+		}
+		if result == nil {
+			break
+		}
+		taskCh <- &outfileTask{
+			path:   fmt.Sprintf("%s.%d", e.intoOpt.FileName, seq),
+			result: result,
+		}
+	}
+	close(taskCh)
+	wg.Wait()
+	return atomic.LoadUint64(&rows), getErr()
+}
+
+// outfileTask is the partial result of a region and the file it is written into.
+type outfileTask struct {
+	path   string
+	result distsql.NewPartialResult
+}
+
+func (e *SelectIntoExec) exportPartialResult(reader *TableReaderExecutor, task *outfileTask) (uint64, error) {
+	defer terror.Call(task.result.Close)
+	w, err := newOutfileWriter(task.path, e.intoOpt)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer terror.Call(w.close)
+
+	loc := e.ctx.GetSessionVars().GetTimeZone()
+	var rows uint64
+	for {
+		row, err := task.result.Next()
+		if err != nil {
+			return rows, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		if err = decodeRawValues(row, reader.schema, loc); err != nil {
+			return rows, errors.Trace(err)
+		}
+		if err = w.writeRow(row); err != nil {
+			return rows, errors.Trace(err)
+		}
+		rows++
+	}
+	return rows, errors.Trace(w.flush())
+}
+
+// outfileWriter formats rows by the FIELDS and LINES clauses and writes them into a file.
+type outfileWriter struct {
+	file       *os.File
+	w          *bufio.Writer
+	fieldsInfo *ast.FieldsClause
+	linesInfo  *ast.LinesClause
+	buf        []byte
+}
+
+// newOutfileWriter creates the file of path, the file must not exist.
+func newOutfileWriter(path string, intoOpt *ast.SelectIntoOption) (*outfileWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, ErrFileExists.GenByArgs(path)
+		}
+		return nil, errors.Trace(err)
+	}
+	return &outfileWriter{
+		file:       file,
+		w:          bufio.NewWriter(file),
+		fieldsInfo: intoOpt.FieldsInfo,
+		linesInfo:  intoOpt.LinesInfo,
+	}, nil
+}
+
+// writeRow writes a row as a line, NULL is written as \N, or "NULL" if the escape character is empty.
+// See https://dev.mysql.com/doc/refman/5.7/en/load-data.html
+func (w *outfileWriter) writeRow(row Row) error {
+	escaped, enclosed := w.fieldsInfo.Escaped, w.fieldsInfo.Enclosed
+	w.buf = append(w.buf[:0], w.linesInfo.Starting...)
+	for i, d := range row {
+		if i > 0 {
+			w.buf = append(w.buf, w.fieldsInfo.Terminated...)
+		}
+		if d.IsNull() {
+			if escaped == 0 {
+				w.buf = append(w.buf, "NULL"...)
+			} else {
+				w.buf = append(w.buf, escaped, 'N')
+			}
+			continue
+		}
+		str, err := d.ToString()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if enclosed != 0 {
+			w.buf = append(w.buf, enclosed)
+		}
+		w.appendEscaped(str)
+		if enclosed != 0 {
+			w.buf = append(w.buf, enclosed)
+		}
+	}
+	w.buf = append(w.buf, w.linesInfo.Terminated...)
+	_, err := w.w.Write(w.buf)
+	return errors.Trace(err)
+}
+
+// appendEscaped prefixes the escape character to the escape character itself, the enclose character,
+// the first characters of the field and line terminators and writes ASCII NUL as the escape character followed by 0.
+func (w *outfileWriter) appendEscaped(str string) {
+	escaped := w.fieldsInfo.Escaped
+	if escaped == 0 {
+		w.buf = append(w.buf, str...)
+		return
+	}
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case c == 0:
+			w.buf = append(w.buf, escaped, '0')
+			continue
+		case c == escaped,
+			w.fieldsInfo.Enclosed != 0 && c == w.fieldsInfo.Enclosed,
+			len(w.fieldsInfo.Terminated) > 0 && c == w.fieldsInfo.Terminated[0],
+			len(w.linesInfo.Terminated) > 0 && c == w.linesInfo.Terminated[0]:
+			w.buf = append(w.buf, escaped)
+		}
+		w.buf = append(w.buf, c)
+	}
+}
+
+func (w *outfileWriter) flush() error {
+	return errors.Trace(w.w.Flush())
+}
+
+func (w *outfileWriter) close() error {
+	return errors.Trace(w.file.Close())
+}
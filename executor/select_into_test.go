@@ -0,0 +1,130 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
+)
+
+func (s *testSuite) TestSelectIntoOutfile(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a varchar(20), b double, c datetime)")
+	tk.MustExec(`insert into t values (1, 'x', 1.5, '2017-01-01 00:00:00'), (2, null, null, null),
+		(3, 'a\tb,c"d\\e', 0, '2017-12-31 23:59:59')`)
+
+	dir, err := ioutil.TempDir("", "outfile")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	readFile := func(path string) string {
+		content, err1 := ioutil.ReadFile(path)
+		c.Assert(err1, IsNil)
+		return string(content)
+	}
+
+	path := filepath.Join(dir, "t.txt")
+	tk.MustExec(fmt.Sprintf("select * from t order by id into outfile '%s'", path))
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(3))
+	c.Assert(readFile(path), Equals, "1\tx\t1.5\t2017-01-01 00:00:00\n"+
+		"2\t\\N\t\\N\t\\N\n"+
+		"3\ta\\\tb,c\"d\\\\e\t0\t2017-12-31 23:59:59\n")
+
+	// The file must not exist.
+	_, err = tk.Exec(fmt.Sprintf("select * from t into outfile '%s'", path))
+	c.Assert(terror.ErrorEqual(err, executor.ErrFileExists), IsTrue)
+
+	path = filepath.Join(dir, "t.csv")
+	tk.MustExec(fmt.Sprintf(`select id, a from t order by id into outfile '%s'
+		fields terminated by ',' enclosed by '"' lines starting by '>' terminated by '\r\n'`, path))
+	c.Assert(readFile(path), Equals, ">\"1\",\"x\"\r\n"+
+		">\"2\",\\N\r\n"+
+		">\"3\",\"a\tb\\,c\\\"d\\\\e\"\r\n")
+
+	path = filepath.Join(dir, "t_noescape.txt")
+	tk.MustExec(fmt.Sprintf(`select id, a, upper(a) from t order by id into outfile '%s' fields terminated by '|' escaped by ''`, path))
+	c.Assert(readFile(path), Equals, "1|x|X\n2|NULL|NULL\n3|a\tb,c\"d\\e|A\tB,C\"D\\E\n")
+
+	path = filepath.Join(dir, "empty.txt")
+	tk.MustExec(fmt.Sprintf("select * from t where id > 10 into outfile '%s'", path))
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(0))
+	c.Assert(readFile(path), Equals, "")
+}
+
+func (s *testSuite) TestSelectIntoOutfileSplitByRegion(c *C) {
+	if s.cluster == nil {
+		// Regions can only be split in the mock tikv store.
+		return
+	}
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, v int)")
+	var values []string
+	expected := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d)", i, i*2))
+		expected = append(expected, fmt.Sprintf("%d\t%d", i, i*2))
+	}
+	tk.MustExec("insert t values " + strings.Join(values, ","))
+
+	dom := sessionctx.GetDomain(tk.Se)
+	tbl, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	s.cluster.SplitTable(s.mvccStore, tbl.Meta().ID, 4)
+
+	dir, err := ioutil.TempDir("", "outfile")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "t.txt")
+	tk.MustExec("set @@tidb_outfile_split_by_region = 1")
+	tk.MustExec(fmt.Sprintf("select * from t into outfile '%s'", path))
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(100))
+
+	files, err := filepath.Glob(path + ".*")
+	c.Assert(err, IsNil)
+	c.Assert(len(files), Equals, 4)
+	var lines []string
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		c.Assert(err, IsNil)
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")...)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		var a, b int
+		fmt.Sscanf(lines[i], "%d", &a)
+		fmt.Sscanf(lines[j], "%d", &b)
+		return a < b
+	})
+	c.Assert(lines, DeepEquals, expected)
+
+	// A select which doesn't read the table directly is written into a single file.
+	path = filepath.Join(dir, "t2.txt")
+	tk.MustExec(fmt.Sprintf("select count(*) from t into outfile '%s'", path))
+	content, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "100\n")
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
@@ -46,7 +47,8 @@ type ShowExec struct {
 	Column *ast.ColumnName // Used for `desc table column`.
 	Flag   int             // Some flag parsed from sql, such as FULL.
 	Full   bool
-	User   *auth.UserIdentity // Used for show grants.
+	User   *auth.UserIdentity   // Used for show grants.
+	Roles  []*auth.RoleIdentity // Used for show grants using roles.
 
 	// GlobalScope is used by show variables
 	GlobalScope bool
@@ -98,6 +100,8 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowCreateTable()
 	case ast.ShowCreateDatabase:
 		return e.fetchShowCreateDatabase()
+	case ast.ShowCreateView:
+		return e.fetchShowCreateView()
 	case ast.ShowDatabases:
 		return e.fetchShowDatabases()
 	case ast.ShowEngines:
@@ -155,7 +159,7 @@ func (e *ShowExec) fetchShowDatabases() error {
 	// TODO: let information_schema be the first database
 	sort.Strings(dbs)
 	for _, d := range dbs {
-		if checker != nil && !checker.DBIsVisible(d) {
+		if checker != nil && !checker.DBIsVisible(e.ctx.GetSessionVars().ActiveRoles, d) {
 			continue
 		}
 		e.rows = append(e.rows, types.MakeDatums(d))
@@ -197,21 +201,25 @@ func (e *ShowExec) fetchShowTables() error {
 	checker := privilege.GetPrivilegeManager(e.ctx)
 	// sort for tables
 	var tableNames []string
+	isView := make(map[string]bool)
 	for _, v := range e.is.SchemaTables(e.DBName) {
 		// Test with mysql.AllPrivMask means any privilege would be OK.
 		// TODO: Should consider column privileges, which also make a table visible.
-		if checker != nil && !checker.RequestVerification(e.DBName.O, v.Meta().Name.O, "", mysql.AllPrivMask) {
+		if checker != nil && !checker.RequestVerification(e.ctx.GetSessionVars().ActiveRoles, e.DBName.O, v.Meta().Name.O, "", mysql.AllPrivMask) {
 			continue
 		}
 		tableNames = append(tableNames, v.Meta().Name.O)
+		isView[v.Meta().Name.O] = v.Meta().IsView()
 	}
 	sort.Strings(tableNames)
 	for _, v := range tableNames {
 		data := types.MakeDatums(v)
 		if e.Full {
-			// TODO: support "VIEW" later if we have supported view feature.
-			// now, just use "BASE TABLE".
-			data = append(data, types.NewDatum("BASE TABLE"))
+			if isView[v] {
+				data = append(data, types.NewDatum("VIEW"))
+			} else {
+				data = append(data, types.NewDatum("BASE TABLE"))
+			}
 		}
 		e.rows = append(e.rows, data)
 	}
@@ -228,6 +236,12 @@ func (e *ShowExec) fetchShowTableStatus() error {
 	sort.Sort(table.Slice(tables))
 
 	for _, t := range tables {
+		if t.Meta().IsView() {
+			data := types.MakeDatums(t.Meta().Name.O, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+				nil, nil, nil, nil, nil, nil, "VIEW")
+			e.rows = append(e.rows, data)
+			continue
+		}
 		now := types.CurrentTime(mysql.TypeDatetime)
 		data := types.MakeDatums(t.Meta().Name.O, "InnoDB", "10", "Compact", 100, 100, 100, 100, 100, 100, 100,
 			now, now, now, "utf8_general_ci", "", "", t.Meta().Comment)
@@ -551,12 +565,66 @@ func (e *ShowExec) fetchShowCreateTable() error {
 	if len(tb.Meta().Comment) > 0 {
 		buf.WriteString(fmt.Sprintf(" COMMENT='%s'", format.OutputFormat(tb.Meta().Comment)))
 	}
+	appendPartitionInfo(tb.Meta().Partition, &buf)
 
 	data := types.MakeDatums(tb.Meta().Name.O, buf.String())
 	e.rows = append(e.rows, data)
 	return nil
 }
 
+// appendPartitionInfo appends the PARTITION BY clause of a partitioned table to buf.
+func appendPartitionInfo(pi *model.PartitionInfo, buf *bytes.Buffer) {
+	if pi == nil {
+		return
+	}
+	buf.WriteString(fmt.Sprintf("\nPARTITION BY %s (%s)", pi.Type, pi.Expr))
+	if pi.Type == model.PartitionTypeHash {
+		buf.WriteString(fmt.Sprintf("\nPARTITIONS %d", len(pi.Definitions)))
+		return
+	}
+	buf.WriteString("\n(")
+	for i, def := range pi.Definitions {
+		if i > 0 {
+			buf.WriteString(",\n ")
+		}
+		if def.LessThan == model.PartitionMaxValue {
+			buf.WriteString(fmt.Sprintf("PARTITION `%s` VALUES LESS THAN %s", def.Name.O, def.LessThan))
+		} else {
+			buf.WriteString(fmt.Sprintf("PARTITION `%s` VALUES LESS THAN (%s)", def.Name.O, def.LessThan))
+		}
+	}
+	buf.WriteString(")")
+}
+
+// fetchShowCreateView composes show create view result.
+func (e *ShowExec) fetchShowCreateView() error {
+	tb, err := e.getTable()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tblInfo := tb.Meta()
+	if !tblInfo.IsView() {
+		return ddl.ErrWrongObject.GenByArgs(e.DBName, tblInfo.Name.O, "VIEW")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("CREATE ALGORITHM=UNDEFINED ")
+	if definer := tblInfo.View.Definer; definer != nil {
+		buf.WriteString(fmt.Sprintf("DEFINER=`%s`@`%s` ", definer.Username, definer.Hostname))
+	}
+	buf.WriteString(fmt.Sprintf("SQL SECURITY %s VIEW `%s` (", tblInfo.View.Security, tblInfo.Name.O))
+	for i, col := range tblInfo.Columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(fmt.Sprintf("`%s`", col.Name.O))
+	}
+	buf.WriteString(fmt.Sprintf(") AS %s", tblInfo.View.SelectStmt))
+
+	e.rows = append(e.rows, types.MakeDatums(tblInfo.Name.O, buf.String(), tblInfo.Charset, tblInfo.Collate))
+	return nil
+}
+
 // fetchShowCreateDatabase composes show create database result.
 func (e *ShowExec) fetchShowCreateDatabase() error {
 	db, ok := e.is.SchemaByName(e.DBName)
@@ -601,7 +669,12 @@ func (e *ShowExec) fetchShowGrants() error {
 	if checker == nil {
 		return errors.New("miss privilege checker")
 	}
-	gs, err := checker.ShowGrants(e.ctx, e.User)
+	for _, role := range e.Roles {
+		if !isRoleGranted(checker, e.User, role) {
+			return ErrRoleNotGranted.GenByArgs(role.Username, role.Hostname, e.User.String())
+		}
+	}
+	gs, err := checker.ShowGrants(e.ctx, e.User, e.Roles)
 	if err != nil {
 		return errors.Trace(err)
 	}
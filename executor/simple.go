@@ -25,17 +25,20 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tipb/go-binlog"
 )
 
 // SimpleExec represents simple statement executor.
 // For statements do simple execution.
 // includes `UseStmt`, 'SetStmt`, `DoStmt`,
-// `BeginStmt`, `CommitStmt`, `RollbackStmt`.
+// `BeginStmt`, `CommitStmt`, `RollbackStmt`,
+// `SavepointStmt`, `ReleaseSavepointStmt`.
 // TODO: list all simple statements.
 type SimpleExec struct {
 	baseExecutor
@@ -62,6 +65,10 @@ func (e *SimpleExec) Next() (Row, error) {
 		e.executeCommit(x)
 	case *ast.RollbackStmt:
 		err = e.executeRollback(x)
+	case *ast.SavepointStmt:
+		err = e.executeSavepoint(x)
+	case *ast.ReleaseSavepointStmt:
+		err = e.executeReleaseSavepoint(x)
 	case *ast.CreateUserStmt:
 		err = e.executeCreateUser(x)
 	case *ast.AlterUserStmt:
@@ -70,6 +77,14 @@ func (e *SimpleExec) Next() (Row, error) {
 		err = e.executeDropUser(x)
 	case *ast.SetPwdStmt:
 		err = e.executeSetPwd(x)
+	case *ast.GrantRoleStmt:
+		err = e.executeGrantRole(x)
+	case *ast.RevokeRoleStmt:
+		err = e.executeRevokeRole(x)
+	case *ast.SetRoleStmt:
+		err = e.executeSetRole(x)
+	case *ast.SetDefaultRoleStmt:
+		err = e.executeSetDefaultRole(x)
 	case *ast.KillStmt:
 		err = e.executeKillStmt(x)
 	case *ast.BinlogStmt:
@@ -123,6 +138,9 @@ func (e *SimpleExec) executeCommit(s *ast.CommitStmt) {
 }
 
 func (e *SimpleExec) executeRollback(s *ast.RollbackStmt) error {
+	if s.SavepointName != "" {
+		return e.executeRollbackToSavepoint(s)
+	}
 	sessVars := e.ctx.GetSessionVars()
 	log.Infof("[%d] execute rollback statement", sessVars.ConnectionID)
 	sessVars.SetStatusFlag(mysql.ServerStatusInTrans, false)
@@ -132,6 +150,93 @@ func (e *SimpleExec) executeRollback(s *ast.RollbackStmt) error {
 	return nil
 }
 
+// executeSavepoint saves the buffered writes of the transaction and its dirty tables, so that
+// ROLLBACK TO SAVEPOINT can discard the changes made after it.
+func (e *SimpleExec) executeSavepoint(s *ast.SavepointStmt) error {
+	txnCtx := e.ctx.GetSessionVars().TxnCtx
+	binlogValue, err := cloneBinlog(txnCtx.Binlog)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	record := variable.SavepointRecord{
+		Name:          s.Name,
+		MemCheckpoint: e.ctx.Txn().Checkpoint(),
+		Binlog:        binlogValue,
+		TableDeltaMap: cloneTableDeltaMap(txnCtx.TableDeltaMap),
+	}
+	if txnCtx.DirtyDB != nil {
+		record.DirtyDB = txnCtx.DirtyDB.(*dirtyDB).clone()
+	}
+	txnCtx.AddSavepoint(record)
+	return nil
+}
+
+// executeRollbackToSavepoint restores the transaction to the state saved by the savepoint, the
+// savepoint is kept and the savepoints set after it are deleted.
+func (e *SimpleExec) executeRollbackToSavepoint(s *ast.RollbackStmt) error {
+	txnCtx := e.ctx.GetSessionVars().TxnCtx
+	idx := txnCtx.SavepointIndex(s.SavepointName)
+	if idx < 0 {
+		return ErrSavepointNotExists.GenByArgs("SAVEPOINT", s.SavepointName)
+	}
+	record := txnCtx.Savepoints[idx]
+	if err := e.ctx.Txn().RollbackToCheckpoint(record.MemCheckpoint); err != nil {
+		return errors.Trace(err)
+	}
+	// The saved states are copied again, so that the savepoint can be rolled back to more than once.
+	binlogValue, err := cloneBinlog(record.Binlog)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	txnCtx.Binlog = binlogValue
+	txnCtx.TableDeltaMap = cloneTableDeltaMap(record.TableDeltaMap)
+	txnCtx.DirtyDB = nil
+	if record.DirtyDB != nil {
+		txnCtx.DirtyDB = record.DirtyDB.(*dirtyDB).clone()
+	}
+	txnCtx.Savepoints = txnCtx.Savepoints[:idx+1]
+	return nil
+}
+
+// executeReleaseSavepoint deletes the savepoint and the savepoints set after it.
+func (e *SimpleExec) executeReleaseSavepoint(s *ast.ReleaseSavepointStmt) error {
+	txnCtx := e.ctx.GetSessionVars().TxnCtx
+	idx := txnCtx.SavepointIndex(s.Name)
+	if idx < 0 {
+		return ErrSavepointNotExists.GenByArgs("SAVEPOINT", s.Name)
+	}
+	txnCtx.Savepoints = txnCtx.Savepoints[:idx]
+	return nil
+}
+
+// cloneBinlog returns a deep copy of the binlog prewrite value of a transaction context.
+func cloneBinlog(v interface{}) (interface{}, error) {
+	prewriteValue, ok := v.(*binlog.PrewriteValue)
+	if !ok {
+		return nil, nil
+	}
+	data, err := prewriteValue.Marshal()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	clone := new(binlog.PrewriteValue)
+	if err = clone.Unmarshal(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return clone, nil
+}
+
+func cloneTableDeltaMap(m map[int64]variable.TableDelta) map[int64]variable.TableDelta {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[int64]variable.TableDelta, len(m))
+	for id, delta := range m {
+		clone[id] = delta
+	}
+	return clone
+}
+
 func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 	users := make([]string, 0, len(s.Specs))
 	for _, spec := range s.Specs {
@@ -140,26 +245,34 @@ func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 			return errors.Trace(err1)
 		}
 		if exists {
+			if s.IsCreateRole && !s.IfNotExists {
+				return terror.ClassExecutor.New(CodeCannotUser, "Operation CREATE ROLE failed for "+spec.User.String())
+			}
 			if !s.IfNotExists {
 				return errors.New("Duplicate user")
 			}
 			continue
 		}
-		pwd := ""
-		if spec.AuthOpt != nil {
-			if spec.AuthOpt.ByAuthString {
-				pwd = auth.EncodePassword(spec.AuthOpt.AuthString)
-			} else {
-				pwd = auth.EncodePassword(spec.AuthOpt.HashString)
-			}
+		plugin := mysql.AuthName
+		if spec.AuthOpt != nil && spec.AuthOpt.AuthPlugin != "" {
+			plugin = spec.AuthOpt.AuthPlugin
+		}
+		if err := checkAuthPlugin(plugin); err != nil {
+			return errors.Trace(err)
+		}
+		pwd, authString := encodePassword(plugin, spec.AuthOpt)
+		// A role is a locked account, it can't be used to connect.
+		accountLocked := "N"
+		if s.IsCreateRole {
+			accountLocked = "Y"
 		}
-		user := fmt.Sprintf(`("%s", "%s", "%s")`, spec.User.Hostname, spec.User.Username, pwd)
+		user := fmt.Sprintf(`("%s", "%s", "%s", "%s", "%s", "%s")`, spec.User.Hostname, spec.User.Username, pwd, accountLocked, plugin, authString)
 		users = append(users, user)
 	}
 	if len(users) == 0 {
 		return nil
 	}
-	sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password) VALUES %s;`, mysql.SystemDB, mysql.UserTable, strings.Join(users, ", "))
+	sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password, Account_locked, plugin, authentication_string) VALUES %s;`, mysql.SystemDB, mysql.UserTable, strings.Join(users, ", "))
 	_, err := e.ctx.(sqlexec.SQLExecutor).Execute(sql)
 	if err != nil {
 		return errors.Trace(err)
@@ -194,20 +307,28 @@ func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 			}
 			continue
 		}
-		pwd := ""
-		if spec.AuthOpt != nil {
-			if spec.AuthOpt.ByAuthString {
-				pwd = auth.EncodePassword(spec.AuthOpt.AuthString)
-			} else {
-				pwd = auth.EncodePassword(spec.AuthOpt.HashString)
+		// The authentication plugin of the user is kept if IDENTIFIED WITH is not specified.
+		var plugin string
+		if spec.AuthOpt != nil && spec.AuthOpt.AuthPlugin != "" {
+			plugin = spec.AuthOpt.AuthPlugin
+		} else {
+			plugin, err = userAuthPlugin(e.ctx, spec.User.Username, spec.User.Hostname)
+			if err != nil {
+				return errors.Trace(err)
 			}
 		}
-		sql := fmt.Sprintf(`UPDATE %s.%s SET Password = "%s" WHERE Host = "%s" and User = "%s";`,
-			mysql.SystemDB, mysql.UserTable, pwd, spec.User.Hostname, spec.User.Username)
+		if err = checkAuthPlugin(plugin); err != nil {
+			return errors.Trace(err)
+		}
+		pwd, authString := encodePassword(plugin, spec.AuthOpt)
+		sql := fmt.Sprintf(`UPDATE %s.%s SET Password = "%s", plugin = "%s", authentication_string = "%s" WHERE Host = "%s" and User = "%s";`,
+			mysql.SystemDB, mysql.UserTable, pwd, plugin, authString, spec.User.Hostname, spec.User.Username)
 		_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 		if err != nil {
 			failedUsers = append(failedUsers, spec.User.String())
+			continue
 		}
+		evictSha2Digest(e.ctx, spec.User)
 	}
 	if len(failedUsers) > 0 {
 		// Commit the transaction even if we returns error
@@ -234,11 +355,22 @@ func (e *SimpleExec) executeDropUser(s *ast.DropUserStmt) error {
 			}
 			continue
 		}
-		sql := fmt.Sprintf(`DELETE FROM %s.%s WHERE Host = "%s" and User = "%s";`, mysql.SystemDB, mysql.UserTable, user.Hostname, user.Username)
-		_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
-		if err != nil {
-			failedUsers = append(failedUsers, user.String())
+		sqls := []string{
+			fmt.Sprintf(`DELETE FROM %s.%s WHERE Host = "%s" and User = "%s";`, mysql.SystemDB, mysql.UserTable, user.Hostname, user.Username),
+			// Revoke the user or role from all the role edges and default roles it's in.
+			fmt.Sprintf(`DELETE FROM %s.%s WHERE (FROM_HOST = "%s" and FROM_USER = "%s") or (TO_HOST = "%s" and TO_USER = "%s");`,
+				mysql.SystemDB, mysql.RoleEdgeTable, user.Hostname, user.Username, user.Hostname, user.Username),
+			fmt.Sprintf(`DELETE FROM %s.%s WHERE (HOST = "%s" and USER = "%s") or (DEFAULT_ROLE_HOST = "%s" and DEFAULT_ROLE_USER = "%s");`,
+				mysql.SystemDB, mysql.DefaultRoleTable, user.Hostname, user.Username, user.Hostname, user.Username),
 		}
+		for _, sql := range sqls {
+			_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+			if err != nil {
+				failedUsers = append(failedUsers, user.String())
+				break
+			}
+		}
+		evictSha2Digest(e.ctx, user)
 	}
 	if len(failedUsers) > 0 {
 		// Commit the transaction even if we returns error
@@ -246,12 +378,226 @@ func (e *SimpleExec) executeDropUser(s *ast.DropUserStmt) error {
 		if err != nil {
 			return errors.Trace(err)
 		}
-		errMsg := "Operation DROP USER failed for " + strings.Join(failedUsers, ",")
+		op := "DROP USER"
+		if s.IsDropRole {
+			op = "DROP ROLE"
+		}
+		errMsg := "Operation " + op + " failed for " + strings.Join(failedUsers, ",")
 		return terror.ClassExecutor.New(CodeCannotUser, errMsg)
 	}
+	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
+	return nil
+}
+
+func (e *SimpleExec) executeGrantRole(s *ast.GrantRoleStmt) error {
+	if err := e.checkRolesAndUsersExist(s.Roles, s.Users); err != nil {
+		return errors.Trace(err)
+	}
+	for _, user := range s.Users {
+		for _, role := range s.Roles {
+			sql := fmt.Sprintf(`REPLACE INTO %s.%s (FROM_HOST, FROM_USER, TO_HOST, TO_USER) VALUES ("%s", "%s", "%s", "%s");`,
+				mysql.SystemDB, mysql.RoleEdgeTable, role.Hostname, role.Username, user.Hostname, user.Username)
+			if _, _, err := e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
+	return nil
+}
+
+func (e *SimpleExec) executeRevokeRole(s *ast.RevokeRoleStmt) error {
+	if err := e.checkRolesAndUsersExist(s.Roles, s.Users); err != nil {
+		return errors.Trace(err)
+	}
+	for _, user := range s.Users {
+		for _, role := range s.Roles {
+			sql := fmt.Sprintf(`DELETE FROM %s.%s WHERE FROM_HOST = "%s" and FROM_USER = "%s" and TO_HOST = "%s" and TO_USER = "%s";`,
+				mysql.SystemDB, mysql.RoleEdgeTable, role.Hostname, role.Username, user.Hostname, user.Username)
+			if _, _, err := e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql); err != nil {
+				return errors.Trace(err)
+			}
+			sql = fmt.Sprintf(`DELETE FROM %s.%s WHERE HOST = "%s" and USER = "%s" and DEFAULT_ROLE_HOST = "%s" and DEFAULT_ROLE_USER = "%s";`,
+				mysql.SystemDB, mysql.DefaultRoleTable, user.Hostname, user.Username, role.Hostname, role.Username)
+			if _, _, err := e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
+	return nil
+}
+
+func (e *SimpleExec) checkRolesAndUsersExist(roles []*auth.RoleIdentity, users []*auth.UserIdentity) error {
+	for _, role := range roles {
+		exists, err := userExists(e.ctx, role.Username, role.Hostname)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !exists {
+			return ErrUnknownAuthID.GenByArgs(role.Username, role.Hostname)
+		}
+	}
+	for _, user := range users {
+		exists, err := userExists(e.ctx, user.Username, user.Hostname)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !exists {
+			return ErrUnknownAuthID.GenByArgs(user.Username, user.Hostname)
+		}
+	}
+	return nil
+}
+
+// executeSetRole sets the active roles of the current session, the roles must be granted to the current user.
+func (e *SimpleExec) executeSetRole(s *ast.SetRoleStmt) error {
+	vars := e.ctx.GetSessionVars()
+	checker := privilege.GetPrivilegeManager(e.ctx)
+	if checker == nil || vars.User == nil {
+		return nil
+	}
+	user := vars.User
+	switch s.SetRoleOpt {
+	case ast.SetRoleDefault:
+		vars.ActiveRoles = checker.GetDefaultRoles(user.Username, user.Hostname)
+	case ast.SetRoleNone:
+		vars.ActiveRoles = nil
+	case ast.SetRoleAll:
+		vars.ActiveRoles = checker.GetAllRoles(user.Username, user.Hostname)
+	case ast.SetRoleAllExcept:
+		var roles []*auth.RoleIdentity
+		for _, role := range checker.GetAllRoles(user.Username, user.Hostname) {
+			if !containsRole(s.RoleList, role) {
+				roles = append(roles, role)
+			}
+		}
+		vars.ActiveRoles = roles
+	case ast.SetRoleRegular:
+		for _, role := range s.RoleList {
+			if !isRoleGranted(checker, user, role) {
+				return ErrRoleNotGranted.GenByArgs(role.Username, role.Hostname, user.String())
+			}
+		}
+		vars.ActiveRoles = s.RoleList
+	}
 	return nil
 }
 
+// executeSetDefaultRole replaces the default roles of the users, which are activated when the users connect.
+func (e *SimpleExec) executeSetDefaultRole(s *ast.SetDefaultRoleStmt) error {
+	for _, user := range s.UserList {
+		exists, err := userExists(e.ctx, user.Username, user.Hostname)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !exists {
+			return ErrUnknownAuthID.GenByArgs(user.Username, user.Hostname)
+		}
+		granted, err := grantedRoles(e.ctx, user)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var roles []*auth.RoleIdentity
+		switch s.SetRoleOpt {
+		case ast.SetRoleAll:
+			roles = granted
+		case ast.SetRoleRegular:
+			for _, role := range s.RoleList {
+				if !containsRole(granted, role) {
+					return ErrRoleNotGranted.GenByArgs(role.Username, role.Hostname, user.String())
+				}
+			}
+			roles = s.RoleList
+		}
+
+		sql := fmt.Sprintf(`DELETE FROM %s.%s WHERE HOST = "%s" and USER = "%s";`, mysql.SystemDB, mysql.DefaultRoleTable, user.Hostname, user.Username)
+		if _, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql); err != nil {
+			return errors.Trace(err)
+		}
+		for _, role := range roles {
+			sql = fmt.Sprintf(`INSERT INTO %s.%s (HOST, USER, DEFAULT_ROLE_HOST, DEFAULT_ROLE_USER) VALUES ("%s", "%s", "%s", "%s");`,
+				mysql.SystemDB, mysql.DefaultRoleTable, user.Hostname, user.Username, role.Hostname, role.Username)
+			if _, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
+	return nil
+}
+
+// grantedRoles returns the roles granted to the user in mysql.role_edges.
+func grantedRoles(ctx context.Context, user *auth.UserIdentity) ([]*auth.RoleIdentity, error) {
+	sql := fmt.Sprintf(`SELECT FROM_USER, FROM_HOST FROM %s.%s WHERE TO_USER = "%s" AND TO_HOST = "%s";`,
+		mysql.SystemDB, mysql.RoleEdgeTable, user.Username, user.Hostname)
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	roles := make([]*auth.RoleIdentity, 0, len(rows))
+	for _, row := range rows {
+		roles = append(roles, &auth.RoleIdentity{Username: row.Data[0].GetString(), Hostname: row.Data[1].GetString()})
+	}
+	return roles, nil
+}
+
+// isRoleGranted checks whether the role is granted to the user by the privilege cache.
+func isRoleGranted(checker privilege.Manager, user *auth.UserIdentity, role *auth.RoleIdentity) bool {
+	return containsRole(checker.GetAllRoles(user.Username, user.Hostname), role)
+}
+
+func containsRole(roles []*auth.RoleIdentity, role *auth.RoleIdentity) bool {
+	for _, r := range roles {
+		if r.Username == role.Username && r.Hostname == role.Hostname {
+			return true
+		}
+	}
+	return false
+}
+
+// userAuthPlugin returns the authentication plugin of the user.
+func userAuthPlugin(ctx context.Context, name string, host string) (string, error) {
+	sql := fmt.Sprintf(`SELECT plugin FROM %s.%s WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, name, host)
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(rows) == 0 || rows[0].Data[0].GetString() == "" {
+		return mysql.AuthName, nil
+	}
+	return rows[0].Data[0].GetString(), nil
+}
+
+// checkAuthPlugin checks whether the authentication plugin is supported.
+func checkAuthPlugin(plugin string) error {
+	switch plugin {
+	case mysql.AuthName, mysql.AuthCachingSha2Password, mysql.AuthSHA256Password:
+		return nil
+	}
+	return ErrPluginIsNotLoaded.GenByArgs(plugin)
+}
+
+// encodePassword returns the values of the Password and authentication_string columns of the user
+// authenticated by plugin, the password of mysql_native_password is stored in Password and the password
+// hash of the other plugins is stored in authentication_string.
+func encodePassword(plugin string, opt *ast.AuthOption) (pwd string, authString string) {
+	if opt == nil {
+		return "", ""
+	}
+	switch plugin {
+	case mysql.AuthCachingSha2Password, mysql.AuthSHA256Password:
+		if opt.ByAuthString {
+			return "", auth.NewSha2Password(opt.AuthString)
+		}
+		return "", opt.HashString
+	}
+	if opt.ByAuthString {
+		return auth.EncodePassword(opt.AuthString), ""
+	}
+	return auth.EncodePassword(opt.HashString), ""
+}
+
 func userExists(ctx context.Context, name string, host string) (bool, error) {
 	sql := fmt.Sprintf(`SELECT * FROM %s.%s WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, name, host)
 	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
@@ -277,13 +623,29 @@ func (e *SimpleExec) executeSetPwd(s *ast.SetPwdStmt) error {
 		return errors.Trace(ErrPasswordNoMatch)
 	}
 
+	plugin, err := userAuthPlugin(e.ctx, s.User.Username, s.User.Hostname)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	pwd, authString := encodePassword(plugin, &ast.AuthOption{AuthString: s.Password, ByAuthString: true})
+
 	// update mysql.user
-	sql := fmt.Sprintf(`UPDATE %s.%s SET password="%s" WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, auth.EncodePassword(s.Password), s.User.Username, s.User.Hostname)
+	sql := fmt.Sprintf(`UPDATE %s.%s SET password="%s", authentication_string="%s" WHERE User="%s" AND Host="%s";`,
+		mysql.SystemDB, mysql.UserTable, pwd, authString, s.User.Username, s.User.Hostname)
 	_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+	evictSha2Digest(e.ctx, s.User)
 	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
 	return errors.Trace(err)
 }
 
+// evictSha2Digest removes the cached caching_sha2_password digest of the user, so that the old password
+// can't pass the fast authentication after the user is dropped or its password is changed.
+func evictSha2Digest(ctx context.Context, user *auth.UserIdentity) {
+	if h := sessionctx.GetDomain(ctx).PrivilegeHandle(); h != nil {
+		h.EvictSha2Digest(user.Username, user.Hostname)
+	}
+}
+
 func (e *SimpleExec) executeKillStmt(s *ast.KillStmt) error {
 	if s.TiDBExtension {
 		sm := e.ctx.GetSessionManager()
@@ -81,6 +81,75 @@ func (s *testSuite) TestTransaction(c *C) {
 	tk.MustQuery("select * from txn").Check(testkit.Rows("1", "2"))
 }
 
+func (s *testSuite) TestSavepoint(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, v int, key k(v))")
+	tk.MustExec("insert t values (1, 1)")
+
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (2, 2)")
+	tk.MustExec("savepoint s1")
+	tk.MustExec("update t set v = 10 where id = 1")
+	tk.MustExec("delete from t where id = 2")
+	tk.MustExec("insert t values (3, 3)")
+	tk.MustExec("savepoint s2")
+	tk.MustExec("insert t values (4, 4)")
+	tk.MustQuery("select * from t order by id").Check(testkit.Rows("1 10", "3 3", "4 4"))
+
+	tk.MustExec("rollback to savepoint s2")
+	tk.MustQuery("select * from t order by id").Check(testkit.Rows("1 10", "3 3"))
+
+	// Rolling back to a savepoint keeps it and deletes the savepoints set after it.
+	tk.MustExec("ROLLBACK WORK TO S1")
+	tk.MustQuery("select * from t order by id").Check(testkit.Rows("1 1", "2 2"))
+	_, err := tk.Exec("rollback to s2")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSavepointNotExists), IsTrue)
+	tk.MustExec("insert t values (5, 5)")
+	tk.MustExec("rollback to s1")
+	tk.MustQuery("select * from t order by id").Check(testkit.Rows("1 1", "2 2"))
+
+	// A savepoint with the same name replaces the old one.
+	tk.MustExec("insert t values (6, 6)")
+	tk.MustExec("savepoint s1")
+	tk.MustExec("insert t values (7, 7)")
+	tk.MustExec("rollback to s1")
+	tk.MustQuery("select * from t order by id").Check(testkit.Rows("1 1", "2 2", "6 6"))
+
+	// Releasing a savepoint deletes it and the savepoints set after it.
+	tk.MustExec("savepoint s2")
+	tk.MustExec("release savepoint s1")
+	_, err = tk.Exec("rollback to s2")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSavepointNotExists), IsTrue)
+	_, err = tk.Exec("release savepoint s1")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSavepointNotExists), IsTrue)
+	tk.MustExec("commit")
+	tk.MustQuery("select * from t order by id").Check(testkit.Rows("1 1", "2 2", "6 6"))
+	tk.MustQuery("select v from t use index(k) where v > 0 order by v").Check(testkit.Rows("1", "2", "6"))
+	tk.MustExec("admin check table t")
+
+	// Savepoints are deleted when the transaction ends.
+	tk.MustExec("begin")
+	tk.MustExec("savepoint s1")
+	tk.MustExec("rollback")
+	tk.MustExec("begin")
+	_, err = tk.Exec("rollback to s1")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSavepointNotExists), IsTrue)
+	tk.MustExec("rollback")
+
+	// The duplicate key checks of the rolled back writes are discarded too.
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, v int)")
+	tk.MustExec("insert into t values (1,1)")
+	tk.MustExec("begin")
+	tk.MustExec("savepoint sp")
+	tk.MustExec("insert into t values (1,2)")
+	tk.MustExec("rollback to savepoint sp")
+	tk.MustExec("commit")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1"))
+}
+
 func inTxn(ctx context.Context) bool {
 	return (ctx.GetSessionVars().Status & mysql.ServerStatusInTrans) > 0
 }
@@ -170,6 +239,102 @@ func (s *testSuite) TestUser(c *C) {
 	tk.MustExec(dropUserSQL)
 }
 
+func (s *testSuite) TestRole(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec(`CREATE ROLE 'r1', 'r2'@'localhost';`)
+	result := tk.MustQuery(`SELECT Host, User, Account_locked FROM mysql.User WHERE User like "r_" order by User`)
+	result.Check(testkit.Rows("% r1 Y", "localhost r2 Y"))
+	tk.MustExec(`CREATE ROLE IF NOT EXISTS 'r1';`)
+	_, err := tk.Exec(`CREATE ROLE 'r1';`)
+	c.Check(err, NotNil)
+
+	tk.MustExec(`CREATE USER 'testrole'@'localhost';`)
+	tk.MustExec(`GRANT 'r1', 'r2'@'localhost' TO 'testrole'@'localhost';`)
+	result = tk.MustQuery(`SELECT FROM_USER, TO_USER FROM mysql.role_edges order by FROM_USER`)
+	result.Check(testkit.Rows("r1 testrole", "r2 testrole"))
+	_, err = tk.Exec(`GRANT 'r_not_exist' TO 'testrole'@'localhost';`)
+	c.Check(terror.ErrorEqual(err, executor.ErrUnknownAuthID), IsTrue)
+
+	tk.MustExec(`SET DEFAULT ROLE ALL TO 'testrole'@'localhost';`)
+	result = tk.MustQuery(`SELECT DEFAULT_ROLE_USER FROM mysql.default_roles WHERE USER = "testrole" order by DEFAULT_ROLE_USER`)
+	result.Check(testkit.Rows("r1", "r2"))
+	tk.MustExec(`SET DEFAULT ROLE 'r1' TO 'testrole'@'localhost';`)
+	result = tk.MustQuery(`SELECT DEFAULT_ROLE_USER FROM mysql.default_roles WHERE USER = "testrole"`)
+	result.Check(testkit.Rows("r1"))
+	tk.MustExec(`SET DEFAULT ROLE NONE TO 'testrole'@'localhost';`)
+	result = tk.MustQuery(`SELECT DEFAULT_ROLE_USER FROM mysql.default_roles WHERE USER = "testrole"`)
+	result.Check(nil)
+	_, err = tk.Exec(`SET DEFAULT ROLE 'testrole'@'localhost' TO 'testrole'@'localhost';`)
+	c.Check(terror.ErrorEqual(err, executor.ErrRoleNotGranted), IsTrue)
+
+	tk.MustExec(`REVOKE 'r2'@'localhost' FROM 'testrole'@'localhost';`)
+	result = tk.MustQuery(`SELECT FROM_USER FROM mysql.role_edges WHERE TO_USER = "testrole"`)
+	result.Check(testkit.Rows("r1"))
+
+	tk.MustExec("FLUSH PRIVILEGES")
+	tk.MustQuery(`SHOW GRANTS FOR 'testrole'@'localhost' USING 'r1'`).Check(testkit.Rows(
+		"GRANT USAGE ON *.* TO 'testrole'@'localhost'",
+		"GRANT 'r1'@'%' TO 'testrole'@'localhost'"))
+	rs, err := tk.Exec(`SHOW GRANTS FOR 'testrole'@'localhost' USING 'r2'@'localhost'`)
+	c.Check(err, IsNil)
+	_, err = rs.Next()
+	c.Check(terror.ErrorEqual(err, executor.ErrRoleNotGranted), IsTrue)
+	c.Check(rs.Close(), IsNil)
+
+	// The grants of the roles granted to the role are shown too.
+	tk.MustExec(`GRANT 'r2'@'localhost' TO 'r1';`)
+	tk.MustExec(`GRANT Select ON test.* TO 'r2'@'localhost';`)
+	tk.MustExec("FLUSH PRIVILEGES")
+	tk.MustQuery(`SHOW GRANTS FOR 'testrole'@'localhost' USING 'r1'`).Check(testkit.Rows(
+		"GRANT USAGE ON *.* TO 'testrole'@'localhost'",
+		"GRANT Select ON test.* TO 'testrole'@'localhost'",
+		"GRANT 'r1'@'%' TO 'testrole'@'localhost'"))
+	tk.MustExec(`REVOKE Select ON test.* FROM 'r2'@'localhost';`)
+
+	// Dropping a role removes it from the role edges.
+	tk.MustExec(`DROP ROLE 'r1', 'r2'@'localhost';`)
+	result = tk.MustQuery(`SELECT FROM_USER FROM mysql.role_edges WHERE TO_USER = "testrole"`)
+	result.Check(nil)
+	_, err = tk.Exec(`DROP ROLE 'r1';`)
+	c.Check(err, NotNil)
+	tk.MustExec(`DROP USER 'testrole'@'localhost';`)
+}
+
+func (s *testSuite) TestAuthPlugin(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec(`CREATE USER 'sha2'@'localhost' IDENTIFIED WITH 'caching_sha2_password' BY '123';`)
+	tk.MustExec(`CREATE USER 'sha256'@'localhost' IDENTIFIED WITH 'sha256_password';`)
+	tk.MustQuery(`SELECT plugin, Password FROM mysql.User WHERE User="sha256" and Host="localhost"`).Check(
+		testkit.Rows("sha256_password "))
+	hash := tk.MustQuery(`SELECT authentication_string FROM mysql.User WHERE User="sha2" and Host="localhost"`).Rows()[0][0].(string)
+	ok, err := auth.CheckSha2Password(hash, "123")
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+
+	// The plugin is kept when the password is changed.
+	tk.MustExec(`ALTER USER 'sha2'@'localhost' IDENTIFIED BY '456';`)
+	tk.MustExec(`SET PASSWORD FOR 'sha256'@'localhost' = '789';`)
+	tk.MustQuery(`SELECT plugin FROM mysql.User WHERE User like "sha2%" and Host="localhost" order by User`).Check(
+		testkit.Rows("caching_sha2_password", "sha256_password"))
+	hash = tk.MustQuery(`SELECT authentication_string FROM mysql.User WHERE User="sha2" and Host="localhost"`).Rows()[0][0].(string)
+	ok, err = auth.CheckSha2Password(hash, "456")
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	hash = tk.MustQuery(`SELECT authentication_string FROM mysql.User WHERE User="sha256" and Host="localhost"`).Rows()[0][0].(string)
+	ok, err = auth.CheckSha2Password(hash, "789")
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+
+	// Switch back to mysql_native_password.
+	tk.MustExec(`ALTER USER 'sha2'@'localhost' IDENTIFIED WITH 'mysql_native_password' BY '123';`)
+	tk.MustQuery(`SELECT plugin, Password, authentication_string FROM mysql.User WHERE User="sha2" and Host="localhost"`).Check(
+		testkit.Rows("mysql_native_password " + auth.EncodePassword("123") + " "))
+
+	_, err = tk.Exec(`CREATE USER 'unknown_plugin'@'localhost' IDENTIFIED WITH 'unknown_password';`)
+	c.Assert(terror.ErrorEqual(err, executor.ErrPluginIsNotLoaded), IsTrue)
+	tk.MustExec(`DROP USER 'sha2'@'localhost', 'sha256'@'localhost';`)
+}
+
 func (s *testSuite) TestSetPwd(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 
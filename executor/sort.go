@@ -15,20 +15,35 @@ package executor
 
 import (
 	"container/heap"
+	"io/ioutil"
+	"os"
 	"sort"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/filesort"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/types"
 )
 
+// spillWorkers is the number of workers used by the file sorter to sort and flush rows concurrently.
+const spillWorkers = 2
+
 // orderByRow binds a row to its order values, so it can be sorted.
 type orderByRow struct {
 	key []*types.Datum
 	row Row
 }
 
+// memUsage returns the estimated bytes of memory used by the row.
+// The order values mostly share their underlying bytes with the row, so they are not counted.
+func (r *orderByRow) memUsage() int64 {
+	return types.EstimatedMemUsage(r.row)
+}
+
 // SortExec represents sorting executor.
 type SortExec struct {
 	baseExecutor
@@ -39,11 +54,24 @@ type SortExec struct {
 	fetched bool
 	err     error
 	schema  *expression.Schema
+
+	// memTracker tracks the memory used by the buffered rows. When the sort memory quota or the memory
+	// quota of the statement is exceeded, the rows are spilled to fileSorter which performs an external merge sort.
+	memTracker *memory.Tracker
+	fileSorter *filesort.FileSorter
 }
 
 // Close implements the Executor Close interface.
 func (e *SortExec) Close() error {
 	e.Rows = nil
+	if e.fileSorter != nil {
+		terror.Log(errors.Trace(e.fileSorter.Close()))
+		e.fileSorter = nil
+	}
+	if e.memTracker != nil {
+		e.memTracker.Detach()
+		e.memTracker = nil
+	}
 	return errors.Trace(e.children[0].Close())
 }
 
@@ -52,9 +80,107 @@ func (e *SortExec) Open() error {
 	e.fetched = false
 	e.Idx = 0
 	e.Rows = nil
+	if e.fileSorter != nil {
+		terror.Log(errors.Trace(e.fileSorter.Close()))
+		e.fileSorter = nil
+	}
+	e.memTracker = memory.NewTracker("Sort", e.ctx.GetSessionVars().MemQuotaSort)
+	e.memTracker.AttachTo(e.ctx.GetSessionVars().StmtCtx.MemTracker)
 	return errors.Trace(e.children[0].Open())
 }
 
+// buildOrderByRow evaluates the order by items on srcRow.
+func (e *SortExec) buildOrderByRow(srcRow Row) (*orderByRow, error) {
+	orderRow := &orderByRow{
+		row: srcRow,
+		key: make([]*types.Datum, len(e.ByItems)),
+	}
+	for i, byItem := range e.ByItems {
+		key, err := byItem.Expr.Eval(srcRow)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		expression.SetDatumCollation(byItem.Expr, &key)
+		orderRow.key[i] = &key
+	}
+	return orderRow, nil
+}
+
+// spill moves all the buffered rows to a file sorter, the rows fetched afterwards should be
+// put into the file sorter by inputFileSorter too.
+func (e *SortExec) spill() error {
+	tmpDir, err := ioutil.TempDir("", "tidb-sort-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	byDesc := make([]bool, 0, len(e.ByItems))
+	for _, item := range e.ByItems {
+		byDesc = append(byDesc, item.Desc)
+	}
+	// Every run written to disk holds about as many rows as fit in the memory quota.
+	bufSize := len(e.Rows)
+	if bufSize < spillWorkers {
+		bufSize = spillWorkers
+	}
+	e.fileSorter, err = new(filesort.Builder).SetSC(e.ctx.GetSessionVars().StmtCtx).
+		SetSchema(len(e.ByItems), e.schema.Len()).
+		SetBuf(bufSize).
+		SetWorkers(spillWorkers).
+		SetDesc(byDesc).
+		SetDir(tmpDir).
+		Build()
+	if err != nil {
+		terror.Log(errors.Trace(os.RemoveAll(tmpDir)))
+		return errors.Trace(err)
+	}
+	for _, orderRow := range e.Rows {
+		err = e.inputFileSorter(orderRow)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	e.Rows = nil
+	e.memTracker.Consume(-e.memTracker.BytesConsumed())
+	return nil
+}
+
+// inputFileSorter puts a row into the file sorter. Values are flattened the same way
+// as they are stored in the table, so they can be restored by the field types of the schema.
+func (e *SortExec) inputFileSorter(orderRow *orderByRow) error {
+	key := make([]types.Datum, 0, len(orderRow.key))
+	for _, k := range orderRow.key {
+		key = append(key, *k)
+	}
+	loc := e.ctx.GetSessionVars().GetTimeZone()
+	val := make([]types.Datum, 0, len(orderRow.row))
+	for _, d := range orderRow.row {
+		b, err := tablecodec.EncodeValue(d, loc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		val = append(val, types.NewBytesDatum(b))
+	}
+	return errors.Trace(e.fileSorter.Input(key, val, 0))
+}
+
+// nextSpilledRow gets the next sorted row from the file sorter.
+func (e *SortExec) nextSpilledRow() (Row, error) {
+	_, val, _, err := e.fileSorter.Output()
+	if err != nil || val == nil {
+		return nil, errors.Trace(err)
+	}
+	loc := e.ctx.GetSessionVars().GetTimeZone()
+	row := make(Row, 0, len(val))
+	for i, v := range val {
+		d, err := tablecodec.DecodeColumnValue(v.GetBytes(), e.schema.Columns[i].RetType, loc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row = append(row, d)
+	}
+	return row, nil
+}
+
 // Len returns the number of rows.
 func (e *SortExec) Len() int {
 	return len(e.Rows)
@@ -103,25 +229,38 @@ func (e *SortExec) Next() (Row, error) {
 			if srcRow == nil {
 				break
 			}
-			orderRow := &orderByRow{
-				row: srcRow,
-				key: make([]*types.Datum, len(e.ByItems)),
+			orderRow, err := e.buildOrderByRow(srcRow)
+			if err != nil {
+				return nil, errors.Trace(err)
 			}
-			for i, byItem := range e.ByItems {
-				key, err := byItem.Expr.Eval(srcRow)
+			if e.fileSorter != nil {
+				err = e.inputFileSorter(orderRow)
 				if err != nil {
 					return nil, errors.Trace(err)
 				}
-				orderRow.key[i] = &key
+				continue
 			}
 			e.Rows = append(e.Rows, orderRow)
+			e.memTracker.Consume(orderRow.memUsage())
+			if e.memTracker.Exceeded() {
+				err = e.spill()
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+		}
+		if e.fileSorter == nil {
+			sort.Sort(e)
 		}
-		sort.Sort(e)
 		e.fetched = true
 	}
 	if e.err != nil {
 		return nil, errors.Trace(e.err)
 	}
+	if e.fileSorter != nil {
+		row, err := e.nextSpilledRow()
+		return row, errors.Trace(err)
+	}
 	if e.Idx >= len(e.Rows) {
 		return nil, nil
 	}
@@ -132,6 +271,7 @@ func (e *SortExec) Next() (Row, error) {
 
 // TopNExec implements a Top-N algorithm and it is built from a SELECT statement with ORDER BY and LIMIT.
 // Instead of sorting all the rows fetched from the table, it keeps the Top-N elements only in a heap to reduce memory usage.
+// If the offset is so large that the heap exceeds the memory quota, it falls back to an external sort of all the rows.
 type TopNExec struct {
 	SortExec
 	limit      *plan.Limit
@@ -202,17 +342,16 @@ func (e *TopNExec) Next() (Row, error) {
 			if srcRow == nil {
 				break
 			}
-			// build orderRow from srcRow.
-			orderRow := &orderByRow{
-				row: srcRow,
-				key: make([]*types.Datum, len(e.ByItems)),
+			orderRow, err := e.buildOrderByRow(srcRow)
+			if err != nil {
+				return nil, errors.Trace(err)
 			}
-			for i, byItem := range e.ByItems {
-				key, err := byItem.Expr.Eval(srcRow)
+			if e.fileSorter != nil {
+				err = e.inputFileSorter(orderRow)
 				if err != nil {
 					return nil, errors.Trace(err)
 				}
-				orderRow.key[i] = &key
+				continue
 			}
 			if e.totalCount == e.heapSize {
 				// An equivalent of Push and Pop. We don't use the standard Push and Pop
@@ -222,11 +361,25 @@ func (e *TopNExec) Next() (Row, error) {
 					e.Swap(0, e.heapSize)
 					heap.Fix(e, 0)
 				}
+				e.memTracker.Consume(orderRow.memUsage() - e.Rows[e.heapSize].memUsage())
 				e.Rows = e.Rows[:e.heapSize]
 			} else {
 				heap.Push(e, orderRow)
+				e.memTracker.Consume(orderRow.memUsage())
+				if e.memTracker.Exceeded() {
+					e.heapSize = 0
+					err = e.spill()
+					if err != nil {
+						return nil, errors.Trace(err)
+					}
+				}
 			}
 		}
+		if e.fileSorter != nil {
+			e.fetched = true
+			e.Idx = 0
+			return e.nextSpilledTopNRow()
+		}
 		if e.limit.Offset == 0 {
 			sort.Sort(&e.SortExec)
 		} else {
@@ -236,6 +389,9 @@ func (e *TopNExec) Next() (Row, error) {
 		}
 		e.fetched = true
 	}
+	if e.fileSorter != nil {
+		return e.nextSpilledTopNRow()
+	}
 	if e.Idx >= len(e.Rows) {
 		return nil, nil
 	}
@@ -243,3 +399,24 @@ func (e *TopNExec) Next() (Row, error) {
 	e.Idx++
 	return row, nil
 }
+
+// nextSpilledTopNRow skips the first offset rows of the file sorter and returns at most count rows.
+// e.Idx counts the rows that have been read from the file sorter.
+func (e *TopNExec) nextSpilledTopNRow() (Row, error) {
+	for uint64(e.Idx) < e.limit.Offset {
+		row, err := e.nextSpilledRow()
+		if err != nil || row == nil {
+			return nil, errors.Trace(err)
+		}
+		e.Idx++
+	}
+	if uint64(e.Idx) >= e.limit.Offset+e.limit.Count {
+		return nil, nil
+	}
+	row, err := e.nextSpilledRow()
+	if err != nil || row == nil {
+		return nil, errors.Trace(err)
+	}
+	e.Idx++
+	return row, nil
+}
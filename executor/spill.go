@@ -0,0 +1,167 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// spillPartitions is the number of partitions the input of a hash join or a hash aggregation
+// is divided into when the memory quota of the statement is exceeded.
+const spillPartitions = 16
+
+// partitionFiles writes key-value records into a set of temporary files, a record is
+// put into the partition chosen by the hash of its key, so records of the same key are
+// always in the same partition.
+type partitionFiles struct {
+	files   []*os.File
+	writers []*bufio.Writer
+	lenBuf  []byte
+}
+
+// newPartitionFiles creates n partition files named by prefix in dir.
+func newPartitionFiles(dir, prefix string, n int) (*partitionFiles, error) {
+	p := &partitionFiles{
+		files:   make([]*os.File, 0, n),
+		writers: make([]*bufio.Writer, 0, n),
+		lenBuf:  make([]byte, binary.MaxVarintLen64),
+	}
+	for i := 0; i < n; i++ {
+		f, err := os.Create(filepath.Join(dir, prefix+strconv.Itoa(i)))
+		if err != nil {
+			terror.Log(errors.Trace(p.close()))
+			return nil, errors.Trace(err)
+		}
+		p.files = append(p.files, f)
+		p.writers = append(p.writers, bufio.NewWriter(f))
+	}
+	return p, nil
+}
+
+// partition returns the index of the partition that records of key belong to.
+func (p *partitionFiles) partition(key []byte) int {
+	h := fnv.New32a()
+	_, err := h.Write(key)
+	terror.Log(errors.Trace(err))
+	return int(h.Sum32() % uint32(len(p.files)))
+}
+
+// write appends a record to the partition idx.
+func (p *partitionFiles) write(idx int, key, value []byte) error {
+	w := p.writers[idx]
+	for _, b := range [][]byte{key, value} {
+		n := binary.PutUvarint(p.lenBuf, uint64(len(b)))
+		if _, err := w.Write(p.lenBuf[:n]); err != nil {
+			return errors.Trace(err)
+		}
+		if _, err := w.Write(b); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// reader flushes the partition idx and returns a reader of its records from the beginning.
+func (p *partitionFiles) reader(idx int) (*partitionReader, error) {
+	if err := p.writers[idx].Flush(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err := p.files[idx].Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &partitionReader{r: bufio.NewReader(p.files[idx])}, nil
+}
+
+// close closes all the partition files, the files are removed along with their directory by the caller.
+func (p *partitionFiles) close() error {
+	var firstErr error
+	for _, f := range p.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return errors.Trace(firstErr)
+}
+
+// partitionReader reads the records written into a partition file.
+type partitionReader struct {
+	r *bufio.Reader
+}
+
+// next returns the next record, ok is false when all the records are read.
+// The returned slices are newly allocated and can be retained by the caller.
+func (r *partitionReader) next() (key, value []byte, ok bool, err error) {
+	key, err = r.readBytes()
+	if err == io.EOF {
+		return nil, nil, false, nil
+	}
+	if err != nil {
+		return nil, nil, false, errors.Trace(err)
+	}
+	value, err = r.readBytes()
+	if err != nil {
+		return nil, nil, false, errors.Trace(err)
+	}
+	return key, value, true, nil
+}
+
+func (r *partitionReader) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r.r, b)
+	return b, errors.Trace(err)
+}
+
+// encodeRowValues appends the encoded values of row to b.
+func encodeRowValues(b []byte, row Row, loc *time.Location) ([]byte, error) {
+	for _, datum := range row {
+		tmp, err := tablecodec.EncodeValue(datum, loc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		b = append(b, tmp...)
+	}
+	return b, nil
+}
+
+// decodeRowValues decodes a row encoded by encodeRowValues by the column types of schema.
+func decodeRowValues(data []byte, schema *expression.Schema, loc *time.Location) (Row, error) {
+	values := make([]types.Datum, schema.Len())
+	err := codec.SetRawValues(data, values)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = decodeRawValues(values, schema, loc)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return values, nil
+}
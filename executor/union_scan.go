@@ -65,6 +65,26 @@ func (udb *dirtyDB) getDirtyTable(tid int64) *dirtyTable {
 	return dt
 }
 
+// clone returns a copy of udb, the rows are shared since they are never modified in place.
+func (udb *dirtyDB) clone() *dirtyDB {
+	newDB := &dirtyDB{tables: make(map[int64]*dirtyTable, len(udb.tables))}
+	for tid, dt := range udb.tables {
+		newDT := &dirtyTable{
+			addedRows:   make(map[int64]Row, len(dt.addedRows)),
+			deletedRows: make(map[int64]struct{}, len(dt.deletedRows)),
+			truncated:   dt.truncated,
+		}
+		for handle, row := range dt.addedRows {
+			newDT.addedRows[handle] = row
+		}
+		for handle := range dt.deletedRows {
+			newDT.deletedRows[handle] = struct{}{}
+		}
+		newDB.tables[tid] = newDT
+	}
+	return newDB
+}
+
 type dirtyTable struct {
 	// addedRows ...
 	// the key is handle.
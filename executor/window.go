@@ -0,0 +1,444 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/expression/aggregation"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// WindowExec represents a window function executor.
+// Its child is sorted by the partition by items and the order by items, so it buffers the rows of one partition,
+// evaluates the window function for every row of the partition, and returns the rows appended with the results.
+type WindowExec struct {
+	baseExecutor
+
+	sc          *variable.StatementContext
+	windowFunc  *aggregation.WindowFuncDesc
+	partitionBy []expression.Expression
+	orderBy     []*plan.ByItems
+	frame       *plan.WindowFrame
+
+	// rows stores the rows of the current partition, results stores their window function results.
+	rows    []Row
+	results []types.Datum
+	// orderKeys stores the evaluated order by items of the rows.
+	orderKeys [][]types.Datum
+	// rangeKeys stores the order by item of the rows as decimals, it's used by the range frame with offsets.
+	rangeKeys []*types.MyDecimal
+	cursor    int
+
+	// nextRow is the first row of the next partition, which has been fetched from the child.
+	nextRow          Row
+	nextPartitionKey []types.Datum
+	childDone        bool
+}
+
+// Open implements the Executor Open interface.
+func (e *WindowExec) Open() error {
+	e.rows = nil
+	e.results = nil
+	e.cursor = 0
+	e.nextRow = nil
+	e.childDone = false
+	return errors.Trace(e.children[0].Open())
+}
+
+// Close implements the Executor Close interface.
+func (e *WindowExec) Close() error {
+	e.rows = nil
+	e.results = nil
+	e.orderKeys = nil
+	e.rangeKeys = nil
+	e.nextRow = nil
+	return errors.Trace(e.children[0].Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *WindowExec) Next() (Row, error) {
+	for e.cursor >= len(e.rows) {
+		if e.childDone && e.nextRow == nil {
+			return nil, nil
+		}
+		if err := e.fetchPartition(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	row := make(Row, 0, len(e.rows[e.cursor])+1)
+	row = append(row, e.rows[e.cursor]...)
+	row = append(row, e.results[e.cursor])
+	e.cursor++
+	return row, nil
+}
+
+// fetchPartition reads the rows of the next partition and evaluates the window function for them.
+func (e *WindowExec) fetchPartition() error {
+	e.rows = e.rows[:0]
+	e.cursor = 0
+	partitionKey := e.nextPartitionKey
+	if e.nextRow != nil {
+		e.rows = append(e.rows, e.nextRow)
+		e.nextRow = nil
+	}
+	for !e.childDone {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			e.childDone = true
+			break
+		}
+		key, err := evalDatums(e.partitionBy, row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(e.rows) == 0 {
+			partitionKey = key
+		} else {
+			same, err := e.equalDatums(partitionKey, key)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !same {
+				e.nextRow, e.nextPartitionKey = row, key
+				break
+			}
+		}
+		e.rows = append(e.rows, row)
+	}
+	return errors.Trace(e.evalPartition())
+}
+
+func evalDatums(exprs []expression.Expression, row Row) ([]types.Datum, error) {
+	datums := make([]types.Datum, 0, len(exprs))
+	for _, expr := range exprs {
+		d, err := expr.Eval(row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		datums = append(datums, d)
+	}
+	return datums, nil
+}
+
+func (e *WindowExec) equalDatums(a, b []types.Datum) (bool, error) {
+	for i := range a {
+		c, err := a[i].CompareDatum(e.sc, &b[i])
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if c != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isPeer checks whether the two rows of the partition are equal on the order by items.
+func (e *WindowExec) isPeer(i, j int) (bool, error) {
+	return e.equalDatums(e.orderKeys[i], e.orderKeys[j])
+}
+
+// evalPartition evaluates the window function for every row of the current partition.
+func (e *WindowExec) evalPartition() error {
+	e.results = e.results[:0]
+	e.orderKeys = e.orderKeys[:0]
+	orderExprs := make([]expression.Expression, 0, len(e.orderBy))
+	for _, item := range e.orderBy {
+		orderExprs = append(orderExprs, item.Expr)
+	}
+	for _, row := range e.rows {
+		key, err := evalDatums(orderExprs, row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.orderKeys = append(e.orderKeys, key)
+	}
+	switch e.windowFunc.Name {
+	case ast.WindowFuncRowNumber:
+		for i := range e.rows {
+			e.results = append(e.results, types.NewIntDatum(int64(i+1)))
+		}
+		return nil
+	case ast.WindowFuncRank, ast.WindowFuncDenseRank:
+		return errors.Trace(e.evalRank(e.windowFunc.Name == ast.WindowFuncDenseRank))
+	case ast.WindowFuncLag, ast.WindowFuncLead:
+		return errors.Trace(e.evalLagLead(e.windowFunc.Name == ast.WindowFuncLag))
+	}
+	if e.frame.Type == ast.Ranges && (e.isOffsetBound(e.frame.Start) || e.isOffsetBound(e.frame.End)) {
+		if err := e.buildRangeKeys(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	switch e.windowFunc.Name {
+	case ast.WindowFuncFirstValue, ast.WindowFuncLastValue:
+		return errors.Trace(e.evalFirstLastValue(e.windowFunc.Name == ast.WindowFuncFirstValue))
+	}
+	return errors.Trace(e.evalAggregate())
+}
+
+func (e *WindowExec) evalRank(dense bool) error {
+	var rank, denseRank int64
+	for i := range e.rows {
+		newPeerGroup := i == 0
+		if !newPeerGroup {
+			peer, err := e.isPeer(i-1, i)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			newPeerGroup = !peer
+		}
+		if newPeerGroup {
+			rank = int64(i + 1)
+			denseRank++
+		}
+		if dense {
+			e.results = append(e.results, types.NewIntDatum(denseRank))
+		} else {
+			e.results = append(e.results, types.NewIntDatum(rank))
+		}
+	}
+	return nil
+}
+
+func (e *WindowExec) evalLagLead(lag bool) error {
+	args := e.windowFunc.Args
+	offset := int64(1)
+	if len(args) > 1 {
+		d, err := args[1].Eval(nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		offset, err = d.ToInt64(e.sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for i, row := range e.rows {
+		idx := int64(i) + offset
+		if lag {
+			idx = int64(i) - offset
+		}
+		var (
+			d   types.Datum
+			err error
+		)
+		if idx >= 0 && idx < int64(len(e.rows)) {
+			d, err = args[0].Eval(e.rows[idx])
+		} else if len(args) > 2 {
+			d, err = args[2].Eval(row)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.results = append(e.results, d)
+	}
+	return nil
+}
+
+func (e *WindowExec) evalFirstLastValue(first bool) error {
+	for i := range e.rows {
+		start, end, err := e.getFrame(i)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var d types.Datum
+		if start < end {
+			idx := end - 1
+			if first {
+				idx = start
+			}
+			d, err = e.windowFunc.Args[0].Eval(e.rows[idx])
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+		e.results = append(e.results, d)
+	}
+	return nil
+}
+
+// evalAggregate evaluates the aggregate function over the frame of every row. If the frame starts from the
+// first row of the partition, the frame only grows, so the rows are aggregated incrementally.
+func (e *WindowExec) evalAggregate() error {
+	agg := e.windowFunc.NewAggFunction()
+	incremental := e.frame.Start.UnBounded
+	ctx := agg.CreateContext()
+	aggregated := 0
+	for i := range e.rows {
+		start, end, err := e.getFrame(i)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !incremental {
+			ctx, aggregated = agg.CreateContext(), start
+		}
+		for ; aggregated < end; aggregated++ {
+			if err = agg.Update(ctx, e.sc, e.rows[aggregated]); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		e.results = append(e.results, agg.GetResult(ctx))
+	}
+	return nil
+}
+
+func (e *WindowExec) isOffsetBound(bound *plan.FrameBound) bool {
+	return !bound.UnBounded && bound.Type != ast.CurrentRow
+}
+
+// buildRangeKeys converts the order by item of the rows to decimals, the NULL values are kept as nil.
+func (e *WindowExec) buildRangeKeys() error {
+	e.rangeKeys = e.rangeKeys[:0]
+	for _, key := range e.orderKeys {
+		if key[0].IsNull() {
+			e.rangeKeys = append(e.rangeKeys, nil)
+			continue
+		}
+		dec, err := key[0].ToDecimal(e.sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.rangeKeys = append(e.rangeKeys, dec)
+	}
+	return nil
+}
+
+// getFrame returns the frame of the i-th row of the partition as a half open interval [start, end).
+func (e *WindowExec) getFrame(i int) (start, end int, err error) {
+	start, err = e.getBoundPosition(i, e.frame.Start, true)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	end, err = e.getBoundPosition(i, e.frame.End, false)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	return start, end, nil
+}
+
+// getBoundPosition returns the position of the frame bound of the i-th row. For the start bound it's the
+// index of the first row in the frame, for the end bound it's the index after the last row in the frame.
+func (e *WindowExec) getBoundPosition(i int, bound *plan.FrameBound, isStart bool) (int, error) {
+	n := len(e.rows)
+	if bound.UnBounded {
+		if bound.Type == ast.Preceding {
+			return 0, nil
+		}
+		return n, nil
+	}
+	if e.frame.Type == ast.Rows {
+		var pos int
+		switch bound.Type {
+		case ast.CurrentRow:
+			pos = i
+		case ast.Preceding:
+			pos = i - int(bound.Offset.GetInt64())
+		case ast.Following:
+			pos = i + int(bound.Offset.GetInt64())
+		}
+		if !isStart {
+			pos++
+		}
+		if pos < 0 {
+			return 0, nil
+		}
+		if pos > n {
+			return n, nil
+		}
+		return pos, nil
+	}
+	// For the range frame, the NULL values are peers of each other, so the offset bound of a NULL row is
+	// regarded as the current row.
+	if bound.Type == ast.CurrentRow || e.rangeKeys[i] == nil {
+		return e.getPeerBoundPosition(i, isStart)
+	}
+	return e.getRangeBoundPosition(i, bound, isStart)
+}
+
+// getPeerBoundPosition returns the position of the first peer of the i-th row when isStart is true,
+// otherwise returns the position after the last peer.
+func (e *WindowExec) getPeerBoundPosition(i int, isStart bool) (int, error) {
+	pos := i
+	if isStart {
+		for pos > 0 {
+			peer, err := e.isPeer(pos-1, i)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			if !peer {
+				break
+			}
+			pos--
+		}
+		return pos, nil
+	}
+	for pos+1 < len(e.rows) {
+		peer, err := e.isPeer(pos+1, i)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		if !peer {
+			break
+		}
+		pos++
+	}
+	return pos + 1, nil
+}
+
+// getRangeBoundPosition returns the position of "N PRECEDING" or "N FOLLOWING" bound of the i-th row for the
+// range frame. The rows of the partition are sorted by the single order by item, so we can binary search it.
+func (e *WindowExec) getRangeBoundPosition(i int, bound *plan.FrameBound, isStart bool) (int, error) {
+	offset, err := bound.Offset.ToDecimal(e.sc)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	desc := e.orderBy[0].Desc
+	// The bound value is "key - offset" for ascending preceding or descending following,
+	// otherwise it's "key + offset".
+	value := new(types.MyDecimal)
+	if (bound.Type == ast.Preceding) != desc {
+		err = types.DecimalSub(e.rangeKeys[i], offset, value)
+	} else {
+		err = types.DecimalAdd(e.rangeKeys[i], offset, value)
+	}
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	// The NULL values are sorted at the beginning when ascending and at the end when descending,
+	// they are out of the range of any non NULL value.
+	pos := sort.Search(len(e.rows), func(j int) bool {
+		key := e.rangeKeys[j]
+		if key == nil {
+			return desc
+		}
+		c := key.Compare(value)
+		if desc {
+			c = -c
+		}
+		if isStart {
+			return c >= 0
+		}
+		return c > 0
+	})
+	return pos, nil
+}
@@ -132,10 +132,17 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 		return false, errors.Trace(err)
 	}
 
+	oldTID, err := physicalTableID(ctx, t, oldData)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	newTID, err := physicalTableID(ctx, t, newData)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
 	dirtyDB := getDirtyDB(ctx)
-	tid := t.Meta().ID
-	dirtyDB.deleteRow(tid, h)
-	dirtyDB.addRow(tid, h, newData)
+	dirtyDB.deleteRow(oldTID, h)
+	dirtyDB.addRow(newTID, h, newData)
 
 	if onDup {
 		sc.AddAffectedRows(2)
@@ -290,12 +297,34 @@ func (e *DeleteExec) removeRow(ctx context.Context, t table.Table, h int64, data
 	if err != nil {
 		return errors.Trace(err)
 	}
-	getDirtyDB(ctx).deleteRow(t.Meta().ID, h)
+	tid, err := physicalTableID(ctx, t, data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	getDirtyDB(ctx).deleteRow(tid, h)
 	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
 	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.Meta().ID, -1, 1)
 	return nil
 }
 
+// physicalTableID returns the ID of the table that stores the row, it's the ID of the partition
+// for a partitioned table. The rows in union scan are recorded by it.
+func physicalTableID(ctx context.Context, t table.Table, row []types.Datum) (int64, error) {
+	pt, ok := t.(table.PartitionedTable)
+	if !ok {
+		return t.Meta().ID, nil
+	}
+	v, err := pt.PartitionExpr().Eval(row)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	idx, err := pt.LocatePartition(ctx.GetSessionVars().StmtCtx, v)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return t.Meta().Partition.Definitions[idx].ID, nil
+}
+
 // Close implements the Executor Close interface.
 func (e *DeleteExec) Close() error {
 	return e.SelectExec.Close()
@@ -725,7 +754,11 @@ func (e *InsertExec) Next() (Row, error) {
 		h, err := e.Table.AddRecord(e.ctx, row)
 		txn.DelOption(kv.PresumeKeyNotExists)
 		if err == nil {
-			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
+			tid, err := physicalTableID(e.ctx, e.Table, row)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			getDirtyDB(e.ctx).addRow(tid, h, row)
 			rowCount++
 			continue
 		}
@@ -1224,7 +1257,11 @@ func (e *ReplaceExec) Next() (Row, error) {
 		row := rows[idx]
 		h, err1 := e.Table.AddRecord(e.ctx, row)
 		if err1 == nil {
-			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
+			tid, err1 := physicalTableID(e.ctx, e.Table, row)
+			if err1 != nil {
+				return nil, errors.Trace(err1)
+			}
+			getDirtyDB(e.ctx).addRow(tid, h, row)
 			idx++
 			continue
 		}
@@ -1250,7 +1287,11 @@ func (e *ReplaceExec) Next() (Row, error) {
 		if err1 != nil {
 			return nil, errors.Trace(err1)
 		}
-		getDirtyDB(e.ctx).deleteRow(e.Table.Meta().ID, h)
+		tid, err1 := physicalTableID(e.ctx, e.Table, oldRow)
+		if err1 != nil {
+			return nil, errors.Trace(err1)
+		}
+		getDirtyDB(e.ctx).deleteRow(tid, h)
 		e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
 	}
 
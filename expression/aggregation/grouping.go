@@ -0,0 +1,64 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregation
+
+import (
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// groupingFunction is the GROUPING function of GROUP BY ... WITH ROLLUP. Its arguments are group-by items,
+// it returns a bit mask in which the bit of an argument is set if the argument is rolled up in the current
+// row, the first argument is the most significant bit.
+// The result only depends on the grouping set the row belongs to rather than the rows aggregated,
+// so it is filled in by the aggregation executor, see plan.GroupingSet.
+type groupingFunction struct {
+	aggFunction
+}
+
+// Clone implements Aggregation interface.
+func (gf *groupingFunction) Clone() Aggregation {
+	nf := *gf
+	nf.Args = make([]expression.Expression, 0, len(gf.Args))
+	for _, arg := range gf.Args {
+		nf.Args = append(nf.Args, arg.Clone())
+	}
+	return &nf
+}
+
+// GetType implements Aggregation interface.
+func (gf *groupingFunction) GetType() *types.FieldType {
+	ft := types.NewFieldType(mysql.TypeLonglong)
+	ft.Flen = 21
+	ft.Flag |= mysql.NotNullFlag
+	types.SetBinChsClnFlag(ft)
+	return ft
+}
+
+// Update implements Aggregation interface.
+func (gf *groupingFunction) Update(ctx *AggEvaluateContext, sc *variable.StatementContext, row []types.Datum) error {
+	return nil
+}
+
+// GetResult implements Aggregation interface.
+func (gf *groupingFunction) GetResult(ctx *AggEvaluateContext) types.Datum {
+	return types.NewIntDatum(0)
+}
+
+// GetPartialResult implements Aggregation interface.
+func (gf *groupingFunction) GetPartialResult(ctx *AggEvaluateContext) []types.Datum {
+	return []types.Datum{gf.GetResult(ctx)}
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregation
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// WindowFuncDesc describes a window function, it's either a pure window function like ROW_NUMBER
+// or an aggregate function evaluated over a window frame.
+type WindowFuncDesc struct {
+	Name     string
+	Args     []expression.Expression
+	Distinct bool
+	RetTp    *types.FieldType
+}
+
+// NewWindowFuncDesc creates a WindowFuncDesc and infers its return type.
+func NewWindowFuncDesc(name string, args []expression.Expression, distinct bool) *WindowFuncDesc {
+	desc := &WindowFuncDesc{Name: strings.ToLower(name), Args: args, Distinct: distinct}
+	switch desc.Name {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank, ast.WindowFuncDenseRank:
+		ft := types.NewFieldType(mysql.TypeLonglong)
+		ft.Flen = 21
+		types.SetBinChsClnFlag(ft)
+		desc.RetTp = ft
+	case ast.WindowFuncLag, ast.WindowFuncLead, ast.WindowFuncFirstValue, ast.WindowFuncLastValue:
+		desc.RetTp = args[0].GetType()
+	default:
+		desc.RetTp = desc.NewAggFunction().GetType()
+	}
+	return desc
+}
+
+// IsAggregate checks whether the window function is an aggregate function.
+func (desc *WindowFuncDesc) IsAggregate() bool {
+	_, ok := ast.WindowFuncs[desc.Name]
+	return !ok
+}
+
+// NewAggFunction creates the Aggregation used to evaluate an aggregate window function.
+func (desc *WindowFuncDesc) NewAggFunction() Aggregation {
+	return NewAggFunction(desc.Name, desc.Args, desc.Distinct)
+}
+
+// Clone copies a window function description totally.
+func (desc *WindowFuncDesc) Clone() *WindowFuncDesc {
+	args := make([]expression.Expression, 0, len(desc.Args))
+	for _, arg := range desc.Args {
+		args = append(args, arg.Clone())
+	}
+	return &WindowFuncDesc{Name: desc.Name, Args: args, Distinct: desc.Distinct, RetTp: desc.RetTp}
+}
+
+// String implements fmt.Stringer interface.
+func (desc *WindowFuncDesc) String() string {
+	result := desc.Name + "("
+	for i, arg := range desc.Args {
+		result += arg.String()
+		if i+1 != len(desc.Args) {
+			result += ", "
+		}
+	}
+	result += ")"
+	return result
+}
+
+// ExplainWindowFunc generates explain information for a window function.
+func ExplainWindowFunc(desc *WindowFuncDesc) string {
+	buffer := bytes.NewBufferString(fmt.Sprintf("%s(", desc.Name))
+	if desc.Distinct {
+		buffer.WriteString("distinct ")
+	}
+	for i, arg := range desc.Args {
+		buffer.WriteString(arg.ExplainInfo())
+		if i+1 < len(desc.Args) {
+			buffer.WriteString(", ")
+		}
+	}
+	buffer.WriteString(")")
+	return buffer.String()
+}
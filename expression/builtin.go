@@ -39,6 +39,8 @@ type baseBuiltinFunc struct {
 	self     builtinFunc
 	pbCode   tipb.ScalarFuncSig
 	foldable bool // Default value is true because many expressions are foldable.
+	// collator compares the string arguments, it's derived from the collations of the arguments.
+	collator charset.Collator
 }
 
 func (b *baseBuiltinFunc) PbCode() tipb.ScalarFuncSig {
@@ -50,11 +52,13 @@ func (b *baseBuiltinFunc) setPbCode(c tipb.ScalarFuncSig) {
 }
 
 func newBaseBuiltinFunc(ctx context.Context, args []Expression) baseBuiltinFunc {
+	_, collation := deriveCollation(args)
 	return baseBuiltinFunc{
 		args:     args,
 		ctx:      ctx,
 		foldable: true,
 		tp:       types.NewFieldType(mysql.TypeUnspecified),
+		collator: charset.GetCollator(collation),
 	}
 }
 
@@ -145,16 +149,21 @@ func newBaseBuiltinFuncWithTp(ctx context.Context, args []Expression, retType ty
 			Flag:    mysql.BinaryFlag,
 		}
 	}
+	chs, collation := deriveCollation(args)
 	if mysql.HasBinaryFlag(fieldType.Flag) && fieldType.Tp != mysql.TypeJSON {
 		fieldType.Charset, fieldType.Collate = charset.CharsetBin, charset.CollationBin
+	} else if retType == types.ETString && chs != charset.CharsetBin {
+		// The string result inherits the collation of the string arguments.
+		fieldType.Charset, fieldType.Collate = chs, collation
 	} else {
-		fieldType.Charset, fieldType.Collate = charset.CharsetUTF8, charset.CharsetUTF8
+		fieldType.Charset, fieldType.Collate = charset.CharsetUTF8, charset.CollationUTF8
 	}
 	return baseBuiltinFunc{
 		args:     args,
 		ctx:      ctx,
 		foldable: true,
 		tp:       fieldType,
+		collator: charset.GetCollator(collation),
 	}
 }
 
@@ -598,4 +607,18 @@ var funcs = map[string]functionClass{
 	ast.JSONMerge:   &jsonMergeFunctionClass{baseFunctionClass{ast.JSONMerge, 2, -1}},
 	ast.JSONObject:  &jsonObjectFunctionClass{baseFunctionClass{ast.JSONObject, 0, -1}},
 	ast.JSONArray:   &jsonArrayFunctionClass{baseFunctionClass{ast.JSONArray, 0, -1}},
+
+	ast.JSONMergePatch:    &jsonMergePatchFunctionClass{baseFunctionClass{ast.JSONMergePatch, 2, -1}},
+	ast.JSONMergePreserve: &jsonMergeFunctionClass{baseFunctionClass{ast.JSONMergePreserve, 2, -1}},
+	ast.JSONValid:         &jsonValidFunctionClass{baseFunctionClass{ast.JSONValid, 1, 1}},
+	ast.JSONArrayAppend:   &jsonArrayAppendFunctionClass{baseFunctionClass{ast.JSONArrayAppend, 3, -1}},
+	ast.JSONArrayInsert:   &jsonArrayInsertFunctionClass{baseFunctionClass{ast.JSONArrayInsert, 3, -1}},
+	ast.JSONContains:      &jsonContainsFunctionClass{baseFunctionClass{ast.JSONContains, 2, 3}},
+	ast.JSONContainsPath:  &jsonContainsPathFunctionClass{baseFunctionClass{ast.JSONContainsPath, 3, -1}},
+	ast.JSONKeys:          &jsonKeysFunctionClass{baseFunctionClass{ast.JSONKeys, 1, 2}},
+	ast.JSONLength:        &jsonLengthFunctionClass{baseFunctionClass{ast.JSONLength, 1, 2}},
+	ast.JSONDepth:         &jsonDepthFunctionClass{baseFunctionClass{ast.JSONDepth, 1, 1}},
+	ast.JSONSearch:        &jsonSearchFunctionClass{baseFunctionClass{ast.JSONSearch, 3, -1}},
+	ast.JSONQuote:         &jsonQuoteFunctionClass{baseFunctionClass{ast.JSONQuote, 1, 1}},
+	ast.JSONPretty:        &jsonPrettyFunctionClass{baseFunctionClass{ast.JSONPretty, 1, 1}},
 }
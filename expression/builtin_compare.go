@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tidb/util/types/json"
 	"github.com/pingcap/tipb/go-tipb"
@@ -472,7 +473,7 @@ func (b *builtinGreatestStringSig) evalString(row []types.Datum) (max string, is
 		if isNull || err != nil {
 			return max, isNull, errors.Trace(err)
 		}
-		if types.CompareString(v, max) > 0 {
+		if b.collator.Compare(v, max) > 0 {
 			max = v
 		}
 	}
@@ -640,7 +641,7 @@ func (b *builtinLeastStringSig) evalString(row []types.Datum) (min string, isNul
 		if isNull || err != nil {
 			return min, isNull, errors.Trace(err)
 		}
-		if types.CompareString(v, min) < 0 {
+		if b.collator.Compare(v, min) < 0 {
 			min = v
 		}
 	}
@@ -1202,7 +1203,7 @@ type builtinLTStringSig struct {
 }
 
 func (s *builtinLTStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfLT(compareString(s.args, row, s.ctx))
+	return resOfLT(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinLTDurationSig struct {
@@ -1258,7 +1259,7 @@ type builtinLEStringSig struct {
 }
 
 func (s *builtinLEStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfLE(compareString(s.args, row, s.ctx))
+	return resOfLE(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinLEDurationSig struct {
@@ -1314,7 +1315,7 @@ type builtinGTStringSig struct {
 }
 
 func (s *builtinGTStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfGT(compareString(s.args, row, s.ctx))
+	return resOfGT(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinGTDurationSig struct {
@@ -1370,7 +1371,7 @@ type builtinGEStringSig struct {
 }
 
 func (s *builtinGEStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfGE(compareString(s.args, row, s.ctx))
+	return resOfGE(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinGEDurationSig struct {
@@ -1426,7 +1427,7 @@ type builtinEQStringSig struct {
 }
 
 func (s *builtinEQStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfEQ(compareString(s.args, row, s.ctx))
+	return resOfEQ(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinEQDurationSig struct {
@@ -1482,7 +1483,7 @@ type builtinNEStringSig struct {
 }
 
 func (s *builtinNEStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfNE(compareString(s.args, row, s.ctx))
+	return resOfNE(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinNEDurationSig struct {
@@ -1624,7 +1625,7 @@ func (s *builtinNullEQStringSig) evalInt(row []types.Datum) (val int64, isNull b
 		res = 1
 	case isNull0 != isNull1:
 		break
-	case types.CompareString(arg0, arg1) == 0:
+	case s.collator.Compare(arg0, arg1) == 0:
 		res = 1
 	}
 	return res, false, nil
@@ -1819,7 +1820,7 @@ func compareInt(args []Expression, row []types.Datum, ctx context.Context) (val
 	return int64(res), false, nil
 }
 
-func compareString(args []Expression, row []types.Datum, ctx context.Context) (val int64, isNull bool, err error) {
+func compareString(args []Expression, row []types.Datum, ctx context.Context, collator charset.Collator) (val int64, isNull bool, err error) {
 	sc := ctx.GetSessionVars().StmtCtx
 	arg0, isNull0, err := args[0].EvalString(row, sc)
 	if isNull0 || err != nil {
@@ -1829,7 +1830,7 @@ func compareString(args []Expression, row []types.Datum, ctx context.Context) (v
 	if isNull1 || err != nil {
 		return 0, isNull1, errors.Trace(err)
 	}
-	return int64(types.CompareString(arg0, arg1)), false, nil
+	return int64(collator.Compare(arg0, arg1)), false, nil
 }
 
 func compareReal(args []Expression, row []types.Datum, ctx context.Context) (val int64, isNull bool, err error) {
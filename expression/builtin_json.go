@@ -14,11 +14,14 @@
 package expression
 
 import (
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tidb/util/types/json"
 	"github.com/pingcap/tipb/go-tipb"
@@ -26,18 +29,20 @@ import (
 
 // jsonFunctionNameToPB is for pushdown json functions to storage engine.
 var jsonFunctionNameToPB = map[string]tipb.ExprType{
-	ast.JSONType:     tipb.ExprType_JsonType,
-	ast.JSONExtract:  tipb.ExprType_JsonExtract,
-	ast.JSONUnquote:  tipb.ExprType_JsonUnquote,
-	ast.JSONValid:    tipb.ExprType_JsonValid,
-	ast.JSONObject:   tipb.ExprType_JsonObject,
-	ast.JSONArray:    tipb.ExprType_JsonArray,
-	ast.JSONMerge:    tipb.ExprType_JsonMerge,
-	ast.JSONSet:      tipb.ExprType_JsonSet,
-	ast.JSONInsert:   tipb.ExprType_JsonInsert,
-	ast.JSONReplace:  tipb.ExprType_JsonReplace,
-	ast.JSONRemove:   tipb.ExprType_JsonRemove,
-	ast.JSONContains: tipb.ExprType_JsonContains,
+	ast.JSONType:          tipb.ExprType_JsonType,
+	ast.JSONExtract:       tipb.ExprType_JsonExtract,
+	ast.JSONUnquote:       tipb.ExprType_JsonUnquote,
+	ast.JSONValid:         tipb.ExprType_JsonValid,
+	ast.JSONObject:        tipb.ExprType_JsonObject,
+	ast.JSONArray:         tipb.ExprType_JsonArray,
+	ast.JSONMerge:         tipb.ExprType_JsonMerge,
+	ast.JSONMergePreserve: tipb.ExprType_JsonMerge,
+	ast.JSONSet:           tipb.ExprType_JsonSet,
+	ast.JSONInsert:        tipb.ExprType_JsonInsert,
+	ast.JSONReplace:       tipb.ExprType_JsonReplace,
+	ast.JSONRemove:        tipb.ExprType_JsonRemove,
+	ast.JSONContains:      tipb.ExprType_JsonContains,
+	ast.JSONContainsPath:  tipb.ExprType_JsonContainsPath,
 }
 
 var (
@@ -51,6 +56,18 @@ var (
 	_ functionClass = &jsonMergeFunctionClass{}
 	_ functionClass = &jsonObjectFunctionClass{}
 	_ functionClass = &jsonArrayFunctionClass{}
+	_ functionClass = &jsonMergePatchFunctionClass{}
+	_ functionClass = &jsonValidFunctionClass{}
+	_ functionClass = &jsonArrayAppendFunctionClass{}
+	_ functionClass = &jsonArrayInsertFunctionClass{}
+	_ functionClass = &jsonContainsFunctionClass{}
+	_ functionClass = &jsonContainsPathFunctionClass{}
+	_ functionClass = &jsonKeysFunctionClass{}
+	_ functionClass = &jsonLengthFunctionClass{}
+	_ functionClass = &jsonDepthFunctionClass{}
+	_ functionClass = &jsonSearchFunctionClass{}
+	_ functionClass = &jsonQuoteFunctionClass{}
+	_ functionClass = &jsonPrettyFunctionClass{}
 
 	// Type of JSON value.
 	_ builtinFunc = &builtinJSONTypeSig{}
@@ -72,6 +89,33 @@ var (
 	_ builtinFunc = &builtinJSONRemoveSig{}
 	// Merge JSON documents, preserving duplicate keys.
 	_ builtinFunc = &builtinJSONMergeSig{}
+	// Merge JSON documents, replacing values of duplicate keys.
+	_ builtinFunc = &builtinJSONMergePatchSig{}
+	// Whether JSON value is valid.
+	_ builtinFunc = &builtinJSONValidJSONSig{}
+	_ builtinFunc = &builtinJSONValidStringSig{}
+	_ builtinFunc = &builtinJSONValidOthersSig{}
+	// Append data to JSON arrays.
+	_ builtinFunc = &builtinJSONArrayAppendSig{}
+	// Insert data into JSON arrays.
+	_ builtinFunc = &builtinJSONArrayInsertSig{}
+	// Whether JSON document contains specific object at path.
+	_ builtinFunc = &builtinJSONContainsSig{}
+	// Whether JSON document contains any data at paths.
+	_ builtinFunc = &builtinJSONContainsPathSig{}
+	// Keys from JSON object.
+	_ builtinFunc = &builtinJSONKeysSig{}
+	_ builtinFunc = &builtinJSONKeys2ArgsSig{}
+	// Number of elements in JSON document.
+	_ builtinFunc = &builtinJSONLengthSig{}
+	// Maximum depth of JSON document.
+	_ builtinFunc = &builtinJSONDepthSig{}
+	// Path to value within JSON document.
+	_ builtinFunc = &builtinJSONSearchSig{}
+	// Quote JSON document.
+	_ builtinFunc = &builtinJSONQuoteSig{}
+	// Print a JSON document in human-readable format.
+	_ builtinFunc = &builtinJSONPrettySig{}
 )
 
 type jsonTypeFunctionClass struct {
@@ -467,6 +511,15 @@ func (b *builtinJSONArraySig) evalJSON(row []types.Datum) (res json.JSON, isNull
 }
 
 func jsonModify(args []Expression, row []types.Datum, mt json.ModifyType, sc *variable.StatementContext) (res json.JSON, isNull bool, err error) {
+	return jsonModifyBy(args, row, sc, func(j json.JSON, pathExprs []json.PathExpression, values []json.JSON) (json.JSON, error) {
+		return j.Modify(pathExprs, values, mt)
+	})
+}
+
+// jsonModifyBy evaluates the JSON document, the path expressions and the values from args
+// like JSON_SET, and modifies the document by modify.
+func jsonModifyBy(args []Expression, row []types.Datum, sc *variable.StatementContext,
+	modify func(j json.JSON, pathExprs []json.PathExpression, values []json.JSON) (json.JSON, error)) (res json.JSON, isNull bool, err error) {
 	res, isNull, err = args[0].EvalJSON(row, sc)
 	if isNull || err != nil {
 		return res, isNull, errors.Trace(err)
@@ -498,9 +551,561 @@ func jsonModify(args []Expression, row []types.Datum, mt json.ModifyType, sc *va
 		}
 		values = append(values, value)
 	}
-	res, err = res.Modify(pathExprs, values, mt)
+	res, err = modify(res, pathExprs, values)
 	if err != nil {
 		return res, true, errors.Trace(err)
 	}
 	return res, false, nil
 }
+
+// evalPathExpr evaluates the argument and parses it as a JSON path expression,
+// the path expression can't contain any wildcard if noWildcard is true.
+func evalPathExpr(arg Expression, row []types.Datum, sc *variable.StatementContext, noWildcard bool) (pathExpr json.PathExpression, isNull bool, err error) {
+	s, isNull, err := arg.EvalString(row, sc)
+	if isNull || err != nil {
+		return pathExpr, isNull, errors.Trace(err)
+	}
+	pathExpr, err = json.ParseJSONPathExpr(s)
+	if err != nil {
+		return pathExpr, true, errors.Trace(err)
+	}
+	if noWildcard && pathExpr.ContainsAnyAsterisk() {
+		return pathExpr, true, json.ErrInvalidJSONPathWildcard
+	}
+	return pathExpr, false, nil
+}
+
+// evalOneOrAll evaluates the oneOrAll argument of JSON_CONTAINS_PATH and JSON_SEARCH, it returns
+// true for 'one' and false for 'all'.
+func evalOneOrAll(arg Expression, row []types.Datum, sc *variable.StatementContext, funcName string) (one bool, isNull bool, err error) {
+	s, isNull, err := arg.EvalString(row, sc)
+	if isNull || err != nil {
+		return false, isNull, errors.Trace(err)
+	}
+	switch strings.ToLower(s) {
+	case "one":
+		return true, false, nil
+	case "all":
+		return false, false, nil
+	}
+	return false, true, json.ErrJSONBadOneOrAllArg.GenByArgs(funcName)
+}
+
+type jsonMergePatchFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONMergePatchSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonMergePatchFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]types.EvalType, 0, len(args))
+	for range args {
+		argTps = append(argTps, types.ETJson)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, argTps...)
+	for i := range args {
+		args[i].GetType().Flag |= mysql.ParseToJSONFlag
+	}
+	sig := &builtinJSONMergePatchSig{bf}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONMergePatchSig) evalJSON(row []types.Datum) (res json.JSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	values := make([]json.JSON, 0, len(b.args))
+	for _, arg := range b.args {
+		var value json.JSON
+		value, isNull, err = arg.EvalJSON(row, sc)
+		if isNull || err != nil {
+			return res, isNull, errors.Trace(err)
+		}
+		values = append(values, value)
+	}
+	return values[0].MergePatch(values[1:]), false, nil
+}
+
+type jsonValidFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONValidJSONSig struct {
+	baseBuiltinFunc
+}
+
+type builtinJSONValidStringSig struct {
+	baseBuiltinFunc
+}
+
+type builtinJSONValidOthersSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonValidFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var sig builtinFunc
+	switch argTp := args[0].GetType().EvalType(); argTp {
+	case types.ETJson:
+		bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETJson)
+		sig = &builtinJSONValidJSONSig{bf}
+	case types.ETString:
+		bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETString)
+		sig = &builtinJSONValidStringSig{bf}
+	default:
+		bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, argTp)
+		sig = &builtinJSONValidOthersSig{bf}
+	}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONValidJSONSig) evalInt(row []types.Datum) (res int64, isNull bool, err error) {
+	_, isNull, err = b.args[0].EvalJSON(row, b.getCtx().GetSessionVars().StmtCtx)
+	return 1, isNull, errors.Trace(err)
+}
+
+func (b *builtinJSONValidStringSig) evalInt(row []types.Datum) (res int64, isNull bool, err error) {
+	val, isNull, err := b.args[0].EvalString(row, b.getCtx().GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	if _, err = json.ParseFromString(val); err != nil {
+		return 0, false, nil
+	}
+	return 1, false, nil
+}
+
+// evalInt returns 0 for the arguments which are neither JSON nor strings, but NULL is still NULL.
+func (b *builtinJSONValidOthersSig) evalInt(row []types.Datum) (res int64, isNull bool, err error) {
+	d, err := b.args[0].Eval(row)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return 0, d.IsNull(), nil
+}
+
+type jsonArrayAppendFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONArrayAppendSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonArrayAppendFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(args)&1 != 1 {
+		return nil, ErrIncorrectParameterCount.GenByArgs(c.funcName)
+	}
+	argTps := make([]types.EvalType, 0, len(args))
+	argTps = append(argTps, types.ETJson)
+	for i := 1; i < len(args)-1; i += 2 {
+		argTps = append(argTps, types.ETString, types.ETJson)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, argTps...)
+	args[0].GetType().Flag |= mysql.ParseToJSONFlag
+	sig := &builtinJSONArrayAppendSig{bf}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONArrayAppendSig) evalJSON(row []types.Datum) (res json.JSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	res, isNull, err = jsonModifyBy(b.args, row, sc, json.JSON.ArrayAppend)
+	return res, isNull, errors.Trace(err)
+}
+
+type jsonArrayInsertFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONArrayInsertSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonArrayInsertFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(args)&1 != 1 {
+		return nil, ErrIncorrectParameterCount.GenByArgs(c.funcName)
+	}
+	argTps := make([]types.EvalType, 0, len(args))
+	argTps = append(argTps, types.ETJson)
+	for i := 1; i < len(args)-1; i += 2 {
+		argTps = append(argTps, types.ETString, types.ETJson)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, argTps...)
+	args[0].GetType().Flag |= mysql.ParseToJSONFlag
+	sig := &builtinJSONArrayInsertSig{bf}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONArrayInsertSig) evalJSON(row []types.Datum) (res json.JSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	res, isNull, err = jsonModifyBy(b.args, row, sc, json.JSON.ArrayInsert)
+	return res, isNull, errors.Trace(err)
+}
+
+type jsonContainsFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONContainsSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonContainsFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []types.EvalType{types.ETJson, types.ETJson}
+	if len(args) == 3 {
+		argTps = append(argTps, types.ETString)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, argTps...)
+	bf.tp.Flen = 1
+	args[0].GetType().Flag |= mysql.ParseToJSONFlag
+	args[1].GetType().Flag |= mysql.ParseToJSONFlag
+	sig := &builtinJSONContainsSig{bf}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONContainsSig) evalInt(row []types.Datum) (res int64, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	target, isNull, err := b.args[0].EvalJSON(row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	candidate, isNull, err := b.args[1].EvalJSON(row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	if len(b.args) == 3 {
+		var pathExpr json.PathExpression
+		pathExpr, isNull, err = evalPathExpr(b.args[2], row, sc, true)
+		if isNull || err != nil {
+			return 0, isNull, errors.Trace(err)
+		}
+		var found bool
+		if target, found = target.Extract([]json.PathExpression{pathExpr}); !found {
+			return 0, true, nil
+		}
+	}
+	return boolToInt64(json.ContainsJSON(target, candidate)), false, nil
+}
+
+type jsonContainsPathFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONContainsPathSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonContainsPathFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]types.EvalType, 0, len(args))
+	argTps = append(argTps, types.ETJson)
+	for range args[1:] {
+		argTps = append(argTps, types.ETString)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, argTps...)
+	bf.tp.Flen = 1
+	args[0].GetType().Flag |= mysql.ParseToJSONFlag
+	sig := &builtinJSONContainsPathSig{bf}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONContainsPathSig) evalInt(row []types.Datum) (res int64, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	j, isNull, err := b.args[0].EvalJSON(row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	one, isNull, err := evalOneOrAll(b.args[1], row, sc, ast.JSONContainsPath)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	for _, arg := range b.args[2:] {
+		var pathExpr json.PathExpression
+		pathExpr, isNull, err = evalPathExpr(arg, row, sc, false)
+		if isNull || err != nil {
+			return 0, isNull, errors.Trace(err)
+		}
+		_, found := j.Extract([]json.PathExpression{pathExpr})
+		if found && one {
+			return 1, false, nil
+		}
+		if !found && !one {
+			return 0, false, nil
+		}
+	}
+	return boolToInt64(!one), false, nil
+}
+
+type jsonKeysFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONKeysSig struct {
+	baseBuiltinFunc
+}
+
+type builtinJSONKeys2ArgsSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonKeysFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var sig builtinFunc
+	if len(args) == 1 {
+		bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, types.ETJson)
+		sig = &builtinJSONKeysSig{bf}
+	} else {
+		bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, types.ETJson, types.ETString)
+		sig = &builtinJSONKeys2ArgsSig{bf}
+	}
+	args[0].GetType().Flag |= mysql.ParseToJSONFlag
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONKeysSig) evalJSON(row []types.Datum) (res json.JSON, isNull bool, err error) {
+	res, isNull, err = b.args[0].EvalJSON(row, b.getCtx().GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	if res.TypeCode != json.TypeCodeObject {
+		return res, true, nil
+	}
+	return res.Keys(), false, nil
+}
+
+func (b *builtinJSONKeys2ArgsSig) evalJSON(row []types.Datum) (res json.JSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	res, isNull, err = b.args[0].EvalJSON(row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	pathExpr, isNull, err := evalPathExpr(b.args[1], row, sc, true)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	var found bool
+	if res, found = res.Extract([]json.PathExpression{pathExpr}); !found {
+		return res, true, nil
+	}
+	if res.TypeCode != json.TypeCodeObject {
+		return res, true, nil
+	}
+	return res.Keys(), false, nil
+}
+
+type jsonLengthFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONLengthSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonLengthFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []types.EvalType{types.ETJson}
+	if len(args) == 2 {
+		argTps = append(argTps, types.ETString)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, argTps...)
+	args[0].GetType().Flag |= mysql.ParseToJSONFlag
+	sig := &builtinJSONLengthSig{bf}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONLengthSig) evalInt(row []types.Datum) (res int64, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	j, isNull, err := b.args[0].EvalJSON(row, sc)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	if len(b.args) == 2 {
+		var pathExpr json.PathExpression
+		pathExpr, isNull, err = evalPathExpr(b.args[1], row, sc, true)
+		if isNull || err != nil {
+			return 0, isNull, errors.Trace(err)
+		}
+		var found bool
+		if j, found = j.Extract([]json.PathExpression{pathExpr}); !found {
+			return 0, true, nil
+		}
+	}
+	return int64(j.Length()), false, nil
+}
+
+type jsonDepthFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONDepthSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonDepthFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETInt, types.ETJson)
+	args[0].GetType().Flag |= mysql.ParseToJSONFlag
+	sig := &builtinJSONDepthSig{bf}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONDepthSig) evalInt(row []types.Datum) (res int64, isNull bool, err error) {
+	j, isNull, err := b.args[0].EvalJSON(row, b.getCtx().GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return 0, isNull, errors.Trace(err)
+	}
+	return int64(j.Depth()), false, nil
+}
+
+type jsonSearchFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONSearchSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonSearchFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]types.EvalType, 0, len(args))
+	argTps = append(argTps, types.ETJson)
+	for range args[1:] {
+		argTps = append(argTps, types.ETString)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETJson, argTps...)
+	args[0].GetType().Flag |= mysql.ParseToJSONFlag
+	sig := &builtinJSONSearchSig{bf}
+	return sig.setSelf(sig), nil
+}
+
+// evalJSON returns the path to the matched string as a JSON string, or the paths as a JSON array
+// if there are more than one. The strings are matched like LIKE, the escape character is '\\' if the
+// escape argument is NULL or empty.
+func (b *builtinJSONSearchSig) evalJSON(row []types.Datum) (res json.JSON, isNull bool, err error) {
+	sc := b.getCtx().GetSessionVars().StmtCtx
+	j, isNull, err := b.args[0].EvalJSON(row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	one, isNull, err := evalOneOrAll(b.args[1], row, sc, ast.JSONSearch)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	pattern, isNull, err := b.args[2].EvalString(row, sc)
+	if isNull || err != nil {
+		return res, isNull, errors.Trace(err)
+	}
+	escape := byte('\\')
+	if len(b.args) >= 4 {
+		var escapeStr string
+		escapeStr, isNull, err = b.args[3].EvalString(row, sc)
+		if err != nil {
+			return res, true, errors.Trace(err)
+		}
+		if !isNull && len(escapeStr) > 1 {
+			return res, true, errIncorrectArgs.GenByArgs("ESCAPE")
+		}
+		if !isNull && len(escapeStr) == 1 {
+			escape = escapeStr[0]
+		}
+	}
+	pathExprs := make([]json.PathExpression, 0, len(b.args))
+	for i := 4; i < len(b.args); i++ {
+		var pathExpr json.PathExpression
+		pathExpr, isNull, err = evalPathExpr(b.args[i], row, sc, false)
+		if isNull || err != nil {
+			return res, isNull, errors.Trace(err)
+		}
+		pathExprs = append(pathExprs, pathExpr)
+	}
+	patChars, patTypes := stringutil.CompilePattern(pattern, escape)
+	paths := j.Search(pathExprs, func(s string) bool {
+		return stringutil.DoMatch(s, patChars, patTypes)
+	}, one)
+	switch len(paths) {
+	case 0:
+		return res, true, nil
+	case 1:
+		return json.CreateJSON(paths[0]), false, nil
+	}
+	values := make([]json.JSON, 0, len(paths))
+	for _, path := range paths {
+		values = append(values, json.CreateJSON(path))
+	}
+	return json.CreateJSON(values), false, nil
+}
+
+type jsonQuoteFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONQuoteSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonQuoteFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, types.ETString)
+	sig := &builtinJSONQuoteSig{bf}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONQuoteSig) evalString(row []types.Datum) (res string, isNull bool, err error) {
+	s, isNull, err := b.args[0].EvalString(row, b.getCtx().GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	return json.QuoteString(s), false, nil
+}
+
+type jsonPrettyFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONPrettySig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonPrettyFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, types.ETJson)
+	args[0].GetType().Flag |= mysql.ParseToJSONFlag
+	sig := &builtinJSONPrettySig{bf}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONPrettySig) evalString(row []types.Datum) (res string, isNull bool, err error) {
+	j, isNull, err := b.args[0].EvalJSON(row, b.getCtx().GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", isNull, errors.Trace(err)
+	}
+	return j.Pretty(), false, nil
+}
@@ -319,3 +319,126 @@ func (s *testEvaluatorSuite) TestJSONORemove(c *C) {
 		}
 	}
 }
+
+func (s *testEvaluatorSuite) TestJSONContainsAndPath(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		fc       functionClass
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{funcs[ast.JSONContains], []interface{}{nil, `1`}, nil, true},
+		{funcs[ast.JSONContains], []interface{}{`[1, 2]`, `1`}, int64(1), true},
+		{funcs[ast.JSONContains], []interface{}{`{"a": [1, 2]}`, `[2]`, `$.a`}, int64(1), true},
+		{funcs[ast.JSONContains], []interface{}{`{"a": [1, 2]}`, `3`, `$.a`}, int64(0), true},
+		{funcs[ast.JSONContains], []interface{}{`{"a": [1, 2]}`, `1`, `$.b`}, nil, true},
+		{funcs[ast.JSONContains], []interface{}{`{"a": [1, 2]}`, `1`, `$.*`}, nil, false},
+		{funcs[ast.JSONContainsPath], []interface{}{`{"a": 1}`, `one`, `$.a`, `$.b`}, int64(1), true},
+		{funcs[ast.JSONContainsPath], []interface{}{`{"a": 1}`, `ALL`, `$.a`, `$.b`}, int64(0), true},
+		{funcs[ast.JSONContainsPath], []interface{}{`{"a": 1}`, `all`, `$.a`, `$.*`}, int64(1), true},
+		{funcs[ast.JSONContainsPath], []interface{}{`{"a": 1}`, `one`, nil}, nil, true},
+		{funcs[ast.JSONContainsPath], []interface{}{`{"a": 1}`, `any`, `$.a`}, nil, false},
+		{funcs[ast.JSONLength], []interface{}{`[1, 2, {"a": 3}]`}, int64(3), true},
+		{funcs[ast.JSONLength], []interface{}{`[1, 2, {"a": 3}]`, `$[2]`}, int64(1), true},
+		{funcs[ast.JSONLength], []interface{}{`[1, 2, {"a": 3}]`, `$[3]`}, nil, true},
+		{funcs[ast.JSONDepth], []interface{}{`[1, 2, {"a": 3}]`}, int64(3), true},
+		{funcs[ast.JSONDepth], []interface{}{`"a"`}, int64(1), true},
+		{funcs[ast.JSONValid], []interface{}{`{"a": 1}`}, int64(1), true},
+		{funcs[ast.JSONValid], []interface{}{`{"a": 1`}, int64(0), true},
+		{funcs[ast.JSONValid], []interface{}{1}, int64(0), true},
+		{funcs[ast.JSONValid], []interface{}{nil}, nil, true},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := t.fc.getFunction(s.ctx, s.datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		if !t.Success {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		if t.Expected == nil {
+			c.Assert(d.IsNull(), IsTrue)
+		} else {
+			c.Assert(d.GetInt64(), Equals, t.Expected)
+		}
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONKeysSearchAndModify(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		fc       functionClass
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{funcs[ast.JSONKeys], []interface{}{`{"b": 1, "a": 2}`}, `["a", "b"]`, true},
+		{funcs[ast.JSONKeys], []interface{}{`[1]`}, nil, true},
+		{funcs[ast.JSONKeys], []interface{}{`{"a": {"c": 1}}`, `$.a`}, `["c"]`, true},
+		{funcs[ast.JSONKeys], []interface{}{`{"a": {"c": 1}}`, `$.b`}, nil, true},
+		{funcs[ast.JSONSearch], []interface{}{`["abc", {"x": "abd"}]`, `all`, `ab_`}, `["$[0]", "$[1].x"]`, true},
+		{funcs[ast.JSONSearch], []interface{}{`["abc", {"x": "abd"}]`, `one`, `ab_`}, `"$[0]"`, true},
+		{funcs[ast.JSONSearch], []interface{}{`["abc", {"x": "abd"}]`, `all`, `ab_`, nil, `$[1]`}, `"$[1].x"`, true},
+		{funcs[ast.JSONSearch], []interface{}{`["a%c"]`, `all`, `a|%c`, `|`}, `"$[0]"`, true},
+		{funcs[ast.JSONSearch], []interface{}{`["abc"]`, `all`, `x%`}, nil, true},
+		{funcs[ast.JSONSearch], []interface{}{`["abc"]`, `all`, `a%`, `||`}, nil, false},
+		{funcs[ast.JSONArrayAppend], []interface{}{`{"a": 1}`, `$.a`, 2, `$.a`, "3"}, `{"a": [1, 2, "3"]}`, true},
+		{funcs[ast.JSONArrayAppend], []interface{}{`[1]`, `$[*]`, 2}, nil, false},
+		{funcs[ast.JSONArrayInsert], []interface{}{`[1, 2]`, `$[0]`, 0, `$[9]`, 3}, `[0, 1, 2, 3]`, true},
+		{funcs[ast.JSONArrayInsert], []interface{}{`[1, 2]`, `$`, 0}, nil, false},
+		{funcs[ast.JSONMergePatch], []interface{}{`{"a": 1}`, `{"a": null, "b": 2}`}, `{"b": 2}`, true},
+		{funcs[ast.JSONMergePatch], []interface{}{`{"a": 1}`, nil}, nil, true},
+		{funcs[ast.JSONMergePreserve], []interface{}{`{"a": 1}`, `{"a": 2}`}, `{"a": [1, 2]}`, true},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := t.fc.getFunction(s.ctx, s.datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		if !t.Success {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		if t.Expected == nil {
+			c.Assert(d.IsNull(), IsTrue)
+			continue
+		}
+		j1, err := json.ParseFromString(t.Expected.(string))
+		c.Assert(err, IsNil)
+		cmp, err := json.CompareJSON(j1, d.GetMysqlJSON())
+		c.Assert(err, IsNil)
+		c.Assert(cmp, Equals, 0)
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONQuoteAndPretty(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		fc       functionClass
+		Input    interface{}
+		Expected interface{}
+	}{
+		{funcs[ast.JSONQuote], nil, nil},
+		{funcs[ast.JSONQuote], `a"b`, `"a\"b"`},
+		{funcs[ast.JSONQuote], "[1]\n", `"[1]\n"`},
+		{funcs[ast.JSONPretty], nil, nil},
+		{funcs[ast.JSONPretty], `[1, {"a": 2}]`, "[\n  1,\n  {\n    \"a\": 2\n  }\n]"},
+		{funcs[ast.JSONPretty], `{}`, "{}"},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input)
+		f, err := t.fc.getFunction(s.ctx, s.datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		if t.Expected == nil {
+			c.Assert(d.IsNull(), IsTrue)
+		} else {
+			c.Assert(d.GetString(), Equals, t.Expected)
+		}
+	}
+}
@@ -198,7 +198,16 @@ func (b *builtinLengthSig) evalInt(row []types.Datum) (int64, bool, error) {
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	return int64(len([]byte(val))), false, nil
+	return int64(len(encodeStringArg(b.args[0], val))), false, nil
+}
+
+// encodeStringArg returns the bytes of the string argument in its charset, the strings of the
+// transcoded charsets are UTF-8 when they're evaluated.
+func encodeStringArg(arg Expression, str string) string {
+	if chs := arg.GetType().Charset; charset.IsTranscoded(chs) {
+		str, _ = charset.Encode(chs, str)
+	}
+	return str
 }
 
 type asciiFunctionClass struct {
@@ -738,7 +747,7 @@ func (b *builtinStrcmpSig) evalInt(row []types.Datum) (int64, bool, error) {
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(err)
 	}
-	res := types.CompareString(left, right)
+	res := b.collator.Compare(left, right)
 	return int64(res), false, nil
 }
 
@@ -808,8 +817,18 @@ func (c *convertFunctionClass) getFunction(ctx context.Context, args []Expressio
 	}
 	bf := newBaseBuiltinFuncWithTp(ctx, args, types.ETString, types.ETString, types.ETString)
 	// TODO: issue #4436: The second parameter should be a constant.
-	// TODO: issue #4474: Charset supported by TiDB and MySQL is not the same.
-	// TODO: Fix #4436 && #4474, set the correct charset and flag of `bf.tp`.
+	if constant, ok := args[1].(*Constant); ok {
+		name, err := constant.Value.ToString()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if chs, coll, err := charset.GetCharsetInfo(name); err == nil {
+			bf.tp.Charset, bf.tp.Collate = chs, coll
+			if chs == charset.CharsetBin {
+				types.SetBinChsClnFlag(bf.tp)
+			}
+		}
+	}
 	bf.tp.Flen = mysql.MaxBlobWidth
 	sig := &builtinConvertSig{bf}
 	return sig.setSelf(sig), nil
@@ -834,13 +853,17 @@ func (b *builtinConvertSig) evalString(row []types.Datum) (string, bool, error)
 		return "", true, errors.Trace(err)
 	}
 
-	encoding, _ := charset.Lookup(charsetName)
-	if encoding == nil {
+	chs, _, err := charset.GetCharsetInfo(charsetName)
+	if err != nil {
 		return "", true, errUnknownCharacterSet.GenByArgs(charsetName)
 	}
-
-	target, _, err := transform.String(encoding.NewDecoder(), expr)
-	return target, err != nil, errors.Trace(err)
+	if types.IsBinaryStr(b.args[0].GetType()) {
+		// The bytes of the binary string are interpreted as the string in the charset.
+		return charset.Decode(chs, expr), false, nil
+	}
+	// The characters which can't be represented in the charset are replaced by '?'.
+	encoded, _ := charset.Encode(chs, expr)
+	return charset.Decode(chs, encoded), false, nil
 }
 
 type substringFunctionClass struct {
@@ -1267,7 +1290,7 @@ func (b *builtinHexStrArgSig) evalString(row []types.Datum) (string, bool, error
 	if isNull || err != nil {
 		return d, isNull, errors.Trace(err)
 	}
-	return strings.ToUpper(hex.EncodeToString(hack.Slice(d))), false, nil
+	return strings.ToUpper(hex.EncodeToString(hack.Slice(encodeStringArg(b.args[0], d)))), false, nil
 }
 
 type builtinHexIntArgSig struct {
@@ -1788,7 +1811,7 @@ func (b *builtinBitLengthSig) evalInt(row []types.Datum) (int64, bool, error) {
 		return 0, isNull, errors.Trace(err)
 	}
 
-	return int64(len(val) * 8), false, nil
+	return int64(len(encodeStringArg(b.args[0], val)) * 8), false, nil
 }
 
 type charFunctionClass struct {
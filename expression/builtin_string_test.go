@@ -639,19 +639,26 @@ func (s *testEvaluatorSuite) TestSubstring(c *C) {
 func (s *testEvaluatorSuite) TestConvert(c *C) {
 	defer testleak.AfterTest(c)()
 	tbl := []struct {
-		str    string
+		str    interface{}
 		cs     string
 		result string
 	}{
 		{"haha", "utf8", "haha"},
 		{"haha", "ascii", "haha"},
+		{"中文", "gbk", "中文"},
+		{"a😀", "gbk", "a?"},
+		{"a😀", "GB18030", "a😀"},
+		{"café中", "latin1", "café?"},
+		{[]byte("\xd6\xd0\xce\xc4"), "gbk", "中文"},
+		{[]byte("\xe9"), "latin1", "é"},
 	}
 	for _, v := range tbl {
 		fc := funcs[ast.Convert]
-		f, err := fc.getFunction(s.ctx, s.datumsToConstants(types.MakeDatums(v.str, v.cs)))
+		f, err := fc.getFunction(s.ctx, s.primitiveValsToConstants([]interface{}{v.str, v.cs}))
 		c.Assert(err, IsNil)
 		c.Assert(f, NotNil)
 		c.Assert(f.canBeFolded(), IsTrue)
+		c.Assert(f.getRetTp().Charset, Equals, strings.ToLower(v.cs))
 		r, err := f.eval(nil)
 		c.Assert(err, IsNil)
 		c.Assert(r.Kind(), Equals, types.KindString)
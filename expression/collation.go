@@ -0,0 +1,144 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The coercibility of the expressions, the collation of the expression with lower coercibility
+// takes precedence when the collations of the arguments are different.
+// See https://dev.mysql.com/doc/refman/5.7/en/charset-collation-coercibility.html
+const (
+	coercibilityExplicit  = 0
+	coercibilityImplicit  = 2
+	coercibilityCoercible = 4
+	coercibilityIgnorable = 6
+)
+
+// coercibility returns the coercibility of the expression. The expressions with the COLLATE clause
+// are explicit, the columns are implicit, the constants are coercible, and the scalar functions take
+// the lowest coercibility of their string arguments.
+func coercibility(expr Expression) int {
+	if mysql.HasExplicitCollateFlag(expr.GetType().Flag) {
+		return coercibilityExplicit
+	}
+	switch x := expr.(type) {
+	case *Column:
+		return coercibilityImplicit
+	case *Constant:
+		if x.Value.IsNull() {
+			return coercibilityIgnorable
+		}
+		return coercibilityCoercible
+	case *ScalarFunction:
+		coer := coercibilityCoercible
+		for _, arg := range x.GetArgs() {
+			if arg.GetType().EvalType() != types.ETString {
+				continue
+			}
+			if argCoer := coercibility(arg); argCoer < coer {
+				coer = argCoer
+			}
+		}
+		return coer
+	}
+	return coercibilityCoercible
+}
+
+// deriveCollation derives the charset and collation from the string arguments. The collation of
+// the argument with the lowest coercibility is used, and the one comparing strings as binary wins
+// if the arguments of the same coercibility have different collations. It returns utf8 and
+// utf8_bin if there is no string argument.
+func deriveCollation(args []Expression) (chs, coll string) {
+	chs, coll = charset.CharsetUTF8, charset.CollationUTF8
+	minCoer := coercibilityIgnorable + 1
+	for _, arg := range args {
+		tp := arg.GetType()
+		if tp.EvalType() != types.ETString || tp.Collate == "" {
+			continue
+		}
+		coer := coercibility(arg)
+		switch {
+		case coer < minCoer:
+		case coer == minCoer && !charset.IsBinCollation(coll) && charset.IsBinCollation(tp.Collate):
+		case coer == minCoer && tp.Charset == charset.CharsetBin:
+		default:
+			continue
+		}
+		chs, coll, minCoer = tp.Charset, tp.Collate, coer
+	}
+	return chs, coll
+}
+
+// collationID returns the ID of the collation of the field type, it returns 0 if the strings of
+// the field type are compared as binary.
+func collationID(tp *types.FieldType) uint8 {
+	if tp.EvalType() != types.ETString || charset.IsBinCollation(tp.Collate) {
+		return 0
+	}
+	return mysql.CollationNames[tp.Collate]
+}
+
+// SetDatumCollation sets the collation of the datum evaluated from the expression, so the datum
+// is compared under the collation of the expression.
+func SetDatumCollation(expr Expression, d *types.Datum) {
+	if id := collationID(expr.GetType()); id != 0 {
+		d.SetCollation(id)
+	}
+}
+
+// SetCollation returns a copy of the string expression with the collation specified by the COLLATE
+// clause, the collation takes precedence over the collations of the other arguments when the
+// expression is compared.
+func SetCollation(expr Expression, collation string) (Expression, error) {
+	coll, err := charset.GetCollationByName(collation)
+	if err != nil || !charset.ValidCharsetAndCollation(coll.CharsetName, coll.Name) {
+		return nil, errUnknownCollation.GenByArgs(collation)
+	}
+	tp := *expr.GetType()
+	if tp.EvalType() != types.ETString || !compatibleCharset(tp.Charset, coll.CharsetName) {
+		chs := tp.Charset
+		if tp.EvalType() != types.ETString {
+			chs = charset.CharsetBin
+		}
+		return nil, errCollationMismatch.GenByArgs(coll.Name, chs)
+	}
+	tp.Charset, tp.Collate = coll.CharsetName, coll.Name
+	tp.Flag |= mysql.ExplicitCollateFlag
+	switch x := expr.Clone().(type) {
+	case *Column:
+		x.RetType = &tp
+		return x, nil
+	case *Constant:
+		x.RetType = &tp
+		return x, nil
+	case *ScalarFunction:
+		x.RetType = &tp
+		return x, nil
+	}
+	return expr, nil
+}
+
+// compatibleCharset checks whether the strings of the charset can be set a collation of the other
+// charset, utf8 is a subset of utf8mb4 so they are compatible.
+func compatibleCharset(chs, collationCharset string) bool {
+	if chs == "" || chs == collationCharset {
+		return true
+	}
+	isUTF8 := func(c string) bool { return c == charset.CharsetUTF8 || c == charset.CharsetUTF8MB4 }
+	return isUTF8(chs) && isUTF8(collationCharset)
+}
@@ -30,6 +30,8 @@ var (
 	errIncorrectArgs       = terror.ClassExpression.New(mysql.ErrWrongArguments, mysql.MySQLErrName[mysql.ErrWrongArguments])
 	errUnknownCharacterSet = terror.ClassExpression.New(mysql.ErrUnknownCharacterSet, mysql.MySQLErrName[mysql.ErrUnknownCharacterSet])
 	errDefaultValue        = terror.ClassExpression.New(mysql.ErrInvalidDefault, "invalid default value")
+	errUnknownCollation    = terror.ClassExpression.New(mysql.ErrUnknownCollation, mysql.MySQLErrName[mysql.ErrUnknownCollation])
+	errCollationMismatch   = terror.ClassExpression.New(mysql.ErrCollationCharsetMismatch, mysql.MySQLErrName[mysql.ErrCollationCharsetMismatch])
 )
 
 func init() {
@@ -41,6 +43,8 @@ func init() {
 		mysql.ErrWrongArguments:             mysql.ErrWrongArguments,
 		mysql.ErrUnknownCharacterSet:        mysql.ErrUnknownCharacterSet,
 		mysql.ErrInvalidDefault:             mysql.ErrInvalidDefault,
+		mysql.ErrUnknownCollation:           mysql.ErrUnknownCollation,
+		mysql.ErrCollationCharsetMismatch:   mysql.ErrCollationCharsetMismatch,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExpression] = expressionMySQLErrCodes
 }
@@ -73,6 +73,10 @@ func NewPBConverter(client kv.Client, sc *variable.StatementContext) PbConverter
 
 // ExprToPB converts Expression to TiPB.
 func (pc PbConverter) ExprToPB(expr Expression) *tipb.Expr {
+	if mysql.HasExplicitCollateFlag(expr.GetType().Flag) {
+		// The collation specified by the COLLATE clause can't be passed to the coprocessor.
+		return nil
+	}
 	switch x := expr.(type) {
 	case *Constant:
 		return pc.constantToPBExpr(x)
@@ -208,8 +212,8 @@ func (pc PbConverter) scalarFuncToPBExpr(expr *ScalarFunction) *tipb.Expr {
 	case ast.Case, ast.Coalesce, ast.If, ast.Ifnull, ast.IsNull, ast.IsTruth, ast.IsFalsity:
 		return pc.builtinFuncToPBExpr(expr)
 	case ast.JSONType, ast.JSONExtract, ast.JSONUnquote, ast.JSONValid,
-		ast.JSONObject, ast.JSONArray, ast.JSONMerge, ast.JSONSet,
-		ast.JSONInsert, ast.JSONReplace, ast.JSONRemove, ast.JSONContains:
+		ast.JSONObject, ast.JSONArray, ast.JSONMerge, ast.JSONMergePreserve, ast.JSONSet,
+		ast.JSONInsert, ast.JSONReplace, ast.JSONRemove, ast.JSONContains, ast.JSONContainsPath:
 		return pc.jsonFuncToPBExpr(expr)
 	default:
 		return nil
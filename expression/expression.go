@@ -31,6 +31,9 @@ import (
 // EvalAstExpr evaluates ast expression directly.
 var EvalAstExpr func(expr ast.ExprNode, ctx context.Context) (types.Datum, error)
 
+// RewriteAstExpr rewrites ast expression to Expression, the column names in it are resolved by schema.
+var RewriteAstExpr func(expr ast.ExprNode, schema *Schema, ctx context.Context) (Expression, error)
+
 // Expression represents all scalar expression in SQL.
 type Expression interface {
 	fmt.Stringer
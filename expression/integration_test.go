@@ -852,6 +852,8 @@ func (s *testIntegrationSuite) TestStringBuiltin(c *C) {
 	// for convert
 	result = tk.MustQuery(`select convert("中文" using "utf8"), convert(cast("中文" as binary) using "utf8");`)
 	result.Check(testkit.Rows("中文 中文"))
+	result = tk.MustQuery(`select convert("中文" using gbk), convert(unhex('D6D0CEC4') using gbk), convert("a😀" using gbk);`)
+	result.Check(testkit.Rows("中文 中文 a?"))
 
 	// for insert
 	result = tk.MustQuery(`select insert("中文", 1, 1, cast("aaa" as binary)), insert("ba", -1, 1, "aaa"), insert("ba", 1, 100, "aaa"), insert("ba", 100, 1, "aaa");`)
@@ -2879,6 +2881,39 @@ func (s *testIntegrationSuite) TestFuncJSON(c *C) {
 
 	r = tk.MustQuery(`select json_extract(json_object(1,2,3,4), '$."1"')`)
 	r.Check(testkit.Rows("2"))
+
+	r = tk.MustQuery(`select a->'$.a[1]', a->>'$.a[1]', table_json.a->>'$.b' from table_json`)
+	r.Check(testkit.Rows("\"2\" 2 true", "<nil> <nil> <nil>"))
+
+	r = tk.MustQuery(`select json_contains(a, '"d"', '$.c'), json_contains(b, '3'), json_contains_path(a, 'one', '$.b', '$.x'), json_contains_path(a, 'all', '$.b', '$.x') from table_json`)
+	r.Check(testkit.Rows("1 0 1 0", "<nil> 1 0 0"))
+
+	r = tk.MustQuery(`select json_length(a), json_length(a, '$.a'), json_depth(a), json_keys(b) from table_json`)
+	r.Check(testkit.Rows(`4 5 4 ["\"hello\"","a","b","c"]`, "6 <nil> 3 <nil>"))
+
+	r = tk.MustQuery(`select json_search(a, 'one', 'b%'), json_search(a, 'all', '_'), json_search(b, 'all', 'hello%', null, '$[*]') from table_json`)
+	r.Check(testkit.Rows(`"$.a[2].aa" ["$.a[1]","$.c[0]"] <nil>`, `<nil> <nil> "$[3]"`))
+
+	r = tk.MustQuery(`select json_array_append(b, '$.c', 'e'), json_array_insert('[1, 2]', '$[1]', 'x') from table_json where json_type(a) = 'OBJECT'`)
+	r.Check(testkit.Rows(`{"\"hello\"":"world","a":[1,"2",{"aa":"bb"},4,{"aa":"cc"}],"b":true,"c":["d","e"]} [1,"x",2]`))
+
+	r = tk.MustQuery(`select json_merge_patch('{"a": 1, "b": 2}', '{"a": null, "c": 3}'), json_merge_preserve('{"a": 1}', '{"a": 2}')`)
+	r.Check(testkit.Rows(`{"b":2,"c":3} {"a":[1,2]}`))
+
+	r = tk.MustQuery(`select json_quote('a"b'), json_pretty('[1, {"a": 2}]'), json_valid('{"a": 1}'), json_valid('{a}'), json_valid(a), json_valid(1), json_valid(null) from table_json where json_type(a) = 'ARRAY'`)
+	r.Check(testkit.Rows("\"a\\\"b\" [\n  1,\n  {\n    \"a\": 2\n  }\n] 1 0 1 0 <nil>"))
+
+	r = tk.MustQuery(`select b from table_json where a->>'$[3]' = 'hello, world'`)
+	r.Check(testkit.Rows(j2))
+
+	rs, err := tk.Exec(`select json_contains_path(a, 'any', '$.a') from table_json`)
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(err, NotNil)
+	rs, err = tk.Exec(`select json_length(a, '$.*') from table_json`)
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(err, NotNil)
 }
 
 func (s *testIntegrationSuite) TestColumnInfoModified(c *C) {
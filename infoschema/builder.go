@@ -178,6 +178,16 @@ func (b *Builder) applyCreateTable(m *meta.Meta, roDBInfo *model.DBInfo, tableID
 	sortedTbls = append(sortedTbls, tbl)
 	sort.Sort(sortedTbls)
 	b.is.sortedTablesBuckets[bucketIdx] = sortedTbls
+	// The physical tables of partitions can be found by their partition IDs.
+	if pt, ok := tbl.(table.PartitionedTable); ok {
+		for _, def := range tblInfo.Partition.Definitions {
+			b.copySortedTables(0, def.ID)
+			bucketIdx = tableBucketIdx(def.ID)
+			sortedTbls = append(b.is.sortedTablesBuckets[bucketIdx], pt.GetPartition(def.ID))
+			sort.Sort(sortedTbls)
+			b.is.sortedTablesBuckets[bucketIdx] = sortedTbls
+		}
+	}
 
 	newTbl, ok := b.is.TableByID(tableID)
 	if ok {
@@ -193,11 +203,22 @@ func (b *Builder) applyDropTable(roDBInfo *model.DBInfo, tableID int64) {
 	if idx == -1 {
 		return
 	}
+	tblInfo := sortedTbls[idx].Meta()
 	if tableNames, ok := b.is.schemaMap[roDBInfo.Name.L]; ok {
-		delete(tableNames.tables, sortedTbls[idx].Meta().Name.L)
+		delete(tableNames.tables, tblInfo.Name.L)
 	}
 	// Remove the table in sorted table slice.
 	b.is.sortedTablesBuckets[bucketIdx] = append(sortedTbls[0:idx], sortedTbls[idx+1:]...)
+	if tblInfo.Partition != nil {
+		for _, def := range tblInfo.Partition.Definitions {
+			b.copySortedTables(def.ID, 0)
+			bucketIdx = tableBucketIdx(def.ID)
+			sortedTbls = b.is.sortedTablesBuckets[bucketIdx]
+			if idx = sortedTbls.searchTable(def.ID); idx != -1 {
+				b.is.sortedTablesBuckets[bucketIdx] = append(sortedTbls[0:idx], sortedTbls[idx+1:]...)
+			}
+		}
+	}
 
 	// The old DBInfo still holds a reference to old table info, we need to remove it.
 	for i, tblInfo := range roDBInfo.Tables {
@@ -279,6 +300,12 @@ func (b *Builder) createSchemaTablesForDB(di *model.DBInfo) error {
 		schTbls.tables[t.Name.L] = tbl
 		sortedTbls := b.is.sortedTablesBuckets[tableBucketIdx(t.ID)]
 		b.is.sortedTablesBuckets[tableBucketIdx(t.ID)] = append(sortedTbls, tbl)
+		if pt, ok := tbl.(table.PartitionedTable); ok {
+			for _, def := range t.Partition.Definitions {
+				bucketIdx := tableBucketIdx(def.ID)
+				b.is.sortedTablesBuckets[bucketIdx] = append(b.is.sortedTablesBuckets[bucketIdx], pt.GetPartition(def.ID))
+			}
+		}
 	}
 	return nil
 }
@@ -15,9 +15,13 @@ package infoschema
 
 import (
 	"fmt"
+	"os"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta/autoid"
@@ -28,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -63,6 +68,7 @@ const (
 	tableOptimizerTrace                     = "OPTIMIZER_TRACE"
 	tableTableSpaces                        = "TABLESPACES"
 	tableCollationCharacterSetApplicability = "COLLATION_CHARACTER_SET_APPLICABILITY"
+	tableSlowQuery                          = "SLOW_QUERY"
 )
 
 type columnInfo struct {
@@ -520,25 +526,40 @@ var tableCollationCharacterSetApplicabilityCols = []columnInfo{
 	{"TABLESPACE_COMMENT", mysql.TypeVarchar, 2048, 0, nil, nil},
 }
 
+var tableSlowQueryCols = []columnInfo{
+	{"TIME", mysql.TypeDatetime, 26, 0, nil, nil},
+	{"TXN_START_TS", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"USER", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"HOST", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"CONN_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"QUERY_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"LOCK_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"ROWS_SENT", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_EXAMINED", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"DB", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"INDEX_NAMES", mysql.TypeVarchar, 1024, 0, nil, nil},
+	{"COP_TASKS", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"COP_PROCESS_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"COP_WAIT_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"PLAN", mysql.TypeBlob, -1, 0, nil, nil},
+	{"QUERY", mysql.TypeBlob, -1, 0, nil, nil},
+}
+
 func dataForCharacterSets() (records [][]types.Datum) {
-	records = append(records,
-		types.MakeDatums("ascii", "ascii_general_ci", "US ASCII", 1),
-		types.MakeDatums("binary", "binary", "Binary pseudo charset", 1),
-		types.MakeDatums("latin1", "latin1_swedish_ci", "cp1252 West European", 1),
-		types.MakeDatums("utf8", "utf8_general_ci", "UTF-8 Unicode", 3),
-		types.MakeDatums("utf8mb4", "utf8mb4_general_ci", "UTF-8 Unicode", 4),
-	)
+	for _, c := range charset.GetAllCharsets() {
+		records = append(records, types.MakeDatums(c.Name, c.DefaultCollation, c.Desc, c.Maxlen))
+	}
 	return records
 }
 
 func dataForColltions() (records [][]types.Datum) {
-	records = append(records,
-		types.MakeDatums("ascii_general_ci", "ascii", 1, "Yes", "Yes", 1),
-		types.MakeDatums("binary", "binary", 2, "Yes", "Yes", 1),
-		types.MakeDatums("latin1_swedish_ci", "latin1", 3, "Yes", "Yes", 1),
-		types.MakeDatums("utf8_general_ci", "utf8", 4, "Yes", "Yes", 1),
-		types.MakeDatums("utf8mb4_general_ci", "utf8mb4", 5, "Yes", "Yes", 1),
-	)
+	for _, c := range charset.GetCollations() {
+		isDefault := ""
+		if c.IsDefault {
+			isDefault = "Yes"
+		}
+		records = append(records, types.MakeDatums(c.Name, c.CharsetName, c.ID, isDefault, "Yes", 1))
+	}
 	return records
 }
 
@@ -614,6 +635,29 @@ var filesCols = []columnInfo{
 	{"EXTRA", mysql.TypeVarchar, 255, 0, nil, nil},
 }
 
+// dataForSlowQuery parses the rows from the slow query log file, the rotated files aren't read.
+func dataForSlowQuery() ([][]types.Datum, error) {
+	filename := config.GetGlobalConfig().Log.SlowQueryFile
+	if filename == "" {
+		return nil, nil
+	}
+	entries, err := slowlog.ParseFile(filename)
+	if err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return nil, nil
+		}
+		return nil, errors.Trace(err)
+	}
+	rows := make([][]types.Datum, 0, len(entries))
+	for _, e := range entries {
+		t := types.Time{Time: types.FromGoTime(e.Time.In(time.Local)), Type: mysql.TypeDatetime, Fsp: types.MaxFsp}
+		rows = append(rows, types.MakeDatums(t, e.TxnStartTS, e.User, e.Host, e.ConnID, e.QueryTime.Seconds(),
+			e.LockTime.Seconds(), e.RowsSent, e.RowsExamined, e.DB, strings.Join(e.IndexNames, ","), e.CopTasks,
+			e.CopProcessTime.Seconds(), e.CopWaitTime.Seconds(), e.Plan, e.Query))
+	}
+	return rows, nil
+}
+
 func dataForSchemata(schemas []*model.DBInfo) [][]types.Datum {
 	rows := [][]types.Datum{}
 	for _, schema := range schemas {
@@ -633,6 +677,21 @@ func dataForTables(schemas []*model.DBInfo) [][]types.Datum {
 	rows := [][]types.Datum{}
 	for _, schema := range schemas {
 		for _, table := range schema.Tables {
+			if table.IsView() {
+				record := types.MakeDatums(
+					catalogVal,    // TABLE_CATALOG
+					schema.Name.O, // TABLE_SCHEMA
+					table.Name.O,  // TABLE_NAME
+					"VIEW",        // TABLE_TYPE
+				)
+				// All the other columns are NULL except TABLE_COMMENT.
+				for i := len(record); i < len(tablesCols)-1; i++ {
+					record = append(record, types.Datum{})
+				}
+				record = append(record, types.NewStringDatum("VIEW"))
+				rows = append(rows, record)
+				continue
+			}
 			record := types.MakeDatums(
 				catalogVal,      // TABLE_CATALOG
 				schema.Name.O,   // TABLE_SCHEMA
@@ -662,6 +721,95 @@ func dataForTables(schemas []*model.DBInfo) [][]types.Datum {
 	return rows
 }
 
+func dataForViews(schemas []*model.DBInfo) [][]types.Datum {
+	rows := [][]types.Datum{}
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			if !table.IsView() {
+				continue
+			}
+			var definer string
+			if table.View.Definer != nil {
+				definer = table.View.Definer.String()
+			}
+			record := types.MakeDatums(
+				catalogVal,            // TABLE_CATALOG
+				schema.Name.O,         // TABLE_SCHEMA
+				table.Name.O,          // TABLE_NAME
+				table.View.SelectStmt, // VIEW_DEFINITION
+				"NONE",                // CHECK_OPTION
+				"NO",                  // IS_UPDATABLE
+				definer,               // DEFINER
+				table.View.Security,   // SECURITY_TYPE
+				table.Charset,         // CHARACTER_SET_CLIENT
+				table.Collate,         // COLLATION_CONNECTION
+			)
+			rows = append(rows, record)
+		}
+	}
+	return rows
+}
+
+func dataForPartitions(schemas []*model.DBInfo) [][]types.Datum {
+	rows := [][]types.Datum{}
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			if table.IsView() {
+				continue
+			}
+			if table.Partition == nil {
+				// A table that is not partitioned has one row with NULL partition information.
+				record := types.MakeDatums(
+					catalogVal,    // TABLE_CATALOG
+					schema.Name.O, // TABLE_SCHEMA
+					table.Name.O,  // TABLE_NAME
+				)
+				for i := len(record); i < len(partitionsCols); i++ {
+					record = append(record, types.Datum{})
+				}
+				rows = append(rows, record)
+				continue
+			}
+			pi := table.Partition
+			for i, def := range pi.Definitions {
+				var desc interface{}
+				if pi.Type == model.PartitionTypeRange {
+					desc = def.LessThan
+				}
+				record := types.MakeDatums(
+					catalogVal,       // TABLE_CATALOG
+					schema.Name.O,    // TABLE_SCHEMA
+					table.Name.O,     // TABLE_NAME
+					def.Name.O,       // PARTITION_NAME
+					nil,              // SUBPARTITION_NAME
+					uint64(i+1),      // PARTITION_ORDINAL_POSITION
+					nil,              // SUBPARTITION_ORDINAL_POSITION
+					pi.Type.String(), // PARTITION_METHOD
+					nil,              // SUBPARTITION_METHOD
+					pi.Expr,          // PARTITION_EXPRESSION
+					nil,              // SUBPARTITION_EXPRESSION
+					desc,             // PARTITION_DESCRIPTION
+					uint64(0),        // TABLE_ROWS
+					uint64(0),        // AVG_ROW_LENGTH
+					uint64(0),        // DATA_LENGTH
+					nil,              // MAX_DATA_LENGTH
+					uint64(0),        // INDEX_LENGTH
+					uint64(0),        // DATA_FREE
+					nil,              // CREATE_TIME
+					nil,              // UPDATE_TIME
+					nil,              // CHECK_TIME
+					nil,              // CHECKSUM
+					"",               // PARTITION_COMMENT
+					"default",        // NODEGROUP
+					nil,              // TABLESPACE_NAME
+				)
+				rows = append(rows, record)
+			}
+		}
+	}
+	return rows
+}
+
 func dataForColumns(schemas []*model.DBInfo) [][]types.Datum {
 	rows := [][]types.Datum{}
 	for _, schema := range schemas {
@@ -699,18 +847,18 @@ func dataForColumnsInTable(schema *model.DBInfo, tbl *model.TableInfo) [][]types
 			columnDefault,                        // COLUMN_DEFAULT
 			columnDesc.Null,                      // IS_NULLABLE
 			types.TypeToStr(col.Tp, col.Charset), // DATA_TYPE
-			colLen,                            // CHARACTER_MAXIMUM_LENGTH
-			colLen,                            // CHARACTER_OCTET_LENGTH
-			decimal,                           // NUMERIC_PRECISION
-			0,                                 // NUMERIC_SCALE
-			0,                                 // DATETIME_PRECISION
-			col.Charset,                       // CHARACTER_SET_NAME
-			col.Collate,                       // COLLATION_NAME
-			columnType,                        // COLUMN_TYPE
-			columnDesc.Key,                    // COLUMN_KEY
-			columnDesc.Extra,                  // EXTRA
-			"select,insert,update,references", // PRIVILEGES
-			columnDesc.Comment,                // COLUMN_COMMENT
+			colLen,                               // CHARACTER_MAXIMUM_LENGTH
+			colLen,                               // CHARACTER_OCTET_LENGTH
+			decimal,                              // NUMERIC_PRECISION
+			0,                                    // NUMERIC_SCALE
+			0,                                    // DATETIME_PRECISION
+			col.Charset,                          // CHARACTER_SET_NAME
+			col.Collate,                          // COLLATION_NAME
+			columnType,                           // COLUMN_TYPE
+			columnDesc.Key,                       // COLUMN_KEY
+			columnDesc.Extra,                     // EXTRA
+			"select,insert,update,references",    // PRIVILEGES
+			columnDesc.Comment,                   // COLUMN_COMMENT
 		)
 		rows = append(rows, record)
 	}
@@ -970,6 +1118,7 @@ var tableNameToColumns = map[string]([]columnInfo){
 	tableOptimizerTrace:                     tableOptimizerTraceCols,
 	tableTableSpaces:                        tableTableSpacesCols,
 	tableCollationCharacterSetApplicability: tableCollationCharacterSetApplicabilityCols,
+	tableSlowQuery:                          tableSlowQueryCols,
 }
 
 func createInfoSchemaTable(handle *Handle, meta *model.TableInfo) *infoschemaTable {
@@ -1030,6 +1179,7 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableFiles:
 	case tableProfiling:
 	case tablePartitions:
+		fullRows = dataForPartitions(dbs)
 	case tableKeyColumm:
 		fullRows = dataForKeyColumnUsage(dbs)
 	case tableReferConst:
@@ -1039,6 +1189,7 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableEngines:
 		fullRows = dataForEngines()
 	case tableViews:
+		fullRows = dataForViews(dbs)
 	case tableRoutines:
 	// TODO: Fill the following tables.
 	case tableSchemaPrivileges:
@@ -1052,6 +1203,8 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableOptimizerTrace:
 	case tableTableSpaces:
 	case tableCollationCharacterSetApplicability:
+	case tableSlowQuery:
+		fullRows, err = dataForSlowQuery()
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -20,14 +20,17 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -199,6 +202,7 @@ func checkIndexAndRecord(txn kv.Transaction, t table.Table, idx table.Index) err
 	for i, col := range idx.Meta().Columns {
 		cols[i] = t.Cols()[col.Offset]
 	}
+	fieldTypes := tablecodec.IndexStorageTypes(t.Meta(), idx.Meta())
 
 	for {
 		vals1, h, err := it.Next()
@@ -216,7 +220,8 @@ func checkIndexAndRecord(txn kv.Transaction, t table.Table, idx table.Index) err
 		if err != nil {
 			return errors.Trace(err)
 		}
-		if !reflect.DeepEqual(vals1, vals2) {
+		// The index stores the sort keys of the strings compared under non-binary collations.
+		if !reflect.DeepEqual(vals1, tablecodec.CollationKeys(vals2, fieldTypes)) {
 			record1 := &RecordData{Handle: h, Values: vals1}
 			record2 := &RecordData{Handle: h, Values: vals2}
 			return errDateNotEqual.Gen("index:%v != record:%v", record1, record2)
@@ -418,12 +423,16 @@ func rowWithCols(txn kv.Retriever, t table.Table, h int64, cols []*table.Column)
 			}
 			continue
 		}
-		colTps[col.ID] = &col.FieldType
+		colTps[col.ID] = t.Meta().StorageType(col.ToInfo())
 	}
+	addVirtualColumnDeps(t, cols, colTps)
 	row, err := tablecodec.DecodeRow(value, colTps, time.UTC)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if row, err = fillVirtualColumns(t, h, cols, row); err != nil {
+		return nil, errors.Trace(err)
+	}
 	for i, col := range cols {
 		if col == nil {
 			continue
@@ -460,8 +469,9 @@ func iterRecords(retriever kv.Retriever, t table.Table, startKey kv.Key, cols []
 
 	colMap := make(map[int64]*types.FieldType, len(cols))
 	for _, col := range cols {
-		colMap[col.ID] = &col.FieldType
+		colMap[col.ID] = t.Meta().StorageType(col.ToInfo())
 	}
+	addVirtualColumnDeps(t, cols, colMap)
 	prefix := t.RecordPrefix()
 	for it.Valid() && it.Key().HasPrefix(prefix) {
 		// first kv pair is row lock information.
@@ -476,6 +486,9 @@ func iterRecords(retriever kv.Retriever, t table.Table, startKey kv.Key, cols []
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if rowMap, err = fillVirtualColumns(t, handle, cols, rowMap); err != nil {
+			return errors.Trace(err)
+		}
 		data := make([]types.Datum, 0, len(cols))
 		for _, col := range cols {
 			if col.IsPKHandleColumn(t.Meta()) {
@@ -499,6 +512,102 @@ func iterRecords(retriever kv.Retriever, t table.Table, startKey kv.Key, cols []
 	return nil
 }
 
+// addVirtualColumnDeps adds the types of all the writable columns of t to colTps if there is a virtual
+// generated column in cols, its value isn't stored and is calculated from the other columns.
+func addVirtualColumnDeps(t table.Table, cols []*table.Column, colTps map[int64]*types.FieldType) {
+	if !hasVirtualColumn(cols) {
+		return
+	}
+	for _, col := range t.WritableCols() {
+		if col != nil && !col.IsVirtualGenerated() {
+			colTps[col.ID] = t.Meta().StorageType(col.ToInfo())
+		}
+	}
+}
+
+// fillVirtualColumns calculates the values of the virtual generated columns in cols for the record of
+// handle h, and returns rowMap with them.
+func fillVirtualColumns(t table.Table, h int64, cols []*table.Column, rowMap map[int64]types.Datum) (map[int64]types.Datum, error) {
+	if !hasVirtualColumn(cols) {
+		return rowMap, nil
+	}
+	// The context is only used to get the origin default values of the columns missing in the record.
+	row, err := tables.RowWithVirtualColumns(mock.NewContext(), t, h, rowMap)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if rowMap == nil {
+		rowMap = make(map[int64]types.Datum, len(cols))
+	}
+	for _, col := range cols {
+		if col != nil && col.IsVirtualGenerated() {
+			rowMap[col.ID] = row[col.Offset]
+		}
+	}
+	return rowMap, nil
+}
+
+func hasVirtualColumn(cols []*table.Column) bool {
+	for _, col := range cols {
+		if col != nil && col.IsVirtualGenerated() {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareGeneratedColumns compares the values of the stored generated columns of t with the values
+// calculated by their generation expressions record by record.
+func CompareGeneratedColumns(ctx context.Context, txn kv.Transaction, t table.Table) error {
+	var genCols []*table.Column
+	for _, col := range t.Cols() {
+		if col != nil && col.IsGenerated() && col.GeneratedStored && col.GeneratedEvalExpr != nil {
+			genCols = append(genCols, col)
+		}
+	}
+	if len(genCols) == 0 {
+		return nil
+	}
+
+	cols := make([]*table.Column, 0, len(t.Cols()))
+	for _, col := range t.Cols() {
+		if col != nil {
+			cols = append(cols, col)
+		}
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	filterFunc := func(h int64, vals []types.Datum, cols []*table.Column) (bool, error) {
+		row := make([]types.Datum, len(t.Meta().Columns))
+		for i, col := range cols {
+			row[col.Offset] = vals[i]
+		}
+		for _, col := range genCols {
+			val, err := col.GeneratedEvalExpr.Eval(row)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			val, err = table.CastValue(ctx, val, col.ToInfo())
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			cmp, err := val.CompareDatum(sc, &row[col.Offset])
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			if cmp != 0 {
+				record1 := &RecordData{Handle: h, Values: []types.Datum{val}}
+				record2 := &RecordData{Handle: h, Values: []types.Datum{row[col.Offset]}}
+				return false, errDateNotEqual.Gen("generated column %s:%v != record:%v", col.Name, record1, record2)
+			}
+		}
+		return true, nil
+	}
+
+	startKey := t.RecordKey(0)
+	err := iterRecords(txn, t, startKey, cols, filterFunc)
+	return errors.Trace(err)
+}
+
 // inspectkv error codes.
 const (
 	codeDataNotEqual       terror.ErrCode = 1
@@ -14,6 +14,8 @@
 package kv
 
 import (
+	"time"
+
 	"github.com/pingcap/tidb/store/tikv/oracle"
 	goctx "golang.org/x/net/context"
 )
@@ -109,6 +111,12 @@ type MemBuffer interface {
 	Size() int
 	// Len returns the number of entries in the DB.
 	Len() int
+	// Checkpoint returns a checkpoint of the buffered writes, the writes made
+	// after it can be discarded by RollbackToCheckpoint.
+	Checkpoint() int
+	// RollbackToCheckpoint discards the writes made after the checkpoint, the
+	// checkpoints taken after it become invalid.
+	RollbackToCheckpoint(cp int) error
 }
 
 // Transaction defines the interface for operations inside a Transaction.
@@ -185,6 +193,15 @@ type Request struct {
 	NotFillCache bool
 	// SyncLog decides whether the WAL(write-ahead log) of this request should be synchronized.
 	SyncLog bool
+	// CopStats collects the execution statistics of the coprocessor tasks if it isn't nil.
+	CopStats CopStatsCollector
+}
+
+// CopStatsCollector collects the execution statistics of the coprocessor tasks.
+type CopStatsCollector interface {
+	// RecordCopTask records a finished coprocessor task, processTime is the time spent on handling
+	// the task and waitTime is the time the task waits before being handled.
+	RecordCopTask(processTime, waitTime time.Duration)
 }
 
 // Response represents the response returned from KV layer.
@@ -240,6 +240,41 @@ func (s *testKVSuite) TestBufferLimit(c *C) {
 	c.Assert(err, NotNil) // buffer len limit
 }
 
+func (s *testKVSuite) TestCheckpoint(c *C) {
+	buffer := NewMemDbBuffer()
+	c.Assert(buffer.Set([]byte("a"), []byte("1")), IsNil)
+	c.Assert(buffer.Set([]byte("b"), []byte("1")), IsNil)
+
+	cp1 := buffer.Checkpoint()
+	c.Assert(buffer.Set([]byte("a"), []byte("2")), IsNil)
+	c.Assert(buffer.Delete([]byte("b")), IsNil)
+	c.Assert(buffer.Set([]byte("c"), []byte("2")), IsNil)
+
+	cp2 := buffer.Checkpoint()
+	c.Assert(buffer.Set([]byte("a"), []byte("3")), IsNil)
+	c.Assert(buffer.Set([]byte("d"), []byte("3")), IsNil)
+
+	c.Assert(buffer.RollbackToCheckpoint(cp2), IsNil)
+	checkBuffer := func(expected map[string]string) {
+		c.Assert(buffer.Len(), Equals, len(expected))
+		for k, v := range expected {
+			val, err := buffer.Get([]byte(k))
+			c.Assert(err, IsNil)
+			c.Assert(string(val), Equals, v)
+		}
+	}
+	// The deleted entry is kept as an empty value in the buffer.
+	checkBuffer(map[string]string{"a": "2", "b": "", "c": "2"})
+
+	// Rolling back to a checkpoint again is allowed.
+	c.Assert(buffer.Set([]byte("e"), []byte("4")), IsNil)
+	c.Assert(buffer.RollbackToCheckpoint(cp2), IsNil)
+	checkBuffer(map[string]string{"a": "2", "b": "", "c": "2"})
+
+	c.Assert(buffer.RollbackToCheckpoint(cp1), IsNil)
+	checkBuffer(map[string]string{"a": "1", "b": "1"})
+}
+
 var opCnt = 100000
 
 func BenchmarkMemDbBufferSequential(b *testing.B) {
@@ -32,6 +32,17 @@ type memDbBuffer struct {
 	entrySizeLimit  int
 	bufferLenLimit  uint64
 	bufferSizeLimit int
+	// undoLog records the previous states of the entries written after the first checkpoint,
+	// a checkpoint is the length of undoLog when it is taken.
+	undoLog      []undoEntry
+	checkpointed bool
+}
+
+// undoEntry is the state of an entry before it is written.
+type undoEntry struct {
+	key    Key
+	value  []byte
+	exists bool
 }
 
 type memDbIter struct {
@@ -93,6 +104,7 @@ func (m *memDbBuffer) Set(k Key, v []byte) error {
 		return ErrEntryTooLarge.Gen("entry too large, size: %d", len(k)+len(v))
 	}
 
+	m.recordUndo(k)
 	err := m.db.Put(k, v)
 	if m.Size() > m.bufferSizeLimit {
 		return ErrTxnTooLarge.Gen("transaction too large, size:%d", m.Size())
@@ -105,6 +117,7 @@ func (m *memDbBuffer) Set(k Key, v []byte) error {
 
 // Delete removes the entry from buffer with provided key.
 func (m *memDbBuffer) Delete(k Key) error {
+	m.recordUndo(k)
 	err := m.db.Put(k, nil)
 	return errors.Trace(err)
 }
@@ -119,6 +132,45 @@ func (m *memDbBuffer) Len() int {
 	return m.db.Len()
 }
 
+// Checkpoint implements the MemBuffer Checkpoint interface.
+func (m *memDbBuffer) Checkpoint() int {
+	m.checkpointed = true
+	return len(m.undoLog)
+}
+
+// RollbackToCheckpoint implements the MemBuffer RollbackToCheckpoint interface.
+func (m *memDbBuffer) RollbackToCheckpoint(cp int) error {
+	for i := len(m.undoLog) - 1; i >= cp; i-- {
+		e := m.undoLog[i]
+		var err error
+		if e.exists {
+			err = m.db.Put(e.key, e.value)
+		} else {
+			err = m.db.Delete(e.key)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if cp < len(m.undoLog) {
+		m.undoLog = m.undoLog[:cp]
+	}
+	return nil
+}
+
+// recordUndo saves the current state of the entry of k if any checkpoint has been taken.
+func (m *memDbBuffer) recordUndo(k Key) {
+	if !m.checkpointed {
+		return
+	}
+	e := undoEntry{key: k.Clone()}
+	v, err := m.db.Get(k)
+	if err == nil {
+		e.value, e.exists = append([]byte(nil), v...), true
+	}
+	m.undoLog = append(m.undoLog, e)
+}
+
 // Next implements the Iterator Next.
 func (i *memDbIter) Next() error {
 	if i.reverse {
@@ -94,6 +94,14 @@ func (t *mockTxn) Size() int {
 	return 0
 }
 
+func (t *mockTxn) Checkpoint() int {
+	return 0
+}
+
+func (t *mockTxn) RollbackToCheckpoint(cp int) error {
+	return nil
+}
+
 // mockStorage is used to start a must commit-failed txn.
 type mockStorage struct {
 }
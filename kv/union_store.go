@@ -61,6 +61,23 @@ type unionStore struct {
 	snapshot           Snapshot                    // for read
 	lazyConditionPairs map[string](*conditionPair) // for delay check
 	opts               options
+
+	// conditionUndoLog records the previous lazy condition pairs of the keys marked after the first
+	// checkpoint, checkpoints records the states of the MemBuffer and conditionUndoLog when they are taken.
+	conditionUndoLog []conditionUndoEntry
+	checkpoints      []unionCheckpoint
+}
+
+// conditionUndoEntry is the lazy condition pair of a key before it is marked.
+type conditionUndoEntry struct {
+	key  string
+	pair *conditionPair
+}
+
+// unionCheckpoint is a checkpoint of the buffered writes and the lazy condition pairs.
+type unionCheckpoint struct {
+	mem       int
+	condition int
 }
 
 // NewUnionStore builds a new UnionStore.
@@ -151,6 +168,20 @@ func (lmb *lazyMemBuffer) Len() int {
 	return lmb.mb.Len()
 }
 
+func (lmb *lazyMemBuffer) Checkpoint() int {
+	if lmb.mb == nil {
+		lmb.mb = NewMemDbBuffer()
+	}
+	return lmb.mb.Checkpoint()
+}
+
+func (lmb *lazyMemBuffer) RollbackToCheckpoint(cp int) error {
+	if lmb.mb == nil {
+		return nil
+	}
+	return lmb.mb.RollbackToCheckpoint(cp)
+}
+
 // Get implements the Retriever interface.
 func (us *unionStore) Get(k Key) ([]byte, error) {
 	v, err := us.MemBuffer.Get(k)
@@ -180,6 +211,12 @@ func (us *unionStore) Get(k Key) ([]byte, error) {
 // markLazyConditionPair marks a kv pair for later check.
 // If condition not match, should return e as error.
 func (us *unionStore) markLazyConditionPair(k Key, v []byte, e error) {
+	if len(us.checkpoints) > 0 {
+		us.conditionUndoLog = append(us.conditionUndoLog, conditionUndoEntry{
+			key:  string(k),
+			pair: us.lazyConditionPairs[string(k)],
+		})
+	}
 	us.lazyConditionPairs[string(k)] = &conditionPair{
 		key:   k.Clone(),
 		value: v,
@@ -187,6 +224,38 @@ func (us *unionStore) markLazyConditionPair(k Key, v []byte, e error) {
 	}
 }
 
+// Checkpoint implements the MemBuffer Checkpoint interface.
+// The lazy condition pairs marked after the checkpoint are discarded with the writes when it is rolled back to.
+func (us *unionStore) Checkpoint() int {
+	us.checkpoints = append(us.checkpoints, unionCheckpoint{
+		mem:       us.MemBuffer.Checkpoint(),
+		condition: len(us.conditionUndoLog),
+	})
+	return len(us.checkpoints) - 1
+}
+
+// RollbackToCheckpoint implements the MemBuffer RollbackToCheckpoint interface.
+func (us *unionStore) RollbackToCheckpoint(cp int) error {
+	if cp < 0 || cp >= len(us.checkpoints) {
+		return errors.Errorf("invalid checkpoint %d", cp)
+	}
+	checkpoint := us.checkpoints[cp]
+	if err := us.MemBuffer.RollbackToCheckpoint(checkpoint.mem); err != nil {
+		return errors.Trace(err)
+	}
+	for i := len(us.conditionUndoLog) - 1; i >= checkpoint.condition; i-- {
+		e := us.conditionUndoLog[i]
+		if e.pair == nil {
+			delete(us.lazyConditionPairs, e.key)
+		} else {
+			us.lazyConditionPairs[e.key] = e.pair
+		}
+	}
+	us.conditionUndoLog = us.conditionUndoLog[:checkpoint.condition]
+	us.checkpoints = us.checkpoints[:cp+1]
+	return nil
+}
+
 // CheckLazyConditionPairs implements the UnionStore interface.
 func (us *unionStore) CheckLazyConditionPairs() error {
 	if len(us.lazyConditionPairs) == 0 {
@@ -125,6 +125,35 @@ func (s *testUnionStoreSuite) TestLazyConditionCheck(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testUnionStoreSuite) TestCheckpointLazyCondition(c *C) {
+	defer testleak.AfterTest(c)()
+	s.store.Set([]byte("1"), []byte("1"))
+	s.store.Set([]byte("2"), []byte("2"))
+
+	s.us.SetOption(PresumeKeyNotExists, nil)
+	s.us.Get([]byte("3"))
+	cp1 := s.us.Checkpoint()
+	s.us.Get([]byte("1"))
+	s.us.Set([]byte("1"), []byte("10"))
+	cp2 := s.us.Checkpoint()
+	s.us.Get([]byte("2"))
+	c.Assert(s.us.CheckLazyConditionPairs(), NotNil)
+
+	c.Assert(s.us.RollbackToCheckpoint(cp2), IsNil)
+	v, err := s.us.Get([]byte("1"))
+	c.Assert(err, IsNil)
+	c.Assert(v, BytesEquals, []byte("10"))
+	s.us.DelOption(PresumeKeyNotExists)
+	c.Assert(s.us.CheckLazyConditionPairs(), NotNil)
+
+	c.Assert(s.us.RollbackToCheckpoint(cp1), IsNil)
+	c.Assert(s.us.CheckLazyConditionPairs(), IsNil)
+	v, err = s.us.Get([]byte("1"))
+	c.Assert(err, IsNil)
+	c.Assert(v, BytesEquals, []byte("1"))
+	c.Assert(s.us.RollbackToCheckpoint(cp2), NotNil)
+}
+
 func checkIterator(c *C, iter Iterator, keys [][]byte, values [][]byte) {
 	defer iter.Close()
 	c.Assert(len(keys), Equals, len(values))
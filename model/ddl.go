@@ -42,6 +42,10 @@ const (
 	ActionModifyColumn
 	ActionRenameTable
 	ActionSetDefaultValue
+	ActionCreateView
+	ActionAddTablePartition
+	ActionDropTablePartition
+	ActionTruncateTablePartition
 )
 
 func (action ActionType) String() string {
@@ -74,6 +78,14 @@ func (action ActionType) String() string {
 		return "rename table"
 	case ActionSetDefaultValue:
 		return "set default value"
+	case ActionCreateView:
+		return "create view"
+	case ActionAddTablePartition:
+		return "add partition"
+	case ActionDropTablePartition:
+		return "drop partition"
+	case ActionTruncateTablePartition:
+		return "truncate partition"
 	default:
 		return "none"
 	}
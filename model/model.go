@@ -17,6 +17,7 @@ import (
 	"strings"
 
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -70,6 +71,17 @@ type ColumnInfo struct {
 	types.FieldType     `json:"type"`
 	State               SchemaState `json:"state"`
 	Comment             string      `json:"comment"`
+	// ChangeStateInfo is set when the column is the new or the old column of a MODIFY COLUMN
+	// job that converts the data, its value is converted from the column it depends on.
+	ChangeStateInfo *ChangeStateInfo `json:"change_state_info"`
+}
+
+// ChangeStateInfo records the column a non-public column depends on during MODIFY COLUMN.
+type ChangeStateInfo struct {
+	// DependencyColumnOffset is the offset of the column whose value is converted to the column.
+	DependencyColumnOffset int `json:"relative_col_offset"`
+	// Replaced is set on the old column after the column it depends on replaces it.
+	Replaced bool `json:"replaced"`
 }
 
 // Clone clones ColumnInfo.
@@ -87,6 +99,19 @@ func (c *ColumnInfo) IsGenerated() bool {
 // for use of execution phase.
 const ExtraHandleID = -1
 
+// The versions of the table info, they decide how the values of the table are encoded.
+const (
+	// TableInfoVersion0 means the strings are stored in UTF-8 and their index keys are binary.
+	TableInfoVersion0 = uint16(0)
+	// TableInfoVersion1 means the strings of the gbk, gb18030 and latin1 columns are stored in the
+	// column charset, and the index keys of the strings compared under non-binary collations are
+	// their sort keys.
+	TableInfoVersion1 = uint16(1)
+
+	// CurrLatestTableInfoVersion is the version of the table info of the new tables.
+	CurrLatestTableInfoVersion = TableInfoVersion1
+)
+
 // TableInfo provides meta data describing a DB table.
 type TableInfo struct {
 	ID      int64  `json:"id"`
@@ -108,6 +133,96 @@ type TableInfo struct {
 	// We need to save original schemaID to keep autoID unchanged
 	// while renaming a table from one database to another.
 	OldSchemaID int64 `json:"old_schema_id,omitempty"`
+	// View is not nil if the table is a view.
+	View *ViewInfo `json:"view,omitempty"`
+	// Partition is not nil if the table is partitioned.
+	Partition *PartitionInfo `json:"partition,omitempty"`
+	// Version is the version of the table info, the tables created before the version is
+	// introduced are TableInfoVersion0.
+	Version uint16 `json:"version"`
+}
+
+// PartitionType is the type for PartitionInfo.
+type PartitionType int
+
+// Partition types.
+const (
+	PartitionTypeRange PartitionType = iota + 1
+	PartitionTypeHash
+)
+
+func (p PartitionType) String() string {
+	switch p {
+	case PartitionTypeRange:
+		return "RANGE"
+	case PartitionTypeHash:
+		return "HASH"
+	default:
+		return ""
+	}
+}
+
+// PartitionInfo provides table partition info.
+type PartitionInfo struct {
+	Type PartitionType `json:"type"`
+	// Expr is the partitioning expression, its value must be an integer.
+	Expr string `json:"expr"`
+	// Definitions are sorted by the upper bound for range partitioning.
+	Definitions []PartitionDefinition `json:"definitions"`
+}
+
+// PartitionDefinition defines a single partition.
+type PartitionDefinition struct {
+	// ID is the physical table ID the rows of the partition are stored with.
+	ID   int64 `json:"id"`
+	Name CIStr `json:"name"`
+	// LessThan is the upper bound of a range partition, "MAXVALUE" means no upper bound.
+	LessThan string `json:"less_than"`
+}
+
+// PartitionMaxValue is the LessThan value of a range partition without upper bound.
+const PartitionMaxValue = "MAXVALUE"
+
+// Clone clones PartitionInfo.
+func (pi *PartitionInfo) Clone() *PartitionInfo {
+	npi := *pi
+	npi.Definitions = make([]PartitionDefinition, len(pi.Definitions))
+	copy(npi.Definitions, pi.Definitions)
+	return &npi
+}
+
+// FindPartition finds the partition by name, returns -1 if not found.
+func (pi *PartitionInfo) FindPartition(name string) int {
+	for i, def := range pi.Definitions {
+		if def.Name.L == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ViewInfo provides meta data describing a view.
+type ViewInfo struct {
+	Definer    *auth.UserIdentity `json:"view_definer"`
+	Security   string             `json:"view_security"`
+	SelectStmt string             `json:"view_select"`
+	// SelectCols are the names of the columns selected by SelectStmt for the view columns.
+	SelectCols []CIStr `json:"view_select_cols"`
+}
+
+// Clone clones ViewInfo.
+func (v *ViewInfo) Clone() *ViewInfo {
+	nv := *v
+	if v.Definer != nil {
+		definer := *v.Definer
+		nv.Definer = &definer
+	}
+	return &nv
+}
+
+// IsView checks whether the table is a view.
+func (t *TableInfo) IsView() bool {
+	return t.View != nil
 }
 
 // Clone clones TableInfo.
@@ -129,6 +244,14 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	if t.View != nil {
+		nt.View = t.View.Clone()
+	}
+
+	if t.Partition != nil {
+		nt.Partition = t.Partition.Clone()
+	}
+
 	return &nt
 }
 
@@ -155,6 +278,17 @@ func (t *TableInfo) GetPkColInfo() *ColumnInfo {
 	return nil
 }
 
+// StorageType returns the field type the values of the column are encoded as in the rows and the
+// index keys. The strings of the tables before TableInfoVersion1 are marked by the LegacyStorageFlag.
+func (t *TableInfo) StorageType(c *ColumnInfo) *types.FieldType {
+	if t.Version >= TableInfoVersion1 || !types.IsNonBinaryStr(&c.FieldType) {
+		return &c.FieldType
+	}
+	ft := c.FieldType
+	ft.Flag |= mysql.LegacyStorageFlag
+	return &ft
+}
+
 // ColumnIsInIndex checks whether c is included in any indices of t.
 func (t *TableInfo) ColumnIsInIndex(c *ColumnInfo) bool {
 	for _, index := range t.Indices {
@@ -18,6 +18,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -119,6 +120,40 @@ func (*testModelSuite) TestModelBasic(c *C) {
 	}
 	no := anIndex.HasPrefixIndex()
 	c.Assert(no, Equals, false)
+
+	c.Assert(table.IsView(), IsFalse)
+	view := &TableInfo{
+		ID:      2,
+		Name:    NewCIStr("v"),
+		Columns: []*ColumnInfo{column},
+		View:    &ViewInfo{Definer: &auth.UserIdentity{Username: "root", Hostname: "%"}, Security: "DEFINER", SelectStmt: "select c from t"},
+	}
+	c.Assert(view.IsView(), IsTrue)
+	nv := view.Clone()
+	c.Assert(nv.View, DeepEquals, view.View)
+	nv.View.Definer.Username = "u"
+	c.Assert(view.View.Definer.Username, Equals, "root")
+
+	partitioned := &TableInfo{
+		ID:      3,
+		Name:    NewCIStr("p"),
+		Columns: []*ColumnInfo{column},
+		Partition: &PartitionInfo{
+			Type: PartitionTypeRange,
+			Expr: "`c`",
+			Definitions: []PartitionDefinition{
+				{ID: 4, Name: NewCIStr("p0"), LessThan: "10"},
+				{ID: 5, Name: NewCIStr("P1"), LessThan: PartitionMaxValue},
+			},
+		},
+	}
+	c.Assert(partitioned.Partition.Type.String(), Equals, "RANGE")
+	c.Assert(partitioned.Partition.FindPartition("p1"), Equals, 1)
+	c.Assert(partitioned.Partition.FindPartition("p2"), Equals, -1)
+	np := partitioned.Clone()
+	c.Assert(np.Partition, DeepEquals, partitioned.Partition)
+	np.Partition.Definitions[0].ID = 6
+	c.Assert(partitioned.Partition.Definitions[0].ID, Equals, int64(4))
 }
 
 func (*testModelSuite) TestJobCodec(c *C) {
@@ -231,6 +266,9 @@ func (testModelSuite) TestString(c *C) {
 		{ActionDropIndex, "drop index"},
 		{ActionAddColumn, "add column"},
 		{ActionDropColumn, "drop column"},
+		{ActionAddTablePartition, "add partition"},
+		{ActionDropTablePartition, "drop partition"},
+		{ActionTruncateTablePartition, "truncate partition"},
 	}
 
 	for _, v := range acts {
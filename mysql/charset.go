@@ -57,6 +57,7 @@ var CharsetIDs = map[string]uint8{
 	"geostd8":  92,
 	"cp932":    95,
 	"eucjpms":  97,
+	"gb18030":  248,
 }
 
 // Charsets maps charset name to its default collation name.
@@ -101,6 +102,7 @@ var Charsets = map[string]string{
 	"geostd8":  "geostd8_general_ci",
 	"cp932":    "cp932_japanese_ci",
 	"eucjpms":  "eucjpms_japanese_ci",
+	"gb18030":  "gb18030_chinese_ci",
 }
 
 // Collations maps MySQL default collation ID to its name.
@@ -324,6 +326,9 @@ var Collations = map[uint8]string{
 	245: "utf8mb4_croatian_ci",
 	246: "utf8mb4_unicode_520_ci",
 	247: "utf8mb4_vietnamese_ci",
+	248: "gb18030_chinese_ci",
+	249: "gb18030_bin",
+	250: "gb18030_unicode_520_ci",
 }
 
 // CollationNames maps MySQL default collation name to its ID
@@ -547,6 +552,9 @@ var CollationNames = map[string]uint8{
 	"utf8mb4_croatian_ci":      245,
 	"utf8mb4_unicode_520_ci":   246,
 	"utf8mb4_vietnamese_ci":    247,
+	"gb18030_chinese_ci":       248,
+	"gb18030_bin":              249,
+	"gb18030_unicode_520_ci":   250,
 }
 
 // MySQL collation information.
@@ -40,6 +40,10 @@ const (
 	ErrHeader         byte = 0xff
 	EOFHeader         byte = 0xfe
 	LocalInFileHeader byte = 0xfb
+	// AuthSwitchHeader is the header of the AuthSwitchRequest packet, it's the same as EOFHeader.
+	AuthSwitchHeader byte = 0xfe
+	// AuthMoreDataHeader is the header of the packets exchanged by authentication plugins.
+	AuthMoreDataHeader byte = 0x01
 )
 
 // Server information.
@@ -120,6 +124,15 @@ const (
 	ComResetConnection
 )
 
+// Cursor type flags of the COM_STMT_EXECUTE command.
+// See https://dev.mysql.com/doc/internals/en/com-stmt-execute.html
+const (
+	CursorTypeNoCursor   byte = 0x00
+	CursorTypeReadOnly   byte = 0x01
+	CursorTypeForUpdate  byte = 0x02
+	CursorTypeScrollable byte = 0x04
+)
+
 // Client information.
 const (
 	ClientLongPassword uint32 = 1 << iota
@@ -153,7 +166,9 @@ const (
 
 // Auth name information.
 const (
-	AuthName = "mysql_native_password"
+	AuthName                = "mysql_native_password"
+	AuthCachingSha2Password = "caching_sha2_password"
+	AuthSHA256Password      = "sha256_password"
 )
 
 // MySQL database and tables.
@@ -168,6 +183,10 @@ const (
 	TablePrivTable = "Tables_priv"
 	// ColumnPrivTable is the table in system db contains column scope privilege info.
 	ColumnPrivTable = "Columns_priv"
+	// RoleEdgeTable is the table in system db contains the roles granted to users.
+	RoleEdgeTable = "role_edges"
+	// DefaultRoleTable is the table in system db contains the default roles of users.
+	DefaultRoleTable = "default_roles"
 	// GlobalVariablesTable is the table contains global system variables.
 	GlobalVariablesTable = "GLOBAL_VARIABLES"
 	// GlobalStatusTable is the table contains global status variables.
@@ -213,6 +232,12 @@ const (
 	ExecutePriv
 	// IndexPriv is the privilege to create/drop index.
 	IndexPriv
+	// FilePriv is the privilege to read and write files on the server host.
+	FilePriv
+	// CreateViewPriv is the privilege to create or alter views.
+	CreateViewPriv
+	// ShowViewPriv is the privilege to run show create view statement.
+	ShowViewPriv
 	// AllPriv is the privilege for all actions.
 	AllPriv
 )
@@ -275,6 +300,9 @@ var Priv2UserCol = map[PrivilegeType]string{
 	AlterPriv:      "Alter_priv",
 	ExecutePriv:    "Execute_priv",
 	IndexPriv:      "Index_priv",
+	FilePriv:       "File_priv",
+	CreateViewPriv: "Create_view_priv",
+	ShowViewPriv:   "Show_view_priv",
 }
 
 // Col2PrivType is the privilege tables column name to privilege type.
@@ -295,10 +323,13 @@ var Col2PrivType = map[string]PrivilegeType{
 	"Alter_priv":       AlterPriv,
 	"Execute_priv":     ExecutePriv,
 	"Index_priv":       IndexPriv,
+	"File_priv":        FilePriv,
+	"Create_view_priv": CreateViewPriv,
+	"Show_view_priv":   ShowViewPriv,
 }
 
 // AllGlobalPrivs is all the privileges in global scope.
-var AllGlobalPrivs = []PrivilegeType{SelectPriv, InsertPriv, UpdatePriv, DeletePriv, CreatePriv, DropPriv, ProcessPriv, GrantPriv, ReferencesPriv, AlterPriv, ShowDBPriv, SuperPriv, ExecutePriv, IndexPriv, CreateUserPriv, TriggerPriv}
+var AllGlobalPrivs = []PrivilegeType{SelectPriv, InsertPriv, UpdatePriv, DeletePriv, CreatePriv, DropPriv, ProcessPriv, GrantPriv, ReferencesPriv, AlterPriv, ShowDBPriv, SuperPriv, ExecutePriv, IndexPriv, CreateUserPriv, TriggerPriv, FilePriv, CreateViewPriv, ShowViewPriv}
 
 // Priv2Str is the map for privilege to string.
 var Priv2Str = map[PrivilegeType]string{
@@ -318,41 +349,48 @@ var Priv2Str = map[PrivilegeType]string{
 	AlterPriv:      "Alter",
 	ExecutePriv:    "Execute",
 	IndexPriv:      "Index",
+	FilePriv:       "File",
+	CreateViewPriv: "Create View",
+	ShowViewPriv:   "Show View",
 }
 
 // Priv2SetStr is the map for privilege to string.
 var Priv2SetStr = map[PrivilegeType]string{
-	CreatePriv:  "Create",
-	SelectPriv:  "Select",
-	InsertPriv:  "Insert",
-	UpdatePriv:  "Update",
-	DeletePriv:  "Delete",
-	DropPriv:    "Drop",
-	GrantPriv:   "Grant",
-	AlterPriv:   "Alter",
-	ExecutePriv: "Execute",
-	IndexPriv:   "Index",
+	CreatePriv:     "Create",
+	SelectPriv:     "Select",
+	InsertPriv:     "Insert",
+	UpdatePriv:     "Update",
+	DeletePriv:     "Delete",
+	DropPriv:       "Drop",
+	GrantPriv:      "Grant",
+	AlterPriv:      "Alter",
+	ExecutePriv:    "Execute",
+	IndexPriv:      "Index",
+	CreateViewPriv: "Create View",
+	ShowViewPriv:   "Show View",
 }
 
 // SetStr2Priv is the map for privilege set string to privilege type.
 var SetStr2Priv = map[string]PrivilegeType{
-	"Create":  CreatePriv,
-	"Select":  SelectPriv,
-	"Insert":  InsertPriv,
-	"Update":  UpdatePriv,
-	"Delete":  DeletePriv,
-	"Drop":    DropPriv,
-	"Grant":   GrantPriv,
-	"Alter":   AlterPriv,
-	"Execute": ExecutePriv,
-	"Index":   IndexPriv,
+	"Create":      CreatePriv,
+	"Select":      SelectPriv,
+	"Insert":      InsertPriv,
+	"Update":      UpdatePriv,
+	"Delete":      DeletePriv,
+	"Drop":        DropPriv,
+	"Grant":       GrantPriv,
+	"Alter":       AlterPriv,
+	"Execute":     ExecutePriv,
+	"Index":       IndexPriv,
+	"Create View": CreateViewPriv,
+	"Show View":   ShowViewPriv,
 }
 
 // AllDBPrivs is all the privileges in database scope.
-var AllDBPrivs = []PrivilegeType{SelectPriv, InsertPriv, UpdatePriv, DeletePriv, CreatePriv, DropPriv, GrantPriv, AlterPriv, ExecutePriv, IndexPriv}
+var AllDBPrivs = []PrivilegeType{SelectPriv, InsertPriv, UpdatePriv, DeletePriv, CreatePriv, DropPriv, GrantPriv, AlterPriv, ExecutePriv, IndexPriv, CreateViewPriv, ShowViewPriv}
 
 // AllTablePrivs is all the privileges in table scope.
-var AllTablePrivs = []PrivilegeType{SelectPriv, InsertPriv, UpdatePriv, DeletePriv, CreatePriv, DropPriv, GrantPriv, AlterPriv, IndexPriv}
+var AllTablePrivs = []PrivilegeType{SelectPriv, InsertPriv, UpdatePriv, DeletePriv, CreatePriv, DropPriv, GrantPriv, AlterPriv, IndexPriv, CreateViewPriv, ShowViewPriv}
 
 // AllColumnPrivs is all the privileges in column scope.
 var AllColumnPrivs = []PrivilegeType{SelectPriv, InsertPriv, UpdatePriv}
@@ -889,7 +889,24 @@ const (
 	ErrInvalidJSONText                                              = 3140
 	ErrInvalidJSONPath                                              = 3143
 	ErrInvalidJSONData                                              = 3146
+	ErrInvalidJSONPathWildcard                                      = 3149
 	ErrJSONUsedAsKey                                                = 3152
+	ErrJSONVacuousPath                                              = 3153
+	ErrJSONBadOneOrAllArg                                           = 3154
+	ErrInvalidJSONPathArrayCell                                     = 3165
+	ErrUnknownAuthID                                                = 3523
+	ErrRoleNotGranted                                               = 3530
+	ErrCTERecursiveRequiresUnion                                    = 3573
+	ErrCTERecursiveRequiresNonRecursiveFirst                        = 3574
+	ErrCTERecursiveForbidsAggregation                               = 3575
+	ErrCTERecursiveRequiresSingleReference                          = 3577
+	ErrFieldInGroupingNotGroupBy                                    = 3580
+	ErrWindowFrameStartIllegal                                      = 3584
+	ErrWindowFrameEndIllegal                                        = 3585
+	ErrWindowFrameIllegal                                           = 3586
+	ErrWindowRangeFrameOrderType                                    = 3587
+	ErrWindowInvalidWindowFuncUse                                   = 3593
+	ErrCTEMaxRecursionDepth                                         = 3636
 
 	// TiKV/PD errors.
 	ErrPDServerTimeout    = 9001
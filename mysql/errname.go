@@ -886,7 +886,24 @@ var MySQLErrName = map[uint16]string{
 	ErrInvalidJSONText:                                       "Invalid JSON text: %-.192s",
 	ErrInvalidJSONPath:                                       "Invalid JSON path expression %s.",
 	ErrInvalidJSONData:                                       "Invalid data type for JSON data",
+	ErrInvalidJSONPathWildcard:                               "In this situation, path expressions may not contain the * and ** tokens.",
 	ErrJSONUsedAsKey:                                         "JSON column '%-.192s' cannot be used in key specification.",
+	ErrJSONVacuousPath:                                       "The path expression '$' is not allowed in this context.",
+	ErrJSONBadOneOrAllArg:                                    "The oneOrAll argument to %s may take these values: 'one' or 'all'.",
+	ErrInvalidJSONPathArrayCell:                              "A path expression is not a path to a cell in an array.",
+	ErrUnknownAuthID:                                         "Unknown authorization ID `%.64s`@`%.64s`",
+	ErrRoleNotGranted:                                        "`%.64s`@`%.64s` is not granted to %s",
+	ErrCTERecursiveRequiresUnion:                             "Recursive Common Table Expression '%s' should contain a UNION",
+	ErrCTERecursiveRequiresNonRecursiveFirst:                 "Recursive Common Table Expression '%s' should have one or more non-recursive query blocks followed by one or more recursive ones",
+	ErrCTERecursiveForbidsAggregation:                        "Recursive Common Table Expression '%s' can contain neither aggregation nor window functions in recursive query block",
+	ErrCTERecursiveRequiresSingleReference:                   "In recursive query block of Recursive Common Table Expression '%s', the recursive table must be referenced only once, and not in any subquery",
+	ErrFieldInGroupingNotGroupBy:                             "Argument #%d of GROUPING function is not in GROUP BY",
+	ErrWindowFrameStartIllegal:                               "Window '%s': frame start cannot be UNBOUNDED FOLLOWING.",
+	ErrWindowFrameEndIllegal:                                 "Window '%s': frame end cannot be UNBOUNDED PRECEDING.",
+	ErrWindowFrameIllegal:                                    "Window '%s': frame start or end is negative, NULL or of non-integral type",
+	ErrWindowRangeFrameOrderType:                             "Window '%s' with RANGE N PRECEDING/FOLLOWING frame requires exactly one ORDER BY expression, of numeric or temporal type",
+	ErrWindowInvalidWindowFuncUse:                            "You cannot use the window function '%s' in this context.'",
+	ErrCTEMaxRecursionDepth:                                  "Recursive query aborted after %d iterations. Try increasing @@cte_max_recursion_depth to a larger value.",
 
 	// TiKV/PD errors.
 	ErrPDServerTimeout:    "PD server timeout",
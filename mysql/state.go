@@ -253,5 +253,9 @@ var MySQLState = map[uint16]string{
 	ErrInvalidJSONText:                     "22032",
 	ErrInvalidJSONPath:                     "42000",
 	ErrInvalidJSONData:                     "22032",
+	ErrInvalidJSONPathWildcard:             "42000",
 	ErrJSONUsedAsKey:                       "42000",
+	ErrJSONVacuousPath:                     "42000",
+	ErrJSONBadOneOrAllArg:                  "42000",
+	ErrInvalidJSONPathArrayCell:            "42000",
 }
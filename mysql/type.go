@@ -61,19 +61,21 @@ const (
 	ZerofillFlag    uint = 64  /* Field is zerofill */
 	BinaryFlag      uint = 128 /* Field is binary   */
 
-	EnumFlag           uint = 256    /* Field is an enum */
-	AutoIncrementFlag  uint = 512    /* Field is an auto increment field */
-	TimestampFlag      uint = 1024   /* Field is a timestamp */
-	SetFlag            uint = 2048   /* Field is a set */
-	NoDefaultValueFlag uint = 4096   /* Field doesn't have a default value */
-	OnUpdateNowFlag    uint = 8192   /* Field is set to NOW on UPDATE */
-	NumFlag            uint = 32768  /* Field is a num (for clients) */
-	PartKeyFlag        uint = 16384  /* Intern: Part of some keys */
-	GroupFlag          uint = 32768  /* Intern: Group field */
-	UniqueFlag         uint = 65536  /* Intern: Used by sql_yacc */
-	BinCmpFlag         uint = 131072 /* Intern: Used by sql_yacc */
-	ParseToJSONFlag    uint = 262144 /* Intern: Used when we want to parse string to JSON in CAST */
-	IsBooleanFlag      uint = 524288 /* Intern: Used for telling boolean literal from integer */
+	EnumFlag            uint = 256     /* Field is an enum */
+	AutoIncrementFlag   uint = 512     /* Field is an auto increment field */
+	TimestampFlag       uint = 1024    /* Field is a timestamp */
+	SetFlag             uint = 2048    /* Field is a set */
+	NoDefaultValueFlag  uint = 4096    /* Field doesn't have a default value */
+	OnUpdateNowFlag     uint = 8192    /* Field is set to NOW on UPDATE */
+	NumFlag             uint = 32768   /* Field is a num (for clients) */
+	PartKeyFlag         uint = 16384   /* Intern: Part of some keys */
+	GroupFlag           uint = 32768   /* Intern: Group field */
+	UniqueFlag          uint = 65536   /* Intern: Used by sql_yacc */
+	BinCmpFlag          uint = 131072  /* Intern: Used by sql_yacc */
+	ParseToJSONFlag     uint = 262144  /* Intern: Used when we want to parse string to JSON in CAST */
+	IsBooleanFlag       uint = 524288  /* Intern: Used for telling boolean literal from integer */
+	ExplicitCollateFlag uint = 1048576 /* Intern: Used for the collation specified by the COLLATE clause */
+	LegacyStorageFlag   uint = 2097152 /* Intern: Used for the strings stored in UTF-8 with binary index keys */
 )
 
 // TypeInt24 bounds.
@@ -147,3 +149,13 @@ func HasParseToJSONFlag(flag uint) bool {
 func HasIsBooleanFlag(flag uint) bool {
 	return (flag & IsBooleanFlag) > 0
 }
+
+// HasExplicitCollateFlag checks if ExplicitCollateFlag is set.
+func HasExplicitCollateFlag(flag uint) bool {
+	return (flag & ExplicitCollateFlag) > 0
+}
+
+// HasLegacyStorageFlag checks if LegacyStorageFlag is set.
+func HasLegacyStorageFlag(flag uint) bool {
+	return (flag & LegacyStorageFlag) > 0
+}
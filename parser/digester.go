@@ -0,0 +1,153 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// Normalize generates the normalized statement of sql, the literals are replaced with "?",
+// the lists of literals like "in (1, 2, 3)" are replaced with "(...)", the keywords are
+// in lower case and the comments, redundant spaces and trailing semicolons are removed.
+// For example, "SELECT * FROM t WHERE a IN (1, 2) AND b = 'x'" is normalized to
+// "select * from t where a in (...) and b = ?".
+func Normalize(sql string) string {
+	d := &sqlDigester{}
+	return d.normalize(sql)
+}
+
+// NormalizeDigest generates the normalized statement and its digest, the digest is the
+// hex encoded MD5 of the normalized statement, same as MySQL.
+func NormalizeDigest(sql string) (normalized, digest string) {
+	normalized = Normalize(sql)
+	sum := md5.Sum([]byte(normalized))
+	return normalized, hex.EncodeToString(sum[:])
+}
+
+const (
+	literalPlaceholder = "?"
+	listPlaceholder    = "(...)"
+)
+
+type sqlDigester struct {
+	tokens []string
+}
+
+func (d *sqlDigester) normalize(sql string) string {
+	s := NewScanner(sql)
+	for {
+		inSpecialComment := s.specialComment != nil
+		tok, pos, lit := s.scan()
+		if tok == 0 {
+			break
+		}
+		if tok == unicode.ReplacementChar && s.r.eof() {
+			break
+		}
+		var text string
+		switch {
+		case tok == invalid:
+			text = skipIllegalChar(s)
+		case tok == intLit || tok == floatLit || tok == decLit || tok == stringLit || tok == hexLit || tok == bitLit:
+			text = literalPlaceholder
+		case tok == hintBegin:
+			text = "/*+"
+		case tok == hintEnd:
+			text = "*/"
+		case inSpecialComment || s.specialComment != nil:
+			// The tokens in the special comments are scanned from the comment text.
+			text = lit
+			if tok == identifier && tokenMap[strings.ToUpper(lit)] != 0 {
+				text = strings.ToLower(lit)
+			}
+		default:
+			text = s.r.s[pos.Offset:s.r.pos().Offset]
+			if tok == identifier && s.isTokenIdentifier(lit, pos.Offset) != 0 {
+				text = strings.ToLower(text)
+			}
+		}
+		d.appendToken(text)
+	}
+	// The statements are the same with or without the trailing semicolons.
+	for len(d.tokens) > 0 && d.tokens[len(d.tokens)-1] == ";" {
+		d.tokens = d.tokens[:len(d.tokens)-1]
+	}
+	return d.String()
+}
+
+// skipIllegalChar consumes the illegal character that the scanner stops at, and returns it.
+func skipIllegalChar(s *Scanner) string {
+	// The token is scanned by the special comment scanner if it's still in the special comment.
+	switch sc := s.specialComment.(type) {
+	case *mysqlSpecificCodeScanner:
+		s = sc.Scanner
+	case *optimizerHintScanner:
+		s = sc.Scanner
+	}
+	start := s.r.pos()
+	s.r.inc()
+	return s.r.data(&start)
+}
+
+// appendToken appends a token, "(?, ?, ...)" is reduced to "(...)" and the following
+// lists like "(...), (...)" in the values of the insert statement are reduced to one.
+func (d *sqlDigester) appendToken(tok string) {
+	if tok != ")" {
+		d.tokens = append(d.tokens, tok)
+		return
+	}
+	n := len(d.tokens)
+	i := n - 1
+	for i >= 0 && (d.tokens[i] == literalPlaceholder || d.tokens[i] == ",") {
+		i--
+	}
+	if i < 0 || i == n-1 || d.tokens[i] != "(" || d.tokens[n-1] != literalPlaceholder {
+		d.tokens = append(d.tokens, tok)
+		return
+	}
+	d.tokens = d.tokens[:i]
+	if i > 1 && d.tokens[i-1] == "," && d.tokens[i-2] == listPlaceholder {
+		d.tokens = d.tokens[:i-1]
+		return
+	}
+	d.tokens = append(d.tokens, listPlaceholder)
+}
+
+// String joins the tokens with single spaces.
+func (d *sqlDigester) String() string {
+	var buf bytes.Buffer
+	for i, tok := range d.tokens {
+		if i > 0 && needSpace(d.tokens[i-1], tok) {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(tok)
+	}
+	return buf.String()
+}
+
+func needSpace(prev, cur string) bool {
+	switch cur {
+	case ",", ")", ".", ";":
+		return false
+	}
+	switch prev {
+	case "(", ".":
+		return false
+	}
+	return true
+}
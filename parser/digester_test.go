@@ -0,0 +1,62 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testSQLDigestSuite{})
+
+type testSQLDigestSuite struct {
+}
+
+func (s *testSQLDigestSuite) TestNormalize(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"SELECT 1", "select ?"},
+		{"select * from t where a = 'x' and b = 1.5 and c = 0x1f and d = b'01'", "select * from t where a = ? and b = ? and c = ? and d = ?"},
+		{"SELECT  *\n FROM `T` /* comment */ WHERE A = -1 -- comment", "select * from `T` where A = - ?"},
+		{"select * from t where a in (1, 2, 3) and b in (4)", "select * from t where a in (...) and b in (...)"},
+		{"select * from t where (a, b) in ((1, 2), (3, 4))", "select * from t where (a, b) in ((...))"},
+		{"select * from t where a in (1, b)", "select * from t where a in (?, b)"},
+		{"insert into t values (1, 'a'), (2, 'b'), (3, 'c')", "insert into t values (...)"},
+		{"select count(*), sum(a) from db.t group by a limit 10", "select count (*), sum (a) from db.t group by a limit ?"},
+		{"select /*+ TIDB_SMJ(t1, t2) */ * from t1, t2", "select /*+ tidb_smj (t1, t2) */ * from t1, t2"},
+		{"select /*!40101 sql_no_cache */ 1", "select sql_no_cache ?"},
+		{"set @a = 1, @@session.autocommit = 0", "set @a = ?, @@session.autocommit = ?"},
+		{"use test;", "use test"},
+		{"select 1 ; ; -- comment", "select ?"},
+		// The illegal characters are kept as they are.
+		{"create user {u1@localhost password = ***}", "create user { u1 @localhost password = * * * }"},
+		{"select /*!40101 {a} */ 1", "select { a } ?"},
+	}
+	for _, t := range tests {
+		c.Check(Normalize(t.input), Equals, t.expect, Commentf("input: %s", t.input))
+	}
+
+	normalized1, digest1 := NormalizeDigest("select * from t where a = 1")
+	normalized2, digest2 := NormalizeDigest("SELECT * FROM t WHERE a = 2")
+	c.Assert(normalized1, Equals, normalized2)
+	c.Assert(digest1, Equals, digest2)
+	c.Assert(digest1, HasLen, 32)
+	_, digest3 := NormalizeDigest("select * from t where b = 1")
+	c.Assert(digest3, Not(Equals), digest1)
+	_, digest4 := NormalizeDigest("select * from t where a = 3;")
+	c.Assert(digest4, Equals, digest1)
+}
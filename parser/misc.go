@@ -140,6 +140,7 @@ var tokenMap = map[string]int{
 	"ADDDATE":             addDate,
 	"ADMIN":               admin,
 	"AFTER":               after,
+	"ALGORITHM":           algorithm,
 	"ALL":                 all,
 	"ALTER":               alter,
 	"ALWAYS":              always,
@@ -188,6 +189,7 @@ var tokenMap = map[string]int{
 	"COMPRESSION":         compression,
 	"CONNECTION":          connection,
 	"CONSISTENT":          consistent,
+	"CURRENT":             current,
 	"CONSTRAINT":          constraint,
 	"CONVERT":             convert,
 	"COUNT":               count,
@@ -214,6 +216,7 @@ var tokenMap = map[string]int{
 	"DEALLOCATE":          deallocate,
 	"DEC":                 decimalType,
 	"DECIMAL":             decimalType,
+	"DEFINER":             definer,
 	"DEFAULT":             defaultKwd,
 	"DELAY_KEY_WRITE":     delayKeyWrite,
 	"DELAYED":             delayed,
@@ -239,6 +242,7 @@ var tokenMap = map[string]int{
 	"ENUM":                enum,
 	"ESCAPE":              escape,
 	"ESCAPED":             escaped,
+	"EXCEPT":              except,
 	"EVENTS":              events,
 	"EXCLUSIVE":           exclusive,
 	"EXECUTE":             execute,
@@ -247,10 +251,12 @@ var tokenMap = map[string]int{
 	"EXTRACT":             extract,
 	"FALSE":               falseKwd,
 	"FIELDS":              fields,
+	"FILE":                file,
 	"FIRST":               first,
 	"FIXED":               fixed,
 	"FLOAT":               floatType,
 	"FLUSH":               flush,
+	"FOLLOWING":           following,
 	"FOR":                 forKwd,
 	"FORCE":               force,
 	"FOREIGN":             foreign,
@@ -266,6 +272,7 @@ var tokenMap = map[string]int{
 	"GRANTS":              grants,
 	"GROUP":               group,
 	"GROUP_CONCAT":        groupConcat,
+	"GROUPING":            grouping,
 	"HASH":                hash,
 	"HAVING":              having,
 	"HIGH_PRIORITY":       highPriority,
@@ -285,6 +292,7 @@ var tokenMap = map[string]int{
 	"INT":                 intType,
 	"INTEGER":             integerType,
 	"INTERVAL":            interval,
+	"INVOKER":             invoker,
 	"INTO":                into,
 	"IS":                  is,
 	"ISOLATION":           isolation,
@@ -311,6 +319,7 @@ var tokenMap = map[string]int{
 	"LONGTEXT":            longtextType,
 	"LOW_PRIORITY":        lowPriority,
 	"MAX":                 max,
+	"MERGE":               merge,
 	"MAX_ROWS":            maxRows,
 	"MAXVALUE":            maxValue,
 	"MEDIUMBLOB":          mediumblobType,
@@ -343,13 +352,16 @@ var tokenMap = map[string]int{
 	"OPTION":              option,
 	"OR":                  or,
 	"ORDER":               order,
+	"OVER":                over,
 	"OUTER":               outer,
+	"OUTFILE":             outfile,
 	"PARTITION":           partition,
 	"PARTITIONS":          partitions,
 	"PASSWORD":            password,
 	"PLUGINS":             plugins,
 	"POSITION":            position,
 	"PRECISION":           precisionType,
+	"PRECEDING":           preceding,
 	"PREPARE":             prepare,
 	"PRIMARY":             primary,
 	"PRIVILEGES":          privileges,
@@ -363,8 +375,10 @@ var tokenMap = map[string]int{
 	"READ":                read,
 	"REAL":                realType,
 	"REDUNDANT":           redundant,
+	"RECURSIVE":           recursive,
 	"REFERENCES":          references,
 	"REGEXP":              regexpKwd,
+	"RELEASE":             release,
 	"RENAME":              rename,
 	"REPEAT":              repeat,
 	"REPEATABLE":          repeatable,
@@ -374,12 +388,17 @@ var tokenMap = map[string]int{
 	"REVOKE":              revoke,
 	"RIGHT":               right,
 	"RLIKE":               rlike,
+	"ROLE":                role,
 	"ROLLBACK":            rollback,
+	"ROLLUP":              rollup,
 	"ROW":                 row,
+	"ROWS":                rows,
 	"ROW_COUNT":           rowCount,
 	"ROW_FORMAT":          rowFormat,
+	"SAVEPOINT":           savepoint,
 	"SCHEMA":              database,
 	"SCHEMAS":             databases,
+	"SECURITY":            security,
 	"SECOND":              second,
 	"SECOND_MICROSECOND":  secondMicrosecond,
 	"SELECT":              selectKwd,
@@ -393,6 +412,7 @@ var tokenMap = map[string]int{
 	"SMALLINT":            smallIntType,
 	"SNAPSHOT":            snapshot,
 	"SOME":                some,
+	"SQL":                 sql,
 	"SQL_CACHE":           sqlCache,
 	"SQL_CALC_FOUND_ROWS": sqlCalcFoundRows,
 	"SQL_NO_CACHE":        sqlNoCache,
@@ -413,6 +433,7 @@ var tokenMap = map[string]int{
 	"TABLE":               tableKwd,
 	"TABLES":              tables,
 	"TERMINATED":          terminated,
+	"TEMPTABLE":           temptable,
 	"TEXT":                textType,
 	"THAN":                than,
 	"THEN":                then,
@@ -434,6 +455,8 @@ var tokenMap = map[string]int{
 	"TRIM":                trim,
 	"TRUE":                trueKwd,
 	"TRUNCATE":            truncate,
+	"UNBOUNDED":           unbounded,
+	"UNDEFINED":           undefined,
 	"UNCOMMITTED":         uncommitted,
 	"UNION":               union,
 	"UNIQUE":              unique,
@@ -456,6 +479,7 @@ var tokenMap = map[string]int{
 	"VIRTUAL":             virtual,
 	"WARNINGS":            warnings,
 	"WEEK":                week,
+	"WORK":                work,
 	"WHEN":                when,
 	"WHERE":               where,
 	"WITH":                with,
@@ -110,6 +110,7 @@ import (
 	elseKwd			"ELSE"
 	enclosed		"ENCLOSED"
 	escaped 		"ESCAPED"
+	except			"EXCEPT"
 	exists			"EXISTS"
 	explain			"EXPLAIN"
 	falseKwd		"FALSE"
@@ -172,6 +173,8 @@ import (
 	or			"OR"
 	order			"ORDER"
 	outer			"OUTER"
+	outfile			"OUTFILE"
+	over			"OVER"
 	partition		"PARTITION"
 	precisionType		"PRECISION"
 	primary			"PRIMARY"
@@ -179,8 +182,10 @@ import (
 	rangeKwd		"RANGE"
 	read			"READ"
 	realType		"REAL"
+	recursive		"RECURSIVE"
 	references		"REFERENCES"
 	regexpKwd		"REGEXP"
+	release			"RELEASE"
 	rename         		"RENAME"
 	repeat			"REPEAT"
 	replace			"REPLACE"
@@ -232,6 +237,7 @@ import (
 	/* The following tokens belong to UnReservedKeyword. */
 	action		"ACTION"
 	after		"AFTER"
+	algorithm	"ALGORITHM"
 	always		"ALWAYS"
 	any 		"ANY"
 	ascii		"ASCII"
@@ -258,11 +264,13 @@ import (
 	compression	"COMPRESSION"
 	connection 	"CONNECTION"
 	consistent	"CONSISTENT"
+	current		"CURRENT"
 	day		"DAY"
 	data 		"DATA"
 	dateType	"DATE"
 	datetimeType	"DATETIME"
 	deallocate	"DEALLOCATE"
+	definer		"DEFINER"
 	delayKeyWrite	"DELAY_KEY_WRITE"
 	disable		"DISABLE"
 	do		"DO"
@@ -278,9 +286,11 @@ import (
 	exclusive       "EXCLUSIVE"
 	execute		"EXECUTE"
 	fields		"FIELDS"
+	file		"FILE"
 	first		"FIRST"
 	fixed		"FIXED"
 	flush		"FLUSH"
+	following	"FOLLOWING"
 	format		"FORMAT"
 	full		"FULL"
 	function	"FUNCTION"
@@ -288,6 +298,7 @@ import (
 	hash		"HASH"
 	hour		"HOUR"
 	identified	"IDENTIFIED"
+	invoker		"INVOKER"
 	isolation	"ISOLATION"
 	indexes		"INDEXES"
 	jsonType	"JSON"
@@ -295,6 +306,7 @@ import (
 	local		"LOCAL"
 	less		"LESS"
 	level		"LEVEL"
+	merge		"MERGE"
 	microsecond	"MICROSECOND"
 	minute		"MINUTE"
 	mode		"MODE"
@@ -311,6 +323,7 @@ import (
 	password	"PASSWORD"
 	partitions	"PARTITIONS"
 	plugins		"PLUGINS"
+	preceding	"PRECEDING"
 	prepare		"PREPARE"
 	privileges	"PRIVILEGES"
 	process		"PROCESS"
@@ -321,17 +334,23 @@ import (
 	redundant	"REDUNDANT"
 	repeatable	"REPEATABLE"
 	reverse		"REVERSE"
+	role		"ROLE"
 	rollback	"ROLLBACK"
+	rollup		"ROLLUP"
 	row 		"ROW"
+	rows		"ROWS"
 	rowCount	"ROW_COUNT"
 	rowFormat	"ROW_FORMAT"
+	savepoint	"SAVEPOINT"
 	second		"SECOND"
+	security	"SECURITY"
 	serializable	"SERIALIZABLE"
 	session		"SESSION"
 	share		"SHARE"
 	shared		"SHARED"
 	signed		"SIGNED"
 	snapshot	"SNAPSHOT"
+	sql		"SQL"
 	sqlCache	"SQL_CACHE"
 	sqlNoCache	"SQL_NO_CACHE"
 	start		"START"
@@ -341,6 +360,7 @@ import (
 	some 		"SOME"
 	global		"GLOBAL"
 	tables		"TABLES"
+	temptable	"TEMPTABLE"
 	textType	"TEXT"
 	than		"THAN"
 	timeType	"TIME"
@@ -348,7 +368,9 @@ import (
 	transaction	"TRANSACTION"
 	triggers	"TRIGGERS"
 	truncate	"TRUNCATE"
+	unbounded	"UNBOUNDED"
 	uncommitted	"UNCOMMITTED"
+	undefined	"UNDEFINED"
 	unknown 	"UNKNOWN"
 	user		"USER"
 	value		"VALUE"
@@ -356,6 +378,7 @@ import (
 	view		"VIEW"
 	warnings	"WARNINGS"
 	week		"WEEK"
+	work		"WORK"
 	yearType	"YEAR"
 
 	/* The following tokens belong to NotKeywordToken. */
@@ -369,6 +392,7 @@ import (
 	extract		"EXTRACT"
 	getFormat	"GET_FORMAT"
 	groupConcat	"GROUP_CONCAT"
+	grouping	"GROUPING"
 	min		"MIN"
 	max		"MAX"
 	now		"NOW"
@@ -448,6 +472,9 @@ import (
 	CommitStmt			"COMMIT statement"
 	CreateTableStmt			"CREATE TABLE statement"
 	CreateUserStmt			"CREATE User statement"
+	CreateRoleStmt			"CREATE ROLE statement"
+	CreateViewStmt			"CREATE VIEW statement"
+	AlterViewStmt			"ALTER VIEW statement"
 	CreateDatabaseStmt		"Create Database Statement"
 	CreateIndexStmt			"CREATE INDEX statement"
 	DoStmt				"Do statement"
@@ -456,6 +483,7 @@ import (
 	DropStatsStmt			"DROP STATS statement"
 	DropTableStmt			"DROP TABLE statement"
 	DropUserStmt			"DROP USER"
+	DropRoleStmt			"DROP ROLE statement"
 	DropViewStmt			"DROP VIEW statement"
 	DeallocateStmt			"Deallocate prepared statement"
 	DeleteFromStmt			"DELETE FROM statement"
@@ -464,17 +492,25 @@ import (
 	ExplainStmt			"EXPLAIN statement"
 	FlushStmt			"Flush statement"
 	GrantStmt			"Grant statement"
+	GrantRoleStmt			"Grant role statement"
 	InsertIntoStmt			"INSERT INTO statement"
 	KillStmt			"Kill statement"
 	LoadDataStmt			"Load data statement"
 	LockTablesStmt			"Lock tables statement"
 	PreparedStmt			"PreparedStmt"
 	SelectStmt			"SELECT statement"
+	SelectStmtWithClause		"SELECT or UNION statement with a WITH clause"
+	ViewSelectStmt			"SELECT statement of a view"
 	RenameTableStmt         	"rename table statement"
 	ReplaceIntoStmt			"REPLACE INTO statement"
 	RevokeStmt			"Revoke statement"
+	RevokeRoleStmt			"Revoke role statement"
+	ReleaseSavepointStmt		"RELEASE SAVEPOINT statement"
 	RollbackStmt			"ROLLBACK statement"
+	SavepointStmt			"SAVEPOINT statement"
 	SetStmt				"Set variable statement"
+	SetRoleStmt			"Set role statement"
+	SetDefaultRoleStmt		"Set default role statement"
 	ShowStmt			"Show engines/databases/tables/columns/warnings/status statement"
 	Statement			"statement"
 	ExplainableStmt			"explainable statement"
@@ -494,6 +530,10 @@ import (
 	AuthOption			"User auth option"
 	AuthString			"Password string value"
 	OptionalBraces			"optional braces"
+	OrReplace			"OR REPLACE or empty"
+	ViewAlgorithm			"view algorithm or empty"
+	ViewDefiner			"view definer or empty"
+	ViewSQLSecurity			"view sql security or empty"
 	CastType			"Cast function target type"
 	CharsetName			"Character set name"
 	ColumnDef			"table column definition"
@@ -534,6 +574,10 @@ import (
 	FieldAsName			"Field alias name"
 	FieldAsNameOpt			"Field alias name opt"
 	FieldList			"field expression list"
+	FrameBound			"window frame bound"
+	FrameClauseOpt			"window frame clause or empty"
+	FrameExtent			"window frame extent"
+	FrameUnits			"window frame units"
 	FlushOption			"Flush option"
 	TableRefsClause			"Table references clause"
 	FuncDatetimePrec		"Function datetime precision"
@@ -553,6 +597,11 @@ import (
 	IndexHintType			"index hint type"
 	IndexName			"index name"
 	IndexNameList			"index name list"
+	IdentList			"identifier list"
+	IdentListWithParenOpt		"optional identifier list in parentheses"
+	CommonTableExpr			"common table expression"
+	WithClause			"WITH clause"
+	WithList			"common table expression list in WITH clause"
 	IndexOption			"Index Option"
 	IndexOptionList			"Index Option List or empty"
 	IndexType			"index type"
@@ -579,6 +628,9 @@ import (
 	ByItem				"BY item"
 	OrderByOptional			"Optional ORDER BY clause optional"
 	ByList				"BY list"
+	OverClause			"OVER clause of window function"
+	PartitionByOpt			"PARTITION BY clause of window or empty"
+	WindowSpecDetails		"window specification"
 	QuickOptional			"QUICK or empty"
 	PartitionDefinition		"Partition definition"
 	PartitionDefinitionList 	"Partition definition list"
@@ -599,13 +651,18 @@ import (
 	OnDeleteOpt			"optional ON DELETE clause"
 	OnUpdateOpt			"optional ON UPDATE clause"
 	ReferOpt			"reference option"
+	Rolename			"Role name"
+	RolenameList			"Role name list"
 	ReplacePriority			"replace statement priority"
 	RowFormat			"Row format option"
 	RowValue			"Row value"
 	SelectLockOpt			"FOR UPDATE or LOCK IN SHARE MODE,"
+	SetRoleOpt			"Set role option"
+	SetDefaultRoleOpt		"Set default role option"
 	SelectStmtCalcFoundRows		"SELECT statement optional SQL_CALC_FOUND_ROWS"
 	SelectStmtSQLCache		"SELECT statement optional SQL_CAHCE/SQL_NO_CACHE"
 	SelectStmtFieldList		"SELECT statement field list"
+	SelectIntoOption		"SELECT statement INTO clause"
 	SelectStmtLimit			"SELECT statement optional LIMIT clause"
 	SelectStmtOpts			"Select statement options"
 	SelectStmtGroup			"SELECT statement optional GROUP BY clause"
@@ -644,6 +701,7 @@ import (
 	UnionClauseList		"Union select clause list"
 	UnionSelect		"Union (select) item"
 	Username		"Username"
+	UsingRoles		"Show grants using roles"
 	UsernameList		"UsernameList"
 	UserSpec		"Username and auth option"
 	UserSpecList		"Username and auth option list"
@@ -698,6 +756,8 @@ import (
 	KeyOrIndex		"{KEY|INDEX}"
 	ColumnKeywordOpt	"Column keyword or empty"
 	PrimaryOpt		"Optional primary keyword"
+	WorkOpt			"Optional WORK keyword"
+	RoleNameString		"Role name string without the host"
 	NowSym			"CURRENT_TIMESTAMP/LOCALTIME/LOCALTIMESTAMP"
 	NowSymFunc		"CURRENT_TIMESTAMP/LOCALTIME/LOCALTIMESTAMP/NOW"
 	DefaultKwdOpt		"optional DEFAULT keyword"
@@ -831,6 +891,27 @@ AlterTableSpec:
 			OldColumnName: $3.(*ast.ColumnName),
 		}
 	}
+|	"ADD" "PARTITION" '(' PartitionDefinitionList ')'
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableAddPartitions,
+			PartDefinitions:	$4.([]*ast.PartitionDefinition),
+		}
+	}
+|	"DROP" "PARTITION" Identifier
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:	ast.AlterTableDropPartition,
+			Name:	$3,
+		}
+	}
+|	"TRUNCATE" "PARTITION" Identifier
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:	ast.AlterTableTruncatePartition,
+			Name:	$3,
+		}
+	}
 |	"DROP" "PRIMARY" "KEY"
 	{
 		$$ = &ast.AlterTableSpec{Tp: ast.AlterTableDropPrimaryKey}
@@ -1579,6 +1660,9 @@ CreateTableStmt:
 			Constraints:    constraints,
 			Options:        $8.([]*ast.TableOption),
 		}
+		if $9 != nil {
+			$$.(*ast.CreateTableStmt).Partition = $9.(*ast.PartitionOptions)
+		}
 	}
 |	"CREATE" "TABLE" IfNotExists TableName "LIKE" TableName
 	{
@@ -1594,40 +1678,101 @@ DefaultKwdOpt:
 |	"DEFAULT"
 
 PartitionOpt:
-	{}
+	{
+		$$ = nil
+	}
 |	"PARTITION" "BY" "KEY" '(' ColumnNameList ')' PartitionNumOpt PartitionDefinitionListOpt
-	{}
+	{
+		$$ = &ast.PartitionOptions{
+			Tp:		model.PartitionTypeHash,
+			ColumnNames:	$5.([]*ast.ColumnName),
+			Num:		$7.(uint64),
+			Definitions:	$8.([]*ast.PartitionDefinition),
+		}
+	}
 |	"PARTITION" "BY" "HASH" '(' Expression ')' PartitionNumOpt PartitionDefinitionListOpt
-	{}
+	{
+		startOffset := parser.startOffset(&yyS[yypt-3])
+		endOffset := parser.endOffset(&yyS[yypt-2])
+		expr := $5
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.PartitionOptions{
+			Tp:		model.PartitionTypeHash,
+			Expr:		expr,
+			Num:		$7.(uint64),
+			Definitions:	$8.([]*ast.PartitionDefinition),
+		}
+	}
 |	"PARTITION" "BY" "RANGE" '(' Expression ')' PartitionNumOpt  PartitionDefinitionListOpt
-	{}
+	{
+		startOffset := parser.startOffset(&yyS[yypt-3])
+		endOffset := parser.endOffset(&yyS[yypt-2])
+		expr := $5
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.PartitionOptions{
+			Tp:		model.PartitionTypeRange,
+			Expr:		expr,
+			Num:		$7.(uint64),
+			Definitions:	$8.([]*ast.PartitionDefinition),
+		}
+	}
 
 PartitionNumOpt:
-	{}
+	{
+		$$ = uint64(0)
+	}
 |	"PARTITIONS" NUM
-	{}
+	{
+		$$ = getUint64FromNUM($2)
+	}
 
 PartitionDefinitionListOpt:
-	{}
+	{
+		$$ = []*ast.PartitionDefinition(nil)
+	}
 |	'(' PartitionDefinitionList ')'
-	{}
+	{
+		$$ = $2.([]*ast.PartitionDefinition)
+	}
 
 PartitionDefinitionList:
 	PartitionDefinition
-	{}
+	{
+		$$ = []*ast.PartitionDefinition{$1.(*ast.PartitionDefinition)}
+	}
 |	PartitionDefinitionList ',' PartitionDefinition
-	{}
+	{
+		$$ = append($1.([]*ast.PartitionDefinition), $3.(*ast.PartitionDefinition))
+	}
 
 PartitionDefinition:
 	"PARTITION" Identifier PartDefValuesOpt PartDefStorageOpt
-	{}
+	{
+		partDef := &ast.PartitionDefinition{
+			Name: model.NewCIStr($2),
+		}
+		if $3 != nil {
+			if values, ok := $3.([]ast.ExprNode); ok {
+				partDef.LessThan = values
+			} else {
+				partDef.MaxValue = true
+			}
+		}
+		$$ = partDef
+	}
 
 PartDefValuesOpt:
-	{}
+	{
+		$$ = nil
+	}
 |	"VALUES" "LESS" "THAN" "MAXVALUE"
-	{}
+	{
+		$$ = true
+	}
 |	"VALUES" "LESS" "THAN" '(' ExpressionList ')'
-	{}
+	{
+		$$ = $5.([]ast.ExprNode)
+	}
 
 PartDefStorageOpt:
 	{}
@@ -1734,11 +1879,120 @@ DropTableStmt:
 	}
 
 DropViewStmt:
-	"DROP" "VIEW" "IF" "EXISTS" TableNameList
+	"DROP" "VIEW" TableNameList
+	{
+		$$ = &ast.DropTableStmt{Tables: $3.([]*ast.TableName), IsView: true}
+	}
+|	"DROP" "VIEW" "IF" "EXISTS" TableNameList
+	{
+		$$ = &ast.DropTableStmt{IfExists: true, Tables: $5.([]*ast.TableName), IsView: true}
+	}
+
+/*******************************************************************
+ *
+ *  Create View Statement
+ *
+ *  Example:
+ *      CREATE OR REPLACE VIEW v (a, b) AS SELECT c, d FROM t
+ *******************************************************************/
+CreateViewStmt:
+	"CREATE" OrReplace ViewAlgorithm ViewDefiner ViewSQLSecurity "VIEW" TableName IdentListWithParenOpt "AS" ViewSelectStmt
+	{
+		startOffset := parser.startOffset(&yyS[yypt])
+		endOffset := parser.endOffset(&parser.yylval)
+		selStmt := $10.(ast.ResultSetNode)
+		selStmt.SetText(parser.src[startOffset:endOffset])
+		x := &ast.CreateViewStmt{
+			OrReplace:	$2.(bool),
+			ViewName:	$7.(*ast.TableName),
+			Cols:		$8.([]model.CIStr),
+			Select:		selStmt,
+			Security:	$5.(string),
+		}
+		if $4 != nil {
+			x.Definer = $4.(*auth.UserIdentity)
+		}
+		$$ = x
+	}
+
+AlterViewStmt:
+	"ALTER" ViewAlgorithm ViewDefiner ViewSQLSecurity "VIEW" TableName IdentListWithParenOpt "AS" ViewSelectStmt
 	{
-		$$ = &ast.DoStmt{}
+		startOffset := parser.startOffset(&yyS[yypt])
+		endOffset := parser.endOffset(&parser.yylval)
+		selStmt := $9.(ast.ResultSetNode)
+		selStmt.SetText(parser.src[startOffset:endOffset])
+		x := &ast.CreateViewStmt{
+			OrReplace:	true,
+			IsAlter:	true,
+			ViewName:	$6.(*ast.TableName),
+			Cols:		$7.([]model.CIStr),
+			Select:		selStmt,
+			Security:	$4.(string),
+		}
+		if $3 != nil {
+			x.Definer = $3.(*auth.UserIdentity)
+		}
+		$$ = x
+	}
+
+OrReplace:
+	{
+		$$ = false
+	}
+|	"OR" "REPLACE"
+	{
+		$$ = true
+	}
+
+ViewAlgorithm:
+	{
+		$$ = ""
+	}
+|	"ALGORITHM" eq "UNDEFINED"
+	{
+		$$ = "UNDEFINED"
+	}
+|	"ALGORITHM" eq "MERGE"
+	{
+		$$ = "MERGE"
+	}
+|	"ALGORITHM" eq "TEMPTABLE"
+	{
+		$$ = "TEMPTABLE"
+	}
+
+ViewDefiner:
+	{
+		$$ = nil
+	}
+|	"DEFINER" eq "CURRENT_USER" OptionalBraces
+	{
+		$$ = nil
+	}
+|	"DEFINER" eq Username
+	{
+		$$ = $3
+	}
+
+ViewSQLSecurity:
+	{
+		$$ = ""
+	}
+|	"SQL" "SECURITY" "DEFINER"
+	{
+		$$ = "DEFINER"
+	}
+|	"SQL" "SECURITY" "INVOKER"
+	{
+		$$ = "INVOKER"
 	}
 
+ViewSelectStmt:
+	SelectStmt
+|	SelectStmtWithClause
+|	UnionStmt
+
 DropUserStmt:
 	"DROP" "USER" UsernameList
 	{
@@ -1749,6 +2003,18 @@ DropUserStmt:
 		$$ = &ast.DropUserStmt{IfExists: true, UserList: $5.([]*auth.UserIdentity)}
 	}
 
+/* See https://dev.mysql.com/doc/refman/8.0/en/drop-role.html */
+DropRoleStmt:
+	"DROP" "ROLE" IfExists RolenameList
+	{
+		roles := $4.([]*auth.RoleIdentity)
+		users := make([]*auth.UserIdentity, 0, len(roles))
+		for _, role := range roles {
+			users = append(users, &auth.UserIdentity{Username: role.Username, Hostname: role.Hostname})
+		}
+		$$ = &ast.DropUserStmt{IsDropRole: true, IfExists: $3.(bool), UserList: users}
+	}
+
 DropStatsStmt:
 	"DROP" "STATS" TableName
 	{
@@ -2156,6 +2422,10 @@ GroupByClause:
 	{
 		$$ = &ast.GroupByClause{Items: $3.([]*ast.ByItem)}
 	}
+|	"GROUP" "BY" ByList "WITH" "ROLLUP"
+	{
+		$$ = &ast.GroupByClause{Items: $3.([]*ast.ByItem), Rollup: true}
+	}
 
 HavingClause:
 	{
@@ -2273,7 +2543,7 @@ identifier | UnReservedKeyword | NotKeywordToken | TiDBKeyword
 UnReservedKeyword:
  "ACTION" | "ASCII" | "AUTO_INCREMENT" | "AFTER" | "ALWAYS" | "AVG" | "BEGIN" | "BIT" | "BOOL" | "BOOLEAN" | "BTREE" | "BYTE" | "CHARSET"
 | "COLUMNS" | "COMMIT" | "COMPACT" | "COMPRESSED" | "CONSISTENT" | "DATA" | "DATE" %prec lowerThanStringLitToken| "DATETIME" | "DAY" | "DEALLOCATE" | "DO" | "DUPLICATE"
-| "DYNAMIC"| "END" | "ENGINE" | "ENGINES" | "ENUM" | "ESCAPE" | "EXECUTE" | "FIELDS" | "FIRST" | "FIXED" | "FLUSH" | "FORMAT" | "FULL" |"GLOBAL"
+| "DYNAMIC"| "END" | "ENGINE" | "ENGINES" | "ENUM" | "ESCAPE" | "EXECUTE" | "FIELDS" | "FILE" | "FIRST" | "FIXED" | "FLUSH" | "FORMAT" | "FULL" |"GLOBAL"
 | "HASH" | "HOUR" | "LESS" | "LOCAL" | "NAMES" | "OFFSET" | "PASSWORD" %prec lowerThanEq | "PREPARE" | "QUICK" | "REDUNDANT"
 | "ROLLBACK" | "SESSION" | "SIGNED" | "SNAPSHOT" | "START" | "STATUS" | "TABLES" | "TEXT" | "THAN" | "TIME" %prec lowerThanStringLitToken | "TIMESTAMP" %prec lowerThanStringLitToken
 | "TRANSACTION" | "TRUNCATE" | "UNKNOWN" | "VALUE" | "WARNINGS" | "YEAR" | "MODE"  | "WEEK"  | "ANY" | "SOME" | "USER" | "IDENTIFIED"
@@ -2282,13 +2552,15 @@ UnReservedKeyword:
 | "REPEATABLE" | "COMMITTED" | "UNCOMMITTED" | "ONLY" | "SERIALIZABLE" | "LEVEL" | "VARIABLES" | "SQL_CACHE" | "INDEXES" | "PROCESSLIST"
 | "SQL_NO_CACHE" | "DISABLE"  | "ENABLE" | "REVERSE" | "PRIVILEGES" | "NO" | "BINLOG" | "FUNCTION" | "VIEW" | "MODIFY" | "EVENTS" | "PARTITIONS"
 | "NONE" | "SUPER" | "EXCLUSIVE" | "STATS_PERSISTENT" | "ROW_COUNT" | "COALESCE" | "MONTH" | "PROCESS"
-| "MICROSECOND" | "MINUTE" | "PLUGINS" | "QUERY" | "SECOND" | "SHARE" | "SHARED"
+| "MICROSECOND" | "MINUTE" | "PLUGINS" | "QUERY" | "SECOND" | "SHARE" | "SHARED" | "CURRENT" | "FOLLOWING" | "PRECEDING"
+| "ROWS" | "UNBOUNDED" | "ALGORITHM" | "DEFINER" | "INVOKER" | "MERGE" | "SECURITY" | "SQL" | "TEMPTABLE" | "UNDEFINED"
+| "ROLLUP" | "SAVEPOINT" | "WORK" | "ROLE"
 
 TiDBKeyword:
 "ADMIN" | "DDL" | "JOBS" | "STATS" | "STATS_META" | "STATS_HISTOGRAMS" | "STATS_BUCKETS" | "TIDB" | "TIDB_SMJ" | "TIDB_INLJ"
 
 NotKeywordToken:
- "ADDDATE" | "BIT_XOR" | "CAST" | "COUNT" | "CURTIME" | "DATE_ADD" | "DATE_SUB" | "EXTRACT" | "GET_FORMAT" | "GROUP_CONCAT" | "MIN" | "MAX" | "NOW" | "POSITION"
+ "ADDDATE" | "BIT_XOR" | "CAST" | "COUNT" | "CURTIME" | "DATE_ADD" | "DATE_SUB" | "EXTRACT" | "GET_FORMAT" | "GROUP_CONCAT" | "GROUPING" | "MIN" | "MAX" | "NOW" | "POSITION"
 | "SUBDATE" | "SUBSTRING" | "SUM" | "TIMESTAMPADD" | "TIMESTAMPDIFF" | "TRIM"
 
 /************************************************************************************
@@ -2344,6 +2616,10 @@ InsertValues:
 	{
 		$$ = &ast.InsertStmt{Select: $1.(*ast.UnionStmt)}
 	}
+|	SelectStmtWithClause
+	{
+		$$ = &ast.InsertStmt{Select: $1.(ast.ResultSetNode)}
+	}
 |	"SET" ColumnSetValueList
 	{
 		$$ = &ast.InsertStmt{Setlist: $2.([]*ast.Assignment)}
@@ -2699,8 +2975,7 @@ SimpleExpr:
 |	FunctionCallGeneric
 |	SimpleExpr "COLLATE" StringName %prec neg
 	{
-		// TODO: Create a builtin function hold expr and collation. When do evaluation, convert expr result using the collation.
-		$$ = $1
+		$$ = &ast.SetCollationExpr{Expr: $1.(ast.ExprNode), Collate: $3.(string)}
 	}
 |	Literal
 |	paramMarker
@@ -2711,6 +2986,20 @@ SimpleExpr:
 	}
 |	Variable
 |	SumExpr
+|	SumExpr OverClause
+	{
+		agg := $1.(*ast.AggregateFuncExpr)
+		$$ = &ast.WindowFuncExpr{F: agg.F, Args: agg.Args, Distinct: agg.Distinct, Spec: *$2.(*ast.WindowSpec)}
+	}
+|	FunctionCallGeneric OverClause
+	{
+		f := $1.(*ast.FuncCallExpr)
+		if _, ok := ast.WindowFuncs[f.FnName.L]; !ok {
+			yylex.Errorf("%s is not a window function", f.FnName.O)
+			return 1
+		}
+		$$ = &ast.WindowFuncExpr{F: f.FnName.L, Args: f.Args, Spec: *$2.(*ast.WindowSpec)}
+	}
 |	'!' SimpleExpr %prec neg
 	{
 		$$ = &ast.UnaryOperationExpr{Op: opcode.Not, V: $2}
@@ -3185,6 +3474,10 @@ SumExpr:
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: $4.([]ast.ExprNode), Distinct: $3.(bool)}
 	}
+|	"GROUPING" '(' ExpressionList ')'
+	{
+		$$ = &ast.AggregateFuncExpr{F: $1, Args: $3.([]ast.ExprNode)}
+	}
 |	"MAX" '(' BuggyDefaultFalseDistinctOpt Expression ')'
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4}, Distinct: $3.(bool)}
@@ -3204,6 +3497,90 @@ FunctionCallGeneric:
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
 
+OverClause:
+	"OVER" '(' WindowSpecDetails ')'
+	{
+		$$ = $3
+	}
+
+WindowSpecDetails:
+	PartitionByOpt OrderByOptional FrameClauseOpt
+	{
+		spec := &ast.WindowSpec{}
+		if $1 != nil {
+			spec.PartitionBy = $1.(*ast.PartitionByClause)
+		}
+		if $2 != nil {
+			spec.OrderBy = $2.(*ast.OrderByClause)
+		}
+		if $3 != nil {
+			spec.Frame = $3.(*ast.FrameClause)
+		}
+		$$ = spec
+	}
+
+PartitionByOpt:
+	{
+		$$ = nil
+	}
+|	"PARTITION" "BY" ByList
+	{
+		$$ = &ast.PartitionByClause{Items: $3.([]*ast.ByItem)}
+	}
+
+FrameClauseOpt:
+	{
+		$$ = nil
+	}
+|	FrameUnits FrameExtent
+	{
+		frame := $2.(*ast.FrameClause)
+		frame.Type = $1.(ast.FrameType)
+		$$ = frame
+	}
+
+FrameUnits:
+	"ROWS"
+	{
+		$$ = ast.Rows
+	}
+|	"RANGE"
+	{
+		$$ = ast.Ranges
+	}
+
+FrameExtent:
+	FrameBound
+	{
+		$$ = &ast.FrameClause{Start: *$1.(*ast.FrameBound), End: ast.FrameBound{Type: ast.CurrentRow}}
+	}
+|	"BETWEEN" FrameBound "AND" FrameBound
+	{
+		$$ = &ast.FrameClause{Start: *$2.(*ast.FrameBound), End: *$4.(*ast.FrameBound)}
+	}
+
+FrameBound:
+	"UNBOUNDED" "PRECEDING"
+	{
+		$$ = &ast.FrameBound{Type: ast.Preceding, UnBounded: true}
+	}
+|	"UNBOUNDED" "FOLLOWING"
+	{
+		$$ = &ast.FrameBound{Type: ast.Following, UnBounded: true}
+	}
+|	"CURRENT" "ROW"
+	{
+		$$ = &ast.FrameBound{Type: ast.CurrentRow}
+	}
+|	NumLiteral "PRECEDING"
+	{
+		$$ = &ast.FrameBound{Type: ast.Preceding, Expr: ast.NewValueExpr($1)}
+	}
+|	NumLiteral "FOLLOWING"
+	{
+		$$ = &ast.FrameBound{Type: ast.Following, Expr: ast.NewValueExpr($1)}
+	}
+
 FuncDatetimePrec:
 	{
 		$$ = nil
@@ -3617,6 +3994,30 @@ RollbackStmt:
 	{
 		$$ = &ast.RollbackStmt{}
 	}
+|	"ROLLBACK" WorkOpt "TO" Identifier
+	{
+		$$ = &ast.RollbackStmt{SavepointName: $4}
+	}
+|	"ROLLBACK" WorkOpt "TO" "SAVEPOINT" Identifier
+	{
+		$$ = &ast.RollbackStmt{SavepointName: $5}
+	}
+
+WorkOpt:
+	{}
+|	"WORK"
+
+SavepointStmt:
+	"SAVEPOINT" Identifier
+	{
+		$$ = &ast.SavepointStmt{Name: $2}
+	}
+
+ReleaseSavepointStmt:
+	"RELEASE" "SAVEPOINT" Identifier
+	{
+		$$ = &ast.ReleaseSavepointStmt{Name: $3}
+	}
 
 SelectStmt:
 	"SELECT" SelectStmtOpts SelectStmtFieldList SelectStmtLimit SelectLockOpt
@@ -3717,6 +4118,20 @@ SelectStmt:
 FromDual:
 	"FROM" "DUAL"
 
+/*
+ * See https://dev.mysql.com/doc/refman/5.7/en/select-into.html
+ */
+SelectIntoOption:
+	"INTO" "OUTFILE" stringLit Fields Lines
+	{
+		$$ = &ast.SelectIntoOption{
+			Tp:         ast.SelectIntoOutfile,
+			FileName:   $3,
+			FieldsInfo: $4.(*ast.FieldsClause),
+			LinesInfo:  $5.(*ast.LinesClause),
+		}
+	}
+
 
 TableRefsClause:
 	TableRefs
@@ -3782,6 +4197,14 @@ TableFactor:
 	{
 		$$ = &ast.TableSource{Source: $2.(*ast.UnionStmt), AsName: $4.(model.CIStr)}
 	}
+|	'(' SelectStmtWithClause ')' TableAsName
+	{
+		if st, ok := $2.(*ast.SelectStmt); ok {
+			endOffset := parser.endOffset(&yyS[yypt-1])
+			parser.setLastSelectFieldText(st, endOffset)
+		}
+		$$ = &ast.TableSource{Source: $2.(ast.ResultSetNode), AsName: $4.(model.CIStr)}
+	}
 |	'(' TableRefs ')'
 	{
 		$$ = $2
@@ -4092,6 +4515,18 @@ SubSelect:
 		s.SetText(src[yyS[yypt-1].offset-1:yyS[yypt].offset-1])
 		$$ = &ast.SubqueryExpr{Query: s}
 	}
+|	'(' SelectStmtWithClause ')'
+	{
+		if st, ok := $2.(*ast.SelectStmt); ok {
+			endOffset := parser.endOffset(&yyS[yypt])
+			parser.setLastSelectFieldText(st, endOffset)
+		}
+		s := $2.(ast.ResultSetNode)
+		src := parser.src
+		// See the implementation of yyParse function
+		s.SetText(src[yyS[yypt-1].offset-1:yyS[yypt].offset-1])
+		$$ = &ast.SubqueryExpr{Query: s}
+	}
 
 // See https://dev.mysql.com/doc/refman/5.7/en/innodb-locking-reads.html
 SelectLockOpt:
@@ -4175,6 +4610,70 @@ UnionSelect:
 UnionOpt:
 DefaultTrueDistinctOpt
 
+// See https://dev.mysql.com/doc/refman/8.0/en/with.html
+SelectStmtWithClause:
+	WithClause SelectStmt
+	{
+		st := $2.(*ast.SelectStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+|	WithClause UnionStmt
+	{
+		st := $2.(*ast.UnionStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+
+WithClause:
+	"WITH" WithList
+	{
+		$$ = &ast.WithClause{CTEs: $2.([]*ast.CommonTableExpression)}
+	}
+|	"WITH" "RECURSIVE" WithList
+	{
+		$$ = &ast.WithClause{IsRecursive: true, CTEs: $3.([]*ast.CommonTableExpression)}
+	}
+
+WithList:
+	CommonTableExpr
+	{
+		$$ = []*ast.CommonTableExpression{$1.(*ast.CommonTableExpression)}
+	}
+|	WithList ',' CommonTableExpr
+	{
+		$$ = append($1.([]*ast.CommonTableExpression), $3.(*ast.CommonTableExpression))
+	}
+
+CommonTableExpr:
+	Identifier IdentListWithParenOpt "AS" SubSelect
+	{
+		$$ = &ast.CommonTableExpression{
+			Name:        model.NewCIStr($1),
+			ColNameList: $2.([]model.CIStr),
+			Query:       $4.(*ast.SubqueryExpr).Query,
+		}
+	}
+
+IdentListWithParenOpt:
+	{
+		$$ = []model.CIStr{}
+	}
+|	'(' IdentList ')'
+	{
+		$$ = $2
+	}
+
+IdentList:
+	Identifier
+	{
+		$$ = []model.CIStr{model.NewCIStr($1)}
+	}
+|	IdentList ',' Identifier
+	{
+		$$ = append($1.([]model.CIStr), model.NewCIStr($3))
+	}
+
 
 /********************Set Statement*******************************/
 SetStmt:
@@ -4203,6 +4702,53 @@ SetStmt:
 		$$ = &ast.SetStmt{Variables: $4.([]*ast.VariableAssignment)}
 	}
 
+/* See https://dev.mysql.com/doc/refman/8.0/en/set-role.html */
+SetRoleStmt:
+	"SET" "ROLE" SetRoleOpt
+	{
+		$$ = $3.(*ast.SetRoleStmt)
+	}
+
+SetRoleOpt:
+	"DEFAULT"
+	{
+		$$ = &ast.SetRoleStmt{SetRoleOpt: ast.SetRoleDefault}
+	}
+|	"ALL" "EXCEPT" RolenameList
+	{
+		$$ = &ast.SetRoleStmt{SetRoleOpt: ast.SetRoleAllExcept, RoleList: $3.([]*auth.RoleIdentity)}
+	}
+|	SetDefaultRoleOpt
+	{
+		$$ = $1.(*ast.SetRoleStmt)
+	}
+
+SetDefaultRoleOpt:
+	"NONE"
+	{
+		$$ = &ast.SetRoleStmt{SetRoleOpt: ast.SetRoleNone}
+	}
+|	"ALL"
+	{
+		$$ = &ast.SetRoleStmt{SetRoleOpt: ast.SetRoleAll}
+	}
+|	RolenameList
+	{
+		$$ = &ast.SetRoleStmt{SetRoleOpt: ast.SetRoleRegular, RoleList: $1.([]*auth.RoleIdentity)}
+	}
+
+/* See https://dev.mysql.com/doc/refman/8.0/en/set-default-role.html */
+SetDefaultRoleStmt:
+	"SET" "DEFAULT" "ROLE" SetDefaultRoleOpt "TO" UsernameList
+	{
+		opt := $4.(*ast.SetRoleStmt)
+		$$ = &ast.SetDefaultRoleStmt{
+			SetRoleOpt:	opt.SetRoleOpt,
+			RoleList:	opt.RoleList,
+			UserList:	$6.([]*auth.UserIdentity),
+		}
+	}
+
 TransactionChars:
 	TransactionChar
 	{
@@ -4407,6 +4953,45 @@ UsernameList:
 		$$ = append($1.([]*auth.UserIdentity), $3.(*auth.UserIdentity))
 	}
 
+UsingRoles:
+	{
+		$$ = nil
+	}
+|	"USING" RolenameList
+	{
+		$$ = $2.([]*auth.RoleIdentity)
+	}
+
+/* The host part of a role name is optional and defaults to '%'. Unreserved keywords are not
+ * allowed as unquoted role names since they can't be told from the privileges in GRANT. */
+RoleNameString:
+	stringLit
+|	identifier
+
+Rolename:
+	RoleNameString
+	{
+		$$ = &auth.RoleIdentity{Username: $1, Hostname: "%"}
+	}
+|	RoleNameString '@' StringName
+	{
+		$$ = &auth.RoleIdentity{Username: $1, Hostname: $3.(string)}
+	}
+|	RoleNameString singleAtIdentifier
+	{
+		$$ = &auth.RoleIdentity{Username: $1, Hostname: strings.TrimPrefix($2, "@")}
+	}
+
+RolenameList:
+	Rolename
+	{
+		$$ = []*auth.RoleIdentity{$1.(*auth.RoleIdentity)}
+	}
+|	RolenameList ',' Rolename
+	{
+		$$ = append($1.([]*auth.RoleIdentity), $3.(*auth.RoleIdentity))
+	}
+
 PasswordOpt:
 	stringLit
 	{
@@ -4462,6 +5047,13 @@ ShowStmt:
 			Table:	$4.(*ast.TableName),
 		}
 	}
+|	"SHOW" "CREATE" "VIEW" TableName
+	{
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowCreateView,
+			Table:	$4.(*ast.TableName),
+		}
+	}
 |	"SHOW" "CREATE" "DATABASE" DBName
 	{
 		$$ = &ast.ShowStmt{
@@ -4474,13 +5066,17 @@ ShowStmt:
 		// See https://dev.mysql.com/doc/refman/5.7/en/show-grants.html
 		$$ = &ast.ShowStmt{Tp: ast.ShowGrants}
 	}
-|	"SHOW" "GRANTS" "FOR" Username
+|	"SHOW" "GRANTS" "FOR" Username UsingRoles
 	{
 		// See https://dev.mysql.com/doc/refman/5.7/en/show-grants.html
-		$$ = &ast.ShowStmt{
+		stmt := &ast.ShowStmt{
 			Tp:	ast.ShowGrants,
 			User:	$4.(*auth.UserIdentity),
 		}
+		if $5 != nil {
+			stmt.Roles = $5.([]*auth.RoleIdentity)
+		}
+		$$ = stmt
 	}
 |	"SHOW" "PROCESSLIST"
 	{
@@ -4782,6 +5378,7 @@ Statement:
 	EmptyStmt
 |	AdminStmt
 |	AlterTableStmt
+|	AlterViewStmt
 |	AlterUserStmt
 |	AnalyzeTableStmt
 |	BeginTransactionStmt
@@ -4795,26 +5392,42 @@ Statement:
 |	CreateIndexStmt
 |	CreateTableStmt
 |	CreateUserStmt
+|	CreateRoleStmt
+|	CreateViewStmt
 |	DoStmt
 |	DropDatabaseStmt
 |	DropIndexStmt
 |	DropTableStmt
 |	DropViewStmt
 |	DropUserStmt
+|	DropRoleStmt
 |	DropStatsStmt
 |	FlushStmt
 |	GrantStmt
+|	GrantRoleStmt
 |	InsertIntoStmt
 |	KillStmt
 |	LoadDataStmt
 |	PreparedStmt
+|	ReleaseSavepointStmt
 |	RollbackStmt
 |	RenameTableStmt
 |	ReplaceIntoStmt
 |	RevokeStmt
+|	RevokeRoleStmt
+|	SavepointStmt
 |	SelectStmt
+|	SelectStmt SelectIntoOption
+	{
+		st := $1.(*ast.SelectStmt)
+		st.SelectIntoOpt = $2.(*ast.SelectIntoOption)
+		$$ = st
+	}
+|	SelectStmtWithClause
 |	UnionStmt
 |	SetStmt
+|	SetRoleStmt
+|	SetDefaultRoleStmt
 |	ShowStmt
 |	SubSelect
 	{
@@ -4830,6 +5443,7 @@ Statement:
 
 ExplainableStmt:
 	SelectStmt
+|	SelectStmtWithClause
 |	DeleteFromStmt
 |	UpdateStmt
 |	InsertIntoStmt
@@ -5573,6 +6187,22 @@ CreateUserStmt:
 		}
 	}
 
+/* See https://dev.mysql.com/doc/refman/8.0/en/create-role.html */
+CreateRoleStmt:
+	"CREATE" "ROLE" IfNotExists RolenameList
+	{
+		roles := $4.([]*auth.RoleIdentity)
+		specs := make([]*ast.UserSpec, 0, len(roles))
+		for _, role := range roles {
+			specs = append(specs, &ast.UserSpec{User: &auth.UserIdentity{Username: role.Username, Hostname: role.Hostname}})
+		}
+		$$ = &ast.CreateUserStmt{
+			IsCreateRole: true,
+			IfNotExists: $3.(bool),
+			Specs: specs,
+		}
+	}
+
 /* See http://dev.mysql.com/doc/refman/5.7/en/alter-user.html */
 AlterUserStmt:
 	"ALTER" "USER" IfExists UserSpecList
@@ -5633,6 +6263,27 @@ AuthOption:
 			HashString: $4.(string),
 		}
 	}
+|	"IDENTIFIED" "WITH" StringName
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin: $3.(string),
+		}
+	}
+|	"IDENTIFIED" "WITH" StringName "BY" AuthString
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin: $3.(string),
+			AuthString: $5.(string),
+			ByAuthString: true,
+		}
+	}
+|	"IDENTIFIED" "WITH" StringName "AS" HashString
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin: $3.(string),
+			HashString: $5.(string),
+		}
+	}
 
 HashString:
 	stringLit
@@ -5656,6 +6307,16 @@ GrantStmt:
 		}
 	 }
 
+/* See https://dev.mysql.com/doc/refman/8.0/en/grant.html#grant-roles */
+GrantRoleStmt:
+	"GRANT" RolenameList "TO" UsernameList
+	{
+		$$ = &ast.GrantRoleStmt{
+			Roles: $2.([]*auth.RoleIdentity),
+			Users: $4.([]*auth.UserIdentity),
+		}
+	}
+
 WithGrantOptionOpt:
 	{
 		$$ = false
@@ -5711,6 +6372,10 @@ PrivType:
 	{
 		$$ = mysql.CreateUserPriv
 	}
+|	"CREATE" "VIEW"
+	{
+		$$ = mysql.CreateViewPriv
+	}
 |	"TRIGGER"
 	{
 		$$ = mysql.TriggerPriv
@@ -5751,6 +6416,10 @@ PrivType:
 	{
 		$$ = mysql.ShowDBPriv
 	}
+|	"SHOW" "VIEW"
+	{
+		$$ = mysql.ShowViewPriv
+	}
 |	"UPDATE"
 	{
 		$$ = mysql.UpdatePriv
@@ -5763,6 +6432,10 @@ PrivType:
 	{
 		$$ = mysql.ReferencesPriv
 	}
+|	"FILE"
+	{
+		$$ = mysql.FilePriv
+	}
 
 ObjectType:
 	{
@@ -5823,6 +6496,16 @@ RevokeStmt:
 		}
 	 }
 
+/* See https://dev.mysql.com/doc/refman/8.0/en/revoke.html */
+RevokeRoleStmt:
+	"REVOKE" RolenameList "FROM" UsernameList
+	{
+		$$ = &ast.RevokeRoleStmt{
+			Roles: $2.([]*auth.RoleIdentity),
+			Users: $4.([]*auth.UserIdentity),
+		}
+	}
+
 /**************************************LoadDataStmt*****************************************
  * See https://dev.mysql.com/doc/refman/5.7/en/load-data.html
  *******************************************************************************************/
@@ -5870,6 +6553,10 @@ Fields:
 			yylex.Errorf("Incorrect arguments %s to ESCAPE", escape)
 			return 1
 		}
+		var escaped byte
+		if len(escape) != 0 {
+			escaped = escape[0]
+		}
 		var enclosed byte
 		str := $3.(string)
 		if len(str) > 1 {
@@ -5881,7 +6568,7 @@ Fields:
 		$$ = &ast.FieldsClause{
 			Terminated: $2.(string),
 			Enclosed:   enclosed,
-			Escaped:    escape[0],
+			Escaped:    escaped,
 		}
 	}
 
@@ -22,6 +22,7 @@ import (
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/charset"
@@ -55,8 +56,8 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"interval", "is", "join", "key", "keys", "kill", "leading", "left", "like", "limit", "lines", "load",
 		"localtime", "localtimestamp", "lock", "longblob", "longtext", "mediumblob", "maxvalue", "mediumint", "mediumtext",
 		"minute_microsecond", "minute_second", "mod", "not", "no_write_to_binlog", "null", "numeric",
-		"on", "option", "or", "order", "outer", "partition", "precision", "primary", "procedure", "range", "read", "real",
-		"references", "regexp", "rename", "repeat", "replace", "revoke", "restrict", "right", "rlike",
+		"on", "option", "or", "order", "outer", "over", "partition", "precision", "primary", "procedure", "range", "read", "real",
+		"recursive", "references", "regexp", "rename", "repeat", "replace", "revoke", "restrict", "right", "rlike",
 		"schema", "schemas", "second_microsecond", "select", "set", "show", "smallint",
 		"starting", "table", "terminated", "then", "tinyblob", "tinyint", "tinytext", "to",
 		"trailing", "true", "union", "unique", "unlock", "unsigned",
@@ -95,7 +96,9 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"enable", "disable", "reverse", "space", "privileges", "get_lock", "release_lock", "sleep", "no", "greatest", "least",
 		"binlog", "hex", "unhex", "function", "indexes", "from_unixtime", "processlist", "events", "less", "than", "timediff",
 		"ln", "log", "log2", "log10", "timestampdiff", "pi", "quote", "none", "super", "shared", "exclusive",
-		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "tidb_version",
+		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "tidb_version", "current", "following",
+		"preceding", "rows", "unbounded", "algorithm", "definer", "invoker", "merge", "security", "sql",
+		"temptable", "undefined", "savepoint", "work", "role",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -302,6 +305,16 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 			INSERT INTO tmp SELECT * from bar;
 			SELECT * from tmp;
 		ROLLBACK;`, true},
+		{"SAVEPOINT sp1", true},
+		{"SAVEPOINT `sp 1`", true},
+		{"SAVEPOINT", false},
+		{"ROLLBACK TO sp1", true},
+		{"ROLLBACK TO SAVEPOINT sp1", true},
+		{"ROLLBACK WORK TO SAVEPOINT sp1", true},
+		{"ROLLBACK TO savepoint", true},
+		{"ROLLBACK TO", false},
+		{"RELEASE SAVEPOINT sp1", true},
+		{"RELEASE sp1", false},
 
 		// qualified select
 		{"SELECT a.b.c FROM t", true},
@@ -344,6 +357,16 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"load data local infile '/tmp/t.csv' into table t fields terminated by 'ab' lines terminated by 'xy' (a,b)", true},
 		{"load data local infile '/tmp/t.csv' into table t (a,b) fields terminated by 'ab'", false},
 
+		// select into outfile
+		{"select * from t into outfile '/tmp/t.csv'", true},
+		{"select a, b from t where a > 1 order by a limit 10 into outfile '/tmp/t.csv'", true},
+		{"select 1 into outfile '/tmp/t.csv'", true},
+		{"select * from t into outfile '/tmp/t.csv' fields terminated by ',' enclosed by '\"' escaped by ''", true},
+		{"select * from t into outfile '/tmp/t.csv' fields terminated by ',' lines starting by 'ab' terminated by '\\r\\n'", true},
+		{"select * from t into outfile '/tmp/t.csv' lines terminated by 'xy' fields terminated by 'ab'", false},
+		{"select * from t into outfile", false},
+		{"select * from t union select * from t into outfile '/tmp/t.csv'", false},
+
 		// select for update
 		{"SELECT * from t for update", true},
 		{"SELECT * from t lock in share mode", true},
@@ -1138,6 +1161,11 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`select group_concat(c2,c1) from t group by c1;`, true},
 		{`select group_concat(distinct c2,c1) from t group by c1;`, true},
 		{`select group_concat(distinctrow c2,c1) from t group by c1;`, true},
+		{`select a, b, sum(c) from t group by a, b with rollup;`, true},
+		{`select a, grouping(a), grouping(a, b) from t group by a, b with rollup;`, true},
+		{`select grouping() from t group by a with rollup;`, false},
+		{`select * from t group by a with rollup order by a;`, true},
+		{`select rollup, grouping from t;`, true},
 
 		// for encryption and compression functions
 		{`select AES_ENCRYPT('text',UNHEX('F3229A0B371ED2D9441B830D21A390C3'))`, true},
@@ -1165,8 +1193,12 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT JSON_UNQUOTE();`, true},
 		{`SELECT JSON_TYPE('[123]');`, true},
 		{`SELECT JSON_TYPE();`, true},
+		{`SELECT JSON_CONTAINS_PATH('{"a": 1}', 'one', '$.a');`, true},
+		{`SELECT JSON_SEARCH('["abc"]', 'all', 'a%', NULL, '$[*]');`, true},
+		{`SELECT JSON_ARRAY_APPEND('[1]', '$', 2), JSON_MERGE_PATCH('{}', '{}'), JSON_PRETTY('[]');`, true},
 
 		// For two json grammar sugar.
+		{`SELECT t.a->'$.a', test.t.a->>'$.a' FROM t`, true},
 		{`SELECT a->'$.a' FROM t`, true},
 		{`SELECT a->>'$.a' FROM t`, true},
 		{`SELECT '{}'->'$.a' FROM t`, false},
@@ -1317,6 +1349,7 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"drop table if exists xxx", true},
 		{"drop table if not exists xxx", false},
 		{"drop view if exists xxx", true},
+		{"drop view xxx, yyy", true},
 		{"drop stats t", true},
 		// for issue 974
 		{`CREATE TABLE address (
@@ -1593,12 +1626,36 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{`ALTER USER IF EXISTS 'root'@'localhost' IDENTIFIED BY 'new-password'`, true},
 		{`ALTER USER 'root'@'localhost' IDENTIFIED BY 'new-password'`, true},
 		{`ALTER USER 'root'@'localhost' IDENTIFIED BY PASSWORD 'hashstring'`, true},
+		{`CREATE USER 'u1'@'%' IDENTIFIED WITH caching_sha2_password BY 'new-password'`, true},
+		{`CREATE USER 'u1'@'%' IDENTIFIED WITH 'sha256_password' AS '$A$005$hashstring', 'u2' IDENTIFIED WITH mysql_native_password`, true},
+		{`ALTER USER 'u1'@'%' IDENTIFIED WITH caching_sha2_password BY 'new-password'`, true},
+		{`CREATE USER 'u1'@'%' IDENTIFIED WITH caching_sha2_password BY PASSWORD 'new-password'`, false},
 		{`ALTER USER 'root'@'localhost' IDENTIFIED BY 'new-password', 'root'@'127.0.0.1' IDENTIFIED BY PASSWORD 'hashstring'`, true},
 		{`ALTER USER USER() IDENTIFIED BY 'new-password'`, true},
 		{`ALTER USER IF EXISTS USER() IDENTIFIED BY 'new-password'`, true},
 		{`DROP USER 'root'@'localhost', 'root1'@'localhost'`, true},
 		{`DROP USER IF EXISTS 'root'@'localhost'`, true},
 
+		// for role statements
+		{"CREATE ROLE r1", true},
+		{"CREATE ROLE IF NOT EXISTS 'r1'@'localhost', `r2`@`%`, r3@localhost", true},
+		{"CREATE ROLE r1 IDENTIFIED BY 'pwd'", false},
+		{"DROP ROLE r1, 'r2'@'%'", true},
+		{"DROP ROLE IF EXISTS r1", true},
+		{"GRANT r1, 'r2'@'localhost' TO 'u1'@'%', u2", true},
+		{"GRANT r1 ON *.* TO u1", false},
+		{"REVOKE r1, r2 FROM u1", true},
+		{"SET ROLE DEFAULT", true},
+		{"SET ROLE NONE", true},
+		{"SET ROLE ALL", true},
+		{"SET ROLE ALL EXCEPT r1, 'r2'@'%'", true},
+		{"SET ROLE r1, r2@localhost", true},
+		{"SET DEFAULT ROLE NONE TO u1", true},
+		{"SET DEFAULT ROLE ALL TO u1, 'u2'@'localhost'", true},
+		{"SET DEFAULT ROLE r1, r2 TO u1", true},
+		{"SET DEFAULT ROLE ALL EXCEPT r1 TO u1", false},
+		{"SHOW GRANTS FOR u1 USING r1, 'r2'@'%'", true},
+
 		// for grant statement
 		{"GRANT ALL ON db1.* TO 'jeffrey'@'localhost';", true},
 		{"GRANT ALL ON db1.* TO 'jeffrey'@'localhost' WITH GRANT OPTION;", true},
@@ -1607,11 +1664,13 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{"GRANT SELECT, INSERT ON *.* TO 'someuser'@'somehost';", true},
 		{"GRANT ALL ON mydb.* TO 'someuser'@'somehost';", true},
 		{"GRANT SELECT, INSERT ON mydb.* TO 'someuser'@'somehost';", true},
+		{"GRANT CREATE VIEW, SHOW VIEW ON mydb.* TO 'someuser'@'somehost';", true},
 		{"GRANT ALL ON mydb.mytbl TO 'someuser'@'somehost';", true},
 		{"GRANT SELECT, INSERT ON mydb.mytbl TO 'someuser'@'somehost';", true},
 		{"GRANT SELECT (col1), INSERT (col1,col2) ON mydb.mytbl TO 'someuser'@'somehost';", true},
 		{"grant all privileges on zabbix.* to 'zabbix'@'localhost' identified by 'password';", true},
 		{"GRANT SELECT ON test.* to 'test'", true}, // For issue 2654.
+		{"GRANT FILE ON *.* TO 'someuser'@'somehost';", true},
 
 		// for revoke statement
 		{"REVOKE ALL ON db1.* FROM 'jeffrey'@'localhost';", true},
@@ -1702,6 +1761,151 @@ func (s *testParserSuite) TestUnion(c *C) {
 	s.RunTest(c, table)
 }
 
+func (s *testParserSuite) TestCommonTableExpression(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"with cte as (select 1) select * from cte", true},
+		{"with cte(a, b) as (select 1, 2) select a, b from cte", true},
+		{"with cte1 as (select 1), cte2 as (select 2) select * from cte1 join cte2", true},
+		{"with cte as (select 1 union select 2) select * from cte", true},
+		{"with cte as (select 1) select * from cte union select * from cte", true},
+		{"with recursive cte(n) as (select 1 union all select n + 1 from cte where n < 10) select * from cte", true},
+		{"select * from (with cte as (select 1) select * from cte) as t", true},
+		{"select (with cte as (select 1) select * from cte)", true},
+		{"insert into t with cte as (select 1) select * from cte", true},
+		{"explain with cte as (select 1) select * from cte", true},
+		{"with cte as select 1 select * from cte", false},
+		{"with cte() as (select 1) select * from cte", false},
+		{"with recursive select 1", false},
+	}
+	s.RunTest(c, table)
+
+	stmt, err := New().ParseOneStmt("with recursive cte(a, b) as (select 1, 2) select * from cte", "", "")
+	c.Assert(err, IsNil)
+	with := stmt.(*ast.SelectStmt).With
+	c.Assert(with.IsRecursive, IsTrue)
+	c.Assert(with.CTEs, HasLen, 1)
+	c.Assert(with.CTEs[0].Name.O, Equals, "cte")
+	c.Assert(with.CTEs[0].ColNameList, DeepEquals, []model.CIStr{model.NewCIStr("a"), model.NewCIStr("b")})
+}
+
+func (s *testParserSuite) TestWindowFunction(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"select row_number() over () from t", true},
+		{"select rank() over (partition by a order by b desc) from t", true},
+		{"select dense_rank() over (partition by a, b order by c) from t", true},
+		{"select lag(a) over (order by b), lead(a, 2, 0) over (order by b) from t", true},
+		{"select first_value(a) over (order by b rows between 1 preceding and 1 following) from t", true},
+		{"select last_value(a) over (order by b rows between unbounded preceding and unbounded following) from t", true},
+		{"select sum(a) over (partition by b order by c range between current row and 2.5 following) from t", true},
+		{"select count(distinct a) over (partition by b) from t", true},
+		{"select avg(a) over (order by b rows 2 preceding) from t", true},
+		{"select a from t order by row_number() over (order by b)", true},
+		{"select abs(a) over () from t", false},
+		{"select row_number() over from t", false},
+		{"select sum(a) over (rows between a preceding and current row) from t", false},
+		{"select sum(a) over (groups 1 preceding) from t", false},
+	}
+	s.RunTest(c, table)
+
+	stmt, err := New().ParseOneStmt("select sum(a) over (partition by b order by c rows 3 preceding) from t", "", "")
+	c.Assert(err, IsNil)
+	win := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.WindowFuncExpr)
+	c.Assert(win.F, Equals, ast.AggFuncSum)
+	c.Assert(win.Spec.PartitionBy.Items, HasLen, 1)
+	c.Assert(win.Spec.OrderBy.Items, HasLen, 1)
+	c.Assert(win.Spec.Frame.Type, Equals, ast.Rows)
+	c.Assert(win.Spec.Frame.Start.Type, Equals, ast.Preceding)
+	c.Assert(win.Spec.Frame.Start.Expr.GetValue(), Equals, int64(3))
+	c.Assert(win.Spec.Frame.End.Type, Equals, ast.CurrentRow)
+}
+
+func (s *testParserSuite) TestView(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"create view v as select * from t", true},
+		{"create or replace view v as select a, b from t where a > 1", true},
+		{"create view v (x, y) as select a, b from t union select c, d from t1", true},
+		{"create algorithm = merge definer = 'root'@'%' sql security invoker view v as select 1", true},
+		{"create definer = current_user() view v as with c as (select 1) select * from c", true},
+		{"alter view v as select a from t", true},
+		{"alter sql security definer view v (x) as select a from t", true},
+		{"create view v", false},
+		{"create view v as insert into t values (1)", false},
+		{"create or replace table t as select 1", false},
+		{"show create view v", true},
+		{"show create view test.v", true},
+	}
+	s.RunTest(c, table)
+
+	src := "create or replace view test.v (x, y) as select a, b from t where a > 1 ; "
+	stmt, err := New().ParseOneStmt(src, "", "")
+	c.Assert(err, IsNil)
+	v := stmt.(*ast.CreateViewStmt)
+	c.Assert(v.OrReplace, IsTrue)
+	c.Assert(v.IsAlter, IsFalse)
+	c.Assert(v.ViewName.Schema.L, Equals, "test")
+	c.Assert(v.ViewName.Name.L, Equals, "v")
+	c.Assert(v.Cols, DeepEquals, []model.CIStr{model.NewCIStr("x"), model.NewCIStr("y")})
+	c.Assert(v.Select.Text(), Equals, "select a, b from t where a > 1")
+
+	stmt, err = New().ParseOneStmt("alter definer = 'u'@'localhost' view v as select 1 union select 2", "", "")
+	c.Assert(err, IsNil)
+	v = stmt.(*ast.CreateViewStmt)
+	c.Assert(v.IsAlter, IsTrue)
+	c.Assert(v.Definer.String(), Equals, "u@localhost")
+	c.Assert(v.Select.Text(), Equals, "select 1 union select 2")
+
+	stmt, err = New().ParseOneStmt("drop view if exists v1, v2", "", "")
+	c.Assert(err, IsNil)
+	drop := stmt.(*ast.DropTableStmt)
+	c.Assert(drop.IsView, IsTrue)
+	c.Assert(drop.IfExists, IsTrue)
+	c.Assert(drop.Tables, HasLen, 2)
+}
+
+func (s *testParserSuite) TestPartition(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"alter table t add partition (partition p2 values less than (2010), partition p3 values less than maxvalue)", true},
+		{"alter table t drop partition p0", true},
+		{"alter table t truncate partition p0", true},
+		{"alter table t add partition p2", false},
+		{"alter table t drop partition", false},
+	}
+	s.RunTest(c, table)
+
+	src := "create table t (a int, d datetime) partition by range ( year(d) ) (partition p0 values less than (2000), partition p1 values less than maxvalue)"
+	stmt, err := New().ParseOneStmt(src, "", "")
+	c.Assert(err, IsNil)
+	part := stmt.(*ast.CreateTableStmt).Partition
+	c.Assert(part.Tp, Equals, model.PartitionTypeRange)
+	c.Assert(part.Expr.Text(), Equals, "year(d)")
+	c.Assert(part.Definitions, HasLen, 2)
+	c.Assert(part.Definitions[0].Name.O, Equals, "p0")
+	c.Assert(part.Definitions[0].LessThan, HasLen, 1)
+	c.Assert(part.Definitions[1].MaxValue, IsTrue)
+
+	stmt, err = New().ParseOneStmt("create table t (a int) partition by key (a) partitions 4", "", "")
+	c.Assert(err, IsNil)
+	part = stmt.(*ast.CreateTableStmt).Partition
+	c.Assert(part.Tp, Equals, model.PartitionTypeHash)
+	c.Assert(part.Expr, IsNil)
+	c.Assert(part.ColumnNames, HasLen, 1)
+	c.Assert(part.Num, Equals, uint64(4))
+
+	stmt, err = New().ParseOneStmt("create table t (a int)", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.CreateTableStmt).Partition, IsNil)
+
+	stmt, err = New().ParseOneStmt("alter table t drop partition p0", "", "")
+	c.Assert(err, IsNil)
+	spec := stmt.(*ast.AlterTableStmt).Specs[0]
+	c.Assert(spec.Tp, Equals, ast.AlterTableDropPartition)
+	c.Assert(spec.Name, Equals, "p0")
+}
+
 func (s *testParserSuite) TestLikeEscape(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCase{
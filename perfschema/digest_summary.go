@@ -0,0 +1,183 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// stmtsDigestSize is the max number of rows in events_statements_summary_by_digest, the statements
+// which can't be summarized in a new row are summarized in the row with NULL schema and digest, same as MySQL.
+const stmtsDigestSize = 10000
+
+type digestKey struct {
+	schema string
+	digest string
+}
+
+// digestSummary is a row of events_statements_summary_by_digest.
+type digestSummary struct {
+	digestKey
+	digestText      string
+	count           uint64
+	sumTimerWait    uint64
+	minTimerWait    uint64
+	maxTimerWait    uint64
+	sumLockTime     uint64
+	sumErrors       uint64
+	sumWarnings     uint64
+	sumRowsAffected uint64
+	sumRowsSent     uint64
+	sumRowsExamined uint64
+	firstSeen       time.Time
+	lastSeen        time.Time
+}
+
+type digestSummaries struct {
+	mu        sync.Mutex
+	summaries map[digestKey]*digestSummary
+}
+
+var stmtDigests = &digestSummaries{summaries: make(map[digestKey]*digestSummary)}
+
+// add summarizes the finished statement event.
+func (s *digestSummaries) add(e *StatementEvent, now time.Time) {
+	wait := e.TimerEnd - e.TimerStart
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := s.lookup(e)
+	if summary == nil {
+		key := digestKey{schema: e.CurrentSchema, digest: e.Digest}
+		// Keep a row for the statements with NULL schema and digest.
+		if len(s.summaries) >= stmtsDigestSize-1 {
+			key = digestKey{}
+		}
+		summary = &digestSummary{digestKey: key, minTimerWait: wait, firstSeen: now}
+		if key.digest != "" {
+			summary.digestText = e.DigestText
+		}
+		s.summaries[key] = summary
+	}
+	summary.count++
+	summary.sumTimerWait += wait
+	if wait < summary.minTimerWait {
+		summary.minTimerWait = wait
+	}
+	if wait > summary.maxTimerWait {
+		summary.maxTimerWait = wait
+	}
+	summary.sumLockTime += e.LockTime
+	if e.ErrNo != 0 {
+		summary.sumErrors++
+	}
+	summary.sumWarnings += e.Warnings
+	summary.sumRowsAffected += e.RowsAffected
+	summary.sumRowsSent += e.RowsSent
+	summary.sumRowsExamined += e.RowsExamined
+	summary.lastSeen = now
+}
+
+// lookup returns the summary row of the event, it's the row with NULL schema and digest
+// if there is no room for the event, or nil if the row isn't created yet.
+func (s *digestSummaries) lookup(e *StatementEvent) *digestSummary {
+	if summary, ok := s.summaries[digestKey{schema: e.CurrentSchema, digest: e.Digest}]; ok {
+		return summary
+	}
+	if len(s.summaries) >= stmtsDigestSize-1 {
+		return s.summaries[digestKey{}]
+	}
+	return nil
+}
+
+func (s *digestSummaries) truncate() {
+	s.mu.Lock()
+	s.summaries = make(map[digestKey]*digestSummary)
+	s.mu.Unlock()
+}
+
+func (summary *digestSummary) toDatums() []types.Datum {
+	row := make([]types.Datum, len(ColumnStmtsSummaryByDigest))
+	if summary.digest != "" {
+		if summary.schema != "" {
+			row[0].SetString(summary.schema)
+		}
+		row[1].SetString(summary.digest)
+		row[2].SetString(summary.digestText)
+	}
+	row[3].SetUint64(summary.count)
+	row[4].SetUint64(summary.sumTimerWait)
+	row[5].SetUint64(summary.minTimerWait)
+	row[6].SetUint64(summary.sumTimerWait / summary.count)
+	row[7].SetUint64(summary.maxTimerWait)
+	row[8].SetUint64(summary.sumLockTime)
+	row[9].SetUint64(summary.sumErrors)
+	row[10].SetUint64(summary.sumWarnings)
+	row[11].SetUint64(summary.sumRowsAffected)
+	row[12].SetUint64(summary.sumRowsSent)
+	row[13].SetUint64(summary.sumRowsExamined)
+	// The optimizer and sort counters aren't collected.
+	for i := 14; i <= 26; i++ {
+		row[i].SetUint64(0)
+	}
+	row[27].SetMysqlTime(types.Time{Time: types.FromGoTime(summary.firstSeen), Type: mysql.TypeTimestamp})
+	row[28].SetMysqlTime(types.Time{Time: types.FromGoTime(summary.lastSeen), Type: mysql.TypeTimestamp})
+	return row
+}
+
+// digestSummaryDataSource is the data source of events_statements_summary_by_digest.
+type digestSummaryDataSource struct {
+	meta *model.TableInfo
+	cols []*table.Column
+}
+
+// GetRows implements the interface of VirtualDataSource.
+func (ds *digestSummaryDataSource) GetRows(ctx context.Context) ([][]types.Datum, error) {
+	stmtDigests.mu.Lock()
+	rows := make([][]types.Datum, 0, len(stmtDigests.summaries))
+	for _, summary := range stmtDigests.summaries {
+		rows = append(rows, summary.toDatums())
+	}
+	stmtDigests.mu.Unlock()
+	return rows, nil
+}
+
+// Meta implements the interface of VirtualDataSource.
+func (ds *digestSummaryDataSource) Meta() *model.TableInfo {
+	return ds.meta
+}
+
+// Cols implements the interface of VirtualDataSource.
+func (ds *digestSummaryDataSource) Cols() []*table.Column {
+	return ds.cols
+}
+
+// TruncateTable truncates the summary table of performance_schema.
+func TruncateTable(name string) error {
+	switch strings.ToUpper(name) {
+	case TableStmtsSummaryByDigest:
+		stmtDigests.truncate()
+		return nil
+	default:
+		return errors.Errorf("can't truncate table %s.%s", Name, name)
+	}
+}
@@ -14,6 +14,8 @@
 package perfschema
 
 import (
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
@@ -197,6 +199,38 @@ var stagesCurrentCols = []columnInfo{
 	{mysql.TypeEnum, -1, 0, nil, []string{"TRANSACTION", "STATEMENT", "STAGE"}},
 }
 
+var stmtsSummaryByDigestCols = []columnInfo{
+	{mysql.TypeVarchar, 64, 0, nil, nil},
+	{mysql.TypeVarchar, 32, 0, nil, nil},
+	{mysql.TypeLongBlob, -1, 0, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeTimestamp, 19, mysql.NotNullFlag, "0000-00-00 00:00:00", nil},
+	{mysql.TypeTimestamp, 19, mysql.NotNullFlag, "0000-00-00 00:00:00", nil},
+}
+
 func (ps *perfSchema) buildTables() {
 	tbls := make([]*model.TableInfo, 0, len(ps.tables))
 	dbID := autoid.GenLocalSchemaID()
@@ -211,8 +245,11 @@ func (ps *perfSchema) buildTables() {
 		var tbl table.Table
 		switch name {
 		//@TODO in the future, we need to add many VirtualTable, we may need to add new type for these tables.
-		case TableSessionStatus, TableGlobalStatus:
+		case TableSessionStatus, TableGlobalStatus, TableStmtsCurrent, TableStmtsHistory, TableStmtsHistoryLong,
+			TableStmtsSummaryByDigest:
 			tbl = createVirtualTable(meta, name)
+		case TableSetupConsumers:
+			tbl = &setupConsumersTable{Table: tables.MemoryTableFromMeta(alloc, meta)}
 		default:
 			tbl = tables.MemoryTableFromMeta(alloc, meta)
 		}
@@ -320,6 +357,7 @@ func (ps *perfSchema) initialize() {
 		stagesCurrentCols,
 		stagesCurrentCols, // same as above
 		stagesCurrentCols, // same as above
+		stmtsSummaryByDigestCols,
 	}
 
 	allColNames := [][]string{
@@ -334,12 +372,13 @@ func (ps *perfSchema) initialize() {
 		ColumnStmtsHistory,
 		ColumnStmtsHistoryLong,
 		ColumnPreparedStmtsInstances,
-		ColumnStmtsCurrent,
-		ColumnStmtsHistory,
-		ColumnStmtsHistoryLong,
+		ColumnTransCurrent,
+		ColumnTransHistory,
+		ColumnTransHistoryLong,
 		ColumnStagesCurrent,
 		ColumnStagesHistory,
 		ColumnStagesHistoryLong,
+		ColumnStmtsSummaryByDigest,
 	}
 
 	// initialize all table, column and result field definitions
@@ -347,6 +386,9 @@ func (ps *perfSchema) initialize() {
 		ps.buildModel(PerfSchemaTables[i], allColNames[i], def)
 	}
 	ps.buildTables()
+	if err := ps.initSetupConsumers(); err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
 }
 
 func (ps *perfSchema) GetDBMeta() *model.DBInfo {
@@ -0,0 +1,480 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const (
+	// stmtsHistorySize is the number of statement events kept for each thread in events_statements_history.
+	stmtsHistorySize = 10
+	// stmtsHistoryLongSize is the number of statement events kept in events_statements_history_long.
+	stmtsHistoryLongSize = 10000
+	// stmtEventShards is the number of shards of the per-thread statement events,
+	// the threads in different shards don't share a lock.
+	stmtEventShards = 32
+)
+
+// The consumer names in setup_consumers, same as MySQL.
+const (
+	ConsumerStagesCurrent         = "events_stages_current"
+	ConsumerStagesHistory         = "events_stages_history"
+	ConsumerStagesHistoryLong     = "events_stages_history_long"
+	ConsumerStmtsCurrent          = "events_statements_current"
+	ConsumerStmtsHistory          = "events_statements_history"
+	ConsumerStmtsHistoryLong      = "events_statements_history_long"
+	ConsumerTransCurrent          = "events_transactions_current"
+	ConsumerTransHistory          = "events_transactions_history"
+	ConsumerTransHistoryLong      = "events_transactions_history_long"
+	ConsumerGlobalInstrumentation = "global_instrumentation"
+	ConsumerThreadInstrumentation = "thread_instrumentation"
+	ConsumerStatementsDigest      = "statements_digest"
+)
+
+// setupConsumers contains the default rows of setup_consumers, same as MySQL.
+var setupConsumers = []struct {
+	name    string
+	enabled string
+}{
+	{ConsumerStagesCurrent, "NO"},
+	{ConsumerStagesHistory, "NO"},
+	{ConsumerStagesHistoryLong, "NO"},
+	{ConsumerStmtsCurrent, "YES"},
+	{ConsumerStmtsHistory, "YES"},
+	{ConsumerStmtsHistoryLong, "NO"},
+	{ConsumerTransCurrent, "NO"},
+	{ConsumerTransHistory, "NO"},
+	{ConsumerTransHistoryLong, "NO"},
+	{ConsumerGlobalInstrumentation, "YES"},
+	{ConsumerThreadInstrumentation, "YES"},
+	{ConsumerStatementsDigest, "YES"},
+}
+
+// serverStartTime is the base of the event timers.
+var serverStartTime = time.Now()
+
+// StatementEvent is a statement event of performance_schema, it's recorded into the events_statements_* tables.
+// The fields are protected by the lock of the shard of its thread once the event is started.
+type StatementEvent struct {
+	ThreadID   uint64
+	EventID    uint64
+	EndEventID uint64
+	EventName  string
+	// TimerStart and TimerEnd are in picoseconds since the server started, same as MySQL.
+	TimerStart    uint64
+	TimerEnd      uint64
+	LockTime      uint64
+	SQLText       string
+	Digest        string
+	DigestText    string
+	CurrentSchema string
+	ErrNo         uint16
+	SQLState      string
+	MessageText   string
+	Warnings      uint64
+	RowsAffected  uint64
+	RowsSent      uint64
+	RowsExamined  uint64
+
+	// consumers are the enabled consumers when the event started.
+	consumers consumerFlags
+}
+
+type consumerFlags struct {
+	current     bool
+	history     bool
+	historyLong bool
+	digest      bool
+}
+
+// eventRing keeps the last events pushed in.
+type eventRing struct {
+	events []*StatementEvent
+	next   int
+}
+
+func newEventRing(size int) *eventRing {
+	return &eventRing{events: make([]*StatementEvent, 0, size)}
+}
+
+func (r *eventRing) push(e *StatementEvent) {
+	if len(r.events) < cap(r.events) {
+		r.events = append(r.events, e)
+		return
+	}
+	r.events[r.next] = e
+	r.next = (r.next + 1) % len(r.events)
+}
+
+// all returns the events from the oldest to the newest.
+func (r *eventRing) all() []*StatementEvent {
+	events := make([]*StatementEvent, 0, len(r.events))
+	events = append(events, r.events[r.next:]...)
+	return append(events, r.events[:r.next]...)
+}
+
+type threadEvents struct {
+	lastEventID uint64
+	current     *StatementEvent
+	// last is the last finished event.
+	last    *StatementEvent
+	history *eventRing
+}
+
+// threadShard holds the statement events of the threads in a shard.
+type threadShard struct {
+	mu      sync.Mutex
+	threads map[uint64]*threadEvents
+}
+
+func (s *threadShard) thread(threadID uint64) *threadEvents {
+	t, ok := s.threads[threadID]
+	if !ok {
+		t = &threadEvents{history: newEventRing(stmtsHistorySize)}
+		s.threads[threadID] = t
+	}
+	return t
+}
+
+// statementEvents holds the statement events of all threads.
+type statementEvents struct {
+	shards [stmtEventShards]threadShard
+
+	historyLongMu sync.Mutex
+	historyLong   *eventRing
+}
+
+var stmtEvents = newStatementEvents()
+
+func newStatementEvents() *statementEvents {
+	s := &statementEvents{historyLong: newEventRing(stmtsHistoryLongSize)}
+	for i := range s.shards {
+		s.shards[i].threads = make(map[uint64]*threadEvents)
+	}
+	return s
+}
+
+func (s *statementEvents) shard(threadID uint64) *threadShard {
+	return &s.shards[threadID%stmtEventShards]
+}
+
+func picoseconds(t time.Time) uint64 {
+	return uint64(t.Sub(serverStartTime).Nanoseconds()) * 1000
+}
+
+// StartStatement starts a statement event of the thread, it returns nil if
+// no statement event consumer is enabled in setup_consumers.
+func StartStatement(threadID uint64, eventName, sqlText, schema string, start time.Time) *StatementEvent {
+	consumers := enabledStmtConsumers()
+	if !consumers.current && !consumers.history && !consumers.historyLong && !consumers.digest {
+		return nil
+	}
+	e := &StatementEvent{
+		ThreadID:      threadID,
+		EventName:     eventName,
+		TimerStart:    picoseconds(start),
+		SQLText:       sqlText,
+		CurrentSchema: schema,
+		consumers:     consumers,
+	}
+	// Lexing the statement for the digest is skipped if neither the digest summary nor the history needs it.
+	if consumers.digest || consumers.history || consumers.historyLong {
+		e.DigestText, e.Digest = parser.NormalizeDigest(sqlText)
+	}
+	shard := stmtEvents.shard(threadID)
+	shard.mu.Lock()
+	t := shard.thread(threadID)
+	t.lastEventID++
+	e.EventID = t.lastEventID
+	if consumers.current {
+		t.current = e
+	}
+	shard.mu.Unlock()
+	return e
+}
+
+// StatementStats is the execution information of a finished statement.
+type StatementStats struct {
+	LockTime     time.Duration
+	Warnings     uint64
+	RowsAffected uint64
+	RowsSent     uint64
+	RowsExamined uint64
+}
+
+// EndStatement finishes the statement event with the execution information, the finished
+// event stays in events_statements_current and is added to the history tables.
+func EndStatement(e *StatementEvent, stats StatementStats, err error) {
+	if e == nil {
+		return
+	}
+	end := picoseconds(time.Now())
+	shard := stmtEvents.shard(e.ThreadID)
+	shard.mu.Lock()
+	e.TimerEnd = end
+	e.EndEventID = e.EventID
+	e.LockTime = uint64(stats.LockTime.Nanoseconds()) * 1000
+	e.Warnings = stats.Warnings
+	e.RowsAffected = stats.RowsAffected
+	e.RowsSent = stats.RowsSent
+	e.RowsExamined = stats.RowsExamined
+	if err != nil {
+		e.setError(err)
+		// Nothing is written if the statement fails.
+		e.RowsAffected = 0
+	}
+	// The events of the thread aren't kept if the thread has ended.
+	if t, ok := shard.threads[e.ThreadID]; ok {
+		t.last = e
+		if e.consumers.history {
+			t.history.push(e)
+		}
+	}
+	shard.mu.Unlock()
+
+	// The event isn't changed after it's finished, so it's read without the lock of the shard.
+	if e.consumers.historyLong {
+		stmtEvents.historyLongMu.Lock()
+		stmtEvents.historyLong.push(e)
+		stmtEvents.historyLongMu.Unlock()
+	}
+	if e.consumers.digest {
+		stmtDigests.add(e, time.Now())
+	}
+}
+
+func (e *StatementEvent) setError(err error) {
+	var sqlErr *mysql.SQLError
+	if te, ok := errors.Cause(err).(*terror.Error); ok {
+		sqlErr = te.ToSQLError()
+	} else {
+		sqlErr = mysql.NewErrf(mysql.ErrUnknown, "%s", err.Error())
+	}
+	e.ErrNo, e.SQLState, e.MessageText = sqlErr.Code, sqlErr.State, sqlErr.Message
+}
+
+// EndThread drops the statement events of the thread, it's called when the connection is closed.
+func EndThread(threadID uint64) {
+	shard := stmtEvents.shard(threadID)
+	shard.mu.Lock()
+	delete(shard.threads, threadID)
+	shard.mu.Unlock()
+}
+
+// toDatums converts the event to a row of the events_statements_* tables.
+func (e *StatementEvent) toDatums() []types.Datum {
+	row := make([]types.Datum, len(ColumnStmtsCurrent))
+	row[0].SetUint64(e.ThreadID)
+	row[1].SetUint64(e.EventID)
+	if e.EndEventID != 0 {
+		row[2].SetUint64(e.EndEventID)
+	}
+	row[3].SetString(e.EventName)
+	row[5].SetUint64(e.TimerStart)
+	if e.TimerEnd != 0 {
+		row[6].SetUint64(e.TimerEnd)
+		row[7].SetUint64(e.TimerEnd - e.TimerStart)
+	}
+	row[8].SetUint64(e.LockTime)
+	row[9].SetString(e.SQLText)
+	if e.Digest != "" {
+		row[10].SetString(e.Digest)
+		row[11].SetString(e.DigestText)
+	}
+	if e.CurrentSchema != "" {
+		row[12].SetString(e.CurrentSchema)
+	}
+	var errCnt uint64
+	if e.ErrNo != 0 {
+		errCnt = 1
+		row[17].SetInt64(int64(e.ErrNo))
+		row[18].SetString(e.SQLState)
+		row[19].SetString(e.MessageText)
+	} else if e.EndEventID != 0 {
+		row[17].SetInt64(0)
+		row[18].SetString("00000")
+	}
+	row[20].SetUint64(errCnt)
+	row[21].SetUint64(e.Warnings)
+	row[22].SetUint64(e.RowsAffected)
+	row[23].SetUint64(e.RowsSent)
+	row[24].SetUint64(e.RowsExamined)
+	// The optimizer and sort counters aren't collected.
+	for i := 25; i <= 37; i++ {
+		row[i].SetUint64(0)
+	}
+	return row
+}
+
+// stmtsDataSource is the data source of events_statements_current, events_statements_history
+// and events_statements_history_long.
+type stmtsDataSource struct {
+	meta      *model.TableInfo
+	cols      []*table.Column
+	tableName string
+}
+
+// GetRows implements the interface of VirtualDataSource.
+func (ds *stmtsDataSource) GetRows(ctx context.Context) ([][]types.Datum, error) {
+	var rows [][]types.Datum
+	switch ds.tableName {
+	case TableStmtsCurrent, TableStmtsHistory:
+		for i := range stmtEvents.shards {
+			shard := &stmtEvents.shards[i]
+			shard.mu.Lock()
+			for _, t := range shard.threads {
+				if ds.tableName == TableStmtsHistory {
+					for _, e := range t.history.all() {
+						rows = append(rows, e.toDatums())
+					}
+				} else if t.current != nil {
+					rows = append(rows, t.current.toDatums())
+				}
+			}
+			shard.mu.Unlock()
+		}
+	case TableStmtsHistoryLong:
+		stmtEvents.historyLongMu.Lock()
+		for _, e := range stmtEvents.historyLong.all() {
+			rows = append(rows, e.toDatums())
+		}
+		stmtEvents.historyLongMu.Unlock()
+	}
+	return rows, nil
+}
+
+// Meta implements the interface of VirtualDataSource.
+func (ds *stmtsDataSource) Meta() *model.TableInfo {
+	return ds.meta
+}
+
+// Cols implements the interface of VirtualDataSource.
+func (ds *stmtsDataSource) Cols() []*table.Column {
+	return ds.cols
+}
+
+// stmtConsumersVersion is increased by the writes to setup_consumers, the cached statement event
+// consumers are valid only if they are read at the current version.
+var stmtConsumersVersion uint64
+
+// stmtConsumersCache caches the statement event consumers, so setup_consumers isn't scanned for every statement.
+var stmtConsumersCache atomic.Value
+
+type cachedStmtConsumers struct {
+	version   uint64
+	consumers consumerFlags
+}
+
+func invalidateStmtConsumers() {
+	atomic.AddUint64(&stmtConsumersVersion, 1)
+}
+
+// enabledStmtConsumers returns the statement event consumers enabled in setup_consumers.
+func enabledStmtConsumers() consumerFlags {
+	version := atomic.LoadUint64(&stmtConsumersVersion)
+	if cached, ok := stmtConsumersCache.Load().(*cachedStmtConsumers); ok && cached.version == version {
+		return cached.consumers
+	}
+	enabled := handle.enabledConsumers()
+	global := enabled[ConsumerGlobalInstrumentation]
+	thread := global && enabled[ConsumerThreadInstrumentation]
+	consumers := consumerFlags{
+		current:     thread && enabled[ConsumerStmtsCurrent],
+		history:     thread && enabled[ConsumerStmtsHistory],
+		historyLong: global && enabled[ConsumerStmtsHistoryLong],
+		digest:      global && enabled[ConsumerStatementsDigest],
+	}
+	stmtConsumersCache.Store(&cachedStmtConsumers{version: version, consumers: consumers})
+	return consumers
+}
+
+// setupConsumersTable is the memory table of setup_consumers, the writes to it invalidate the
+// cached statement event consumers.
+type setupConsumersTable struct {
+	table.Table
+}
+
+// AddRecord implements table.Table AddRecord interface.
+func (t *setupConsumersTable) AddRecord(ctx context.Context, r []types.Datum) (int64, error) {
+	defer invalidateStmtConsumers()
+	return t.Table.AddRecord(ctx, r)
+}
+
+// UpdateRecord implements table.Table UpdateRecord interface.
+func (t *setupConsumersTable) UpdateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, touched []bool) error {
+	defer invalidateStmtConsumers()
+	return t.Table.UpdateRecord(ctx, h, oldData, newData, touched)
+}
+
+// RemoveRecord implements table.Table RemoveRecord interface.
+func (t *setupConsumersTable) RemoveRecord(ctx context.Context, h int64, r []types.Datum) error {
+	defer invalidateStmtConsumers()
+	return t.Table.RemoveRecord(ctx, h, r)
+}
+
+// initSetupConsumers fills setup_consumers with the default rows.
+func (ps *perfSchema) initSetupConsumers() error {
+	tbl := ps.mTables[TableSetupConsumers]
+	for _, c := range setupConsumers {
+		enabled, err := types.ParseEnumName(setupConsumersCols[1].elems, c.enabled)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		row := make([]types.Datum, 2)
+		row[0].SetString(c.name)
+		row[1].SetMysqlEnum(enabled)
+		_, err = tbl.AddRecord(nil, row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// enabledConsumers returns the consumers enabled in setup_consumers.
+func (ps *perfSchema) enabledConsumers() map[string]bool {
+	tbl := ps.mTables[TableSetupConsumers]
+	enabled := make(map[string]bool, len(setupConsumers))
+	for h := int64(0); ; h++ {
+		var found bool
+		h, found, _ = tbl.Seek(nil, h)
+		if !found {
+			break
+		}
+		row, err := tbl.Row(nil, h)
+		if err != nil {
+			continue
+		}
+		name, err1 := row[0].ToString()
+		value, err2 := row[1].ToString()
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		enabled[strings.ToLower(name)] = strings.EqualFold(value, "YES")
+	}
+	return enabled
+}
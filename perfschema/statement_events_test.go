@@ -0,0 +1,110 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+func (*testSuite) TestEventRing(c *C) {
+	r := newEventRing(3)
+	for i := 1; i <= 5; i++ {
+		r.push(&StatementEvent{EventID: uint64(i)})
+		events := r.all()
+		c.Assert(events[len(events)-1].EventID, Equals, uint64(i))
+	}
+	events := r.all()
+	c.Assert(events, HasLen, 3)
+	for i, e := range events {
+		c.Assert(e.EventID, Equals, uint64(i+3))
+	}
+}
+
+func (*testSuite) TestStatementEvents(c *C) {
+	threadID := uint64(1 << 40)
+	defer EndThread(threadID)
+	for i := 0; i < stmtsHistorySize+2; i++ {
+		e := StartStatement(threadID, "statement/sql/select", "select 1", "test", time.Now())
+		c.Assert(e, NotNil)
+		EndStatement(e, StatementStats{RowsSent: 1}, nil)
+	}
+	t := stmtEvents.shard(threadID).threads[threadID]
+	c.Assert(t.current.EventID, Equals, uint64(stmtsHistorySize+2))
+	history := t.history.all()
+	c.Assert(history, HasLen, stmtsHistorySize)
+	c.Assert(history[0].EventID, Equals, uint64(3))
+
+	ds := &stmtsDataSource{tableName: TableStmtsCurrent}
+	rows, err := ds.GetRows(nil)
+	c.Assert(err, IsNil)
+	var found bool
+	for _, row := range rows {
+		if row[0].GetUint64() == threadID {
+			found = true
+			c.Assert(row[23].GetUint64(), Equals, uint64(1))
+			c.Assert(row[7].GetUint64(), Equals, row[6].GetUint64()-row[5].GetUint64())
+		}
+	}
+	c.Assert(found, IsTrue)
+
+	// No event is collected when global_instrumentation is disabled.
+	tbl := handle.mTables[TableSetupConsumers]
+	h, found, err := tbl.Seek(nil, 0)
+	for ; found && err == nil; h, found, err = tbl.Seek(nil, h+1) {
+		row, err1 := tbl.Row(nil, h)
+		c.Assert(err1, IsNil)
+		if row[0].GetString() != ConsumerGlobalInstrumentation {
+			continue
+		}
+		newRow := append(row[:0:0], row...)
+		newRow[1].SetString("NO")
+		c.Assert(tbl.UpdateRecord(nil, h, row, newRow, nil), IsNil)
+		c.Assert(StartStatement(threadID, "statement/sql/select", "select 1", "test", time.Now()), IsNil)
+		c.Assert(tbl.UpdateRecord(nil, h, newRow, row, nil), IsNil)
+	}
+	c.Assert(StartStatement(threadID, "statement/sql/select", "select 1", "test", time.Now()), NotNil)
+}
+
+func (*testSuite) TestEndStatementAfterEndThread(c *C) {
+	threadID := uint64(1<<40 + 1)
+	e := StartStatement(threadID, "statement/sql/select", "select 1", "test", time.Now())
+	c.Assert(e, NotNil)
+	EndThread(threadID)
+	EndStatement(e, StatementStats{}, nil)
+	_, ok := stmtEvents.shard(threadID).threads[threadID]
+	c.Assert(ok, IsFalse)
+}
+
+func (*testSuite) TestDigestSummaryOverflow(c *C) {
+	s := &digestSummaries{summaries: make(map[digestKey]*digestSummary)}
+	now := time.Now()
+	for i := 0; i < stmtsDigestSize+10; i++ {
+		e := &StatementEvent{CurrentSchema: "test", Digest: fmt.Sprintf("%032d", i), TimerStart: 1, TimerEnd: uint64(i + 2)}
+		s.add(e, now)
+	}
+	c.Assert(s.summaries, HasLen, stmtsDigestSize)
+	overflow := s.summaries[digestKey{}]
+	c.Assert(overflow, NotNil)
+	c.Assert(overflow.count, Equals, uint64(11))
+	row := overflow.toDatums()
+	c.Assert(row[0].IsNull(), IsTrue)
+	c.Assert(row[1].IsNull(), IsTrue)
+	c.Assert(row[3].GetUint64(), Equals, uint64(11))
+
+	c.Assert(TruncateTable("events_statements_summary_by_digest"), IsNil)
+	c.Assert(TruncateTable(TableStmtsCurrent), NotNil)
+}
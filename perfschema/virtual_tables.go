@@ -85,6 +85,10 @@ func createVirtualDataSource(tableName string, meta *model.TableInfo) (tables.Vi
 		return &statusDataSource{meta: meta, cols: columns, globalScope: false}, nil
 	case TableGlobalStatus:
 		return &statusDataSource{meta: meta, cols: columns, globalScope: true}, nil
+	case TableStmtsCurrent, TableStmtsHistory, TableStmtsHistoryLong:
+		return &stmtsDataSource{meta: meta, cols: columns, tableName: tableName}, nil
+	case TableStmtsSummaryByDigest:
+		return &digestSummaryDataSource{meta: meta, cols: columns}, nil
 	default:
 		return nil, errors.New("can't find table named by " + tableName)
 	}
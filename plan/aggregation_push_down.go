@@ -188,6 +188,15 @@ func (a *aggregationOptimizer) decompose(aggFunc aggregation.Aggregation, schema
 	return result, schema
 }
 
+func (a *aggregationOptimizer) hasGroupingFunc(aggFuncs []aggregation.Aggregation) bool {
+	for _, fun := range aggFuncs {
+		if fun.GetName() == ast.AggFuncGrouping {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *aggregationOptimizer) allFirstRow(aggFuncs []aggregation.Aggregation) bool {
 	for _, fun := range aggFuncs {
 		if fun.GetName() != ast.AggFuncFirstRow {
@@ -378,7 +387,7 @@ func (a *aggregationOptimizer) aggPushDown(p LogicalPlan) LogicalPlan {
 				projChild := proj.children[0]
 				agg.SetChildren(projChild)
 				projChild.SetParents(agg)
-			} else if union, ok1 := child.(*Union); ok1 {
+			} else if union, ok1 := child.(*Union); ok1 && !a.hasGroupingFunc(agg.AggFuncs) {
 				var gbyCols []*expression.Column
 				for _, gbyExpr := range agg.GroupByItems {
 					gbyCols = append(gbyCols, expression.ExtractColumns(gbyExpr)...)
@@ -410,6 +419,10 @@ func (a *aggregationOptimizer) aggPushDown(p LogicalPlan) LogicalPlan {
 // For count(expr), sum(expr), avg(expr), count(distinct expr, [expr...]) we may need to rewrite the expr. Details are shown below.
 // If we can eliminate agg successful, we return a projection. Else we return a nil pointer.
 func (a *aggregationOptimizer) tryToEliminateAggregation(agg *LogicalAggregation) *Projection {
+	if len(agg.GroupingSets) > 0 {
+		// The super-aggregate rows of the grouping sets can't be produced by a projection.
+		return nil
+	}
 	schemaByGroupby := expression.NewSchema(agg.groupByCols...)
 	coveredByUniqueKey := false
 	for _, key := range agg.children[0].Schema().Keys {
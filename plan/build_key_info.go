@@ -29,6 +29,10 @@ func (s *buildKeySolver) optimize(lp LogicalPlan, _ context.Context, _ *idAlloca
 
 func (p *LogicalAggregation) buildKeyInfo() {
 	p.baseLogicalPlan.buildKeyInfo()
+	if len(p.GroupingSets) > 0 {
+		// The super-aggregate rows of the grouping sets duplicate the keys with NULL values.
+		return
+	}
 	for _, key := range p.Children()[0].Schema().Keys {
 		indices := p.schema.ColumnsIndices(key)
 		if indices == nil {
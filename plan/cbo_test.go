@@ -209,6 +209,98 @@ func (s *testAnalyzeSuite) TestEmptyTable(c *C) {
 	}
 }
 
+func (s *testAnalyzeSuite) TestGeneratedColumnSubstitute(c *C) {
+	defer testleak.AfterTest(c)()
+	store, dom, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	testKit := testkit.NewTestKit(c, store)
+	defer func() {
+		dom.Close()
+		store.Close()
+	}()
+	testKit.MustExec("use test")
+	testKit.MustExec("drop table if exists t")
+	testKit.MustExec(`create table t (a int primary key, doc json,
+		uid varchar(20) as (json_unquote(json_extract(doc, '$.user_id'))) stored,
+		score int as (a + 1) stored,
+		j json as (json_extract(doc, '$.tags')) stored,
+		v varchar(20) as (json_unquote(json_extract(doc, '$.name'))) virtual,
+		n int as (json_extract(doc, '$.num')) virtual,
+		index uid (uid), index score (score), index v (v), index n (n))`)
+	testKit.MustExec(`insert into t (a, doc) values (1, '{"user_id": "u1", "name": "n1", "num": 5}'), (2, '{"user_id": "u2", "num": 3}')`)
+	tests := []struct {
+		sql  string
+		best string
+	}{
+		{
+			sql:  `select * from t where json_unquote(json_extract(doc, '$.user_id')) = 'u1'`,
+			best: "IndexLookUp(Index(t.uid)[[u1,u1]], Table(t))->Projection",
+		},
+		{
+			sql:  `select * from t where doc->>'$.user_id' in ('u1', 'u2')`,
+			best: "IndexLookUp(Index(t.uid)[[u1,u1] [u2,u2]], Table(t))->Projection",
+		},
+		{
+			sql:  `select a from t order by json_unquote(json_extract(doc, '$.user_id'))`,
+			best: "IndexReader(Index(t.uid)[[<nil>,+inf]])->Projection",
+		},
+		{
+			sql:  `select a from t where a + 1 > 2`,
+			best: "IndexReader(Index(t.score)[(2,+inf]])->Projection",
+		},
+		{
+			// The expression isn't substituted since the column type is different from its type.
+			sql:  `select a from t where json_extract(doc, '$.user_id') = 'u1'`,
+			best: "TableReader(Table(t))->Sel([eq(json_extract(test.t.doc, $.user_id), cast(u1))])->Projection",
+		},
+		{
+			sql:  `select a from t where json_unquote(json_extract(doc, '$.name')) = 'n1'`,
+			best: "IndexLookUp(Index(t.v)[[n1,n1]], Table(t))->Projection->Sel([eq(test.t.v, n1)])->Projection",
+		},
+		{
+			sql:  `select a from t where v > 'n0'`,
+			best: "IndexLookUp(Index(t.v)[(n0,+inf]], Table(t))->Projection->Sel([gt(test.t.v, n0)])->Projection",
+		},
+		{
+			sql:  `select a from t order by v`,
+			best: "IndexLookUp(Index(t.v)[[<nil>,+inf]], Table(t))->Projection->Projection",
+		},
+		{
+			sql:  `select a from t order by json_unquote(json_extract(doc, '$.name'))`,
+			best: "IndexLookUp(Index(t.v)[[<nil>,+inf]], Table(t))->Projection->Projection",
+		},
+		{
+			// The INT column is compared with the constant through the implicit cast.
+			sql:  `select a from t where json_extract(doc, '$.num') = 5`,
+			best: "IndexLookUp(Index(t.n)[[5,5]], Table(t))->Projection->Sel([eq(test.t.n, 5)])->Projection",
+		},
+		{
+			sql:  `select a from t where 4 < json_extract(doc, '$.num')`,
+			best: "IndexLookUp(Index(t.n)[(4,+inf]], Table(t))->Projection->Sel([gt(test.t.n, 4)])->Projection",
+		},
+	}
+	for _, tt := range tests {
+		ctx := testKit.Se.(context.Context)
+		stmts, err := tidb.Parse(ctx, tt.sql)
+		c.Assert(err, IsNil)
+		c.Assert(stmts, HasLen, 1)
+		stmt := stmts[0]
+		is := sessionctx.GetDomain(ctx).InfoSchema()
+		err = plan.ResolveName(stmt, is, ctx)
+		c.Assert(err, IsNil)
+		p, err := plan.Optimize(ctx, stmt, is)
+		c.Assert(err, IsNil)
+		c.Assert(plan.ToString(p), Equals, tt.best, Commentf("for %s", tt.sql))
+	}
+	testKit.MustQuery(`select a from t where doc->>'$.user_id' = 'u2'`).Check(testkit.Rows("2"))
+	testKit.MustQuery(`select a from t order by doc->>'$.user_id' desc`).Check(testkit.Rows("2", "1"))
+	testKit.MustQuery(`select a, v from t where a + 1 > 2`).Check(testkit.Rows("2 <nil>"))
+	testKit.MustQuery(`select a, v from t where doc->>'$.name' = 'n1'`).Check(testkit.Rows("1 n1"))
+	testKit.MustQuery(`select a, v from t order by v desc`).Check(testkit.Rows("1 n1", "2 <nil>"))
+	testKit.MustQuery(`select a, n from t where json_extract(doc, '$.num') = 5`).Check(testkit.Rows("1 5"))
+	testKit.MustQuery(`select a from t where json_extract(doc, '$.num') in (3, 5) order by n`).Check(testkit.Rows("2", "1"))
+}
+
 func (s *testAnalyzeSuite) TestAnalyze(c *C) {
 	defer testleak.AfterTest(c)()
 	store, dom, err := newStoreWithBootstrap()
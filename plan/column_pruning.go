@@ -69,8 +69,12 @@ func (p *Projection) PruneColumns(parentUsedCols []*expression.Column) {
 		}
 	}
 	var selfUsedCols []*expression.Column
-	for _, expr := range p.Exprs {
+	for i, expr := range p.Exprs {
 		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(expr)...)
+		// The virtual generated columns are kept in the data source, so that the indexes on them can be used.
+		if _, ok := expr.(*expression.ScalarFunction); ok && p.calculateGenCols && UseDAGPlanBuilder(p.ctx) {
+			selfUsedCols = append(selfUsedCols, p.schema.Columns[i])
+		}
 	}
 	child.PruneColumns(selfUsedCols)
 }
@@ -104,7 +108,8 @@ func (p *LogicalAggregation) PruneColumns(parentUsedCols []*expression.Column) {
 	if len(p.GroupByItems) > 0 {
 		for i := len(p.GroupByItems) - 1; i >= 0; i-- {
 			cols := expression.ExtractColumns(p.GroupByItems[i])
-			if len(cols) == 0 {
+			// The constant group-by items can't be pruned for the grouping sets, `group by 1 with rollup` returns two rows.
+			if len(cols) == 0 && len(p.GroupingSets) == 0 {
 				p.GroupByItems = append(p.GroupByItems[:i], p.GroupByItems[i+1:]...)
 			} else {
 				selfUsedCols = append(selfUsedCols, cols...)
@@ -119,6 +124,31 @@ func (p *LogicalAggregation) PruneColumns(parentUsedCols []*expression.Column) {
 	child.PruneColumns(selfUsedCols)
 }
 
+// PruneColumns implements LogicalPlan interface.
+func (p *LogicalWindow) PruneColumns(parentUsedCols []*expression.Column) {
+	child := p.children[0].(LogicalPlan)
+	windowCol := p.GetWindowResultColumn()
+	var selfUsedCols []*expression.Column
+	for _, col := range parentUsedCols {
+		if !col.Equal(windowCol, nil) {
+			selfUsedCols = append(selfUsedCols, col)
+		}
+	}
+	for _, arg := range p.WindowFuncDesc.Args {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(arg)...)
+	}
+	for _, item := range p.PartitionBy {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(item)...)
+	}
+	for _, item := range p.OrderBy {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(item.Expr)...)
+	}
+	child.PruneColumns(selfUsedCols)
+	schema := child.Schema().Clone()
+	schema.Append(windowCol)
+	p.SetSchema(schema)
+}
+
 // PruneColumns implements LogicalPlan interface.
 func (p *Sort) PruneColumns(parentUsedCols []*expression.Column) {
 	child := p.children[0].(LogicalPlan)
@@ -155,6 +185,16 @@ func (p *Union) PruneColumns(parentUsedCols []*expression.Column) {
 	}
 }
 
+// PruneColumns implements LogicalPlan interface.
+// The columns of a recursive common table expression can't be pruned, because the recursive part reads the rows
+// produced by itself.
+func (p *RecursiveCTE) PruneColumns(_ []*expression.Column) {
+	for _, c := range p.Children() {
+		child := c.(LogicalPlan)
+		child.PruneColumns(child.Schema().Columns)
+	}
+}
+
 // PruneColumns implements LogicalPlan interface.
 func (p *DataSource) PruneColumns(parentUsedCols []*expression.Column) {
 	used := getUsedList(parentUsedCols, p.schema)
@@ -200,6 +240,10 @@ func (p *DataSource) pruneUnionScanSchema(usedMask []bool) {
 func (p *TableDual) PruneColumns(_ []*expression.Column) {
 }
 
+// PruneColumns implements LogicalPlan interface.
+func (p *CTEWorkTable) PruneColumns(_ []*expression.Column) {
+}
+
 // PruneColumns implements LogicalPlan interface.
 func (p *Exists) PruneColumns(parentUsedCols []*expression.Column) {
 	p.children[0].(LogicalPlan).PruneColumns(nil)
@@ -751,6 +751,16 @@ func (s *testPlanSuite) TestDAGPlanBuilderAgg(c *C) {
 			sql:  "select sum(to_base64(e)) from t group by e,d,c order by c,e",
 			best: "IndexReader(Index(t.c_d_e)[[<nil>,+inf]])->StreamAgg->Sort->Projection",
 		},
+		// Test rollup, it is only supported by the hash agg and the base grouping set is pushed down.
+		{
+			sql:  "select count(*) from t group by g with rollup order by g",
+			best: "TableReader(Table(t)->HashAgg)->HashAgg->Sort->Projection",
+		},
+		// GROUPING can't be pushed down.
+		{
+			sql:  "select sum(e), grouping(c) from t group by c with rollup",
+			best: "TableReader(Table(t))->HashAgg",
+		},
 		// Test stream agg + limit or sort
 		{
 			sql:  "select count(*) from t group by g order by g limit 10",
@@ -120,9 +120,10 @@ func (pe *projectionEliminater) eliminate(p LogicalPlan, replace map[string]*exp
 	children := make([]Plan, 0, len(p.Children()))
 
 	childFlag := canEliminate
-	if _, isUnion := p.(*Union); isUnion {
+	switch p.(type) {
+	case *Union, *RecursiveCTE:
 		childFlag = false
-	} else if isProj {
+	case *Projection:
 		childFlag = true
 	}
 	for _, child := range p.Children() {
@@ -150,6 +151,10 @@ func (pe *projectionEliminater) eliminate(p LogicalPlan, replace map[string]*exp
 			newSchema.Append(p.Schema().Columns[len(p.Schema().Columns)-1])
 			p.SetSchema(newSchema)
 		}
+	case *LogicalWindow:
+		newSchema := p.Children()[0].Schema().Clone()
+		newSchema.Append(p.Schema().Columns[len(p.Schema().Columns)-1])
+		p.SetSchema(newSchema)
 	default:
 		for _, dst := range p.Schema().Columns {
 			resolveColumnAndReplace(dst, replace)
@@ -204,6 +209,18 @@ func (p *LogicalAggregation) replaceExprColumns(replace map[string]*expression.C
 	p.collectGroupByColumns()
 }
 
+func (p *LogicalWindow) replaceExprColumns(replace map[string]*expression.Column) {
+	for _, arg := range p.WindowFuncDesc.Args {
+		resolveExprAndReplace(arg, replace)
+	}
+	for _, item := range p.PartitionBy {
+		resolveExprAndReplace(item, replace)
+	}
+	for _, item := range p.OrderBy {
+		resolveExprAndReplace(item.Expr, replace)
+	}
+}
+
 func (p *Selection) replaceExprColumns(replace map[string]*expression.Column) {
 	for _, expr := range p.Conditions {
 		resolveExprAndReplace(expr, replace)
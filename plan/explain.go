@@ -16,6 +16,7 @@ package plan
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/expression/aggregation"
@@ -57,6 +58,69 @@ func setParents4FinalPlan(plan PhysicalPlan) {
 	}
 }
 
+// ExplainTree returns the operator tree of the physical plan in text, each operator takes a line in the
+// format of "id	task	operator info	count" and the children are indented under their parents.
+// It returns an empty string if p isn't a physical plan.
+func ExplainTree(p Plan) string {
+	pp, ok := p.(PhysicalPlan)
+	if !ok {
+		return ""
+	}
+	var buf bytes.Buffer
+	explainTree(&buf, pp, "root", 0)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func explainTree(buf *bytes.Buffer, p PhysicalPlan, taskType string, indent int) {
+	var count float64
+	// The DML plans don't have stats profiles.
+	if profile := p.statsProfile(); profile != nil {
+		count = profile.count
+	}
+	fmt.Fprintf(buf, "%s%s\t%s\t%s\t%.2f\n", strings.Repeat("  ", indent), p.ExplainID(), taskType, p.ExplainInfo(), count)
+	switch copPlan := p.(type) {
+	case *PhysicalTableReader:
+		explainTree(buf, copPlan.tablePlan, "cop", indent+1)
+	case *PhysicalIndexReader:
+		explainTree(buf, copPlan.indexPlan, "cop", indent+1)
+	case *PhysicalIndexLookUpReader:
+		explainTree(buf, copPlan.indexPlan, "cop", indent+1)
+		explainTree(buf, copPlan.tablePlan, "cop", indent+1)
+	}
+	for _, child := range p.Children() {
+		if c, ok := child.(PhysicalPlan); ok {
+			explainTree(buf, c, taskType, indent+1)
+		}
+	}
+}
+
+// UsedIndexNames returns the names of the indices read by the physical plan, in the format of "table:index".
+func UsedIndexNames(p Plan) []string {
+	pp, ok := p.(PhysicalPlan)
+	if !ok {
+		return nil
+	}
+	var names []string
+	collectIndexNames(pp, &names)
+	return names
+}
+
+func collectIndexNames(p PhysicalPlan, names *[]string) {
+	switch x := p.(type) {
+	case *PhysicalIndexScan:
+		*names = append(*names, x.Table.Name.O+":"+x.Index.Name.O)
+	case *PhysicalIndexReader:
+		collectIndexNames(x.indexPlan, names)
+	case *PhysicalIndexLookUpReader:
+		collectIndexNames(x.indexPlan, names)
+	}
+	for _, child := range p.Children() {
+		if c, ok := child.(PhysicalPlan); ok {
+			collectIndexNames(c, names)
+		}
+	}
+}
+
 // ExplainInfo implements PhysicalPlan interface.
 func (p *SelectLock) ExplainInfo() string {
 	return p.Lock.String()
@@ -157,19 +221,31 @@ func (p *TableDual) ExplainInfo() string {
 	return fmt.Sprintf("rows:%v", p.RowCount)
 }
 
-// ExplainInfo implements PhysicalPlan interface.
-func (p *Sort) ExplainInfo() string {
-	buffer := bytes.NewBufferString("")
-	for i, item := range p.ByItems {
+func explainByItems(buffer *bytes.Buffer, byItems []*ByItems) *bytes.Buffer {
+	for i, item := range byItems {
 		order := "asc"
 		if item.Desc {
 			order = "desc"
 		}
 		buffer.WriteString(fmt.Sprintf("%s:%s", item.Expr.ExplainInfo(), order))
-		if i+1 < len(p.ByItems) {
+		if i+1 < len(byItems) {
 			buffer.WriteString(", ")
 		}
 	}
+	return buffer
+}
+
+// ExplainInfo implements PhysicalPlan interface.
+func (p *Sort) ExplainInfo() string {
+	buffer := explainByItems(bytes.NewBufferString(""), p.ByItems)
+	buffer.WriteString(fmt.Sprintf(", spill:%v", p.spill))
+	return buffer.String()
+}
+
+// ExplainInfo implements PhysicalPlan interface.
+func (p *TopN) ExplainInfo() string {
+	buffer := explainByItems(bytes.NewBufferString(""), p.ByItems)
+	buffer.WriteString(fmt.Sprintf(", offset:%v, count:%v, spill:%v", p.Offset, p.Count, p.spill))
 	return buffer.String()
 }
 
@@ -184,6 +260,9 @@ func (p *PhysicalAggregation) ExplainInfo() string {
 	if p.HasGby && len(p.GroupByItems) > 0 {
 		buffer.WriteString(fmt.Sprintf(", group by:%s",
 			expression.ExplainExpressionList(p.GroupByItems)))
+		if len(p.GroupingSets) > 0 {
+			buffer.WriteString(" with rollup")
+		}
 	}
 	buffer.WriteString(", funcs:")
 	for i, agg := range p.AggFuncs {
@@ -195,6 +274,21 @@ func (p *PhysicalAggregation) ExplainInfo() string {
 	return buffer.String()
 }
 
+// ExplainInfo implements PhysicalPlan interface.
+func (p *PhysicalWindow) ExplainInfo() string {
+	buffer := bytes.NewBufferString(aggregation.ExplainWindowFunc(p.WindowFuncDesc))
+	if len(p.PartitionBy) > 0 {
+		buffer.WriteString(fmt.Sprintf(", partition by:%s",
+			expression.ExplainExpressionList(p.PartitionBy)))
+	}
+	if len(p.OrderBy) > 0 {
+		buffer.WriteString(", order by:")
+		explainByItems(buffer, p.OrderBy)
+	}
+	buffer.WriteString(fmt.Sprintf(", frame:%s", p.Frame))
+	return buffer.String()
+}
+
 // ExplainInfo implements PhysicalPlan interface.
 func (p *PhysicalApply) ExplainInfo() string {
 	buffer := bytes.NewBufferString(p.PhysicalJoin.ExplainInfo())
@@ -34,11 +34,12 @@ func (s *testExplainSuite) TestExplain(c *C) {
 	}()
 	tk := testkit.NewTestKit(c, store)
 	tk.MustExec("use test")
-	tk.MustExec("drop table if exists t1, t2, t3")
+	tk.MustExec("drop table if exists t1, t2, t3, t4")
 	tk.MustExec("create table t1 (c1 int primary key, c2 int, c3 int, index c2 (c2))")
 	tk.MustExec("create table t2 (c1 int unique, c2 int)")
 	tk.MustExec("insert into t2 values(1, 0), (2, 1)")
 	tk.MustExec("create table t3 (a bigint, b bigint, c bigint, d bigint)")
+	tk.MustExec("create table t4 (a varchar(255))")
 
 	tests := []struct {
 		sql    string
@@ -78,7 +79,7 @@ func (s *testExplainSuite) TestExplain(c *C) {
 			[]string{
 				"TableScan_4   cop table:t2, range:(-inf,+inf), keep order:false 8000",
 				"TableReader_5 Sort_3  root data:TableScan_4 8000",
-				"Sort_3  TableReader_5 root t2.c2:asc 8000",
+				"Sort_3  TableReader_5 root t2.c2:asc, spill:false 8000",
 			},
 		},
 		{
@@ -130,7 +131,7 @@ func (s *testExplainSuite) TestExplain(c *C) {
 				"TableScan_16 HashAgg_15  cop table:b, range:(-inf,+inf), keep order:false 8000",
 				"HashAgg_15  TableScan_16 cop type:complete, group by:b.c2, funcs:count(b.c2), firstrow(b.c2) 6400",
 				"TableReader_18 HashAgg_17  root data:HashAgg_15 6400",
-				"HashAgg_17 HashLeftJoin_13 TableReader_18 root type:final, group by:, funcs:count(col_0), firstrow(col_1) 6400",
+				"HashAgg_17 HashLeftJoin_13 TableReader_18 root type:final, group by:col_2, funcs:count(col_0), firstrow(col_1) 6400",
 				"HashLeftJoin_13 Projection_9 TableReader_22,HashAgg_17 root inner join, small:HashAgg_17, equal:[eq(a.c1, b.c2)] 8000",
 				"Projection_9  HashLeftJoin_13 root cast(join_agg_0) 8000",
 			},
@@ -139,9 +140,9 @@ func (s *testExplainSuite) TestExplain(c *C) {
 			"select * from t2 order by t2.c2 limit 0, 1",
 			[]string{
 				"TableScan_7 TopN_5  cop table:t2, range:(-inf,+inf), keep order:false 8000",
-				"TopN_5  TableScan_7 cop  1",
+				"TopN_5  TableScan_7 cop test.t2.c2:asc, offset:0, count:1, spill:false 1",
 				"TableReader_8 TopN_5  root data:TopN_5 1",
-				"TopN_5  TableReader_8 root  1",
+				"TopN_5  TableReader_8 root test.t2.c2:asc, offset:0, count:1, spill:false 1",
 			},
 		},
 		{
@@ -222,6 +223,16 @@ func (s *testExplainSuite) TestExplain(c *C) {
 				"Limit_6  TableReader_15 root offset:0, count:1 1",
 			},
 		},
+		{
+			"select c2, count(c3) from t1 group by c2 with rollup",
+			[]string{
+				"TableScan_5 HashAgg_4  cop table:t1, range:(-inf,+inf), keep order:false 8000",
+				"HashAgg_4  TableScan_5 cop type:complete, group by:test.t1.c2, funcs:count(test.t1.c3), firstrow(test.t1.c2) 6400",
+				"TableReader_7 HashAgg_6  root data:HashAgg_4 6400",
+				"HashAgg_6 Projection_3 TableReader_7 root type:final, group by:col_2 with rollup, funcs:count(col_0), firstrow(col_1) 6400",
+				"Projection_3  HashAgg_6 root test.t1.c2, 2_col_0 6400",
+			},
+		},
 	}
 	tk.MustExec("set @@session.tidb_opt_insubquery_unfold = 1")
 	tk.MustExec("set @@session.tidb_opt_agg_push_down = 1")
@@ -276,6 +287,53 @@ func (s *testExplainSuite) TestExplain(c *C) {
 		result.Check(testkit.Rows(tt.expect...))
 	}
 
+	spillTests := []struct {
+		sql    string
+		expect []string
+	}{
+		{
+			"select * from t2 order by c2",
+			[]string{
+				"TableScan_4   cop table:t2, range:(-inf,+inf), keep order:false 8000",
+				"TableReader_5 Sort_3  root data:TableScan_4 8000",
+				"Sort_3  TableReader_5 root t2.c2:asc, spill:true 8000",
+			},
+		},
+		{
+			"select * from t2 order by t2.c2 limit 1000, 1",
+			[]string{
+				"TableScan_7 TopN_5  cop table:t2, range:(-inf,+inf), keep order:false 8000",
+				"TopN_5  TableScan_7 cop test.t2.c2:asc, offset:0, count:1001, spill:false 1",
+				"TableReader_8 TopN_5  root data:TopN_5 1",
+				"TopN_5  TableReader_8 root test.t2.c2:asc, offset:1000, count:1, spill:true 1",
+			},
+		},
+		// The estimated memory usage depends on the width of the columns.
+		{
+			"select * from t2 order by t2.c2 limit 5, 1",
+			[]string{
+				"TableScan_7 TopN_5  cop table:t2, range:(-inf,+inf), keep order:false 8000",
+				"TopN_5  TableScan_7 cop test.t2.c2:asc, offset:0, count:6, spill:false 1",
+				"TableReader_8 TopN_5  root data:TopN_5 1",
+				"TopN_5  TableReader_8 root test.t2.c2:asc, offset:5, count:1, spill:false 1",
+			},
+		},
+		{
+			"select * from t4 order by t4.a limit 5, 1",
+			[]string{
+				"TableScan_7 TopN_5  cop table:t4, range:(-inf,+inf), keep order:false 8000",
+				"TopN_5  TableScan_7 cop test.t4.a:asc, offset:0, count:6, spill:false 1",
+				"TableReader_8 TopN_5  root data:TopN_5 1",
+				"TopN_5  TableReader_8 root test.t4.a:asc, offset:5, count:1, spill:true 1",
+			},
+		},
+	}
+	tk.MustExec("set @@session.tidb_mem_quota_sort = 1024")
+	for _, tt := range spillTests {
+		result := tk.MustQuery("explain " + tt.sql)
+		result.Check(testkit.Rows(tt.expect...))
+	}
+
 	dotFormatTests := []struct {
 		sql    string
 		expect string
@@ -0,0 +1,243 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// genColExpr is the generation expression of a generated column.
+type genColExpr struct {
+	col  *expression.Column
+	expr expression.Expression
+	// typeMatch indicates whether the expression can be substituted by the column everywhere, otherwise
+	// only the comparisons between the expression and constants are substituted, see substituteGenColCmp.
+	typeMatch bool
+}
+
+// gcSubstituter substitutes the expressions in the filters, projections and order by items with the
+// generated columns whose generation expressions are the same, e.g. `json_extract(doc, '$.a') = 1`
+// is rewritten to `gc = 1` if gc is generated as `json_extract(doc, '$.a')`, so the indexes on the
+// generated columns can be used.
+type gcSubstituter struct {
+}
+
+func (s *gcSubstituter) optimize(lp LogicalPlan, ctx context.Context, _ *idAllocator) (LogicalPlan, error) {
+	var exprs []genColExpr
+	collectGenColExprs(lp, &exprs)
+	if len(exprs) > 0 {
+		substituteGenCols(lp, exprs, ctx)
+	}
+	return lp, nil
+}
+
+func collectGenColExprs(p Plan, exprs *[]genColExpr) {
+	if ds, ok := p.(*DataSource); ok {
+		*exprs = append(*exprs, ds.genColExprs...)
+	}
+	for _, child := range p.Children() {
+		collectGenColExprs(child, exprs)
+	}
+}
+
+func substituteGenCols(p Plan, exprs []genColExpr, ctx context.Context) {
+	for _, child := range p.Children() {
+		substituteGenCols(child, exprs, ctx)
+	}
+	switch x := p.(type) {
+	case *Selection:
+		schema := x.Children()[0].Schema()
+		for i, cond := range x.Conditions {
+			x.Conditions[i] = substituteGenCol(cond, exprs, schema, ctx)
+		}
+	case *Projection:
+		// The projection calculating the virtual generated columns must keep their generation expressions.
+		if x.calculateGenCols {
+			return
+		}
+		schema := x.Children()[0].Schema()
+		for i, expr := range x.Exprs {
+			x.Exprs[i] = substituteGenCol(expr, exprs, schema, ctx)
+		}
+	case *Sort:
+		schema := x.Children()[0].Schema()
+		proj, isProj := x.Children()[0].(*Projection)
+		for _, item := range x.ByItems {
+			newExpr := substituteGenCol(item.Expr, exprs, schema, ctx)
+			if newExpr == item.Expr && isProj {
+				newExpr = substituteProjectedGenCol(item.Expr, exprs, proj, ctx)
+			}
+			item.Expr = newExpr
+		}
+	}
+}
+
+// substituteProjectedGenCol handles the order by items that refer to the auxiliary columns of the projection
+// below the sort, e.g. `select a from t order by json_extract(doc, '$.a')`. If the item is the generation
+// expression of a generated column of the projection's child, the projection outputs the column and the item
+// is substituted with it.
+func substituteProjectedGenCol(expr expression.Expression, exprs []genColExpr, proj *Projection, ctx context.Context) expression.Expression {
+	if _, ok := expr.(*expression.ScalarFunction); !ok {
+		return expr
+	}
+	childSchema := proj.Children()[0].Schema()
+	childExpr := expression.ColumnSubstitute(expr, proj.Schema(), proj.Exprs)
+	col, ok := substituteGenCol(childExpr, exprs, childSchema, ctx).(*expression.Column)
+	if !ok {
+		return expr
+	}
+	for i, projExpr := range proj.Exprs {
+		if projCol, ok := projExpr.(*expression.Column); ok && projCol.Equal(col, ctx) {
+			return proj.Schema().Columns[i].Clone()
+		}
+	}
+	proj.Exprs = append(proj.Exprs, col)
+	newCol := &expression.Column{
+		FromID:   proj.id,
+		Position: proj.schema.Len() + 1,
+		TblName:  col.TblName,
+		ColName:  col.ColName,
+		RetType:  col.GetType(),
+	}
+	proj.schema.Append(newCol)
+	return newCol.Clone()
+}
+
+// substituteGenCol substitutes the sub-expressions of expr with the generated columns in the schema.
+func substituteGenCol(expr expression.Expression, exprs []genColExpr, schema *expression.Schema, ctx context.Context) expression.Expression {
+	sf, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return expr
+	}
+	for _, gc := range exprs {
+		if gc.typeMatch && schema.Contains(gc.col) && sf.Equal(gc.expr, ctx) {
+			return gc.col.Clone()
+		}
+	}
+	if newFunc := substituteGenColCmp(sf, exprs, schema, ctx); newFunc != nil {
+		return newFunc
+	}
+	if sf.FuncName.L == ast.Cast {
+		newFunc := sf.Clone().(*expression.ScalarFunction)
+		newFunc.GetArgs()[0] = substituteGenCol(newFunc.GetArgs()[0], exprs, schema, ctx)
+		return newFunc
+	}
+	var changed bool
+	newArgs := make([]expression.Expression, 0, len(sf.GetArgs()))
+	for _, arg := range sf.GetArgs() {
+		newArg := substituteGenCol(arg, exprs, schema, ctx)
+		changed = changed || newArg != arg
+		newArgs = append(newArgs, newArg)
+	}
+	if !changed {
+		return expr
+	}
+	return expression.NewFunctionInternal(sf.GetCtx(), sf.FuncName.L, sf.RetType, newArgs...)
+}
+
+// symmetricCmp maps `a op b` to `b op' a`.
+var symmetricCmp = map[string]string{
+	ast.EQ: ast.EQ,
+	ast.NE: ast.NE,
+	ast.LT: ast.GT,
+	ast.LE: ast.GE,
+	ast.GT: ast.LT,
+	ast.GE: ast.LE,
+}
+
+// substituteGenColCmp substitutes the comparison between the generation expression and numeric constants when the
+// numeric generated column doesn't match the type of the expression, e.g. `json_extract(doc, '$.a') = 5` is
+// rewritten to `gc = 5` if gc is an INT column generated as `json_extract(doc, '$.a')`. The constants are compared
+// with the value of the expression cast to the column type, which is the implicit cast of the comparison.
+// It returns nil if the comparison can't be substituted.
+func substituteGenColCmp(sf *expression.ScalarFunction, exprs []genColExpr, schema *expression.Schema, ctx context.Context) expression.Expression {
+	funcName := sf.FuncName.L
+	args := sf.GetArgs()
+	if op, ok := symmetricCmp[funcName]; ok {
+		if _, isCon := numericConstant(args[0]); isCon {
+			funcName, args = op, []expression.Expression{args[1], args[0]}
+		}
+	} else if funcName != ast.In {
+		return nil
+	}
+	for _, gc := range exprs {
+		if gc.typeMatch || !schema.Contains(gc.col) || !uncast(args[0]).Equal(gc.expr, ctx) {
+			continue
+		}
+		switch gc.col.RetType.EvalType() {
+		case types.ETInt, types.ETReal, types.ETDecimal:
+		default:
+			continue
+		}
+		newArgs := []expression.Expression{gc.col.Clone()}
+		for _, arg := range args[1:] {
+			con, ok := numericConstant(arg)
+			if !ok {
+				return nil
+			}
+			newArgs = append(newArgs, con)
+		}
+		newFunc, err := expression.NewFunction(ctx, funcName, sf.RetType, newArgs...)
+		if err != nil {
+			return nil
+		}
+		return newFunc
+	}
+	return nil
+}
+
+// uncast returns the argument of the implicit cast added for the comparison.
+func uncast(expr expression.Expression) expression.Expression {
+	if sf, ok := expr.(*expression.ScalarFunction); ok && sf.FuncName.L == ast.Cast {
+		return sf.GetArgs()[0]
+	}
+	return expr
+}
+
+// numericConstant returns the numeric constant of expr, which may be cast for the comparison.
+func numericConstant(expr expression.Expression) (*expression.Constant, bool) {
+	con, ok := uncast(expr).(*expression.Constant)
+	if !ok {
+		return nil, false
+	}
+	switch con.GetType().EvalType() {
+	case types.ETInt, types.ETReal, types.ETDecimal:
+		return con, true
+	}
+	return nil, false
+}
+
+// genColTypeMatch checks whether the generation expression can be substituted by the generated
+// column, the value of the column is cast from the value of the expression, so they must be
+// compared in the same way and the cast must not lose precision.
+func genColTypeMatch(exprTp, colTp *types.FieldType) bool {
+	evalType := exprTp.EvalType()
+	if evalType != colTp.EvalType() {
+		return false
+	}
+	switch evalType {
+	case types.ETString:
+		return charset.GetCollator(exprTp.Collate) == charset.GetCollator(colTp.Collate)
+	case types.ETReal, types.ETDecimal:
+		if colTp.Decimal == types.UnspecifiedLength {
+			return true
+		}
+		return exprTp.Decimal != types.UnspecifiedLength && exprTp.Decimal <= colTp.Decimal
+	}
+	return true
+}
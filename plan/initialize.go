@@ -27,6 +27,8 @@ const (
 	TypeProj = "Projection"
 	// TypeAgg is the type of Aggregation.
 	TypeAgg = "Aggregation"
+	// TypeWindow is the type of Window.
+	TypeWindow = "Window"
 	// TypeStreamAgg is the type of StreamAgg.
 	TypeStreamAgg = "StreamAgg"
 	// TypeHashAgg is the type of HashAgg.
@@ -39,6 +41,10 @@ const (
 	TypeJoin = "Join"
 	// TypeUnion is the type of Union.
 	TypeUnion = "Union"
+	// TypeRecursiveCTE is the type of RecursiveCTE.
+	TypeRecursiveCTE = "RecursiveCTE"
+	// TypeCTEWorkTable is the type of CTEWorkTable.
+	TypeCTEWorkTable = "CTEWorkTable"
 	// TypeTableScan is the type of TableScan.
 	TypeTableScan = "TableScan"
 	// TypeMemTableScan is the type of TableScan.
@@ -93,6 +99,12 @@ func (p LogicalAggregation) init(allocator *idAllocator, ctx context.Context) *L
 	return &p
 }
 
+func (p LogicalWindow) init(allocator *idAllocator, ctx context.Context) *LogicalWindow {
+	p.basePlan = newBasePlan(TypeWindow, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	return &p
+}
+
 func (p LogicalJoin) init(allocator *idAllocator, ctx context.Context) *LogicalJoin {
 	p.basePlan = newBasePlan(TypeJoin, allocator, ctx, &p)
 	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
@@ -160,6 +172,20 @@ func (p TableDual) init(allocator *idAllocator, ctx context.Context) *TableDual
 	return &p
 }
 
+func (p RecursiveCTE) init(allocator *idAllocator, ctx context.Context) *RecursiveCTE {
+	p.basePlan = newBasePlan(TypeRecursiveCTE, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
+func (p CTEWorkTable) init(allocator *idAllocator, ctx context.Context) *CTEWorkTable {
+	p.basePlan = newBasePlan(TypeCTEWorkTable, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p Exists) init(allocator *idAllocator, ctx context.Context) *Exists {
 	p.basePlan = newBasePlan(TypeExists, allocator, ctx, &p)
 	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
@@ -259,6 +285,12 @@ func (p PhysicalAggregation) init(allocator *idAllocator, ctx context.Context) *
 	return &p
 }
 
+func (p PhysicalWindow) init(allocator *idAllocator, ctx context.Context) *PhysicalWindow {
+	p.basePlan = newBasePlan(TypeWindow, allocator, ctx, &p)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p PhysicalApply) init(allocator *idAllocator, ctx context.Context) *PhysicalApply {
 	p.basePlan = newBasePlan(TypeApply, allocator, ctx, &p)
 	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
@@ -21,6 +21,7 @@ import (
 	"github.com/cznic/mathutil"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/expression/aggregation"
 	"github.com/pingcap/tidb/infoschema"
@@ -59,7 +60,7 @@ func (p *LogicalAggregation) collectGroupByColumns() {
 	}
 }
 
-func (b *planBuilder) buildAggregation(p LogicalPlan, aggFuncList []*ast.AggregateFuncExpr, gbyItems []expression.Expression) (LogicalPlan, map[int]int) {
+func (b *planBuilder) buildAggregation(p LogicalPlan, aggFuncList []*ast.AggregateFuncExpr, gbyItems []expression.Expression, rollup bool) (LogicalPlan, map[int]int) {
 	b.optFlag = b.optFlag | flagBuildKeyInfo
 	b.optFlag = b.optFlag | flagAggregationOptimize
 
@@ -79,6 +80,12 @@ func (b *planBuilder) buildAggregation(p LogicalPlan, aggFuncList []*ast.Aggrega
 			newArgList = append(newArgList, newArg)
 		}
 		newFunc := aggregation.NewAggFunction(aggFunc.F, newArgList, aggFunc.Distinct)
+		if newFunc.GetName() == ast.AggFuncGrouping {
+			if err := b.checkGroupingArgs(newArgList, gbyItems, rollup); err != nil {
+				b.err = errors.Trace(err)
+				return nil, nil
+			}
+		}
 		combined := false
 		for j, oldFunc := range agg.AggFuncs {
 			if oldFunc.Equal(newFunc, b.ctx) {
@@ -106,11 +113,42 @@ func (b *planBuilder) buildAggregation(p LogicalPlan, aggFuncList []*ast.Aggrega
 	}
 	addChild(agg, p)
 	agg.GroupByItems = gbyItems
+	if rollup {
+		// GROUP BY a, b WITH ROLLUP groups by (a, b), (a) and ().
+		for i := len(gbyItems); i >= 0; i-- {
+			set := make([]int, 0, i)
+			for j := 0; j < i; j++ {
+				set = append(set, j)
+			}
+			agg.GroupingSets = append(agg.GroupingSets, set)
+		}
+		agg.buildGroupingOutputs()
+	}
 	agg.SetSchema(schema)
 	agg.collectGroupByColumns()
 	return agg, aggIndexMap
 }
 
+// checkGroupingArgs checks that GROUPING is used with ROLLUP and all of its arguments are group-by items.
+func (b *planBuilder) checkGroupingArgs(args, gbyItems []expression.Expression, rollup bool) error {
+	if !rollup {
+		return ErrInvalidGroupFuncUse
+	}
+	for i, arg := range args {
+		found := false
+		for _, item := range gbyItems {
+			if item.Equal(arg, b.ctx) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrFieldInGroupingNotGroupBy.GenByArgs(i + 1)
+		}
+	}
+	return nil
+}
+
 func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 	switch x := node.(type) {
 	case *ast.Join:
@@ -123,7 +161,13 @@ func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 		case *ast.UnionStmt:
 			p = b.buildUnion(v)
 		case *ast.TableName:
-			p = b.buildDataSource(v)
+			if v.CTE != nil {
+				p = b.buildCTE(v.CTE)
+			} else if v.TableInfo.IsView() {
+				p = b.buildView(v)
+			} else {
+				p = b.buildDataSource(v)
+			}
 		default:
 			b.err = ErrUnsupportedType.Gen("unsupported table source type %T", v)
 			return nil
@@ -548,11 +592,44 @@ func (b *planBuilder) buildUnion(union *ast.UnionStmt) LogicalPlan {
 			return nil
 		}
 	}
+	b.buildUnionSchema(u)
+	if b.err != nil {
+		return nil
+	}
+	var p LogicalPlan = u
+	if union.Distinct {
+		p = b.buildDistinct(u, u.Schema().Len())
+	}
+	if union.OrderBy != nil {
+		p = b.buildSort(p, union.OrderBy.Items, nil)
+	}
+	if union.Limit != nil {
+		p = b.buildLimit(p, union.Limit)
+	}
+	return p
+}
+
+// buildUnionAll builds an Union plan which concatenates the results of children.
+func (b *planBuilder) buildUnionAll(children []LogicalPlan) LogicalPlan {
+	if len(children) == 1 {
+		return children[0]
+	}
+	u := Union{}.init(b.allocator, b.ctx)
+	u.children = make([]Plan, 0, len(children))
+	for _, child := range children {
+		u.children = append(u.children, child)
+	}
+	b.buildUnionSchema(u)
+	return u
+}
+
+// buildUnionSchema adds a projection for each child of the Union if needed, and infers the schema of the Union.
+func (b *planBuilder) buildUnionSchema(u *Union) {
 	firstSchema := u.children[0].Schema().Clone()
 	for i, sel := range u.children {
 		if firstSchema.Len() != sel.Schema().Len() {
 			b.err = errors.New("The used SELECT statements have a different number of columns")
-			return nil
+			return
 		}
 		if _, ok := sel.(*Projection); !ok {
 			b.optFlag |= flagEliminateProjection
@@ -590,16 +667,286 @@ func (b *planBuilder) buildUnion(union *ast.UnionStmt) LogicalPlan {
 	}
 
 	u.SetSchema(firstSchema)
-	var p LogicalPlan = u
-	if union.Distinct {
-		p = b.buildDistinct(u, u.Schema().Len())
+}
+
+// recursiveCTEInfo is the building state of a recursive common table expression.
+type recursiveCTEInfo struct {
+	plan *RecursiveCTE
+	// seeds are the plans of the non-recursive selects.
+	seeds []LogicalPlan
+	// seed is the union of seeds, it is built when the work table is referenced for the first time.
+	seed LogicalPlan
+	// refs is the number of references to the work table.
+	refs int
+}
+
+// buildCTE builds the plan of a common table expression. The query is built every time it's referenced, like
+// a derived table.
+func (b *planBuilder) buildCTE(cte *ast.CommonTableExpression) LogicalPlan {
+	if info, ok := b.recursiveCTEs[cte]; ok {
+		return b.buildCTEWorkTable(cte, info)
+	}
+	var p LogicalPlan
+	if cte.IsRecursive {
+		p = b.buildRecursiveCTE(cte)
+	} else {
+		p = b.buildResultSetNode(cte.Query)
 	}
+	if b.err != nil {
+		return nil
+	}
+	for i, col := range p.Schema().Columns {
+		col.TblName = cte.Name
+		col.DBName = model.NewCIStr("")
+		if len(cte.ColNameList) > 0 {
+			col.ColName = cte.ColNameList[i]
+		}
+	}
+	return p
+}
+
+// buildView expands the view by building the SELECT statement stored in its meta data.
+func (b *planBuilder) buildView(tn *ast.TableName) LogicalPlan {
+	viewInfo := tn.TableInfo.View
+	fullName := tn.Schema.L + "." + tn.Name.L
+	for _, name := range b.buildingViews {
+		if name == fullName {
+			b.err = ErrViewRecursive.GenByArgs(tn.Schema.O, tn.Name.O)
+			return nil
+		}
+	}
+	b.buildingViews = append(b.buildingViews, fullName)
+	defer func() {
+		b.buildingViews = b.buildingViews[:len(b.buildingViews)-1]
+	}()
+
+	charset, collation := b.ctx.GetSessionVars().GetCharsetInfo()
+	stmt, err := parser.New().ParseOneStmt(viewInfo.SelectStmt, charset, collation)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	// The table names in the SELECT statement are resolved in the database of the view.
+	resolver := nameResolver{Info: b.is, Ctx: b.ctx, DefaultSchema: tn.Schema}
+	stmt.Accept(&resolver)
+	if resolver.Err != nil {
+		b.err = ErrViewInvalid.GenByArgs(tn.Schema.O, tn.Name.O)
+		return nil
+	}
+
+	// The SELECT statement can't refer to the columns of the outer query.
+	outerSchemas, visitInfoLen := b.outerSchemas, len(b.visitInfo)
+	b.outerSchemas = nil
+	p := b.buildResultSetNode(stmt.(ast.ResultSetNode))
+	b.outerSchemas = outerSchemas
+	if b.err != nil {
+		return nil
+	}
+	// A view with SQL SECURITY DEFINER is accessed with the privileges of its definer, so the privileges
+	// needed by the SELECT statement are checked for the definer, and the current user only needs the
+	// SELECT privilege on the view itself.
+	if viewInfo.Security != "INVOKER" && viewInfo.Definer != nil {
+		for i := visitInfoLen; i < len(b.visitInfo); i++ {
+			if b.visitInfo[i].user == nil {
+				b.visitInfo[i].user = viewInfo.Definer
+			}
+		}
+	}
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, tn.Schema.L, tn.Name.L, "")
+
+	selectCols := viewInfo.SelectCols
+	if len(selectCols) == 0 {
+		for _, col := range tn.TableInfo.Columns {
+			selectCols = append(selectCols, col.Name)
+		}
+	}
+	if !schemaHasColumnNames(p.Schema(), selectCols) {
+		return b.projectViewColumns(tn, p, selectCols)
+	}
+	for i, col := range p.Schema().Columns {
+		col.TblName = tn.Name
+		col.DBName = tn.Schema
+		col.ColName = tn.TableInfo.Columns[i].Name
+	}
+	return p
+}
+
+// schemaHasColumnNames checks whether the columns of the schema have the names in order.
+func schemaHasColumnNames(schema *expression.Schema, names []model.CIStr) bool {
+	if schema.Len() != len(names) {
+		return false
+	}
+	for i, col := range schema.Columns {
+		if col.ColName.L != names[i].L {
+			return false
+		}
+	}
+	return true
+}
+
+// projectViewColumns projects the view columns from the result of the SELECT statement by the names of the
+// selected columns, because the result may have different columns after the tables are altered, e.g. the
+// `select *` of a table with a new column.
+func (b *planBuilder) projectViewColumns(tn *ast.TableName, p LogicalPlan, selectCols []model.CIStr) LogicalPlan {
+	proj := Projection{Exprs: make([]expression.Expression, 0, len(selectCols))}.init(b.allocator, b.ctx)
+	schema := expression.NewSchema(make([]*expression.Column, 0, len(selectCols))...)
+	for i, name := range selectCols {
+		var selected *expression.Column
+		for _, col := range p.Schema().Columns {
+			if col.ColName.L != name.L {
+				continue
+			}
+			// The selected column is ambiguous.
+			if selected != nil {
+				b.err = ErrViewInvalid.GenByArgs(tn.Schema.O, tn.Name.O)
+				return nil
+			}
+			selected = col
+		}
+		if selected == nil {
+			b.err = ErrViewInvalid.GenByArgs(tn.Schema.O, tn.Name.O)
+			return nil
+		}
+		proj.Exprs = append(proj.Exprs, selected.Clone())
+		schema.Append(&expression.Column{
+			FromID:   proj.id,
+			DBName:   tn.Schema,
+			TblName:  tn.Name,
+			ColName:  tn.TableInfo.Columns[i].Name,
+			RetType:  selected.RetType,
+			Position: i,
+		})
+	}
+	proj.SetSchema(schema)
+	proj.SetChildren(p)
+	p.SetParents(proj)
+	return proj
+}
+
+// buildViewColumns builds the SELECT statement of a CREATE VIEW statement and returns the columns of the view
+// and the names of the selected columns.
+func (b *planBuilder) buildViewColumns(v *ast.CreateViewStmt) ([]*model.ColumnInfo, []model.CIStr) {
+	// A view referring to itself through other views is not allowed.
+	b.buildingViews = append(b.buildingViews, v.ViewName.Schema.L+"."+v.ViewName.Name.L)
+	p := b.buildResultSetNode(v.Select)
+	b.buildingViews = b.buildingViews[:0]
+	if b.err != nil {
+		return nil, nil
+	}
+	schema := p.Schema()
+	if len(v.Cols) > 0 && len(v.Cols) != schema.Len() {
+		b.err = ErrViewWrongList
+		return nil, nil
+	}
+	cols := make([]*model.ColumnInfo, 0, schema.Len())
+	selectCols := make([]model.CIStr, 0, schema.Len())
+	names := make(map[string]struct{}, schema.Len())
+	for i, col := range schema.Columns {
+		name := col.ColName
+		if len(v.Cols) > 0 {
+			name = v.Cols[i]
+		}
+		if _, ok := names[name.L]; ok {
+			b.err = ErrDupFieldName.GenByArgs(name.O)
+			return nil, nil
+		}
+		names[name.L] = struct{}{}
+		ft := *col.RetType
+		ft.Flag &^= mysql.PriKeyFlag | mysql.UniqueKeyFlag | mysql.MultipleKeyFlag | mysql.AutoIncrementFlag | mysql.OnUpdateNowFlag
+		cols = append(cols, &model.ColumnInfo{Name: name, FieldType: ft})
+		selectCols = append(selectCols, col.ColName)
+	}
+	return cols, selectCols
+}
+
+func (b *planBuilder) buildRecursiveCTE(cte *ast.CommonTableExpression) LogicalPlan {
+	union := cte.Query.(*ast.UnionStmt)
+	info := &recursiveCTEInfo{plan: RecursiveCTE{Distinct: union.Distinct}.init(b.allocator, b.ctx)}
+	if b.recursiveCTEs == nil {
+		b.recursiveCTEs = make(map[*ast.CommonTableExpression]*recursiveCTEInfo)
+	}
+	b.recursiveCTEs[cte] = info
+	defer delete(b.recursiveCTEs, cte)
+
+	var recursives []LogicalPlan
+	for _, sel := range union.SelectList.Selects {
+		refs := info.refs
+		p := b.buildSelect(sel)
+		if b.err != nil {
+			return nil
+		}
+		switch {
+		case info.refs == refs && len(recursives) == 0:
+			info.seeds = append(info.seeds, p)
+		case info.refs == refs:
+			b.err = ErrCTERecursiveRequiresNonRecursiveFirst.GenByArgs(cte.Name.O)
+		case info.refs > refs+1:
+			b.err = ErrCTERecursiveRequiresSingleReference.GenByArgs(cte.Name.O)
+		case b.detectSelectAgg(sel):
+			b.err = ErrCTERecursiveForbidsAggregation.GenByArgs(cte.Name.O)
+		default:
+			recursives = append(recursives, p)
+		}
+		if b.err != nil {
+			return nil
+		}
+	}
+	if len(recursives) == 0 {
+		b.err = ErrCTERecursiveRequiresUnion.GenByArgs(cte.Name.O)
+		return nil
+	}
+	recursive := b.buildUnionAll(recursives)
+	if b.err != nil {
+		return nil
+	}
+	if recursive.Schema().Len() != info.seed.Schema().Len() {
+		b.err = errors.New("The used SELECT statements have a different number of columns")
+		return nil
+	}
+	p := info.plan
+	addChild(p, info.seed)
+	addChild(p, recursive)
+	// The result types are decided by the seed part, the rows of the recursive part are converted by the executor.
+	schema := info.seed.Schema().Clone()
+	for _, col := range schema.Columns {
+		col.FromID = p.id
+		col.DBName = model.NewCIStr("")
+	}
+	p.SetSchema(schema)
+	var lp LogicalPlan = p
 	if union.OrderBy != nil {
-		p = b.buildSort(p, union.OrderBy.Items, nil)
+		lp = b.buildSort(lp, union.OrderBy.Items, nil)
 	}
 	if union.Limit != nil {
-		p = b.buildLimit(p, union.Limit)
+		lp = b.buildLimit(lp, union.Limit)
 	}
+	return lp
+}
+
+// buildCTEWorkTable builds the reference of a recursive common table expression in its recursive part.
+func (b *planBuilder) buildCTEWorkTable(cte *ast.CommonTableExpression, info *recursiveCTEInfo) LogicalPlan {
+	if len(info.seeds) == 0 {
+		b.err = ErrCTERecursiveRequiresNonRecursiveFirst.GenByArgs(cte.Name.O)
+		return nil
+	}
+	if info.seed == nil {
+		info.seed = b.buildUnionAll(info.seeds)
+		if b.err != nil {
+			return nil
+		}
+	}
+	info.refs++
+	p := CTEWorkTable{CTEID: info.plan.id}.init(b.allocator, b.ctx)
+	schema := info.seed.Schema().Clone()
+	for i, col := range schema.Columns {
+		col.FromID = p.id
+		col.TblName = cte.Name
+		col.DBName = model.NewCIStr("")
+		if len(cte.ColNameList) > 0 {
+			col.ColName = cte.ColNameList[i]
+		}
+	}
+	p.SetSchema(schema)
 	return p
 }
 
@@ -640,6 +987,231 @@ func (b *planBuilder) buildSort(p LogicalPlan, byItems []*ast.ByItem, aggMapper
 	return sort
 }
 
+// unnamedWindow is the window name used in the error messages, because named windows are not supported yet.
+const unnamedWindow = "<unnamed window>"
+
+// buildWindowFunctions builds a LogicalWindow for every window function in the select fields, and records the index
+// of its result column in b.windowMapper.
+func (b *planBuilder) buildWindowFunctions(p LogicalPlan, sel *ast.SelectStmt, aggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
+	extractor := &WindowFuncExtractor{}
+	for _, field := range sel.Fields.Fields {
+		if !ast.HasWindowFlag(field.Expr) {
+			continue
+		}
+		field.Expr.Accept(extractor)
+		if extractor.err != nil {
+			b.err = errors.Trace(extractor.err)
+			return nil
+		}
+	}
+	if len(extractor.WindowFuncs) == 0 {
+		return p
+	}
+	if sel.Having != nil {
+		b.err = ErrNotSupportedYet.GenByArgs("HAVING clause with window functions")
+		return nil
+	}
+	if b.windowMapper == nil {
+		b.windowMapper = make(map[*ast.WindowFuncExpr]int)
+	}
+	for _, windowFunc := range extractor.WindowFuncs {
+		p = b.buildWindowFunction(p, windowFunc, aggMapper)
+		if b.err != nil {
+			return nil
+		}
+		b.windowMapper[windowFunc] = p.Schema().Len() - 1
+	}
+	return p
+}
+
+// buildWindowFunction builds a LogicalWindow whose child is sorted by the partition by items and the order by items,
+// so the rows of a partition are adjacent and ordered when the window function is evaluated.
+func (b *planBuilder) buildWindowFunction(p LogicalPlan, windowFunc *ast.WindowFuncExpr, aggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
+	args := make([]expression.Expression, 0, len(windowFunc.Args))
+	for _, arg := range windowFunc.Args {
+		newArg, np, err := b.rewrite(arg, p, aggMapper, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		p = np
+		args = append(args, newArg)
+	}
+	if err := checkWindowFuncArgs(windowFunc.F, args); err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	var (
+		partitionBy []expression.Expression
+		orderBy     []*ByItems
+		sortItems   []*ByItems
+	)
+	spec := &windowFunc.Spec
+	if spec.PartitionBy != nil {
+		for _, item := range spec.PartitionBy.Items {
+			expr, np, err := b.rewrite(item.Expr, p, aggMapper, true)
+			if err != nil {
+				b.err = errors.Trace(err)
+				return nil
+			}
+			p = np
+			partitionBy = append(partitionBy, expr)
+			sortItems = append(sortItems, &ByItems{Expr: expr, Desc: item.Desc})
+		}
+	}
+	if spec.OrderBy != nil {
+		for _, item := range spec.OrderBy.Items {
+			expr, np, err := b.rewrite(item.Expr, p, aggMapper, true)
+			if err != nil {
+				b.err = errors.Trace(err)
+				return nil
+			}
+			p = np
+			orderBy = append(orderBy, &ByItems{Expr: expr, Desc: item.Desc})
+			sortItems = append(sortItems, &ByItems{Expr: expr, Desc: item.Desc})
+		}
+	}
+	frame, err := buildWindowFrame(spec.Frame, orderBy)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	if len(sortItems) > 0 {
+		sort := Sort{ByItems: sortItems}.init(b.allocator, b.ctx)
+		addChild(sort, p)
+		sort.SetSchema(p.Schema().Clone())
+		p = sort
+	}
+	desc := aggregation.NewWindowFuncDesc(windowFunc.F, args, windowFunc.Distinct)
+	window := LogicalWindow{
+		WindowFuncDesc: desc,
+		PartitionBy:    partitionBy,
+		OrderBy:        orderBy,
+		Frame:          frame,
+	}.init(b.allocator, b.ctx)
+	schema := p.Schema().Clone()
+	schema.Append(&expression.Column{
+		FromID:      window.id,
+		ColName:     model.NewCIStr(fmt.Sprintf("%d_window_col", window.id)),
+		Position:    schema.Len(),
+		IsAggOrSubq: true,
+		RetType:     desc.RetTp,
+	})
+	addChild(window, p)
+	window.SetSchema(schema)
+	return window
+}
+
+// checkWindowFuncArgs checks the argument count of the window function, and the offset of LAG and LEAD,
+// which must be a non-negative integer constant.
+func checkWindowFuncArgs(name string, args []expression.Expression) error {
+	name = strings.ToLower(name)
+	var minArgs, maxArgs int
+	switch name {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank, ast.WindowFuncDenseRank:
+		minArgs, maxArgs = 0, 0
+	case ast.WindowFuncLag, ast.WindowFuncLead:
+		minArgs, maxArgs = 1, 3
+	case ast.WindowFuncFirstValue, ast.WindowFuncLastValue:
+		minArgs, maxArgs = 1, 1
+	default:
+		return nil
+	}
+	if len(args) < minArgs || len(args) > maxArgs {
+		return expression.ErrIncorrectParameterCount.GenByArgs(name)
+	}
+	if len(args) > 1 {
+		con, ok := args[1].(*expression.Constant)
+		if !ok {
+			return ErrWrongArguments.Gen("Incorrect arguments to %s", name)
+		}
+		switch con.Value.Kind() {
+		case types.KindInt64:
+			if con.Value.GetInt64() < 0 {
+				return ErrWrongArguments.Gen("Incorrect arguments to %s", name)
+			}
+		case types.KindUint64:
+		default:
+			return ErrWrongArguments.Gen("Incorrect arguments to %s", name)
+		}
+	}
+	return nil
+}
+
+// buildWindowFrame builds the frame of a window function. When the frame clause is omitted, the frame is
+// "RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW" if the window is ordered, otherwise the frame is the whole partition.
+func buildWindowFrame(clause *ast.FrameClause, orderBy []*ByItems) (*WindowFrame, error) {
+	if clause == nil {
+		if len(orderBy) > 0 {
+			return &WindowFrame{
+				Type:  ast.Ranges,
+				Start: &FrameBound{Type: ast.Preceding, UnBounded: true},
+				End:   &FrameBound{Type: ast.CurrentRow},
+			}, nil
+		}
+		return &WindowFrame{
+			Type:  ast.Rows,
+			Start: &FrameBound{Type: ast.Preceding, UnBounded: true},
+			End:   &FrameBound{Type: ast.Following, UnBounded: true},
+		}, nil
+	}
+	if clause.Start.UnBounded && clause.Start.Type == ast.Following {
+		return nil, ErrWindowFrameStartIllegal.GenByArgs(unnamedWindow)
+	}
+	if clause.End.UnBounded && clause.End.Type == ast.Preceding {
+		return nil, ErrWindowFrameEndIllegal.GenByArgs(unnamedWindow)
+	}
+	frame := &WindowFrame{Type: clause.Type}
+	var err error
+	frame.Start, err = buildFrameBound(clause.Type, &clause.Start, orderBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	frame.End, err = buildFrameBound(clause.Type, &clause.End, orderBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return frame, nil
+}
+
+func buildFrameBound(tp ast.FrameType, bound *ast.FrameBound, orderBy []*ByItems) (*FrameBound, error) {
+	fb := &FrameBound{Type: bound.Type, UnBounded: bound.UnBounded}
+	if bound.UnBounded || bound.Type == ast.CurrentRow {
+		return fb, nil
+	}
+	v, ok := bound.Expr.(*ast.ValueExpr)
+	if !ok {
+		return nil, ErrWindowFrameIllegal.GenByArgs(unnamedWindow)
+	}
+	fb.Offset = v.Datum
+	switch fb.Offset.Kind() {
+	case types.KindInt64:
+		if fb.Offset.GetInt64() < 0 {
+			return nil, ErrWindowFrameIllegal.GenByArgs(unnamedWindow)
+		}
+	case types.KindUint64:
+	case types.KindMysqlDecimal, types.KindFloat32, types.KindFloat64:
+		// Only the range frame accepts a non-integral offset.
+		if tp == ast.Rows {
+			return nil, ErrWindowFrameIllegal.GenByArgs(unnamedWindow)
+		}
+	default:
+		return nil, ErrWindowFrameIllegal.GenByArgs(unnamedWindow)
+	}
+	if tp == ast.Ranges {
+		// The offset is added to or subtracted from the order by item, so it must be a single numeric expression.
+		if len(orderBy) != 1 {
+			return nil, ErrWindowRangeFrameOrderType.GenByArgs(unnamedWindow)
+		}
+		switch orderBy[0].Expr.GetType().EvalType() {
+		case types.ETInt, types.ETReal, types.ETDecimal:
+		default:
+			return nil, ErrWindowRangeFrameOrderType.GenByArgs(unnamedWindow)
+		}
+	}
+	return fb, nil
+}
+
 // getUintForLimitOffset gets uint64 value for limit/offset.
 // For ordinary statement, limit/offset should be uint64 constant value.
 // For prepared statement, limit/offset is string. We should convert it to uint64.
@@ -751,6 +1323,7 @@ type havingAndOrderbyExprResolver struct {
 	p            LogicalPlan
 	selectFields []*ast.SelectField
 	aggMapper    map[*ast.AggregateFuncExpr]int
+	windowMapper map[*ast.WindowFuncExpr]int
 	colMapper    map[*ast.ColumnNameExpr]int
 	gbyItems     []*ast.ByItem
 	outerSchemas []*expression.Schema
@@ -766,6 +1339,9 @@ func (a *havingAndOrderbyExprResolver) Enter(n ast.Node) (node ast.Node, skipChi
 		// Enter a new context, skip it.
 		// For example: select sum(c) + c + exists(select c from t) from t;
 		return n, true
+	case *ast.WindowFuncExpr:
+		// The window function is evaluated before the projection, so its children are resolved as select fields.
+		return n, true
 	default:
 		a.inExpr = true
 	}
@@ -810,6 +1386,17 @@ func (a *havingAndOrderbyExprResolver) Leave(n ast.Node) (node ast.Node, ok bool
 			Expr:      v,
 			AsName:    model.NewCIStr(fmt.Sprintf("sel_agg_%d", len(a.selectFields))),
 		})
+	case *ast.WindowFuncExpr:
+		if !a.orderBy {
+			// The window function can't be used in having clause, which is reported when rewriting it.
+			break
+		}
+		a.windowMapper[v] = len(a.selectFields)
+		a.selectFields = append(a.selectFields, &ast.SelectField{
+			Auxiliary: true,
+			Expr:      v,
+			AsName:    model.NewCIStr(fmt.Sprintf("sel_window_%d", len(a.selectFields))),
+		})
 	case *ast.ColumnNameExpr:
 		resolveFieldsFirst := true
 		if a.inAggFunc || (a.orderBy && a.inExpr) {
@@ -875,13 +1462,15 @@ func (a *havingAndOrderbyExprResolver) Leave(n ast.Node) (node ast.Node, ok bool
 
 // resolveHavingAndOrderBy will process aggregate functions and resolve the columns that don't exist in select fields.
 // If we found some columns that are not in select fields, we will append it to select fields and update the colMapper.
+// The window functions in order by clause are appended to select fields too, the last returned map stores their indices.
 // When we rewrite the order by / having expression, we will find column in map at first.
 func (b *planBuilder) resolveHavingAndOrderBy(sel *ast.SelectStmt, p LogicalPlan) (
-	map[*ast.AggregateFuncExpr]int, map[*ast.AggregateFuncExpr]int) {
+	map[*ast.AggregateFuncExpr]int, map[*ast.AggregateFuncExpr]int, map[*ast.WindowFuncExpr]int) {
 	extractor := &havingAndOrderbyExprResolver{
 		p:            p,
 		selectFields: sel.Fields.Fields,
 		aggMapper:    make(map[*ast.AggregateFuncExpr]int),
+		windowMapper: make(map[*ast.WindowFuncExpr]int),
 		colMapper:    b.colMapper,
 		outerSchemas: b.outerSchemas,
 	}
@@ -893,7 +1482,7 @@ func (b *planBuilder) resolveHavingAndOrderBy(sel *ast.SelectStmt, p LogicalPlan
 		n, ok := sel.Having.Expr.Accept(extractor)
 		if !ok {
 			b.err = errors.Trace(extractor.err)
-			return nil, nil
+			return nil, nil, nil
 		}
 		sel.Having.Expr = n.(ast.ExprNode)
 	}
@@ -907,13 +1496,13 @@ func (b *planBuilder) resolveHavingAndOrderBy(sel *ast.SelectStmt, p LogicalPlan
 			n, ok := item.Expr.Accept(extractor)
 			if !ok {
 				b.err = errors.Trace(extractor.err)
-				return nil, nil
+				return nil, nil, nil
 			}
 			item.Expr = n.(ast.ExprNode)
 		}
 	}
 	sel.Fields.Fields = extractor.selectFields
-	return havingAggMapper, extractor.aggMapper
+	return havingAggMapper, extractor.aggMapper, extractor.windowMapper
 }
 
 func (b *planBuilder) extractAggFuncs(fields []*ast.SelectField) ([]*ast.AggregateFuncExpr, map[*ast.AggregateFuncExpr]int) {
@@ -1084,6 +1673,7 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		p                             LogicalPlan
 		aggFuncs                      []*ast.AggregateFuncExpr
 		havingMap, orderMap, totalMap map[*ast.AggregateFuncExpr]int
+		orderWindowMap                map[*ast.WindowFuncExpr]int
 		gbyCols                       []expression.Expression
 	)
 	if sel.From != nil {
@@ -1108,7 +1698,10 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 	// We must resolve having and order by clause before build projection,
 	// because when the query is "select a+1 as b from t having sum(b) < 0", we must replace sum(b) to sum(a+1),
 	// which only can be done before building projection and extracting Agg functions.
-	havingMap, orderMap = b.resolveHavingAndOrderBy(sel, p)
+	havingMap, orderMap, orderWindowMap = b.resolveHavingAndOrderBy(sel, p)
+	if b.err != nil {
+		return nil
+	}
 	if sel.Where != nil {
 		p = b.buildSelection(p, sel.Where, nil)
 		if b.err != nil {
@@ -1124,7 +1717,7 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 			return nil
 		}
 		var aggIndexMap map[int]int
-		p, aggIndexMap = b.buildAggregation(p, aggFuncs, gbyCols)
+		p, aggIndexMap = b.buildAggregation(p, aggFuncs, gbyCols, sel.GroupBy != nil && sel.GroupBy.Rollup)
 		for k, v := range totalMap {
 			totalMap[k] = aggIndexMap[v]
 		}
@@ -1132,6 +1725,10 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 			return nil
 		}
 	}
+	p = b.buildWindowFunctions(p, sel, totalMap)
+	if b.err != nil {
+		return nil
+	}
 	var oldLen int
 	p, oldLen = b.buildProjection(p, sel.Fields.Fields, totalMap)
 	if b.err != nil {
@@ -1150,6 +1747,10 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		}
 	}
 	if sel.OrderBy != nil {
+		// The window functions in order by clause have been evaluated as auxiliary select fields.
+		for windowFunc, index := range orderWindowMap {
+			b.windowMapper[windowFunc] = index
+		}
 		p = b.buildSort(p, sel.OrderBy.Items, orderMap)
 		if b.err != nil {
 			return nil
@@ -1209,12 +1810,16 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 	p := DataSource{
 		indexHints:     tn.IndexHints,
 		tableInfo:      tableInfo,
+		table:          tbl,
 		statisticTable: statisticTable,
 		DBName:         schemaName,
 		Columns:        make([]*model.ColumnInfo, 0, len(tableInfo.Columns)),
 		NeedColHandle:  b.needColHandle > 0,
 	}.init(b.allocator, b.ctx)
 	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, schemaName.L, tableInfo.Name.L, "")
+	if tableInfo.Partition != nil {
+		b.optFlag |= flagPartitionProcessor
+	}
 
 	var columns []*table.Column
 	if b.inUpdateStmt {
@@ -1242,6 +1847,9 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 	needUnionScan := b.ctx.Txn() != nil && !b.ctx.Txn().IsReadOnly()
 	if b.needColHandle == 0 && !needUnionScan {
 		p.SetSchema(schema)
+		if b.buildGenColExprs(p, columns); b.err != nil {
+			return nil
+		}
 		return b.projectVirtualColumns(p, columns)
 	}
 	if needUnionScan {
@@ -1278,9 +1886,35 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 		schema.TblID2Handle[tableInfo.ID] = []*expression.Column{pkCol}
 	}
 	p.SetSchema(schema)
+	if b.buildGenColExprs(p, columns); b.err != nil {
+		return nil
+	}
 	return b.projectVirtualColumns(p, columns)
 }
 
+// buildGenColExprs builds the generation expressions of the generated columns for the gcSubstituter.
+func (b *planBuilder) buildGenColExprs(ds *DataSource, columns []*table.Column) {
+	for i, column := range columns {
+		if !column.IsGenerated() {
+			continue
+		}
+		expr, _, err := b.rewrite(column.GeneratedExpr, ds, nil, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return
+		}
+		col := ds.Schema().Columns[i]
+		ds.genColExprs = append(ds.genColExprs, genColExpr{
+			col:       col,
+			expr:      expr,
+			typeMatch: genColTypeMatch(expr.GetType(), col.RetType),
+		})
+	}
+	if len(ds.genColExprs) > 0 {
+		b.optFlag |= flagGcSubstitute
+	}
+}
+
 // projectVirtualColumns is only for DataSource. If some table has virtual generated columns,
 // we add a projection on the original DataSource, and calculate those columns in the projection
 // so that plans above it can reference generated columns by their name.
@@ -1430,6 +2064,9 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 	b.inUpdateStmt = true
 	b.needColHandle++
 	sel := &ast.SelectStmt{Fields: &ast.FieldList{}, From: update.TableRefs, Where: update.Where, OrderBy: update.Order, Limit: update.Limit}
+	if !b.checkNoView(extractTableList(sel.From.TableRefs, nil), "UPDATE") {
+		return nil
+	}
 	p := b.buildResultSetNode(sel.From.TableRefs)
 	if b.err != nil {
 		return nil
@@ -1503,19 +2140,20 @@ func (b *planBuilder) buildUpdateLists(tableList []*ast.TableName, list []*ast.A
 			b.err = infoschema.ErrTableNotExists.GenByArgs(tn.DBInfo.Name.O, tableInfo.Name.O)
 			return nil, nil
 		}
-		for i, colInfo := range tableInfo.Columns {
-			if !colInfo.IsGenerated() {
+		// Only the public columns are updated here, the values of the non-public columns are filled by the table.
+		for _, col := range table.Cols() {
+			if !col.IsGenerated() {
 				continue
 			}
-			columnFullName := fmt.Sprintf("%s.%s.%s", tn.Schema.L, tn.Name.L, colInfo.Name.L)
+			columnFullName := fmt.Sprintf("%s.%s.%s", tn.Schema.L, tn.Name.L, col.Name.L)
 			if _, ok := modifyColumns[columnFullName]; ok {
-				b.err = ErrBadGeneratedColumn.GenByArgs(colInfo.Name.O, tableInfo.Name.O)
+				b.err = ErrBadGeneratedColumn.GenByArgs(col.Name.O, tableInfo.Name.O)
 				return nil, nil
 			}
 			for _, asName := range tableAsName[tableInfo] {
 				virtualAssignments = append(virtualAssignments, &ast.Assignment{
-					Column: &ast.ColumnName{Table: *asName, Name: colInfo.Name},
-					Expr:   table.Cols()[i].GeneratedExpr,
+					Column: &ast.ColumnName{Table: *asName, Name: col.Name},
+					Expr:   col.GeneratedExpr,
 				})
 			}
 		}
@@ -1591,6 +2229,9 @@ func extractTableAsNameForUpdate(p Plan, asNames map[*model.TableInfo][]*model.C
 func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 	b.needColHandle++
 	sel := &ast.SelectStmt{Fields: &ast.FieldList{}, From: delete.TableRefs, Where: delete.Where, OrderBy: delete.Order, Limit: delete.Limit}
+	if !b.checkNoView(extractTableList(sel.From.TableRefs, nil), "DELETE") {
+		return nil
+	}
 	p := b.buildResultSetNode(sel.From.TableRefs)
 	if b.err != nil {
 		return nil
@@ -1662,6 +2303,26 @@ func extractTableList(node ast.ResultSetNode, input []*ast.TableName) []*ast.Tab
 	return input
 }
 
+// checkNoView checks that there is no view in the tables of a statement which needs base tables, views are neither
+// updatable nor insertable, and they have no data to analyze or check.
+func (b *planBuilder) checkNoView(tables []*ast.TableName, stmtType string) bool {
+	for _, tn := range tables {
+		if tn.TableInfo == nil || !tn.TableInfo.IsView() {
+			continue
+		}
+		switch stmtType {
+		case "UPDATE", "DELETE":
+			b.err = ErrNonUpdatableTable.GenByArgs(tn.Name.O, stmtType)
+		case "INSERT", "LOAD":
+			b.err = ErrNonInsertableTable.GenByArgs(tn.Name.O, stmtType)
+		default:
+			b.err = ddl.ErrWrongObject.GenByArgs(tn.Schema.O, tn.Name.O, "BASE TABLE")
+		}
+		return false
+	}
+	return true
+}
+
 func appendVisitInfo(vi []visitInfo, priv mysql.PrivilegeType, db, tbl, col string) []visitInfo {
 	return append(vi, visitInfo{
 		privilege: priv,
@@ -14,6 +14,8 @@
 package plan
 
 import (
+	"fmt"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
@@ -21,12 +23,14 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/types"
 )
 
 var (
 	_ LogicalPlan = &LogicalJoin{}
 	_ LogicalPlan = &LogicalAggregation{}
+	_ LogicalPlan = &LogicalWindow{}
 	_ LogicalPlan = &Projection{}
 	_ LogicalPlan = &Selection{}
 	_ LogicalPlan = &LogicalApply{}
@@ -35,6 +39,8 @@ var (
 	_ LogicalPlan = &TableDual{}
 	_ LogicalPlan = &DataSource{}
 	_ LogicalPlan = &Union{}
+	_ LogicalPlan = &RecursiveCTE{}
+	_ LogicalPlan = &CTEWorkTable{}
 	_ LogicalPlan = &Sort{}
 	_ LogicalPlan = &Update{}
 	_ LogicalPlan = &Delete{}
@@ -180,6 +186,15 @@ type LogicalAggregation struct {
 
 	AggFuncs     []aggregation.Aggregation
 	GroupByItems []expression.Expression
+	// GroupingSets is not empty for GROUP BY ... WITH ROLLUP. Every grouping set is the indices of the
+	// group-by items it groups by, and the rows are aggregated by every grouping set separately.
+	// The first grouping set is the base grouping set which groups by all the group-by items.
+	GroupingSets [][]int
+	// groupingOutputs maps the aggregate functions whose results don't come from the aggregated rows in some
+	// grouping sets to their results in every grouping set, a nil result means the function is aggregated as usual.
+	// It is keyed by the functions rather than their positions, because the optimization rules rewrite the arguments
+	// and remove the unused functions.
+	groupingOutputs map[aggregation.Aggregation][]*types.Datum
 	// groupByCols stores the columns that are group-by items.
 	groupByCols []*expression.Column
 
@@ -187,6 +202,92 @@ type LogicalAggregation struct {
 	inputCount         float64 // inputCount is the input count of this plan.
 }
 
+// buildGroupingOutputs computes groupingOutputs after the aggregate functions and the grouping sets are built.
+// In the rows of a grouping set, the first rows of the rolled up group-by columns are NULL, and GROUPING returns
+// a bit mask of whether its arguments are rolled up.
+func (p *LogicalAggregation) buildGroupingOutputs() {
+	p.groupingOutputs = make(map[aggregation.Aggregation][]*types.Datum)
+	for s, items := range p.GroupingSets {
+		inSet := make([]bool, len(p.GroupByItems))
+		for _, i := range items {
+			inSet[i] = true
+		}
+		for _, fun := range p.AggFuncs {
+			var result *types.Datum
+			switch fun.GetName() {
+			case ast.AggFuncGrouping:
+				var mask int64
+				for _, arg := range fun.GetArgs() {
+					mask <<= 1
+					if idx := p.groupByItemIndex(arg); idx >= 0 && !inSet[idx] {
+						mask |= 1
+					}
+				}
+				d := types.NewIntDatum(mask)
+				result = &d
+			case ast.AggFuncFirstRow:
+				if p.isRolledUp(fun.GetArgs()[0], inSet) {
+					result = &types.Datum{}
+				}
+			}
+			if result == nil {
+				continue
+			}
+			if p.groupingOutputs[fun] == nil {
+				p.groupingOutputs[fun] = make([]*types.Datum, len(p.GroupingSets))
+			}
+			p.groupingOutputs[fun][s] = result
+		}
+	}
+}
+
+func (p *LogicalAggregation) groupByItemIndex(expr expression.Expression) int {
+	for i, item := range p.GroupByItems {
+		if item.Equal(expr, p.ctx) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isRolledUp checks whether expr should be NULL in the rows of a grouping set. It is the case if expr
+// is not a group-by item of the grouping set and refers to the columns of the group-by items rolled up.
+func (p *LogicalAggregation) isRolledUp(expr expression.Expression, inSet []bool) bool {
+	if idx := p.groupByItemIndex(expr); idx >= 0 && inSet[idx] {
+		return false
+	}
+	schema := expression.NewSchema(expression.ExtractColumns(expr)...)
+	for i, item := range p.GroupByItems {
+		if inSet[i] {
+			continue
+		}
+		for _, col := range expression.ExtractColumns(item) {
+			if schema.Contains(col) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildGroupingSets builds the grouping sets of the physical aggregation.
+func (p *LogicalAggregation) buildGroupingSets() []*GroupingSet {
+	if len(p.GroupingSets) == 0 {
+		return nil
+	}
+	sets := make([]*GroupingSet, 0, len(p.GroupingSets))
+	for s, items := range p.GroupingSets {
+		set := &GroupingSet{Items: items, Outputs: make(map[int]types.Datum)}
+		for i, fun := range p.AggFuncs {
+			if results := p.groupingOutputs[fun]; results != nil && results[s] != nil {
+				set.Outputs[i] = *results[s]
+			}
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
 func (p *LogicalAggregation) extractCorrelatedCols() []*expression.CorrelatedColumn {
 	corCols := p.basePlan.extractCorrelatedCols()
 	for _, expr := range p.GroupByItems {
@@ -200,6 +301,79 @@ func (p *LogicalAggregation) extractCorrelatedCols() []*expression.CorrelatedCol
 	return corCols
 }
 
+// FrameBound is the boundary of a window frame.
+type FrameBound struct {
+	Type      ast.BoundType
+	UnBounded bool
+	// Offset is the N of "N PRECEDING" or "N FOLLOWING".
+	Offset types.Datum
+}
+
+// WindowFrame is the frame of a window function. The frame is always filled by the plan builder,
+// when the window specification doesn't contain a frame clause the default frame is used.
+type WindowFrame struct {
+	Type  ast.FrameType
+	Start *FrameBound
+	End   *FrameBound
+}
+
+// String implements fmt.Stringer interface.
+func (b *FrameBound) String() string {
+	var prefix string
+	if b.UnBounded {
+		prefix = "unbounded"
+	} else if b.Type != ast.CurrentRow {
+		prefix = fmt.Sprintf("%v", b.Offset.GetValue())
+	}
+	switch b.Type {
+	case ast.Preceding:
+		return prefix + " preceding"
+	case ast.Following:
+		return prefix + " following"
+	}
+	return "current row"
+}
+
+// String implements fmt.Stringer interface.
+func (f *WindowFrame) String() string {
+	tp := "rows"
+	if f.Type == ast.Ranges {
+		tp = "range"
+	}
+	return fmt.Sprintf("%s between %s and %s", tp, f.Start, f.End)
+}
+
+// LogicalWindow represents a window function plan. Its child is sorted by the partition by items
+// and the order by items, and its schema is the child's schema appended with the result column.
+type LogicalWindow struct {
+	*basePlan
+	baseLogicalPlan
+
+	WindowFuncDesc *aggregation.WindowFuncDesc
+	PartitionBy    []expression.Expression
+	OrderBy        []*ByItems
+	Frame          *WindowFrame
+}
+
+func (p *LogicalWindow) extractCorrelatedCols() []*expression.CorrelatedColumn {
+	corCols := p.basePlan.extractCorrelatedCols()
+	for _, arg := range p.WindowFuncDesc.Args {
+		corCols = append(corCols, extractCorColumns(arg)...)
+	}
+	for _, item := range p.PartitionBy {
+		corCols = append(corCols, extractCorColumns(item)...)
+	}
+	for _, item := range p.OrderBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	return corCols
+}
+
+// GetWindowResultColumn returns the column storing the result of the window function.
+func (p *LogicalWindow) GetWindowResultColumn() *expression.Column {
+	return p.schema.Columns[p.schema.Len()-1]
+}
+
 // Selection means a filter.
 type Selection struct {
 	*basePlan
@@ -271,6 +445,27 @@ type TableDual struct {
 	RowCount int
 }
 
+// RecursiveCTE represents a recursive common table expression. The first child is the seed part, the second
+// child is the recursive part, which reads the rows produced by the previous iteration from a CTEWorkTable.
+type RecursiveCTE struct {
+	*basePlan
+	baseLogicalPlan
+	basePhysicalPlan
+
+	// Distinct indicates whether the duplicated rows should be removed.
+	Distinct bool
+}
+
+// CTEWorkTable represents the reference of a recursive common table expression in its recursive part.
+type CTEWorkTable struct {
+	*basePlan
+	baseLogicalPlan
+	basePhysicalPlan
+
+	// CTEID is the ID of the RecursiveCTE which fills the work table.
+	CTEID int
+}
+
 // DataSource represents a tablescan without condition push down.
 type DataSource struct {
 	*basePlan
@@ -278,6 +473,7 @@ type DataSource struct {
 
 	indexHints []*ast.IndexHint
 	tableInfo  *model.TableInfo
+	table      table.Table
 	Columns    []*model.ColumnInfo
 	DBName     model.CIStr
 
@@ -295,6 +491,20 @@ type DataSource struct {
 
 	// This is schema the PhysicalUnionScan should be.
 	unionScanSchema *expression.Schema
+
+	// genColExprs are the generation expressions of the generated columns, the same expressions above
+	// the data source are substituted with the columns.
+	genColExprs []genColExpr
+
+	// virtualColConds are the conditions on the virtual generated columns, which are checked above the
+	// data source after the columns are calculated, they are only used to build the index ranges.
+	virtualColConds []expression.Expression
+}
+
+// isVirtualGenCol checks whether the column of the data source is a virtual generated column.
+func (p *DataSource) isVirtualGenCol(col *expression.Column) bool {
+	idx := p.schema.ColumnIndex(col)
+	return idx != -1 && p.Columns[idx].IsGenerated() && !p.Columns[idx].GeneratedStored
 }
 
 func (p *DataSource) getPKIsHandleCol() *expression.Column {
@@ -337,6 +547,10 @@ type Sort struct {
 
 	ByItems   []*ByItems
 	ExecLimit *Limit // no longer be used by new plan
+
+	// spill is true if the rows to sort are estimated to exceed the sort memory quota or the memory quota
+	// of the statement, so they are expected to be spilled to disk and sorted externally.
+	spill bool
 }
 
 func (p *Sort) extractCorrelatedCols() []*expression.CorrelatedColumn {
@@ -359,6 +573,11 @@ type TopN struct {
 
 	// partial is true if this topn is generated by push-down optimization.
 	partial bool
+
+	inputCount float64 // inputCount is the input count of this plan.
+	// spill is true if the rows kept by the topn are estimated to exceed the sort memory quota or the memory
+	// quota of the statement.
+	spill bool
 }
 
 // isLimit checks if TopN is a limit plan.
@@ -123,7 +123,7 @@ func (is *PhysicalIndexScan) matchProperty(prop *requiredProperty, infos ...*phy
 	}
 	matchedIdx := 0
 	matchedList := make([]bool, len(prop.props))
-	for i, idxCol := range is.Index.Columns {
+	for i, idxCol := range sortedIndex(is.Table, is.Index).Columns {
 		if idxCol.Length != types.UnspecifiedLength {
 			break
 		}
@@ -246,6 +246,21 @@ func (p *Union) matchProperty(_ *requiredProperty, childPlanInfo ...*physicalPla
 	return &physicalPlanInfo{p: np, cost: cost, count: count, reliable: reliable}
 }
 
+// matchProperty implements PhysicalPlan matchProperty interface.
+func (p *RecursiveCTE) matchProperty(_ *requiredProperty, childPlanInfo ...*physicalPlanInfo) *physicalPlanInfo {
+	np := p.Copy()
+	children := make([]Plan, 0, len(childPlanInfo))
+	cost := float64(0)
+	count := float64(0)
+	for _, res := range childPlanInfo {
+		children = append(children, res.p)
+		cost += res.cost
+		count += res.count
+	}
+	np.SetChildren(children...)
+	return &physicalPlanInfo{p: np, cost: cost, count: count}
+}
+
 // matchProperty implements PhysicalPlan matchProperty interface.
 func (p *Selection) matchProperty(prop *requiredProperty, childPlanInfo ...*physicalPlanInfo) *physicalPlanInfo {
 	if p.onTable {
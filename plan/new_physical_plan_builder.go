@@ -57,6 +57,8 @@ func (p *requiredProp) enforceProperty(task task, ctx context.Context, allocator
 // getChildrenPossibleProps will check if this sort property can be pushed or not.
 // When a sort column will be replaced by scalar function, we refuse it.
 // When a sort column will be replaced by a constant, we just remove it.
+// When a sort column is a virtual generated column calculated by the projection, we push the column itself,
+// which can only be satisfied by the indexes on it.
 func (p *Projection) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	p.expectedCnt = prop.expectedCnt
 	newProp := &requiredProp{taskTp: rootTaskType, expectedCnt: prop.expectedCnt}
@@ -70,7 +72,10 @@ func (p *Projection) getChildrenPossibleProps(prop *requiredProp) [][]*requiredP
 		case *expression.Column:
 			newCols = append(newCols, expr)
 		case *expression.ScalarFunction:
-			return nil
+			if !p.calculateGenCols {
+				return nil
+			}
+			newCols = append(newCols, p.schema.Columns[idx])
 		}
 	}
 	newProp.cols = newCols
@@ -165,7 +170,7 @@ func (p *LogicalJoin) getIndexJoinByOuterIdx(outerIdx int) []PhysicalPlan {
 		}
 	}
 	for _, indexInfo := range indices {
-		matchedOffsets := joinKeysMatchIndex(innerJoinKeys, indexInfo)
+		matchedOffsets := joinKeysMatchIndex(innerJoinKeys, sortedIndex(x.tableInfo, indexInfo))
 		if matchedOffsets == nil {
 			continue
 		}
@@ -420,7 +425,8 @@ func getPropByOrderByItems(items []*ByItems) (*requiredProp, bool) {
 	cols := make([]*expression.Column, 0, len(items))
 	for i, item := range items {
 		col, ok := item.Expr.(*expression.Column)
-		if !ok {
+		// The order of the column with the COLLATE clause may be different from the order of the index.
+		if !ok || mysql.HasExplicitCollateFlag(col.RetType.Flag) {
 			return nil, false
 		}
 		cols = append(cols, col)
@@ -652,7 +658,7 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 			return nil, errors.Trace(err)
 		}
 	}
-	if !includeTableScan || len(p.pushedDownConds) > 0 || len(prop.cols) > 0 {
+	if !includeTableScan || len(p.pushedDownConds) > 0 || len(p.virtualColConds) > 0 || len(prop.cols) > 0 {
 		for _, idx := range indices {
 			idxTask, err := p.convertToIndexScan(prop, idx)
 			if err != nil {
@@ -709,19 +715,25 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	statsTbl := p.statisticTable
 	rowCount := float64(statsTbl.Count)
 	sc := p.ctx.GetSessionVars().StmtCtx
-	idxCols, colLengths := expression.IndexInfo2Cols(p.Schema().Columns, idx)
+	sortedIdx := sortedIndex(p.tableInfo, idx)
+	idxCols, colLengths := expression.IndexInfo2Cols(p.Schema().Columns, sortedIdx)
 	is.Ranges = ranger.FullIndexRange()
-	if len(p.pushedDownConds) > 0 {
+	if len(p.pushedDownConds) > 0 || len(p.virtualColConds) > 0 {
 		conds := make([]expression.Expression, 0, len(p.pushedDownConds))
 		for _, cond := range p.pushedDownConds {
 			conds = append(conds, cond.Clone())
 		}
 		if len(idxCols) > 0 {
+			for _, cond := range p.virtualColConds {
+				conds = append(conds, cond.Clone())
+			}
 			var ranges []types.Range
 			ranges, is.AccessCondition, is.filterCondition, err = ranger.BuildRange(sc, conds, ranger.IndexRangeType, idxCols, colLengths)
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+			// The conditions on the virtual generated columns are checked above the data source.
+			is.filterCondition = p.removeVirtualColConds(is.filterCondition)
 			is.Ranges = ranger.Ranges2IndexRanges(ranges)
 			rowCount, err = statsTbl.GetRowCountByIndexRanges(sc, is.Index.ID, is.Ranges)
 			if err != nil {
@@ -752,10 +764,10 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	// Check if this plan matches the property.
 	matchProperty := false
 	if !prop.isEmpty() {
-		for i, col := range idx.Columns {
+		for i, col := range sortedIdx.Columns {
 			// not matched
 			if col.Name.L == prop.cols[0].ColName.L {
-				matchProperty = matchIndicesProp(idx.Columns[i:], prop.cols)
+				matchProperty = matchIndicesProp(sortedIdx.Columns[i:], prop.cols)
 				break
 			} else if i >= len(is.AccessCondition) {
 				break
@@ -793,6 +805,10 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 			task = addUnionScan(cop, p)
 		}
 	} else {
+		// The virtual generated columns read from the storage aren't calculated, so they can't be sorted here.
+		if p.propHasVirtualGenCols(prop) {
+			return invalidTask, nil
+		}
 		is.OutOfOrder = true
 		expectedCnt := math.MaxFloat64
 		if prop.isEmpty() {
@@ -812,6 +828,34 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	return task, nil
 }
 
+// propHasVirtualGenCols checks whether the property requires the order of virtual generated columns.
+func (p *DataSource) propHasVirtualGenCols(prop *requiredProp) bool {
+	for _, col := range prop.cols {
+		if p.isVirtualGenCol(col) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeVirtualColConds removes the conditions on the virtual generated columns.
+func (p *DataSource) removeVirtualColConds(conds []expression.Expression) []expression.Expression {
+	newConds := conds[:0]
+	for _, cond := range conds {
+		isVirtual := false
+		for _, col := range expression.ExtractColumns(cond) {
+			if p.isVirtualGenCol(col) {
+				isVirtual = true
+				break
+			}
+		}
+		if !isVirtual {
+			newConds = append(newConds, cond)
+		}
+	}
+	return newConds
+}
+
 func (is *PhysicalIndexScan) initSchema(id int, idx *model.IndexInfo, isDoubleRead bool) {
 	var indexCols []*expression.Column
 	for _, col := range idx.Columns {
@@ -903,6 +947,9 @@ func (p *DataSource) convertToTableScan(prop *requiredProp) (task task, err erro
 	if prop.taskTp == copDoubleReadTaskType {
 		return &copTask{cst: math.MaxFloat64}, nil
 	}
+	if p.propHasVirtualGenCols(prop) {
+		return invalidTask, nil
+	}
 	ts := PhysicalTableScan{
 		Table:               p.tableInfo,
 		Columns:             p.Columns,
@@ -1068,6 +1115,30 @@ func (p *PhysicalApply) getChildrenPossibleProps(prop *requiredProp) [][]*requir
 	return [][]*requiredProp{{lProp, &requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64}}}
 }
 
+func (p *RecursiveCTE) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
+	p.expectedCnt = prop.expectedCnt
+	if !prop.isEmpty() {
+		return nil
+	}
+	return [][]*requiredProp{{&requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64}, &requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64}}}
+}
+
+func (p *LogicalWindow) generatePhysicalPlans() []PhysicalPlan {
+	window := p.toPhysicalWindow()
+	window.profile = p.profile
+	return []PhysicalPlan{window}
+}
+
+// getChildrenPossibleProps requires nothing of the child, because the sort of the partition by items and the order
+// by items has been added as the child of the window by the plan builder.
+func (p *PhysicalWindow) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
+	p.expectedCnt = prop.expectedCnt
+	if !prop.isEmpty() {
+		return nil
+	}
+	return [][]*requiredProp{{&requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64}}}
+}
+
 func (p *Limit) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	p.expectedCnt = prop.expectedCnt
 	if !prop.isEmpty() {
@@ -1136,12 +1207,17 @@ func (p *LogicalAggregation) generatePhysicalPlans() []PhysicalPlan {
 	agg := PhysicalAggregation{
 		GroupByItems: p.GroupByItems,
 		AggFuncs:     p.AggFuncs,
+		GroupingSets: p.buildGroupingSets(),
 		HasGby:       len(p.GroupByItems) > 0,
 		AggType:      CompleteAgg,
 	}.init(p.allocator, p.ctx)
 	agg.SetSchema(p.schema.Clone())
 	agg.profile = p.profile
 	aggs = append(aggs, agg)
+	// The grouping sets are only supported by the hash aggregation.
+	if len(p.GroupingSets) > 0 {
+		return aggs
+	}
 
 	streamAggs := p.getStreamAggs()
 	aggs = append(aggs, streamAggs...)
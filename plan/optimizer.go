@@ -24,27 +24,32 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/auth"
 )
 
 // AllowCartesianProduct means whether tidb allows cartesian join without equal conditions.
 var AllowCartesianProduct = true
 
 const (
-	flagPrunColumns uint64 = 1 << iota
+	flagGcSubstitute uint64 = 1 << iota
+	flagPrunColumns
 	flagEliminateProjection
 	flagBuildKeyInfo
 	flagDecorrelate
 	flagPredicatePushDown
+	flagPartitionProcessor
 	flagAggregationOptimize
 	flagPushDownTopN
 )
 
 var optRuleList = []logicalOptRule{
+	&gcSubstituter{},
 	&columnPruner{},
 	&projectionEliminater{},
 	&buildKeySolver{},
 	&decorrelateSolver{},
 	&ppdSolver{},
+	&partitionProcessor{},
 	&aggregationOptimizer{},
 	&pushDownTopNOptimizer{},
 }
@@ -72,7 +77,7 @@ func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Pla
 	// Maybe it's better to move this to Preprocess, but check privilege need table
 	// information, which is collected into visitInfo during logical plan builder.
 	if pm := privilege.GetPrivilegeManager(ctx); pm != nil {
-		if !checkPrivilege(pm, builder.visitInfo) {
+		if !checkPrivilege(pm, ctx.GetSessionVars().ActiveRoles, builder.visitInfo) {
 			return nil, errors.New("privilege check fail")
 		}
 	}
@@ -98,9 +103,15 @@ func BuildLogicalPlan(ctx context.Context, node ast.Node, is infoschema.InfoSche
 	return p, nil
 }
 
-func checkPrivilege(pm privilege.Manager, vs []visitInfo) bool {
+func checkPrivilege(pm privilege.Manager, activeRoles []*auth.RoleIdentity, vs []visitInfo) bool {
 	for _, v := range vs {
-		if !pm.RequestVerification(v.db, v.table, v.column, v.privilege) {
+		if v.user != nil {
+			if !pm.RequestVerificationWithUser(v.db, v.table, v.column, v.privilege, v.user) {
+				return false
+			}
+			continue
+		}
+		if !pm.RequestVerification(activeRoles, v.db, v.table, v.column, v.privilege) {
 			return false
 		}
 	}
@@ -231,4 +242,5 @@ func init() {
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = mySQLErrCodes
 	expression.EvalAstExpr = evalAstExpr
+	expression.RewriteAstExpr = rewriteAstExpr
 }
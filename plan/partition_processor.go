@@ -0,0 +1,223 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// partitionProcessor rewrites the DataSource of a partitioned table to a Union of the DataSources of its
+// partitions. The partitions that can't contain any row satisfying the filter conditions are pruned.
+type partitionProcessor struct {
+	alloc *idAllocator
+}
+
+func (s *partitionProcessor) optimize(lp LogicalPlan, ctx context.Context, alloc *idAllocator) (LogicalPlan, error) {
+	s.alloc = alloc
+	return s.rewriteDataSource(lp, nil)
+}
+
+// rewriteDataSource rewrites the DataSources in lp, conds are the conditions of the Selection above lp, they are
+// used for pruning when the conditions aren't pushed down to the DataSource.
+func (s *partitionProcessor) rewriteDataSource(lp LogicalPlan, conds []expression.Expression) (LogicalPlan, error) {
+	if ds, ok := lp.(*DataSource); ok {
+		return s.processDataSource(ds, conds)
+	}
+	var childConds []expression.Expression
+	if sel, ok := lp.(*Selection); ok {
+		childConds = sel.Conditions
+	}
+	children := make([]Plan, 0, len(lp.Children()))
+	for _, child := range lp.Children() {
+		newChild, err := s.rewriteDataSource(child.(LogicalPlan), childConds)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		newChild.SetParents(lp)
+		children = append(children, newChild)
+	}
+	lp.SetChildren(children...)
+	return lp, nil
+}
+
+func (s *partitionProcessor) processDataSource(ds *DataSource, conds []expression.Expression) (LogicalPlan, error) {
+	pt, ok := ds.table.(table.PartitionedTable)
+	if !ok {
+		return ds, nil
+	}
+	allConds := make([]expression.Expression, 0, len(conds)+len(ds.pushedDownConds))
+	allConds = append(allConds, conds...)
+	allConds = append(allConds, ds.pushedDownConds...)
+	used, err := s.prunePartitions(ds, pt, allConds)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	defs := ds.tableInfo.Partition.Definitions
+	children := make([]Plan, 0, len(defs))
+	for i, def := range defs {
+		if !used[i] {
+			continue
+		}
+		partition := pt.GetPartition(def.ID)
+		newDS := DataSource{
+			indexHints:      ds.indexHints,
+			tableInfo:       partition.Meta(),
+			table:           partition,
+			Columns:         ds.Columns,
+			DBName:          ds.DBName,
+			TableAsName:     ds.TableAsName,
+			LimitCount:      ds.LimitCount,
+			pushedDownConds: ds.pushedDownConds,
+			statisticTable:  ds.statisticTable,
+			NeedColHandle:   ds.NeedColHandle,
+			unionScanSchema: ds.unionScanSchema,
+		}.init(s.alloc, ds.ctx)
+		// The handle of a partition's DataSource is keyed by the partition ID, while the handle of the Union
+		// is still keyed by the table ID, so the rows are written to the partitioned table.
+		schema := ds.schema.Clone()
+		if handles, ok := schema.TblID2Handle[ds.tableInfo.ID]; ok {
+			delete(schema.TblID2Handle, ds.tableInfo.ID)
+			schema.TblID2Handle[def.ID] = handles
+		}
+		newDS.SetSchema(schema)
+		children = append(children, newDS)
+	}
+	if len(children) == 0 {
+		dual := TableDual{}.init(s.alloc, ds.ctx)
+		dual.SetSchema(ds.schema)
+		return dual, nil
+	}
+	union := Union{}.init(s.alloc, ds.ctx)
+	union.SetSchema(ds.schema)
+	for _, child := range children {
+		child.SetParents(union)
+	}
+	union.SetChildren(children...)
+	return union, nil
+}
+
+// prunePartitions returns the partitions which may contain rows satisfying conds. The ranges of the partitioning
+// column are built from conds, a point is located by evaluating the partitioning expression. For a RANGE
+// partitioned table, a range is located by its ends if the expression is monotonic.
+func (s *partitionProcessor) prunePartitions(ds *DataSource, pt table.PartitionedTable, conds []expression.Expression) ([]bool, error) {
+	pi := ds.tableInfo.Partition
+	used := make([]bool, len(pi.Definitions))
+	partExpr := pt.PartitionExpr()
+	partCols := expression.ExtractColumns(partExpr)
+	var col *expression.Column
+	if len(partCols) == 1 {
+		for _, c := range ds.schema.Columns {
+			if c.ColName.L == partCols[0].ColName.L {
+				col = c
+				break
+			}
+		}
+	}
+	if col == nil || len(conds) == 0 {
+		return markAllPartitions(used), nil
+	}
+
+	sc := ds.ctx.GetSessionVars().StmtCtx
+	ranges, _, _, err := ranger.BuildRange(sc, conds, ranger.ColumnRangeType, []*expression.Column{col}, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	monotonic := pi.Type == model.PartitionTypeRange && isMonotonicPartitionExpr(partExpr)
+	offset := partCols[0].Index
+	locate := func(d types.Datum) (int, error) {
+		row := make([]types.Datum, len(ds.tableInfo.Columns))
+		row[offset] = d
+		v, err := partExpr.Eval(row)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		return pt.LocatePartition(sc, v)
+	}
+	for _, ran := range ranger.Ranges2ColumnRanges(ranges) {
+		if isPointColumnRange(sc, ran) {
+			idx, err := locate(ran.Low)
+			if table.ErrNoPartitionForGivenValue.Equal(err) {
+				continue
+			} else if err != nil {
+				return markAllPartitions(used), nil
+			}
+			used[idx] = true
+			continue
+		}
+		if !monotonic {
+			return markAllPartitions(used), nil
+		}
+		lowIdx, highIdx := 0, len(used)-1
+		if ran.Low.Kind() != types.KindNull && ran.Low.Kind() != types.KindMinNotNull {
+			lowIdx, err = locate(ran.Low)
+			if table.ErrNoPartitionForGivenValue.Equal(err) {
+				continue
+			} else if err != nil {
+				return markAllPartitions(used), nil
+			}
+		}
+		if ran.High.Kind() != types.KindMaxValue {
+			idx, err := locate(ran.High)
+			if err == nil {
+				highIdx = idx
+			} else if !table.ErrNoPartitionForGivenValue.Equal(err) {
+				return markAllPartitions(used), nil
+			}
+		}
+		for i := lowIdx; i <= highIdx; i++ {
+			used[i] = true
+		}
+	}
+	return used, nil
+}
+
+func markAllPartitions(used []bool) []bool {
+	for i := range used {
+		used[i] = true
+	}
+	return used
+}
+
+func isPointColumnRange(sc *variable.StatementContext, ran *types.ColumnRange) bool {
+	if ran.LowExcl || ran.HighExcl {
+		return false
+	}
+	cmp, err := ran.Low.CompareDatum(sc, &ran.High)
+	return err == nil && cmp == 0
+}
+
+// isMonotonicPartitionExpr checks whether the partitioning expression is a column or a non-decreasing function
+// of a column, so the partitions of a range are between the partitions of its ends.
+func isMonotonicPartitionExpr(expr expression.Expression) bool {
+	switch x := expr.(type) {
+	case *expression.Column:
+		return true
+	case *expression.ScalarFunction:
+		switch x.FuncName.L {
+		case ast.Year, ast.ToDays, ast.ToSeconds, ast.UnixTimestamp:
+			_, ok := x.GetArgs()[0].(*expression.Column)
+			return ok && len(x.GetArgs()) == 1
+		}
+	}
+	return false
+}
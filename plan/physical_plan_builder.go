@@ -146,7 +146,8 @@ func (p *DataSource) convert2IndexScan(prop *requiredProperty, index *model.Inde
 			for _, cond := range sel.Conditions {
 				conds = append(conds, cond.Clone())
 			}
-			is.AccessCondition, newSel.Conditions, is.accessEqualCount, is.accessInAndEqCount = ranger.DetachIndexScanConditions(conds, is.Index)
+			sortedIdx := sortedIndex(is.Table, is.Index)
+			is.AccessCondition, newSel.Conditions, is.accessEqualCount, is.accessInAndEqCount = ranger.DetachIndexScanConditions(conds, sortedIdx)
 			memDB := infoschema.IsMemoryDB(p.DBName.L)
 			isDistReq := !memDB && client != nil && client.IsRequestTypeSupported(kv.ReqTypeIndex, 0)
 			if isDistReq {
@@ -156,12 +157,14 @@ func (p *DataSource) convert2IndexScan(prop *requiredProperty, index *model.Inde
 				newSel.Conditions = append(idxConds, tblConds...)
 			}
 			var err error
-			is.Ranges, err = ranger.BuildIndexRange(p.ctx.GetSessionVars().StmtCtx, is.Table, is.Index, is.accessInAndEqCount, is.AccessCondition)
-			if err != nil {
-				if !types.ErrTruncated.Equal(err) {
-					return nil, errors.Trace(err)
+			if len(sortedIdx.Columns) > 0 {
+				is.Ranges, err = ranger.BuildIndexRange(p.ctx.GetSessionVars().StmtCtx, is.Table, sortedIdx, is.accessInAndEqCount, is.AccessCondition)
+				if err != nil {
+					if !types.ErrTruncated.Equal(err) {
+						return nil, errors.Trace(err)
+					}
+					log.Warn("truncate error in buildIndexRange")
 				}
-				log.Warn("truncate error in buildIndexRange")
 			}
 			rowCount, err = statsTbl.GetRowCountByIndexRanges(sc, is.Index.ID, is.Ranges)
 			if err != nil {
@@ -178,6 +181,24 @@ func (p *DataSource) convert2IndexScan(prop *requiredProperty, index *model.Inde
 	return resultPlan.matchProperty(prop, &physicalPlanInfo{count: rowCount, reliable: !statsTbl.Pseudo}), nil
 }
 
+// sortedIndex returns the index with the leading columns whose index keys are in the order of their
+// values, the ranges and the orders on the index are only built on these columns. The index keys of
+// the strings compared under non-binary collations are binary in the tables before
+// model.TableInfoVersion1, so these columns and the ones after them are cut off.
+func sortedIndex(tblInfo *model.TableInfo, idx *model.IndexInfo) *model.IndexInfo {
+	if tblInfo.Version >= model.TableInfoVersion1 {
+		return idx
+	}
+	for i, ic := range idx.Columns {
+		if types.HasCollationKey(&tblInfo.Columns[ic.Offset].FieldType) {
+			sorted := *idx
+			sorted.Columns = idx.Columns[:i]
+			return &sorted
+		}
+	}
+	return idx
+}
+
 func isCoveringIndex(columns []*model.ColumnInfo, indexColumns []*model.IndexColumn, pkIsHandle bool) bool {
 	for _, colInfo := range columns {
 		if pkIsHandle && mysql.HasPriKeyFlag(colInfo.Flag) {
@@ -188,7 +209,10 @@ func isCoveringIndex(columns []*model.ColumnInfo, indexColumns []*model.IndexCol
 		}
 		isIndexColumn := false
 		for _, indexCol := range indexColumns {
-			if colInfo.Name.L == indexCol.Name.L && indexCol.Length == types.UnspecifiedLength {
+			// The sort keys of the strings compared under non-binary collations are stored in the
+			// index, so the values have to be read from the table.
+			if colInfo.Name.L == indexCol.Name.L && indexCol.Length == types.UnspecifiedLength &&
+				!types.HasCollationKey(&colInfo.FieldType) {
 				isIndexColumn = true
 				break
 			}
@@ -1050,6 +1074,10 @@ func (p *LogicalJoin) convert2PhysicalPlan(prop *requiredProperty) (*physicalPla
 
 // convert2PhysicalPlanStream converts the logical aggregation to the stream aggregation *physicalPlanInfo.
 func (p *LogicalAggregation) convert2PhysicalPlanStream(prop *requiredProperty) (*physicalPlanInfo, error) {
+	// The grouping sets are only supported by the hash aggregation.
+	if len(p.GroupingSets) > 0 {
+		return &physicalPlanInfo{cost: math.MaxFloat64}, nil
+	}
 	for _, aggFunc := range p.AggFuncs {
 		if aggFunc.GetMode() == aggregation.FinalMode {
 			return &physicalPlanInfo{cost: math.MaxFloat64}, nil
@@ -1125,6 +1153,7 @@ func (p *LogicalAggregation) convert2PhysicalPlanCompleteHash(childInfo *physica
 		AggType:      CompleteAgg,
 		AggFuncs:     p.AggFuncs,
 		GroupByItems: p.GroupByItems,
+		GroupingSets: p.buildGroupingSets(),
 	}.init(p.allocator, p.ctx)
 	agg.HasGby = len(p.GroupByItems) > 0
 	agg.SetSchema(p.schema)
@@ -1147,7 +1176,8 @@ func (p *LogicalAggregation) convert2PhysicalPlanHash() (*physicalPlanInfo, erro
 			break
 		}
 	}
-	if !distinct {
+	// The final aggregation gets the encoded group keys from the coprocessor, which can't be split into grouping sets.
+	if !distinct && len(p.GroupingSets) == 0 {
 		if x, ok := childInfo.p.(physicalDistSQLPlan); ok {
 			info := p.convert2PhysicalPlanFinalHash(x, childInfo)
 			if info != nil {
@@ -1217,6 +1247,58 @@ func (p *Union) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo,
 	return info, errors.Trace(p.storePlanInfo(prop, info))
 }
 
+// convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
+func (p *RecursiveCTE) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
+	info, err := p.getPlanInfo(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if info != nil {
+		return info, nil
+	}
+	childInfos := make([]*physicalPlanInfo, 0, len(p.children))
+	for _, child := range p.Children() {
+		childInfo, err := child.(LogicalPlan).convert2PhysicalPlan(&requiredProperty{})
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		childInfos = append(childInfos, childInfo)
+	}
+	info = p.matchProperty(prop, childInfos...)
+	info = enforceProperty(prop, info)
+	return info, errors.Trace(p.storePlanInfo(prop, info))
+}
+
+func (p *LogicalWindow) toPhysicalWindow() *PhysicalWindow {
+	window := PhysicalWindow{
+		WindowFuncDesc: p.WindowFuncDesc,
+		PartitionBy:    p.PartitionBy,
+		OrderBy:        p.OrderBy,
+		Frame:          p.Frame,
+	}.init(p.allocator, p.ctx)
+	window.SetSchema(p.schema.Clone())
+	return window
+}
+
+// convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
+func (p *LogicalWindow) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
+	info, err := p.getPlanInfo(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if info != nil {
+		return info, nil
+	}
+	childInfo, err := p.children[0].(LogicalPlan).convert2PhysicalPlan(&requiredProperty{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	info = addPlanToResponse(p.toPhysicalWindow(), childInfo)
+	info.cost += info.count * cpuFactor
+	info = enforceProperty(prop, info)
+	return info, errors.Trace(p.storePlanInfo(prop, info))
+}
+
 // makeScanController will try to build a selection that controls the below scan's filter condition,
 // and return a physicalPlanInfo. If the onlyCheck is true, it will only check whether this selection
 // can become a scan controller without building the physical plan.
@@ -1264,7 +1346,7 @@ func (p *Selection) makeScanController() *physicalPlanInfo {
 			for _, cond := range corColConds {
 				condsBackUp = append(condsBackUp, cond.Clone())
 			}
-			_, _, accessEqualCount, _ := ranger.DetachIndexScanConditions(condsBackUp, idx)
+			_, _, accessEqualCount, _ := ranger.DetachIndexScanConditions(condsBackUp, sortedIndex(ds.tableInfo, idx))
 			if chosenPlan == nil || bestEqualCount < accessEqualCount {
 				is := PhysicalIndexScan{
 					Table:               ds.tableInfo,
@@ -1480,7 +1562,7 @@ func (p *Sort) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo,
 		props: make([]*columnProp, 0, len(p.ByItems)),
 	}
 	for _, by := range p.ByItems {
-		if col, ok := by.Expr.(*expression.Column); ok {
+		if col, ok := by.Expr.(*expression.Column); ok && !mysql.HasExplicitCollateFlag(col.RetType.Flag) {
 			selfProp.props = append(selfProp.props, &columnProp{col: col, desc: by.Desc})
 		} else {
 			selfProp.props = nil
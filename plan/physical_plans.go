@@ -50,6 +50,8 @@ var (
 	_ PhysicalPlan = &MaxOneRow{}
 	_ PhysicalPlan = &TableDual{}
 	_ PhysicalPlan = &Union{}
+	_ PhysicalPlan = &RecursiveCTE{}
+	_ PhysicalPlan = &CTEWorkTable{}
 	_ PhysicalPlan = &Sort{}
 	_ PhysicalPlan = &Update{}
 	_ PhysicalPlan = &Delete{}
@@ -63,6 +65,7 @@ var (
 	_ PhysicalPlan = &PhysicalIndexReader{}
 	_ PhysicalPlan = &PhysicalIndexLookUpReader{}
 	_ PhysicalPlan = &PhysicalAggregation{}
+	_ PhysicalPlan = &PhysicalWindow{}
 	_ PhysicalPlan = &PhysicalApply{}
 	_ PhysicalPlan = &PhysicalIndexJoin{}
 	_ PhysicalPlan = &PhysicalHashJoin{}
@@ -580,11 +583,33 @@ type PhysicalAggregation struct {
 	AggType      AggregationType
 	AggFuncs     []aggregation.Aggregation
 	GroupByItems []expression.Expression
+	GroupingSets []*GroupingSet
 
 	propKeys   []*expression.Column
 	inputCount float64 // inputCount is the input count of this plan.
 }
 
+// GroupingSet is a grouping set of the aggregation with GROUP BY ... WITH ROLLUP.
+type GroupingSet struct {
+	// Items are the indices of the group-by items the grouping set groups by.
+	Items []int
+	// Outputs maps the index of an aggregate function to its result in the rows of the grouping set.
+	// They are the NULL values of the rolled up group-by columns and the results of the GROUPING functions.
+	Outputs map[int]types.Datum
+}
+
+// PhysicalWindow is the physical operator of LogicalWindow. It requires its child to be sorted by the
+// partition by items and the order by items.
+type PhysicalWindow struct {
+	*basePlan
+	basePhysicalPlan
+
+	WindowFuncDesc *aggregation.WindowFuncDesc
+	PartitionBy    []expression.Expression
+	OrderBy        []*ByItems
+	Frame          *WindowFrame
+}
+
 // PhysicalUnionScan represents a union scan operator.
 type PhysicalUnionScan struct {
 	*basePlan
@@ -696,6 +721,20 @@ func (p *PhysicalAggregation) extractCorrelatedCols() []*expression.CorrelatedCo
 	return corCols
 }
 
+func (p *PhysicalWindow) extractCorrelatedCols() []*expression.CorrelatedColumn {
+	corCols := p.basePlan.extractCorrelatedCols()
+	for _, arg := range p.WindowFuncDesc.Args {
+		corCols = append(corCols, extractCorColumns(arg)...)
+	}
+	for _, item := range p.PartitionBy {
+		corCols = append(corCols, extractCorColumns(item)...)
+	}
+	for _, item := range p.OrderBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	return corCols
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *PhysicalIndexScan) Copy() PhysicalPlan {
 	np := *p
@@ -1038,6 +1077,24 @@ func (p *Union) Copy() PhysicalPlan {
 	return &np
 }
 
+// Copy implements the PhysicalPlan Copy interface.
+func (p *RecursiveCTE) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.baseLogicalPlan = newBaseLogicalPlan(np.basePlan)
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// Copy implements the PhysicalPlan Copy interface.
+func (p *CTEWorkTable) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.baseLogicalPlan = newBaseLogicalPlan(np.basePlan)
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *Sort) Copy() PhysicalPlan {
 	np := *p
@@ -1095,6 +1152,33 @@ func (p *SelectLock) Copy() PhysicalPlan {
 	return &np
 }
 
+// Copy implements the PhysicalPlan Copy interface.
+func (p *PhysicalWindow) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (p *PhysicalWindow) MarshalJSON() ([]byte, error) {
+	partitionBy, err := json.Marshal(p.PartitionBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	orderBy, err := json.Marshal(p.OrderBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	buffer := bytes.NewBufferString("{")
+	buffer.WriteString(fmt.Sprintf(
+		"\"WindowFunc\": \"%s\",\n"+
+			"\"PartitionBy\": %s,\n"+
+			"\"OrderBy\": %s,\n"+
+			"\"child\": \"%s\"}", p.WindowFuncDesc, partitionBy, orderBy, p.children[0].ExplainID()))
+	return buffer.Bytes(), nil
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *PhysicalAggregation) Copy() PhysicalPlan {
 	np := *p
@@ -1181,7 +1265,7 @@ func buildSchema(p PhysicalPlan) {
 		} else {
 			x.SetSchema(x.children[0].Schema().Clone())
 		}
-	case *Union:
+	case *Union, *RecursiveCTE:
 		panic("Union shouldn't rebuild schema")
 	}
 }
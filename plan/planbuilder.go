@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -42,6 +43,26 @@ var (
 	ErrAnalyzeMissIndex     = terror.ClassOptimizerPlan.New(CodeAnalyzeMissIndex, "Index '%s' in field list does not exist in table '%s'")
 	ErrAlterAutoID          = terror.ClassAutoid.New(CodeAlterAutoID, "No support for setting auto_increment using alter_table")
 	ErrBadGeneratedColumn   = terror.ClassOptimizerPlan.New(CodeBadGeneratedColumn, mysql.MySQLErrName[mysql.ErrBadGeneratedColumn])
+	ErrNonUniqTable         = terror.ClassOptimizerPlan.New(CodeNonUniqTable, mysql.MySQLErrName[mysql.ErrNonuniqTable])
+	ErrViewWrongList        = terror.ClassOptimizerPlan.New(CodeViewWrongList, mysql.MySQLErrName[mysql.ErrViewWrongList])
+	ErrViewInvalid          = terror.ClassOptimizerPlan.New(CodeViewInvalid, mysql.MySQLErrName[mysql.ErrViewInvalid])
+	ErrViewRecursive        = terror.ClassOptimizerPlan.New(CodeViewRecursive, mysql.MySQLErrName[mysql.ErrViewRecursive])
+	ErrDupFieldName         = terror.ClassOptimizerPlan.New(CodeDupFieldName, mysql.MySQLErrName[mysql.ErrDupFieldName])
+	ErrNonUpdatableTable    = terror.ClassOptimizerPlan.New(CodeNonUpdatableTable, mysql.MySQLErrName[mysql.ErrNonUpdatableTable])
+	ErrNonInsertableTable   = terror.ClassOptimizerPlan.New(CodeNonInsertableTable, mysql.MySQLErrName[mysql.ErrNonInsertableTable])
+
+	ErrCTERecursiveRequiresUnion             = terror.ClassOptimizerPlan.New(CodeCTERecursiveRequiresUnion, mysql.MySQLErrName[mysql.ErrCTERecursiveRequiresUnion])
+	ErrCTERecursiveRequiresNonRecursiveFirst = terror.ClassOptimizerPlan.New(CodeCTERecursiveRequiresNonRecursiveFirst, mysql.MySQLErrName[mysql.ErrCTERecursiveRequiresNonRecursiveFirst])
+	ErrCTERecursiveForbidsAggregation        = terror.ClassOptimizerPlan.New(CodeCTERecursiveForbidsAggregation, mysql.MySQLErrName[mysql.ErrCTERecursiveForbidsAggregation])
+	ErrCTERecursiveRequiresSingleReference   = terror.ClassOptimizerPlan.New(CodeCTERecursiveRequiresSingleReference, mysql.MySQLErrName[mysql.ErrCTERecursiveRequiresSingleReference])
+
+	ErrWindowFrameStartIllegal    = terror.ClassOptimizerPlan.New(CodeWindowFrameStartIllegal, mysql.MySQLErrName[mysql.ErrWindowFrameStartIllegal])
+	ErrWindowFrameEndIllegal      = terror.ClassOptimizerPlan.New(CodeWindowFrameEndIllegal, mysql.MySQLErrName[mysql.ErrWindowFrameEndIllegal])
+	ErrWindowFrameIllegal         = terror.ClassOptimizerPlan.New(CodeWindowFrameIllegal, mysql.MySQLErrName[mysql.ErrWindowFrameIllegal])
+	ErrWindowRangeFrameOrderType  = terror.ClassOptimizerPlan.New(CodeWindowRangeFrameOrderType, mysql.MySQLErrName[mysql.ErrWindowRangeFrameOrderType])
+	ErrWindowInvalidWindowFuncUse = terror.ClassOptimizerPlan.New(CodeWindowInvalidWindowFuncUse, mysql.MySQLErrName[mysql.ErrWindowInvalidWindowFuncUse])
+	ErrNotSupportedYet            = terror.ClassOptimizerPlan.New(CodeNotSupportedYet, mysql.MySQLErrName[mysql.ErrNotSupportedYet])
+	ErrFieldInGroupingNotGroupBy  = terror.ClassOptimizerPlan.New(CodeFieldInGroupingNotGroupBy, mysql.MySQLErrName[mysql.ErrFieldInGroupingNotGroupBy])
 )
 
 // Error codes.
@@ -55,6 +76,26 @@ const (
 	CodeUnknownTable                      = mysql.ErrBadTable
 	CodeWrongArguments                    = 1210
 	CodeBadGeneratedColumn                = mysql.ErrBadGeneratedColumn
+	CodeNonUniqTable                      = mysql.ErrNonuniqTable
+	CodeViewWrongList                     = mysql.ErrViewWrongList
+	CodeViewInvalid                       = mysql.ErrViewInvalid
+	CodeViewRecursive                     = mysql.ErrViewRecursive
+	CodeDupFieldName                      = mysql.ErrDupFieldName
+	CodeNonUpdatableTable                 = mysql.ErrNonUpdatableTable
+	CodeNonInsertableTable                = mysql.ErrNonInsertableTable
+
+	CodeCTERecursiveRequiresUnion             = mysql.ErrCTERecursiveRequiresUnion
+	CodeCTERecursiveRequiresNonRecursiveFirst = mysql.ErrCTERecursiveRequiresNonRecursiveFirst
+	CodeCTERecursiveForbidsAggregation        = mysql.ErrCTERecursiveForbidsAggregation
+	CodeCTERecursiveRequiresSingleReference   = mysql.ErrCTERecursiveRequiresSingleReference
+
+	CodeWindowFrameStartIllegal    = mysql.ErrWindowFrameStartIllegal
+	CodeWindowFrameEndIllegal      = mysql.ErrWindowFrameEndIllegal
+	CodeWindowFrameIllegal         = mysql.ErrWindowFrameIllegal
+	CodeWindowRangeFrameOrderType  = mysql.ErrWindowRangeFrameOrderType
+	CodeWindowInvalidWindowFuncUse = mysql.ErrWindowInvalidWindowFuncUse
+	CodeNotSupportedYet            = mysql.ErrNotSupportedYet
+	CodeFieldInGroupingNotGroupBy  = mysql.ErrFieldInGroupingNotGroupBy
 )
 
 func init() {
@@ -64,6 +105,26 @@ func init() {
 		CodeAmbiguous:          mysql.ErrNonUniq,
 		CodeWrongArguments:     mysql.ErrWrongArguments,
 		CodeBadGeneratedColumn: mysql.ErrBadGeneratedColumn,
+		CodeNonUniqTable:       mysql.ErrNonuniqTable,
+		CodeViewWrongList:      mysql.ErrViewWrongList,
+		CodeViewInvalid:        mysql.ErrViewInvalid,
+		CodeViewRecursive:      mysql.ErrViewRecursive,
+		CodeDupFieldName:       mysql.ErrDupFieldName,
+		CodeNonUpdatableTable:  mysql.ErrNonUpdatableTable,
+		CodeNonInsertableTable: mysql.ErrNonInsertableTable,
+
+		CodeCTERecursiveRequiresUnion:             mysql.ErrCTERecursiveRequiresUnion,
+		CodeCTERecursiveRequiresNonRecursiveFirst: mysql.ErrCTERecursiveRequiresNonRecursiveFirst,
+		CodeCTERecursiveForbidsAggregation:        mysql.ErrCTERecursiveForbidsAggregation,
+		CodeCTERecursiveRequiresSingleReference:   mysql.ErrCTERecursiveRequiresSingleReference,
+
+		CodeWindowFrameStartIllegal:    mysql.ErrWindowFrameStartIllegal,
+		CodeWindowFrameEndIllegal:      mysql.ErrWindowFrameEndIllegal,
+		CodeWindowFrameIllegal:         mysql.ErrWindowFrameIllegal,
+		CodeWindowRangeFrameOrderType:  mysql.ErrWindowRangeFrameOrderType,
+		CodeWindowInvalidWindowFuncUse: mysql.ErrWindowInvalidWindowFuncUse,
+		CodeNotSupportedYet:            mysql.ErrNotSupportedYet,
+		CodeFieldInGroupingNotGroupBy:  mysql.ErrFieldInGroupingNotGroupBy,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizerPlan] = tableMySQLErrCodes
 }
@@ -73,6 +134,14 @@ type visitInfo struct {
 	db        string
 	table     string
 	column    string
+	// user is the user whose privilege is checked, it's nil for the current user.
+	user *auth.UserIdentity
+}
+
+// isCurrentUser checks whether user is the user of the session.
+func isCurrentUser(ctx context.Context, user *auth.UserIdentity) bool {
+	current := ctx.GetSessionVars().User
+	return current != nil && current.Username == user.Username && current.Hostname == user.Hostname
 }
 
 type tableHintInfo struct {
@@ -131,6 +200,12 @@ type planBuilder struct {
 	visitInfo     []visitInfo
 	tableHintInfo []tableHintInfo
 	optFlag       uint64
+	// recursiveCTEs stores the recursive common table expressions whose queries are being built.
+	recursiveCTEs map[*ast.CommonTableExpression]*recursiveCTEInfo
+	// windowMapper maps the window functions to the index of their result columns in the schema of the plan being built.
+	windowMapper map[*ast.WindowFuncExpr]int
+	// buildingViews stores the full names of the views being expanded, it's used to detect view recursion.
+	buildingViews []string
 }
 
 func (b *planBuilder) build(node ast.Node) Plan {
@@ -153,6 +228,9 @@ func (b *planBuilder) build(node ast.Node) Plan {
 	case *ast.PrepareStmt:
 		return b.buildPrepare(x)
 	case *ast.SelectStmt:
+		if x.SelectIntoOpt != nil {
+			return b.buildSelectInto(x)
+		}
 		return b.buildSelect(x)
 	case *ast.UnionStmt:
 		return b.buildUnion(x)
@@ -167,8 +245,9 @@ func (b *planBuilder) build(node ast.Node) Plan {
 	case *ast.AnalyzeTableStmt:
 		return b.buildAnalyze(x)
 	case *ast.BinlogStmt, *ast.FlushStmt, *ast.UseStmt,
-		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.CreateUserStmt, *ast.SetPwdStmt,
-		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt:
+		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.SavepointStmt, *ast.ReleaseSavepointStmt,
+		*ast.CreateUserStmt, *ast.SetPwdStmt, *ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt,
+		*ast.KillStmt, *ast.DropStatsStmt, *ast.GrantRoleStmt, *ast.RevokeRoleStmt, *ast.SetRoleStmt, *ast.SetDefaultRoleStmt:
 		return b.buildSimple(node.(ast.StmtNode))
 	case ast.DDLNode:
 		return b.buildDDL(x)
@@ -363,6 +442,9 @@ func (b *planBuilder) buildAdmin(as *ast.AdminStmt) Plan {
 
 	switch as.Tp {
 	case ast.AdminCheckTable:
+		if !b.checkNoView(as.Tables, "ADMIN CHECK TABLE") {
+			return nil
+		}
 		p = &CheckTable{Tables: as.Tables}
 		p.SetSchema(expression.NewSchema())
 	case ast.AdminShowDDL:
@@ -452,6 +534,9 @@ func (b *planBuilder) buildAnalyzeIndex(as *ast.AnalyzeTableStmt) Plan {
 }
 
 func (b *planBuilder) buildAnalyze(as *ast.AnalyzeTableStmt) Plan {
+	if !b.checkNoView(as.TableNames, "ANALYZE") {
+		return nil
+	}
 	if len(as.IndexNames) == 0 {
 		return b.buildAnalyzeTable(as)
 	}
@@ -530,8 +615,12 @@ func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
 		Flag:   show.Flag,
 		Full:   show.Full,
 		User:   show.User,
+		Roles:  show.Roles,
 	}.init(b.allocator, b.ctx)
 	resultPlan = p
+	if show.Tp == ast.ShowCreateView {
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.ShowViewPriv, show.Table.Schema.L, show.Table.Name.L, "")
+	}
 	switch show.Tp {
 	case ast.ShowProcedureStatus:
 		p.SetSchema(buildShowProcedureSchema())
@@ -585,8 +674,16 @@ func (b *planBuilder) buildSimple(node ast.StmtNode) Plan {
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.CreateUserPriv, "", "", "")
 	case *ast.GrantStmt:
 		b.visitInfo = collectVisitInfoFromGrantStmt(b.visitInfo, raw)
-	case *ast.SetPwdStmt, *ast.RevokeStmt, *ast.KillStmt:
+	case *ast.SetPwdStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.GrantRoleStmt, *ast.RevokeRoleStmt:
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case *ast.SetDefaultRoleStmt:
+		// Users can set their own default roles from the roles granted to them.
+		for _, user := range raw.UserList {
+			if !isCurrentUser(b.ctx, user) {
+				b.visitInfo = appendVisitInfo(b.visitInfo, mysql.CreateUserPriv, "", "", "")
+				break
+			}
+		}
 	}
 	return p
 }
@@ -688,6 +785,9 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		b.err = infoschema.ErrTableNotExists.GenByArgs()
 		return nil
 	}
+	if !b.checkNoView([]*ast.TableName{tn}, "INSERT") {
+		return nil
+	}
 	tableInfo := tn.TableInfo
 	// Build Schema with DBName otherwise ColumnRef with DBName cannot match any Column in Schema.
 	schema := expression.TableInfo2SchemaWithDBName(tn.Schema, tableInfo)
@@ -878,6 +978,9 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 }
 
 func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
+	if !b.checkNoView([]*ast.TableName{ld.Table}, "LOAD") {
+		return nil
+	}
 	p := &LoadData{
 		IsLocal:    ld.IsLocal,
 		Path:       ld.Path,
@@ -914,6 +1017,22 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 			privilege: mysql.CreatePriv,
 			db:        v.Name,
 		})
+	case *ast.CreateViewStmt:
+		cols, selectCols := b.buildViewColumns(v)
+		if b.err != nil {
+			return nil
+		}
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.CreateViewPriv, v.ViewName.Schema.L, v.ViewName.Name.L, "")
+		if v.OrReplace || v.IsAlter {
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DropPriv, v.ViewName.Schema.L, v.ViewName.Name.L, "")
+		}
+		// Only the users with the SUPER privilege can create views for other users.
+		if v.Definer != nil && !isCurrentUser(b.ctx, v.Definer) {
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+		}
+		p := &DDL{Statement: node, ViewCols: cols, ViewSelectCols: selectCols}
+		p.SetSchema(expression.NewSchema())
+		return p
 	case *ast.CreateIndexStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
 			privilege: mysql.IndexPriv,
@@ -976,6 +1095,22 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 	return p
 }
 
+func (b *planBuilder) buildSelectInto(sel *ast.SelectStmt) Plan {
+	// Writing files on the server host requires the FILE privilege.
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.FilePriv, "", "", "")
+	intoOpt := sel.SelectIntoOpt
+	sel.SelectIntoOpt = nil
+	targetPlan, err := Optimize(b.ctx, sel, b.is)
+	sel.SelectIntoOpt = intoOpt
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	p := &SelectInto{TargetPlan: targetPlan, IntoOpt: intoOpt}
+	p.SetSchema(expression.NewSchema())
+	return p
+}
+
 func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 	if show, ok := explain.Stmt.(*ast.ShowStmt); ok {
 		return b.buildShow(show)
@@ -1130,6 +1265,8 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema) {
 		names = []string{"Table", "Create Table"}
 	case ast.ShowCreateDatabase:
 		names = []string{"Database", "Create Database"}
+	case ast.ShowCreateView:
+		names = []string{"View", "Create View", "character_set_client", "collation_connection"}
 	case ast.ShowGrants:
 		names = []string{fmt.Sprintf("Grants for %s", s.User)}
 	case ast.ShowIndex:
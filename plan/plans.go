@@ -91,7 +91,8 @@ type Show struct {
 	Column *ast.ColumnName // Used for `desc table column`.
 	Flag   int             // Some flag parsed from sql, such as FULL.
 	Full   bool
-	User   *auth.UserIdentity // Used for show grants.
+	User   *auth.UserIdentity   // Used for show grants.
+	Roles  []*auth.RoleIdentity // Used for show grants using roles.
 
 	// Used by show variables
 	GlobalScope bool
@@ -179,11 +180,23 @@ type LoadData struct {
 	GenCols InsertGeneratedColumns
 }
 
+// SelectInto represents a SELECT ... INTO OUTFILE plan.
+type SelectInto struct {
+	basePlan
+
+	TargetPlan Plan
+	IntoOpt    *ast.SelectIntoOption
+}
+
 // DDL represents a DDL statement plan.
 type DDL struct {
 	basePlan
 
 	Statement ast.DDLNode
+	// ViewCols are the columns of the view in a CREATE VIEW statement.
+	ViewCols []*model.ColumnInfo
+	// ViewSelectCols are the names of the columns selected for ViewCols.
+	ViewSelectCols []model.CIStr
 }
 
 // Explain represents a explain plan.
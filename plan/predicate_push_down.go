@@ -66,6 +66,24 @@ func (p *TableDual) PredicatePushDown(predicates []expression.Expression) ([]exp
 	return predicates, p, nil
 }
 
+// PredicatePushDown implements LogicalPlan PredicatePushDown interface.
+func (p *CTEWorkTable) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
+	return predicates, p, nil
+}
+
+// PredicatePushDown implements LogicalPlan PredicatePushDown interface.
+// The predicates can't be pushed into a recursive common table expression, because they would also filter the rows
+// fed to the next iteration.
+func (p *RecursiveCTE) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
+	for _, child := range p.children {
+		_, _, err := child.(LogicalPlan).PredicatePushDown(nil)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	return predicates, p, nil
+}
+
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *LogicalJoin) PredicatePushDown(predicates []expression.Expression) (ret []expression.Expression, retPlan LogicalPlan, err error) {
 	err = outerJoinSimplify(p, predicates)
@@ -344,6 +362,12 @@ func (p *Projection) PredicatePushDown(predicates []expression.Expression) (ret
 		}
 	}
 	child := p.children[0].(LogicalPlan)
+	if ds, ok := child.(*DataSource); ok && p.calculateGenCols && UseDAGPlanBuilder(p.ctx) {
+		ds.virtualColConds = nil
+		for _, cond := range ret {
+			ds.virtualColConds = append(ds.virtualColConds, cond.Clone())
+		}
+	}
 	restConds, _, err1 := child.PredicatePushDown(push)
 	if err1 != nil {
 		return nil, nil, errors.Trace(err1)
@@ -403,7 +427,9 @@ func (p *LogicalAggregation) PredicatePushDown(predicates []expression.Expressio
 			ret = append(ret, cond)
 		case *expression.ScalarFunction:
 			extractedCols := expression.ExtractColumns(cond)
-			ok := true
+			// The conditions on the group-by columns also filter the super-aggregate rows of the grouping sets,
+			// so they can't be pushed down.
+			ok := len(p.GroupingSets) == 0
 			for _, col := range extractedCols {
 				if p.getGbyColIndex(col) == -1 {
 					ok = false
@@ -431,6 +457,13 @@ func (p *Limit) PredicatePushDown(predicates []expression.Expression) ([]express
 	return predicates, p, errors.Trace(err)
 }
 
+// PredicatePushDown implements LogicalPlan PredicatePushDown interface.
+func (p *LogicalWindow) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
+	// The result of a window function depends on all the rows of its partition, so we can't push down any condition.
+	_, _, err := p.baseLogicalPlan.PredicatePushDown(nil)
+	return predicates, p, errors.Trace(err)
+}
+
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *MaxOneRow) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
 	// MaxOneRow forbids any condition to push down.
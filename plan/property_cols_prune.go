@@ -26,7 +26,7 @@ func (p *DataSource) preparePossibleProperties() (result [][]*expression.Column)
 		}
 	}
 	for _, idx := range indices {
-		cols, _ := expression.IndexInfo2Cols(p.schema.Columns, idx)
+		cols, _ := expression.IndexInfo2Cols(p.schema.Columns, sortedIndex(p.tableInfo, idx))
 		if len(cols) > 0 {
 			result = append(result, cols)
 		}
@@ -15,6 +15,7 @@ package plan
 
 import (
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/expression/aggregation"
 	"github.com/pingcap/tidb/model"
 )
 
@@ -193,6 +194,30 @@ func (p *PhysicalAggregation) ResolveIndices() {
 	}
 }
 
+// ResolveIndices implements Plan interface.
+func (p *LogicalWindow) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	resolveWindowIndices(p.children[0].Schema(), p.WindowFuncDesc, p.PartitionBy, p.OrderBy)
+}
+
+// ResolveIndices implements Plan interface.
+func (p *PhysicalWindow) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	resolveWindowIndices(p.children[0].Schema(), p.WindowFuncDesc, p.PartitionBy, p.OrderBy)
+}
+
+func resolveWindowIndices(schema *expression.Schema, desc *aggregation.WindowFuncDesc, partitionBy []expression.Expression, orderBy []*ByItems) {
+	for _, arg := range desc.Args {
+		arg.ResolveIndices(schema)
+	}
+	for _, item := range partitionBy {
+		item.ResolveIndices(schema)
+	}
+	for _, item := range orderBy {
+		item.Expr.ResolveIndices(schema)
+	}
+}
+
 // ResolveIndices implements Plan interface.
 func (p *Sort) ResolveIndices() {
 	p.basePlan.ResolveIndices()
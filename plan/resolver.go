@@ -80,6 +80,8 @@ type resolverContext struct {
 	tableMap map[string]int
 	// table map to lookup and check derived-table(subselect) name conflict.
 	derivedTableMap map[string]int
+	// common table expressions defined in the WITH clause.
+	cteMap map[string]*ast.CommonTableExpression
 	// tableSources collected in from clause.
 	tables []*ast.TableSource
 	// result fields collected in select field list.
@@ -109,6 +111,9 @@ type resolverContext struct {
 	inOrderBy bool
 	// When visiting column name in ByItem, we should know if the column name is in an expression.
 	inByItemExpression bool
+	// The by items of a window spec would overwrite inOrderBy and inByItemExpression,
+	// so we save them when entering a window function and restore them when leaving.
+	windowFuncStack []windowFuncState
 	// If subquery use outer context.
 	useOuterContext bool
 	// When visiting multi-table delete stmt table list.
@@ -121,6 +126,12 @@ type resolverContext struct {
 	inColumnOption bool
 }
 
+// windowFuncState stores the states of resolverContext which are changed by the window spec.
+type windowFuncState struct {
+	inOrderBy          bool
+	inByItemExpression bool
+}
+
 // currentContext gets the current resolverContext.
 func (nr *nameResolver) currentContext() *resolverContext {
 	stackLen := len(nr.contextStack)
@@ -165,6 +176,12 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		if ctx.inHaving {
 			ctx.inHavingAgg = true
 		}
+	case *ast.WindowFuncExpr:
+		ctx := nr.currentContext()
+		ctx.windowFuncStack = append(ctx.windowFuncStack, windowFuncState{
+			inOrderBy:          ctx.inOrderBy,
+			inByItemExpression: ctx.inByItemExpression,
+		})
 	case *ast.AlterTableStmt:
 		nr.pushContext()
 		for _, spec := range v.Specs {
@@ -195,6 +212,9 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 	case *ast.CreateTableStmt:
 		nr.pushContext()
 		nr.currentContext().inCreateOrDropTable = true
+	case *ast.CreateViewStmt:
+		nr.pushContext()
+		nr.currentContext().inCreateOrDropTable = true
 	case *ast.ColumnOption:
 		nr.currentContext().inColumnOption = true
 	case *ast.DeleteStmt:
@@ -249,6 +269,9 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		nr.pushContext()
 	case *ast.UpdateStmt:
 		nr.pushContext()
+	case *ast.WithClause:
+		nr.handleWithClause(v)
+		return inNode, true
 	}
 	return inNode, false
 }
@@ -263,6 +286,11 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		if ctx.inHaving {
 			ctx.inHavingAgg = false
 		}
+	case *ast.WindowFuncExpr:
+		ctx := nr.currentContext()
+		state := ctx.windowFuncStack[len(ctx.windowFuncStack)-1]
+		ctx.windowFuncStack = ctx.windowFuncStack[:len(ctx.windowFuncStack)-1]
+		ctx.inOrderBy, ctx.inByItemExpression = state.inOrderBy, state.inByItemExpression
 	case *ast.AlterTableStmt:
 		nr.popContext()
 	case *ast.AnalyzeTableStmt:
@@ -277,6 +305,8 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.popContext()
 	case *ast.CreateTableStmt:
 		nr.popContext()
+	case *ast.CreateViewStmt:
+		nr.popContext()
 	case *ast.ColumnOption:
 		nr.currentContext().inColumnOption = false
 	case *ast.DeleteTableList:
@@ -361,15 +391,20 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 
 // handleTableName looks up and sets the schema information and result fields for table name.
 func (nr *nameResolver) handleTableName(tn *ast.TableName) {
+	ctx := nr.currentContext()
+	if tn.Schema.L == "" && !ctx.inCreateOrDropTable {
+		if cte := nr.findCTE(tn.Name); cte != nil {
+			nr.handleCTEName(tn, cte)
+			return
+		}
+	}
 	if tn.Schema.L == "" {
-		sessionVars := nr.Ctx.GetSessionVars()
-		if sessionVars.CurrentDB == "" {
+		if nr.DefaultSchema.L == "" {
 			nr.Err = errors.Trace(ErrNoDB)
 			return
 		}
 		tn.Schema = nr.DefaultSchema
 	}
-	ctx := nr.currentContext()
 	if ctx.inCreateOrDropTable {
 		// The table may not exist in create table or drop table statement.
 		// Skip resolving the table to avoid error.
@@ -430,6 +465,96 @@ func (nr *nameResolver) handleTableName(tn *ast.TableName) {
 	tn.SetResultFields(rfs)
 }
 
+// handleWithClause resolves the common table expressions in the WITH clause and puts them
+// in current resolverContext, so they can be referenced by the following common table
+// expressions and the statement.
+func (nr *nameResolver) handleWithClause(with *ast.WithClause) {
+	ctx := nr.currentContext()
+	if ctx.cteMap == nil {
+		ctx.cteMap = make(map[string]*ast.CommonTableExpression, len(with.CTEs))
+	}
+	for _, cte := range with.CTEs {
+		if _, ok := ctx.cteMap[cte.Name.L]; ok {
+			nr.Err = ErrNonUniqTable.GenByArgs(cte.Name.O)
+			return
+		}
+		if with.IsRecursive {
+			// A recursive common table expression can be referenced in its own query.
+			ctx.cteMap[cte.Name.L] = cte
+		}
+		cte.Query.Accept(nr)
+		if nr.Err != nil {
+			return
+		}
+		if len(cte.ColNameList) > 0 && len(cte.ColNameList) != len(cte.Query.GetResultFields()) {
+			nr.Err = ErrViewWrongList
+			return
+		}
+		ctx.cteMap[cte.Name.L] = cte
+	}
+}
+
+// findCTE looks up the common table expression from top to bottom in the context stack.
+func (nr *nameResolver) findCTE(name model.CIStr) *ast.CommonTableExpression {
+	for i := len(nr.contextStack) - 1; i >= 0; i-- {
+		if cte, ok := nr.contextStack[i].cteMap[name.L]; ok {
+			return cte
+		}
+	}
+	return nil
+}
+
+// handleCTEName sets the result fields for a table name which refers to a common table expression.
+func (nr *nameResolver) handleCTEName(tn *ast.TableName, cte *ast.CommonTableExpression) {
+	tn.CTE = cte
+	rfs := cte.Query.GetResultFields()
+	if rfs == nil {
+		// The query is being resolved, so this is a recursive reference,
+		// the result fields come from the first non-recursive select.
+		cte.IsRecursive = true
+		union, ok := cte.Query.(*ast.UnionStmt)
+		if !ok {
+			nr.Err = ErrCTERecursiveRequiresUnion.GenByArgs(cte.Name.O)
+			return
+		}
+		rfs = union.SelectList.Selects[0].GetResultFields()
+		if rfs == nil {
+			nr.Err = ErrCTERecursiveRequiresNonRecursiveFirst.GenByArgs(cte.Name.O)
+			return
+		}
+	}
+	if len(cte.ColNameList) > 0 && len(cte.ColNameList) != len(rfs) {
+		nr.Err = ErrViewWrongList
+		return
+	}
+	tblInfo := &model.TableInfo{Name: cte.Name}
+	cteRfs := make([]*ast.ResultField, 0, len(rfs))
+	tmp := make([]struct {
+		ast.ValueExpr
+		ast.ResultField
+	}, len(rfs))
+	for i, v := range rfs {
+		col := *v.Column
+		col.Offset = i
+		if len(cte.ColNameList) > 0 {
+			col.Name = cte.ColNameList[i]
+		} else if v.ColumnAsName.L != "" {
+			col.Name = v.ColumnAsName
+		}
+		expr := &tmp[i].ValueExpr
+		rf := &tmp[i].ResultField
+		expr.SetType(&col.FieldType)
+		*rf = ast.ResultField{
+			Column:    &col,
+			Table:     tblInfo,
+			Expr:      expr,
+			TableName: tn,
+		}
+		cteRfs = append(cteRfs, rf)
+	}
+	tn.SetResultFields(cteRfs)
+}
+
 // handleTableSources checks name duplication
 // and puts the table source in current resolverContext.
 // Note:
@@ -962,6 +1087,8 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 		names = []string{"Table", "Create Table"}
 	case ast.ShowCreateDatabase:
 		names = []string{"Database", "Create Database"}
+	case ast.ShowCreateView:
+		names = []string{"View", "Create View", "character_set_client", "collation_connection"}
 	case ast.ShowGrants:
 		names = []string{fmt.Sprintf("Grants for %s", s.User)}
 	case ast.ShowTriggers:
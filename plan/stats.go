@@ -112,6 +112,21 @@ func (p *Union) prepareStatsProfile() *statsProfile {
 	return p.profile
 }
 
+func (p *RecursiveCTE) prepareStatsProfile() *statsProfile {
+	p.profile = &statsProfile{
+		cardinality: make([]float64, p.schema.Len()),
+	}
+	// The number of iterations is unknown, so we simply add up the profiles of the seed part and the recursive part.
+	for _, child := range p.children {
+		childProfile := child.(LogicalPlan).prepareStatsProfile()
+		p.profile.count += childProfile.count
+		for i := range p.profile.cardinality {
+			p.profile.cardinality[i] += childProfile.cardinality[i]
+		}
+	}
+	return p.profile
+}
+
 func (p *Limit) prepareStatsProfile() *statsProfile {
 	childProfile := p.children[0].(LogicalPlan).prepareStatsProfile()
 	p.profile = &statsProfile{
@@ -145,6 +160,7 @@ func (p *TopN) prepareStatsProfile() *statsProfile {
 			p.profile.cardinality[i] = p.profile.count
 		}
 	}
+	p.inputCount = childProfile.count
 	return p.profile
 }
 
@@ -179,6 +195,18 @@ func (p *Projection) prepareStatsProfile() *statsProfile {
 	return p.profile
 }
 
+// The window function doesn't change the row count, and we simply regard its result column as unique.
+func (p *LogicalWindow) prepareStatsProfile() *statsProfile {
+	childProfile := p.children[0].(LogicalPlan).prepareStatsProfile()
+	p.profile = &statsProfile{
+		count:       childProfile.count,
+		cardinality: make([]float64, 0, p.schema.Len()),
+	}
+	p.profile.cardinality = append(p.profile.cardinality, childProfile.cardinality...)
+	p.profile.cardinality = append(p.profile.cardinality, childProfile.count)
+	return p.profile
+}
+
 func (p *LogicalAggregation) prepareStatsProfile() *statsProfile {
 	childProfile := p.children[0].(LogicalPlan).prepareStatsProfile()
 	var gbyCols []*expression.Column
@@ -26,7 +26,7 @@ func ToString(p Plan) string {
 
 func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 	switch in.(type) {
-	case *LogicalJoin, *Union, *PhysicalHashJoin, *PhysicalHashSemiJoin, *LogicalApply, *PhysicalApply, *PhysicalMergeJoin, *PhysicalIndexJoin:
+	case *LogicalJoin, *Union, *RecursiveCTE, *PhysicalHashJoin, *PhysicalHashSemiJoin, *LogicalApply, *PhysicalApply, *PhysicalMergeJoin, *PhysicalIndexJoin:
 		idxs = append(idxs, len(strs))
 	}
 
@@ -129,6 +129,15 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		strs = strs[:idx]
 		str = "UnionAll{" + strings.Join(children, "->") + "}"
 		idxs = idxs[:last]
+	case *RecursiveCTE:
+		last := len(idxs) - 1
+		idx := idxs[last]
+		children := strs[idx:]
+		strs = strs[:idx]
+		str = "RecursiveCTE{" + strings.Join(children, "->") + "}"
+		idxs = idxs[:last]
+	case *CTEWorkTable:
+		str = "CTEWorkTable"
 	case *DataSource:
 		if x.TableAsName != nil && x.TableAsName.L != "" {
 			str = fmt.Sprintf("DataScan(%s)", x.TableAsName)
@@ -162,6 +171,10 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 			}
 		}
 		str += ")"
+	case *LogicalWindow:
+		str = fmt.Sprintf("Window(%s)", x.WindowFuncDesc)
+	case *PhysicalWindow:
+		str = fmt.Sprintf("Window(%s)", x.WindowFuncDesc)
 	case *Cache:
 		str = "Cache"
 	case *PhysicalTableReader:
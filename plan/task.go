@@ -311,6 +311,21 @@ func (p *TopN) getCost(count float64) float64 {
 	return count*cpuFactor + float64(p.Count)*memoryFactor
 }
 
+// exceedSortMemQuota checks whether buffering count rows of the schema is estimated to exceed the
+// sort memory quota or the memory quota of a statement. The row size is estimated by the column types.
+func exceedSortMemQuota(ctx context.Context, count float64, schema *expression.Schema) bool {
+	var rowSize int64
+	for _, col := range schema.Columns {
+		rowSize += types.EstimatedMemUsageOfType(col.RetType)
+	}
+	vars := ctx.GetSessionVars()
+	quota := vars.MemQuotaSort
+	if vars.MemQuotaQuery < quota {
+		quota = vars.MemQuotaQuery
+	}
+	return count*float64(rowSize) > float64(quota)
+}
+
 // canPushDown checks if this topN can be pushed down. If each of the expression can be converted to pb, it can be pushed.
 func (p *TopN) canPushDown() bool {
 	exprs := make([]expression.Expression, 0, len(p.ByItems))
@@ -331,7 +346,9 @@ func (p *TopN) allColsFromSchema(schema *expression.Schema) bool {
 
 func (p *Sort) attach2Task(tasks ...task) task {
 	t := tasks[0].copy()
-	t = attachPlan2Task(p.Copy(), t)
+	sort := p.Copy().(*Sort)
+	sort.spill = exceedSortMemQuota(p.ctx, t.count(), p.schema)
+	t = attachPlan2Task(sort, t)
 	t.addCost(p.getCost(t.count()))
 	return t
 }
@@ -370,7 +387,9 @@ func (p *TopN) attach2Task(tasks ...task) task {
 	}
 	t = finishCopTask(t, p.ctx, p.allocator)
 	if !p.partial {
-		t = attachPlan2Task(p.Copy(), t)
+		topN := p.Copy().(*TopN)
+		topN.spill = exceedSortMemQuota(p.ctx, math.Min(p.inputCount, float64(p.Offset+p.Count)), p.schema)
+		t = attachPlan2Task(topN, t)
 		t.addCost(p.getCost(t.count()))
 	}
 	return t
@@ -404,6 +423,19 @@ func (p *Union) attach2Task(tasks ...task) task {
 	return newTask
 }
 
+func (p *RecursiveCTE) attach2Task(tasks ...task) task {
+	np := p.Copy()
+	newTask := &rootTask{p: np}
+	newChildren := make([]Plan, 0, len(p.children))
+	for _, task := range tasks {
+		task = finishCopTask(task, p.ctx, p.allocator)
+		newTask.cst += task.cost()
+		newChildren = append(newChildren, task.plan())
+	}
+	np.SetChildren(newChildren...)
+	return newTask
+}
+
 func (sel *Selection) attach2Task(tasks ...task) task {
 	t := finishCopTask(tasks[0].copy(), sel.ctx, sel.allocator)
 	t.addCost(t.count() * cpuFactor)
@@ -427,6 +459,8 @@ func (p *PhysicalAggregation) newPartialAggregate() (partialAgg, finalAgg *Physi
 		return
 	}
 	partialAgg = p.Copy().(*PhysicalAggregation)
+	// The partial aggregation groups by the base grouping set, and the final aggregation aggregates the grouping sets.
+	partialAgg.GroupingSets = nil
 	// TODO: It's toooooo ugly here. Refactor in the future !!
 	gkType := types.NewFieldType(mysql.TypeBlob)
 	gkType.Charset = charset.CharsetBin
@@ -459,9 +493,10 @@ func (p *PhysicalAggregation) newPartialAggregate() (partialAgg, finalAgg *Physi
 		finalAggFuncs[i] = fun
 	}
 	finalAgg = PhysicalAggregation{
-		HasGby:   p.HasGby, // TODO: remove this field
-		AggType:  FinalAgg,
-		AggFuncs: finalAggFuncs,
+		HasGby:       p.HasGby, // TODO: remove this field
+		AggType:      FinalAgg,
+		AggFuncs:     finalAggFuncs,
+		GroupingSets: p.GroupingSets,
 	}.init(p.allocator, p.ctx)
 	finalAgg.profile = p.profile
 	finalAgg.SetSchema(p.schema)
@@ -470,6 +505,7 @@ func (p *PhysicalAggregation) newPartialAggregate() (partialAgg, finalAgg *Physi
 		gbyCol := &expression.Column{
 			FromID:   partialAgg.id,
 			Position: cursor + i,
+			ColName:  model.NewCIStr(fmt.Sprintf("col_%d", cursor+i)),
 			RetType:  gbyExpr.GetType(),
 		}
 		partialSchema.Append(gbyCol)
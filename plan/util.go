@@ -45,3 +45,40 @@ func (a *AggregateFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
 	}
 	return n, true
 }
+
+// WindowFuncExtractor visits Expr tree.
+// It collects WindowFuncExprs, and reports an error if a window function is nested in another one.
+type WindowFuncExtractor struct {
+	inWindowFuncExpr bool
+	// WindowFuncs is the collected WindowFuncExprs.
+	WindowFuncs []*ast.WindowFuncExpr
+	err         error
+}
+
+// Enter implements Visitor interface.
+func (a *WindowFuncExtractor) Enter(n ast.Node) (ast.Node, bool) {
+	switch v := n.(type) {
+	case *ast.WindowFuncExpr:
+		if a.inWindowFuncExpr {
+			a.err = ErrWindowInvalidWindowFuncUse.GenByArgs(v.F)
+			return n, true
+		}
+		a.inWindowFuncExpr = true
+	case *ast.SelectStmt, *ast.UnionStmt:
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (a *WindowFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
+	switch v := n.(type) {
+	case *ast.WindowFuncExpr:
+		if a.err != nil {
+			return n, false
+		}
+		a.inWindowFuncExpr = false
+		a.WindowFuncs = append(a.WindowFuncs, v)
+	}
+	return n, true
+}
@@ -28,18 +28,32 @@ func (k keyType) String() string {
 
 // Manager is the interface for providing privilege related operations.
 type Manager interface {
-	// ShowGrants shows granted privileges for user.
-	ShowGrants(ctx context.Context, user *auth.UserIdentity) ([]string, error)
+	// ShowGrants shows granted privileges for user, the privileges of roles are merged in.
+	ShowGrants(ctx context.Context, user *auth.UserIdentity, roles []*auth.RoleIdentity) ([]string, error)
 
 	// RequestVerification verifies user privilege for the request.
+	// The privileges of activeRoles are taken into account.
 	// If table is "", only check global/db scope privileges.
 	// If table is not "", check global/db/table scope privileges.
-	RequestVerification(db, table, column string, priv mysql.PrivilegeType) bool
+	RequestVerification(activeRoles []*auth.RoleIdentity, db, table, column string, priv mysql.PrivilegeType) bool
+	// RequestVerificationWithUser verifies the privilege of another user for the request, such as the definer
+	// of a view. The default roles of the user are taken into account.
+	RequestVerificationWithUser(db, table, column string, priv mysql.PrivilegeType, user *auth.UserIdentity) bool
 	// ConnectionVerification verifies user privilege for connection.
 	ConnectionVerification(host, user string, auth, salt []byte) bool
 
-	// DBIsVisible returns true is the database is visible to current user.
-	DBIsVisible(db string) bool
+	// GetAuthPlugin returns the authentication plugin of the account the user connects as,
+	// it returns "" if there is no such account.
+	GetAuthPlugin(user, host string) string
+
+	// DBIsVisible returns true is the database is visible to current user or any of the active roles.
+	DBIsVisible(activeRoles []*auth.RoleIdentity, db string) bool
+
+	// GetDefaultRoles returns the default roles of the user.
+	GetDefaultRoles(user, host string) []*auth.RoleIdentity
+
+	// GetAllRoles returns all the roles granted to the user.
+	GetAllRoles(user, host string) []*auth.RoleIdentity
 
 	// UserPrivilegesTable provide data for INFORMATION_SCHEMA.USERS_PRIVILEGE table.
 	UserPrivilegesTable() [][]types.Datum
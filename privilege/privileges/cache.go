@@ -16,6 +16,7 @@ package privileges
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/types"
@@ -50,6 +52,12 @@ type userRecord struct {
 	User       string // max length 16, primary key
 	Password   string // max length 41
 	Privileges mysql.PrivilegeType
+	// AccountLocked is true for roles, they can't be used to connect.
+	AccountLocked bool
+	// AuthPlugin is the authentication plugin of the user, the password of mysql_native_password
+	// is stored in Password, the password hash of the other plugins is stored in AuthString.
+	AuthPlugin string
+	AuthString string
 
 	// patChars is compiled from Host, cached for pattern match performance.
 	patChars []byte
@@ -96,12 +104,29 @@ type columnsPrivRecord struct {
 	patTypes []byte
 }
 
+// roleEdgeRecord means the role FromUser@FromHost is granted to the user ToUser@ToHost.
+type roleEdgeRecord struct {
+	FromHost string
+	FromUser string
+	ToHost   string
+	ToUser   string
+}
+
+type defaultRoleRecord struct {
+	Host            string
+	User            string
+	DefaultRoleHost string
+	DefaultRoleUser string
+}
+
 // MySQLPrivilege is the in-memory cache of mysql privilege tables.
 type MySQLPrivilege struct {
-	User        []userRecord
-	DB          []dbRecord
-	TablesPriv  []tablesPrivRecord
-	ColumnsPriv []columnsPrivRecord
+	User         []userRecord
+	DB           []dbRecord
+	TablesPriv   []tablesPrivRecord
+	ColumnsPriv  []columnsPrivRecord
+	RoleEdges    []roleEdgeRecord
+	DefaultRoles []defaultRoleRecord
 }
 
 // LoadAll loads the tables from database to memory.
@@ -134,6 +159,22 @@ func (p *MySQLPrivilege) LoadAll(ctx context.Context) error {
 		}
 		log.Warn("mysql.columns_priv missing")
 	}
+
+	err = p.LoadRoleEdgesTable(ctx)
+	if err != nil {
+		if !noSuchTable(err) {
+			return errors.Trace(err)
+		}
+		log.Warn("mysql.role_edges missing")
+	}
+
+	err = p.LoadDefaultRolesTable(ctx)
+	if err != nil {
+		if !noSuchTable(err) {
+			return errors.Trace(err)
+		}
+		log.Warn("mysql.default_roles missing")
+	}
 	return nil
 }
 
@@ -149,12 +190,12 @@ func noSuchTable(err error) bool {
 
 // LoadUserTable loads the mysql.user table from database.
 func (p *MySQLPrivilege) LoadUserTable(ctx context.Context) error {
-	return p.loadTable(ctx, "select Host,User,Password,Select_priv,Insert_priv,Update_priv,Delete_priv,Create_priv,Drop_priv,Process_priv,Grant_priv,References_priv,Alter_priv,Show_db_priv,Super_priv,Execute_priv,Index_priv,Create_user_priv,Trigger_priv from mysql.user order by host, user;", p.decodeUserTableRow)
+	return p.loadTable(ctx, "select Host,User,Password,Select_priv,Insert_priv,Update_priv,Delete_priv,Create_priv,Drop_priv,Process_priv,File_priv,Grant_priv,References_priv,Alter_priv,Show_db_priv,Super_priv,Execute_priv,Index_priv,Create_user_priv,Trigger_priv,Create_view_priv,Show_view_priv,Account_locked,plugin,authentication_string from mysql.user order by host, user;", p.decodeUserTableRow)
 }
 
 // LoadDBTable loads the mysql.db table from database.
 func (p *MySQLPrivilege) LoadDBTable(ctx context.Context) error {
-	return p.loadTable(ctx, "select Host,DB,User,Select_priv,Insert_priv,Update_priv,Delete_priv,Create_priv,Drop_priv,Grant_priv,Index_priv,Alter_priv,Execute_priv from mysql.db order by host, db, user;", p.decodeDBTableRow)
+	return p.loadTable(ctx, "select Host,DB,User,Select_priv,Insert_priv,Update_priv,Delete_priv,Create_priv,Drop_priv,Grant_priv,Index_priv,Alter_priv,Execute_priv,Create_view_priv,Show_view_priv from mysql.db order by host, db, user;", p.decodeDBTableRow)
 }
 
 // LoadTablesPrivTable loads the mysql.tables_priv table from database.
@@ -167,6 +208,16 @@ func (p *MySQLPrivilege) LoadColumnsPrivTable(ctx context.Context) error {
 	return p.loadTable(ctx, "select Host,DB,User,Table_name,Column_name,Timestamp,Column_priv from mysql.columns_priv", p.decodeColumnsPrivTableRow)
 }
 
+// LoadRoleEdgesTable loads the mysql.role_edges table from database.
+func (p *MySQLPrivilege) LoadRoleEdgesTable(ctx context.Context) error {
+	return p.loadTable(ctx, "select FROM_HOST,FROM_USER,TO_HOST,TO_USER from mysql.role_edges", p.decodeRoleEdgesTableRow)
+}
+
+// LoadDefaultRolesTable loads the mysql.default_roles table from database.
+func (p *MySQLPrivilege) LoadDefaultRolesTable(ctx context.Context) error {
+	return p.loadTable(ctx, "select HOST,USER,DEFAULT_ROLE_HOST,DEFAULT_ROLE_USER from mysql.default_roles", p.decodeDefaultRolesTableRow)
+}
+
 func (p *MySQLPrivilege) loadTable(ctx context.Context, sql string,
 	decodeTableRow func(*ast.Row, []*ast.ResultField) error) error {
 	tmp, err := ctx.(sqlexec.SQLExecutor).Execute(sql)
@@ -209,6 +260,12 @@ func (p *MySQLPrivilege) decodeUserTableRow(row *ast.Row, fs []*ast.ResultField)
 			value.patChars, value.patTypes = stringutil.CompilePattern(value.Host, '\\')
 		case f.ColumnAsName.L == "password":
 			value.Password = d.GetString()
+		case f.ColumnAsName.L == "account_locked":
+			value.AccountLocked = d.GetMysqlEnum().String() == "Y"
+		case f.ColumnAsName.L == "plugin":
+			value.AuthPlugin = d.GetString()
+		case f.ColumnAsName.L == "authentication_string":
+			value.AuthString = d.GetString()
 		case d.Kind() == types.KindMysqlEnum:
 			ed := d.GetMysqlEnum()
 			if ed.String() != "Y" {
@@ -307,6 +364,44 @@ func (p *MySQLPrivilege) decodeColumnsPrivTableRow(row *ast.Row, fs []*ast.Resul
 	return nil
 }
 
+func (p *MySQLPrivilege) decodeRoleEdgesTableRow(row *ast.Row, fs []*ast.ResultField) error {
+	var value roleEdgeRecord
+	for i, f := range fs {
+		d := row.Data[i]
+		switch f.ColumnAsName.L {
+		case "from_host":
+			value.FromHost = d.GetString()
+		case "from_user":
+			value.FromUser = d.GetString()
+		case "to_host":
+			value.ToHost = d.GetString()
+		case "to_user":
+			value.ToUser = d.GetString()
+		}
+	}
+	p.RoleEdges = append(p.RoleEdges, value)
+	return nil
+}
+
+func (p *MySQLPrivilege) decodeDefaultRolesTableRow(row *ast.Row, fs []*ast.ResultField) error {
+	var value defaultRoleRecord
+	for i, f := range fs {
+		d := row.Data[i]
+		switch f.ColumnAsName.L {
+		case "host":
+			value.Host = d.GetString()
+		case "user":
+			value.User = d.GetString()
+		case "default_role_host":
+			value.DefaultRoleHost = d.GetString()
+		case "default_role_user":
+			value.DefaultRoleUser = d.GetString()
+		}
+	}
+	p.DefaultRoles = append(p.DefaultRoles, value)
+	return nil
+}
+
 func decodeSetToPrivilege(s types.Set) mysql.PrivilegeType {
 	var ret mysql.PrivilegeType
 	if s.Name == "" {
@@ -351,10 +446,14 @@ func patternMatch(str string, patChars, patTypes []byte) bool {
 }
 
 // connectionVerification verifies the connection have access to TiDB server.
+// The locked accounts, such as roles, can't be used to connect.
 func (p *MySQLPrivilege) connectionVerification(user, host string) *userRecord {
 	for i := 0; i < len(p.User); i++ {
 		record := &p.User[i]
 		if record.match(user, host) {
+			if record.AccountLocked {
+				return nil
+			}
 			return record
 		}
 	}
@@ -401,8 +500,20 @@ func (p *MySQLPrivilege) matchColumns(user, host, db, table, column string) *col
 	return nil
 }
 
-// RequestVerification checks whether the user have sufficient privileges to do the operation.
-func (p *MySQLPrivilege) RequestVerification(user, host, db, table, column string, priv mysql.PrivilegeType) bool {
+// RequestVerification checks whether the user or any of the active roles have sufficient privileges to do the operation.
+func (p *MySQLPrivilege) RequestVerification(activeRoles []*auth.RoleIdentity, user, host, db, table, column string, priv mysql.PrivilegeType) bool {
+	if p.requestVerification(user, host, db, table, column, priv) {
+		return true
+	}
+	for _, role := range p.findAllRoles(activeRoles) {
+		if p.requestVerification(role.Username, role.Hostname, db, table, column, priv) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *MySQLPrivilege) requestVerification(user, host, db, table, column string, priv mysql.PrivilegeType) bool {
 	record1 := p.matchUser(user, host)
 	if record1 != nil && record1.Privileges&priv > 0 {
 		return true
@@ -431,8 +542,20 @@ func (p *MySQLPrivilege) RequestVerification(user, host, db, table, column strin
 	return false
 }
 
-// DBIsVisible checks whether the user can see the db.
-func (p *MySQLPrivilege) DBIsVisible(user, host, db string) bool {
+// DBIsVisible checks whether the user or any of the active roles can see the db.
+func (p *MySQLPrivilege) DBIsVisible(activeRoles []*auth.RoleIdentity, user, host, db string) bool {
+	if p.dbIsVisible(user, host, db) {
+		return true
+	}
+	for _, role := range p.findAllRoles(activeRoles) {
+		if p.dbIsVisible(role.Username, role.Hostname, db) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *MySQLPrivilege) dbIsVisible(user, host, db string) bool {
 	if record := p.matchUser(user, host); record != nil {
 		if record.Privileges != 0 {
 			return true
@@ -473,38 +596,155 @@ func (p *MySQLPrivilege) DBIsVisible(user, host, db string) bool {
 	return false
 }
 
-func (p *MySQLPrivilege) showGrants(user, host string) []string {
+// showGrants shows the grants of the account user@host, the privileges of roles are merged into the privileges of the account.
+func (p *MySQLPrivilege) showGrants(user, host string, roles []*auth.RoleIdentity) []string {
 	var gs []string
+	roles = p.findAllRoles(roles)
+	isGrantee := func(u, h string) bool {
+		if u == user && h == host {
+			return true
+		}
+		for _, role := range roles {
+			if u == role.Username && h == role.Hostname {
+				return true
+			}
+		}
+		return false
+	}
+
 	// Show global grants
+	var (
+		userFound  bool
+		globalPriv mysql.PrivilegeType
+	)
 	for _, record := range p.User {
 		if record.User == user && record.Host == host {
-			g := userPrivToString(record.Privileges)
-			s := fmt.Sprintf(`GRANT %s ON *.* TO '%s'@'%s'`, g, record.User, record.Host)
-			gs = append(gs, s)
-			break // it's unique
+			userFound = true
+			globalPriv |= record.Privileges
+		} else if isGrantee(record.User, record.Host) {
+			globalPriv |= record.Privileges
+		}
+	}
+	if userFound {
+		g := userPrivToString(globalPriv)
+		if len(g) == 0 {
+			g = "USAGE"
 		}
+		s := fmt.Sprintf(`GRANT %s ON *.* TO '%s'@'%s'`, g, user, host)
+		gs = append(gs, s)
 	}
 
 	// Show db scope grants
+	var dbs []string
+	dbPrivs := make(map[string]mysql.PrivilegeType)
 	for _, record := range p.DB {
-		if record.User == user && record.Host == host {
-			g := dbPrivToString(record.Privileges)
-			s := fmt.Sprintf(`GRANT %s ON %s.* TO '%s'@'%s'`, g, record.DB, record.User, record.Host)
-			gs = append(gs, s)
+		if isGrantee(record.User, record.Host) {
+			if _, ok := dbPrivs[record.DB]; !ok {
+				dbs = append(dbs, record.DB)
+			}
+			dbPrivs[record.DB] |= record.Privileges
 		}
 	}
+	for _, db := range dbs {
+		g := dbPrivToString(dbPrivs[db])
+		s := fmt.Sprintf(`GRANT %s ON %s.* TO '%s'@'%s'`, g, db, user, host)
+		gs = append(gs, s)
+	}
 
 	// Show table scope grants
+	var tables []string
+	tablePrivs := make(map[string]mysql.PrivilegeType)
 	for _, record := range p.TablesPriv {
-		if record.User == user && record.Host == host {
-			g := tablePrivToString(record.TablePriv)
-			s := fmt.Sprintf(`GRANT %s ON %s.%s TO '%s'@'%s'`, g, record.DB, record.TableName, record.User, record.Host)
-			gs = append(gs, s)
+		if isGrantee(record.User, record.Host) {
+			table := fmt.Sprintf("%s.%s", record.DB, record.TableName)
+			if _, ok := tablePrivs[table]; !ok {
+				tables = append(tables, table)
+			}
+			tablePrivs[table] |= record.TablePriv
 		}
 	}
+	for _, table := range tables {
+		g := tablePrivToString(tablePrivs[table])
+		s := fmt.Sprintf(`GRANT %s ON %s TO '%s'@'%s'`, g, table, user, host)
+		gs = append(gs, s)
+	}
+
+	// Show the roles granted to the account.
+	var grantedRoles []string
+	for _, edge := range p.RoleEdges {
+		if edge.ToUser == user && edge.ToHost == host {
+			grantedRoles = append(grantedRoles, fmt.Sprintf("'%s'@'%s'", edge.FromUser, edge.FromHost))
+		}
+	}
+	if len(grantedRoles) > 0 {
+		s := fmt.Sprintf(`GRANT %s TO '%s'@'%s'`, strings.Join(grantedRoles, ","), user, host)
+		gs = append(gs, s)
+	}
 	return gs
 }
 
+// getAllRoles returns the roles granted to the account matching user@host, including the roles granted to them.
+func (p *MySQLPrivilege) getAllRoles(user, host string) []*auth.RoleIdentity {
+	record := p.matchUser(user, host)
+	if record == nil {
+		return nil
+	}
+	var roles []*auth.RoleIdentity
+	for _, role := range p.findAllRoles(p.grantedRoles(record.User, record.Host)) {
+		// The account itself is found if it's granted to its roles.
+		if role.Username != record.User || role.Hostname != record.Host {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// grantedRoles returns the roles granted to the account user@host directly.
+func (p *MySQLPrivilege) grantedRoles(user, host string) []*auth.RoleIdentity {
+	var roles []*auth.RoleIdentity
+	for _, edge := range p.RoleEdges {
+		if edge.ToUser == user && edge.ToHost == host {
+			roles = append(roles, &auth.RoleIdentity{Username: edge.FromUser, Hostname: edge.FromHost})
+		}
+	}
+	return roles
+}
+
+// findAllRoles returns the roles and the roles granted to them recursively. The grants of roles may have cycles,
+// every role is returned only once.
+func (p *MySQLPrivilege) findAllRoles(roles []*auth.RoleIdentity) []*auth.RoleIdentity {
+	var allRoles []*auth.RoleIdentity
+	visited := make(map[string]struct{}, len(roles))
+	queue := append([]*auth.RoleIdentity(nil), roles...)
+	for len(queue) > 0 {
+		role := queue[0]
+		queue = queue[1:]
+		key := role.Username + "@" + role.Hostname
+		if _, ok := visited[key]; ok {
+			continue
+		}
+		visited[key] = struct{}{}
+		allRoles = append(allRoles, role)
+		queue = append(queue, p.grantedRoles(role.Username, role.Hostname)...)
+	}
+	return allRoles
+}
+
+// getDefaultRoles returns the default roles of the account matching user@host.
+func (p *MySQLPrivilege) getDefaultRoles(user, host string) []*auth.RoleIdentity {
+	record := p.matchUser(user, host)
+	if record == nil {
+		return nil
+	}
+	var roles []*auth.RoleIdentity
+	for _, r := range p.DefaultRoles {
+		if r.User == record.User && r.Host == record.Host {
+			roles = append(roles, &auth.RoleIdentity{Username: r.DefaultRoleUser, Hostname: r.DefaultRoleHost})
+		}
+	}
+	return roles
+}
+
 func userPrivToString(privs mysql.PrivilegeType) string {
 	if privs == userTablePrivilegeMask {
 		return mysql.AllPrivilegeLiteral
@@ -576,6 +816,46 @@ func appendUserPrivilegesTableRow(rows [][]types.Datum, user userRecord) [][]typ
 // Handle wraps MySQLPrivilege providing thread safe access.
 type Handle struct {
 	priv atomic.Value
+
+	// sha2Cache caches SHA256(SHA256(password)) of the users authenticated by caching_sha2_password,
+	// so that the next connections of the users can be verified by the scramble in the fast authentication.
+	sha2Cache struct {
+		sync.Mutex
+		entries map[string]sha2CacheEntry
+	}
+}
+
+type sha2CacheEntry struct {
+	// authString is the password hash when the entry is added, the entry is stale if the password is changed.
+	authString string
+	digest     []byte
+}
+
+func (h *Handle) getSha2Digest(record *userRecord) []byte {
+	h.sha2Cache.Lock()
+	defer h.sha2Cache.Unlock()
+	entry, ok := h.sha2Cache.entries[record.User+"@"+record.Host]
+	if !ok || entry.authString != record.AuthString {
+		return nil
+	}
+	return entry.digest
+}
+
+func (h *Handle) setSha2Digest(record *userRecord, digest []byte) {
+	h.sha2Cache.Lock()
+	defer h.sha2Cache.Unlock()
+	if h.sha2Cache.entries == nil {
+		h.sha2Cache.entries = make(map[string]sha2CacheEntry)
+	}
+	h.sha2Cache.entries[record.User+"@"+record.Host] = sha2CacheEntry{authString: record.AuthString, digest: digest}
+}
+
+// EvictSha2Digest removes the cached caching_sha2_password digest of the user, it's called when the user
+// is dropped or its password or authentication plugin is changed.
+func (h *Handle) EvictSha2Digest(user, host string) {
+	h.sha2Cache.Lock()
+	defer h.sha2Cache.Unlock()
+	delete(h.sha2Cache.entries, user+"@"+host)
 }
 
 // NewHandle returns a Handle.
@@ -597,5 +877,24 @@ func (h *Handle) Update(ctx context.Context) error {
 	}
 
 	h.priv.Store(&priv)
+	h.evictStaleSha2Digests(&priv)
 	return nil
 }
+
+// evictStaleSha2Digests removes the cached digests of the users which are dropped, or whose password or
+// authentication plugin is changed in the reloaded privileges, maybe by another TiDB server.
+func (h *Handle) evictStaleSha2Digests(priv *MySQLPrivilege) {
+	authStrings := make(map[string]string, len(priv.User))
+	for _, record := range priv.User {
+		if record.AuthPlugin == mysql.AuthCachingSha2Password {
+			authStrings[record.User+"@"+record.Host] = record.AuthString
+		}
+	}
+	h.sha2Cache.Lock()
+	defer h.sha2Cache.Unlock()
+	for key, entry := range h.sha2Cache.entries {
+		if authString, ok := authStrings[key]; !ok || authString != entry.authString {
+			delete(h.sha2Cache.entries, key)
+		}
+	}
+}
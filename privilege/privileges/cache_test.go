@@ -87,6 +87,29 @@ func (s *testCacheSuite) TestLoadDBTable(c *C) {
 	c.Assert(p.DB[1].Privileges, Equals, mysql.DropPriv|mysql.GrantPriv|mysql.IndexPriv|mysql.AlterPriv|mysql.ExecutePriv)
 }
 
+func (s *testCacheSuite) TestLoadRoleEdgesTable(c *C) {
+	se, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	defer se.Close()
+	mustExec(c, se, "use mysql;")
+	mustExec(c, se, "truncate table role_edges;")
+	mustExec(c, se, "truncate table default_roles;")
+
+	mustExec(c, se, `INSERT INTO mysql.role_edges (FROM_HOST, FROM_USER, TO_HOST, TO_USER) VALUES ("%", "r1", "localhost", "u1"), ("%", "r2", "localhost", "u1")`)
+	mustExec(c, se, `INSERT INTO mysql.default_roles (HOST, USER, DEFAULT_ROLE_HOST, DEFAULT_ROLE_USER) VALUES ("localhost", "u1", "%", "r2")`)
+
+	var p privileges.MySQLPrivilege
+	err = p.LoadRoleEdgesTable(se)
+	c.Assert(err, IsNil)
+	c.Assert(p.RoleEdges, HasLen, 2)
+	c.Assert(p.RoleEdges[0].FromUser, Equals, "r1")
+	c.Assert(p.RoleEdges[1].ToUser, Equals, "u1")
+	err = p.LoadDefaultRolesTable(se)
+	c.Assert(err, IsNil)
+	c.Assert(p.DefaultRoles, HasLen, 1)
+	c.Assert(p.DefaultRoles[0].DefaultRoleUser, Equals, "r2")
+}
+
 func (s *testCacheSuite) TestLoadTablesPrivTable(c *C) {
 	se, err := tidb.CreateSession(s.store)
 	c.Assert(err, IsNil)
@@ -135,23 +158,23 @@ func (s *testCacheSuite) TestPatternMatch(c *C) {
 	defer se.Close()
 	mustExec(c, se, "USE MYSQL;")
 	mustExec(c, se, "TRUNCATE TABLE mysql.user")
-	mustExec(c, se, `INSERT INTO mysql.user VALUES ("10.0.%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`)
+	mustExec(c, se, `INSERT INTO mysql.user VALUES ("10.0.%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "N", "mysql_native_password", "")`)
 	var p privileges.MySQLPrivilege
 	err = p.LoadUserTable(se)
 	c.Assert(err, IsNil)
-	c.Assert(p.RequestVerification("root", "10.0.1", "test", "", "", mysql.SelectPriv), IsTrue)
-	c.Assert(p.RequestVerification("root", "10.0.1.118", "test", "", "", mysql.SelectPriv), IsTrue)
-	c.Assert(p.RequestVerification("root", "localhost", "test", "", "", mysql.SelectPriv), IsFalse)
-	c.Assert(p.RequestVerification("root", "127.0.0.1", "test", "", "", mysql.SelectPriv), IsFalse)
-	c.Assert(p.RequestVerification("root", "114.114.114.114", "test", "", "", mysql.SelectPriv), IsFalse)
+	c.Assert(p.RequestVerification(nil, "root", "10.0.1", "test", "", "", mysql.SelectPriv), IsTrue)
+	c.Assert(p.RequestVerification(nil, "root", "10.0.1.118", "test", "", "", mysql.SelectPriv), IsTrue)
+	c.Assert(p.RequestVerification(nil, "root", "localhost", "test", "", "", mysql.SelectPriv), IsFalse)
+	c.Assert(p.RequestVerification(nil, "root", "127.0.0.1", "test", "", "", mysql.SelectPriv), IsFalse)
+	c.Assert(p.RequestVerification(nil, "root", "114.114.114.114", "test", "", "", mysql.SelectPriv), IsFalse)
 
 	mustExec(c, se, "TRUNCATE TABLE mysql.user")
-	mustExec(c, se, `INSERT INTO mysql.user VALUES ("", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`)
+	mustExec(c, se, `INSERT INTO mysql.user VALUES ("", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "N", "mysql_native_password", "")`)
 	p = privileges.MySQLPrivilege{}
 	err = p.LoadUserTable(se)
 	c.Assert(err, IsNil)
-	c.Assert(p.RequestVerification("root", "", "test", "", "", mysql.SelectPriv), IsTrue)
-	c.Assert(p.RequestVerification("root", "notnull", "test", "", "", mysql.SelectPriv), IsFalse)
+	c.Assert(p.RequestVerification(nil, "root", "", "test", "", "", mysql.SelectPriv), IsTrue)
+	c.Assert(p.RequestVerification(nil, "root", "notnull", "test", "", "", mysql.SelectPriv), IsFalse)
 }
 
 func (s *testCacheSuite) TestCaseInsensitive(c *C) {
@@ -166,9 +189,9 @@ func (s *testCacheSuite) TestCaseInsensitive(c *C) {
 	err = p.LoadDBTable(se)
 	c.Assert(err, IsNil)
 	// DB and Table names are case insensitive in MySQL.
-	c.Assert(p.RequestVerification("genius", "127.0.0.1", "TCTrain", "TCTrainOrder", "", mysql.SelectPriv), IsTrue)
-	c.Assert(p.RequestVerification("genius", "127.0.0.1", "TCTRAIN", "TCTRAINORDER", "", mysql.SelectPriv), IsTrue)
-	c.Assert(p.RequestVerification("genius", "127.0.0.1", "tctrain", "tctrainorder", "", mysql.SelectPriv), IsTrue)
+	c.Assert(p.RequestVerification(nil, "genius", "127.0.0.1", "TCTrain", "TCTrainOrder", "", mysql.SelectPriv), IsTrue)
+	c.Assert(p.RequestVerification(nil, "genius", "127.0.0.1", "TCTRAIN", "TCTRAINORDER", "", mysql.SelectPriv), IsTrue)
+	c.Assert(p.RequestVerification(nil, "genius", "127.0.0.1", "tctrain", "tctrainorder", "", mysql.SelectPriv), IsTrue)
 }
 
 func (s *testCacheSuite) TestAbnormalMySQLTable(c *C) {
@@ -183,7 +206,7 @@ func (s *testCacheSuite) TestAbnormalMySQLTable(c *C) {
 	c.Assert(err, IsNil)
 	defer se.Close()
 
-	// Simulate the case mysql.user is synchronized from MySQL.
+	// Simulate the case mysql.user is synchronized from MySQL 5.7.
 	mustExec(c, se, "DROP TABLE mysql.user;")
 	mustExec(c, se, "USE mysql;")
 	mustExec(c, se, `CREATE TABLE user (
@@ -230,15 +253,18 @@ func (s *testCacheSuite) TestAbnormalMySQLTable(c *C) {
   plugin char(64) COLLATE utf8_bin DEFAULT 'mysql_native_password',
   authentication_string text COLLATE utf8_bin,
   password_expired enum('N','Y') CHARACTER SET utf8 NOT NULL DEFAULT 'N',
+  password_last_changed timestamp NULL DEFAULT NULL,
+  password_lifetime smallint(5) unsigned DEFAULT NULL,
+  account_locked enum('N','Y') CHARACTER SET utf8 NOT NULL DEFAULT 'N',
   PRIMARY KEY (Host,User)
 ) ENGINE=MyISAM DEFAULT CHARSET=utf8 COLLATE=utf8_bin COMMENT='Users and global privileges';`)
-	mustExec(c, se, `INSERT INTO user VALUES ('localhost','root','','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','','','','',0,0,0,0,'mysql_native_password','','N');
+	mustExec(c, se, `INSERT INTO user VALUES ('localhost','root','','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','Y','','','','',0,0,0,0,'mysql_native_password','','N',NULL,NULL,'N');
 `)
 	var p privileges.MySQLPrivilege
 	err = p.LoadUserTable(se)
 	c.Assert(err, IsNil)
 	// MySQL mysql.user table schema is not identical to TiDB, check it doesn't break privilege.
-	c.Assert(p.RequestVerification("root", "localhost", "test", "", "", mysql.SelectPriv), IsTrue)
+	c.Assert(p.RequestVerification(nil, "root", "localhost", "test", "", "", mysql.SelectPriv), IsTrue)
 
 	// Absent of those tables doesn't cause error.
 	mustExec(c, se, "DROP TABLE mysql.db;")
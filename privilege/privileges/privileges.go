@@ -53,7 +53,7 @@ type UserPrivileges struct {
 }
 
 // RequestVerification implements the Manager interface.
-func (p *UserPrivileges) RequestVerification(db, table, column string, priv mysql.PrivilegeType) bool {
+func (p *UserPrivileges) RequestVerification(activeRoles []*auth.RoleIdentity, db, table, column string, priv mysql.PrivilegeType) bool {
 	if !Enable || SkipWithGrant {
 		return true
 	}
@@ -69,10 +69,32 @@ func (p *UserPrivileges) RequestVerification(db, table, column string, priv mysq
 	}
 
 	mysqlPriv := p.Handle.Get()
-	return mysqlPriv.RequestVerification(p.user, p.host, db, table, column, priv)
+	return mysqlPriv.RequestVerification(activeRoles, p.user, p.host, db, table, column, priv)
+}
+
+// RequestVerificationWithUser implements the Manager interface.
+func (p *UserPrivileges) RequestVerificationWithUser(db, table, column string, priv mysql.PrivilegeType, user *auth.UserIdentity) bool {
+	if !Enable || SkipWithGrant {
+		return true
+	}
+
+	if p.user == "" && p.host == "" {
+		return true
+	}
+
+	if strings.EqualFold(db, "INFORMATION_SCHEMA") {
+		return true
+	}
+
+	mysqlPriv := p.Handle.Get()
+	roles := mysqlPriv.getDefaultRoles(user.Username, user.Hostname)
+	return mysqlPriv.RequestVerification(roles, user.Username, user.Hostname, db, table, column, priv)
 }
 
 // ConnectionVerification implements the Manager interface.
+// For mysql_native_password users, authentication is the scrambled password.
+// For caching_sha2_password and sha256_password users, authentication is the plaintext password if salt
+// is nil, otherwise it's the scramble sent in the fast authentication of caching_sha2_password.
 func (p *UserPrivileges) ConnectionVerification(user, host string, authentication, salt []byte) bool {
 	if SkipWithGrant {
 		p.user = user
@@ -87,16 +109,31 @@ func (p *UserPrivileges) ConnectionVerification(user, host string, authenticatio
 		return false
 	}
 
+	var ok bool
+	switch record.AuthPlugin {
+	case mysql.AuthCachingSha2Password, mysql.AuthSHA256Password:
+		ok = p.verifySha2Password(record, authentication, salt)
+	default:
+		ok = verifyNativePassword(record, authentication, salt)
+	}
+	if !ok {
+		return false
+	}
+
+	p.user = user
+	p.host = host
+	return true
+}
+
+func verifyNativePassword(record *userRecord, authentication, salt []byte) bool {
 	pwd := record.Password
 	if len(pwd) != 0 && len(pwd) != mysql.PWDHashLen+1 {
-		log.Errorf("User [%s] password from SystemDB not like a sha1sum", user)
+		log.Errorf("User [%s] password from SystemDB not like a sha1sum", record.User)
 		return false
 	}
 
 	// empty password
 	if len(pwd) == 0 && len(authentication) == 0 {
-		p.user = user
-		p.host = host
 		return true
 	}
 
@@ -110,22 +147,59 @@ func (p *UserPrivileges) ConnectionVerification(user, host string, authenticatio
 		return false
 	}
 
-	if !auth.CheckScrambledPassword(salt, hpwd, authentication) {
+	return auth.CheckScrambledPassword(salt, hpwd, authentication)
+}
+
+func (p *UserPrivileges) verifySha2Password(record *userRecord, authentication, salt []byte) bool {
+	// empty password
+	if len(record.AuthString) == 0 {
+		return len(authentication) == 0
+	}
+
+	if salt != nil {
+		// Only caching_sha2_password supports the fast authentication, it succeeds if the user
+		// has been authenticated by the plaintext password since the password is changed.
+		if record.AuthPlugin != mysql.AuthCachingSha2Password {
+			return false
+		}
+		digest := p.Handle.getSha2Digest(record)
+		return digest != nil && auth.CheckSha2Scramble(authentication, salt, digest)
+	}
+
+	ok, err := auth.CheckSha2Password(record.AuthString, string(authentication))
+	if err != nil {
+		log.Errorf("User [%s] check sha2 password error %v", record.User, err)
 		return false
 	}
+	if ok && record.AuthPlugin == mysql.AuthCachingSha2Password {
+		p.Handle.setSha2Digest(record, auth.Sha2ScrambleDigest(authentication))
+	}
+	return ok
+}
 
-	p.user = user
-	p.host = host
-	return true
+// GetAuthPlugin implements the Manager interface.
+func (p *UserPrivileges) GetAuthPlugin(user, host string) string {
+	if SkipWithGrant {
+		return ""
+	}
+	mysqlPriv := p.Handle.Get()
+	record := mysqlPriv.connectionVerification(user, host)
+	if record == nil {
+		return ""
+	}
+	if record.AuthPlugin == "" {
+		return mysql.AuthName
+	}
+	return record.AuthPlugin
 }
 
 // DBIsVisible implements the Manager interface.
-func (p *UserPrivileges) DBIsVisible(db string) bool {
+func (p *UserPrivileges) DBIsVisible(activeRoles []*auth.RoleIdentity, db string) bool {
 	if !Enable || SkipWithGrant {
 		return true
 	}
 	mysqlPriv := p.Handle.Get()
-	return mysqlPriv.DBIsVisible(p.user, p.host, db)
+	return mysqlPriv.DBIsVisible(activeRoles, p.user, p.host, db)
 }
 
 // UserPrivilegesTable implements the Manager interface.
@@ -135,7 +209,19 @@ func (p *UserPrivileges) UserPrivilegesTable() [][]types.Datum {
 }
 
 // ShowGrants implements privilege.Manager ShowGrants interface.
-func (p *UserPrivileges) ShowGrants(ctx context.Context, user *auth.UserIdentity) ([]string, error) {
+func (p *UserPrivileges) ShowGrants(ctx context.Context, user *auth.UserIdentity, roles []*auth.RoleIdentity) ([]string, error) {
+	mysqlPrivilege := p.Handle.Get()
+	return mysqlPrivilege.showGrants(user.Username, user.Hostname, roles), nil
+}
+
+// GetDefaultRoles implements privilege.Manager GetDefaultRoles interface.
+func (p *UserPrivileges) GetDefaultRoles(user, host string) []*auth.RoleIdentity {
+	mysqlPrivilege := p.Handle.Get()
+	return mysqlPrivilege.getDefaultRoles(user, host)
+}
+
+// GetAllRoles implements privilege.Manager GetAllRoles interface.
+func (p *UserPrivileges) GetAllRoles(user, host string) []*auth.RoleIdentity {
 	mysqlPrivilege := p.Handle.Get()
-	return mysqlPrivilege.showGrants(user.Username, user.Hostname), nil
+	return mysqlPrivilege.getAllRoles(user, host)
 }
@@ -15,6 +15,9 @@ package privileges_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/pingcap/check"
@@ -101,16 +104,16 @@ func (s *testPrivilegeSuite) TestCheckDBPrivilege(c *C) {
 	se := newSession(c, s.store, s.dbName)
 	c.Assert(se.Auth(&auth.UserIdentity{Username: "testcheck", Hostname: "localhost"}, nil, nil), IsTrue)
 	pc := privilege.GetPrivilegeManager(se)
-	c.Assert(pc.RequestVerification("test", "", "", mysql.SelectPriv), IsFalse)
+	c.Assert(pc.RequestVerification(nil, "test", "", "", mysql.SelectPriv), IsFalse)
 
 	mustExec(c, rootSe, `GRANT SELECT ON *.* TO  'testcheck'@'localhost';`)
 	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
-	c.Assert(pc.RequestVerification("test", "", "", mysql.SelectPriv), IsTrue)
-	c.Assert(pc.RequestVerification("test", "", "", mysql.UpdatePriv), IsFalse)
+	c.Assert(pc.RequestVerification(nil, "test", "", "", mysql.SelectPriv), IsTrue)
+	c.Assert(pc.RequestVerification(nil, "test", "", "", mysql.UpdatePriv), IsFalse)
 
 	mustExec(c, rootSe, `GRANT Update ON test.* TO  'testcheck'@'localhost';`)
 	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
-	c.Assert(pc.RequestVerification("test", "", "", mysql.UpdatePriv), IsTrue)
+	c.Assert(pc.RequestVerification(nil, "test", "", "", mysql.UpdatePriv), IsTrue)
 }
 
 func (s *testPrivilegeSuite) TestCheckTablePrivilege(c *C) {
@@ -122,21 +125,21 @@ func (s *testPrivilegeSuite) TestCheckTablePrivilege(c *C) {
 	se := newSession(c, s.store, s.dbName)
 	c.Assert(se.Auth(&auth.UserIdentity{Username: "test1", Hostname: "localhost"}, nil, nil), IsTrue)
 	pc := privilege.GetPrivilegeManager(se)
-	c.Assert(pc.RequestVerification("test", "test", "", mysql.SelectPriv), IsFalse)
+	c.Assert(pc.RequestVerification(nil, "test", "test", "", mysql.SelectPriv), IsFalse)
 
 	mustExec(c, rootSe, `GRANT SELECT ON *.* TO  'test1'@'localhost';`)
 	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
-	c.Assert(pc.RequestVerification("test", "test", "", mysql.SelectPriv), IsTrue)
-	c.Assert(pc.RequestVerification("test", "test", "", mysql.UpdatePriv), IsFalse)
+	c.Assert(pc.RequestVerification(nil, "test", "test", "", mysql.SelectPriv), IsTrue)
+	c.Assert(pc.RequestVerification(nil, "test", "test", "", mysql.UpdatePriv), IsFalse)
 
 	mustExec(c, rootSe, `GRANT Update ON test.* TO  'test1'@'localhost';`)
 	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
-	c.Assert(pc.RequestVerification("test", "test", "", mysql.UpdatePriv), IsTrue)
-	c.Assert(pc.RequestVerification("test", "test", "", mysql.IndexPriv), IsFalse)
+	c.Assert(pc.RequestVerification(nil, "test", "test", "", mysql.UpdatePriv), IsTrue)
+	c.Assert(pc.RequestVerification(nil, "test", "test", "", mysql.IndexPriv), IsFalse)
 
 	mustExec(c, rootSe, `GRANT Index ON test.test TO  'test1'@'localhost';`)
 	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
-	c.Assert(pc.RequestVerification("test", "test", "", mysql.IndexPriv), IsTrue)
+	c.Assert(pc.RequestVerification(nil, "test", "test", "", mysql.IndexPriv), IsTrue)
 }
 
 func (s *testPrivilegeSuite) TestShowGrants(c *C) {
@@ -147,14 +150,14 @@ func (s *testPrivilegeSuite) TestShowGrants(c *C) {
 	mustExec(c, se, `FLUSH PRIVILEGES;`)
 	pc := privilege.GetPrivilegeManager(se)
 
-	gs, err := pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"})
+	gs, err := pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"}, nil)
 	c.Assert(err, IsNil)
 	c.Assert(gs, HasLen, 1)
 	c.Assert(gs[0], Equals, `GRANT Index ON *.* TO 'show'@'localhost'`)
 
 	mustExec(c, se, `GRANT Select ON *.* TO  'show'@'localhost';`)
 	mustExec(c, se, `FLUSH PRIVILEGES;`)
-	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"})
+	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"}, nil)
 	c.Assert(err, IsNil)
 	c.Assert(gs, HasLen, 1)
 	c.Assert(gs[0], Equals, `GRANT Select,Index ON *.* TO 'show'@'localhost'`)
@@ -162,7 +165,7 @@ func (s *testPrivilegeSuite) TestShowGrants(c *C) {
 	// The order of privs is the same with AllGlobalPrivs
 	mustExec(c, se, `GRANT Update ON *.* TO  'show'@'localhost';`)
 	mustExec(c, se, `FLUSH PRIVILEGES;`)
-	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"})
+	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"}, nil)
 	c.Assert(err, IsNil)
 	c.Assert(gs, HasLen, 1)
 	c.Assert(gs[0], Equals, `GRANT Select,Update,Index ON *.* TO 'show'@'localhost'`)
@@ -170,7 +173,7 @@ func (s *testPrivilegeSuite) TestShowGrants(c *C) {
 	// All privileges
 	mustExec(c, se, `GRANT ALL ON *.* TO  'show'@'localhost';`)
 	mustExec(c, se, `FLUSH PRIVILEGES;`)
-	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"})
+	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"}, nil)
 	c.Assert(err, IsNil)
 	c.Assert(gs, HasLen, 1)
 	c.Assert(gs[0], Equals, `GRANT ALL PRIVILEGES ON *.* TO 'show'@'localhost'`)
@@ -178,7 +181,7 @@ func (s *testPrivilegeSuite) TestShowGrants(c *C) {
 	// Add db scope privileges
 	mustExec(c, se, `GRANT Select ON test.* TO  'show'@'localhost';`)
 	mustExec(c, se, `FLUSH PRIVILEGES;`)
-	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"})
+	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"}, nil)
 	c.Assert(err, IsNil)
 	c.Assert(gs, HasLen, 2)
 	expected := []string{`GRANT ALL PRIVILEGES ON *.* TO 'show'@'localhost'`,
@@ -187,7 +190,7 @@ func (s *testPrivilegeSuite) TestShowGrants(c *C) {
 
 	mustExec(c, se, `GRANT Index ON test1.* TO  'show'@'localhost';`)
 	mustExec(c, se, `FLUSH PRIVILEGES;`)
-	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"})
+	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"}, nil)
 	c.Assert(err, IsNil)
 	c.Assert(gs, HasLen, 3)
 	expected = []string{`GRANT ALL PRIVILEGES ON *.* TO 'show'@'localhost'`,
@@ -197,7 +200,7 @@ func (s *testPrivilegeSuite) TestShowGrants(c *C) {
 
 	mustExec(c, se, `GRANT ALL ON test1.* TO  'show'@'localhost';`)
 	mustExec(c, se, `FLUSH PRIVILEGES;`)
-	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"})
+	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"}, nil)
 	c.Assert(err, IsNil)
 	c.Assert(gs, HasLen, 3)
 	expected = []string{`GRANT ALL PRIVILEGES ON *.* TO 'show'@'localhost'`,
@@ -208,7 +211,7 @@ func (s *testPrivilegeSuite) TestShowGrants(c *C) {
 	// Add table scope privileges
 	mustExec(c, se, `GRANT Update ON test.test TO  'show'@'localhost';`)
 	mustExec(c, se, `FLUSH PRIVILEGES;`)
-	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"})
+	gs, err = pc.ShowGrants(se, &auth.UserIdentity{Username: "show", Hostname: "localhost"}, nil)
 	c.Assert(err, IsNil)
 	c.Assert(gs, HasLen, 4)
 	expected = []string{`GRANT ALL PRIVILEGES ON *.* TO 'show'@'localhost'`,
@@ -245,6 +248,90 @@ func (s *testPrivilegeSuite) TestDropTablePriv(c *C) {
 	mustExec(c, se, `DROP TABLE todrop;`)
 }
 
+func (s *testPrivilegeSuite) TestSelectIntoOutfilePriv(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "outfile")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	sql := fmt.Sprintf("SELECT * FROM tooutfile INTO OUTFILE '%s'", filepath.Join(dir, "t.txt"))
+
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE TABLE tooutfile(c int);`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "root", Hostname: "localhost"}, nil, nil), IsTrue)
+	mustExec(c, se, `CREATE USER 'outfile'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON test.tooutfile TO  'outfile'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "outfile", Hostname: "localhost"}, nil, nil), IsTrue)
+	pc := privilege.GetPrivilegeManager(se.(context.Context))
+	c.Assert(pc.RequestVerification(nil, "", "", "", mysql.FilePriv), IsFalse)
+	_, err = se.Execute(sql)
+	c.Assert(err, NotNil)
+
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "root", Hostname: "localhost"}, nil, nil), IsTrue)
+	mustExec(c, se, `GRANT File ON *.* TO  'outfile'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "outfile", Hostname: "localhost"}, nil, nil), IsTrue)
+	c.Assert(pc.RequestVerification(nil, "", "", "", mysql.FilePriv), IsTrue)
+	mustExec(c, se, sql)
+
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "root", Hostname: "localhost"}, nil, nil), IsTrue)
+	mustExec(c, se, `DROP TABLE tooutfile;`)
+}
+
+func (s *testPrivilegeSuite) TestViewPrivileges(c *C) {
+	defer testleak.AfterTest(c)()
+	root := &auth.UserIdentity{Username: "root", Hostname: "localhost"}
+	viewer := &auth.UserIdentity{Username: "viewer", Hostname: "localhost"}
+	se := newSession(c, s.store, s.dbName)
+	c.Assert(se.Auth(root, nil, nil), IsTrue)
+	mustExec(c, se, `CREATE TABLE secret(c int);`)
+	mustExec(c, se, `CREATE TABLE pub(c int);`)
+	mustExec(c, se, `CREATE USER 'viewer'@'localhost', 'owner'@'localhost';`)
+	mustExec(c, se, `GRANT Create View ON test.* TO 'viewer'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON test.pub TO 'viewer'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON test.secret TO 'owner'@'localhost';`)
+	mustExec(c, se, `CREATE DEFINER='owner'@'localhost' SQL SECURITY DEFINER VIEW v_owner AS SELECT * FROM secret;`)
+	mustExec(c, se, `CREATE DEFINER='viewer'@'localhost' SQL SECURITY DEFINER VIEW v_viewer AS SELECT * FROM secret;`)
+	mustExec(c, se, `CREATE DEFINER='owner'@'localhost' SQL SECURITY INVOKER VIEW v_invoker AS SELECT * FROM secret;`)
+	mustExec(c, se, `GRANT Select ON test.v_owner TO 'viewer'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON test.v_viewer TO 'viewer'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON test.v_invoker TO 'viewer'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+
+	c.Assert(se.Auth(viewer, nil, nil), IsTrue)
+	// The privileges of the definer are checked for the tables in a SQL SECURITY DEFINER view.
+	mustExec(c, se, `SELECT * FROM v_owner;`)
+	_, err := se.Execute(`SELECT * FROM v_viewer;`)
+	c.Assert(err, NotNil)
+	_, err = se.Execute(`SELECT * FROM v_invoker;`)
+	c.Assert(err, NotNil)
+	_, err = se.Execute(`SELECT * FROM secret;`)
+	c.Assert(err, NotNil)
+
+	// Creating a view needs the CREATE VIEW privilege and the privileges for its SELECT statement, and
+	// only the users with the SUPER privilege can create views for other users.
+	_, err = se.Execute(`CREATE VIEW v1 AS SELECT * FROM secret;`)
+	c.Assert(err, NotNil)
+	_, err = se.Execute(`CREATE DEFINER='owner'@'localhost' VIEW v1 AS SELECT * FROM pub;`)
+	c.Assert(err, NotNil)
+	mustExec(c, se, `CREATE DEFINER='viewer'@'localhost' VIEW v1 AS SELECT * FROM pub;`)
+	_, err = se.Execute(`CREATE VIEW test1.v1 AS SELECT * FROM pub;`)
+	c.Assert(err, NotNil)
+	_, err = se.Execute(`CREATE OR REPLACE VIEW v1 AS SELECT * FROM pub;`)
+	c.Assert(err, NotNil)
+
+	// SHOW CREATE VIEW needs the SHOW VIEW privilege.
+	_, err = se.Execute(`SHOW CREATE VIEW v1;`)
+	c.Assert(err, NotNil)
+	c.Assert(se.Auth(root, nil, nil), IsTrue)
+	mustExec(c, se, `GRANT Show View ON test.v1 TO 'viewer'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(viewer, nil, nil), IsTrue)
+	mustExec(c, se, `SHOW CREATE VIEW v1;`)
+}
+
 func (s *testPrivilegeSuite) TestCheckAuthenticate(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -275,6 +362,195 @@ func (s *testPrivilegeSuite) TestCheckAuthenticate(c *C) {
 	c.Assert(se.Auth(&auth.UserIdentity{Username: "u4", Hostname: "localhost"}, nil, nil), IsFalse)
 }
 
+func (s *testPrivilegeSuite) TestCheckSha2Authenticate(c *C) {
+	defer testleak.AfterTest(c)()
+
+	rootSe := newSession(c, s.store, s.dbName)
+	mustExec(c, rootSe, `CREATE USER 'sha2'@'localhost' IDENTIFIED WITH 'caching_sha2_password' BY 'abc';`)
+	mustExec(c, rootSe, `CREATE USER 'sha256'@'localhost' IDENTIFIED WITH 'sha256_password' BY 'abc';`)
+	mustExec(c, rootSe, `CREATE USER 'sha2_empty'@'localhost' IDENTIFIED WITH 'caching_sha2_password';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	se := newSession(c, s.store, s.dbName)
+	sha2 := &auth.UserIdentity{Username: "sha2", Hostname: "localhost"}
+	sha256 := &auth.UserIdentity{Username: "sha256", Hostname: "localhost"}
+	c.Assert(se.AuthPlugin(sha2), Equals, mysql.AuthCachingSha2Password)
+	c.Assert(se.AuthPlugin(sha256), Equals, mysql.AuthSHA256Password)
+	c.Assert(se.AuthPlugin(&auth.UserIdentity{Username: "u_not_exist", Hostname: "localhost"}), Equals, "")
+
+	salt := []byte("01234567890123456789")
+	stage1 := auth.Sha256Hash([]byte("abc"))
+	scramble := auth.Sha256Hash(append(auth.Sha256Hash(stage1), salt...))
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	// The fast authentication fails before the user is authenticated by the plaintext password.
+	c.Assert(se.Auth(sha2, scramble, salt), IsFalse)
+	c.Assert(se.Auth(sha2, []byte("abd"), nil), IsFalse)
+	c.Assert(se.Auth(sha2, []byte("abc"), nil), IsTrue)
+	c.Assert(se.Auth(sha2, scramble, salt), IsTrue)
+	// sha256_password doesn't support the fast authentication.
+	c.Assert(se.Auth(sha256, []byte("abc"), nil), IsTrue)
+	c.Assert(se.Auth(sha256, scramble, salt), IsFalse)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "sha2_empty", Hostname: "localhost"}, nil, salt), IsTrue)
+
+	// Changing the password invalidates the cached credential.
+	mustExec(c, rootSe, `ALTER USER 'sha2'@'localhost' IDENTIFIED BY 'def';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	c.Assert(se.AuthPlugin(sha2), Equals, mysql.AuthCachingSha2Password)
+	c.Assert(se.Auth(sha2, scramble, salt), IsFalse)
+	c.Assert(se.Auth(sha2, []byte("def"), nil), IsTrue)
+
+	// The cached credential is evicted by SET PASSWORD and DROP USER before the privileges are reloaded,
+	// and it's evicted when the privileges are reloaded without the user.
+	defScramble := func() []byte {
+		stage1 := auth.Sha256Hash([]byte("def"))
+		scramble := auth.Sha256Hash(append(auth.Sha256Hash(stage1), salt...))
+		for i := range scramble {
+			scramble[i] ^= stage1[i]
+		}
+		return scramble
+	}()
+	c.Assert(se.Auth(sha2, defScramble, salt), IsTrue)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(sha2, defScramble, salt), IsTrue)
+	mustExec(c, rootSe, `UPDATE mysql.user SET User = 'sha2_tmp' WHERE User = 'sha2';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	mustExec(c, rootSe, `UPDATE mysql.user SET User = 'sha2' WHERE User = 'sha2_tmp';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(sha2, defScramble, salt), IsFalse)
+	c.Assert(se.Auth(sha2, []byte("def"), nil), IsTrue)
+	mustExec(c, rootSe, `SET PASSWORD FOR 'sha2'@'localhost' = 'ghi';`)
+	c.Assert(se.Auth(sha2, defScramble, salt), IsFalse)
+	c.Assert(se.Auth(sha2, []byte("def"), nil), IsTrue)
+	mustExec(c, rootSe, `DROP USER 'sha2'@'localhost';`)
+	c.Assert(se.Auth(sha2, defScramble, salt), IsFalse)
+	mustExec(c, rootSe, `CREATE USER 'sha2'@'localhost' IDENTIFIED WITH 'caching_sha2_password' BY 'def';`)
+
+	mustExec(c, rootSe, "drop user 'sha2'@'localhost', 'sha256'@'localhost', 'sha2_empty'@'localhost'")
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+}
+
+func (s *testPrivilegeSuite) TestRoles(c *C) {
+	defer testleak.AfterTest(c)()
+
+	rootSe := newSession(c, s.store, s.dbName)
+	mustExec(c, rootSe, `CREATE ROLE 'r_select', 'r_update'@'localhost';`)
+	mustExec(c, rootSe, `GRANT Select ON test.* TO 'r_select';`)
+	mustExec(c, rootSe, `GRANT Update ON test.test TO 'r_update'@'localhost';`)
+	mustExec(c, rootSe, `CREATE USER 'u_role'@'localhost';`)
+	mustExec(c, rootSe, `GRANT 'r_select', 'r_update'@'localhost' TO 'u_role'@'localhost';`)
+	mustExec(c, rootSe, `SET DEFAULT ROLE 'r_select' TO 'u_role'@'localhost';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+
+	// A role can't be used to connect.
+	se := newSession(c, s.store, s.dbName)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "r_select", Hostname: "localhost"}, nil, nil), IsFalse)
+
+	// The default roles are activated when the user connects.
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "u_role", Hostname: "localhost"}, nil, nil), IsTrue)
+	pc := privilege.GetPrivilegeManager(se.(context.Context))
+	activeRoles := func() []*auth.RoleIdentity {
+		return se.(context.Context).GetSessionVars().ActiveRoles
+	}
+	c.Assert(activeRoles(), HasLen, 1)
+	c.Assert(pc.RequestVerification(activeRoles(), "test", "test", "", mysql.SelectPriv), IsTrue)
+	c.Assert(pc.RequestVerification(activeRoles(), "test", "test", "", mysql.UpdatePriv), IsFalse)
+	mustExec(c, se, `SELECT * FROM test;`)
+	_, err := se.Execute(`UPDATE test SET name = 'a';`)
+	c.Assert(err, NotNil)
+
+	mustExec(c, se, `SET ROLE ALL;`)
+	c.Assert(activeRoles(), HasLen, 2)
+	mustExec(c, se, `UPDATE test SET name = 'a';`)
+
+	mustExec(c, se, `SET ROLE ALL EXCEPT 'r_select';`)
+	c.Assert(pc.RequestVerification(activeRoles(), "test", "test", "", mysql.SelectPriv), IsFalse)
+	c.Assert(pc.RequestVerification(activeRoles(), "test", "test", "", mysql.UpdatePriv), IsTrue)
+
+	mustExec(c, se, `SET ROLE NONE;`)
+	c.Assert(activeRoles(), HasLen, 0)
+	c.Assert(pc.DBIsVisible(activeRoles(), "test"), IsFalse)
+
+	mustExec(c, se, `SET ROLE DEFAULT;`)
+	c.Assert(pc.DBIsVisible(activeRoles(), "test"), IsTrue)
+
+	_, err = se.Execute(`SET ROLE 'r_unknown';`)
+	c.Assert(err, NotNil)
+
+	// Users can set their own default roles without the CREATE USER privilege, but not the others'.
+	mustExec(c, se, `SET DEFAULT ROLE 'r_select' TO 'u_role'@'localhost';`)
+	_, err = se.Execute(`SET DEFAULT ROLE NONE TO 'root'@'%';`)
+	c.Assert(err, NotNil)
+
+	gs, err := pc.ShowGrants(se.(context.Context), &auth.UserIdentity{Username: "u_role", Hostname: "localhost"},
+		[]*auth.RoleIdentity{{Username: "r_select", Hostname: "%"}})
+	c.Assert(err, IsNil)
+	expected := []string{`GRANT USAGE ON *.* TO 'u_role'@'localhost'`,
+		`GRANT Select ON test.* TO 'u_role'@'localhost'`,
+		`GRANT 'r_select'@'%','r_update'@'localhost' TO 'u_role'@'localhost'`}
+	c.Assert(testutil.CompareUnorderedStringSlice(gs, expected), IsTrue, Commentf("%v", gs))
+
+	// Revoking a role deactivates it after the user connects again.
+	mustExec(c, rootSe, `REVOKE 'r_select' FROM 'u_role'@'localhost';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "u_role", Hostname: "localhost"}, nil, nil), IsTrue)
+	c.Assert(activeRoles(), HasLen, 0)
+	c.Assert(pc.GetAllRoles("u_role", "localhost"), HasLen, 1)
+
+	mustExec(c, rootSe, `DROP ROLE 'r_select', 'r_update'@'localhost';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	c.Assert(pc.GetAllRoles("u_role", "localhost"), HasLen, 0)
+}
+
+func (s *testPrivilegeSuite) TestNestedRoles(c *C) {
+	defer testleak.AfterTest(c)()
+
+	rootSe := newSession(c, s.store, s.dbName)
+	mustExec(c, rootSe, `CREATE ROLE 'r_outer', 'r_inner', 'r_cycle';`)
+	mustExec(c, rootSe, `GRANT Update ON test.test TO 'r_inner';`)
+	mustExec(c, rootSe, `GRANT Select ON test.* TO 'r_cycle';`)
+	// r_outer -> r_inner -> r_cycle -> r_outer
+	mustExec(c, rootSe, `GRANT 'r_inner' TO 'r_outer';`)
+	mustExec(c, rootSe, `GRANT 'r_cycle' TO 'r_inner';`)
+	mustExec(c, rootSe, `GRANT 'r_outer' TO 'r_cycle';`)
+	mustExec(c, rootSe, `CREATE USER 'u_nested'@'localhost';`)
+	mustExec(c, rootSe, `GRANT 'r_outer' TO 'u_nested'@'localhost';`)
+	mustExec(c, rootSe, `SET DEFAULT ROLE 'r_outer' TO 'u_nested'@'localhost';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+
+	// The privileges of the roles granted to the active roles are inherited.
+	se := newSession(c, s.store, s.dbName)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "u_nested", Hostname: "localhost"}, nil, nil), IsTrue)
+	pc := privilege.GetPrivilegeManager(se.(context.Context))
+	activeRoles := se.(context.Context).GetSessionVars().ActiveRoles
+	c.Assert(activeRoles, HasLen, 1)
+	c.Assert(pc.RequestVerification(activeRoles, "test", "test", "", mysql.SelectPriv), IsTrue)
+	c.Assert(pc.RequestVerification(activeRoles, "test", "test", "", mysql.UpdatePriv), IsTrue)
+	c.Assert(pc.RequestVerification(activeRoles, "test", "test", "", mysql.DeletePriv), IsFalse)
+	c.Assert(pc.DBIsVisible(activeRoles, "test"), IsTrue)
+	mustExec(c, se, `UPDATE test SET name = 'a';`)
+
+	// SET ROLE ALL activates the nested roles, and the cycle is visited once.
+	c.Assert(pc.GetAllRoles("u_nested", "localhost"), HasLen, 3)
+	mustExec(c, se, `SET ROLE ALL;`)
+	c.Assert(se.(context.Context).GetSessionVars().ActiveRoles, HasLen, 3)
+	mustExec(c, se, `SET ROLE 'r_cycle';`)
+	mustExec(c, se, `SELECT * FROM test;`)
+
+	gs, err := pc.ShowGrants(se.(context.Context), &auth.UserIdentity{Username: "u_nested", Hostname: "localhost"},
+		[]*auth.RoleIdentity{{Username: "r_outer", Hostname: "%"}})
+	c.Assert(err, IsNil)
+	expected := []string{`GRANT USAGE ON *.* TO 'u_nested'@'localhost'`,
+		`GRANT Select ON test.* TO 'u_nested'@'localhost'`,
+		`GRANT Update ON test.test TO 'u_nested'@'localhost'`,
+		`GRANT 'r_outer'@'%' TO 'u_nested'@'localhost'`}
+	c.Assert(testutil.CompareUnorderedStringSlice(gs, expected), IsTrue, Commentf("%v", gs))
+
+	mustExec(c, rootSe, `DROP ROLE 'r_outer', 'r_inner', 'r_cycle';`)
+	mustExec(c, rootSe, `DROP USER 'u_nested'@'localhost';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+}
+
 func (s *testPrivilegeSuite) TestInformationSchema(c *C) {
 	defer testleak.AfterTest(c)()
 
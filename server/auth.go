@@ -0,0 +1,241 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/auth"
+)
+
+const (
+	// The second byte of the AuthMoreData packets sent by caching_sha2_password.
+	cachingSha2FastAuthSuccess     byte = 0x03
+	cachingSha2PerformFullAuth     byte = 0x04
+	cachingSha2RequestPublicKey    byte = 0x02
+	sha256PasswordRequestPublicKey byte = 0x01
+
+	rsaKeyBits = 2048
+)
+
+// defaultAuthPlugin returns the authentication plugin advertised in the initial handshake.
+func (s *Server) defaultAuthPlugin() string {
+	if s.cfg != nil {
+		switch plugin := s.cfg.Security.DefaultAuthPlugin; plugin {
+		case mysql.AuthCachingSha2Password, mysql.AuthSHA256Password:
+			return plugin
+		}
+	}
+	return mysql.AuthName
+}
+
+// getRSAKey returns the RSA private key and the PEM encoded public key used to exchange passwords
+// on insecure connections. The key is loaded from the configured file, or generated if no file
+// is configured, on the first call.
+func (s *Server) getRSAKey() (*rsa.PrivateKey, []byte, error) {
+	s.rsaKeyOnce.Do(func() {
+		var path string
+		if s.cfg != nil {
+			path = s.cfg.Security.RSAPrivateKey
+		}
+		s.rsaKey, s.rsaPublicKeyPEM, s.rsaKeyErr = loadOrGenerateRSAKey(path)
+		if s.rsaKeyErr != nil {
+			log.Errorf("Load RSA key error %v", errors.ErrorStack(s.rsaKeyErr))
+		}
+	})
+	return s.rsaKey, s.rsaPublicKeyPEM, s.rsaKeyErr
+}
+
+func loadOrGenerateRSAKey(path string) (*rsa.PrivateKey, []byte, error) {
+	var (
+		key *rsa.PrivateKey
+		err error
+	)
+	if path == "" {
+		key, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	} else {
+		key, err = loadRSAPrivateKey(path)
+	}
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// loadRSAPrivateKey loads a PKCS #1 or PKCS #8 encoded RSA private key from the PEM file.
+func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("no PEM data is found in %s", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("%s is not a RSA private key", path)
+	}
+	return rsaKey, nil
+}
+
+// isSecureTransport returns whether the password can be sent in plaintext on the connection.
+func (cc *clientConn) isSecureTransport() bool {
+	return cc.tlsConn != nil || cc.bufReadConn.RemoteAddr().Network() == "unix"
+}
+
+// writeAuthSwitchRequest asks the client to authenticate by the plugin with the salt sent in the initial
+// handshake, and returns the auth data the client responds.
+// See https://dev.mysql.com/doc/internals/en/connection-phase-packets.html#packet-Protocol::AuthSwitchRequest
+func (cc *clientConn) writeAuthSwitchRequest(plugin string) ([]byte, error) {
+	data := make([]byte, 4, 4+1+len(plugin)+1+len(cc.salt)+1)
+	data = append(data, mysql.AuthSwitchHeader)
+	data = append(data, plugin...)
+	data = append(data, 0)
+	data = append(data, cc.salt...)
+	data = append(data, 0)
+	if err := cc.writeAuthMoreData(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	authData, err := cc.readPacket()
+	return authData, errors.Trace(err)
+}
+
+// writeAuthMoreData writes and flushes a packet during the authentication.
+func (cc *clientConn) writeAuthMoreData(data []byte) error {
+	if err := cc.writePacket(data); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
+// authenticate verifies the auth data sent by the client with the plugin. caching_sha2_password and
+// sha256_password may exchange more packets with the client to get the plaintext password.
+func (cc *clientConn) authenticate(ctx QueryCtx, user *auth.UserIdentity, plugin string, authData []byte) (bool, error) {
+	switch plugin {
+	case mysql.AuthCachingSha2Password:
+		return cc.authCachingSha2Password(ctx, user, authData)
+	case mysql.AuthSHA256Password:
+		return cc.authSHA256Password(ctx, user, authData)
+	}
+	return ctx.Auth(user, authData, cc.salt), nil
+}
+
+// authCachingSha2Password tries the fast authentication by the scramble first, if the credential of the
+// user is not cached, the client is asked to send the plaintext password, in TLS or encrypted by the RSA key.
+// See https://dev.mysql.com/doc/dev/mysql-server/latest/page_caching_sha2_authentication_exchanges.html
+func (cc *clientConn) authCachingSha2Password(ctx QueryCtx, user *auth.UserIdentity, authData []byte) (bool, error) {
+	// The empty password is sent as empty auth data.
+	if len(authData) == 0 {
+		return ctx.Auth(user, nil, cc.salt), nil
+	}
+	if ctx.Auth(user, authData, cc.salt) {
+		return true, errors.Trace(cc.writeAuthMoreData([]byte{0, 0, 0, 0, mysql.AuthMoreDataHeader, cachingSha2FastAuthSuccess}))
+	}
+
+	if err := cc.writeAuthMoreData([]byte{0, 0, 0, 0, mysql.AuthMoreDataHeader, cachingSha2PerformFullAuth}); err != nil {
+		return false, errors.Trace(err)
+	}
+	data, err := cc.readPacket()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !cc.isSecureTransport() {
+		if len(data) == 1 && data[0] == cachingSha2RequestPublicKey {
+			if data, err = cc.writePublicKey(); err != nil {
+				return false, errors.Trace(err)
+			}
+		}
+		if data, err = cc.decryptPassword(data); err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+	return ctx.Auth(user, bytes.TrimRight(data, "\x00"), nil), nil
+}
+
+// authSHA256Password gets the plaintext password from the client, in TLS or encrypted by the RSA key.
+// See https://dev.mysql.com/doc/internals/en/sha256.html
+func (cc *clientConn) authSHA256Password(ctx QueryCtx, user *auth.UserIdentity, authData []byte) (bool, error) {
+	if !cc.isSecureTransport() {
+		// The empty password is sent as empty auth data or a single NUL.
+		if len(authData) == 0 || len(authData) == 1 && authData[0] == 0 {
+			return ctx.Auth(user, nil, nil), nil
+		}
+		var err error
+		if len(authData) == 1 && authData[0] == sha256PasswordRequestPublicKey {
+			if authData, err = cc.writePublicKey(); err != nil {
+				return false, errors.Trace(err)
+			}
+		}
+		if authData, err = cc.decryptPassword(authData); err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+	return ctx.Auth(user, bytes.TrimRight(authData, "\x00"), nil), nil
+}
+
+// writePublicKey sends the PEM encoded RSA public key to the client, and returns the encrypted password
+// the client responds.
+func (cc *clientConn) writePublicKey() ([]byte, error) {
+	_, publicKey, err := cc.server.getRSAKey()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	data := make([]byte, 4, 4+1+len(publicKey))
+	data = append(data, mysql.AuthMoreDataHeader)
+	data = append(data, publicKey...)
+	if err = cc.writeAuthMoreData(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	data, err = cc.readPacket()
+	return data, errors.Trace(err)
+}
+
+// decryptPassword decrypts the password encrypted by the RSA public key, the client XORs the NUL
+// terminated password with the salt before the encryption.
+func (cc *clientConn) decryptPassword(data []byte) ([]byte, error) {
+	key, _, err := cc.server.getRSAKey()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	pwd, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, data, nil)
+	if err != nil {
+		log.Warnf("Decrypt password error %v", err)
+		return nil, errors.Trace(errAccessDenied.GenByArgs(cc.user, cc.bufReadConn.RemoteAddr().String(), "YES"))
+	}
+	for i := range pwd {
+		pwd[i] ^= cc.salt[i%len(cc.salt)]
+	}
+	return pwd, nil
+}
@@ -51,10 +51,13 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/arena"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/types"
 )
 
 // clientConn represents a connection between server and client, it maintains connection specific state,
@@ -75,6 +78,7 @@ type clientConn struct {
 	ctx          QueryCtx          // an interface to execute sql statements.
 	attrs        map[string]string // attributes parsed from client handshake response, not used for now.
 	killed       bool
+	cursors      map[uint32]*stmtCursor // open cursors of the statements executed with a cursor, keyed by statement ID.
 }
 
 func (cc *clientConn) String() string {
@@ -105,12 +109,17 @@ func (cc *clientConn) handshake() error {
 	}
 
 	err := cc.writePacket(data)
-	cc.pkt.sequence = 0
+	cc.pkt.resetSequence()
 	if err != nil {
 		return errors.Trace(err)
 	}
 
-	return errors.Trace(cc.flush())
+	if err = cc.flush(); err != nil {
+		return errors.Trace(err)
+	}
+	// The packets after the handshake are compressed if the client requires the compressed protocol.
+	cc.pkt.compress = cc.capability&mysql.ClientCompress > 0
+	return nil
 }
 
 func (cc *clientConn) Close() error {
@@ -121,6 +130,7 @@ func (cc *clientConn) Close() error {
 	connGauge.Set(float64(connections))
 	err := cc.bufReadConn.Close()
 	terror.Log(errors.Trace(err))
+	cc.closeCursors()
 	if cc.ctx != nil {
 		return cc.ctx.Close()
 	}
@@ -163,7 +173,7 @@ func (cc *clientConn) writeInitialHandshake() error {
 	data = append(data, cc.salt[8:]...)
 	data = append(data, 0)
 	// auth-plugin name
-	data = append(data, []byte("mysql_native_password")...)
+	data = append(data, cc.server.defaultAuthPlugin()...)
 	data = append(data, 0)
 	err := cc.writePacket(data)
 	if err != nil {
@@ -186,6 +196,7 @@ type handshakeResponse41 struct {
 	User       string
 	DBName     string
 	Auth       []byte
+	AuthPlugin string
 	Attrs      map[string]string
 }
 
@@ -257,8 +268,10 @@ func parseHandshakeResponseBody(packet *handshakeResponse41, data []byte, offset
 	}
 
 	if packet.Capability&mysql.ClientPluginAuth > 0 {
-		// TODO: Support mysql.ClientPluginAuth, skip it now
 		idx := bytes.IndexByte(data[offset:], 0)
+		if idx >= 0 {
+			packet.AuthPlugin = string(data[offset : offset+idx])
+		}
 		offset = offset + idx + 1
 	}
 
@@ -344,37 +357,151 @@ func (cc *clientConn) readOptionalSSLRequestAndHandshakeResponse() error {
 	cc.collation = resp.Collation
 	cc.attrs = resp.Attrs
 
-	// Open session and do auth.
+	cc.ctx, err = cc.openSessionAndDoAuth(resp.Auth, resp.AuthPlugin)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cc.dbname != "" {
+		err = cc.useDB(cc.dbname)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	cc.ctx.SetSessionManager(cc.server)
+	return nil
+}
+
+// openSessionAndDoAuth opens a new session for cc.user and verifies authData sent by the client with
+// authPlugin, the client is asked to switch to the plugin of the user if they are different.
+// The session is closed if the verification fails.
+func (cc *clientConn) openSessionAndDoAuth(authData []byte, authPlugin string) (QueryCtx, error) {
 	var tlsStatePtr *tls.ConnectionState
 	if cc.tlsConn != nil {
 		tlsState := cc.tlsConn.ConnectionState()
 		tlsStatePtr = &tlsState
 	}
-	cc.ctx, err = cc.server.driver.OpenCtx(uint64(cc.connectionID), cc.capability, cc.collation, cc.dbname, tlsStatePtr)
+	ctx, err := cc.server.driver.OpenCtx(uint64(cc.connectionID), cc.capability, cc.collation, cc.dbname, tlsStatePtr)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
+	}
+	if cc.server.skipAuth() {
+		return ctx, nil
 	}
-	if !cc.server.skipAuth() {
-		// Do Auth.
-		addr := cc.bufReadConn.RemoteAddr().String()
-		host, _, err1 := net.SplitHostPort(addr)
-		if err1 != nil {
-			return errors.Trace(errAccessDenied.GenByArgs(cc.user, addr, "YES"))
+	addr := cc.bufReadConn.RemoteAddr().String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		terror.Call(ctx.Close)
+		return nil, errors.Trace(errAccessDenied.GenByArgs(cc.user, addr, "YES"))
+	}
+	user := &auth.UserIdentity{Username: cc.user, Hostname: host}
+	// Clients without the plugin auth capability use mysql_native_password.
+	if authPlugin == "" {
+		authPlugin = mysql.AuthName
+	}
+	if userPlugin := ctx.AuthPlugin(user); userPlugin != "" && userPlugin != authPlugin &&
+		cc.capability&mysql.ClientPluginAuth > 0 {
+		if authData, err = cc.writeAuthSwitchRequest(userPlugin); err != nil {
+			terror.Call(ctx.Close)
+			return nil, errors.Trace(err)
 		}
-		if !cc.ctx.Auth(&auth.UserIdentity{Username: cc.user, Hostname: host}, resp.Auth, cc.salt) {
-			return errors.Trace(errAccessDenied.GenByArgs(cc.user, host, "YES"))
+		authPlugin = userPlugin
+	}
+	ok, err := cc.authenticate(ctx, user, authPlugin, authData)
+	if err != nil {
+		terror.Call(ctx.Close)
+		return nil, errors.Trace(err)
+	}
+	if !ok {
+		terror.Call(ctx.Close)
+		return nil, errors.Trace(errAccessDenied.GenByArgs(cc.user, host, "YES"))
+	}
+	return ctx, nil
+}
+
+// parseChangeUser parses the payload of COM_CHANGE_USER by the capability negotiated in the handshake.
+// See https://dev.mysql.com/doc/internals/en/com-change-user.html
+func parseChangeUser(packet *handshakeResponse41, data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("change user panic, packet data: %v", data)
+			err = mysql.ErrMalformPacket
 		}
+	}()
+	offset := 0
+	packet.User = string(data[offset : offset+bytes.IndexByte(data[offset:], 0)])
+	offset += len(packet.User) + 1
+
+	if packet.Capability&mysql.ClientSecureConnection > 0 {
+		authLen := int(data[offset])
+		offset++
+		packet.Auth = data[offset : offset+authLen]
+		offset += authLen
+	} else {
+		packet.Auth = data[offset : offset+bytes.IndexByte(data[offset:], 0)]
+		offset += len(packet.Auth) + 1
 	}
-	if cc.dbname != "" {
-		err = cc.useDB(cc.dbname)
-		if err != nil {
-			return errors.Trace(err)
+
+	packet.DBName = string(data[offset : offset+bytes.IndexByte(data[offset:], 0)])
+	offset += len(packet.DBName) + 1
+
+	// The character set and the auth plugin name are optional.
+	if len(data[offset:]) >= 2 {
+		packet.Collation = data[offset]
+		offset += 2
+	}
+	if packet.Capability&mysql.ClientPluginAuth > 0 && len(data[offset:]) > 0 {
+		idx := bytes.IndexByte(data[offset:], 0)
+		if idx < 0 {
+			idx = len(data[offset:])
 		}
+		packet.AuthPlugin = string(data[offset : offset+idx])
 	}
-	cc.ctx.SetSessionManager(cc.server)
 	return nil
 }
 
+// handleChangeUser authenticates the user in COM_CHANGE_USER and replaces the session with a new one,
+// the transaction, prepared statements and variables of the old session are dropped.
+// If the authentication or the database of the new user fails, the error is sent and the connection
+// is closed like MySQL does.
+func (cc *clientConn) handleChangeUser(data []byte) error {
+	resp := handshakeResponse41{Capability: cc.capability, Collation: cc.collation}
+	if err := parseChangeUser(&resp, data); err != nil {
+		return errors.Trace(err)
+	}
+
+	oldUser, oldDBName, oldCollation := cc.user, cc.dbname, cc.collation
+	cc.user, cc.dbname, cc.collation = resp.User, resp.DBName, resp.Collation
+	ctx, err := cc.openSessionAndDoAuth(resp.Auth, resp.AuthPlugin)
+	if err == nil && cc.dbname != "" {
+		// Use the database on the new session before it replaces the old one.
+		if _, err = ctx.Execute("use `" + cc.dbname + "`"); err != nil {
+			terror.Call(ctx.Close)
+		}
+	}
+	if err != nil {
+		cc.user, cc.dbname, cc.collation = oldUser, oldDBName, oldCollation
+		log.Infof("[%d] change user error %s", cc.connectionID, errors.ErrorStack(err))
+		terror.Log(errors.Trace(cc.writeError(err)))
+		return io.EOF
+	}
+
+	cc.closeCursors()
+	terror.Call(cc.ctx.Close)
+	cc.ctx = ctx
+	cc.ctx.SetSessionManager(cc.server)
+	return cc.writeOK()
+}
+
+// handleResetConnection resets the session without re-authentication and closing the connection.
+// See https://dev.mysql.com/doc/internals/en/com-reset-connection.html
+func (cc *clientConn) handleResetConnection() error {
+	cc.closeCursors()
+	if err := cc.ctx.Reset(); err != nil {
+		return errors.Trace(err)
+	}
+	return cc.writeOK()
+}
+
 // Run reads client query and writes query result to client in for loop, if there is a panic during query handling,
 // it will be recovered and log the panic error.
 // This function returns and the connection is closed if there is an IO error or there is a panic.
@@ -431,7 +558,7 @@ func (cc *clientConn) Run() {
 			terror.Log(errors.Trace(err1))
 		}
 		cc.addMetrics(data[0], startTime, err)
-		cc.pkt.sequence = 0
+		cc.pkt.resetSequence()
 	}
 }
 
@@ -476,8 +603,14 @@ func (cc *clientConn) addMetrics(cmd byte, startTime time.Time, err error) {
 		label = "StmtSendLongData"
 	case mysql.ComStmtReset:
 		label = "StmtReset"
+	case mysql.ComStmtFetch:
+		label = "StmtFetch"
 	case mysql.ComSetOption:
 		label = "SetOption"
+	case mysql.ComChangeUser:
+		label = "ChangeUser"
+	case mysql.ComResetConnection:
+		label = "ResetConnection"
 	default:
 		label = strconv.Itoa(int(cmd))
 	}
@@ -517,18 +650,18 @@ func (cc *clientConn) dispatch(data []byte) error {
 		if len(data) > 0 && data[len(data)-1] == 0 {
 			data = data[:len(data)-1]
 		}
-		return cc.handleQuery(hack.String(data))
+		return cc.handleQuery(cc.decodeInput(hack.String(data)))
 	case mysql.ComPing:
 		return cc.writeOK()
 	case mysql.ComInitDB:
-		if err := cc.useDB(hack.String(data)); err != nil {
+		if err := cc.useDB(cc.decodeInput(hack.String(data))); err != nil {
 			return errors.Trace(err)
 		}
 		return cc.writeOK()
 	case mysql.ComFieldList:
-		return cc.handleFieldList(hack.String(data))
+		return cc.handleFieldList(cc.decodeInput(hack.String(data)))
 	case mysql.ComStmtPrepare:
-		return cc.handleStmtPrepare(hack.String(data))
+		return cc.handleStmtPrepare(cc.decodeInput(hack.String(data)))
 	case mysql.ComStmtExecute:
 		return cc.handleStmtExecute(data)
 	case mysql.ComStmtClose:
@@ -537,13 +670,55 @@ func (cc *clientConn) dispatch(data []byte) error {
 		return cc.handleStmtSendLongData(data)
 	case mysql.ComStmtReset:
 		return cc.handleStmtReset(data)
+	case mysql.ComStmtFetch:
+		return cc.handleStmtFetch(data)
 	case mysql.ComSetOption:
 		return cc.handleSetOption(data)
+	case mysql.ComChangeUser:
+		return cc.handleChangeUser(data)
+	case mysql.ComResetConnection:
+		return cc.handleResetConnection()
 	default:
 		return mysql.NewErrf(mysql.ErrUnknown, "command %d not supported now", cmd)
 	}
 }
 
+// decodeInput decodes the string sent by the client from character_set_client to UTF-8.
+func (cc *clientConn) decodeInput(str string) string {
+	return charset.Decode(cc.ctx.GetSessionVars().Systems[variable.CharacterSetClient], str)
+}
+
+// resultsCharset returns the charset of the strings sent to the client, it's empty if the
+// strings are sent as they are.
+func (cc *clientConn) resultsCharset() string {
+	return cc.ctx.GetSessionVars().Systems[variable.CharacterSetResults]
+}
+
+// encodeRow encodes the strings of the non-binary columns in the row from UTF-8 to
+// character_set_results, the row is copied if any value is encoded.
+func (cc *clientConn) encodeRow(columns []*ColumnInfo, row []types.Datum) []types.Datum {
+	cs := cc.resultsCharset()
+	if !charset.IsTranscoded(cs) {
+		return row
+	}
+	var encoded []types.Datum
+	for i := 0; i < len(row) && i < len(columns); i++ {
+		kind := row[i].Kind()
+		if (kind != types.KindString && kind != types.KindBytes) || columns[i].Charset == mysql.BinaryCollationID {
+			continue
+		}
+		if encoded == nil {
+			encoded = append([]types.Datum(nil), row...)
+		}
+		str, _ := charset.Encode(cs, row[i].GetString())
+		encoded[i].SetString(str)
+	}
+	if encoded == nil {
+		return row
+	}
+	return encoded
+}
+
 func (cc *clientConn) useDB(db string) (err error) {
 	// if input is "use `SELECT`", mysql client just send "SELECT"
 	// so we add `` around db.
@@ -629,6 +804,20 @@ func (cc *clientConn) writeEOF(more bool) error {
 	return errors.Trace(err)
 }
 
+// writeEOFWithStatus writes an EOF packet with the server status, it won't flush the stream either.
+func (cc *clientConn) writeEOFWithStatus(status uint16) error {
+	data := cc.alloc.AllocWithLen(4, 9)
+
+	data = append(data, mysql.EOFHeader)
+	if cc.capability&mysql.ClientProtocol41 > 0 {
+		data = append(data, dumpUint16(cc.ctx.WarningCount())...)
+		data = append(data, dumpUint16(status)...)
+	}
+
+	err := cc.writePacket(data)
+	return errors.Trace(err)
+}
+
 func (cc *clientConn) writeReq(filePath string) error {
 	data := cc.alloc.AllocWithLen(4, 5+len(filePath))
 	data = append(data, mysql.LocalInFileHeader)
@@ -792,25 +981,14 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 		return errors.Trace(err)
 	}
 
-	columnLen := dumpLengthEncodedInt(uint64(len(columns)))
-	data := cc.alloc.AllocWithLen(4, 1024)
-	data = append(data, columnLen...)
-	if err = cc.writePacket(data); err != nil {
+	if err = cc.writeColumnInfo(columns); err != nil {
 		return errors.Trace(err)
 	}
-
-	for _, v := range columns {
-		data = data[0:4]
-		data = append(data, v.Dump(cc.alloc)...)
-		if err = cc.writePacket(data); err != nil {
-			return errors.Trace(err)
-		}
-	}
-
 	if err = cc.writeEOF(false); err != nil {
 		return errors.Trace(err)
 	}
 
+	data := cc.alloc.AllocWithLen(4, 1024)
 	for {
 		if err != nil {
 			return errors.Trace(err)
@@ -819,6 +997,7 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 			break
 		}
 		data = data[0:4]
+		row = cc.encodeRow(columns, row)
 		if binary {
 			var rowData []byte
 			rowData, err = dumpRowValuesBinary(cc.alloc, columns, row)
@@ -855,6 +1034,31 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 	return errors.Trace(cc.flush())
 }
 
+// writeColumnInfo writes the column count and the column definitions of a resultset, the charset
+// of the non-binary columns is set to character_set_results.
+func (cc *clientConn) writeColumnInfo(columns []*ColumnInfo) error {
+	if id, ok := mysql.CharsetIDs[strings.ToLower(cc.resultsCharset())]; ok {
+		for _, column := range columns {
+			if column.Charset != mysql.BinaryCollationID {
+				column.Charset = uint16(id)
+			}
+		}
+	}
+	data := cc.alloc.AllocWithLen(4, 1024)
+	data = append(data, dumpLengthEncodedInt(uint64(len(columns)))...)
+	if err := cc.writePacket(data); err != nil {
+		return errors.Trace(err)
+	}
+	for _, v := range columns {
+		data = data[0:4]
+		data = append(data, v.Dump(cc.alloc)...)
+		if err := cc.writePacket(data); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 func (cc *clientConn) writeMultiResultset(rss []ResultSet, binary bool) error {
 	for _, rs := range rss {
 		if err := cc.writeResultset(rs, binary, true); err != nil {
@@ -41,7 +41,9 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/types"
 )
 
 func (cc *clientConn) handleStmtPrepare(sql string) error {
@@ -118,10 +120,12 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 
 	flag := data[pos]
 	pos++
-	// Now we only support CURSOR_TYPE_NO_CURSOR flag.
-	if flag != 0 {
+	// Now we only support CURSOR_TYPE_NO_CURSOR and CURSOR_TYPE_READ_ONLY flags.
+	if flag != mysql.CursorTypeNoCursor && flag != mysql.CursorTypeReadOnly {
 		return mysql.NewErrf(mysql.ErrUnknown, "unsupported flag %d", flag)
 	}
+	// Executing a statement closes its open cursor.
+	cc.closeCursor(stmtID)
 
 	// skip iteration-count, always 1
 	pos += 4
@@ -162,6 +166,7 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 		if err != nil {
 			return errors.Trace(err)
 		}
+		cc.decodeStmtArgs(args, stmt.GetParamsType())
 	}
 	rs, err := stmt.Execute(args...)
 	if err != nil {
@@ -170,10 +175,115 @@ func (cc *clientConn) handleStmtExecute(data []byte) (err error) {
 	if rs == nil {
 		return errors.Trace(cc.writeOK())
 	}
+	if flag == mysql.CursorTypeReadOnly {
+		return errors.Trace(cc.openCursor(stmtID, rs))
+	}
 
 	return errors.Trace(cc.writeResultset(rs, true, false))
 }
 
+// stmtCursor is the open cursor of a statement executed with CURSOR_TYPE_READ_ONLY,
+// the rows of its resultset are sent by COM_STMT_FETCH.
+type stmtCursor struct {
+	columns []*ColumnInfo
+	// rows are the rows not sent yet.
+	rows [][]types.Datum
+}
+
+// openCursor reads all the rows of rs into the cursor of the statement like MySQL does, and writes
+// the column definitions of rs without any rows. The rows are read before the other statements run
+// on the session, so they can't change the transaction or the statement context of the cursor.
+// See https://dev.mysql.com/doc/internals/en/com-stmt-execute-response.html
+func (cc *clientConn) openCursor(stmtID uint32, rs ResultSet) error {
+	var rows [][]types.Datum
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			terror.Call(rs.Close)
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		rows = append(rows, row)
+	}
+	columns, err := rs.Columns()
+	if err != nil {
+		terror.Call(rs.Close)
+		return errors.Trace(err)
+	}
+	if err = rs.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	if cc.cursors == nil {
+		cc.cursors = make(map[uint32]*stmtCursor)
+	}
+	cc.cursors[stmtID] = &stmtCursor{columns: columns, rows: rows}
+
+	if err = cc.writeColumnInfo(columns); err != nil {
+		return errors.Trace(err)
+	}
+	if err = cc.writeEOFWithStatus(cc.ctx.Status() | mysql.ServerStatusCursorExists); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
+// closeCursor closes the open cursor of the statement if there is one.
+func (cc *clientConn) closeCursor(stmtID uint32) {
+	delete(cc.cursors, stmtID)
+}
+
+// closeCursors closes all the open cursors of the connection.
+func (cc *clientConn) closeCursors() {
+	for stmtID := range cc.cursors {
+		cc.closeCursor(stmtID)
+	}
+}
+
+// handleStmtFetch sends at most the requested number of rows from the open cursor of a statement,
+// the cursor is closed after its last row is sent.
+// See https://dev.mysql.com/doc/internals/en/com-stmt-fetch.html
+func (cc *clientConn) handleStmtFetch(data []byte) (err error) {
+	if len(data) < 8 {
+		return mysql.ErrMalformPacket
+	}
+
+	stmtID := binary.LittleEndian.Uint32(data[0:4])
+	numRows := binary.LittleEndian.Uint32(data[4:8])
+	cur, ok := cc.cursors[stmtID]
+	if !ok {
+		return mysql.NewErrf(mysql.ErrStmtHasNoOpenCursor, "The statement (%d) has no open cursor.", stmtID)
+	}
+
+	data = cc.alloc.AllocWithLen(4, 1024)
+	for i := uint32(0); i < numRows && len(cur.rows) > 0; i++ {
+		var rowData []byte
+		rowData, err = dumpRowValuesBinary(cc.alloc, cur.columns, cc.encodeRow(cur.columns, cur.rows[0]))
+		if err != nil {
+			cc.closeCursor(stmtID)
+			return errors.Trace(err)
+		}
+		data = append(data[0:4], rowData...)
+		if err = cc.writePacket(data); err != nil {
+			cc.closeCursor(stmtID)
+			return errors.Trace(err)
+		}
+		cur.rows[0] = nil
+		cur.rows = cur.rows[1:]
+	}
+
+	status := cc.ctx.Status() | mysql.ServerStatusCursorExists
+	if len(cur.rows) == 0 {
+		status |= mysql.ServerStatusLastRowSend
+		cc.closeCursor(stmtID)
+	}
+	if err = cc.writeEOFWithStatus(status); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
 func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTypes, paramValues []byte) (err error) {
 	pos := 0
 	var v []byte
@@ -310,13 +420,29 @@ func parseStmtArgs(args []interface{}, boundParams [][]byte, nullBitmap, paramTy
 	return
 }
 
+// decodeStmtArgs decodes the arguments of the non-binary string types from character_set_client
+// to UTF-8, the arguments of the BLOB types are binary strings like MySQL does.
+func (cc *clientConn) decodeStmtArgs(args []interface{}, paramTypes []byte) {
+	for i, arg := range args {
+		str, ok := arg.(string)
+		if !ok || (i<<1) >= len(paramTypes) {
+			continue
+		}
+		switch paramTypes[i<<1] {
+		case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString:
+			args[i] = cc.decodeInput(str)
+		}
+	}
+}
+
 func (cc *clientConn) handleStmtClose(data []byte) (err error) {
 	if len(data) < 4 {
 		return
 	}
 
-	stmtID := int(binary.LittleEndian.Uint32(data[0:4]))
-	stmt := cc.ctx.GetStatement(stmtID)
+	stmtID := binary.LittleEndian.Uint32(data[0:4])
+	cc.closeCursor(stmtID)
+	stmt := cc.ctx.GetStatement(int(stmtID))
 	if stmt != nil {
 		return errors.Trace(stmt.Close())
 	}
@@ -351,6 +477,7 @@ func (cc *clientConn) handleStmtReset(data []byte) (err error) {
 		return mysql.NewErr(mysql.ErrUnknownStmtHandler,
 			strconv.Itoa(stmtID), "stmt_reset")
 	}
+	cc.closeCursor(uint32(stmtID))
 	stmt.Reset()
 	return cc.writeOK()
 }
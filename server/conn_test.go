@@ -86,6 +86,7 @@ func (ts ConnTestSuite) TestParseHandshakeResponse(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(p.User, Equals, "pam")
 	c.Assert(p.DBName, Equals, "test")
+	c.Assert(p.AuthPlugin, Equals, mysql.AuthName)
 }
 
 func (ts ConnTestSuite) TestIssue1768(c *C) {
@@ -162,3 +163,58 @@ func mapBelong(m1, m2 map[string]string) bool {
 	}
 	return true
 }
+
+func (ts ConnTestSuite) TestCompressedPacketIO(c *C) {
+	c.Parallel()
+	var inBuffer bytes.Buffer
+	writer := &packetIO{bufWriter: bufio.NewWriter(&inBuffer), compress: true}
+	payloads := [][]byte{
+		[]byte("short"),
+		bytes.Repeat([]byte("compressible "), 1000),
+		make([]byte, mysql.MaxPayloadLen+100),
+	}
+	for _, payload := range payloads {
+		data := append(make([]byte, 4), payload...)
+		c.Assert(writer.writePacket(data), IsNil)
+	}
+	c.Assert(writer.flush(), IsNil)
+	// The payloads are split into 2 compressed packets.
+	c.Assert(writer.compressedSequence, Equals, uint8(2))
+	c.Assert(inBuffer.Len() < 4*len(payloads)+len(payloads[0])+len(payloads[1])+len(payloads[2]), IsTrue)
+	// The packet sequence continues from the compressed sequence after flush.
+	c.Assert(writer.sequence, Equals, writer.compressedSequence)
+
+	reader := &packetIO{
+		bufReadConn: &bufferedReadConn{rb: bufio.NewReader(&inBuffer)},
+		compress:    true,
+	}
+	for _, payload := range payloads {
+		data, err := reader.readPacket()
+		c.Assert(err, IsNil)
+		c.Assert(bytes.Equal(data, payload), IsTrue)
+	}
+	c.Assert(reader.compressedSequence, Equals, writer.compressedSequence)
+
+	// The packets are compressed before the flush once the buffer is large enough.
+	writer.resetSequence()
+	reader.resetSequence()
+	for i := 0; i < 100; i++ {
+		c.Assert(writer.writePacket(append(make([]byte, 4), payloads[1]...)), IsNil)
+		c.Assert(writer.compressedWriteBuf.Len() < defaultWriterSize, IsTrue)
+	}
+	c.Assert(writer.compressedSequence > 0, IsTrue)
+	c.Assert(writer.flush(), IsNil)
+	for i := 0; i < 100; i++ {
+		data, err := reader.readPacket()
+		c.Assert(err, IsNil)
+		c.Assert(bytes.Equal(data, payloads[1]), IsTrue)
+	}
+
+	// A short payload is not compressed, and a compressed packet with a wrong sequence is rejected.
+	writer.resetSequence()
+	c.Assert(writer.writePacket(append(make([]byte, 4), payloads[0]...)), IsNil)
+	c.Assert(writer.flush(), IsNil)
+	c.Assert(inBuffer.Bytes()[4:7], DeepEquals, []byte{0, 0, 0})
+	_, err := reader.readPacket()
+	c.Assert(err, NotNil)
+}
@@ -17,6 +17,7 @@ import (
 	"crypto/tls"
 	"fmt"
 
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
@@ -75,9 +76,16 @@ type QueryCtx interface {
 	// Close closes the QueryCtx.
 	Close() error
 
+	// Reset rolls back the current transaction, drops the prepared statements, user variables and
+	// session variables, the current user and database are kept.
+	Reset() error
+
 	// Auth verifies user's authentication.
 	Auth(user *auth.UserIdentity, auth []byte, salt []byte) bool
 
+	// AuthPlugin returns the authentication plugin of the user, it returns "" if the user doesn't exist.
+	AuthPlugin(user *auth.UserIdentity) string
+
 	// ShowProcess shows the information about the session.
 	ShowProcess() util.ProcessInfo
 
@@ -85,6 +93,9 @@ type QueryCtx interface {
 
 	// Cancel the execution of current transaction.
 	Cancel()
+
+	// GetSessionVars returns the session variables.
+	GetSessionVars() *variable.SessionVars
 }
 
 // PreparedStatement is the interface to use a prepared statement.
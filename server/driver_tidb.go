@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
@@ -223,11 +224,25 @@ func (tc *TiDBContext) Close() error {
 	return nil
 }
 
+// Reset implements QueryCtx Reset method.
+func (tc *TiDBContext) Reset() error {
+	if err := tc.session.Reset(); err != nil {
+		return errors.Trace(err)
+	}
+	tc.stmts = make(map[int]*TiDBStatement)
+	return nil
+}
+
 // Auth implements QueryCtx Auth method.
 func (tc *TiDBContext) Auth(user *auth.UserIdentity, auth []byte, salt []byte) bool {
 	return tc.session.Auth(user, auth, salt)
 }
 
+// AuthPlugin implements QueryCtx AuthPlugin method.
+func (tc *TiDBContext) AuthPlugin(user *auth.UserIdentity) string {
+	return tc.session.AuthPlugin(user)
+}
+
 // FieldList implements QueryCtx FieldList method.
 func (tc *TiDBContext) FieldList(table string) (colums []*ColumnInfo, err error) {
 	rs, err := tc.Execute("SELECT * FROM `" + table + "` LIMIT 0")
@@ -287,6 +302,11 @@ func (tc *TiDBContext) Cancel() {
 	tc.session.Cancel()
 }
 
+// GetSessionVars implements QueryCtx GetSessionVars method.
+func (tc *TiDBContext) GetSessionVars() *variable.SessionVars {
+	return tc.session.GetSessionVars()
+}
+
 type tidbResultSet struct {
 	recordSet ast.RecordSet
 }
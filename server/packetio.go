@@ -36,19 +36,38 @@ package server
 
 import (
 	"bufio"
+	"bytes"
+	"compress/zlib"
 	"io"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
 )
 
-const defaultWriterSize = 16 * 1024
+const (
+	defaultWriterSize = 16 * 1024
+	// minCompressLength is the minimal payload length to compress in the compressed protocol,
+	// shorter payloads are sent uncompressed.
+	minCompressLength = 50
+	// compressedHeaderSize is the size of the header of a compressed packet.
+	compressedHeaderSize = 7
+)
 
 // packetIO is a helper to read and write data in packet format.
+// If compress is true, the packets are framed in compressed packets, which have their own sequence numbers.
+// See https://dev.mysql.com/doc/internals/en/compressed-packet-header.html
 type packetIO struct {
 	bufReadConn *bufferedReadConn
 	bufWriter   *bufio.Writer
 	sequence    uint8
+
+	compress           bool
+	compressedSequence uint8
+	// compressedReader holds the uncompressed payload of the last compressed packet read.
+	compressedReader bytes.Reader
+	// compressedWriteBuf buffers the packets written until they are flushed or the buffer reaches
+	// defaultWriterSize, then they are written as compressed packets.
+	compressedWriteBuf bytes.Buffer
 }
 
 func newPacketIO(bufReadConn *bufferedReadConn) *packetIO {
@@ -62,15 +81,22 @@ func (p *packetIO) setBufferedReadConn(bufReadConn *bufferedReadConn) {
 	p.bufWriter = bufio.NewWriterSize(bufReadConn, defaultWriterSize)
 }
 
+// resetSequence resets the sequence numbers at the beginning of a command.
+func (p *packetIO) resetSequence() {
+	p.sequence = 0
+	p.compressedSequence = 0
+}
+
 func (p *packetIO) readOnePacket() ([]byte, error) {
 	var header [4]byte
 
-	if _, err := io.ReadFull(p.bufReadConn, header[:]); err != nil {
+	if err := p.readFull(header[:]); err != nil {
 		return nil, errors.Trace(err)
 	}
 
+	// Like MySQL, the sequence of the packets in compressed packets is not checked.
 	sequence := header[3]
-	if sequence != p.sequence {
+	if sequence != p.sequence && !p.compress {
 		return nil, errInvalidSequence.Gen("invalid sequence %d != %d", sequence, p.sequence)
 	}
 
@@ -79,12 +105,72 @@ func (p *packetIO) readOnePacket() ([]byte, error) {
 	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
 
 	data := make([]byte, length)
-	if _, err := io.ReadFull(p.bufReadConn, data); err != nil {
+	if err := p.readFull(data); err != nil {
 		return nil, errors.Trace(err)
 	}
 	return data, nil
 }
 
+// readFull reads exactly len(buf) bytes of packet data, from the uncompressed payloads of
+// the compressed packets if compress is true.
+func (p *packetIO) readFull(buf []byte) error {
+	if !p.compress {
+		_, err := io.ReadFull(p.bufReadConn, buf)
+		return errors.Trace(err)
+	}
+	for len(buf) > 0 {
+		if p.compressedReader.Len() == 0 {
+			if err := p.readCompressedPacket(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		n, err := p.compressedReader.Read(buf)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		buf = buf[n:]
+	}
+	return nil
+}
+
+// readCompressedPacket reads a compressed packet and puts its uncompressed payload in compressedReader.
+func (p *packetIO) readCompressedPacket() error {
+	var header [compressedHeaderSize]byte
+	if _, err := io.ReadFull(p.bufReadConn, header[:]); err != nil {
+		return errors.Trace(err)
+	}
+
+	sequence := header[3]
+	if sequence != p.compressedSequence {
+		return errInvalidSequence.Gen("invalid compressed sequence %d != %d", sequence, p.compressedSequence)
+	}
+	p.compressedSequence++
+
+	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	// The uncompressed length is 0 if the payload is not compressed.
+	uncompressedLength := int(uint32(header[4]) | uint32(header[5])<<8 | uint32(header[6])<<16)
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(p.bufReadConn, data); err != nil {
+		return errors.Trace(err)
+	}
+	if uncompressedLength > 0 {
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		data = make([]byte, uncompressedLength)
+		if _, err = io.ReadFull(r, data); err != nil {
+			return errors.Trace(err)
+		}
+		if err = r.Close(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	p.compressedReader.Reset(data)
+	return nil
+}
+
 func (p *packetIO) readPacket() ([]byte, error) {
 	data, err := p.readOnePacket()
 	if err != nil {
@@ -114,6 +200,11 @@ func (p *packetIO) readPacket() ([]byte, error) {
 
 // writePacket writes data that already have header
 func (p *packetIO) writePacket(data []byte) error {
+	// The packets are buffered and compressed when they are flushed or the buffer is large enough.
+	var w io.Writer = p.bufWriter
+	if p.compress {
+		w = &p.compressedWriteBuf
+	}
 	length := len(data) - 4
 
 	for length >= mysql.MaxPayloadLen {
@@ -123,7 +214,7 @@ func (p *packetIO) writePacket(data []byte) error {
 
 		data[3] = p.sequence
 
-		if n, err := p.bufWriter.Write(data[:4+mysql.MaxPayloadLen]); err != nil {
+		if n, err := w.Write(data[:4+mysql.MaxPayloadLen]); err != nil {
 			return mysql.ErrBadConn
 		} else if n != (4 + mysql.MaxPayloadLen) {
 			return mysql.ErrBadConn
@@ -139,16 +230,75 @@ func (p *packetIO) writePacket(data []byte) error {
 	data[2] = byte(length >> 16)
 	data[3] = p.sequence
 
-	if n, err := p.bufWriter.Write(data); err != nil {
+	if n, err := w.Write(data); err != nil {
 		return errors.Trace(mysql.ErrBadConn)
 	} else if n != len(data) {
 		return errors.Trace(mysql.ErrBadConn)
-	} else {
-		p.sequence++
-		return nil
 	}
+	p.sequence++
+	// Compress the buffered packets before the buffer grows too large, so a large resultset
+	// written before a flush is not buffered in memory as a whole.
+	if p.compress && p.compressedWriteBuf.Len() >= defaultWriterSize {
+		return errors.Trace(p.writeCompressedPackets())
+	}
+	return nil
 }
 
 func (p *packetIO) flush() error {
+	if p.compress {
+		if err := p.writeCompressedPackets(); err != nil {
+			return errors.Trace(err)
+		}
+		// Like MySQL, the sequence of the following packets continues from the compressed sequence.
+		p.sequence = p.compressedSequence
+	}
 	return p.bufWriter.Flush()
 }
+
+// writeCompressedPackets writes the buffered packets as compressed packets. A payload is sent
+// uncompressed if it is shorter than minCompressLength or compressing doesn't make it shorter.
+func (p *packetIO) writeCompressedPackets() error {
+	data := p.compressedWriteBuf.Bytes()
+	if len(data) == 0 {
+		return nil
+	}
+	var compressBuf bytes.Buffer
+	for len(data) > 0 {
+		payload := data
+		if len(payload) > mysql.MaxPayloadLen {
+			payload = payload[:mysql.MaxPayloadLen]
+		}
+		data = data[len(payload):]
+
+		uncompressedLength := 0
+		if len(payload) >= minCompressLength {
+			compressBuf.Reset()
+			zw := zlib.NewWriter(&compressBuf)
+			if _, err := zw.Write(payload); err != nil {
+				return errors.Trace(err)
+			}
+			if err := zw.Close(); err != nil {
+				return errors.Trace(err)
+			}
+			if compressBuf.Len() < len(payload) {
+				uncompressedLength = len(payload)
+				payload = compressBuf.Bytes()
+			}
+		}
+
+		length := len(payload)
+		header := [compressedHeaderSize]byte{
+			byte(length), byte(length >> 8), byte(length >> 16), p.compressedSequence,
+			byte(uncompressedLength), byte(uncompressedLength >> 8), byte(uncompressedLength >> 16),
+		}
+		if _, err := p.bufWriter.Write(header[:]); err != nil {
+			return errors.Trace(mysql.ErrBadConn)
+		}
+		if _, err := p.bufWriter.Write(payload); err != nil {
+			return errors.Trace(mysql.ErrBadConn)
+		}
+		p.compressedSequence++
+	}
+	p.compressedWriteBuf.Reset()
+	return nil
+}
@@ -29,6 +29,7 @@
 package server
 
 import (
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
@@ -70,7 +71,7 @@ const defaultCapability = mysql.ClientLongPassword | mysql.ClientLongFlag |
 	mysql.ClientConnectWithDB | mysql.ClientProtocol41 |
 	mysql.ClientTransactions | mysql.ClientSecureConnection | mysql.ClientFoundRows |
 	mysql.ClientMultiStatements | mysql.ClientMultiResults | mysql.ClientLocalFiles |
-	mysql.ClientConnectAtts | mysql.ClientPluginAuth
+	mysql.ClientConnectAtts | mysql.ClientPluginAuth | mysql.ClientCompress
 
 // Server is the MySQL protocol server
 type Server struct {
@@ -83,6 +84,12 @@ type Server struct {
 	clients           map[uint32]*clientConn
 	capability        uint32
 
+	// The RSA key used by caching_sha2_password and sha256_password, see getRSAKey.
+	rsaKeyOnce      sync.Once
+	rsaKey          *rsa.PrivateKey
+	rsaPublicKeyPEM []byte
+	rsaKeyErr       error
+
 	// When a critical error occurred, we don't want to exit the process, because there may be
 	// a supervisor automatically restart it, then new client connection will be created, but we can't server it.
 	// So we just stop the listener and store to force clients to chose other TiDB servers.
@@ -286,6 +286,27 @@ func runTestClientWithCollation(t *C) {
 	})
 }
 
+func runTestClientWithGBK(t *C) {
+	runTestsOnNewDB(t, func(config *mysql.Config) {
+		config.Collation = "gbk_chinese_ci"
+	}, "ClientWithGBK", func(dbt *DBTest) {
+		// "中文" encoded in GBK.
+		gbkStr := "\xd6\xd0\xce\xc4"
+		dbt.mustExec("create table test (a varchar(10) charset gbk, b varchar(10) charset utf8mb4)")
+		dbt.mustExec("insert test values ('" + gbkStr + "', '" + gbkStr + "')")
+		rows := dbt.mustQuery("select a, b, char_length(a) from test where a = ?", gbkStr)
+		t.Assert(rows.Next(), IsTrue)
+		var outA, outB []byte
+		var length int
+		err := rows.Scan(&outA, &outB, &length)
+		t.Assert(err, IsNil)
+		t.Assert(string(outA), Equals, gbkStr)
+		t.Assert(string(outB), Equals, gbkStr)
+		t.Assert(length, Equals, 2)
+		t.Assert(rows.Close(), IsNil)
+	})
+}
+
 func runTestPreparedString(t *C) {
 	runTestsOnNewDB(t, nil, "PreparedString", func(dbt *DBTest) {
 		dbt.mustExec("create table test (a char(10), b char(10))")
@@ -15,15 +15,23 @@
 package server
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha1"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/binary"
 	"encoding/pem"
+	"fmt"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
@@ -32,6 +40,8 @@ import (
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/config"
 	tmysql "github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tidb/util/auth"
 )
 
 type TidbTestSuite struct {
@@ -373,6 +383,11 @@ func (ts *TidbTestSuite) TestClientWithCollation(c *C) {
 	runTestClientWithCollation(c)
 }
 
+func (ts *TidbTestSuite) TestClientWithGBK(c *C) {
+	c.Parallel()
+	runTestClientWithGBK(c)
+}
+
 func (ts *TidbTestSuite) TestShowCreateTableFlen(c *C) {
 	// issue #4540
 	ctx, err := ts.tidbdrv.OpenCtx(uint64(0), 0, uint8(tmysql.DefaultCollationID), "test", nil)
@@ -418,3 +433,467 @@ func (ts *TidbTestSuite) TestShowCreateTableFlen(c *C) {
 	c.Assert(int(cols[0].ColumnLength), Equals, 5*tmysql.MaxBytesOfCharacter)
 	c.Assert(int(cols[1].ColumnLength), Equals, len(row[1].GetString())*tmysql.MaxBytesOfCharacter)
 }
+
+func (ts *TidbTestSuite) TestCursorFetch(c *C) {
+	ctx, err := ts.tidbdrv.OpenCtx(uint64(0), tmysql.ClientProtocol41, uint8(tmysql.DefaultCollationID), "test", nil)
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("use test")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("create table cursor_t (a int)")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("insert cursor_t values (1), (2), (3), (4), (5)")
+	c.Assert(err, IsNil)
+	stmt, _, _, err := ctx.Prepare("select a from cursor_t order by a")
+	c.Assert(err, IsNil)
+
+	var outBuffer bytes.Buffer
+	cc := &clientConn{
+		server:     ts.server,
+		capability: tmysql.ClientProtocol41,
+		alloc:      arena.NewAllocator(32 * 1024),
+		ctx:        ctx,
+		pkt: &packetIO{
+			bufWriter: bufio.NewWriter(&outBuffer),
+		},
+	}
+	// readPackets returns the payloads of the packets written since the last call.
+	readPackets := func() [][]byte {
+		var packets [][]byte
+		data := outBuffer.Bytes()
+		for len(data) > 0 {
+			length := int(data[0]) | int(data[1])<<8 | int(data[2])<<16
+			packets = append(packets, data[4:4+length])
+			data = data[4+length:]
+		}
+		outBuffer.Reset()
+		return packets
+	}
+	eofStatus := func(packet []byte) uint16 {
+		c.Assert(packet[0], Equals, tmysql.EOFHeader)
+		return binary.LittleEndian.Uint16(packet[3:5])
+	}
+	stmtID := make([]byte, 4)
+	binary.LittleEndian.PutUint32(stmtID, uint32(stmt.ID()))
+	execute := append(append([]byte{tmysql.ComStmtExecute}, stmtID...), tmysql.CursorTypeReadOnly, 1, 0, 0, 0)
+	fetch := func(numRows uint32) []byte {
+		data := append([]byte{tmysql.ComStmtFetch}, stmtID...)
+		return append(data, byte(numRows), byte(numRows>>8), byte(numRows>>16), byte(numRows>>24))
+	}
+	// checkRows checks the binary rows packets of a single INT column.
+	checkRows := func(packets [][]byte, expected ...uint32) {
+		c.Assert(packets, HasLen, len(expected))
+		for i, packet := range packets {
+			c.Assert(packet[0], Equals, byte(0))
+			c.Assert(binary.LittleEndian.Uint32(packet[len(packet)-4:]), Equals, expected[i])
+		}
+	}
+
+	// Executing with a cursor sends the columns without any rows.
+	c.Assert(cc.dispatch(execute), IsNil)
+	packets := readPackets()
+	c.Assert(packets, HasLen, 3)
+	c.Assert(eofStatus(packets[2])&tmysql.ServerStatusCursorExists, Equals, tmysql.ServerStatusCursorExists)
+
+	c.Assert(cc.dispatch(fetch(2)), IsNil)
+	packets = readPackets()
+	checkRows(packets[:len(packets)-1], 1, 2)
+	status := eofStatus(packets[len(packets)-1])
+	c.Assert(status&tmysql.ServerStatusCursorExists, Equals, tmysql.ServerStatusCursorExists)
+	c.Assert(status&tmysql.ServerStatusLastRowSend, Equals, uint16(0))
+
+	// The statements run between the fetches don't change the rows of the cursor.
+	c.Assert(cc.dispatch(append([]byte{tmysql.ComQuery}, "delete from cursor_t where a = 4"...)), IsNil)
+	c.Assert(readPackets()[0][0], Equals, tmysql.OKHeader)
+	c.Assert(cc.dispatch(append([]byte{tmysql.ComQuery}, "select a from cursor_t"...)), IsNil)
+	readPackets()
+
+	c.Assert(cc.dispatch(fetch(10)), IsNil)
+	packets = readPackets()
+	checkRows(packets[:len(packets)-1], 3, 4, 5)
+	status = eofStatus(packets[len(packets)-1])
+	c.Assert(status&tmysql.ServerStatusLastRowSend, Equals, tmysql.ServerStatusLastRowSend)
+
+	// The cursor is closed after the last row is sent.
+	err = cc.dispatch(fetch(1))
+	c.Assert(err.(*tmysql.SQLError).Code, Equals, uint16(tmysql.ErrStmtHasNoOpenCursor))
+
+	// Re-executing the statement opens a new cursor, and closing the statement closes it.
+	c.Assert(cc.dispatch(execute), IsNil)
+	readPackets()
+	c.Assert(cc.dispatch(fetch(3)), IsNil)
+	packets = readPackets()
+	checkRows(packets[:len(packets)-1], 1, 2, 3)
+	c.Assert(cc.cursors, HasLen, 1)
+	c.Assert(cc.dispatch(append([]byte{tmysql.ComStmtClose}, stmtID...)), IsNil)
+	c.Assert(cc.cursors, HasLen, 0)
+}
+
+func (ts *TidbTestSuite) TestStmtArgsWithGBK(c *C) {
+	ctx, err := ts.tidbdrv.OpenCtx(uint64(0), tmysql.ClientProtocol41, uint8(tmysql.DefaultCollationID), "test", nil)
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("use test")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("set names gbk")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("create table stmt_gbk_t (b blob, s varchar(10) charset gbk)")
+	c.Assert(err, IsNil)
+	stmt, _, _, err := ctx.Prepare("insert stmt_gbk_t values (?, ?)")
+	c.Assert(err, IsNil)
+
+	var outBuffer bytes.Buffer
+	cc := &clientConn{
+		server:     ts.server,
+		capability: tmysql.ClientProtocol41,
+		alloc:      arena.NewAllocator(32 * 1024),
+		ctx:        ctx,
+		pkt: &packetIO{
+			bufWriter: bufio.NewWriter(&outBuffer),
+		},
+	}
+	// The BLOB argument is sent as it is, and the VAR_STRING argument is "中文" encoded in GBK.
+	blob := "\xff\x80\x00\x81"
+	gbkStr := "\xd6\xd0\xce\xc4"
+	data := []byte{tmysql.ComStmtExecute}
+	data = append(data, byte(stmt.ID()), byte(stmt.ID()>>8), byte(stmt.ID()>>16), byte(stmt.ID()>>24))
+	data = append(data, 0, 1, 0, 0, 0)
+	data = append(data, 0, 1, tmysql.TypeBlob, 0, tmysql.TypeVarString, 0)
+	data = append(append(data, byte(len(blob))), blob...)
+	data = append(append(data, byte(len(gbkStr))), gbkStr...)
+	c.Assert(cc.dispatch(data), IsNil)
+
+	rs, err := ctx.Execute("select hex(b), hex(s) from stmt_gbk_t")
+	c.Assert(err, IsNil)
+	row, err := rs[0].Next()
+	c.Assert(err, IsNil)
+	c.Assert(row[0].GetString(), Equals, "FF800081")
+	c.Assert(row[1].GetString(), Equals, "D6D0CEC4")
+}
+
+// countingConn counts the bytes read from a net.Conn.
+type countingConn struct {
+	net.Conn
+	bytesRead int
+}
+
+func (conn *countingConn) Read(b []byte) (int, error) {
+	n, err := conn.Conn.Read(b)
+	conn.bytesRead += n
+	return n, err
+}
+
+func (ts *TidbTestSuite) TestCompressedProtocol(c *C) {
+	ctx, err := ts.tidbdrv.OpenCtx(uint64(0), 0, uint8(tmysql.DefaultCollationID), "test", nil)
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("use test")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("create table compress_t (id int primary key, v varchar(1024))")
+	c.Assert(err, IsNil)
+	const rowCount = 2000
+	for i := 0; i < rowCount; i += 100 {
+		values := make([]string, 0, 100)
+		for j := i; j < i+100; j++ {
+			values = append(values, fmt.Sprintf("(%d, repeat('%d', 1000))", j, j%10))
+		}
+		_, err = ctx.Execute("insert compress_t values " + strings.Join(values, ","))
+		c.Assert(err, IsNil)
+	}
+
+	// The client side of the compressed protocol is a packetIO too.
+	netConn, err := net.Dial("tcp", "127.0.0.1:4001")
+	c.Assert(err, IsNil)
+	conn := &countingConn{Conn: netConn}
+	defer conn.Close()
+	pkt := newPacketIO(newBufferedReadConn(conn))
+	_, err = pkt.readPacket()
+	c.Assert(err, IsNil)
+
+	capability := tmysql.ClientProtocol41 | tmysql.ClientSecureConnection | tmysql.ClientCompress
+	response := make([]byte, 4, 64)
+	response = append(response, dumpUint32(capability)...)
+	response = append(response, dumpUint32(uint32(tmysql.MaxPayloadLen))...)
+	response = append(response, tmysql.DefaultCollationID)
+	response = append(response, make([]byte, 23)...)
+	response = append(response, "root"...)
+	// The terminator of the user name and the length of the empty auth data.
+	response = append(response, 0, 0)
+	c.Assert(pkt.writePacket(response), IsNil)
+	c.Assert(pkt.flush(), IsNil)
+	data, err := pkt.readPacket()
+	c.Assert(err, IsNil)
+	c.Assert(data[0], Equals, tmysql.OKHeader)
+
+	pkt.compress = true
+	pkt.resetSequence()
+	query := "select id, v from test.compress_t order by id"
+	c.Assert(pkt.writePacket(append(append(make([]byte, 4), tmysql.ComQuery), query...)), IsNil)
+	c.Assert(pkt.flush(), IsNil)
+
+	readBefore := conn.bytesRead
+	// Column count, 2 column definitions and EOF.
+	for i := 0; i < 4; i++ {
+		_, err = pkt.readPacket()
+		c.Assert(err, IsNil)
+	}
+	payloadLen := 0
+	for i := 0; ; i++ {
+		data, err = pkt.readPacket()
+		c.Assert(err, IsNil)
+		payloadLen += len(data)
+		if data[0] == tmysql.EOFHeader && len(data) < 9 {
+			c.Assert(i, Equals, rowCount)
+			break
+		}
+		id, _, n := parseLengthEncodedInt(data)
+		c.Assert(string(data[n:n+int(id)]), Equals, strconv.Itoa(i))
+		v, _, _, err := parseLengthEncodedBytes(data[n+int(id):])
+		c.Assert(err, IsNil)
+		c.Assert(string(v), Equals, strings.Repeat(strconv.Itoa(i%10), 1000))
+	}
+	c.Assert(conn.bytesRead-readBefore < payloadLen/10, IsTrue)
+	// The resultset is sent in many compressed packets as it's written, rather than buffered
+	// and compressed as a whole when it's flushed.
+	c.Assert(pkt.compressedSequence > 10, IsTrue, Commentf("%d", pkt.compressedSequence))
+}
+
+func (ts *TidbTestSuite) TestChangeUserAndResetConnection(c *C) {
+	ctx, err := ts.tidbdrv.OpenCtx(uint64(0), 0, uint8(tmysql.DefaultCollationID), "test", nil)
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("use test")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("create table reset_t (a int)")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("create user 'change_user'@'%' identified by '123'")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("grant select on test.* to 'change_user'@'%'")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("flush privileges")
+	c.Assert(err, IsNil)
+
+	var pkt *packetIO
+	var salt []byte
+	// connect connects to the server as root and records the salt of the initial handshake.
+	connect := func() net.Conn {
+		netConn, err := net.Dial("tcp", "127.0.0.1:4001")
+		c.Assert(err, IsNil)
+		pkt = newPacketIO(newBufferedReadConn(netConn))
+		data, err := pkt.readPacket()
+		c.Assert(err, IsNil)
+		// The salt is split into the auth-plugin-data-part-1 and part-2 of the initial handshake.
+		pos := 1 + bytes.IndexByte(data[1:], 0) + 1 + 4
+		salt = append([]byte{}, data[pos:pos+8]...)
+		pos += 8 + 1 + 2 + 1 + 2 + 2 + 1 + 10
+		salt = append(salt, data[pos:pos+12]...)
+
+		capability := tmysql.ClientProtocol41 | tmysql.ClientSecureConnection | tmysql.ClientConnectWithDB
+		response := make([]byte, 4, 64)
+		response = append(response, dumpUint32(capability)...)
+		response = append(response, dumpUint32(uint32(tmysql.MaxPayloadLen))...)
+		response = append(response, tmysql.DefaultCollationID)
+		response = append(response, make([]byte, 23)...)
+		response = append(response, "root"...)
+		response = append(response, 0, 0)
+		response = append(response, "test"...)
+		response = append(response, 0)
+		c.Assert(pkt.writePacket(response), IsNil)
+		c.Assert(pkt.flush(), IsNil)
+		data, err = pkt.readPacket()
+		c.Assert(err, IsNil)
+		c.Assert(data[0], Equals, tmysql.OKHeader)
+		return netConn
+	}
+	scramble := func(password string) []byte {
+		stage1 := auth.Sha1Hash([]byte(password))
+		hash := auth.Sha1Hash(append(append([]byte{}, salt...), auth.Sha1Hash(stage1)...))
+		for i := range hash {
+			hash[i] ^= stage1[i]
+		}
+		return hash
+	}
+	netConn := connect()
+	defer netConn.Close()
+
+	// command sends a command and returns the first packet of the response.
+	command := func(cmd byte, payload []byte) []byte {
+		pkt.resetSequence()
+		c.Assert(pkt.writePacket(append(append(make([]byte, 4), cmd), payload...)), IsNil)
+		c.Assert(pkt.flush(), IsNil)
+		data, err := pkt.readPacket()
+		c.Assert(err, IsNil)
+		return data
+	}
+	// query returns the single value of the result set of sql, "NULL" stands for a NULL value.
+	query := func(sql string) string {
+		c.Assert(command(tmysql.ComQuery, []byte(sql))[0], Equals, byte(1))
+		var value string
+		// The column definition, EOF and the row.
+		for i := 0; i < 3; i++ {
+			data, err := pkt.readPacket()
+			c.Assert(err, IsNil)
+			if i == 2 {
+				if data[0] == 0xfb {
+					value = "NULL"
+				} else {
+					v, _, _, err := parseLengthEncodedBytes(data)
+					c.Assert(err, IsNil)
+					value = string(v)
+				}
+			}
+		}
+		data, err := pkt.readPacket()
+		c.Assert(err, IsNil)
+		c.Assert(data[0], Equals, tmysql.EOFHeader)
+		return value
+	}
+	exec := func(sql string) {
+		c.Assert(command(tmysql.ComQuery, []byte(sql))[0], Equals, tmysql.OKHeader)
+	}
+
+	exec("set @a = 1")
+	exec("set @@session.tidb_index_join_batch_size = 10")
+	exec("begin")
+	exec("insert reset_t values (1)")
+	c.Assert(query("select count(*) from reset_t"), Equals, "1")
+	c.Assert(command(tmysql.ComStmtPrepare, []byte("select 1"))[0], Equals, tmysql.OKHeader)
+	// Skip the column definition and EOF of the prepared statement.
+	for i := 0; i < 2; i++ {
+		_, err = pkt.readPacket()
+		c.Assert(err, IsNil)
+	}
+
+	// Resetting the connection rolls back the transaction and drops the session state.
+	c.Assert(command(tmysql.ComResetConnection, nil)[0], Equals, tmysql.OKHeader)
+	c.Assert(query("select count(*) from reset_t"), Equals, "0")
+	c.Assert(query("select @a"), Equals, "NULL")
+	c.Assert(query("select @@session.tidb_index_join_batch_size"), Equals, "25000")
+	c.Assert(query("select database()"), Equals, "test")
+	c.Assert(query("select current_user()"), Equals, "root@127.0.0.1")
+	stmtID := []byte{1, 0, 0, 0}
+	c.Assert(command(tmysql.ComStmtExecute, append(stmtID, 0, 1, 0, 0, 0))[0], Equals, byte(0xff))
+
+	changeUser := func(user, password, db string) []byte {
+		payload := append([]byte(user), 0)
+		authData := scramble(password)
+		payload = append(payload, byte(len(authData)))
+		payload = append(payload, authData...)
+		payload = append(payload, db...)
+		payload = append(payload, 0)
+		payload = append(payload, dumpUint16(tmysql.DefaultCollationID)...)
+		return command(tmysql.ComChangeUser, payload)
+	}
+	c.Assert(changeUser("change_user", "123", "test")[0], Equals, tmysql.OKHeader)
+	c.Assert(query("select current_user()"), Equals, "change_user@127.0.0.1")
+	c.Assert(query("select database()"), Equals, "test")
+	c.Assert(query("select @a"), Equals, "NULL")
+	c.Assert(command(tmysql.ComQuery, []byte("insert reset_t values (2)"))[0], Equals, byte(0xff))
+
+	// The connection is closed after the error if the authentication fails.
+	c.Assert(changeUser("change_user", "wrong", "")[0], Equals, byte(0xff))
+	_, err = pkt.readPacket()
+	c.Assert(err, NotNil)
+
+	// The connection is also closed if the database of the new user can't be used,
+	// the new user must not take over the connection.
+	netConn = connect()
+	defer netConn.Close()
+	c.Assert(changeUser("change_user", "123", "no_such_db")[0], Equals, byte(0xff))
+	_, err = pkt.readPacket()
+	c.Assert(err, NotNil)
+}
+
+func (ts *TidbTestSuite) TestCachingSha2Auth(c *C) {
+	ctx, err := ts.tidbdrv.OpenCtx(uint64(0), 0, uint8(tmysql.DefaultCollationID), "test", nil)
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("create user 'sha2_user'@'%' identified with 'caching_sha2_password' by '123'")
+	c.Assert(err, IsNil)
+	_, err = ctx.Execute("flush privileges")
+	c.Assert(err, IsNil)
+
+	// connect sends the handshake response of the plugin and returns the packet IO and the salt.
+	connect := func(plugin string, authData func(salt []byte) []byte) (*packetIO, []byte, []byte) {
+		netConn, err := net.Dial("tcp", "127.0.0.1:4001")
+		c.Assert(err, IsNil)
+		pkt := newPacketIO(newBufferedReadConn(netConn))
+		data, err := pkt.readPacket()
+		c.Assert(err, IsNil)
+		pos := 1 + bytes.IndexByte(data[1:], 0) + 1 + 4
+		salt := append([]byte{}, data[pos:pos+8]...)
+		pos += 8 + 1 + 2 + 1 + 2 + 2 + 1 + 10
+		salt = append(salt, data[pos:pos+12]...)
+
+		capability := tmysql.ClientProtocol41 | tmysql.ClientSecureConnection | tmysql.ClientPluginAuth
+		response := make([]byte, 4, 128)
+		response = append(response, dumpUint32(capability)...)
+		response = append(response, dumpUint32(uint32(tmysql.MaxPayloadLen))...)
+		response = append(response, tmysql.DefaultCollationID)
+		response = append(response, make([]byte, 23)...)
+		response = append(response, "sha2_user"...)
+		response = append(response, 0)
+		authResp := authData(salt)
+		response = append(response, byte(len(authResp)))
+		response = append(response, authResp...)
+		response = append(response, plugin...)
+		response = append(response, 0)
+		c.Assert(pkt.writePacket(response), IsNil)
+		c.Assert(pkt.flush(), IsNil)
+		data, err = pkt.readPacket()
+		c.Assert(err, IsNil)
+		return pkt, salt, data
+	}
+	write := func(pkt *packetIO, payload []byte) []byte {
+		c.Assert(pkt.writePacket(append(make([]byte, 4), payload...)), IsNil)
+		c.Assert(pkt.flush(), IsNil)
+		data, err := pkt.readPacket()
+		c.Assert(err, IsNil)
+		return data
+	}
+	sha2Scramble := func(password string) func(salt []byte) []byte {
+		return func(salt []byte) []byte {
+			stage1 := auth.Sha256Hash([]byte(password))
+			hash := auth.Sha256Hash(append(auth.Sha256Hash(stage1), salt...))
+			for i := range hash {
+				hash[i] ^= stage1[i]
+			}
+			return hash
+		}
+	}
+	// fullAuth requests the public key and sends the encrypted password.
+	fullAuth := func(pkt *packetIO, salt []byte, password string) []byte {
+		data := write(pkt, []byte{0x02})
+		c.Assert(data[0], Equals, tmysql.AuthMoreDataHeader)
+		block, _ := pem.Decode(data[1:])
+		c.Assert(block, NotNil)
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		c.Assert(err, IsNil)
+		plain := append([]byte(password), 0)
+		for i := range plain {
+			plain[i] ^= salt[i%len(salt)]
+		}
+		enc, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, pub.(*rsa.PublicKey), plain, nil)
+		c.Assert(err, IsNil)
+		return write(pkt, enc)
+	}
+
+	// The client is asked to switch to caching_sha2_password, and the scramble can't be verified
+	// before the user is authenticated by the plaintext password.
+	pkt, salt, data := connect(tmysql.AuthName, func([]byte) []byte { return make([]byte, 20) })
+	c.Assert(data[0], Equals, tmysql.AuthSwitchHeader)
+	c.Assert(string(data[1:1+bytes.IndexByte(data[1:], 0)]), Equals, tmysql.AuthCachingSha2Password)
+	data = write(pkt, sha2Scramble("123")(salt))
+	c.Assert(data, DeepEquals, []byte{tmysql.AuthMoreDataHeader, 0x04})
+	c.Assert(fullAuth(pkt, salt, "123")[0], Equals, tmysql.OKHeader)
+	pkt.bufReadConn.Close()
+
+	// The fast authentication succeeds after the full authentication.
+	pkt, _, data = connect(tmysql.AuthCachingSha2Password, sha2Scramble("123"))
+	c.Assert(data, DeepEquals, []byte{tmysql.AuthMoreDataHeader, 0x03})
+	data, err = pkt.readPacket()
+	c.Assert(err, IsNil)
+	c.Assert(data[0], Equals, tmysql.OKHeader)
+	pkt.bufReadConn.Close()
+
+	// A wrong password falls back to the full authentication and fails.
+	pkt, salt, data = connect(tmysql.AuthCachingSha2Password, sha2Scramble("456"))
+	c.Assert(data, DeepEquals, []byte{tmysql.AuthMoreDataHeader, 0x04})
+	c.Assert(fullAuth(pkt, salt, "456")[0], Equals, tmysql.ErrHeader)
+	pkt.bufReadConn.Close()
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/perfschema"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/sessionctx"
@@ -77,7 +78,12 @@ type Session interface {
 	SetCollation(coID int) error
 	SetSessionManager(util.SessionManager)
 	Close()
+	// Reset rolls back the current transaction and resets the session to its initial state,
+	// the user, the current database and the connection settings are kept.
+	Reset() error
 	Auth(user *auth.UserIdentity, auth []byte, salt []byte) bool
+	// AuthPlugin returns the authentication plugin of the user, it returns "" if the user doesn't exist.
+	AuthPlugin(user *auth.UserIdentity) string
 	// Cancel the execution of current transaction.
 	Cancel()
 	ShowProcess() util.ProcessInfo
@@ -656,6 +662,9 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 	rawStmts, err := s.ParseSQL(sql, charset, collation)
 	if err != nil {
 		log.Warnf("[%d] parse error:\n%v\n%s", connID, err, sql)
+		// The statement which fails to parse is recorded as an error statement, same as MySQL.
+		event := s.startStmtEvent("statement/sql/error", sql, startTS)
+		endStmtEvent(event, nil, err)
 		return nil, errors.Trace(err)
 	}
 	sessionExecuteParseDuration.Observe(time.Since(startTS).Seconds())
@@ -666,9 +675,11 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 		startTS := time.Now()
 		// Some executions are done in compile stage, so we reset them before compile.
 		executor.ResetStmtCtx(s, rst)
+		event := s.startStmtEvent(executor.StmtEventName(rst), stmtSecureText(rst), startTS)
 		st, err1 := Compile(s, rst)
 		if err1 != nil {
 			log.Warnf("[%d] compile error:\n%v\n%s", connID, err1, sql)
+			endStmtEvent(event, s.sessionVars.StmtCtx, err1)
 			err2 := s.RollbackTxn()
 			terror.Log(errors.Trace(err2))
 			return nil, errors.Trace(err1)
@@ -679,6 +690,7 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 
 		startTS = time.Now()
 		r, err := runStmt(s, st)
+		r = finishStmtEvent(event, s.sessionVars.StmtCtx, r, err)
 		if err != nil {
 			if !kv.ErrKeyExists.Equal(err) {
 				log.Warnf("[%d] session error:\n%v\n%s", connID, errors.ErrorStack(err), s)
@@ -768,12 +780,86 @@ func (s *session) ExecutePreparedStmt(stmtID uint32, args ...interface{}) (ast.R
 		return nil, errors.Trace(err)
 	}
 	s.PrepareTxnCtx()
+	var event *perfschema.StatementEvent
+	if prepared, ok := s.sessionVars.PreparedStmts[stmtID].(*executor.Prepared); ok {
+		event = s.startStmtEvent(executor.StmtEventName(prepared.Stmt), stmtSecureText(prepared.Stmt), time.Now())
+	}
 	st := executor.CompileExecutePreparedStmt(s, stmtID, args...)
 
 	r, err := runStmt(s, st)
+	r = finishStmtEvent(event, s.sessionVars.StmtCtx, r, err)
 	return r, errors.Trace(err)
 }
 
+// startStmtEvent starts the performance_schema statement event of the session, the internal SQLs aren't recorded.
+func (s *session) startStmtEvent(eventName, sqlText string, start time.Time) *perfschema.StatementEvent {
+	if s.sessionVars.InRestrictedSQL {
+		return nil
+	}
+	return perfschema.StartStatement(s.sessionVars.ConnectionID, eventName, sqlText, s.sessionVars.CurrentDB, start)
+}
+
+// finishStmtEvent finishes the statement event after the statement is executed, the statement which returns
+// a record set is finished when the record set is closed.
+func finishStmtEvent(event *perfschema.StatementEvent, sc *variable.StatementContext, rs ast.RecordSet, err error) ast.RecordSet {
+	if event == nil {
+		return rs
+	}
+	if err != nil || rs == nil {
+		endStmtEvent(event, sc, err)
+		return rs
+	}
+	return &stmtEventRecordSet{RecordSet: rs, event: event, sc: sc}
+}
+
+// endStmtEvent ends the statement event with the execution information in the statement context.
+func endStmtEvent(event *perfschema.StatementEvent, sc *variable.StatementContext, err error) {
+	var stats perfschema.StatementStats
+	if sc != nil {
+		stats = perfschema.StatementStats{
+			LockTime:     sc.LockTime(),
+			Warnings:     uint64(sc.WarningCount()),
+			RowsAffected: sc.AffectedRows(),
+			RowsSent:     sc.FoundRows(),
+			RowsExamined: sc.ExaminedRows(),
+		}
+	}
+	perfschema.EndStatement(event, stats, err)
+}
+
+// stmtSecureText returns the text of the statement with the password information hidden.
+func stmtSecureText(node ast.StmtNode) string {
+	if ss, ok := node.(ast.SensitiveStmtNode); ok {
+		return ss.SecureText()
+	}
+	return node.Text()
+}
+
+// stmtEventRecordSet ends the statement event when the record set is closed.
+type stmtEventRecordSet struct {
+	ast.RecordSet
+	event *perfschema.StatementEvent
+	sc    *variable.StatementContext
+	err   error
+}
+
+func (rs *stmtEventRecordSet) Next() (*ast.Row, error) {
+	row, err := rs.RecordSet.Next()
+	if err != nil {
+		rs.err = err
+	}
+	return row, errors.Trace(err)
+}
+
+func (rs *stmtEventRecordSet) Close() error {
+	err := rs.RecordSet.Close()
+	if rs.event != nil {
+		endStmtEvent(rs.event, rs.sc, rs.err)
+		rs.event = nil
+	}
+	return errors.Trace(err)
+}
+
 func (s *session) DropPreparedStmt(stmtID uint32) error {
 	vars := s.sessionVars
 	if _, ok := vars.PreparedStmts[stmtID]; !ok {
@@ -827,12 +913,37 @@ func (s *session) Close() {
 	if s.statsCollector != nil {
 		s.statsCollector.Delete()
 	}
+	// Sessions without a connection share the thread of ID 0, keep their events.
+	if connID := s.sessionVars.ConnectionID; connID != 0 {
+		perfschema.EndThread(connID)
+	}
 	if err := s.RollbackTxn(); err != nil {
 		log.Error("session Close error:", errors.ErrorStack(err))
 	}
 	return
 }
 
+// Reset implements the Session Reset interface.
+// Prepared statements, user variables and session system variables are dropped along with the session variables,
+// the session system variables are loaded from the global ones again when the next statement is executed.
+func (s *session) Reset() error {
+	if err := s.RollbackTxn(); err != nil {
+		return errors.Trace(err)
+	}
+	old := s.sessionVars
+	vars := variable.NewSessionVars()
+	vars.User = old.User
+	vars.ActiveRoles = old.ActiveRoles
+	vars.CurrentDB = old.CurrentDB
+	vars.ConnectionID = old.ConnectionID
+	vars.ClientCapability = old.ClientCapability
+	vars.TLSConnectionState = old.TLSConnectionState
+	vars.GlobalVarsAccessor = old.GlobalVarsAccessor
+	vars.BinlogClient = old.BinlogClient
+	s.sessionVars = vars
+	return nil
+}
+
 // GetSessionVars implements the context.Context interface.
 func (s *session) GetSessionVars() *variable.SessionVars {
 	return s.sessionVars
@@ -859,6 +970,7 @@ func (s *session) Auth(user *auth.UserIdentity, authentication []byte, salt []by
 	// Check IP.
 	if pm.ConnectionVerification(user.Username, user.Hostname, authentication, salt) {
 		s.sessionVars.User = user
+		s.sessionVars.ActiveRoles = pm.GetDefaultRoles(user.Username, user.Hostname)
 		return true
 	}
 
@@ -869,6 +981,7 @@ func (s *session) Auth(user *auth.UserIdentity, authentication []byte, salt []by
 				Username: user.Username,
 				Hostname: addr,
 			}
+			s.sessionVars.ActiveRoles = pm.GetDefaultRoles(user.Username, addr)
 			return true
 		}
 	}
@@ -877,6 +990,19 @@ func (s *session) Auth(user *auth.UserIdentity, authentication []byte, salt []by
 	return false
 }
 
+func (s *session) AuthPlugin(user *auth.UserIdentity) string {
+	pm := privilege.GetPrivilegeManager(s)
+	if plugin := pm.GetAuthPlugin(user.Username, user.Hostname); plugin != "" {
+		return plugin
+	}
+	for _, addr := range getHostByIP(user.Hostname) {
+		if plugin := pm.GetAuthPlugin(user.Username, addr); plugin != "" {
+			return plugin
+		}
+	}
+	return ""
+}
+
 func getHostByIP(ip string) []string {
 	if ip == "127.0.0.1" {
 		return []string{"localhost"}
@@ -1023,7 +1149,7 @@ func createSessionWithDomain(store kv.Storage, dom *domain.Domain) (*session, er
 
 const (
 	notBootstrapped         = 0
-	currentBootstrapVersion = 15
+	currentBootstrapVersion = 19
 )
 
 func getStoreBootstrapVersion(store kv.Storage) int64 {
@@ -1072,6 +1198,7 @@ const loadCommonGlobalVarsSQL = "select HIGH_PRIORITY * from mysql.global_variab
 	variable.AutocommitVar + quoteCommaQuote +
 	variable.SQLModeVar + quoteCommaQuote +
 	variable.MaxAllowedPacket + quoteCommaQuote +
+	variable.CTEMaxRecursionDepth + quoteCommaQuote +
 	/* TiDB specific global variables: */
 	variable.TiDBSkipUTF8Check + quoteCommaQuote +
 	variable.TiDBIndexJoinBatchSize + quoteCommaQuote +
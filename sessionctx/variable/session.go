@@ -16,12 +16,14 @@ package variable
 import (
 	"crypto/tls"
 	"math"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/memory"
 )
 
 const (
@@ -87,6 +89,37 @@ type TransactionContext struct {
 	SchemaVersion int64
 	StartTS       uint64
 	TableDeltaMap map[int64]TableDelta
+	// Savepoints are the savepoints set in the transaction, from the oldest to the newest.
+	Savepoints []SavepointRecord
+}
+
+// SavepointRecord is the transaction state saved by a SAVEPOINT statement.
+type SavepointRecord struct {
+	Name string
+	// MemCheckpoint is the checkpoint of the transaction's MemBuffer.
+	MemCheckpoint int
+	DirtyDB       interface{}
+	Binlog        interface{}
+	TableDeltaMap map[int64]TableDelta
+}
+
+// AddSavepoint appends a savepoint, the existing savepoint with the same name is deleted.
+func (tc *TransactionContext) AddSavepoint(record SavepointRecord) {
+	if idx := tc.SavepointIndex(record.Name); idx >= 0 {
+		tc.Savepoints = append(tc.Savepoints[:idx], tc.Savepoints[idx+1:]...)
+	}
+	tc.Savepoints = append(tc.Savepoints, record)
+}
+
+// SavepointIndex returns the index of the savepoint named name in Savepoints, or -1 if it doesn't exist.
+// Savepoint names are case-insensitive.
+func (tc *TransactionContext) SavepointIndex(name string) int {
+	for i, sp := range tc.Savepoints {
+		if strings.EqualFold(sp.Name, name) {
+			return i
+		}
+	}
+	return -1
 }
 
 // UpdateDeltaForTable updates the delta info for some table.
@@ -138,6 +171,10 @@ type SessionVars struct {
 	// User is the user identity with which the session login.
 	User *auth.UserIdentity
 
+	// ActiveRoles is the roles activated by SET ROLE or the default roles, the privileges
+	// granted to them are available to the session.
+	ActiveRoles []*auth.RoleIdentity
+
 	// CurrentDB is the default database of this session.
 	CurrentDB string
 
@@ -217,8 +254,20 @@ type SessionVars struct {
 	// BatchDelete indicates if we should split delete data into multiple batches.
 	BatchDelete bool
 
+	// OutfileSplitByRegion indicates if we should write the result of SELECT ... INTO OUTFILE into one file per region.
+	OutfileSplitByRegion bool
+
 	// MaxRowCountForINLJ defines max row count that the outer table of index nested loop join could be without force hint.
 	MaxRowCountForINLJ int
+
+	// MemQuotaSort is the memory quota in bytes of a sort operator, the sort spills to disk when it is exceeded.
+	MemQuotaSort int64
+
+	// MemQuotaQuery is the memory quota in bytes of a statement, the hash join, hash aggregation and sort spill to disk when it is exceeded.
+	MemQuotaQuery int64
+
+	// CTEMaxRecursionDepth is the max number of iterations of a recursive common table expression.
+	CTEMaxRecursionDepth int
 }
 
 // NewSessionVars creates a session vars object.
@@ -241,6 +290,9 @@ func NewSessionVars() *SessionVars {
 		IndexSerialScanConcurrency: DefIndexSerialScanConcurrency,
 		DistSQLScanConcurrency:     DefDistSQLScanConcurrency,
 		MaxRowCountForINLJ:         DefMaxRowCountForINLJ,
+		MemQuotaSort:               DefMemQuotaSort,
+		MemQuotaQuery:              DefMemQuotaQuery,
+		CTEMaxRecursionDepth:       DefCTEMaxRecursionDepth,
 	}
 }
 
@@ -308,14 +360,18 @@ func (s *SessionVars) GetTimeZone() *time.Location {
 
 // special session variables.
 const (
-	SQLModeVar          = "sql_mode"
-	AutocommitVar       = "autocommit"
-	CharacterSetResults = "character_set_results"
-	MaxAllowedPacket    = "max_allowed_packet"
-	TimeZone            = "time_zone"
-	TxnIsolation        = "tx_isolation"
+	SQLModeVar           = "sql_mode"
+	AutocommitVar        = "autocommit"
+	CharacterSetResults  = "character_set_results"
+	MaxAllowedPacket     = "max_allowed_packet"
+	TimeZone             = "time_zone"
+	TxnIsolation         = "tx_isolation"
+	CTEMaxRecursionDepth = "cte_max_recursion_depth"
 )
 
+// DefCTEMaxRecursionDepth is the default value of cte_max_recursion_depth.
+const DefCTEMaxRecursionDepth = 1000
+
 // TableDelta stands for the changed count for one table.
 type TableDelta struct {
 	Delta int64
@@ -341,13 +397,21 @@ type StatementContext struct {
 		sync.Mutex
 		affectedRows uint64
 		foundRows    uint64
+		examinedRows uint64
+		lockTime     time.Duration
 		warnings     []error
+		// copTasks, copProcessTime and copWaitTime are the execution statistics of the coprocessor tasks.
+		copTasks       uint64
+		copProcessTime time.Duration
+		copWaitTime    time.Duration
 	}
 
 	// Copied from SessionVars.TimeZone.
 	TimeZone     *time.Location
 	Priority     mysql.PriorityEnum
 	NotFillCache bool
+	// MemTracker tracks the memory used by the executors of the statement.
+	MemTracker *memory.Tracker
 }
 
 // AddAffectedRows adds affected rows.
@@ -380,6 +444,53 @@ func (sc *StatementContext) AddFoundRows(rows uint64) {
 	sc.mu.Unlock()
 }
 
+// ExaminedRows gets the rows read from the storage.
+func (sc *StatementContext) ExaminedRows() uint64 {
+	sc.mu.Lock()
+	rows := sc.mu.examinedRows
+	sc.mu.Unlock()
+	return rows
+}
+
+// AddExaminedRows adds the rows read from the storage.
+func (sc *StatementContext) AddExaminedRows(rows uint64) {
+	sc.mu.Lock()
+	sc.mu.examinedRows += rows
+	sc.mu.Unlock()
+}
+
+// LockTime gets the time spent on locking rows.
+func (sc *StatementContext) LockTime() time.Duration {
+	sc.mu.Lock()
+	d := sc.mu.lockTime
+	sc.mu.Unlock()
+	return d
+}
+
+// AddLockTime adds the time spent on locking rows.
+func (sc *StatementContext) AddLockTime(d time.Duration) {
+	sc.mu.Lock()
+	sc.mu.lockTime += d
+	sc.mu.Unlock()
+}
+
+// RecordCopTask implements the kv.CopStatsCollector interface.
+func (sc *StatementContext) RecordCopTask(processTime, waitTime time.Duration) {
+	sc.mu.Lock()
+	sc.mu.copTasks++
+	sc.mu.copProcessTime += processTime
+	sc.mu.copWaitTime += waitTime
+	sc.mu.Unlock()
+}
+
+// CopTaskStats gets the number of the coprocessor tasks and their total process and wait time.
+func (sc *StatementContext) CopTaskStats() (tasks uint64, processTime, waitTime time.Duration) {
+	sc.mu.Lock()
+	tasks, processTime, waitTime = sc.mu.copTasks, sc.mu.copProcessTime, sc.mu.copWaitTime
+	sc.mu.Unlock()
+	return
+}
+
 // GetWarnings gets warnings.
 func (sc *StatementContext) GetWarnings() []error {
 	sc.mu.Lock()
@@ -451,7 +562,12 @@ func (sc *StatementContext) ResetForRetry() {
 	sc.mu.Lock()
 	sc.mu.affectedRows = 0
 	sc.mu.foundRows = 0
+	sc.mu.examinedRows = 0
+	sc.mu.lockTime = 0
 	sc.mu.warnings = nil
+	sc.mu.copTasks = 0
+	sc.mu.copProcessTime = 0
+	sc.mu.copWaitTime = 0
 	sc.mu.Unlock()
 }
 
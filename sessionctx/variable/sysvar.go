@@ -575,6 +575,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "net_read_timeout", "30"},
 	{ScopeNone, "innodb_page_size", "16384"},
 	{ScopeGlobal, MaxAllowedPacket, "67108864"},
+	{ScopeGlobal | ScopeSession, CTEMaxRecursionDepth, strconv.Itoa(DefCTEMaxRecursionDepth)},
 	{ScopeNone, "innodb_log_file_size", "50331648"},
 	{ScopeGlobal, "sync_relay_log_info", "10000"},
 	{ScopeGlobal | ScopeSession, "optimizer_trace_limit", "1"},
@@ -616,7 +617,10 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, TiDBSkipUTF8Check, boolToIntStr(DefSkipUTF8Check)},
 	{ScopeSession, TiDBBatchInsert, boolToIntStr(DefBatchInsert)},
 	{ScopeSession, TiDBBatchDelete, boolToIntStr(DefBatchDelete)},
+	{ScopeSession, TiDBOutfileSplitByRegion, boolToIntStr(DefOutfileSplitByRegion)},
 	{ScopeSession, TiDBCurrentTS, strconv.Itoa(DefCurretTS)},
+	{ScopeSession, TiDBMemQuotaSort, strconv.FormatInt(DefMemQuotaSort, 10)},
+	{ScopeSession, TiDBMemQuotaQuery, strconv.FormatInt(DefMemQuotaQuery, 10)},
 }
 
 // SetNamesVariables is the system variable names related to set names statements.
@@ -627,6 +631,8 @@ var SetNamesVariables = []string{
 }
 
 const (
+	// CharacterSetClient is the name for character_set_client system variable.
+	CharacterSetClient = "character_set_client"
 	// CharacterSetConnection is the name for character_set_connection system variable.
 	CharacterSetConnection = "character_set_connection"
 	// CollationConnection is the name for collation_connection system variable.
@@ -52,6 +52,17 @@ const (
 	// It is read-only.
 	TiDBCurrentTS = "tidb_current_ts"
 
+	// tidb_mem_quota_sort is the memory quota in bytes of a sort operator in a statement.
+	// When the rows buffered by a sort or top-n operator exceed this quota, they are spilled to temporary files
+	// and sorted by an external merge sort. The sort also spills when the statement exceeds tidb_mem_quota_query.
+	TiDBMemQuotaSort = "tidb_mem_quota_sort"
+
+	// tidb_mem_quota_query is the memory quota in bytes of a statement.
+	// When the memory tracked by the statement exceeds this quota, the hash join and hash aggregation operators
+	// partition their input into temporary files and process the partitions one at a time, the sort and top-n
+	// operators spill their rows to temporary files and sort them by an external merge sort.
+	TiDBMemQuotaQuery = "tidb_mem_quota_query"
+
 	/* Session and global */
 
 	// tidb_distsql_scan_concurrency is used to set the concurrency of a distsql scan task.
@@ -96,6 +107,11 @@ const (
 	// split data into multiple batches and use a single txn for each batch. This will be helpful when deleting large data.
 	TiDBBatchDelete = "tidb_batch_delete"
 
+	// tidb_outfile_split_by_region is used to enable/disable splitting the output of SELECT ... INTO OUTFILE by region.
+	// If set this option on, a select that reads a table directly writes the data of every region into a separate file
+	// named by the OUTFILE path with a sequence number suffix, and the files are written concurrently.
+	TiDBOutfileSplitByRegion = "tidb_outfile_split_by_region"
+
 	// tidb_max_row_count_for_inlj is used when do index nested loop join.
 	// It controls the max row count of outer table when do index nested loop join without hint.
 	// After the row count of the inner table is accurate, this variable will be removed.
@@ -116,5 +132,8 @@ const (
 	DefOptInSubqUnfolding         = false
 	DefBatchInsert                = false
 	DefBatchDelete                = false
+	DefOutfileSplitByRegion       = false
 	DefCurretTS                   = 0
+	DefMemQuotaSort               = 32 << 30 // 32GB.
+	DefMemQuotaQuery              = 32 << 30 // 32GB.
 )
@@ -143,8 +143,16 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		vars.BatchInsert = tidbOptOn(sVal)
 	case variable.TiDBBatchDelete:
 		vars.BatchDelete = tidbOptOn(sVal)
+	case variable.TiDBOutfileSplitByRegion:
+		vars.OutfileSplitByRegion = tidbOptOn(sVal)
 	case variable.TiDBMaxRowCountForINLJ:
 		vars.MaxRowCountForINLJ = tidbOptPositiveInt(sVal, variable.DefMaxRowCountForINLJ)
+	case variable.TiDBMemQuotaSort:
+		vars.MemQuotaSort = tidbOptPositiveInt64(sVal, variable.DefMemQuotaSort)
+	case variable.TiDBMemQuotaQuery:
+		vars.MemQuotaQuery = tidbOptPositiveInt64(sVal, variable.DefMemQuotaQuery)
+	case variable.CTEMaxRecursionDepth:
+		vars.CTEMaxRecursionDepth = tidbOptPositiveInt(sVal, variable.DefCTEMaxRecursionDepth)
 	case variable.TiDBCurrentTS:
 		return variable.ErrReadOnly
 	}
@@ -165,6 +173,14 @@ func tidbOptPositiveInt(opt string, defaultVal int) int {
 	return val
 }
 
+func tidbOptPositiveInt64(opt string, defaultVal int64) int64 {
+	val, err := strconv.ParseInt(opt, 10, 64)
+	if err != nil || val <= 0 {
+		return defaultVal
+	}
+	return val
+}
+
 func parseTimeZone(s string) (*time.Location, error) {
 	if s == "SYSTEM" {
 		// TODO: Support global time_zone variable, it should be set to global time_zone value.
@@ -157,10 +157,29 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 	SetSessionSystemVar(v, variable.TiDBBatchInsert, types.NewStringDatum("1"))
 	c.Assert(v.BatchInsert, IsTrue)
 
+	// Test case for tidb_outfile_split_by_region.
+	c.Assert(v.OutfileSplitByRegion, IsFalse)
+	SetSessionSystemVar(v, variable.TiDBOutfileSplitByRegion, types.NewStringDatum("1"))
+	c.Assert(v.OutfileSplitByRegion, IsTrue)
+
 	//Test case for tidb_max_row_count_for_inlj.
 	c.Assert(v.MaxRowCountForINLJ, Equals, 128)
 	SetSessionSystemVar(v, variable.TiDBMaxRowCountForINLJ, types.NewStringDatum("127"))
 	c.Assert(v.MaxRowCountForINLJ, Equals, 127)
+
+	// Test case for tidb_mem_quota_sort.
+	c.Assert(v.MemQuotaSort, Equals, int64(variable.DefMemQuotaSort))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaSort, types.NewStringDatum("1024"))
+	c.Assert(v.MemQuotaSort, Equals, int64(1024))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaSort, types.NewStringDatum("-1"))
+	c.Assert(v.MemQuotaSort, Equals, int64(variable.DefMemQuotaSort))
+
+	// Test case for tidb_mem_quota_query.
+	c.Assert(v.MemQuotaQuery, Equals, int64(variable.DefMemQuotaQuery))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaQuery, types.NewStringDatum("1024"))
+	c.Assert(v.MemQuotaQuery, Equals, int64(1024))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaQuery, types.NewStringDatum("-1"))
+	c.Assert(v.MemQuotaQuery, Equals, int64(variable.DefMemQuotaQuery))
 }
 
 type mockGlobalAccessor struct {
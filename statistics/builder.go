@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-tipb"
 )
 
 // SortedBuilder is used to build histograms for PK and index.
@@ -132,6 +133,26 @@ func BuildIndex(ctx context.Context, numBuckets, id int64, records ast.RecordSet
 	return b.Count, b.Hist(), nil
 }
 
+// BuildIndexResp builds the histogram for the analyze index request,
+// the rows of records are the encoded index values in a bytes datum.
+func BuildIndexResp(sc *variable.StatementContext, req *tipb.AnalyzeIndexReq, records ast.RecordSet) (*tipb.AnalyzeIndexResp, error) {
+	b := NewSortedBuilder(sc, req.BucketSize, 0)
+	for {
+		row, err := records.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		err = b.Iterate(row.Data[0])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return &tipb.AnalyzeIndexResp{Hist: HistogramToProto(b.Hist())}, nil
+}
+
 // BuildColumn builds histogram from samples for column.
 func BuildColumn(ctx context.Context, numBuckets, id int64, collector *SampleCollector) (*Histogram, error) {
 	count := collector.Count
@@ -145,3 +145,33 @@ func (s SampleBuilder) CollectSamplesAndEstimateNDVs() ([]*SampleCollector, *Sor
 		}
 	}
 }
+
+// BuildColumnsResp collects the samples of the columns for the analyze columns request,
+// the first column of records is the primary key handle if pkID isn't -1.
+func BuildColumnsResp(sc *variable.StatementContext, req *tipb.AnalyzeColumnsReq, pkID int64, records ast.RecordSet) (*tipb.AnalyzeColumnsResp, error) {
+	colLen := len(req.ColumnsInfo)
+	if pkID != -1 {
+		colLen--
+	}
+	builder := SampleBuilder{
+		Sc:            sc,
+		RecordSet:     records,
+		ColLen:        colLen,
+		PkID:          pkID,
+		MaxBucketSize: req.BucketSize,
+		MaxSketchSize: req.SketchSize,
+		MaxSampleSize: req.SampleSize,
+	}
+	collectors, pkBuilder, err := builder.CollectSamplesAndEstimateNDVs()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	resp := &tipb.AnalyzeColumnsResp{}
+	if pkID != -1 {
+		resp.PkHist = HistogramToProto(pkBuilder.Hist())
+	}
+	for _, c := range collectors {
+		resp.Collectors = append(resp.Collectors, SampleCollectorToProto(c))
+	}
+	return resp, nil
+}
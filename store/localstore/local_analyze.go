@@ -0,0 +1,143 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localstore
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/distsql/dagexec"
+	"github.com/pingcap/tidb/distsql/xeval"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-tipb"
+)
+
+// BuildIndexResp and BuildColumnsResp build the statistics for the analyze requests.
+// They are set by the tidb package, because the statistics package depends on packages
+// whose tests use localstore.
+var (
+	BuildIndexResp   func(sc *variable.StatementContext, req *tipb.AnalyzeIndexReq, records ast.RecordSet) (*tipb.AnalyzeIndexResp, error)
+	BuildColumnsResp func(sc *variable.StatementContext, req *tipb.AnalyzeColumnsReq, pkID int64, records ast.RecordSet) (*tipb.AnalyzeColumnsResp, error)
+)
+
+// handleAnalyzeRequest builds the statistics of the index or the columns in the region,
+// and returns the marshaled tipb.AnalyzeIndexResp or tipb.AnalyzeColumnsResp.
+func (rs *localRegion) handleAnalyzeRequest(req *regionRequest) ([]byte, error) {
+	analyzeReq := new(tipb.AnalyzeReq)
+	err := proto.Unmarshal(req.data, analyzeReq)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	txn := newTxn(rs.store, kv.Version{Ver: analyzeReq.StartTs})
+	if analyzeReq.Tp == tipb.AnalyzeType_TypeIndex {
+		return rs.handleAnalyzeIndexReq(req, txn, analyzeReq)
+	}
+	return rs.handleAnalyzeColumnsReq(req, txn, analyzeReq)
+}
+
+func (rs *localRegion) handleAnalyzeIndexReq(req *regionRequest, txn kv.Transaction, analyzeReq *tipb.AnalyzeReq) ([]byte, error) {
+	e := dagexec.NewIndexScan(txnSnapshot{txn: txn}, int(analyzeReq.IdxReq.NumColumns), rs.extractKVRanges(req.ranges, false))
+	resp, err := BuildIndexResp(xeval.FlagsToStatementContext(analyzeReq.Flags), analyzeReq.IdxReq, &analyzeIndexExec{idxExec: e})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	data, err := proto.Marshal(resp)
+	return data, errors.Trace(err)
+}
+
+func (rs *localRegion) handleAnalyzeColumnsReq(req *regionRequest, txn kv.Transaction, analyzeReq *tipb.AnalyzeReq) ([]byte, error) {
+	columns := analyzeReq.ColReq.ColumnsInfo
+	e := &analyzeColumnsExec{
+		tblExec: dagexec.NewTableScan(txnSnapshot{txn: txn}, columns, rs.extractKVRanges(req.ranges, false)),
+	}
+	pkID := int64(-1)
+	if columns[0].GetPkHandle() {
+		pkID = columns[0].ColumnId
+	}
+	resp, err := BuildColumnsResp(xeval.FlagsToStatementContext(analyzeReq.Flags), analyzeReq.ColReq, pkID, e)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	data, err := proto.Marshal(resp)
+	return data, errors.Trace(err)
+}
+
+// analyzeIndexExec wraps the index scan executor as an ast.RecordSet, each row is the encoded index values.
+type analyzeIndexExec struct {
+	idxExec dagexec.Executor
+}
+
+// Fields implements the ast.RecordSet Fields interface.
+func (e *analyzeIndexExec) Fields() ([]*ast.ResultField, error) {
+	return nil, nil
+}
+
+// Next implements the ast.RecordSet Next interface.
+func (e *analyzeIndexExec) Next() (*ast.Row, error) {
+	values, err := e.idxExec.Next()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if values == nil {
+		return nil, nil
+	}
+	var value []byte
+	for _, val := range values {
+		value = append(value, val...)
+	}
+	return &ast.Row{Data: []types.Datum{types.NewBytesDatum(value)}}, nil
+}
+
+// Close implements the ast.RecordSet Close interface.
+func (e *analyzeIndexExec) Close() error {
+	return nil
+}
+
+// analyzeColumnsExec wraps the table scan executor as an ast.RecordSet for the statistics.SampleBuilder.
+type analyzeColumnsExec struct {
+	tblExec dagexec.Executor
+}
+
+// Fields implements the ast.RecordSet Fields interface.
+func (e *analyzeColumnsExec) Fields() ([]*ast.ResultField, error) {
+	return nil, nil
+}
+
+// Next implements the ast.RecordSet Next interface.
+func (e *analyzeColumnsExec) Next() (*ast.Row, error) {
+	values, err := e.tblExec.Next()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if values == nil {
+		return nil, nil
+	}
+	row := &ast.Row{}
+	for _, val := range values {
+		d := types.NewBytesDatum(val)
+		if len(val) == 1 && val[0] == codec.NilFlag {
+			d.SetNull()
+		}
+		row.Data = append(row.Data, d)
+	}
+	return row, nil
+}
+
+// Close implements the ast.RecordSet Close interface.
+func (e *analyzeColumnsExec) Close() error {
+	return nil
+}
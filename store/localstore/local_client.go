@@ -29,6 +29,10 @@ func (c *dbClient) Send(ctx goctx.Context, req *kv.Request) kv.Response {
 	} else if it.concurrency <= 0 {
 		it.concurrency = 1
 	}
+	if req.KeepOrder {
+		// The responses are sent in the order of the tasks only if they are handled one by one.
+		it.concurrency = 1
+	}
 	it.taskChan = make(chan *task, it.concurrency)
 	it.errChan = make(chan error, it.concurrency)
 	it.respChan = make(chan *regionResponse, it.concurrency)
@@ -45,10 +49,52 @@ func (c *dbClient) IsRequestTypeSupported(reqType, subType int64) bool {
 		default:
 			return supportExpr(tipb.ExprType(subType))
 		}
+	case kv.ReqTypeDAG:
+		return supportDAGExpr(tipb.ExprType(subType))
+	case kv.ReqTypeAnalyze:
+		return BuildIndexResp != nil && BuildColumnsResp != nil
 	}
 	return false
 }
 
+// supportDAGExpr checks if the expression can be evaluated by the DAG executors,
+// they are evaluated by the expression package, so the supported expressions are the same as TiKV's.
+func supportDAGExpr(exprType tipb.ExprType) bool {
+	switch exprType {
+	case tipb.ExprType_Null, tipb.ExprType_Int64, tipb.ExprType_Uint64, tipb.ExprType_String, tipb.ExprType_Bytes,
+		tipb.ExprType_MysqlDuration, tipb.ExprType_MysqlTime, tipb.ExprType_MysqlDecimal,
+		tipb.ExprType_ColumnRef:
+		return true
+	// logic operators.
+	case tipb.ExprType_And, tipb.ExprType_Or, tipb.ExprType_Not:
+		return true
+	// compare operators.
+	case tipb.ExprType_LT, tipb.ExprType_LE, tipb.ExprType_EQ, tipb.ExprType_NE,
+		tipb.ExprType_GE, tipb.ExprType_GT, tipb.ExprType_NullEQ,
+		tipb.ExprType_In, tipb.ExprType_ValueList, tipb.ExprType_IsNull,
+		tipb.ExprType_Like:
+		return true
+	// arithmetic operators.
+	case tipb.ExprType_Plus, tipb.ExprType_Div, tipb.ExprType_Minus, tipb.ExprType_Mul:
+		return true
+	// control functions
+	case tipb.ExprType_Case, tipb.ExprType_If, tipb.ExprType_IfNull, tipb.ExprType_Coalesce:
+		return true
+	// aggregate functions.
+	case tipb.ExprType_Count, tipb.ExprType_First, tipb.ExprType_Max, tipb.ExprType_Min, tipb.ExprType_Sum, tipb.ExprType_Avg:
+		return true
+	// json functions.
+	case tipb.ExprType_JsonType, tipb.ExprType_JsonExtract, tipb.ExprType_JsonUnquote,
+		tipb.ExprType_JsonObject, tipb.ExprType_JsonArray, tipb.ExprType_JsonMerge,
+		tipb.ExprType_JsonSet, tipb.ExprType_JsonInsert, tipb.ExprType_JsonReplace, tipb.ExprType_JsonRemove:
+		return true
+	case kv.ReqSubTypeDesc, kv.ReqSubTypeSignature:
+		return true
+	default:
+		return false
+	}
+}
+
 func supportExpr(exprType tipb.ExprType) bool {
 	switch exprType {
 	// data type.
@@ -84,7 +130,8 @@ func supportExpr(exprType tipb.ExprType) bool {
 		return true
 	case tipb.ExprType_JsonType, tipb.ExprType_JsonExtract, tipb.ExprType_JsonUnquote, tipb.ExprType_JsonValid,
 		tipb.ExprType_JsonObject, tipb.ExprType_JsonArray, tipb.ExprType_JsonMerge, tipb.ExprType_JsonSet,
-		tipb.ExprType_JsonInsert, tipb.ExprType_JsonReplace, tipb.ExprType_JsonRemove, tipb.ExprType_JsonContains:
+		tipb.ExprType_JsonInsert, tipb.ExprType_JsonReplace, tipb.ExprType_JsonRemove, tipb.ExprType_JsonContains,
+		tipb.ExprType_JsonContainsPath:
 		return true
 	case kv.ReqSubTypeDesc:
 		return true
@@ -292,6 +292,18 @@ func (rs *localRegion) Handle(req *regionRequest) (*regionResponse, error) {
 			return nil, errors.Trace(err)
 		}
 		resp.data = data
+	} else if req.Tp == kv.ReqTypeDAG {
+		data, err := rs.handleDAGRequest(req)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		resp.data = data
+	} else if req.Tp == kv.ReqTypeAnalyze {
+		data, err := rs.handleAnalyzeRequest(req)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		resp.data = data
 	}
 	if bytes.Compare(rs.startKey, req.startKey) < 0 || bytes.Compare(rs.endKey, req.endKey) > 0 {
 		resp.newStartKey = rs.startKey
@@ -352,7 +364,7 @@ func (rs *localRegion) getRowsFromSelectReq(ctx *selectContext) error {
 		ctx.colTps[col.GetColumnId()] = distsql.FieldTypeFromPBColumn(col)
 	}
 
-	kvRanges := rs.extractKVRanges(ctx)
+	kvRanges := rs.extractKVRanges(ctx.keyRanges, ctx.descScan)
 	limit := int64(-1)
 	if ctx.sel.Limit != nil {
 		limit = ctx.sel.GetLimit()
@@ -409,9 +421,9 @@ func (rs *localRegion) getRowsFromAgg(ctx *selectContext) error {
 	return nil
 }
 
-// extractKVRanges extracts kv.KeyRanges slice from ctx.keyRanges, and also returns if it is in descending order.
-func (rs *localRegion) extractKVRanges(ctx *selectContext) (kvRanges []kv.KeyRange) {
-	for _, kran := range ctx.keyRanges {
+// extractKVRanges extracts the parts of keyRanges in the region, they are reversed if descScan is true.
+func (rs *localRegion) extractKVRanges(keyRanges []kv.KeyRange, descScan bool) (kvRanges []kv.KeyRange) {
+	for _, kran := range keyRanges {
 		upperKey := kran.EndKey
 		if bytes.Compare(upperKey, rs.startKey) <= 0 {
 			continue
@@ -433,7 +445,7 @@ func (rs *localRegion) extractKVRanges(ctx *selectContext) (kvRanges []kv.KeyRan
 		}
 		kvRanges = append(kvRanges, kvr)
 	}
-	if ctx.descScan {
+	if descScan {
 		reverseKVRanges(kvRanges)
 	}
 	return
@@ -646,7 +658,23 @@ func (rs *localRegion) getRowData(value []byte, colTps map[int64]*types.FieldTyp
 	if res == nil {
 		res = make(map[int64][]byte, len(colTps))
 	}
-	return res, nil
+	return res, errors.Trace(decodeCharsetValues(res, colTps))
+}
+
+// decodeCharsetValues decodes the stored strings of the columns in the transcoded charsets to UTF-8.
+func decodeCharsetValues(values map[int64][]byte, colTps map[int64]*types.FieldType) error {
+	for id, value := range values {
+		ft, ok := colTps[id]
+		if !ok {
+			continue
+		}
+		value, err := tablecodec.DecodeCharsetValue(value, ft)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		values[id] = value
+	}
+	return nil
 }
 
 // Put column values into ctx, the values will be used for expr evaluation.
@@ -706,7 +734,7 @@ func toPBError(err error) *tipb.Error {
 }
 
 func (rs *localRegion) getRowsFromIndexReq(ctx *selectContext) error {
-	kvRanges := rs.extractKVRanges(ctx)
+	kvRanges := rs.extractKVRanges(ctx.keyRanges, ctx.descScan)
 	limit := int64(-1)
 	if ctx.sel.Limit != nil {
 		limit = ctx.sel.GetLimit()
@@ -0,0 +1,106 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localstore
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/distsql/dagexec"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tipb/go-tipb"
+)
+
+// handleDAGRequest executes the executors of the DAG request in the region,
+// and returns the marshaled tipb.SelectResponse.
+func (rs *localRegion) handleDAGRequest(req *regionRequest) ([]byte, error) {
+	dagReq := new(tipb.DAGRequest)
+	err := proto.Unmarshal(req.data, dagReq)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	snap := txnSnapshot{txn: newTxn(rs.store, kv.Version{Ver: dagReq.StartTs})}
+	e, err := dagexec.Build(snap, dagReq, rs.extractKVRanges(req.ranges, false))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var (
+		chunks []tipb.Chunk
+		rowCnt int
+	)
+	for {
+		var row [][]byte
+		row, err = e.Next()
+		if err != nil || row == nil {
+			break
+		}
+		var data []byte
+		for _, offset := range dagReq.OutputOffsets {
+			data = append(data, row[offset]...)
+		}
+		chunks = appendRow(chunks, data, rowCnt)
+		rowCnt++
+	}
+	selResp := &tipb.SelectResponse{
+		Error:  toPBError(err),
+		Chunks: chunks,
+	}
+	data, err := proto.Marshal(selResp)
+	return data, errors.Trace(err)
+}
+
+// txnSnapshot is the snapshot of the transaction read by the coprocessor executors.
+type txnSnapshot struct {
+	txn kv.Transaction
+}
+
+// Get implements dagexec.Snapshot Get interface.
+func (s txnSnapshot) Get(key kv.Key) ([]byte, error) {
+	val, err := s.txn.Get(key)
+	if kv.ErrNotExist.Equal(err) {
+		return nil, nil
+	}
+	return val, errors.Trace(err)
+}
+
+// Scan implements dagexec.Snapshot Scan interface.
+func (s txnSnapshot) Scan(startKey, endKey kv.Key, desc bool) (kv.Key, []byte, error) {
+	var it kv.Iterator
+	var err error
+	if desc {
+		it, err = s.txn.SeekReverse(endKey)
+	} else {
+		it, err = s.txn.Seek(startKey)
+	}
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	defer it.Close()
+	if !it.Valid() {
+		return nil, nil, nil
+	}
+	key := it.Key()
+	if desc && key.Cmp(startKey) < 0 || !desc && key.Cmp(endKey) >= 0 {
+		return nil, nil, nil
+	}
+	return append(kv.Key(nil), key...), it.Value(), nil
+}
+
+func appendRow(chunks []tipb.Chunk, data []byte, rowCnt int) []tipb.Chunk {
+	if rowCnt%chunkSize == 0 {
+		chunks = append(chunks, tipb.Chunk{})
+	}
+	cur := &chunks[len(chunks)-1]
+	cur.RowsData = append(cur.RowsData, data...)
+	return chunks
+}
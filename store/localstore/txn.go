@@ -169,3 +169,11 @@ func (txn *dbTxn) Size() int {
 func (txn *dbTxn) Len() int {
 	return txn.us.Len()
 }
+
+func (txn *dbTxn) Checkpoint() int {
+	return txn.us.Checkpoint()
+}
+
+func (txn *dbTxn) RollbackToCheckpoint(cp int) error {
+	return txn.us.RollbackToCheckpoint(cp)
+}
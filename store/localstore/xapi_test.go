@@ -25,6 +25,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/testleak"
@@ -114,6 +115,131 @@ func (s *testXAPISuite) TestSelect(c *C) {
 	store.Close()
 }
 
+func (s *testXAPISuite) TestDAG(c *C) {
+	defer testleak.AfterTest(c)()
+	store := createMemStore(time.Now().Nanosecond())
+	defer store.Close()
+	count := int64(10)
+	err := prepareTableData(store, tbInfo, count, genValues)
+	c.Check(err, IsNil)
+	client := store.GetClient()
+	c.Assert(client.IsRequestTypeSupported(kv.ReqTypeDAG, kv.ReqSubTypeBasic), IsTrue)
+	c.Assert(client.IsRequestTypeSupported(kv.ReqTypeAnalyze, kv.ReqSubTypeAnalyzeIdx), IsFalse)
+	BuildIndexResp, BuildColumnsResp = statistics.BuildIndexResp, statistics.BuildColumnsResp
+	defer func() {
+		BuildIndexResp, BuildColumnsResp = nil, nil
+	}()
+	c.Assert(client.IsRequestTypeSupported(kv.ReqTypeAnalyze, kv.ReqSubTypeAnalyzeIdx), IsTrue)
+
+	txn, err := store.Begin()
+	c.Check(err, IsNil)
+	defer txn.Commit()
+	tblColumns := tbInfo.toPBTableInfo().Columns
+	idxColumns := append(tbInfo.toPBIndexInfo(0).Columns, tblColumns[0])
+	tblScan := &tipb.Executor{
+		Tp:      tipb.ExecType_TypeTableScan,
+		TblScan: &tipb.TableScan{TableId: tbInfo.tID, Columns: tblColumns},
+	}
+	tests := []struct {
+		executors     []*tipb.Executor
+		outputOffsets []uint32
+		ranges        []kv.KeyRange
+		handles       []int64
+	}{
+		// Table scan with limit.
+		{
+			executors:     []*tipb.Executor{tblScan, {Tp: tipb.ExecType_TypeLimit, Limit: &tipb.Limit{Limit: 3}}},
+			outputOffsets: []uint32{0, 1, 2},
+			ranges:        []kv.KeyRange{fullTableRange(tbInfo.tID)},
+			handles:       []int64{1, 2, 3},
+		},
+		// Table scan with top n on the double column.
+		{
+			executors: []*tipb.Executor{tblScan, {Tp: tipb.ExecType_TypeTopN, TopN: &tipb.TopN{
+				OrderBy: []*tipb.ByItem{{Expr: &tipb.Expr{Tp: tipb.ExprType_ColumnRef, Val: codec.EncodeInt(nil, 2)}, Desc: true}},
+				Limit:   2,
+			}}},
+			outputOffsets: []uint32{0, 1, 2},
+			ranges:        []kv.KeyRange{fullTableRange(tbInfo.tID)},
+			handles:       []int64{10, 9},
+		},
+		// Table scan on point ranges, the missing row is skipped.
+		{
+			executors:     []*tipb.Executor{tblScan},
+			outputOffsets: []uint32{0, 1, 2},
+			ranges: []kv.KeyRange{
+				pointTableRange(tbInfo.tID, 2),
+				pointTableRange(tbInfo.tID, 20),
+				pointTableRange(tbInfo.tID, 5),
+			},
+			handles: []int64{2, 5},
+		},
+	}
+	for _, t := range tests {
+		dagReq := &tipb.DAGRequest{
+			StartTs:       txn.StartTS(),
+			Executors:     t.executors,
+			OutputOffsets: t.outputOffsets,
+		}
+		var expected []types.Datum
+		for _, handle := range t.handles {
+			row := append([]types.Datum{types.NewDatum(handle)}, genValues(handle, tbInfo)...)
+			for _, offset := range t.outputOffsets {
+				expected = append(expected, row[offset])
+			}
+		}
+		expectedEncoded, err := codec.EncodeValue(nil, expected...)
+		c.Assert(err, IsNil)
+		c.Assert(sendDAGRequest(c, client, dagReq, t.ranges), BytesEquals, expectedEncoded)
+	}
+
+	// Index scan in descending order, the index is on the varchar column and the handle is encoded in the index key.
+	dagReq := &tipb.DAGRequest{
+		StartTs: txn.StartTS(),
+		Executors: []*tipb.Executor{{
+			Tp:      tipb.ExecType_TypeIndexScan,
+			IdxScan: &tipb.IndexScan{TableId: tbInfo.tID, IndexId: tbInfo.iIDs[0], Columns: idxColumns, Desc: true},
+		}},
+		OutputOffsets: []uint32{1},
+	}
+	expectedEncoded, err := codec.EncodeKey(nil, types.MakeDatums(9, 8, 7, 6, 5, 4, 3, 2, 10, 1)...)
+	c.Assert(err, IsNil)
+	rowsData := sendDAGRequest(c, client, dagReq, []kv.KeyRange{fullIndexRange(tbInfo.tID, tbInfo.iIDs[0])})
+	c.Assert(rowsData, BytesEquals, expectedEncoded)
+
+	// Analyze index request.
+	analyzeReq := &tipb.AnalyzeReq{
+		Tp:      tipb.AnalyzeType_TypeIndex,
+		StartTs: txn.StartTS(),
+		IdxReq:  &tipb.AnalyzeIndexReq{BucketSize: 64, NumColumns: 1},
+	}
+	data, err := proto.Marshal(analyzeReq)
+	c.Assert(err, IsNil)
+	req := &kv.Request{Tp: kv.ReqTypeAnalyze, Data: data, KeyRanges: []kv.KeyRange{fullIndexRange(tbInfo.tID, tbInfo.iIDs[0])}, Concurrency: 1}
+	data, err = client.Send(goctx.Background(), req).Next()
+	c.Assert(err, IsNil)
+	idxResp := new(tipb.AnalyzeIndexResp)
+	c.Assert(proto.Unmarshal(data, idxResp), IsNil)
+	c.Assert(idxResp.Hist.Ndv, Equals, count)
+}
+
+// sendDAGRequest sends the DAG request and returns the data of the rows.
+func sendDAGRequest(c *C, client kv.Client, dagReq *tipb.DAGRequest, ranges []kv.KeyRange) []byte {
+	data, err := proto.Marshal(dagReq)
+	c.Assert(err, IsNil)
+	req := &kv.Request{Tp: kv.ReqTypeDAG, Data: data, KeyRanges: ranges, Concurrency: 1}
+	data, err = client.Send(goctx.Background(), req).Next()
+	c.Assert(err, IsNil)
+	selResp := new(tipb.SelectResponse)
+	c.Assert(proto.Unmarshal(data, selResp), IsNil)
+	c.Assert(selResp.Error, IsNil)
+	var rowsData []byte
+	for _, chunk := range selResp.Chunks {
+		rowsData = append(rowsData, chunk.RowsData...)
+	}
+	return rowsData
+}
+
 // simpleTableInfo just have the minimum information enough to describe the table.
 // The first column is pk handle column.
 type simpleTableInfo struct {
@@ -241,6 +367,11 @@ func fullTableRange(tid int64) kv.KeyRange {
 	}
 }
 
+func pointTableRange(tid int64, handle int64) kv.KeyRange {
+	key := tablecodec.EncodeRowKey(tid, codec.EncodeInt(nil, handle))
+	return kv.KeyRange{StartKey: key, EndKey: key.PrefixNext()}
+}
+
 var fullPBTableRange = &tipb.KeyRange{
 	Low:  codec.EncodeInt(nil, math.MinInt64),
 	High: codec.EncodeInt(nil, math.MaxInt64),
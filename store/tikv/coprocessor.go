@@ -107,6 +107,7 @@ func (c *CopClient) Send(ctx goctx.Context, req *kv.Request) kv.Response {
 		req:         req,
 		concurrency: req.Concurrency,
 		finished:    make(chan struct{}),
+		startTime:   time.Now(),
 	}
 	it.tasks = tasks
 	if it.concurrency > len(tasks) {
@@ -306,6 +307,8 @@ type copIterator struct {
 	req         *kv.Request
 	concurrency int
 	finished    chan struct{}
+	// startTime is the time the tasks are built, it's used to calculate the wait time of the tasks.
+	startTime time.Time
 
 	// If keepOrder, results are stored in copTask.respChan, read them out one by one.
 	tasks []*copTask
@@ -339,6 +342,11 @@ func (it *copIterator) work(ctx goctx.Context, taskCh <-chan *copTask) {
 		if bo.totalSleep > 0 {
 			backoffHistogram.Observe(float64(bo.totalSleep) / 1000)
 		}
+		if it.req.CopStats != nil {
+			// The backoff time is counted as wait time.
+			backoff := time.Duration(bo.totalSleep) * time.Millisecond
+			it.req.CopStats.RecordCopTask(costTime-backoff, startTime.Sub(it.startTime)+backoff)
+		}
 		var ch chan copResponse
 		if !it.req.KeepOrder {
 			ch = it.respChan
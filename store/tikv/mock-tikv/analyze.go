@@ -14,12 +14,11 @@
 package mocktikv
 
 import (
-	"time"
-
 	"github.com/golang/protobuf/proto"
 	"github.com/juju/errors"
 	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/distsql/dagexec"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/util/codec"
@@ -57,14 +56,7 @@ func (h *rpcHandler) handleCopAnalyzeRequest(req *coprocessor.Request) *coproces
 }
 
 func (h *rpcHandler) handleAnalyzeIndexReq(req *coprocessor.Request, analyzeReq *tipb.AnalyzeReq) (*coprocessor.Response, error) {
-	e := &indexScanExec{
-		colsLen:        int(analyzeReq.IdxReq.NumColumns),
-		kvRanges:       h.extractKVRanges(req.Ranges, false),
-		startTS:        analyzeReq.StartTs,
-		isolationLevel: h.isolationLevel,
-		mvccStore:      h.mvccStore,
-		IndexScan:      &tipb.IndexScan{Desc: false},
-	}
+	e := dagexec.NewIndexScan(h.newSnapshot(analyzeReq.StartTs), int(analyzeReq.IdxReq.NumColumns), h.extractKVRanges(req.Ranges))
 	statsBuilder := statistics.NewSortedBuilder(flagsToStatementContext(analyzeReq.Flags), analyzeReq.IdxReq.BucketSize, 0)
 	for {
 		values, err := e.Next()
@@ -92,24 +84,14 @@ func (h *rpcHandler) handleAnalyzeIndexReq(req *coprocessor.Request, analyzeReq
 }
 
 type analyzeColumnsExec struct {
-	tblExec *tableScanExec
+	tblExec dagexec.Executor
 }
 
 func (h *rpcHandler) handleAnalyzeColumnsReq(req *coprocessor.Request, analyzeReq *tipb.AnalyzeReq) (*coprocessor.Response, error) {
 	sc := flagsToStatementContext(analyzeReq.Flags)
-	timeZone := time.FixedZone("UTC", int(analyzeReq.TimeZoneOffset))
-	evalCtx := &evalContext{sc: sc, timeZone: timeZone}
 	columns := analyzeReq.ColReq.ColumnsInfo
-	evalCtx.setColumnInfo(columns)
 	e := &analyzeColumnsExec{
-		tblExec: &tableScanExec{
-			TableScan:      &tipb.TableScan{Columns: columns},
-			kvRanges:       h.extractKVRanges(req.Ranges, false),
-			colIDs:         evalCtx.colIDs,
-			startTS:        analyzeReq.GetStartTs(),
-			isolationLevel: h.isolationLevel,
-			mvccStore:      h.mvccStore,
-		},
+		tblExec: dagexec.NewTableScan(h.newSnapshot(analyzeReq.GetStartTs()), columns, h.extractKVRanges(req.Ranges)),
 	}
 	pkID := int64(-1)
 	numCols := len(columns)
@@ -15,32 +15,19 @@ package mocktikv
 
 import (
 	"bytes"
-	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/juju/errors"
 	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
-	"github.com/pingcap/tidb/distsql"
-	"github.com/pingcap/tidb/expression/aggregation"
+	"github.com/pingcap/tidb/distsql/dagexec"
 	"github.com/pingcap/tidb/kv"
-	"github.com/pingcap/tidb/model"
-	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
-	"github.com/pingcap/tidb/tablecodec"
-	"github.com/pingcap/tidb/util/codec"
-	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 )
 
 var dummySlice = make([]byte, 0)
 
-type dagContext struct {
-	dagReq    *tipb.DAGRequest
-	keyRanges []*coprocessor.KeyRange
-	evalCtx   *evalContext
-}
-
 func (h *rpcHandler) handleCopDAGRequest(req *coprocessor.Request) *coprocessor.Response {
 	resp := &coprocessor.Response{}
 	if len(req.Ranges) == 0 {
@@ -60,14 +47,7 @@ func (h *rpcHandler) handleCopDAGRequest(req *coprocessor.Request) *coprocessor.
 		resp.OtherError = err.Error()
 		return resp
 	}
-	sc := flagsToStatementContext(dagReq.Flags)
-	timeZone := time.FixedZone("UTC", int(dagReq.TimeZoneOffset))
-	ctx := &dagContext{
-		dagReq:    dagReq,
-		keyRanges: req.Ranges,
-		evalCtx:   &evalContext{sc: sc, timeZone: timeZone},
-	}
-	e, err := h.buildDAG(ctx, dagReq.Executors)
+	e, err := dagexec.Build(h.newSnapshot(dagReq.GetStartTs()), dagReq, h.extractKVRanges(req.Ranges))
 	if err != nil {
 		return &coprocessor.Response{
 			OtherError: err.Error(),
@@ -96,215 +76,53 @@ func (h *rpcHandler) handleCopDAGRequest(req *coprocessor.Request) *coprocessor.
 	return buildResp(chunks, err)
 }
 
-func (h *rpcHandler) buildExec(ctx *dagContext, curr *tipb.Executor) (executor, error) {
-	var currExec executor
-	var err error
-	switch curr.GetTp() {
-	case tipb.ExecType_TypeTableScan:
-		currExec = h.buildTableScan(ctx, curr)
-	case tipb.ExecType_TypeIndexScan:
-		currExec = h.buildIndexScan(ctx, curr)
-	case tipb.ExecType_TypeSelection:
-		currExec, err = h.buildSelection(ctx, curr)
-	case tipb.ExecType_TypeAggregation:
-		currExec, err = h.buildAggregation(ctx, curr)
-	case tipb.ExecType_TypeTopN:
-		currExec, err = h.buildTopN(ctx, curr)
-	case tipb.ExecType_TypeLimit:
-		currExec = &limitExec{limit: curr.Limit.GetLimit()}
-	default:
-		// TODO: Support other types.
-		err = errors.Errorf("this exec type %v doesn't support yet.", curr.GetTp())
-	}
-
-	return currExec, errors.Trace(err)
-}
-
-func (h *rpcHandler) buildDAG(ctx *dagContext, executors []*tipb.Executor) (executor, error) {
-	var src executor
-	for i := 0; i < len(executors); i++ {
-		curr, err := h.buildExec(ctx, executors[i])
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		curr.SetSrcExec(src)
-		src = curr
-	}
-	return src, nil
+// mvccSnapshot is the snapshot of the MVCCStore at startTS read by the coprocessor executors.
+type mvccSnapshot struct {
+	mvccStore      MVCCStore
+	startTS        uint64
+	isolationLevel kvrpcpb.IsolationLevel
 }
 
-func (h *rpcHandler) buildTableScan(ctx *dagContext, executor *tipb.Executor) *tableScanExec {
-	columns := executor.TblScan.Columns
-	ctx.evalCtx.setColumnInfo(columns)
-	ranges := h.extractKVRanges(ctx.keyRanges, executor.TblScan.Desc)
-
-	return &tableScanExec{
-		TableScan:      executor.TblScan,
-		kvRanges:       ranges,
-		colIDs:         ctx.evalCtx.colIDs,
-		startTS:        ctx.dagReq.GetStartTs(),
-		isolationLevel: h.isolationLevel,
+func (h *rpcHandler) newSnapshot(startTS uint64) *mvccSnapshot {
+	return &mvccSnapshot{
 		mvccStore:      h.mvccStore,
-	}
-}
-
-func (h *rpcHandler) buildIndexScan(ctx *dagContext, executor *tipb.Executor) *indexScanExec {
-	columns := executor.IdxScan.Columns
-	ctx.evalCtx.setColumnInfo(columns)
-	length := len(columns)
-	pkStatus := pkColNotExists
-	// The PKHandle column info has been collected in ctx.
-	if columns[length-1].GetPkHandle() {
-		if mysql.HasUnsignedFlag(uint(columns[length-1].GetFlag())) {
-			pkStatus = pkColIsUnsigned
-		} else {
-			pkStatus = pkColIsSigned
-		}
-		columns = columns[:length-1]
-	} else if columns[length-1].ColumnId == model.ExtraHandleID {
-		pkStatus = pkColIsSigned
-		columns = columns[:length-1]
-	}
-	ranges := h.extractKVRanges(ctx.keyRanges, executor.IdxScan.Desc)
-
-	return &indexScanExec{
-		IndexScan:      executor.IdxScan,
-		kvRanges:       ranges,
-		colsLen:        len(columns),
-		startTS:        ctx.dagReq.GetStartTs(),
+		startTS:        startTS,
 		isolationLevel: h.isolationLevel,
-		mvccStore:      h.mvccStore,
-		pkStatus:       pkStatus,
 	}
 }
 
-func (h *rpcHandler) buildSelection(ctx *dagContext, executor *tipb.Executor) (*selectionExec, error) {
-	var err error
-	var relatedColOffsets []int
-	pbConds := executor.Selection.Conditions
-	for _, cond := range pbConds {
-		relatedColOffsets, err = extractOffsetsInExpr(cond, ctx.evalCtx.columnInfos, relatedColOffsets)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-	}
-	conds, err := convertToExprs(ctx.evalCtx.sc, ctx.evalCtx.fieldTps, pbConds)
+// Get implements dagexec.Snapshot Get interface.
+func (s *mvccSnapshot) Get(key kv.Key) ([]byte, error) {
+	val, err := s.mvccStore.Get(key, s.startTS, s.isolationLevel)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-
-	return &selectionExec{
-		evalCtx:           ctx.evalCtx,
-		relatedColOffsets: relatedColOffsets,
-		conditions:        conds,
-		row:               make([]types.Datum, len(ctx.evalCtx.columnInfos)),
-	}, nil
-}
-
-func (h *rpcHandler) buildAggregation(ctx *dagContext, executor *tipb.Executor) (*aggregateExec, error) {
-	length := len(executor.Aggregation.AggFunc)
-	aggs := make([]aggregation.Aggregation, 0, length)
-	var err error
-	var relatedColOffsets []int
-	for _, expr := range executor.Aggregation.AggFunc {
-		var aggExpr aggregation.Aggregation
-		aggExpr, err = aggregation.NewDistAggFunc(expr, ctx.evalCtx.fieldTps, ctx.evalCtx.sc)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		aggs = append(aggs, aggExpr)
-		relatedColOffsets, err = extractOffsetsInExpr(expr, ctx.evalCtx.columnInfos, relatedColOffsets)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-	}
-	for _, item := range executor.Aggregation.GroupBy {
-		relatedColOffsets, err = extractOffsetsInExpr(item, ctx.evalCtx.columnInfos, relatedColOffsets)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-	}
-	groupBys, err := convertToExprs(ctx.evalCtx.sc, ctx.evalCtx.fieldTps, executor.Aggregation.GetGroupBy())
-	if err != nil {
-		return nil, errors.Trace(err)
+	if len(val) == 0 {
+		return nil, nil
 	}
-
-	return &aggregateExec{
-		evalCtx:           ctx.evalCtx,
-		aggExprs:          aggs,
-		groupByExprs:      groupBys,
-		groups:            make(map[string]struct{}),
-		groupKeys:         make([][]byte, 0),
-		relatedColOffsets: relatedColOffsets,
-		row:               make([]types.Datum, len(ctx.evalCtx.columnInfos)),
-	}, nil
+	return val, nil
 }
 
-func (h *rpcHandler) buildTopN(ctx *dagContext, executor *tipb.Executor) (*topNExec, error) {
-	topN := executor.TopN
-	var err error
-	var relatedColOffsets []int
-	pbConds := make([]*tipb.Expr, len(topN.OrderBy))
-	for i, item := range topN.OrderBy {
-		relatedColOffsets, err = extractOffsetsInExpr(item.Expr, ctx.evalCtx.columnInfos, relatedColOffsets)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		pbConds[i] = item.Expr
+// Scan implements dagexec.Snapshot Scan interface.
+func (s *mvccSnapshot) Scan(startKey, endKey kv.Key, desc bool) (kv.Key, []byte, error) {
+	var pairs []Pair
+	if desc {
+		pairs = s.mvccStore.ReverseScan(startKey, endKey, 1, s.startTS, s.isolationLevel)
+	} else {
+		pairs = s.mvccStore.Scan(startKey, endKey, 1, s.startTS, s.isolationLevel)
 	}
-	heap := &topNHeap{
-		totalCount: int(topN.Limit),
-		topNSorter: topNSorter{
-			orderByItems: topN.OrderBy,
-			sc:           ctx.evalCtx.sc,
-		},
+	if len(pairs) == 0 {
+		return nil, nil, nil
 	}
-
-	conds, err := convertToExprs(ctx.evalCtx.sc, ctx.evalCtx.fieldTps, pbConds)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-
-	return &topNExec{
-		heap:              heap,
-		evalCtx:           ctx.evalCtx,
-		relatedColOffsets: relatedColOffsets,
-		orderByExprs:      conds,
-		row:               make([]types.Datum, len(ctx.evalCtx.columnInfos)),
-	}, nil
-}
-
-type evalContext struct {
-	colIDs      map[int64]int
-	columnInfos []*tipb.ColumnInfo
-	fieldTps    []*types.FieldType
-	sc          *variable.StatementContext
-	timeZone    *time.Location
-}
-
-func (e *evalContext) setColumnInfo(cols []*tipb.ColumnInfo) {
-	e.columnInfos = make([]*tipb.ColumnInfo, len(cols))
-	copy(e.columnInfos, cols)
-
-	e.colIDs = make(map[int64]int)
-	e.fieldTps = make([]*types.FieldType, 0, len(e.columnInfos))
-	for i, col := range e.columnInfos {
-		ft := distsql.FieldTypeFromPBColumn(col)
-		e.fieldTps = append(e.fieldTps, ft)
-		e.colIDs[col.GetColumnId()] = i
+	pair := pairs[0]
+	if pair.Err != nil {
+		// TODO: Handle lock error.
+		return nil, nil, errors.Trace(pair.Err)
 	}
-}
-
-// decodeRelatedColumnVals decodes data to Datum slice according to the row information.
-func (e *evalContext) decodeRelatedColumnVals(relatedColOffsets []int, value [][]byte, row []types.Datum) error {
-	var err error
-	for _, offset := range relatedColOffsets {
-		row[offset], err = tablecodec.DecodeColumnValue(value[offset], e.fieldTps[offset], e.timeZone)
-		if err != nil {
-			return errors.Trace(err)
-		}
+	if pair.Key == nil {
+		return nil, nil, nil
 	}
-	return nil
+	return pair.Key, pair.Value, nil
 }
 
 // Flags are used by tipb.SelectRequest.Flags to handle execution mode, like how to handle truncate error.
@@ -365,7 +183,7 @@ func toPBError(err error) *tipb.Error {
 }
 
 // extractKVRanges extracts kv.KeyRanges slice from a SelectRequest.
-func (h *rpcHandler) extractKVRanges(keyRanges []*coprocessor.KeyRange, descScan bool) (kvRanges []kv.KeyRange) {
+func (h *rpcHandler) extractKVRanges(keyRanges []*coprocessor.KeyRange) (kvRanges []kv.KeyRange) {
 	for _, kran := range keyRanges {
 		upperKey := kran.GetEnd()
 		if bytes.Compare(upperKey, h.rawStartKey) <= 0 {
@@ -380,19 +198,9 @@ func (h *rpcHandler) extractKVRanges(keyRanges []*coprocessor.KeyRange, descScan
 		kvr.EndKey = kv.Key(minEndKey(upperKey, h.rawEndKey))
 		kvRanges = append(kvRanges, kvr)
 	}
-	if descScan {
-		reverseKVRanges(kvRanges)
-	}
 	return
 }
 
-func reverseKVRanges(kvRanges []kv.KeyRange) {
-	for i := 0; i < len(kvRanges)/2; i++ {
-		j := len(kvRanges) - i - 1
-		kvRanges[i], kvRanges[j] = kvRanges[j], kvRanges[i]
-	}
-}
-
 const rowsPerChunk = 64
 
 func appendRow(chunks []tipb.Chunk, data []byte, rowCnt int) []tipb.Chunk {
@@ -417,36 +225,3 @@ func minEndKey(rangeEndKey kv.Key, regionEndKey []byte) []byte {
 	}
 	return regionEndKey
 }
-
-func isDuplicated(offsets []int, offset int) bool {
-	for _, idx := range offsets {
-		if idx == offset {
-			return true
-		}
-	}
-	return false
-}
-
-func extractOffsetsInExpr(expr *tipb.Expr, columns []*tipb.ColumnInfo, collector []int) ([]int, error) {
-	if expr == nil {
-		return nil, nil
-	}
-	if expr.GetTp() == tipb.ExprType_ColumnRef {
-		_, idx, err := codec.DecodeInt(expr.Val)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		if !isDuplicated(collector, int(idx)) {
-			collector = append(collector, int(idx))
-		}
-		return collector, nil
-	}
-	var err error
-	for _, child := range expr.Children {
-		collector, err = extractOffsetsInExpr(child, columns, collector)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-	}
-	return collector, nil
-}
@@ -214,3 +214,11 @@ func (txn *tikvTxn) Len() int {
 func (txn *tikvTxn) Size() int {
 	return txn.us.Size()
 }
+
+func (txn *tikvTxn) Checkpoint() int {
+	return txn.us.Checkpoint()
+}
+
+func (txn *tikvTxn) RollbackToCheckpoint(cp int) error {
+	return txn.us.RollbackToCheckpoint(cp)
+}
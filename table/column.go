@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tidb/util/types/json"
@@ -38,6 +39,9 @@ type Column struct {
 	*model.ColumnInfo
 	// If this column is a generated column, the expression will be stored here.
 	GeneratedExpr ast.ExprNode
+	// GeneratedEvalExpr is GeneratedExpr built on the columns of the table, it calculates the value
+	// of the generated column from a row indexed by the column offset.
+	GeneratedEvalExpr expression.Expression
 }
 
 // String implements fmt.Stringer interface.
@@ -73,6 +77,7 @@ func ToColumn(col *model.ColumnInfo) *Column {
 	return &Column{
 		col,
 		nil,
+		nil,
 	}
 }
 
@@ -103,6 +108,27 @@ func FindOnUpdateCols(cols []*Column) []*Column {
 	return rcols
 }
 
+// IsVirtualGenerated checks whether the column is a virtual generated column, whose value isn't stored.
+func (c *Column) IsVirtualGenerated() bool {
+	return c.IsGenerated() && !c.GeneratedStored
+}
+
+// FillVirtualColumnValues calculates the values of the virtual generated columns in cols for the row
+// indexed by the column offset, the row must have the values of the columns they depend on.
+func FillVirtualColumnValues(cols []*Column, row []types.Datum) error {
+	for _, col := range cols {
+		if col == nil || !col.IsVirtualGenerated() || col.GeneratedEvalExpr == nil {
+			continue
+		}
+		val, err := col.GeneratedEvalExpr.Eval(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		row[col.Offset] = val
+	}
+	return nil
+}
+
 // truncateTrailingSpaces trancates trailing spaces for CHAR[(M)] column.
 // fix: https://github.com/pingcap/tidb/issues/3660
 func truncateTrailingSpaces(v *types.Datum) {
@@ -149,6 +175,9 @@ func CastValue(ctx context.Context, val types.Datum, col *model.ColumnInfo) (cas
 	if err != nil {
 		return casted, errors.Trace(err)
 	}
+	if charset.IsTranscoded(col.Charset) {
+		return castToCharset(ctx, casted, col)
+	}
 	if ctx.GetSessionVars().SkipUTF8Check {
 		return casted, nil
 	}
@@ -172,6 +201,22 @@ func CastValue(ctx context.Context, val types.Datum, col *model.ColumnInfo) (cas
 	return casted, errors.Trace(err)
 }
 
+// castToCharset checks the string can be represented in the charset of the column it's stored in,
+// the characters which can't be represented are replaced by '?'.
+func castToCharset(ctx context.Context, casted types.Datum, col *model.ColumnInfo) (types.Datum, error) {
+	if casted.Kind() != types.KindString && casted.Kind() != types.KindBytes {
+		return casted, nil
+	}
+	str := casted.GetString()
+	encoded, ok := charset.Encode(col.Charset, str)
+	if ok {
+		return casted, nil
+	}
+	casted = types.NewStringDatum(charset.Decode(col.Charset, encoded))
+	err := ctx.GetSessionVars().StmtCtx.HandleTruncate(ErrTruncateWrongValue)
+	return casted, errors.Trace(err)
+}
+
 // ColDesc describes column information like MySQL desc and show columns do.
 type ColDesc struct {
 	Field        string
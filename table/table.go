@@ -19,10 +19,12 @@ package table
 
 import (
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -67,6 +69,8 @@ var (
 	ErrInvalidRecordKey = terror.ClassTable.New(codeInvalidRecordKey, "invalid record key")
 	// ErrTruncateWrongValue returns for truncate wrong value for field.
 	ErrTruncateWrongValue = terror.ClassTable.New(codeTruncateWrongValue, "Incorrect value")
+	// ErrNoPartitionForGivenValue is returned when a row doesn't belong to any partition of the table.
+	ErrNoPartitionForGivenValue = terror.ClassTable.New(codeNoPartitionForGivenValue, mysql.MySQLErrName[mysql.ErrNoPartitionForGivenValue])
 )
 
 // RecordIterFunc is used for low-level record iteration.
@@ -141,6 +145,19 @@ type Table interface {
 	Type() Type
 }
 
+// PartitionedTable is a Table whose rows are stored in several partitions. Each partition is
+// stored as a physical table with the ID of its partition definition.
+type PartitionedTable interface {
+	Table
+	// GetPartition returns the physical table of the partition with the given ID, nil if not found.
+	GetPartition(pid int64) Table
+	// PartitionExpr returns the partitioning expression, its columns are indexed by the column offsets.
+	PartitionExpr() expression.Expression
+	// LocatePartition returns the index of the partition definition that a value of the partitioning
+	// expression belongs to.
+	LocatePartition(sc *variable.StatementContext, v types.Datum) (int, error)
+}
+
 // TableFromMeta builds a table.Table from *model.TableInfo.
 // Currently, it is assigned to tables.TableFromMeta in tidb package's init function.
 var TableFromMeta func(alloc autoid.Allocator, tblInfo *model.TableInfo) (Table, error)
@@ -165,6 +182,8 @@ const (
 	codeDuplicateColumn    = 1110
 	codeNoDefaultValue     = 1364
 	codeTruncateWrongValue = 1366
+
+	codeNoPartitionForGivenValue = 1526
 )
 
 // Slice is used for table sorting.
@@ -185,6 +204,8 @@ func init() {
 		codeDuplicateColumn:    mysql.ErrFieldSpecifiedTwice,
 		codeNoDefaultValue:     mysql.ErrNoDefaultForField,
 		codeTruncateWrongValue: mysql.ErrTruncatedWrongValueForField,
+
+		codeNoPartitionForGivenValue: mysql.ErrNoPartitionForGivenValue,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTable] = tableMySQLErrCodes
 }
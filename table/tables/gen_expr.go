@@ -18,8 +18,11 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/mock"
 )
 
 // getDefaultCharsetAndCollate is copyed from ddl/ddl_api.go.
@@ -80,3 +83,31 @@ func simpleResolveName(node ast.ExprNode, tblInfo *model.TableInfo) (ast.ExprNod
 	}
 	return node, nil
 }
+
+// buildGeneratedEvalExprs builds the generation expressions of the generated columns on the columns of the
+// table, they calculate the values of the virtual generated columns when the rows are read, and the values
+// of the generated columns when the rows are reorganized or checked.
+func buildGeneratedEvalExprs(cols []*table.Column, tblInfo *model.TableInfo) error {
+	ctx := mock.NewContext()
+	schema := expression.TableInfo2Schema(tblInfo)
+	for _, col := range cols {
+		if !col.IsGenerated() {
+			continue
+		}
+		if expression.RewriteAstExpr == nil {
+			return errors.Errorf("can't build the generation expression of column %s", col.Name)
+		}
+		node, err := parseExpression(col.GeneratedExprString)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		expr, err := expression.RewriteAstExpr(node, schema, ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// The expression may return a different type from the column, so it's wrapped by a CAST as
+		// the generated columns calculated in the plans.
+		col.GeneratedEvalExpr = expression.BuildCastFunction(ctx, expr, &col.FieldType)
+	}
+	return nil
+}
@@ -97,6 +97,9 @@ type index struct {
 	tblInfo *model.TableInfo
 	idxInfo *model.IndexInfo
 	prefix  kv.Key
+	// collationTypes are the field types of the index columns if any of them is compared under
+	// a non-binary collation, the sort keys of the values are encoded in the index keys.
+	collationTypes []*types.FieldType
 
 	buffer []byte // It's used reduce the number of new slice when multiple index keys are created.
 }
@@ -105,10 +108,11 @@ type index struct {
 func NewIndexWithBuffer(tableInfo *model.TableInfo, indexInfo *model.IndexInfo) table.Index {
 	idxPrefix := tablecodec.EncodeTableIndexPrefix(tableInfo.ID, indexInfo.ID)
 	index := &index{
-		tblInfo: tableInfo,
-		idxInfo: indexInfo,
-		prefix:  idxPrefix,
-		buffer:  make([]byte, 0, len(idxPrefix)+len(indexInfo.Columns)*9+9),
+		tblInfo:        tableInfo,
+		idxInfo:        indexInfo,
+		prefix:         idxPrefix,
+		collationTypes: indexCollationTypes(tableInfo, indexInfo),
+		buffer:         make([]byte, 0, len(idxPrefix)+len(indexInfo.Columns)*9+9),
 	}
 	return index
 }
@@ -116,13 +120,32 @@ func NewIndexWithBuffer(tableInfo *model.TableInfo, indexInfo *model.IndexInfo)
 // NewIndex builds a new Index object.
 func NewIndex(tableInfo *model.TableInfo, indexInfo *model.IndexInfo) table.Index {
 	index := &index{
-		tblInfo: tableInfo,
-		idxInfo: indexInfo,
-		prefix:  tablecodec.EncodeTableIndexPrefix(tableInfo.ID, indexInfo.ID),
+		tblInfo:        tableInfo,
+		idxInfo:        indexInfo,
+		prefix:         tablecodec.EncodeTableIndexPrefix(tableInfo.ID, indexInfo.ID),
+		collationTypes: indexCollationTypes(tableInfo, indexInfo),
 	}
 	return index
 }
 
+// indexCollationTypes returns the field types of the index columns, it returns nil if all the
+// index columns are compared as binary.
+func indexCollationTypes(tableInfo *model.TableInfo, indexInfo *model.IndexInfo) []*types.FieldType {
+	var hasCollationKey bool
+	fieldTypes := make([]*types.FieldType, len(indexInfo.Columns))
+	for i, ic := range indexInfo.Columns {
+		if ic.Offset < 0 || ic.Offset >= len(tableInfo.Columns) {
+			return nil
+		}
+		fieldTypes[i] = tableInfo.StorageType(tableInfo.Columns[ic.Offset])
+		hasCollationKey = hasCollationKey || types.HasCollationKey(fieldTypes[i])
+	}
+	if !hasCollationKey {
+		return nil
+	}
+	return fieldTypes
+}
+
 // Meta returns index info.
 func (c *index) Meta() *model.IndexInfo {
 	return c.idxInfo
@@ -158,6 +181,10 @@ func (c *index) GenIndexKey(indexedValues []types.Datum, h int64) (key []byte, d
 		}
 	}
 
+	if c.collationTypes != nil {
+		indexedValues = tablecodec.CollationKeys(indexedValues, c.collationTypes)
+	}
+
 	if c.buffer != nil {
 		key = c.buffer[:0]
 	} else {
@@ -0,0 +1,256 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// partitionedTable implements the table.PartitionedTable interface.
+// The embedded Table is the logical table, it holds no data but allocates the handles
+// of all the partitions, so a handle identifies a row in the whole table.
+type partitionedTable struct {
+	Table
+
+	partitionExpr expression.Expression
+	// upperBounds are the upper bounds of range partitions.
+	upperBounds []int64
+	// maxValue is true if the last range partition has no upper bound.
+	maxValue   bool
+	partitions []*Table
+}
+
+// PartitionInfoForPartition returns a copy of tblInfo that describes the physical table of the
+// partition def, the copy has the ID of the partition.
+func PartitionInfoForPartition(tblInfo *model.TableInfo, def model.PartitionDefinition) *model.TableInfo {
+	pInfo := *tblInfo
+	pInfo.ID = def.ID
+	pInfo.Partition = nil
+	return &pInfo
+}
+
+func newPartitionedTable(tbl *Table, tblInfo *model.TableInfo) (table.Table, error) {
+	pi := tblInfo.Partition
+	expr, err := parseExpression(pi.Expr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if expression.RewriteAstExpr == nil {
+		return nil, errors.Errorf("can't build the partitioning expression of table %s", tblInfo.Name)
+	}
+	schema := expression.TableInfo2Schema(tblInfo)
+	partitionExpr, err := expression.RewriteAstExpr(expr, schema, mock.NewContext())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	t := &partitionedTable{
+		Table:         *tbl,
+		partitionExpr: partitionExpr,
+		partitions:    make([]*Table, 0, len(pi.Definitions)),
+	}
+	for i, def := range pi.Definitions {
+		if pi.Type == model.PartitionTypeRange {
+			if def.LessThan == model.PartitionMaxValue {
+				t.maxValue = i == len(pi.Definitions)-1
+			} else {
+				bound, err := strconv.ParseInt(def.LessThan, 10, 64)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				t.upperBounds = append(t.upperBounds, bound)
+			}
+		}
+		pInfo := PartitionInfoForPartition(tblInfo, def)
+		p := newTable(def.ID, tbl.Columns, tbl.alloc)
+		for _, idxInfo := range tblInfo.Indices {
+			p.indices = append(p.indices, NewIndex(pInfo, idxInfo))
+		}
+		p.meta = pInfo
+		t.partitions = append(t.partitions, p)
+	}
+	return t, nil
+}
+
+// GetPartition implements table.PartitionedTable GetPartition interface.
+func (t *partitionedTable) GetPartition(pid int64) table.Table {
+	for _, p := range t.partitions {
+		if p.ID == pid {
+			return p
+		}
+	}
+	return nil
+}
+
+// PartitionExpr implements table.PartitionedTable PartitionExpr interface.
+func (t *partitionedTable) PartitionExpr() expression.Expression {
+	return t.partitionExpr
+}
+
+// LocatePartition implements table.PartitionedTable LocatePartition interface.
+// A NULL value belongs to the first partition.
+func (t *partitionedTable) LocatePartition(sc *variable.StatementContext, v types.Datum) (int, error) {
+	if v.IsNull() {
+		return 0, nil
+	}
+	val, err := v.ToInt64(sc)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if t.meta.Partition.Type == model.PartitionTypeHash {
+		idx := val % int64(len(t.partitions))
+		if idx < 0 {
+			idx = -idx
+		}
+		return int(idx), nil
+	}
+	idx := sort.Search(len(t.upperBounds), func(i int) bool { return val < t.upperBounds[i] })
+	if idx == len(t.upperBounds) && !t.maxValue {
+		return 0, table.ErrNoPartitionForGivenValue.GenByArgs(strconv.FormatInt(val, 10))
+	}
+	return idx, nil
+}
+
+// locatePartition returns the partition that the row r belongs to.
+func (t *partitionedTable) locatePartition(ctx context.Context, r []types.Datum) (*Table, error) {
+	v, err := t.partitionExpr.Eval(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	idx, err := t.LocatePartition(ctx.GetSessionVars().StmtCtx, v)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return t.partitions[idx], nil
+}
+
+// AddRecord implements table.Table AddRecord interface.
+func (t *partitionedTable) AddRecord(ctx context.Context, r []types.Datum) (recordID int64, err error) {
+	p, err := t.locatePartition(ctx, r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	recordID, err = t.genRecordID(r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	recordID, err = p.addRecord(ctx, r, recordID)
+	if err != nil {
+		return recordID, errors.Trace(err)
+	}
+	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.ID, 1, 1)
+	return recordID, nil
+}
+
+// UpdateRecord implements table.Table UpdateRecord interface.
+// If the new row belongs to another partition, it's moved with the same handle.
+func (t *partitionedTable) UpdateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, touched []bool) error {
+	from, err := t.locatePartition(ctx, oldData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	to, err := t.locatePartition(ctx, newData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if from == to {
+		return errors.Trace(from.UpdateRecord(ctx, h, oldData, newData, touched))
+	}
+	if err = from.RemoveRecord(ctx, h, oldData); err != nil {
+		return errors.Trace(err)
+	}
+	_, err = to.addRecord(ctx, newData, h)
+	return errors.Trace(err)
+}
+
+// RemoveRecord implements table.Table RemoveRecord interface.
+func (t *partitionedTable) RemoveRecord(ctx context.Context, h int64, r []types.Datum) error {
+	p, err := t.locatePartition(ctx, r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(p.RemoveRecord(ctx, h, r))
+}
+
+// RowWithCols implements table.Table RowWithCols interface.
+// The handle is unique in the table, so the row is read from the first partition that has it.
+func (t *partitionedTable) RowWithCols(ctx context.Context, h int64, cols []*table.Column) ([]types.Datum, error) {
+	for _, p := range t.partitions {
+		row, err := p.RowWithCols(ctx, h, cols)
+		if kv.ErrNotExist.Equal(err) {
+			continue
+		}
+		return row, errors.Trace(err)
+	}
+	return nil, errors.Trace(kv.ErrNotExist)
+}
+
+// Row implements table.Table Row interface.
+func (t *partitionedTable) Row(ctx context.Context, h int64) ([]types.Datum, error) {
+	r, err := t.RowWithCols(ctx, h, t.Cols())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r, nil
+}
+
+// IterRecords implements table.Table IterRecords interface.
+// Records are iterated partition by partition, so only the whole table can be iterated.
+func (t *partitionedTable) IterRecords(ctx context.Context, startKey kv.Key, cols []*table.Column,
+	fn table.RecordIterFunc) error {
+	if startKey != nil && startKey.Cmp(t.FirstKey()) != 0 {
+		return table.ErrUnsupportedOp.Gen("iterate records of a partitioned table from a key")
+	}
+	more := true
+	for _, p := range t.partitions {
+		err := p.IterRecords(ctx, p.FirstKey(), cols, func(h int64, rec []types.Datum, cols []*table.Column) (bool, error) {
+			var err error
+			more, err = fn(h, rec, cols)
+			return more, errors.Trace(err)
+		})
+		if err != nil || !more {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// Seek implements table.Table Seek interface.
+func (t *partitionedTable) Seek(ctx context.Context, h int64) (int64, bool, error) {
+	var (
+		handle int64
+		found  bool
+	)
+	for _, p := range t.partitions {
+		ph, ok, err := p.Seek(ctx, h)
+		if err != nil {
+			return 0, false, errors.Trace(err)
+		}
+		if ok && (!found || ph < handle) {
+			handle, found = ph, true
+		}
+	}
+	return handle, found, nil
+}
@@ -90,6 +90,9 @@ func TableFromMeta(alloc autoid.Allocator, tblInfo *model.TableInfo) (table.Tabl
 		}
 		columns = append(columns, col)
 	}
+	if err := buildGeneratedEvalExprs(columns, tblInfo); err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	t := newTable(tblInfo.ID, columns, alloc)
 
@@ -103,6 +106,9 @@ func TableFromMeta(alloc autoid.Allocator, tblInfo *model.TableInfo) (table.Tabl
 	}
 
 	t.meta = tblInfo
+	if tblInfo.Partition != nil {
+		return newPartitionedTable(t, tblInfo)
+	}
 	return t, nil
 }
 
@@ -214,8 +220,17 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 	txn := ctx.Txn()
 	bs := kv.NewBufferStore(txn)
 
+	// The old values of the changing columns are only used to remove index entries, if they can't be
+	// converted, the row hasn't been backfilled and there is no entry to remove.
+	oldData, _ = t.fillChangingColumns(ctx, oldData, true)
+	newData, err := t.fillChangingColumns(ctx, newData, false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	touched = t.changingColumnsTouched(touched, len(newData))
+
 	// rebuild index
-	err := t.rebuildIndices(bs, h, touched, oldData, newData)
+	err = t.rebuildIndices(bs, h, touched, oldData, newData)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -232,7 +247,9 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 
 	for _, col := range t.WritableCols() {
 		var value types.Datum
-		if col.State != model.StatePublic {
+		if col.ChangeStateInfo != nil {
+			value = newData[col.Offset]
+		} else if col.State != model.StatePublic {
 			// If col is in write only or write reorganization state
 			// and the value is not default, keep the original value.
 			value, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
@@ -249,14 +266,15 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 		} else {
 			value = newData[col.Offset]
 		}
+		storageType := t.meta.StorageType(col.ToInfo())
 		if !t.canSkip(col, value) {
 			colIDs = append(colIDs, col.ID)
-			row = append(row, value)
+			row = append(row, tablecodec.EncodeCharset(value, storageType))
 		}
 		if shouldWriteBinlog(ctx) && !t.canSkipUpdateBinlog(col, value) {
 			binlogColIDs = append(binlogColIDs, col.ID)
-			binlogOldRow = append(binlogOldRow, oldData[col.Offset])
-			binlogNewRow = append(binlogNewRow, value)
+			binlogOldRow = append(binlogOldRow, tablecodec.EncodeCharset(oldData[col.Offset], storageType))
+			binlogNewRow = append(binlogNewRow, tablecodec.EncodeCharset(value, storageType))
 		}
 	}
 
@@ -316,24 +334,41 @@ func (t *Table) rebuildIndices(rm kv.RetrieverMutator, h int64, touched []bool,
 
 // AddRecord implements table.Table AddRecord interface.
 func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64, err error) {
-	var hasRecordID bool
+	recordID, err = t.genRecordID(r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	recordID, err = t.addRecord(ctx, r, recordID)
+	if err != nil {
+		return recordID, errors.Trace(err)
+	}
+	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.ID, 1, 1)
+	return recordID, nil
+}
+
+// genRecordID returns the handle of a new row, it's the value of the PK handle column if there is one.
+func (t *Table) genRecordID(r []types.Datum) (int64, error) {
 	for _, col := range t.Cols() {
 		if col.IsPKHandleColumn(t.meta) {
-			recordID = r[col.Offset].GetInt64()
-			hasRecordID = true
-			break
-		}
-	}
-	if !hasRecordID {
-		recordID, err = t.alloc.Alloc(t.ID)
-		if err != nil {
-			return 0, errors.Trace(err)
+			return r[col.Offset].GetInt64(), nil
 		}
 	}
+	recordID, err := t.alloc.Alloc(t.ID)
+	return recordID, errors.Trace(err)
+}
 
+// addRecord writes the row and its index entries with the handle recordID. If any key is duplicated,
+// it returns the original handle.
+func (t *Table) addRecord(ctx context.Context, r []types.Datum, recordID int64) (int64, error) {
 	txn := ctx.Txn()
 	bs := kv.NewBufferStore(txn)
 
+	r, err := t.fillChangingColumns(ctx, r, false)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+
 	skipCheck := ctx.GetSessionVars().SkipConstraintCheck
 	if skipCheck {
 		txn.SetOption(kv.SkipCheckForWrite, true)
@@ -352,7 +387,9 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 
 	for _, col := range t.WritableCols() {
 		var value types.Datum
-		if col.State != model.StatePublic {
+		if col.ChangeStateInfo != nil {
+			value = r[col.Offset]
+		} else if col.State != model.StatePublic {
 			// If col is in write only or write reorganization state, we must add it with its default value.
 			value, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
 			if err != nil {
@@ -363,7 +400,7 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 		}
 		if !t.canSkip(col, value) {
 			colIDs = append(colIDs, col.ID)
-			row = append(row, value)
+			row = append(row, tablecodec.EncodeCharset(value, t.meta.StorageType(col.ToInfo())))
 		}
 	}
 
@@ -387,11 +424,79 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 			return 0, errors.Trace(err)
 		}
 	}
-	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
-	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.ID, 1, 1)
 	return recordID, nil
 }
 
+// fillChangingColumns returns the row r extended to all the columns of the table, the values of the
+// columns changed by MODIFY COLUMN are converted from the columns they depend on, or calculated by the
+// new generation expressions for the generated columns. If a value can't be converted, an error is
+// returned unless ignoreErr is true. The old column replaced by MODIFY COLUMN gets the zero value
+// instead, it's only read by the servers still on the older schema.
+func (t *Table) fillChangingColumns(ctx context.Context, r []types.Datum, ignoreErr bool) ([]types.Datum, error) {
+	var row []types.Datum
+	for _, col := range t.Columns {
+		if col.ChangeStateInfo == nil {
+			continue
+		}
+		if row == nil {
+			row = make([]types.Datum, len(t.Columns))
+			copy(row, r)
+		}
+		value, err := t.changingColumnValue(ctx, row, col)
+		if err != nil && col.ChangeStateInfo.Replaced {
+			value, err = table.GetZeroValue(col.ToInfo()), nil
+		}
+		if err != nil && !ignoreErr {
+			return nil, errors.Trace(err)
+		}
+		row[col.Offset] = value
+	}
+	if row == nil {
+		return r, nil
+	}
+	return row, nil
+}
+
+// changingColumnsTouched returns touched extended to length n, a column changed by MODIFY COLUMN is touched
+// if the column it depends on is touched.
+func (t *Table) changingColumnsTouched(touched []bool, n int) []bool {
+	var newTouched []bool
+	for _, col := range t.Columns {
+		if col.ChangeStateInfo == nil {
+			continue
+		}
+		if newTouched == nil {
+			newTouched = make([]bool, n)
+			copy(newTouched, touched)
+		}
+		touchedCol := newTouched[col.ChangeStateInfo.DependencyColumnOffset]
+		// A generated column is also touched if a column in its generation expression is touched.
+		for _, c := range t.Columns {
+			if _, ok := col.Dependences[c.Name.L]; ok && c.Offset < n && newTouched[c.Offset] {
+				touchedCol = true
+			}
+		}
+		newTouched[col.Offset] = touchedCol
+	}
+	if newTouched == nil {
+		return touched
+	}
+	return newTouched
+}
+
+// changingColumnValue returns the value of the column changed by MODIFY COLUMN for the row indexed by the
+// column offset.
+func (t *Table) changingColumnValue(ctx context.Context, row []types.Datum, col *table.Column) (types.Datum, error) {
+	if col.GeneratedEvalExpr == nil {
+		return table.CastValue(ctx, row[col.ChangeStateInfo.DependencyColumnOffset], col.ToInfo())
+	}
+	value, err := col.GeneratedEvalExpr.Eval(row)
+	if err != nil {
+		return value, errors.Trace(err)
+	}
+	return table.CastValue(ctx, value, col.ToInfo())
+}
+
 // genIndexKeyStr generates index content string representation.
 func (t *Table) genIndexKeyStr(colVals []types.Datum) (string, error) {
 	// Pass pre-composed error to txn.
@@ -478,12 +583,18 @@ func (t *Table) RowWithCols(ctx context.Context, h int64, cols []*table.Column)
 			}
 			continue
 		}
-		colTps[col.ID] = &col.FieldType
+		colTps[col.ID] = t.meta.StorageType(col.ToInfo())
 	}
+	hasVirtualCol := t.addVirtualColumnDeps(cols, colTps)
 	rowMap, err := tablecodec.DecodeRow(value, colTps, ctx.GetSessionVars().GetTimeZone())
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if hasVirtualCol {
+		if rowMap, err = t.fillVirtualColumns(ctx, h, rowMap); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	defaultVals := make([]types.Datum, len(cols))
 	for i, col := range cols {
 		if col == nil {
@@ -520,16 +631,19 @@ func (t *Table) RemoveRecord(ctx context.Context, h int64, r []types.Datum) erro
 	if err != nil {
 		return errors.Trace(err)
 	}
-	err = t.removeRowIndices(ctx, h, r)
+	rec, _ := t.fillChangingColumns(ctx, r, true)
+	err = t.removeRowIndices(ctx, h, rec)
 	if err != nil {
 		return errors.Trace(err)
 	}
 	if shouldWriteBinlog(ctx) {
 		colIDs := make([]int64, 0, len(t.Cols()))
+		row := make([]types.Datum, 0, len(t.Cols()))
 		for _, col := range t.Cols() {
 			colIDs = append(colIDs, col.ID)
+			row = append(row, tablecodec.EncodeCharset(r[col.Offset], t.meta.StorageType(col.ToInfo())))
 		}
-		err = t.addDeleteBinlog(ctx, r, colIDs)
+		err = t.addDeleteBinlog(ctx, row, colIDs)
 	}
 	return errors.Trace(err)
 }
@@ -639,8 +753,9 @@ func (t *Table) IterRecords(ctx context.Context, startKey kv.Key, cols []*table.
 
 	colMap := make(map[int64]*types.FieldType)
 	for _, col := range cols {
-		colMap[col.ID] = &col.FieldType
+		colMap[col.ID] = t.meta.StorageType(col.ToInfo())
 	}
+	hasVirtualCol := t.addVirtualColumnDeps(cols, colMap)
 	prefix := t.RecordPrefix()
 	defaultVals := make([]types.Datum, len(cols))
 	for it.Valid() && it.Key().HasPrefix(prefix) {
@@ -655,6 +770,11 @@ func (t *Table) IterRecords(ctx context.Context, startKey kv.Key, cols []*table.
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if hasVirtualCol {
+			if rowMap, err = t.fillVirtualColumns(ctx, handle, rowMap); err != nil {
+				return errors.Trace(err)
+			}
+		}
 		data := make([]types.Datum, len(cols))
 		for _, col := range cols {
 			if col.IsPKHandleColumn(t.meta) {
@@ -689,16 +809,90 @@ func (t *Table) IterRecords(ctx context.Context, startKey kv.Key, cols []*table.
 	return nil
 }
 
+// addVirtualColumnDeps adds all the writable columns to colTps if there are virtual generated columns in cols,
+// because their values are calculated from the other columns.
+func (t *Table) addVirtualColumnDeps(cols []*table.Column, colTps map[int64]*types.FieldType) bool {
+	hasVirtualCol := false
+	for _, col := range cols {
+		if col != nil && col.IsVirtualGenerated() {
+			hasVirtualCol = true
+			break
+		}
+	}
+	if !hasVirtualCol {
+		return false
+	}
+	for _, col := range t.WritableCols() {
+		if col != nil {
+			colTps[col.ID] = t.meta.StorageType(col.ToInfo())
+		}
+	}
+	return true
+}
+
+// fillVirtualColumns calculates the values of the virtual generated columns of the row decoded to rowMap,
+// and returns rowMap with them.
+func (t *Table) fillVirtualColumns(ctx context.Context, h int64, rowMap map[int64]types.Datum) (map[int64]types.Datum, error) {
+	row, err := RowWithVirtualColumns(ctx, t, h, rowMap)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if rowMap == nil {
+		// The record of the row whose columns are all null is decoded to a nil map.
+		rowMap = make(map[int64]types.Datum)
+	}
+	for _, col := range t.WritableCols() {
+		if col != nil && col.IsVirtualGenerated() {
+			rowMap[col.ID] = row[col.Offset]
+		}
+	}
+	return rowMap, nil
+}
+
+// RowWithVirtualColumns returns the row of the writable columns of t indexed by the column offset. The values
+// are from rowMap decoded from the record of handle h, the columns missing in it have their origin default
+// values, and the values of the virtual generated columns are calculated from the others.
+func RowWithVirtualColumns(ctx context.Context, t table.Table, h int64, rowMap map[int64]types.Datum) ([]types.Datum, error) {
+	cols := t.WritableCols()
+	row := make([]types.Datum, len(t.Meta().Columns))
+	for _, col := range cols {
+		if col == nil || col.IsVirtualGenerated() {
+			continue
+		}
+		if col.IsPKHandleColumn(t.Meta()) {
+			if mysql.HasUnsignedFlag(col.Flag) {
+				row[col.Offset].SetUint64(uint64(h))
+			} else {
+				row[col.Offset].SetInt64(h)
+			}
+			continue
+		}
+		val, ok := rowMap[col.ID]
+		if !ok {
+			var err error
+			val, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		row[col.Offset] = val
+	}
+	if err := table.FillVirtualColumnValues(cols, row); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return row, nil
+}
+
 // GetColDefaultValue gets a column default value.
 // The defaultVals is used to avoid calculating the default value multiple times.
 func GetColDefaultValue(ctx context.Context, col *table.Column, defaultVals []types.Datum) (
 	colVal types.Datum, err error) {
-	if col.OriginDefaultValue == nil && mysql.HasNotNullFlag(col.Flag) {
-		return colVal, errors.New("Miss column")
-	}
 	if col.State != model.StatePublic {
 		return colVal, nil
 	}
+	if col.OriginDefaultValue == nil && mysql.HasNotNullFlag(col.Flag) {
+		return colVal, errors.New("Miss column")
+	}
 	if defaultVals[col.Offset].IsNull() {
 		colVal, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
 		if err != nil {
@@ -56,6 +56,15 @@ func (vt *VirtualTable) IterRecords(ctx context.Context, startKey kv.Key, cols [
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if len(cols) != len(vt.dataSource.Cols()) {
+		for i, fullRow := range rows {
+			row := make([]types.Datum, len(cols))
+			for j, col := range cols {
+				row[j] = fullRow[col.Offset]
+			}
+			rows[i] = row
+		}
+	}
 	for i, row := range rows {
 		more, err := fn(int64(i), row, cols)
 		if err != nil {
@@ -20,8 +20,10 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -162,7 +164,8 @@ func EncodeValue(raw types.Datum, loc *time.Location) ([]byte, error) {
 	return b, errors.Trace(err)
 }
 
-// EncodeRow encode row data and column ids into a slice of byte.
+// EncodeRow encode row data and column ids into a slice of byte, the strings of the columns in
+// the transcoded charsets must be encoded by EncodeCharset.
 // Row layout: colID1, value1, colID2, value2, .....
 func EncodeRow(row []types.Datum, colIDs []int64, loc *time.Location) ([]byte, error) {
 	if len(row) != len(colIDs) {
@@ -235,7 +238,8 @@ func DecodeColumnValue(data []byte, ft *types.FieldType, loc *time.Location) (ty
 	return colDatum, nil
 }
 
-// DecodeRowWithMap decodes a byte slice into datums with a existing row map.
+// DecodeRowWithMap decodes a byte slice into datums with a existing row map, the strings of the
+// columns in the transcoded charsets are decoded to UTF-8.
 // Row layout: colID1, value1, colID2, value2, .....
 func DecodeRowWithMap(b []byte, cols map[int64]*types.FieldType, loc *time.Location, row map[int64]types.Datum) (map[int64]types.Datum, error) {
 	if row == nil {
@@ -278,7 +282,7 @@ func DecodeRowWithMap(b []byte, cols map[int64]*types.FieldType, loc *time.Locat
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
-			row[id] = v
+			row[id] = DecodeCharset(v, ft)
 			cnt++
 			if cnt == len(cols) {
 				// Get enough data.
@@ -443,6 +447,75 @@ func EncodeIndexSeekKey(tableID int64, idxID int64, encodedValue []byte) kv.Key
 	return key
 }
 
+// IndexStorageTypes returns the storage types of the index columns, the values in the index keys
+// are encoded as these types.
+func IndexStorageTypes(tblInfo *model.TableInfo, idxInfo *model.IndexInfo) []*types.FieldType {
+	fieldTypes := make([]*types.FieldType, len(idxInfo.Columns))
+	for i, ic := range idxInfo.Columns {
+		fieldTypes[i] = tblInfo.StorageType(tblInfo.Columns[ic.Offset])
+	}
+	return fieldTypes
+}
+
+// CollationKeys returns the values to be encoded in the index key, the string values of the columns
+// compared under non-binary collations are replaced with their sort keys, so the values equal under
+// the collations have the same index key. The vals isn't changed.
+func CollationKeys(vals []types.Datum, fieldTypes []*types.FieldType) []types.Datum {
+	var keys []types.Datum
+	for i := 0; i < len(vals) && i < len(fieldTypes); i++ {
+		v := &vals[i]
+		if (v.Kind() != types.KindString && v.Kind() != types.KindBytes) || !types.HasCollationKey(fieldTypes[i]) {
+			continue
+		}
+		if keys == nil {
+			keys = append([]types.Datum(nil), vals...)
+		}
+		keys[i].SetBytes(charset.GetCollator(fieldTypes[i].Collate).Key(v.GetString()))
+	}
+	if keys == nil {
+		return vals
+	}
+	return keys
+}
+
+// EncodeCharset returns the value to be stored, the string of a column in a transcoded charset is
+// encoded to the charset of the column. The strings are UTF-8 out of the storage.
+func EncodeCharset(d types.Datum, ft *types.FieldType) types.Datum {
+	if (d.Kind() != types.KindString && d.Kind() != types.KindBytes) || !types.IsTranscodedStr(ft) {
+		return d
+	}
+	// The value is checked by table.CastValue, the characters that can't be represented are replaced.
+	encoded, _ := charset.Encode(ft.Charset, d.GetString())
+	d.SetBytes([]byte(encoded))
+	return d
+}
+
+// DecodeCharset decodes the stored string of a column in a transcoded charset to UTF-8.
+func DecodeCharset(d types.Datum, ft *types.FieldType) types.Datum {
+	if (d.Kind() != types.KindString && d.Kind() != types.KindBytes) || !types.IsTranscodedStr(ft) {
+		return d
+	}
+	d.SetBytes([]byte(charset.Decode(ft.Charset, d.GetString())))
+	return d
+}
+
+// DecodeCharsetValue decodes the stored string in the encoded value of a column to UTF-8, and
+// returns it encoded again. The data is returned as it is if the column isn't in a transcoded charset.
+func DecodeCharsetValue(data []byte, ft *types.FieldType) ([]byte, error) {
+	if len(data) == 0 || !types.IsTranscodedStr(ft) {
+		return data, nil
+	}
+	_, d, err := codec.DecodeOne(data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if d.Kind() != types.KindString && d.Kind() != types.KindBytes {
+		return data, nil
+	}
+	b, err := codec.EncodeValue(nil, DecodeCharset(d, ft))
+	return b, errors.Trace(err)
+}
+
 // DecodeIndexKey decodes datums from an index key.
 func DecodeIndexKey(key kv.Key) ([]types.Datum, error) {
 	b := key[prefixLen+idLen:]
@@ -40,6 +40,7 @@ import (
 	"github.com/pingcap/tidb/store/tikv"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/printer"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/systimemon"
 	"github.com/pingcap/tidb/x-server"
 	"github.com/pingcap/tipb/go-binlog"
@@ -321,6 +322,8 @@ func setGlobalVars() {
 func setupLog() {
 	err := logutil.InitLogger(cfg.Log.ToLogConfig())
 	terror.MustNil(err)
+	err = slowlog.InitLogger(cfg.Log.SlowQueryFile, &cfg.Log.File)
+	terror.MustNil(err)
 }
 
 func printInfo() {
@@ -339,10 +342,13 @@ func createServer() {
 	terror.MustNil(err)
 	if cfg.XProtocol.XServer {
 		xcfg := &xserver.Config{
-			Addr:   fmt.Sprintf("%s:%d", cfg.XProtocol.XHost, cfg.XProtocol.XPort),
-			Socket: cfg.XProtocol.XSocket,
+			Addr:     fmt.Sprintf("%s:%d", cfg.XProtocol.XHost, cfg.XProtocol.XPort),
+			Socket:   cfg.XProtocol.XSocket,
+			SkipAuth: cfg.Security.SkipGrantTable,
+			SSLCert:  cfg.Security.SSLCert,
+			SSLKey:   cfg.Security.SSLKey,
 		}
-		xsvr, err = xserver.NewServer(xcfg)
+		xsvr, err = xserver.NewServer(xcfg, driver)
 		terror.MustNil(err)
 	}
 }
@@ -375,12 +381,14 @@ func setupMetrics() {
 }
 
 func runServer() {
-	err := svr.Run()
-	terror.MustNil(err)
 	if cfg.XProtocol.XServer {
-		err := xsvr.Run()
-		terror.MustNil(err)
+		go func() {
+			err := xsvr.Run()
+			terror.MustNil(err)
+		}()
 	}
+	err := svr.Run()
+	terror.MustNil(err)
 }
 
 func cleanup() {
@@ -31,6 +31,7 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/store/localstore/engine"
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
@@ -296,4 +297,7 @@ func init() {
 	terror.Log(errors.Trace(err))
 	err = RegisterLocalStore("goleveldb", goleveldb.Driver{})
 	terror.Log(errors.Trace(err))
+	// localstore can't import statistics directly, it would cause import cycles in tests.
+	localstore.BuildIndexResp = statistics.BuildIndexResp
+	localstore.BuildColumnsResp = statistics.BuildColumnsResp
 }
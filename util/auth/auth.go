@@ -35,6 +35,18 @@ func (user *UserIdentity) String() string {
 	return fmt.Sprintf("%s@%s", user.Username, user.Hostname)
 }
 
+// RoleIdentity represents a role name, a role is an account in mysql.user which can't be used to connect.
+type RoleIdentity struct {
+	Username string
+	Hostname string
+}
+
+// String converts RoleIdentity to the format user@host.
+func (role *RoleIdentity) String() string {
+	// TODO: Escape username and hostname.
+	return fmt.Sprintf("%s@%s", role.Username, role.Hostname)
+}
+
 // CheckScrambledPassword check scrambled password received from client.
 // The new authentication is performed in following manner:
 //   SERVER:  public_seed=create_random_string()
@@ -14,10 +14,17 @@
 package auth
 
 import (
+	"testing"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
 var _ = Suite(&testAuthSuite{})
 
 type testAuthSuite struct {
@@ -48,3 +55,47 @@ func (s *testAuthSuite) TestCheckScramble(c *C) {
 	res := CheckScrambledPassword(salt, hpwd, auth)
 	c.Assert(res, IsTrue)
 }
+
+func (s *testAuthSuite) TestSha256Crypt(c *C) {
+	defer testleak.AfterTest(c)()
+	// Test vectors from https://www.akkadia.org/drepper/SHA-crypt.txt
+	c.Assert(sha256Crypt([]byte("Hello world!"), []byte("saltstring"), 5000), Equals, "5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5")
+	c.Assert(sha256Crypt([]byte("Hello world!"), []byte("saltstringsaltst"), 10000), Equals, "3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA")
+}
+
+func (s *testAuthSuite) TestSha2Password(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(NewSha2Password(""), Equals, "")
+	ok, err := CheckSha2Password("", "")
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+
+	hash := NewSha2Password("123")
+	c.Assert(hash, HasLen, 70)
+	c.Assert(hash[:7], Equals, "$A$005$")
+	c.Assert(NewSha2Password("123"), Not(Equals), hash)
+	ok, err = CheckSha2Password(hash, "123")
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	ok, err = CheckSha2Password(hash, "1234")
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	_, err = CheckSha2Password("*23AE809DDACAF96AF0FD78ED04B6A265E05AA257", "123")
+	c.Assert(err, NotNil)
+}
+
+func (s *testAuthSuite) TestCheckSha2Scramble(c *C) {
+	defer testleak.AfterTest(c)()
+	pwd := []byte("123")
+	nonce := []byte("01234567890123456789")
+	// The scramble computed by clients.
+	stage1 := Sha256Hash(pwd)
+	scramble := Sha256Hash(append(Sha256Hash(stage1), nonce...))
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	digest := Sha2ScrambleDigest(pwd)
+	c.Assert(CheckSha2Scramble(scramble, nonce, digest), IsTrue)
+	c.Assert(CheckSha2Scramble(scramble, []byte("98765432109876543210"), digest), IsFalse)
+	c.Assert(CheckSha2Scramble(scramble[:10], nonce, digest), IsFalse)
+}
@@ -0,0 +1,210 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/terror"
+)
+
+// The password hash of caching_sha2_password and sha256_password is stored in the same format as MySQL,
+// "$A$<iterations / 1000 in 3 digits>$<20 bytes salt><43 bytes SHA256-crypt digest>".
+// The digest is computed by the SHA256-crypt algorithm described in https://www.akkadia.org/drepper/SHA-crypt.txt.
+const (
+	sha2SaltLength          = 20
+	sha2DigestLength        = 43
+	sha2IterationMultiplier = 1000
+	sha2DefaultIterations   = 5
+	sha2HashPrefix          = "$A$"
+	sha2HashHeaderLength    = len(sha2HashPrefix) + 4 // "$A$005$"
+)
+
+const b64Alphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// NewSha2Password encodes the plaintext password with a random salt in the format stored by
+// caching_sha2_password and sha256_password. The empty password is stored as "".
+func NewSha2Password(pwd string) string {
+	if len(pwd) == 0 {
+		return ""
+	}
+	salt := make([]byte, sha2SaltLength)
+	_, err := rand.Read(salt)
+	terror.Log(errors.Trace(err))
+	// The salt is made up of the characters of the digest encoding, so that the hash can be
+	// put in SQL string literals without escaping.
+	for i := range salt {
+		salt[i] = b64Alphabet[salt[i]&0x3f]
+	}
+	iterations := sha2DefaultIterations * sha2IterationMultiplier
+	digest := sha256Crypt([]byte(pwd), salt, iterations)
+	return fmt.Sprintf("%s%03d$%s%s", sha2HashPrefix, sha2DefaultIterations, salt, digest)
+}
+
+// CheckSha2Password checks the plaintext password against the password hash created by NewSha2Password.
+func CheckSha2Password(hash string, pwd string) (bool, error) {
+	if len(hash) == 0 {
+		return len(pwd) == 0, nil
+	}
+	if len(hash) != sha2HashHeaderLength+sha2SaltLength+sha2DigestLength ||
+		hash[:len(sha2HashPrefix)] != sha2HashPrefix || hash[sha2HashHeaderLength-1] != '$' {
+		return false, errors.Errorf("invalid sha2 password hash %q", hash)
+	}
+	iterations, err := strconv.Atoi(hash[len(sha2HashPrefix) : sha2HashHeaderLength-1])
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	salt := hash[sha2HashHeaderLength : sha2HashHeaderLength+sha2SaltLength]
+	digest := sha256Crypt([]byte(pwd), []byte(salt), iterations*sha2IterationMultiplier)
+	return digest == hash[sha2HashHeaderLength+sha2SaltLength:], nil
+}
+
+// Sha256Hash is an util function to calculate sha256 hash.
+func Sha256Hash(bs []byte) []byte {
+	h := sha256.Sum256(bs)
+	return h[:]
+}
+
+// Sha2ScrambleDigest returns SHA256(SHA256(password)), it's what the server keeps to verify the scramble
+// sent by caching_sha2_password clients in the fast authentication.
+func Sha2ScrambleDigest(pwd []byte) []byte {
+	return Sha256Hash(Sha256Hash(pwd))
+}
+
+// CheckSha2Scramble checks the scramble sent by caching_sha2_password clients in the fast authentication.
+// The client sends XOR(SHA256(password), SHA256(SHA256(SHA256(password)), nonce)), digest is
+// SHA256(SHA256(password)) returned by Sha2ScrambleDigest.
+func CheckSha2Scramble(scramble, nonce, digest []byte) bool {
+	if len(scramble) != sha256.Size || len(digest) != sha256.Size {
+		return false
+	}
+	h := sha256.New()
+	_, err := h.Write(digest)
+	terror.Log(errors.Trace(err))
+	_, err = h.Write(nonce)
+	terror.Log(errors.Trace(err))
+	stage1 := h.Sum(nil)
+	for i := range stage1 {
+		stage1[i] ^= scramble[i]
+	}
+	return bytes.Equal(Sha256Hash(stage1), digest)
+}
+
+// sha256Crypt computes the SHA256-crypt digest of the key with the salt, the result is encoded in 43 characters.
+func sha256Crypt(key, salt []byte, rounds int) string {
+	// Digest B.
+	b := sha256.New()
+	writeAll(b, key, salt, key)
+	digestB := b.Sum(nil)
+
+	// Digest A.
+	a := sha256.New()
+	writeAll(a, key, salt)
+	for n := len(key); n > 0; n -= sha256.Size {
+		if n > sha256.Size {
+			writeAll(a, digestB)
+		} else {
+			writeAll(a, digestB[:n])
+		}
+	}
+	for n := len(key); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			writeAll(a, digestB)
+		} else {
+			writeAll(a, key)
+		}
+	}
+	digestA := a.Sum(nil)
+
+	// Digest DP and the P sequence.
+	dp := sha256.New()
+	for i := 0; i < len(key); i++ {
+		writeAll(dp, key)
+	}
+	p := repeatBytes(dp.Sum(nil), len(key))
+
+	// Digest DS and the S sequence.
+	ds := sha256.New()
+	for i := 0; i < 16+int(digestA[0]); i++ {
+		writeAll(ds, salt)
+	}
+	s := repeatBytes(ds.Sum(nil), len(salt))
+
+	c := digestA
+	for i := 0; i < rounds; i++ {
+		h := sha256.New()
+		if i&1 != 0 {
+			writeAll(h, p)
+		} else {
+			writeAll(h, c)
+		}
+		if i%3 != 0 {
+			writeAll(h, s)
+		}
+		if i%7 != 0 {
+			writeAll(h, p)
+		}
+		if i&1 != 0 {
+			writeAll(h, c)
+		} else {
+			writeAll(h, p)
+		}
+		c = h.Sum(nil)
+	}
+
+	buf := make([]byte, 0, sha2DigestLength)
+	order := [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+	}
+	for _, o := range order {
+		buf = appendB64(buf, uint(c[o[0]])<<16|uint(c[o[1]])<<8|uint(c[o[2]]), 4)
+	}
+	buf = appendB64(buf, uint(c[31])<<8|uint(c[30]), 3)
+	return string(buf)
+}
+
+func writeAll(h hash.Hash, bs ...[]byte) {
+	for _, b := range bs {
+		_, err := h.Write(b)
+		terror.Log(errors.Trace(err))
+	}
+}
+
+// repeatBytes repeats b to the length n.
+func repeatBytes(b []byte, n int) []byte {
+	r := make([]byte, 0, n)
+	for len(r) < n {
+		if n-len(r) >= len(b) {
+			r = append(r, b...)
+		} else {
+			r = append(r, b[:n-len(r)]...)
+		}
+	}
+	return r
+}
+
+func appendB64(buf []byte, w uint, n int) []byte {
+	for i := 0; i < n; i++ {
+		buf = append(buf, b64Alphabet[w&0x3f])
+		w >>= 6
+	}
+	return buf
+}
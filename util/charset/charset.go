@@ -41,6 +41,9 @@ type Collation struct {
 
 var charsets = make(map[string]*Charset)
 
+// supportedCollations are the implemented collations of the supported charsets.
+var supportedCollations []*Collation
+
 // All the supported charsets should be in the following table.
 var charsetInfos = []*Charset{
 	{CharsetUTF8, CollationUTF8, make(map[string]*Collation), "UTF-8 Unicode", 3},
@@ -48,6 +51,8 @@ var charsetInfos = []*Charset{
 	{CharsetASCII, CollationASCII, make(map[string]*Collation), "US ASCII", 1},
 	{CharsetLatin1, CollationLatin1, make(map[string]*Collation), "Latin1", 1},
 	{CharsetBin, CollationBin, make(map[string]*Collation), "binary", 1},
+	{CharsetGBK, CollationGBK, make(map[string]*Collation), "GBK Simplified Chinese", 2},
+	{CharsetGB18030, CollationGB18030, make(map[string]*Collation), "China National Standard GB18030", 4},
 }
 
 func init() {
@@ -60,6 +65,18 @@ func init() {
 			continue
 		}
 		charset.Collations[c.Name] = c
+		if collator, ok := collators[c.Name]; ok {
+			collatorsByID[c.ID] = collator
+			supportedCollations = append(supportedCollations, &Collation{
+				ID:          c.ID,
+				CharsetName: c.CharsetName,
+				Name:        c.Name,
+				IsDefault:   c.Name == charset.DefaultCollation,
+			})
+		} else if collator, ok := transcodedBinCollators[c.CharsetName]; ok {
+			collators[c.Name] = collator
+			collatorsByID[c.ID] = collator
+		}
 	}
 }
 
@@ -165,9 +182,21 @@ func GetCharsetInfoByID(coID int) (string, string, error) {
 	return "", "", errors.Errorf("Unknown charset id %d", coID)
 }
 
-// GetCollations returns a list for all collations.
+// GetCollationByName returns the collation of the name, it's used to get the charset of a collation.
+func GetCollationByName(name string) (*Collation, error) {
+	name = strings.ToLower(name)
+	for _, collation := range collations {
+		if collation.Name == name {
+			return collation, nil
+		}
+	}
+	return nil, errors.Errorf("Unknown collation %s", name)
+}
+
+// GetCollations returns a list for the implemented collations of the supported charsets,
+// the collations are marked as default if they are the default collations in TiDB.
 func GetCollations() []*Collation {
-	return collations
+	return supportedCollations
 }
 
 const (
@@ -191,6 +220,14 @@ const (
 	CharsetLatin1 = "latin1"
 	// CollationLatin1 is the default collation for CharsetLatin1.
 	CollationLatin1 = "latin1_bin"
+	// CharsetGBK is a double byte charset for simplified Chinese.
+	CharsetGBK = "gbk"
+	// CollationGBK is the default collation for CharsetGBK.
+	CollationGBK = "gbk_chinese_ci"
+	// CharsetGB18030 is a multiple byte charset for Chinese, it covers all the Unicode characters.
+	CharsetGB18030 = "gb18030"
+	// CollationGB18030 is the default collation for CharsetGB18030.
+	CollationGB18030 = "gb18030_chinese_ci"
 )
 
 var collations = []*Collation{
@@ -413,4 +450,7 @@ var collations = []*Collation{
 	{245, "utf8mb4", "utf8mb4_croatian_ci", false},
 	{246, "utf8mb4", "utf8mb4_unicode_520_ci", false},
 	{247, "utf8mb4", "utf8mb4_vietnamese_ci", false},
+	{248, "gb18030", "gb18030_chinese_ci", true},
+	{249, "gb18030", "gb18030_bin", false},
+	{250, "gb18030", "gb18030_unicode_520_ci", false},
 }
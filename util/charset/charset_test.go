@@ -49,6 +49,8 @@ func (s *testCharsetSuite) TestValidCharset(c *C) {
 		{"utf8", "utf8_invalid_ci", false},
 		{"utf16", "utf16_bin", false},
 		{"gb2312", "gb2312_chinese_ci", false},
+		{"gbk", "gbk_chinese_ci", true},
+		{"gb18030", "gb18030_bin", true},
 	}
 	for _, tt := range tests {
 		testValidCharset(c, tt.cs, tt.co, tt.succ)
@@ -0,0 +1,350 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charset
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Collator compares the strings under a collation.
+type Collator interface {
+	// Compare returns an integer comparing the two strings under the collation.
+	Compare(a, b string) int
+	// Key returns the sort key of the string, the byte order of the sort keys is
+	// the same as the order of the strings under the collation.
+	Key(str string) []byte
+}
+
+const (
+	// CollationUTF8GeneralCI is the case insensitive collation for CharsetUTF8.
+	CollationUTF8GeneralCI = "utf8_general_ci"
+	// CollationUTF8MB4GeneralCI is the case insensitive collation for CharsetUTF8MB4.
+	CollationUTF8MB4GeneralCI = "utf8mb4_general_ci"
+	// CollationUTF8UnicodeCI is the Unicode collation for CharsetUTF8.
+	CollationUTF8UnicodeCI = "utf8_unicode_ci"
+	// CollationUTF8MB4UnicodeCI is the Unicode collation for CharsetUTF8MB4.
+	CollationUTF8MB4UnicodeCI = "utf8mb4_unicode_ci"
+	// CollationGBKBin is the binary collation for CharsetGBK.
+	CollationGBKBin = "gbk_bin"
+	// CollationGB18030Bin is the binary collation for CharsetGB18030.
+	CollationGB18030Bin = "gb18030_bin"
+)
+
+var (
+	binCollatorInstance        = &binCollator{}
+	generalCICollatorInstance  = &weightCollator{weight: generalCIWeight}
+	unicodeCICollatorInstance  = &weightCollator{weight: unicodeCIWeight}
+	latin1BinCollatorInstance  = &encodingCollator{charset: CharsetLatin1}
+	gbkBinCollatorInstance     = &encodingCollator{charset: CharsetGBK}
+	gb18030BinCollatorInstance = &encodingCollator{charset: CharsetGB18030}
+)
+
+// collators are the implemented collations, the other collations are accepted
+// but compare the strings as binary.
+var collators = map[string]Collator{
+	CollationBin:              binCollatorInstance,
+	CollationUTF8:             binCollatorInstance,
+	CollationUTF8MB4:          binCollatorInstance,
+	CollationASCII:            binCollatorInstance,
+	CollationLatin1:           latin1BinCollatorInstance,
+	CollationUTF8GeneralCI:    generalCICollatorInstance,
+	CollationUTF8MB4GeneralCI: generalCICollatorInstance,
+	CollationUTF8UnicodeCI:    unicodeCICollatorInstance,
+	CollationUTF8MB4UnicodeCI: unicodeCICollatorInstance,
+	CollationGBK:              &encodingCollator{charset: CharsetGBK, ci: true},
+	CollationGBKBin:           gbkBinCollatorInstance,
+	CollationGB18030:          &encodingCollator{charset: CharsetGB18030, ci: true},
+	CollationGB18030Bin:       gb18030BinCollatorInstance,
+}
+
+// transcodedBinCollators compare the strings of the transcoded charsets as binary, that is by their
+// bytes in the charsets like they're stored. The collations of the charsets which aren't implemented
+// use them.
+var transcodedBinCollators = map[string]Collator{
+	CharsetLatin1:  latin1BinCollatorInstance,
+	CharsetGBK:     gbkBinCollatorInstance,
+	CharsetGB18030: gb18030BinCollatorInstance,
+}
+
+var collatorsByID = make(map[int]Collator)
+
+// GetCollator returns the collator of the collation, the binary collator is returned
+// if the collation is not implemented.
+func GetCollator(collation string) Collator {
+	if c, ok := collators[strings.ToLower(collation)]; ok {
+		return c
+	}
+	return binCollatorInstance
+}
+
+// GetCollatorByID returns the collator of the collation ID, the binary collator is
+// returned if the collation is not implemented.
+func GetCollatorByID(coID int) Collator {
+	if c, ok := collatorsByID[coID]; ok {
+		return c
+	}
+	return binCollatorInstance
+}
+
+// IsBinCollation returns if the strings are compared as binary under the collation.
+func IsBinCollation(collation string) bool {
+	return GetCollator(collation) == binCollatorInstance
+}
+
+type binCollator struct{}
+
+// Compare implements Collator interface.
+func (c *binCollator) Compare(a, b string) int {
+	return strings.Compare(a, b)
+}
+
+// Key implements Collator interface.
+func (c *binCollator) Key(str string) []byte {
+	return []byte(str)
+}
+
+// encodingCollator compares the strings by their bytes encoded in the charset, so the frequently
+// used Chinese characters are sorted by their pinyin. The trailing spaces are ignored, and the ASCII
+// letters are compared case insensitively if ci is true.
+type encodingCollator struct {
+	charset string
+	ci      bool
+}
+
+// Compare implements Collator interface.
+func (c *encodingCollator) Compare(a, b string) int {
+	return bytes.Compare(c.Key(a), c.Key(b))
+}
+
+// Key implements Collator interface.
+func (c *encodingCollator) Key(str string) []byte {
+	str = strings.TrimRight(str, " ")
+	if c.ci {
+		str = strings.Map(upperASCII, str)
+	}
+	encoded, _ := Encode(c.charset, str)
+	return []byte(encoded)
+}
+
+func upperASCII(r rune) rune {
+	if r >= 'a' && r <= 'z' {
+		return r - 'a' + 'A'
+	}
+	return r
+}
+
+// weightCollator compares the strings by the weights of their characters, the weights are
+// compared as uint16 and the trailing spaces are ignored like the PAD SPACE collations of MySQL.
+type weightCollator struct {
+	// weight returns the weight of the rune, or the weights if the rune is expanded to
+	// multiple weights. The rune is ignored if the weight is 0 and the expansion is nil.
+	weight func(r rune) (uint16, []uint16)
+}
+
+// Compare implements Collator interface.
+func (c *weightCollator) Compare(a, b string) int {
+	ia := weightIter{str: strings.TrimRight(a, " "), weight: c.weight}
+	ib := weightIter{str: strings.TrimRight(b, " "), weight: c.weight}
+	for {
+		wa, oka := ia.next()
+		wb, okb := ib.next()
+		switch {
+		case !oka && !okb:
+			return 0
+		case !oka:
+			return -1
+		case !okb:
+			return 1
+		case wa < wb:
+			return -1
+		case wa > wb:
+			return 1
+		}
+	}
+}
+
+// Key implements Collator interface.
+func (c *weightCollator) Key(str string) []byte {
+	str = strings.TrimRight(str, " ")
+	key := make([]byte, 0, len(str)*2)
+	it := weightIter{str: str, weight: c.weight}
+	for {
+		w, ok := it.next()
+		if !ok {
+			return key
+		}
+		key = append(key, byte(w>>8), byte(w))
+	}
+}
+
+type weightIter struct {
+	str       string
+	expansion []uint16
+	weight    func(r rune) (uint16, []uint16)
+}
+
+func (it *weightIter) next() (uint16, bool) {
+	for {
+		if len(it.expansion) > 0 {
+			w := it.expansion[0]
+			it.expansion = it.expansion[1:]
+			return w, true
+		}
+		if len(it.str) == 0 {
+			return 0, false
+		}
+		r, size := utf8.DecodeRuneInString(it.str)
+		it.str = it.str[size:]
+		w, expansion := it.weight(r)
+		if expansion != nil {
+			it.expansion = expansion
+			continue
+		}
+		if w != 0 {
+			return w, true
+		}
+	}
+}
+
+// The weights of the characters outside of the BMP, they are treated as the replacement character.
+const supplementaryWeight = 0xFFFD
+
+// nulWeights is the expansion of the NUL character, its weight is 0 but it isn't ignorable.
+var nulWeights = []uint16{0}
+
+var generalCI struct {
+	once    sync.Once
+	weights []uint16
+}
+
+// generalCIWeight returns the weight of the rune under utf8_general_ci, the weight is the
+// upper case of the rune without accents, so "a", "A" and "á" are equal.
+func generalCIWeight(r rune) (uint16, []uint16) {
+	if r > 0xFFFF {
+		return supplementaryWeight, nil
+	}
+	generalCI.once.Do(func() {
+		generalCI.weights = make([]uint16, 0x10000)
+		for i := range generalCI.weights {
+			w := baseRune(unicode.ToUpper(rune(i)))
+			if w > 0xFFFF {
+				w = rune(i)
+			}
+			generalCI.weights[i] = uint16(w)
+		}
+		// The special cases of MySQL.
+		generalCI.weights['ß'] = 'S'
+		generalCI.weights['Ø'], generalCI.weights['ø'] = 'O', 'O'
+	})
+	if r == 0 {
+		return 0, nulWeights
+	}
+	return generalCI.weights[r], nil
+}
+
+// baseRune returns the rune without the accents if it's a letter decomposed to a base
+// letter and combining marks, otherwise returns the rune itself.
+func baseRune(r rune) rune {
+	decomposed := norm.NFD.String(string(r))
+	base, size := utf8.DecodeRuneInString(decomposed)
+	if size == len(decomposed) || !unicode.IsLetter(base) || base > 0xFFFF {
+		return r
+	}
+	for _, m := range decomposed[size:] {
+		if !unicode.Is(unicode.Mn, m) {
+			return r
+		}
+	}
+	return base
+}
+
+var unicodeCI struct {
+	once       sync.Once
+	weights    []uint16
+	expansions map[rune][]uint16
+}
+
+// unicodeCIWeight returns the weights of the rune under utf8_unicode_ci. It compares the
+// characters at the primary level like the Unicode Collation Algorithm: the case and the
+// accents are ignored, the compatibility characters are equal to their decompositions
+// (so "ﬁ" equals "fi"), and "ß", "æ" and "œ" are expanded to "ss", "ae" and "oe". The other
+// characters are sorted by the code points of their base characters.
+func unicodeCIWeight(r rune) (uint16, []uint16) {
+	if r > 0xFFFF {
+		return supplementaryWeight, nil
+	}
+	unicodeCI.once.Do(initUnicodeCI)
+	if unicodeCI.weights[r] == 0 {
+		// It's ignorable if there is no expansion.
+		return 0, unicodeCI.expansions[r]
+	}
+	return unicodeCI.weights[r], nil
+}
+
+func initUnicodeCI() {
+	special := map[rune]string{
+		'ß': "SS", 'ẞ': "SS",
+		'Æ': "AE", 'æ': "AE",
+		'Œ': "OE", 'œ': "OE",
+		'Ø': "O", 'ø': "O",
+		'Đ': "D", 'đ': "D",
+		'Ł': "L", 'ł': "L",
+	}
+	unicodeCI.weights = make([]uint16, 0x10000)
+	unicodeCI.expansions = make(map[rune][]uint16)
+	for i := 0; i <= 0xFFFF; i++ {
+		r := rune(i)
+		var decomposed string
+		switch {
+		case r >= 0xD800 && r <= 0xDFFF:
+			// The surrogates are invalid in UTF-8, they're decoded as the replacement character.
+			decomposed = string(utf8.RuneError)
+		case special[r] != "":
+			decomposed = special[r]
+		default:
+			decomposed = norm.NFKD.String(string(r))
+		}
+		var weights []uint16
+		for _, d := range decomposed {
+			if unicode.In(d, unicode.Mn, unicode.Me, unicode.Cf) {
+				continue
+			}
+			if s, ok := special[d]; ok {
+				for _, sr := range s {
+					weights = append(weights, uint16(sr))
+				}
+				continue
+			}
+			if d > 0xFFFF {
+				weights = append(weights, supplementaryWeight)
+				continue
+			}
+			weights = append(weights, uint16(unicode.ToUpper(d)))
+		}
+		if r == 0 {
+			weights = nulWeights
+		}
+		if len(weights) == 1 && weights[0] != 0 {
+			unicodeCI.weights[r] = weights[0]
+		} else if len(weights) > 0 {
+			unicodeCI.expansions[r] = weights
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charset
+
+import (
+	"bytes"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testCollatorSuite{})
+
+type testCollatorSuite struct {
+}
+
+func (s *testCollatorSuite) TestCompare(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		collation string
+		a         string
+		b         string
+		expect    int
+	}{
+		{"utf8_bin", "a", "A", 1},
+		{"utf8_bin", "a", "a ", -1},
+		{"latin1_swedish_ci", "a", "A", 1},
+		{"utf8mb4_general_ci", "a", "A", 0},
+		{"utf8mb4_general_ci", "abc", "ABC  ", 0},
+		{"utf8mb4_general_ci", "á", "A", 0},
+		{"utf8mb4_general_ci", "ab", "abc", -1},
+		{"utf8mb4_general_ci", "b", "A", 1},
+		{"utf8mb4_general_ci", "ß", "s", 0},
+		{"utf8mb4_general_ci", "ß", "ss", -1},
+		{"utf8mb4_general_ci", "a\x00", "a", 1},
+		{"UTF8_GENERAL_CI", "a", "A", 0},
+		{"utf8mb4_unicode_ci", "ß", "ss", 0},
+		{"utf8mb4_unicode_ci", "Straße", "STRASSE", 0},
+		{"utf8mb4_unicode_ci", "œuvre", "OEUVRE", 0},
+		{"utf8mb4_unicode_ci", "ﬁ", "fi", 0},
+		{"utf8mb4_unicode_ci", "é", "e", 0},
+		{"utf8mb4_unicode_ci", "ae", "æ", 0},
+		{"utf8mb4_unicode_ci", "ad", "æ", -1},
+		{"utf8mb4_unicode_ci", "😀", "😃", 0},
+		{"gbk_bin", "啊", "吧", -1},
+		{"gbk_bin", "a", "A", 1},
+		{"gbk_bin", "a", "a ", 0},
+		{"gbk_chinese_ci", "abc", "ABC", 0},
+		{"gbk_chinese_ci", "中", "啊", 1},
+		{"gb18030_chinese_ci", "中", "啊", 1},
+		{"gb18030_bin", "😀", "😃", -1},
+	}
+	for _, tt := range tests {
+		collator := GetCollator(tt.collation)
+		comment := Commentf("%s: %q vs %q", tt.collation, tt.a, tt.b)
+		c.Assert(collator.Compare(tt.a, tt.b), Equals, tt.expect, comment)
+		c.Assert(collator.Compare(tt.b, tt.a), Equals, -tt.expect, comment)
+		c.Assert(bytes.Compare(collator.Key(tt.a), collator.Key(tt.b)), Equals, tt.expect, comment)
+	}
+}
+
+func (s *testCollatorSuite) TestGetCollator(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(IsBinCollation("utf8_bin"), IsTrue)
+	// The strings of latin1 are compared by their bytes in latin1 under the collations not implemented.
+	c.Assert(IsBinCollation("latin1_swedish_ci"), IsFalse)
+	c.Assert(GetCollator("latin1_swedish_ci"), Equals, GetCollator("latin1_bin"))
+	c.Assert(GetCollator("latin1_bin").Compare("\u20ac", "\u00e9"), Equals, -1)
+	c.Assert(IsBinCollation(""), IsTrue)
+	c.Assert(IsBinCollation("utf8mb4_general_ci"), IsFalse)
+	c.Assert(IsBinCollation("utf8_unicode_ci"), IsFalse)
+	c.Assert(GetCollatorByID(45), Equals, GetCollator("utf8mb4_general_ci"))
+	c.Assert(GetCollatorByID(224), Equals, GetCollator("utf8mb4_unicode_ci"))
+	c.Assert(GetCollatorByID(83), Equals, GetCollator("utf8_bin"))
+	c.Assert(GetCollatorByID(8), Equals, GetCollator("latin1_bin"))
+	c.Assert(GetCollatorByID(11), Equals, GetCollator("binary"))
+
+	for _, co := range GetCollations() {
+		_, ok := collators[co.Name]
+		c.Assert(ok, IsTrue, Commentf("%s", co.Name))
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charset
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/juju/errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// The strings are UTF-8 in TiDB, the strings of the following charsets are transcoded when
+// they're stored, sent to or received from the clients.
+var transcodedCharsets = map[string]encoding.Encoding{
+	CharsetLatin1:  latin1,
+	CharsetGBK:     gbk,
+	CharsetGB18030: simplifiedchinese.GB18030,
+}
+
+// IsTranscoded returns if the strings of the charset are transcoded from UTF-8.
+func IsTranscoded(charset string) bool {
+	_, ok := transcodedCharsets[strings.ToLower(charset)]
+	return ok
+}
+
+// Encode encodes the UTF-8 string to the charset. The characters which can't be represented
+// in the charset are replaced by '?', and ok is false if there is any. The string is returned
+// as it is if the charset isn't transcoded.
+func Encode(charset string, str string) (encoded string, ok bool) {
+	enc, transcoded := transcodedCharsets[strings.ToLower(charset)]
+	if !transcoded {
+		return str, true
+	}
+	encoded, _, err := transform.String(enc.NewEncoder(), str)
+	if err == nil {
+		return encoded, true
+	}
+	// Encode the characters one by one to replace the unsupported ones.
+	ok = true
+	encoder := enc.NewEncoder()
+	buf := make([]byte, 0, len(str))
+	for len(str) > 0 {
+		r, size := utf8.DecodeRuneInString(str)
+		c, _, err := transform.String(encoder, str[:size])
+		if r == utf8.RuneError && size == 1 || err != nil {
+			c, ok = "?", false
+		}
+		buf = append(buf, c...)
+		str = str[size:]
+	}
+	return string(buf), ok
+}
+
+// Decode decodes the string in the charset to UTF-8, the invalid bytes are decoded to
+// utf8.RuneError. The string is returned as it is if the charset isn't transcoded.
+func Decode(charset string, str string) string {
+	enc, transcoded := transcodedCharsets[strings.ToLower(charset)]
+	if !transcoded {
+		return str
+	}
+	decoded, _, err := transform.String(enc.NewDecoder(), str)
+	if err != nil {
+		return str
+	}
+	return decoded
+}
+
+// latin1 is the latin1 charset of MySQL, it's the same as Windows-1252 except that the 5 bytes
+// undefined in Windows-1252 are mapped to the C1 control characters, so any byte sequence can be
+// decoded and encoded back.
+var latin1 encoding.Encoding = latin1Encoding{}
+
+var (
+	errUnsupportedRune    = errors.New("encoding: rune not supported by latin1")
+	errUnsupportedGBKRune = errors.New("encoding: rune not supported by gbk")
+)
+
+type latin1Encoding struct{}
+
+// NewDecoder implements encoding.Encoding interface.
+func (latin1Encoding) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: latin1Decoder{}}
+}
+
+// NewEncoder implements encoding.Encoding interface.
+func (latin1Encoding) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: latin1Encoder{}}
+}
+
+// latin1Undefined returns if the byte is undefined in Windows-1252.
+func latin1Undefined(b byte) bool {
+	return b == 0x81 || b == 0x8D || b == 0x8F || b == 0x90 || b == 0x9D
+}
+
+type latin1Decoder struct{ transform.NopResetter }
+
+// Transform implements transform.Transformer interface.
+func (latin1Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	var buf [utf8.UTFMax]byte
+	for nSrc < len(src) {
+		b := src[nSrc]
+		var r rune
+		switch {
+		case b < 0x80 || latin1Undefined(b):
+			r = rune(b)
+		default:
+			r = charmap.Windows1252.DecodeByte(b)
+		}
+		size := utf8.EncodeRune(buf[:], r)
+		if nDst+size > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], buf[:size])
+		nSrc++
+	}
+	return nDst, nSrc, nil
+}
+
+type latin1Encoder struct{ transform.NopResetter }
+
+// Transform implements transform.Transformer interface.
+func (latin1Encoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if nDst >= len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		r, size := rune(src[nSrc]), 1
+		if r >= utf8.RuneSelf {
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			r, size = utf8.DecodeRune(src[nSrc:])
+		}
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if r <= 0xFF && latin1Undefined(byte(r)) {
+			b, ok = byte(r), true
+		}
+		if !ok || r == utf8.RuneError && size == 1 {
+			return nDst, nSrc, errUnsupportedRune
+		}
+		dst[nDst] = b
+		nDst++
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+// gbk is the gbk charset of MySQL, it's the same as GBK except that the euro sign, which is
+// encoded as 0x80 in Windows-936, can't be represented.
+var gbk encoding.Encoding = gbkEncoding{simplifiedchinese.GBK}
+
+var euroSign = []byte("\u20ac")
+
+type gbkEncoding struct {
+	encoding.Encoding
+}
+
+// NewEncoder implements encoding.Encoding interface.
+func (e gbkEncoding) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: gbkEncoder{e.Encoding.NewEncoder()}}
+}
+
+type gbkEncoder struct {
+	transform.Transformer
+}
+
+// Transform implements transform.Transformer interface.
+func (e gbkEncoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	i := bytes.Index(src, euroSign)
+	if i < 0 {
+		return e.Transformer.Transform(dst, src, atEOF)
+	}
+	nDst, nSrc, err = e.Transformer.Transform(dst, src[:i], true)
+	if err == nil {
+		err = errUnsupportedGBKRune
+	}
+	return nDst, nSrc, err
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charset
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testEncodingSuite{})
+
+type testEncodingSuite struct {
+}
+
+func (s *testEncodingSuite) TestEncode(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		charset string
+		str     string
+		encoded string
+		ok      bool
+	}{
+		{"utf8", "中文", "中文", true},
+		{"binary", "\xff", "\xff", true},
+		{"gbk", "a中文", "a\xd6\xd0\xce\xc4", true},
+		{"GBK", "a😀b", "a?b", false},
+		{"gbk", "a\xffb", "a?b", false},
+		{"gbk", "中€文", "\xd6\xd0?\xce\xc4", false},
+		{"gb18030", "😀", "\x949\xfc6", true},
+		{"latin1", "café€", "caf\xe9\x80", true},
+		{"latin1", "\u0081", "\x81", true},
+		{"latin1", "中", "?", false},
+	}
+	for _, tt := range tests {
+		comment := Commentf("%s %q", tt.charset, tt.str)
+		encoded, ok := Encode(tt.charset, tt.str)
+		c.Assert(encoded, Equals, tt.encoded, comment)
+		c.Assert(ok, Equals, tt.ok, comment)
+		if tt.ok {
+			c.Assert(Decode(tt.charset, encoded), Equals, tt.str, comment)
+		}
+	}
+
+	// Any byte sequence can be decoded from latin1 and encoded back.
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	decoded := Decode("latin1", string(all))
+	encoded, ok := Encode("latin1", decoded)
+	c.Assert(ok, IsTrue)
+	c.Assert(encoded, Equals, string(all))
+
+	c.Assert(IsTranscoded("gbk"), IsTrue)
+	c.Assert(IsTranscoded("utf8mb4"), IsFalse)
+	c.Assert(IsTranscoded(""), IsFalse)
+}
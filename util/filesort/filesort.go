@@ -415,7 +415,7 @@ func (fs *FileSorter) openAllFiles() error {
 
 // fetchNextRow fetches the next row given the source file index.
 func (fs *FileSorter) fetchNextRow(index int) (*comparableRow, error) {
-	n, err := fs.fds[index].Read(fs.head)
+	n, err := io.ReadFull(fs.fds[index], fs.head)
 	if err == io.EOF {
 		return nil, nil
 	}
@@ -426,8 +426,12 @@ func (fs *FileSorter) fetchNextRow(index int) (*comparableRow, error) {
 		return nil, errors.New("incorrect header")
 	}
 	rowSize := int(binary.BigEndian.Uint64(fs.head))
+	if rowSize > len(fs.rowBytes) {
+		return nil, errors.New("incorrect row size")
+	}
 
-	n, err = fs.fds[index].Read(fs.rowBytes)
+	// Rows may have different sizes, so only read the bytes of the current row.
+	n, err = io.ReadFull(fs.fds[index], fs.rowBytes[:rowSize])
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -435,7 +439,7 @@ func (fs *FileSorter) fetchNextRow(index int) (*comparableRow, error) {
 		return nil, errors.New("incorrect row")
 	}
 
-	fs.dcod, err = codec.Decode(fs.rowBytes, fs.keySize+fs.valSize+1)
+	fs.dcod, err = codec.Decode(fs.rowBytes[:rowSize], fs.keySize+fs.valSize+1)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -203,6 +203,46 @@ func (s *testFileSortSuite) TestMultipleFiles(c *C) {
 	}
 }
 
+func (s *testFileSortSuite) TestVariableRowSize(c *C) {
+	defer testleak.AfterTest(c)()
+
+	seed := rand.NewSource(time.Now().UnixNano())
+	r := rand.New(seed)
+
+	sc := new(variable.StatementContext)
+	bufSize := 10
+	byDesc := []bool{false}
+
+	tmpDir, err := ioutil.TempDir("", "util_filesort_test")
+	c.Assert(err, IsNil)
+
+	fsBuilder := new(Builder)
+	fs, err := fsBuilder.SetSC(sc).SetSchema(1, 1).SetBuf(bufSize).SetWorkers(1).SetDesc(byDesc).SetDir(tmpDir).Build()
+	c.Assert(err, IsNil)
+	defer fs.Close()
+
+	// Rows with values of different lengths are written into the same file.
+	nRows := bufSize * 5
+	for i := 0; i < nRows; i++ {
+		k := r.Intn(1000)
+		val := make([]byte, r.Intn(100))
+		err = fs.Input([]types.Datum{types.NewIntDatum(int64(k))}, []types.Datum{types.NewBytesDatum(val)}, int64(len(val)))
+		c.Assert(err, IsNil)
+	}
+
+	var prev int64 = -1
+	for i := 0; i < nRows; i++ {
+		key, val, handle, err := fs.Output()
+		c.Assert(err, IsNil)
+		c.Assert(key[0].GetInt64(), GreaterEqual, prev)
+		c.Assert(int64(len(val[0].GetBytes())), Equals, handle)
+		prev = key[0].GetInt64()
+	}
+	key, _, _, err := fs.Output()
+	c.Assert(err, IsNil)
+	c.Assert(key, IsNil)
+}
+
 func (s *testFileSortSuite) TestMultipleWorkers(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -0,0 +1,95 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Tracker is used to track the memory usage during query execution.
+// Trackers are arranged into a tree: the bytes consumed by a Tracker are also
+// consumed by all of its ancestors, so the tracker of a statement sees the memory
+// used by all of its operators.
+//
+// A Tracker is safe for concurrent Consume calls, AttachTo and Detach must not be
+// called concurrently with other methods.
+type Tracker struct {
+	label         string
+	bytesLimit    int64
+	bytesConsumed int64
+	parent        *Tracker
+}
+
+// NewTracker creates a memory tracker. A bytesLimit not greater than 0 means no limit.
+func NewTracker(label string, bytesLimit int64) *Tracker {
+	return &Tracker{
+		label:      label,
+		bytesLimit: bytesLimit,
+	}
+}
+
+// AttachTo attaches the tracker to parent, the bytes already consumed by the tracker are
+// consumed by parent too. Attaching to a nil parent does nothing.
+func (t *Tracker) AttachTo(parent *Tracker) {
+	if parent == nil {
+		return
+	}
+	if t.parent != nil {
+		t.Detach()
+	}
+	t.parent = parent
+	parent.Consume(t.BytesConsumed())
+}
+
+// Detach detaches the tracker from its parent and returns the bytes it consumed to the parent.
+func (t *Tracker) Detach() {
+	if t.parent == nil {
+		return
+	}
+	t.parent.Consume(-t.BytesConsumed())
+	t.parent = nil
+}
+
+// Consume is used to consume or, with a negative value, release memory.
+func (t *Tracker) Consume(bytes int64) {
+	for tracker := t; tracker != nil; tracker = tracker.parent {
+		atomic.AddInt64(&tracker.bytesConsumed, bytes)
+	}
+}
+
+// BytesConsumed returns the bytes consumed by the tracker and its descendants.
+func (t *Tracker) BytesConsumed() int64 {
+	return atomic.LoadInt64(&t.bytesConsumed)
+}
+
+// BytesLimit returns the bytes limit of the tracker.
+func (t *Tracker) BytesLimit() int64 {
+	return t.bytesLimit
+}
+
+// Exceeded returns whether the tracker or any of its ancestors consumes more bytes than its limit.
+func (t *Tracker) Exceeded() bool {
+	for tracker := t; tracker != nil; tracker = tracker.parent {
+		if tracker.bytesLimit > 0 && tracker.BytesConsumed() > tracker.bytesLimit {
+			return true
+		}
+	}
+	return false
+}
+
+// String implements the fmt.Stringer interface.
+func (t *Tracker) String() string {
+	return fmt.Sprintf("\"%s\"{consumed: %d, limit: %d}", t.label, t.BytesConsumed(), t.bytesLimit)
+}
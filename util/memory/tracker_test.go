@@ -0,0 +1,68 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testSuite{})
+
+type testSuite struct{}
+
+func (s *testSuite) TestConsume(c *C) {
+	defer testleak.AfterTest(c)()
+	root := NewTracker("root", 100)
+	child := NewTracker("child", -1)
+	child.Consume(10)
+	child.AttachTo(root)
+	c.Assert(root.BytesConsumed(), Equals, int64(10))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			child.Consume(10)
+			child.Consume(-5)
+		}()
+	}
+	wg.Wait()
+	c.Assert(child.BytesConsumed(), Equals, int64(60))
+	c.Assert(root.BytesConsumed(), Equals, int64(60))
+	c.Assert(child.Exceeded(), IsFalse)
+
+	child.Consume(50)
+	c.Assert(root.Exceeded(), IsTrue)
+	c.Assert(child.Exceeded(), IsTrue)
+	c.Assert(root.String(), Equals, `"root"{consumed: 110, limit: 100}`)
+
+	child.Detach()
+	c.Assert(root.BytesConsumed(), Equals, int64(0))
+	c.Assert(child.BytesConsumed(), Equals, int64(110))
+	c.Assert(child.Exceeded(), IsFalse)
+
+	// Attaching to a nil parent does nothing.
+	child.AttachTo(nil)
+	c.Assert(child.Exceeded(), IsFalse)
+}
@@ -27,7 +27,7 @@ func buildIndexRange(sc *variable.StatementContext, cols []*expression.Column, l
 	var ranges []*types.IndexRange
 	for i := 0; i < inAndEqCount; i++ {
 		// Build ranges for equal or in access conditions.
-		point := rb.build(accessCondition[i])
+		point := rb.buildIndexColumn(accessCondition[i], cols[i].RetType)
 		if i == 0 {
 			ranges = rb.buildIndexRanges(point, cols[i].RetType)
 		} else {
@@ -37,7 +37,7 @@ func buildIndexRange(sc *variable.StatementContext, cols []*expression.Column, l
 	rangePoints := fullRange
 	// Build rangePoints for non-equal access conditions.
 	for i := inAndEqCount; i < len(accessCondition); i++ {
-		rangePoints = rb.intersection(rangePoints, rb.build(accessCondition[i]))
+		rangePoints = rb.intersection(rangePoints, rb.buildIndexColumn(accessCondition[i], cols[inAndEqCount].RetType))
 	}
 	if inAndEqCount == 0 {
 		ranges = rb.buildIndexRanges(rangePoints, cols[0].RetType)
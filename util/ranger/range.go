@@ -21,6 +21,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
@@ -421,6 +422,26 @@ func (r *builder) buildFromScalarFunc(expr *expression.ScalarFunction) []point {
 	return nil
 }
 
+// buildIndexColumn builds the points of the expression on the index column of the field type. The
+// string points are compared under the collation of the column if it's a non-binary collation, so
+// the points equal under the collation are merged, e.g. the points of "a in ('a', 'A')".
+func (r *builder) buildIndexColumn(expr expression.Expression, tp *types.FieldType) []point {
+	points := r.build(expr)
+	if !types.HasCollationKey(tp) {
+		return points
+	}
+	for i := range points {
+		setPointCollation(&points[i], tp)
+	}
+	return r.union(points, nil)
+}
+
+func setPointCollation(p *point, tp *types.FieldType) {
+	if p.value.Kind() == types.KindString || p.value.Kind() == types.KindBytes {
+		p.value.SetCollation(mysql.CollationNames[tp.Collate])
+	}
+}
+
 func (r *builder) intersection(a, b []point) []point {
 	return r.merge(a, b, false)
 }
@@ -504,6 +525,9 @@ func (r *builder) convertPoint(point point, tp *types.FieldType) point {
 		r.err = errors.Trace(err)
 	}
 	point.value = casted
+	if types.HasCollationKey(tp) {
+		setPointCollation(&point, tp)
+	}
 	if valCmpCasted == 0 {
 		return point
 	}
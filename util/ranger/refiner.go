@@ -48,9 +48,9 @@ func BuildIndexRange(sc *variable.StatementContext, tblInfo *model.TableInfo, in
 	var ranges []*types.IndexRange
 	for i := 0; i < accessInAndEqCount; i++ {
 		// Build ranges for equal or in access conditions.
-		point := rb.build(accessCondition[i])
 		colOff := index.Columns[i].Offset
 		tp := &tblInfo.Columns[colOff].FieldType
+		point := rb.buildIndexColumn(accessCondition[i], tp)
 		if i == 0 {
 			ranges = rb.buildIndexRanges(point, tp)
 		} else {
@@ -60,7 +60,8 @@ func BuildIndexRange(sc *variable.StatementContext, tblInfo *model.TableInfo, in
 	rangePoints := fullRange
 	// Build rangePoints for non-equal access conditions.
 	for i := accessInAndEqCount; i < len(accessCondition); i++ {
-		rangePoints = rb.intersection(rangePoints, rb.build(accessCondition[i]))
+		tp := &tblInfo.Columns[index.Columns[accessInAndEqCount].Offset].FieldType
+		rangePoints = rb.intersection(rangePoints, rb.buildIndexColumn(accessCondition[i], tp))
 	}
 	if accessInAndEqCount == 0 {
 		colOff := index.Columns[0].Offset
@@ -168,7 +169,10 @@ func checkIndexCondition(condition expression.Expression, indexColumns []*model.
 		}
 		isIndexColumn := false
 		for _, indCol := range indexColumns {
-			if col.ColName.L == indCol.Name.L && indCol.Length == types.UnspecifiedLength {
+			// The index stores the sort keys of the strings compared under non-binary collations,
+			// the conditions on them can't be evaluated on the index.
+			if col.ColName.L == indCol.Name.L && indCol.Length == types.UnspecifiedLength && !types.HasCollationKey(col.RetType) &&
+				!mysql.HasExplicitCollateFlag(col.RetType.Flag) {
 				isIndexColumn = true
 				break
 			}
@@ -374,6 +378,9 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 	case ast.LogicOr, ast.LogicAnd:
 		return c.check(scalar.GetArgs()[0]) && c.check(scalar.GetArgs()[1])
 	case ast.EQ, ast.NE, ast.GE, ast.GT, ast.LE, ast.LT:
+		if hasExplicitCollation(scalar.GetArgs()) {
+			return false
+		}
 		if _, ok := scalar.GetArgs()[0].(*expression.Constant); ok {
 			if c.checkColumn(scalar.GetArgs()[1]) {
 				return scalar.FuncName.L != ast.NE || c.length == types.UnspecifiedLength
@@ -398,7 +405,7 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 		}
 		return c.check(scalar.GetArgs()[0])
 	case ast.In:
-		if !c.checkColumn(scalar.GetArgs()[0]) {
+		if hasExplicitCollation(scalar.GetArgs()) || !c.checkColumn(scalar.GetArgs()[0]) {
 			return false
 		}
 		for _, v := range scalar.GetArgs()[1:] {
@@ -413,10 +420,27 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 	return false
 }
 
+// hasExplicitCollation checks whether any of the arguments has the collation specified by the COLLATE
+// clause. The arguments are compared under the collation, which may be different from the collation
+// of the index column, so the range can't be built on the index.
+func hasExplicitCollation(args []expression.Expression) bool {
+	for _, arg := range args {
+		if mysql.HasExplicitCollateFlag(arg.GetType().Flag) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool {
-	if !c.checkColumn(scalar.GetArgs()[0]) {
+	if hasExplicitCollation(scalar.GetArgs()) || !c.checkColumn(scalar.GetArgs()[0]) {
 		return false
 	}
+	if types.HasCollationKey(scalar.GetArgs()[0].GetType()) {
+		// The like function compares the strings as binary, but the range is built on the sort keys
+		// of the collation, which contains more values.
+		c.shouldReserve = true
+	}
 	pattern, ok := scalar.GetArgs()[1].(*expression.Constant)
 	if !ok {
 		return false
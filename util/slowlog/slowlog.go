@@ -0,0 +1,285 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slowlog writes the slow queries to the slow query log file and parses the file.
+//
+// The entries are written in the format of the MySQL slow query log, with some TiDB specific
+// fields, so the file can be analyzed by the tools for MySQL like mysqldumpslow and pt-query-digest:
+//
+//	# Time: 2017-11-08T10:11:12.123456+08:00
+//	# User@Host: root[root] @ 127.0.0.1 [127.0.0.1]  Id: 3
+//	# Query_time: 0.501234  Lock_time: 0.000000  Rows_sent: 1  Rows_examined: 10000
+//	# Txn_start_ts: 396204235187290113
+//	# DB: test
+//	# Index_names: t:idx_a
+//	# Cop_tasks: 2  Cop_process_time: 0.480000  Cop_wait_time: 0.000012
+//	# Plan:
+//	#   IndexLookUp_7	root		10000.00
+//	#     IndexScan_5	cop	table:t, index:a, range:[1,1], keep order:false	10000.00
+//	#     TableScan_6	cop	table:t, keep order:false	10000.00
+//	select * from t where a = 1;
+package slowlog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/pkg/logutil"
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// TimeFormat is the format of the time of the entries.
+	TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+	defaultMaxSize = 300 // MB
+
+	timeHeader = "# Time: "
+	planHeader = "# Plan:"
+	planPrefix = "#   "
+)
+
+// Entry is an entry of the slow query log.
+type Entry struct {
+	Time           time.Time
+	User           string
+	Host           string
+	ConnID         uint64
+	QueryTime      time.Duration
+	LockTime       time.Duration
+	RowsSent       uint64
+	RowsExamined   uint64
+	TxnStartTS     uint64
+	DB             string
+	IndexNames     []string
+	CopTasks       uint64
+	CopProcessTime time.Duration
+	CopWaitTime    time.Duration
+	// Plan is the operator tree of the execution plan, the lines are separated by '\n'.
+	Plan  string
+	Query string
+}
+
+// String returns the entry in the format of the slow query log, it ends with a new line.
+func (e *Entry) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(timeHeader + e.Time.Format(TimeFormat) + "\n")
+	fmt.Fprintf(&buf, "# User@Host: %s[%s] @ %s [%s]  Id: %d\n", e.User, e.User, e.Host, e.Host, e.ConnID)
+	fmt.Fprintf(&buf, "# Query_time: %.6f  Lock_time: %.6f  Rows_sent: %d  Rows_examined: %d\n",
+		e.QueryTime.Seconds(), e.LockTime.Seconds(), e.RowsSent, e.RowsExamined)
+	fmt.Fprintf(&buf, "# Txn_start_ts: %d\n", e.TxnStartTS)
+	fmt.Fprintf(&buf, "# DB: %s\n", e.DB)
+	if len(e.IndexNames) > 0 {
+		fmt.Fprintf(&buf, "# Index_names: %s\n", strings.Join(e.IndexNames, ","))
+	}
+	if e.CopTasks > 0 {
+		fmt.Fprintf(&buf, "# Cop_tasks: %d  Cop_process_time: %.6f  Cop_wait_time: %.6f\n",
+			e.CopTasks, e.CopProcessTime.Seconds(), e.CopWaitTime.Seconds())
+	}
+	if len(e.Plan) > 0 {
+		buf.WriteString(planHeader + "\n")
+		for _, line := range strings.Split(e.Plan, "\n") {
+			buf.WriteString(planPrefix + line + "\n")
+		}
+	}
+	buf.WriteString(e.Query + ";\n")
+	return buf.String()
+}
+
+var logger struct {
+	sync.Mutex
+	out io.WriteCloser
+}
+
+// InitLogger initializes the logger to write the slow queries to the file, the file is rotated with
+// the size and age limits of cfg. The logger is disabled if the filename is empty.
+func InitLogger(filename string, cfg *logutil.FileLogConfig) error {
+	var out io.WriteCloser
+	if filename != "" {
+		if st, err := os.Stat(filename); err == nil && st.IsDir() {
+			return errors.New("can't use directory as slow query log file name")
+		}
+		maxSize := cfg.MaxSize
+		if maxSize == 0 {
+			maxSize = defaultMaxSize
+		}
+		out = &lumberjack.Logger{
+			Filename:   filename,
+			MaxSize:    maxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxDays,
+			LocalTime:  true,
+		}
+	}
+	logger.Lock()
+	defer logger.Unlock()
+	if logger.out != nil {
+		if err := logger.out.Close(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	logger.out = out
+	return nil
+}
+
+// Enabled returns whether the slow queries are written to the slow query log file.
+func Enabled() bool {
+	logger.Lock()
+	enabled := logger.out != nil
+	logger.Unlock()
+	return enabled
+}
+
+// Write writes the entry to the slow query log file.
+func Write(e *Entry) error {
+	s := e.String()
+	logger.Lock()
+	defer logger.Unlock()
+	if logger.out == nil {
+		return nil
+	}
+	_, err := io.WriteString(logger.out, s)
+	return errors.Trace(err)
+}
+
+// ParseFile parses the entries of the slow query log file.
+func ParseFile(filename string) ([]*Entry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse parses the entries of the slow query log, the lines before the first entry are ignored.
+func Parse(r io.Reader) ([]*Entry, error) {
+	var (
+		entries    []*Entry
+		e          *Entry
+		inPlan     bool
+		planLines  []string
+		queryLines []string
+	)
+	finish := func() {
+		if e == nil {
+			return
+		}
+		e.Plan = strings.Join(planLines, "\n")
+		e.Query = strings.TrimSuffix(strings.Join(queryLines, "\n"), ";")
+		entries = append(entries, e)
+	}
+	rd := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, err := rd.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Trace(err)
+		}
+		if len(line) == 0 && err == io.EOF {
+			break
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, timeHeader):
+			finish()
+			e, inPlan, planLines, queryLines = &Entry{}, false, nil, nil
+			e.Time, err = time.Parse(TimeFormat, line[len(timeHeader):])
+			if err != nil {
+				return nil, errors.Errorf("invalid slow query log at line %d: %v", lineNum, err)
+			}
+		case e == nil:
+		case len(queryLines) == 0 && strings.HasPrefix(line, "#"):
+			// The header lines of the entry.
+			if line == planHeader {
+				inPlan = true
+			} else if inPlan && strings.HasPrefix(line, planPrefix) {
+				planLines = append(planLines, line[len(planPrefix):])
+			} else if err = e.parseFields(line); err != nil {
+				return nil, errors.Errorf("invalid slow query log at line %d: %v", lineNum, err)
+			}
+		default:
+			queryLines = append(queryLines, line)
+		}
+	}
+	finish()
+	return entries, nil
+}
+
+// parseFields parses a header line like "# Query_time: 0.501234  Lock_time: 0.000000".
+func (e *Entry) parseFields(line string) error {
+	for _, field := range strings.Split(strings.TrimPrefix(line, "# "), "  ") {
+		idx := strings.Index(field, ": ")
+		if idx < 0 {
+			continue
+		}
+		var err error
+		key, value := field[:idx], field[idx+2:]
+		switch key {
+		case "User@Host":
+			// The value is like "root[root] @ 127.0.0.1 [127.0.0.1]".
+			if idx := strings.Index(value, "["); idx >= 0 {
+				e.User = value[:idx]
+			}
+			if idx := strings.Index(value, " @ "); idx >= 0 {
+				host := value[idx+3:]
+				if idx := strings.LastIndex(host, " ["); idx >= 0 {
+					host = host[:idx]
+				}
+				e.Host = host
+			}
+		case "Id":
+			e.ConnID, err = strconv.ParseUint(value, 10, 64)
+		case "Query_time":
+			e.QueryTime, err = parseSeconds(value)
+		case "Lock_time":
+			e.LockTime, err = parseSeconds(value)
+		case "Rows_sent":
+			e.RowsSent, err = strconv.ParseUint(value, 10, 64)
+		case "Rows_examined":
+			e.RowsExamined, err = strconv.ParseUint(value, 10, 64)
+		case "Txn_start_ts":
+			e.TxnStartTS, err = strconv.ParseUint(value, 10, 64)
+		case "DB":
+			e.DB = value
+		case "Index_names":
+			e.IndexNames = strings.Split(value, ",")
+		case "Cop_tasks":
+			e.CopTasks, err = strconv.ParseUint(value, 10, 64)
+		case "Cop_process_time":
+			e.CopProcessTime, err = parseSeconds(value)
+		case "Cop_wait_time":
+			e.CopWaitTime, err = parseSeconds(value)
+		}
+		if err != nil {
+			return errors.Errorf("invalid value of %s: %s", key, value)
+		}
+	}
+	return nil
+}
+
+func parseSeconds(s string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	// The times are logged in microseconds.
+	return time.Duration(f*1e6+0.5) * time.Microsecond, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/pkg/logutil"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testSlowLogSuite{})
+
+type testSlowLogSuite struct {
+}
+
+func (s *testSlowLogSuite) TestFormatAndParse(c *C) {
+	defer testleak.AfterTest(c)()
+	t, err := time.Parse(TimeFormat, "2017-11-08T10:11:12.123456+08:00")
+	c.Assert(err, IsNil)
+	e1 := &Entry{
+		Time:           t,
+		User:           "root",
+		Host:           "127.0.0.1",
+		ConnID:         3,
+		QueryTime:      501234 * time.Microsecond,
+		RowsSent:       1,
+		RowsExamined:   10000,
+		TxnStartTS:     396204235187290113,
+		DB:             "test",
+		IndexNames:     []string{"t:idx_a", "t1:idx_b"},
+		CopTasks:       2,
+		CopProcessTime: 480 * time.Millisecond,
+		CopWaitTime:    12 * time.Microsecond,
+		Plan:           "IndexLookUp_7\troot\t\t10000.00\n  IndexScan_5\tcop\ttable:t, index:a\t10000.00",
+		Query:          "select *\nfrom t where a = 1",
+	}
+	c.Assert(e1.String(), Equals, `# Time: 2017-11-08T10:11:12.123456+08:00
+# User@Host: root[root] @ 127.0.0.1 [127.0.0.1]  Id: 3
+# Query_time: 0.501234  Lock_time: 0.000000  Rows_sent: 1  Rows_examined: 10000
+# Txn_start_ts: 396204235187290113
+# DB: test
+# Index_names: t:idx_a,t1:idx_b
+# Cop_tasks: 2  Cop_process_time: 0.480000  Cop_wait_time: 0.000012
+# Plan:
+#   IndexLookUp_7	root		10000.00
+#     IndexScan_5	cop	table:t, index:a	10000.00
+select *
+from t where a = 1;
+`)
+	// The entry without index, coprocessor tasks and plan.
+	e2 := &Entry{Time: t, User: "u1", Host: "localhost", ConnID: 4, QueryTime: time.Second, Query: "set @a = 1"}
+
+	// The lines before the first entry are ignored.
+	log := "/usr/local/bin/tidb-server, Version: 5.7.1-TiDB, started with:\n" + e1.String() + e2.String()
+	entries, err := Parse(strings.NewReader(log))
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Assert(entries[0].Time.Equal(t), IsTrue)
+	entries[0].Time = t
+	c.Assert(entries[0], DeepEquals, e1)
+	c.Assert(entries[1].String(), Equals, e2.String())
+
+	_, err = Parse(strings.NewReader("# Time: 2017-11-08T10:11:12.123456+08:00\n# Query_time: abc\nselect 1;\n"))
+	c.Assert(err, NotNil)
+}
+
+func (s *testSlowLogSuite) TestWriteFile(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "slow_log_test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+
+	c.Assert(InitLogger(dir, &logutil.FileLogConfig{}), NotNil)
+	c.Assert(Enabled(), IsFalse)
+	// Nothing is written if the logger is disabled.
+	c.Assert(Write(&Entry{Query: "select 1"}), IsNil)
+
+	filename := filepath.Join(dir, "tidb-slow.log")
+	c.Assert(InitLogger(filename, &logutil.FileLogConfig{}), IsNil)
+	c.Assert(Enabled(), IsTrue)
+	now := time.Now()
+	c.Assert(Write(&Entry{Time: now, Query: "select 1"}), IsNil)
+	c.Assert(Write(&Entry{Time: now, Query: "select 2"}), IsNil)
+	c.Assert(InitLogger("", nil), IsNil)
+	c.Assert(Enabled(), IsFalse)
+
+	entries, err := ParseFile(filename)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Assert(entries[0].Query, Equals, "select 1")
+	c.Assert(entries[1].Query, Equals, "select 2")
+	// The time is logged in microseconds.
+	c.Assert(entries[1].Time.Equal(now.Truncate(time.Microsecond)), IsTrue)
+}
@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
@@ -387,6 +388,9 @@ func (d *Datum) SetValue(val interface{}) {
 // CompareDatum compares datum to another datum.
 // TODO: return error properly.
 func (d *Datum) CompareDatum(sc *variable.StatementContext, ad *Datum) (int, error) {
+	if collation := d.stringCollation(ad); collation != 0 {
+		return charset.GetCollatorByID(int(collation)).Compare(d.GetString(), ad.GetString()), nil
+	}
 	if d.k == KindMysqlJSON && ad.k != KindMysqlJSON {
 		cmp, err := ad.CompareDatum(sc, d)
 		return cmp * -1, errors.Trace(err)
@@ -438,6 +442,18 @@ func (d *Datum) CompareDatum(sc *variable.StatementContext, ad *Datum) (int, err
 	}
 }
 
+// stringCollation returns the collation to compare the two string datums, it returns 0 if
+// any of them isn't a string or none of them has the collation set.
+func (d *Datum) stringCollation(ad *Datum) uint8 {
+	if (d.k != KindString && d.k != KindBytes) || (ad.k != KindString && ad.k != KindBytes) {
+		return 0
+	}
+	if d.collation != 0 {
+		return d.collation
+	}
+	return ad.collation
+}
+
 func (d *Datum) compareInt64(sc *variable.StatementContext, i int64) (int, error) {
 	switch d.k {
 	case KindMaxValue:
@@ -1792,6 +1808,47 @@ func DatumsToString(datums []Datum) (string, error) {
 	return strings.Join(strs, ", "), nil
 }
 
+// datumSize is the size of the Datum struct itself.
+var datumSize = int64(unsafe.Sizeof(Datum{}))
+
+// EstimatedMemUsage returns the estimated bytes of memory used by the datums.
+// Only the Datum structs and the byte slices they hold are taken into account.
+func EstimatedMemUsage(array []Datum) int64 {
+	bytesConsumed := int64(len(array)) * datumSize
+	for i := range array {
+		bytesConsumed += int64(len(array[i].b))
+	}
+	return bytesConsumed
+}
+
+// maxEstimatedVarLen is the max estimated length of a variable-length value, the max length of TEXT and BLOB
+// is far more than the length of a typical value.
+const maxEstimatedVarLen = 256
+
+// EstimatedMemUsageOfType returns the estimated bytes of memory used by a datum of the field type,
+// it's measured in the same way as EstimatedMemUsage. The length of a string value is estimated by
+// the max length of the type.
+func EstimatedMemUsageOfType(tp *FieldType) int64 {
+	var length int
+	switch tp.Tp {
+	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeString,
+		mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob:
+		length = tp.Flen
+		if length == UnspecifiedLength || length > maxEstimatedVarLen {
+			length = maxEstimatedVarLen
+		}
+	case mysql.TypeBit:
+		length = (tp.Flen + 7) / 8
+	case mysql.TypeEnum, mysql.TypeSet:
+		for _, elem := range tp.Elems {
+			if len(elem) > length {
+				length = len(elem)
+			}
+		}
+	}
+	return datumSize + int64(length)
+}
+
 // CopyDatum returns a new copy of the datum.
 // TODO: Abandon this function.
 func CopyDatum(datum Datum) Datum {
@@ -586,3 +586,29 @@ func (ts *testDatumSuite) TestCopyDatum(c *C) {
 		}
 	}
 }
+
+func (ts *testDatumSuite) TestEstimatedMemUsage(c *C) {
+	c.Assert(EstimatedMemUsage(nil), Equals, int64(0))
+	datums := MakeDatums(int64(1), "abcd", []byte("ab"), nil)
+	c.Assert(EstimatedMemUsage(datums), Equals, 4*datumSize+6)
+
+	tests := []struct {
+		tp     byte
+		flen   int
+		elems  []string
+		expect int64
+	}{
+		{mysql.TypeLonglong, 20, nil, datumSize},
+		{mysql.TypeNewDecimal, 10, nil, datumSize},
+		{mysql.TypeVarchar, 10, nil, datumSize + 10},
+		{mysql.TypeVarchar, UnspecifiedLength, nil, datumSize + maxEstimatedVarLen},
+		{mysql.TypeBlob, 65535, nil, datumSize + maxEstimatedVarLen},
+		{mysql.TypeBit, 9, nil, datumSize + 2},
+		{mysql.TypeEnum, UnspecifiedLength, []string{"a", "abc"}, datumSize + 3},
+	}
+	for _, tt := range tests {
+		tp := NewFieldType(tt.tp)
+		tp.Flen, tp.Elems = tt.flen, tt.elems
+		c.Assert(EstimatedMemUsageOfType(tp), Equals, tt.expect, Commentf("%v", tp))
+	}
+}
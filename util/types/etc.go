@@ -97,6 +97,19 @@ func IsNonBinaryStr(ft *FieldType) bool {
 	return false
 }
 
+// HasCollationKey returns a boolean indicating whether the field type is a string type compared
+// under a non-binary collation, the sort keys of its values are encoded in the index keys unless
+// the LegacyStorageFlag is set.
+func HasCollationKey(ft *FieldType) bool {
+	return IsNonBinaryStr(ft) && !charset.IsBinCollation(ft.Collate) && !mysql.HasLegacyStorageFlag(ft.Flag)
+}
+
+// IsTranscodedStr returns a boolean indicating whether the field type is a non-binary string type
+// whose values are stored in a charset other than UTF-8.
+func IsTranscodedStr(ft *FieldType) bool {
+	return IsNonBinaryStr(ft) && charset.IsTranscoded(ft.Charset) && !mysql.HasLegacyStorageFlag(ft.Flag)
+}
+
 var type2Str = map[byte]string{
 	mysql.TypeBit:        "bit",
 	mysql.TypeBlob:       "text",
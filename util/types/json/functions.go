@@ -284,8 +284,7 @@ func (j JSON) Modify(pathExprList []PathExpression, values []JSON, mt ModifyType
 	}
 	for _, pathExpr := range pathExprList {
 		if pathExpr.flags.containsAnyAsterisk() {
-			// TODO: should return 3149(42000)
-			return retj, errors.New("Invalid path expression")
+			return retj, ErrInvalidJSONPathWildcard
 		}
 	}
 	for i := 0; i < len(pathExprList); i++ {
@@ -295,7 +294,8 @@ func (j JSON) Modify(pathExprList []PathExpression, values []JSON, mt ModifyType
 	return j, nil
 }
 
-// set is for Modify. The result JSON maybe share something with input JSON.
+// set is for Modify. The result JSON maybe share something with input JSON, but
+// the containers on the path are copied before modified, so the input JSON is never changed.
 func set(j JSON, pathExpr PathExpression, value JSON, mt ModifyType) JSON {
 	if len(pathExpr.legs) == 0 {
 		if mt&ModifyReplace != 0 {
@@ -304,6 +304,7 @@ func set(j JSON, pathExpr PathExpression, value JSON, mt ModifyType) JSON {
 		return j
 	}
 	currentLeg, subPathExpr := pathExpr.popOneLeg()
+	j = copyContainer(j)
 	if currentLeg.typ == pathLegIndex {
 		// If j is not an array, we should autowrap that as array.
 		// Then if its length equals to 1, we unwrap it back.
@@ -340,25 +341,39 @@ func set(j JSON, pathExpr PathExpression, value JSON, mt ModifyType) JSON {
 	return j
 }
 
+// copyContainer returns a shallow copy of the array or object j.
+func copyContainer(j JSON) JSON {
+	switch j.TypeCode {
+	case TypeCodeArray:
+		j.Array = append(make([]JSON, 0, len(j.Array)), j.Array...)
+	case TypeCodeObject:
+		object := make(map[string]JSON, len(j.Object))
+		for k, v := range j.Object {
+			object[k] = v
+		}
+		j.Object = object
+	}
+	return j
+}
+
 // Remove removes the elements indicated by pathExprList from JSON.
 func (j JSON) Remove(pathExprList []PathExpression) (JSON, error) {
 	for _, pathExpr := range pathExprList {
 		if len(pathExpr.legs) == 0 {
-			// TODO: should return 3153(42000)
-			return j, errors.New("Invalid path expression")
+			return j, ErrJSONVacuousPath
 		}
 		if pathExpr.flags.containsAnyAsterisk() {
-			// TODO: should return 3149(42000)
-			return j, errors.New("Invalid path expression")
+			return j, ErrInvalidJSONPathWildcard
 		}
 		j = remove(j, pathExpr)
 	}
 	return j, nil
 }
 
-// remove is used in Remove.
+// remove is used in Remove, the containers on the path are copied before modified like set.
 func remove(j JSON, pathExpr PathExpression) JSON {
 	currentLeg, subPathExpr := pathExpr.popOneLeg()
+	j = copyContainer(j)
 	if currentLeg.typ == pathLegIndex && j.TypeCode == TypeCodeArray {
 		var index = currentLeg.arrayIndex
 		if len(j.Array) > index {
@@ -380,3 +395,342 @@ func remove(j JSON, pathExpr PathExpression) JSON {
 	}
 	return j
 }
+
+// ArrayAppend appends the values to the end of the arrays indicated by pathExprList, the
+// scalars and objects indicated are autowrapped as arrays before appended.
+// e.g. json_array_append('{"a": 1}', '$.a', 2) => '{"a": [1, 2]}'
+func (j JSON) ArrayAppend(pathExprList []PathExpression, values []JSON) (JSON, error) {
+	if len(pathExprList) != len(values) {
+		return j, errors.New("Incorrect parameter count")
+	}
+	for i, pathExpr := range pathExprList {
+		if pathExpr.flags.containsAnyAsterisk() {
+			return j, ErrInvalidJSONPathWildcard
+		}
+		elemList := extract(j, pathExpr)
+		if len(elemList) == 0 {
+			continue
+		}
+		var array JSON
+		if elem := elemList[0]; elem.TypeCode == TypeCodeArray {
+			array = copyContainer(elem)
+		} else {
+			array = autoWrapAsArray(elem, 2)
+		}
+		array.Array = append(array.Array, values[i])
+		j = set(j, pathExpr, array, ModifyReplace)
+	}
+	return j, nil
+}
+
+// ArrayInsert inserts the values into the arrays at the positions indicated by pathExprList,
+// the last leg of the path expressions must be an array index. The values are appended if the
+// indexes are beyond the ends of the arrays, and nothing is inserted if the paths don't exist
+// or don't point to arrays.
+// e.g. json_array_insert('[1, 2]', '$[1]', 3) => '[1, 3, 2]'
+func (j JSON) ArrayInsert(pathExprList []PathExpression, values []JSON) (JSON, error) {
+	if len(pathExprList) != len(values) {
+		return j, errors.New("Incorrect parameter count")
+	}
+	for i, pathExpr := range pathExprList {
+		if pathExpr.flags.containsAnyAsterisk() {
+			return j, ErrInvalidJSONPathWildcard
+		}
+		if len(pathExpr.legs) == 0 || pathExpr.legs[len(pathExpr.legs)-1].typ != pathLegIndex {
+			return j, ErrInvalidJSONPathArrayCell
+		}
+		parentPath := PathExpression{legs: pathExpr.legs[:len(pathExpr.legs)-1]}
+		elemList := extract(j, parentPath)
+		if len(elemList) == 0 || elemList[0].TypeCode != TypeCodeArray {
+			continue
+		}
+		elems := elemList[0].Array
+		index := pathExpr.legs[len(pathExpr.legs)-1].arrayIndex
+		if index > len(elems) {
+			index = len(elems)
+		}
+		array := JSON{TypeCode: TypeCodeArray, Array: make([]JSON, 0, len(elems)+1)}
+		array.Array = append(array.Array, elems[:index]...)
+		array.Array = append(array.Array, values[i])
+		array.Array = append(array.Array, elems[index:]...)
+		j = set(j, parentPath, array, ModifyReplace)
+	}
+	return j, nil
+}
+
+// MergePatch merges patches into j according to RFC 7396: an object patch is merged into
+// the target object member by member, the members whose values are null are removed, and
+// a non-object patch replaces the target.
+func (j JSON) MergePatch(patches []JSON) JSON {
+	for _, patch := range patches {
+		j = mergePatch(j, patch)
+	}
+	return j
+}
+
+// mergePatch is used by MergePatch, the target is copied before modified like set.
+func mergePatch(target JSON, patch JSON) JSON {
+	if patch.TypeCode != TypeCodeObject {
+		return patch
+	}
+	if target.TypeCode == TypeCodeObject {
+		target = copyContainer(target)
+	} else {
+		target = JSON{TypeCode: TypeCodeObject, Object: make(map[string]JSON, len(patch.Object))}
+	}
+	for key, value := range patch.Object {
+		if value.TypeCode == TypeCodeLiteral && byte(value.I64) == LiteralNil {
+			delete(target.Object, key)
+			continue
+		}
+		target.Object[key] = mergePatch(target.Object[key], value)
+	}
+	return target
+}
+
+// ContainsJSON returns if target contains candidate:
+// 1) a scalar contains the candidate if and only if they are equal;
+// 2) an object contains a candidate object if and only if each key in the candidate is in
+//    the target, and the value of the key in the target contains the one in the candidate;
+// 3) an array contains a candidate array if and only if it contains each element of the
+//    candidate, and contains a non-array candidate if and only if any of its elements does.
+//    The scalar elements only contain the scalar candidates, the array elements only contain
+//    the array candidates.
+func ContainsJSON(target JSON, candidate JSON) bool {
+	switch target.TypeCode {
+	case TypeCodeObject:
+		if candidate.TypeCode != TypeCodeObject {
+			return false
+		}
+		for key, value := range candidate.Object {
+			child, ok := target.Object[key]
+			if !ok || !ContainsJSON(child, value) {
+				return false
+			}
+		}
+		return true
+	case TypeCodeArray:
+		if candidate.TypeCode != TypeCodeArray {
+			return arrayContains(target.Array, candidate)
+		}
+		for _, elem := range candidate.Array {
+			if !arrayContains(target.Array, elem) {
+				return false
+			}
+		}
+		return true
+	default:
+		cmp, err := CompareJSON(target, candidate)
+		return err == nil && cmp == 0
+	}
+}
+
+// arrayContains returns if any element of the array contains the non-array candidate.
+func arrayContains(array []JSON, candidate JSON) bool {
+	for _, elem := range array {
+		if (elem.TypeCode == TypeCodeArray) != (candidate.TypeCode == TypeCodeArray) {
+			continue
+		}
+		if ContainsJSON(elem, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// Length returns the length of j, the length of a scalar is 1, the length of an array is
+// the number of its elements, and the length of an object is the number of its members.
+func (j JSON) Length() int {
+	switch j.TypeCode {
+	case TypeCodeArray:
+		return len(j.Array)
+	case TypeCodeObject:
+		return len(j.Object)
+	default:
+		return 1
+	}
+}
+
+// Depth returns the maximum depth of j, the depth of a scalar or an empty array or object
+// is 1, and the depth of a non-empty array or object is 1 plus the depth of its deepest child.
+func (j JSON) Depth() int {
+	depth := 0
+	switch j.TypeCode {
+	case TypeCodeArray:
+		for _, child := range j.Array {
+			if d := child.Depth(); d > depth {
+				depth = d
+			}
+		}
+	case TypeCodeObject:
+		for _, child := range j.Object {
+			if d := child.Depth(); d > depth {
+				depth = d
+			}
+		}
+	}
+	return depth + 1
+}
+
+// Keys returns the sorted keys of the object j as a JSON array.
+func (j JSON) Keys() JSON {
+	keys := getSortedKeys(j.Object)
+	ret := JSON{TypeCode: TypeCodeArray, Array: make([]JSON, 0, len(keys))}
+	for _, key := range keys {
+		ret.Array = append(ret.Array, JSON{TypeCode: TypeCodeString, Str: key})
+	}
+	return ret
+}
+
+// Search returns the paths of the string values in j which are matched by match. If
+// pathExprList isn't empty, only the values under the paths are searched. It returns the first
+// path only if one is true.
+func (j JSON) Search(pathExprList []PathExpression, match func(string) bool, one bool) []string {
+	if len(pathExprList) == 0 {
+		pathExprList = []PathExpression{{}}
+	}
+	var paths []string
+	seen := make(map[string]struct{})
+	for _, pathExpr := range pathExprList {
+		for _, pv := range extractWithLegs(j, pathExpr, nil) {
+			walk(pv.value, pv.legs, func(legs []pathLeg, value JSON) bool {
+				if value.TypeCode != TypeCodeString || !match(value.Str) {
+					return false
+				}
+				path := PathExpression{legs: legs}.String()
+				if _, ok := seen[path]; !ok {
+					seen[path] = struct{}{}
+					paths = append(paths, path)
+				}
+				return one
+			})
+			if one && len(paths) > 0 {
+				return paths
+			}
+		}
+	}
+	return paths
+}
+
+// pathValue is a value in a JSON document with the legs of its path.
+type pathValue struct {
+	legs  []pathLeg
+	value JSON
+}
+
+// extractWithLegs is like extract, but it returns the values with their paths,
+// the legs of the paths are appended to prefix.
+func extractWithLegs(j JSON, pathExpr PathExpression, prefix []pathLeg) (ret []pathValue) {
+	if len(pathExpr.legs) == 0 {
+		return []pathValue{{legs: prefix, value: j}}
+	}
+	currentLeg, subPathExpr := pathExpr.popOneLeg()
+	if currentLeg.typ == pathLegIndex {
+		if j.TypeCode != TypeCodeArray {
+			// The scalar or object is autowrapped as an array, and its path isn't changed.
+			if currentLeg.arrayIndex == 0 || currentLeg.arrayIndex == arrayIndexAsterisk {
+				ret = append(ret, extractWithLegs(j, subPathExpr, prefix)...)
+			}
+		} else if currentLeg.arrayIndex == arrayIndexAsterisk {
+			for i, child := range j.Array {
+				ret = append(ret, extractWithLegs(child, subPathExpr, appendLeg(prefix, indexLeg(i)))...)
+			}
+		} else if currentLeg.arrayIndex < len(j.Array) {
+			child := j.Array[currentLeg.arrayIndex]
+			ret = append(ret, extractWithLegs(child, subPathExpr, appendLeg(prefix, currentLeg))...)
+		}
+	} else if currentLeg.typ == pathLegKey && j.TypeCode == TypeCodeObject {
+		if len(currentLeg.dotKey) == 1 && currentLeg.dotKey[0] == '*' {
+			for _, key := range getSortedKeys(j.Object) {
+				ret = append(ret, extractWithLegs(j.Object[key], subPathExpr, appendLeg(prefix, keyLeg(key)))...)
+			}
+		} else if child, ok := j.Object[currentLeg.dotKey]; ok {
+			ret = append(ret, extractWithLegs(child, subPathExpr, appendLeg(prefix, currentLeg))...)
+		}
+	} else if currentLeg.typ == pathLegDoubleAsterisk {
+		ret = append(ret, extractWithLegs(j, subPathExpr, prefix)...)
+		if j.TypeCode == TypeCodeArray {
+			for i, child := range j.Array {
+				ret = append(ret, extractWithLegs(child, pathExpr, appendLeg(prefix, indexLeg(i)))...)
+			}
+		} else if j.TypeCode == TypeCodeObject {
+			for _, key := range getSortedKeys(j.Object) {
+				ret = append(ret, extractWithLegs(j.Object[key], pathExpr, appendLeg(prefix, keyLeg(key)))...)
+			}
+		}
+	}
+	return
+}
+
+// walk calls fn on j and the values in j in document order with their paths, the legs of the
+// paths are appended to prefix. It stops walking and returns true once fn returns true.
+func walk(j JSON, prefix []pathLeg, fn func(legs []pathLeg, value JSON) bool) bool {
+	if fn(prefix, j) {
+		return true
+	}
+	switch j.TypeCode {
+	case TypeCodeArray:
+		for i, child := range j.Array {
+			if walk(child, appendLeg(prefix, indexLeg(i)), fn) {
+				return true
+			}
+		}
+	case TypeCodeObject:
+		for _, key := range getSortedKeys(j.Object) {
+			if walk(j.Object[key], appendLeg(prefix, keyLeg(key)), fn) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appendLeg returns a copy of legs with leg appended, so the legs of different paths never share
+// the same underlying array.
+func appendLeg(legs []pathLeg, leg pathLeg) []pathLeg {
+	ret := make([]pathLeg, 0, len(legs)+1)
+	ret = append(ret, legs...)
+	return append(ret, leg)
+}
+
+func indexLeg(index int) pathLeg {
+	return pathLeg{typ: pathLegIndex, arrayIndex: index}
+}
+
+func keyLeg(key string) pathLeg {
+	return pathLeg{typ: pathLegKey, dotKey: key}
+}
+
+// QuoteString quotes s as a JSON string literal, the double quotes, backslashes
+// and control characters are escaped.
+func QuoteString(s string) string {
+	var buf bytes.Buffer
+	buf.Grow(len(s) + 2)
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(&buf, `\u%04x`, c)
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
@@ -219,8 +219,213 @@ func (s *testJSONSuite) TestJSONModify(c *C) {
 			cmp, err = CompareJSON(obtain, expected)
 			c.Assert(err, IsNil)
 			c.Assert(cmp, Equals, 0)
+			// The base JSON is not changed.
+			cmp, err = CompareJSON(base, mustParseFromString(tt.base))
+			c.Assert(err, IsNil)
+			c.Assert(cmp, Equals, 0)
+		} else {
+			c.Assert(err, NotNil)
+		}
+	}
+}
+
+func mustParsePathExprs(c *C, pathExprStrings []string) []PathExpression {
+	pathExprList := make([]PathExpression, 0, len(pathExprStrings))
+	for _, s := range pathExprStrings {
+		pe, err := ParseJSONPathExpr(s)
+		c.Assert(err, IsNil)
+		pathExprList = append(pathExprList, pe)
+	}
+	return pathExprList
+}
+
+func (s *testJSONSuite) TestJSONArrayAppendInsert(c *C) {
+	var tests = []struct {
+		base     string
+		path     string
+		value    string
+		insert   bool
+		expected string
+		success  bool
+	}{
+		{`[1, 2]`, "$", `3`, false, `[1, 2, 3]`, true},
+		{`{"a": 1}`, "$.a", `2`, false, `{"a": [1, 2]}`, true},
+		{`{"a": 1}`, "$", `2`, false, `[{"a": 1}, 2]`, true},
+		{`1`, "$[0]", `2`, false, `[1, 2]`, true},
+		{`[1, [2]]`, "$[1]", `3`, false, `[1, [2, 3]]`, true},
+		// nothing changed because the path doesn't exist.
+		{`{"a": 1}`, "$.b", `2`, false, `{"a": 1}`, true},
+
+		{`[1, 2]`, "$[1]", `3`, true, `[1, 3, 2]`, true},
+		{`[1, 2]`, "$[0]", `3`, true, `[3, 1, 2]`, true},
+		{`[1, 2]`, "$[10]", `3`, true, `[1, 2, 3]`, true},
+		{`{"a": [1]}`, "$.a[0]", `3`, true, `{"a": [3, 1]}`, true},
+		// nothing changed because the path doesn't point to an array.
+		{`{"a": 1}`, "$.a[0]", `3`, true, `{"a": 1}`, true},
+
+		// bad path expression.
+		{`[1, 2]`, "$[*]", `3`, false, `null`, false},
+		{`[1, 2]`, "$[*]", `3`, true, `null`, false},
+		{`{"a": 1}`, "$.a", `3`, true, `null`, false},
+		{`[1, 2]`, "$", `3`, true, `null`, false},
+	}
+	for _, tt := range tests {
+		base := mustParseFromString(tt.base)
+		pathExprList := mustParsePathExprs(c, []string{tt.path})
+		values := []JSON{mustParseFromString(tt.value)}
+		var obtain JSON
+		var err error
+		if tt.insert {
+			obtain, err = base.ArrayInsert(pathExprList, values)
 		} else {
+			obtain, err = base.ArrayAppend(pathExprList, values)
+		}
+		if !tt.success {
 			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		cmp, err := CompareJSON(obtain, mustParseFromString(tt.expected))
+		c.Assert(err, IsNil)
+		c.Assert(cmp, Equals, 0, Commentf("%v", tt))
+		// The base JSON is not changed.
+		cmp, err = CompareJSON(base, mustParseFromString(tt.base))
+		c.Assert(err, IsNil)
+		c.Assert(cmp, Equals, 0)
+	}
+}
+
+func (s *testJSONSuite) TestJSONMergePatch(c *C) {
+	var tests = []struct {
+		base     string
+		patches  []string
+		expected string
+	}{
+		{`{"a": 1}`, []string{`{"b": 2}`}, `{"a": 1, "b": 2}`},
+		{`{"a": 1}`, []string{`{"a": 2}`}, `{"a": 2}`},
+		{`{"a": 1, "b": 2}`, []string{`{"a": null}`}, `{"b": 2}`},
+		{`{"a": {"b": 1}}`, []string{`{"a": {"c": 2}}`}, `{"a": {"b": 1, "c": 2}}`},
+		{`[1]`, []string{`[2]`}, `[2]`},
+		{`{"a": 1}`, []string{`4`}, `4`},
+		{`4`, []string{`{"a": 1}`}, `{"a": 1}`},
+		{`{"a": 1}`, []string{`{"a": 2}`, `{"b": 3}`}, `{"a": 2, "b": 3}`},
+	}
+	for _, tt := range tests {
+		base := mustParseFromString(tt.base)
+		patches := make([]JSON, 0, len(tt.patches))
+		for _, s := range tt.patches {
+			patches = append(patches, mustParseFromString(s))
 		}
+		cmp, err := CompareJSON(base.MergePatch(patches), mustParseFromString(tt.expected))
+		c.Assert(err, IsNil)
+		c.Assert(cmp, Equals, 0)
+		// The base JSON is not changed.
+		cmp, err = CompareJSON(base, mustParseFromString(tt.base))
+		c.Assert(err, IsNil)
+		c.Assert(cmp, Equals, 0)
+	}
+}
+
+func (s *testJSONSuite) TestContainsJSON(c *C) {
+	var tests = []struct {
+		target    string
+		candidate string
+		expected  bool
+	}{
+		{`1`, `1`, true},
+		{`1`, `1.0`, true},
+		{`1`, `"1"`, false},
+		{`{"a": 1, "b": [2, 3]}`, `{"a": 1}`, true},
+		{`{"a": 1, "b": [2, 3]}`, `{"b": 3}`, true},
+		{`{"a": 1, "b": [2, 3]}`, `{"a": 2}`, false},
+		{`{"a": 1}`, `1`, false},
+		{`[1, 2, [3, 4]]`, `2`, true},
+		{`[1, 2, [3, 4]]`, `[1, 2]`, true},
+		{`[1, 2, [3, 4]]`, `[[3]]`, true},
+		{`[1, 2, [3, 4]]`, `3`, false},
+		{`[1, 2, [3, 4]]`, `[1, 5]`, false},
+		{`[{"a": 1, "b": 2}]`, `{"a": 1}`, true},
+		{`1`, `[1]`, false},
+	}
+	for _, tt := range tests {
+		target := mustParseFromString(tt.target)
+		candidate := mustParseFromString(tt.candidate)
+		c.Assert(ContainsJSON(target, candidate), Equals, tt.expected, Commentf("%v", tt))
+	}
+}
+
+func (s *testJSONSuite) TestJSONLengthDepthKeys(c *C) {
+	var tests = []struct {
+		j      string
+		length int
+		depth  int
+		keys   string
+	}{
+		{`1`, 1, 1, ``},
+		{`[]`, 0, 1, ``},
+		{`{}`, 0, 1, `[]`},
+		{`[1, [2, 3]]`, 2, 3, ``},
+		{`{"b": 1, "a": {"c": []}}`, 2, 3, `["a", "b"]`},
+	}
+	for _, tt := range tests {
+		j := mustParseFromString(tt.j)
+		c.Assert(j.Length(), Equals, tt.length)
+		c.Assert(j.Depth(), Equals, tt.depth)
+		if tt.keys != "" {
+			cmp, err := CompareJSON(j.Keys(), mustParseFromString(tt.keys))
+			c.Assert(err, IsNil)
+			c.Assert(cmp, Equals, 0)
+		}
+	}
+}
+
+func (s *testJSONSuite) TestJSONSearch(c *C) {
+	j := mustParseFromString(`["abc", [{"k": "10"}, "def"], {"x": "abc"}, {"y": "bcd"}]`)
+	var tests = []struct {
+		target    string
+		pathExprs []string
+		one       bool
+		expected  []string
+	}{
+		{"abc", nil, true, []string{"$[0]"}},
+		{"abc", nil, false, []string{"$[0]", "$[2].x"}},
+		{"10", nil, false, []string{"$[1][0].k"}},
+		{"abc", []string{"$[2]"}, false, []string{"$[2].x"}},
+		{"abc", []string{"$[*]", "$**.x"}, false, []string{"$[0]", "$[2].x"}},
+		{"abc", []string{"$[3]"}, false, nil},
+		{"zzz", nil, false, nil},
+	}
+	for _, tt := range tests {
+		target := tt.target
+		match := func(s string) bool { return s == target }
+		paths := j.Search(mustParsePathExprs(c, tt.pathExprs), match, tt.one)
+		c.Assert(paths, DeepEquals, tt.expected)
+	}
+	c.Assert(mustParseFromString(`"abc"`).Search(nil, func(string) bool { return true }, false), DeepEquals, []string{"$"})
+}
+
+func (s *testJSONSuite) TestQuoteString(c *C) {
+	var tests = []struct {
+		input  string
+		output string
+	}{
+		{`abc`, `"abc"`},
+		{`"quoted"`, `"\"quoted\""`},
+		{"a\tb\nc\\", `"a\tb\nc\\"`},
+		{"\x01你", `"\u0001你"`},
+		{`<a&b>`, `"<a&b>"`},
+	}
+	for _, tt := range tests {
+		quoted := QuoteString(tt.input)
+		c.Assert(quoted, Equals, tt.output)
+		j := mustParseFromString(quoted)
+		c.Assert(j.Str, Equals, tt.input)
 	}
 }
+
+func (s *testJSONSuite) TestJSONPretty(c *C) {
+	j := mustParseFromString(`{"a": [1, {"b": 2}], "c": {}, "d": []}`)
+	expected := "{\n  \"a\": [\n    1,\n    {\n      \"b\": 2\n    }\n  ],\n  \"c\": {},\n  \"d\": []\n}"
+	c.Assert(j.Pretty(), Equals, expected)
+	c.Assert(mustParseFromString(`"abc"`).Pretty(), Equals, `"abc"`)
+}
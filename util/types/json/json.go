@@ -140,6 +140,14 @@ func (j JSON) String() string {
 	return strings.TrimSpace(hack.String(bytes))
 }
 
+// Pretty returns the JSON text of j indented by 2 spaces, each element of the
+// arrays and each member of the objects is in its own line.
+func (j JSON) Pretty() string {
+	bytes, err := json.MarshalIndent(j, "", "  ")
+	terror.Log(errors.Trace(err))
+	return hack.String(bytes)
+}
+
 var (
 	// ErrInvalidJSONText means invalid JSON text.
 	ErrInvalidJSONText = terror.ClassJSON.New(mysql.ErrInvalidJSONText, mysql.MySQLErrName[mysql.ErrInvalidJSONText])
@@ -147,12 +155,24 @@ var (
 	ErrInvalidJSONPath = terror.ClassJSON.New(mysql.ErrInvalidJSONPath, mysql.MySQLErrName[mysql.ErrInvalidJSONPath])
 	// ErrInvalidJSONData means invalid JSON data.
 	ErrInvalidJSONData = terror.ClassJSON.New(mysql.ErrInvalidJSONData, mysql.MySQLErrName[mysql.ErrInvalidJSONData])
+	// ErrInvalidJSONPathWildcard means the path expression contains wildcards where they're not allowed.
+	ErrInvalidJSONPathWildcard = terror.ClassJSON.New(mysql.ErrInvalidJSONPathWildcard, mysql.MySQLErrName[mysql.ErrInvalidJSONPathWildcard])
+	// ErrJSONVacuousPath means the path expression '$' is not allowed.
+	ErrJSONVacuousPath = terror.ClassJSON.New(mysql.ErrJSONVacuousPath, mysql.MySQLErrName[mysql.ErrJSONVacuousPath])
+	// ErrJSONBadOneOrAllArg means the oneOrAll argument is neither 'one' nor 'all'.
+	ErrJSONBadOneOrAllArg = terror.ClassJSON.New(mysql.ErrJSONBadOneOrAllArg, mysql.MySQLErrName[mysql.ErrJSONBadOneOrAllArg])
+	// ErrInvalidJSONPathArrayCell means the path expression is not a path to a cell in an array.
+	ErrInvalidJSONPathArrayCell = terror.ClassJSON.New(mysql.ErrInvalidJSONPathArrayCell, mysql.MySQLErrName[mysql.ErrInvalidJSONPathArrayCell])
 )
 
 func init() {
 	terror.ErrClassToMySQLCodes[terror.ClassJSON] = map[terror.ErrCode]uint16{
-		mysql.ErrInvalidJSONText: mysql.ErrInvalidJSONText,
-		mysql.ErrInvalidJSONPath: mysql.ErrInvalidJSONPath,
-		mysql.ErrInvalidJSONData: mysql.ErrInvalidJSONData,
+		mysql.ErrInvalidJSONText:          mysql.ErrInvalidJSONText,
+		mysql.ErrInvalidJSONPath:          mysql.ErrInvalidJSONPath,
+		mysql.ErrInvalidJSONData:          mysql.ErrInvalidJSONData,
+		mysql.ErrInvalidJSONPathWildcard:  mysql.ErrInvalidJSONPathWildcard,
+		mysql.ErrJSONVacuousPath:          mysql.ErrJSONVacuousPath,
+		mysql.ErrJSONBadOneOrAllArg:       mysql.ErrJSONBadOneOrAllArg,
+		mysql.ErrInvalidJSONPathArrayCell: mysql.ErrInvalidJSONPathArrayCell,
 	}
 }
@@ -14,6 +14,7 @@
 package json
 
 import (
+	"bytes"
 	"regexp"
 	"strconv"
 	"strings"
@@ -91,6 +92,11 @@ type PathExpression struct {
 	flags pathExpressionFlag
 }
 
+// ContainsAnyAsterisk returns true if pe contains any asterisk.
+func (pe PathExpression) ContainsAnyAsterisk() bool {
+	return pe.flags.containsAnyAsterisk()
+}
+
 // popOneLeg returns a pathLeg, and a child PathExpression without that leg.
 func (pe PathExpression) popOneLeg() (pathLeg, PathExpression) {
 	newPe := PathExpression{
@@ -193,6 +199,35 @@ func ParseJSONPathExpr(pathExpr string) (pe PathExpression, err error) {
 	return
 }
 
+// jsonPathKeyIdentRe matches the keys which can be written in path expressions without quotes.
+var jsonPathKeyIdentRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// String implements fmt.Stringer interface.
+func (pe PathExpression) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('$')
+	for _, leg := range pe.legs {
+		switch leg.typ {
+		case pathLegIndex:
+			if leg.arrayIndex == arrayIndexAsterisk {
+				buf.WriteString("[*]")
+			} else {
+				buf.WriteString("[" + strconv.Itoa(leg.arrayIndex) + "]")
+			}
+		case pathLegKey:
+			buf.WriteByte('.')
+			if leg.dotKey == "*" || jsonPathKeyIdentRe.MatchString(leg.dotKey) {
+				buf.WriteString(leg.dotKey)
+			} else {
+				buf.WriteString(QuoteString(leg.dotKey))
+			}
+		case pathLegDoubleAsterisk:
+			buf.WriteString("**")
+		}
+	}
+	return buf.String()
+}
+
 func isBlank(c rune) bool {
 	if c == '\n' || c == '\r' || c == '\t' || c == ' ' {
 		return true
@@ -62,3 +62,20 @@ func (s *testJSONSuite) TestValidatePathExpr(c *C) {
 		}
 	}
 }
+
+func (s *testJSONSuite) TestPathExprToString(c *C) {
+	var tests = []struct {
+		exprString string
+		expected   string
+	}{
+		{`   $  `, "$"},
+		{"   $ .   key1  [  3  ]\t[*].*.key3", "$.key1[3][*].*.key3"},
+		{`$**[1]`, "$**[1]"},
+		{`$."key1 string"."a"`, `$."key1 string".a`},
+	}
+	for _, tt := range tests {
+		pe, err := ParseJSONPathExpr(tt.exprString)
+		c.Assert(err, IsNil)
+		c.Assert(pe.String(), Equals, tt.expected)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Notice"
+	"github.com/pingcap/tipb/go-mysqlx/Session"
+)
+
+// The authentication mechanisms.
+// See https://dev.mysql.com/doc/internals/en/x-protocol-authentication-authentication.html
+const (
+	authMySQL41 = "MYSQL41"
+	authPlain   = "PLAIN"
+)
+
+// defaultCapability is the client capability of the sessions opened by the X Protocol connections.
+const defaultCapability = mysql.ClientProtocol41 | mysql.ClientSecureConnection | mysql.ClientPluginAuth
+
+// authMechanisms returns the authentication mechanisms supported on the connection, the plaintext
+// password is only accepted on secure connections.
+func (cc *clientConn) authMechanisms() []string {
+	if cc.isSecureTransport() {
+		return []string{authMySQL41, authPlain}
+	}
+	return []string{authMySQL41}
+}
+
+// isSecureTransport returns whether the password can be sent in plaintext on the connection.
+func (cc *clientConn) isSecureTransport() bool {
+	return cc.tlsConn != nil || cc.conn.RemoteAddr().Network() == "unix"
+}
+
+// handleAuthenticateStart authenticates the client by the mechanism, the session is opened if it succeeds.
+func (cc *clientConn) handleAuthenticateStart(payload []byte) error {
+	var msg Mysqlx_Session.AuthenticateStart
+	if err := msg.Unmarshal(payload); err != nil {
+		return errXBadMessage
+	}
+	var (
+		authData []byte
+		password string
+		plain    bool
+		err      error
+	)
+	switch msg.GetMechName() {
+	case authMySQL41:
+		// The client responds "schema\0user\0*<40 hex digits of the scramble>" to the salt.
+		if authData, err = cc.authMySQL41(); err != nil {
+			return errors.Trace(err)
+		}
+		if cc.dbname, cc.user, password, err = parseAuthData(authData); err != nil {
+			return errors.Trace(err)
+		}
+		authData = nil
+		if len(password) > 0 {
+			if password[0] != '*' {
+				return errAccessDenied.GenByArgs(cc.user, cc.host(), "YES")
+			}
+			if authData, err = hex.DecodeString(password[1:]); err != nil {
+				return errAccessDenied.GenByArgs(cc.user, cc.host(), "YES")
+			}
+		}
+	case authPlain:
+		// The client sends "schema\0user\0password".
+		if !cc.isSecureTransport() {
+			return errNotSupported.GenByArgs("PLAIN authentication over insecure connections")
+		}
+		if cc.dbname, cc.user, password, err = parseAuthData(msg.GetAuthData()); err != nil {
+			return errors.Trace(err)
+		}
+		plain = true
+	default:
+		return errNotSupported.GenByArgs("Authentication mechanism " + msg.GetMechName())
+	}
+
+	ctx, err := cc.openSessionAndDoAuth(authData, password, plain)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cc.dbname != "" {
+		if _, err = ctx.Execute("use " + quoteIdentifier(cc.dbname)); err != nil {
+			terror.Call(ctx.Close)
+			return errors.Trace(err)
+		}
+	}
+	cc.ctx = ctx
+	if err = cc.writeSessionStateChanged(Mysqlx_Notice.SessionStateChanged_CLIENT_ID_ASSIGNED, uintScalar(uint64(cc.connectionID))); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.writePacket(Mysqlx.ServerMessages_SESS_AUTHENTICATE_OK, &Mysqlx_Session.AuthenticateOk{}))
+}
+
+// authMySQL41 sends the salt to the client and returns the auth data it responds.
+func (cc *clientConn) authMySQL41() ([]byte, error) {
+	challenge := &Mysqlx_Session.AuthenticateContinue{AuthData: cc.salt}
+	if err := cc.writePacket(Mysqlx.ServerMessages_SESS_AUTHENTICATE_CONTINUE, challenge); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := cc.flush(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	tp, payload, err := cc.readPacket()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if tp != Mysqlx.ClientMessages_SESS_AUTHENTICATE_CONTINUE {
+		return nil, errXBadMessage
+	}
+	var msg Mysqlx_Session.AuthenticateContinue
+	if err = msg.Unmarshal(payload); err != nil {
+		return nil, errXBadMessage
+	}
+	return msg.GetAuthData(), nil
+}
+
+// parseAuthData splits the auth data "schema\0user\0password".
+func parseAuthData(data []byte) (schema, user, password string, err error) {
+	parts := bytes.Split(data, []byte{0})
+	if len(parts) != 3 {
+		return "", "", "", errXInvalidProtocolData.GenByArgs("authentication data")
+	}
+	return string(parts[0]), string(parts[1]), string(parts[2]), nil
+}
+
+func (cc *clientConn) host() string {
+	host, _, err := net.SplitHostPort(cc.conn.RemoteAddr().String())
+	if err != nil {
+		return cc.conn.RemoteAddr().String()
+	}
+	return host
+}
+
+// openSessionAndDoAuth opens a session and verifies the scramble, or the plaintext password if plain is true.
+func (cc *clientConn) openSessionAndDoAuth(authData []byte, password string, plain bool) (server.QueryCtx, error) {
+	var tlsStatePtr *tls.ConnectionState
+	if cc.tlsConn != nil {
+		tlsState := cc.tlsConn.ConnectionState()
+		tlsStatePtr = &tlsState
+	}
+	ctx, err := cc.server.driver.OpenCtx(uint64(cc.connectionID), defaultCapability, cc.collation, cc.dbname, tlsStatePtr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if cc.server.cfg.SkipAuth {
+		return ctx, nil
+	}
+	user := &auth.UserIdentity{Username: cc.user, Hostname: cc.host()}
+	var ok bool
+	if !plain {
+		ok = ctx.Auth(user, authData, cc.salt)
+	} else {
+		switch ctx.AuthPlugin(user) {
+		case mysql.AuthCachingSha2Password, mysql.AuthSHA256Password:
+			ok = ctx.Auth(user, []byte(password), nil)
+		default:
+			ok = ctx.Auth(user, scramblePassword(cc.salt, password), cc.salt)
+		}
+	}
+	if !ok {
+		terror.Call(ctx.Close)
+		usingPassword := "NO"
+		if len(authData) > 0 || len(password) > 0 {
+			usingPassword = "YES"
+		}
+		return nil, errAccessDenied.GenByArgs(cc.user, user.Hostname, usingPassword)
+	}
+	return ctx, nil
+}
+
+// scramblePassword computes the mysql_native_password scramble of the plaintext password,
+// SHA1(password) XOR SHA1(salt + SHA1(SHA1(password))).
+func scramblePassword(salt []byte, password string) []byte {
+	if len(password) == 0 {
+		return nil
+	}
+	stage1 := auth.Sha1Hash([]byte(password))
+	h := sha1.New()
+	_, err := h.Write(salt)
+	terror.Log(errors.Trace(err))
+	_, err = h.Write(auth.Sha1Hash(stage1))
+	terror.Log(errors.Trace(err))
+	scramble := h.Sum(nil)
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	return scramble
+}
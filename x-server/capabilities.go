@@ -0,0 +1,138 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Connection"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+)
+
+// The names of the capabilities.
+// See https://dev.mysql.com/doc/internals/en/x-protocol-connection-connection.html
+const (
+	capTLS             = "tls"
+	capAuthMechanisms  = "authentication.mechanisms"
+	capDocFormats      = "doc.formats"
+	capNodeType        = "node_type"
+	capClientPwdExpire = "client.pwd_expire_ok"
+)
+
+// handleCapabilitiesGet sends the capabilities of the server.
+func (cc *clientConn) handleCapabilitiesGet() error {
+	var caps []*Mysqlx_Connection.Capability
+	if cc.server.tlsConfig != nil {
+		caps = append(caps, capability(capTLS, boolScalar(cc.tlsConn != nil)))
+	}
+	mechanisms := &Mysqlx_Datatypes.Array{}
+	for _, mech := range cc.authMechanisms() {
+		mechanisms.Value = append(mechanisms.Value, scalarAny(stringScalar(mech)))
+	}
+	caps = append(caps,
+		&Mysqlx_Connection.Capability{
+			Name:  stringPtr(capAuthMechanisms),
+			Value: &Mysqlx_Datatypes.Any{Type: Mysqlx_Datatypes.Any_ARRAY.Enum(), Array: mechanisms},
+		},
+		capability(capDocFormats, stringScalar("text")),
+		capability(capNodeType, stringScalar("mysql")),
+		capability(capClientPwdExpire, boolScalar(false)),
+	)
+	msg := &Mysqlx_Connection.Capabilities{Capabilities: caps}
+	return errors.Trace(cc.writePacket(Mysqlx.ServerMessages_CONN_CAPABILITIES, msg))
+}
+
+// handleCapabilitiesSet sets the capabilities, the connection is upgraded to TLS after Ok is sent
+// if the tls capability is set.
+func (cc *clientConn) handleCapabilitiesSet(payload []byte) error {
+	var msg Mysqlx_Connection.CapabilitiesSet
+	if err := msg.Unmarshal(payload); err != nil {
+		return errXBadMessage
+	}
+	var upgradeTLS bool
+	for _, c := range msg.GetCapabilities().GetCapabilities() {
+		switch c.GetName() {
+		case capTLS:
+			enabled, ok := anyBool(c.GetValue())
+			if !ok {
+				return errXCapabilitiesPrepareFailed.GenByArgs(capTLS)
+			}
+			if enabled {
+				if cc.server.tlsConfig == nil || cc.tlsConn != nil {
+					return errXCapabilitiesPrepareFailed.GenByArgs(capTLS)
+				}
+				upgradeTLS = true
+			}
+		case capClientPwdExpire:
+			if _, ok := anyBool(c.GetValue()); !ok {
+				return errXCapabilitiesPrepareFailed.GenByArgs(capClientPwdExpire)
+			}
+		default:
+			return errXCapabilityNotFound.GenByArgs(c.GetName())
+		}
+	}
+	if err := cc.writeOk(); err != nil {
+		return errors.Trace(err)
+	}
+	if !upgradeTLS {
+		return nil
+	}
+	if err := cc.flush(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.upgradeToTLS(cc.server.tlsConfig))
+}
+
+func capability(name string, value *Mysqlx_Datatypes.Scalar) *Mysqlx_Connection.Capability {
+	return &Mysqlx_Connection.Capability{Name: stringPtr(name), Value: scalarAny(value)}
+}
+
+// anyBool returns the boolean value of the scalar, integers are accepted as well.
+func anyBool(v *Mysqlx_Datatypes.Any) (bool, bool) {
+	if v.GetType() != Mysqlx_Datatypes.Any_SCALAR {
+		return false, false
+	}
+	switch s := v.GetScalar(); s.GetType() {
+	case Mysqlx_Datatypes.Scalar_V_BOOL:
+		return s.GetVBool(), true
+	case Mysqlx_Datatypes.Scalar_V_SINT:
+		return s.GetVSignedInt() != 0, true
+	case Mysqlx_Datatypes.Scalar_V_UINT:
+		return s.GetVUnsignedInt() != 0, true
+	}
+	return false, false
+}
+
+func scalarAny(s *Mysqlx_Datatypes.Scalar) *Mysqlx_Datatypes.Any {
+	return &Mysqlx_Datatypes.Any{Type: Mysqlx_Datatypes.Any_SCALAR.Enum(), Scalar: s}
+}
+
+func stringScalar(s string) *Mysqlx_Datatypes.Scalar {
+	return &Mysqlx_Datatypes.Scalar{
+		Type:    Mysqlx_Datatypes.Scalar_V_STRING.Enum(),
+		VString: &Mysqlx_Datatypes.Scalar_String{Value: []byte(s)},
+	}
+}
+
+func boolScalar(b bool) *Mysqlx_Datatypes.Scalar {
+	return &Mysqlx_Datatypes.Scalar{Type: Mysqlx_Datatypes.Scalar_V_BOOL.Enum(), VBool: &b}
+}
+
+func uintScalar(v uint64) *Mysqlx_Datatypes.Scalar {
+	return &Mysqlx_Datatypes.Scalar{Type: Mysqlx_Datatypes.Scalar_V_UINT.Enum(), VUnsignedInt: &v}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -18,4 +18,7 @@ type Config struct {
 	Addr     string `json:"addr" toml:"addr"`
 	Socket   string `json:"socket" toml:"socket"`
 	SkipAuth bool   `json:"skip_auth" toml:"skip_auth"`
+	// SSLCert and SSLKey are the certificate and the key to enable the TLS capability.
+	SSLCert string `json:"ssl_cert" toml:"ssl_cert"`
+	SSLKey  string `json:"ssl_key" toml:"ssl_key"`
 }
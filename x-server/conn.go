@@ -14,20 +14,53 @@
 package xserver
 
 import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
 	"io"
 	"net"
+	"runtime"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/server"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Expect"
 )
 
+const (
+	defaultReaderSize = 16 * 1024
+	defaultWriterSize = 16 * 1024
+	// maxMessageSize is the max size of the messages sent by clients, the same as mysqlx_max_allowed_packet.
+	maxMessageSize = 64 * 1024 * 1024
+	// expectNoError is the condition key of Expect.Open to stop executing the messages after an error.
+	expectNoError = 1
+)
+
+// message is implemented by the protobuf messages sent to clients.
+type message interface {
+	Marshal() ([]byte, error)
+}
+
+// expectation is the state of an Expect block.
+type expectation struct {
+	noError bool
+	failed  bool
+}
+
 // clientConn represents a connection between server and client,
 // it maintains connection specific state, handles client query.
 type clientConn struct {
 	conn         net.Conn
+	tlsConn      *tls.Conn       // TLS connection, nil if not TLS.
+	bufReader    *bufio.Reader   // buffered reader of the connection.
+	bufWriter    *bufio.Writer   // buffered writer of the connection.
 	server       *Server         // a reference of server instance.
+	ctx          server.QueryCtx // an interface to execute sql statements.
 	connectionID uint32          // atomically allocated by a global variable, unique in process scope.
 	collation    uint8           // collation used by client, may be different from the collation used by database.
 	user         string          // user of the client.
@@ -35,19 +68,31 @@ type clientConn struct {
 	salt         []byte          // random bytes used for authentication.
 	alloc        arena.Allocator // an memory allocator for reducing memory allocation.
 	killed       bool
+	sendWarnings bool           // whether the warnings of statements are sent as notices.
+	expects      []*expectation // the stack of the open Expect blocks.
+}
+
+func (cc *clientConn) String() string {
+	return fmt.Sprintf("id:%d, addr:%s user:%s",
+		cc.connectionID, cc.conn.RemoteAddr(), cc.user)
 }
 
 func (cc *clientConn) Run() {
+	const size = 4096
 	defer func() {
-		x := recover()
-		if x != nil {
-			log.Error(x)
+		r := recover()
+		if r != nil {
+			buf := make([]byte, size)
+			stackSize := runtime.Stack(buf, false)
+			buf = buf[:stackSize]
+			log.Errorf("[%d] %v, %s", cc.connectionID, r, buf)
 		}
 		err := cc.Close()
 		terror.Log(errors.Trace(err))
 	}()
 
 	for !cc.killed {
+		cc.alloc.Reset()
 		tp, payload, err := cc.readPacket()
 		if err != nil {
 			if terror.ErrorNotEqual(err, io.EOF) {
@@ -57,32 +102,82 @@ func (cc *clientConn) Run() {
 			return
 		}
 		if err = cc.dispatch(tp, payload); err != nil {
-			if terror.ErrorEqual(err, terror.ErrResultUndetermined) {
+			if terror.ErrorEqual(err, io.EOF) {
+				terror.Log(errors.Trace(cc.flush()))
+				return
+			} else if terror.ErrResultUndetermined.Equal(err) {
 				log.Errorf("[%d] result undetermined error, close this connection %s",
 					cc.connectionID, errors.ErrorStack(err))
-			} else if terror.ErrorEqual(err, terror.ErrCritical) {
+				return
+			} else if terror.ErrCritical.Equal(err) {
 				log.Errorf("[%d] critical error, stop the server listener %s",
 					cc.connectionID, errors.ErrorStack(err))
 				select {
 				case cc.server.stopListenerCh <- struct{}{}:
 				default:
 				}
+				return
 			}
 			log.Warnf("[%d] dispatch error: %s, %s", cc.connectionID, cc, err)
-			cc.writeError(err)
+			if err = cc.writeError(err); err != nil {
+				return
+			}
+		}
+		if err = cc.flush(); err != nil {
+			log.Errorf("[%d] write packet error, close this connection %s",
+				cc.connectionID, errors.ErrorStack(err))
 			return
 		}
 	}
 }
 
 func (cc *clientConn) Close() error {
+	if cc.ctx != nil {
+		terror.Log(errors.Trace(cc.ctx.Close()))
+	}
 	err := cc.conn.Close()
 	return errors.Trace(err)
 }
 
+// handshake negotiates the capabilities and authenticates the client. An error is sent to the client
+// if the authentication fails.
 func (cc *clientConn) handshake() error {
-	// TODO: implement it.
-	return nil
+	for {
+		tp, payload, err := cc.readPacket()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		switch tp {
+		case Mysqlx.ClientMessages_CON_CAPABILITIES_GET:
+			err = cc.handleCapabilitiesGet()
+		case Mysqlx.ClientMessages_CON_CAPABILITIES_SET:
+			err = cc.handleCapabilitiesSet(payload)
+		case Mysqlx.ClientMessages_SESS_AUTHENTICATE_START:
+			if err = cc.handleAuthenticateStart(payload); err == nil {
+				return errors.Trace(cc.flush())
+			}
+			terror.Log(errors.Trace(cc.writeError(err)))
+			terror.Log(errors.Trace(cc.flush()))
+			return errors.Trace(err)
+		case Mysqlx.ClientMessages_CON_CLOSE:
+			terror.Log(errors.Trace(cc.writeOk()))
+			terror.Log(errors.Trace(cc.flush()))
+			return errors.Trace(io.EOF)
+		default:
+			err = errXBadMessage
+		}
+		if err != nil {
+			if terror.ErrorEqual(err, io.EOF) {
+				return errors.Trace(err)
+			}
+			if err = cc.writeError(err); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if err = cc.flush(); err != nil {
+			return errors.Trace(err)
+		}
+	}
 }
 
 // readPacket reads a full size request encoded in x protocol.
@@ -91,13 +186,192 @@ func (cc *clientConn) handshake() error {
 // | 4 bytes length | 1 byte type | payload[0:length-1] |
 // ------------------------------------------------------
 // See: https://dev.mysql.com/doc/internals/en/x-protocol-messages-messages.html
-func (cc *clientConn) readPacket() (byte, []byte, error) {
-	return 0x00, nil, nil
+func (cc *clientConn) readPacket() (Mysqlx.ClientMessages_Type, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(cc.bufReader, header[:]); err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	length := binary.LittleEndian.Uint32(header[:4])
+	if length == 0 || length > maxMessageSize {
+		return 0, nil, errXBadMessage
+	}
+	payload := cc.alloc.AllocWithLen(int(length-1), int(length-1))
+	if _, err := io.ReadFull(cc.bufReader, payload); err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	return Mysqlx.ClientMessages_Type(header[4]), payload, nil
+}
+
+// writePacket writes a message into the buffer, the buffer is sent to the client by flush.
+func (cc *clientConn) writePacket(tp Mysqlx.ServerMessages_Type, msg message) error {
+	var (
+		data []byte
+		err  error
+	)
+	if msg != nil {
+		if data, err = msg.Marshal(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	var header [5]byte
+	binary.LittleEndian.PutUint32(header[:4], uint32(len(data)+1))
+	header[4] = byte(tp)
+	if _, err = cc.bufWriter.Write(header[:]); err != nil {
+		return errors.Trace(err)
+	}
+	_, err = cc.bufWriter.Write(data)
+	return errors.Trace(err)
+}
+
+func (cc *clientConn) flush() error {
+	return errors.Trace(cc.bufWriter.Flush())
+}
+
+func (cc *clientConn) writeOk() error {
+	return errors.Trace(cc.writePacket(Mysqlx.ServerMessages_OK, &Mysqlx.Ok{}))
 }
 
-func (cc *clientConn) dispatch(tp byte, payload []byte) error {
+// upgradeToTLS runs the TLS handshake on the connection, the buffered reader and writer are replaced.
+func (cc *clientConn) upgradeToTLS(tlsConfig *tls.Config) error {
+	tlsConn := tls.Server(cc.conn, tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return errors.Trace(err)
+	}
+	cc.conn = tlsConn
+	cc.tlsConn = tlsConn
+	cc.bufReader = bufio.NewReaderSize(tlsConn, defaultReaderSize)
+	cc.bufWriter = bufio.NewWriterSize(tlsConn, defaultWriterSize)
 	return nil
 }
 
-func (cc *clientConn) writeError(e error) {
+// dispatch handles the message from the client. The error is returned to be sent to the client,
+// and the connection is closed if io.EOF is returned.
+func (cc *clientConn) dispatch(tp Mysqlx.ClientMessages_Type, payload []byte) error {
+	switch tp {
+	case Mysqlx.ClientMessages_EXPECT_OPEN:
+		return errors.Trace(cc.handleExpectOpen(payload))
+	case Mysqlx.ClientMessages_EXPECT_CLOSE:
+		return errors.Trace(cc.handleExpectClose())
+	}
+	// The messages in a failed no_error Expect block are not executed.
+	if n := len(cc.expects); n > 0 && cc.expects[n-1].failed {
+		return errXExpectNoErrorFailed
+	}
+	err := cc.dispatchMessage(tp, payload)
+	if err != nil && terror.ErrorNotEqual(err, io.EOF) {
+		cc.failExpectation()
+	}
+	return errors.Trace(err)
+}
+
+func (cc *clientConn) dispatchMessage(tp Mysqlx.ClientMessages_Type, payload []byte) error {
+	switch tp {
+	case Mysqlx.ClientMessages_SQL_STMT_EXECUTE:
+		return errors.Trace(cc.handleStmtExecute(payload))
+	case Mysqlx.ClientMessages_CRUD_FIND:
+		return errors.Trace(cc.handleFind(payload))
+	case Mysqlx.ClientMessages_CRUD_INSERT:
+		return errors.Trace(cc.handleInsert(payload))
+	case Mysqlx.ClientMessages_CRUD_UPDATE:
+		return errors.Trace(cc.handleUpdate(payload))
+	case Mysqlx.ClientMessages_CRUD_DELETE:
+		return errors.Trace(cc.handleDelete(payload))
+	case Mysqlx.ClientMessages_SESS_RESET:
+		// The session is closed, the client needs to authenticate again.
+		terror.Log(errors.Trace(cc.ctx.Close()))
+		cc.ctx = nil
+		cc.expects = nil
+		if err := cc.writeOk(); err != nil {
+			return errors.Trace(err)
+		}
+		if err := cc.flush(); err != nil {
+			return errors.Trace(err)
+		}
+		if err := cc.handshake(); err != nil {
+			// The error is sent to the client by handshake.
+			log.Infof("[%d] handshake error %s", cc.connectionID, errors.ErrorStack(err))
+			return io.EOF
+		}
+		return nil
+	case Mysqlx.ClientMessages_SESS_CLOSE, Mysqlx.ClientMessages_CON_CLOSE:
+		if err := cc.writeOk(); err != nil {
+			return errors.Trace(err)
+		}
+		return io.EOF
+	case Mysqlx.ClientMessages_CON_CAPABILITIES_GET, Mysqlx.ClientMessages_CON_CAPABILITIES_SET,
+		Mysqlx.ClientMessages_SESS_AUTHENTICATE_START, Mysqlx.ClientMessages_SESS_AUTHENTICATE_CONTINUE:
+		// These messages are only expected before the authentication.
+		return errXBadMessage
+	}
+	return errNotSupported.GenByArgs(fmt.Sprintf("Message type %d", tp))
+}
+
+// handleExpectOpen opens an Expect block, only the no_error condition is supported.
+// See https://dev.mysql.com/doc/internals/en/x-protocol-expect-expectations.html
+func (cc *clientConn) handleExpectOpen(payload []byte) error {
+	var msg Mysqlx_Expect.Open
+	if err := msg.Unmarshal(payload); err != nil {
+		return errXBadMessage
+	}
+	exp := &expectation{}
+	if n := len(cc.expects); n > 0 && msg.GetOp() == Mysqlx_Expect.Open_EXPECT_CTX_COPY_PREV {
+		*exp = *cc.expects[n-1]
+	}
+	cc.expects = append(cc.expects, exp)
+	for _, cond := range msg.GetCond() {
+		if cond.GetConditionKey() != expectNoError {
+			exp.failed = true
+			return errXExpectBadCondition.GenByArgs(cond.GetConditionKey())
+		}
+		exp.noError = cond.GetOp() == Mysqlx_Expect.Open_Condition_EXPECT_OP_SET
+	}
+	if exp.failed {
+		return errXExpectNoErrorFailed
+	}
+	return errors.Trace(cc.writeOk())
+}
+
+// handleExpectClose closes the innermost Expect block, the failure is propagated to the outer block.
+func (cc *clientConn) handleExpectClose() error {
+	n := len(cc.expects)
+	if n == 0 {
+		return errXExpectNotOpen
+	}
+	exp := cc.expects[n-1]
+	cc.expects = cc.expects[:n-1]
+	if exp.failed {
+		cc.failExpectation()
+		return errXExpectNoErrorFailed
+	}
+	return errors.Trace(cc.writeOk())
+}
+
+// failExpectation marks the innermost Expect block failed if it expects no error.
+func (cc *clientConn) failExpectation() {
+	if n := len(cc.expects); n > 0 && cc.expects[n-1].noError {
+		cc.expects[n-1].failed = true
+	}
+}
+
+// writeError writes the error to the client as a Mysqlx.Error message.
+func (cc *clientConn) writeError(e error) error {
+	var (
+		m  *mysql.SQLError
+		te *terror.Error
+		ok bool
+	)
+	originErr := errors.Cause(e)
+	if te, ok = originErr.(*terror.Error); ok {
+		m = te.ToSQLError()
+	} else {
+		m = mysql.NewErrf(mysql.ErrUnknown, "%s", e.Error())
+	}
+	code := uint32(m.Code)
+	msg := &Mysqlx.Error{
+		Severity: Mysqlx.Error_ERROR.Enum(),
+		Code:     &code,
+		SqlState: &m.State,
+		Msg:      &m.Message,
+	}
+	return errors.Trace(cc.writePacket(Mysqlx.ServerMessages_ERROR, msg))
 }
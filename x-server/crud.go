@@ -0,0 +1,462 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tipb/go-mysqlx/Crud"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Expr"
+)
+
+// The CRUD messages are translated into SQL statements on the collection tables created by
+// create_collection, or on the ordinary tables in the table data model.
+// See https://dev.mysql.com/doc/internals/en/x-protocol-crud-crud.html
+
+// idMember is the member of the documents stored in the _id column.
+const idMember = "_id"
+
+func (cc *clientConn) handleFind(payload []byte) error {
+	var msg Mysqlx_Crud.Find
+	if err := msg.Unmarshal(payload); err != nil {
+		return errXBadMessage
+	}
+	sql, err := buildFind(&msg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+func (cc *clientConn) handleInsert(payload []byte) error {
+	var msg Mysqlx_Crud.Insert
+	if err := msg.Unmarshal(payload); err != nil {
+		return errXBadMessage
+	}
+	sql, err := buildInsert(&msg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+func (cc *clientConn) handleUpdate(payload []byte) error {
+	var msg Mysqlx_Crud.Update
+	if err := msg.Unmarshal(payload); err != nil {
+		return errXBadMessage
+	}
+	sql, err := buildUpdate(&msg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+func (cc *clientConn) handleDelete(payload []byte) error {
+	var msg Mysqlx_Crud.Delete
+	if err := msg.Unmarshal(payload); err != nil {
+		return errXBadMessage
+	}
+	sql, err := buildDelete(&msg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+func isDocumentModel(model Mysqlx_Crud.DataModel) bool {
+	return model != Mysqlx_Crud.DataModel_TABLE
+}
+
+// crudBuilder builds the SQL statement of a CRUD message.
+type crudBuilder struct {
+	buf        bytes.Buffer
+	args       []*Mysqlx_Datatypes.Scalar
+	isDocument bool
+}
+
+func newCrudBuilder(args []*Mysqlx_Datatypes.Scalar, model Mysqlx_Crud.DataModel) *crudBuilder {
+	return &crudBuilder{args: args, isDocument: isDocumentModel(model)}
+}
+
+func (b *crudBuilder) expr(e *Mysqlx_Expr.Expr) error {
+	s, err := newExprGenerator(b.args, b.isDocument).generate(e)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	b.buf.WriteString(s)
+	return nil
+}
+
+func (b *crudBuilder) collection(c *Mysqlx_Crud.Collection) error {
+	if c.GetName() == "" {
+		return errXInvalidCollection
+	}
+	if c.GetSchema() != "" {
+		b.buf.WriteString(quoteIdentifier(c.GetSchema()))
+		b.buf.WriteByte('.')
+	}
+	b.buf.WriteString(quoteIdentifier(c.GetName()))
+	return nil
+}
+
+func (b *crudBuilder) where(criteria *Mysqlx_Expr.Expr) error {
+	if criteria == nil {
+		return nil
+	}
+	b.buf.WriteString(" WHERE ")
+	return errors.Trace(b.expr(criteria))
+}
+
+func (b *crudBuilder) orderBy(orders []*Mysqlx_Crud.Order) error {
+	for i, order := range orders {
+		if i == 0 {
+			b.buf.WriteString(" ORDER BY ")
+		} else {
+			b.buf.WriteByte(',')
+		}
+		if err := b.expr(order.GetExpr()); err != nil {
+			return errors.Trace(err)
+		}
+		if order.GetDirection() == Mysqlx_Crud.Order_DESC {
+			b.buf.WriteString(" DESC")
+		}
+	}
+	return nil
+}
+
+// limit writes the LIMIT clause, the offset is only allowed if allowOffset is true.
+func (b *crudBuilder) limit(limit *Mysqlx_Crud.Limit, allowOffset bool) error {
+	if limit == nil {
+		return nil
+	}
+	b.buf.WriteString(" LIMIT ")
+	if limit.GetOffset() != 0 {
+		if !allowOffset {
+			return errXInvalidArgument.GenByArgs("non-zero offset value not allowed for this operation")
+		}
+		b.buf.WriteString(strconv.FormatUint(limit.GetOffset(), 10))
+		b.buf.WriteByte(',')
+	}
+	b.buf.WriteString(strconv.FormatUint(limit.GetRowCount(), 10))
+	return nil
+}
+
+// buildFind translates Crud.Find into a SELECT statement, the projection of documents is built by JSON_OBJECT.
+func buildFind(msg *Mysqlx_Crud.Find) (string, error) {
+	b := newCrudBuilder(msg.GetArgs(), msg.GetDataModel())
+	b.buf.WriteString("SELECT ")
+	if err := b.projection(msg.GetProjection()); err != nil {
+		return "", errors.Trace(err)
+	}
+	b.buf.WriteString(" FROM ")
+	if err := b.collection(msg.GetCollection()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.where(msg.GetCriteria()); err != nil {
+		return "", errors.Trace(err)
+	}
+	for i, e := range msg.GetGrouping() {
+		if i == 0 {
+			b.buf.WriteString(" GROUP BY ")
+		} else {
+			b.buf.WriteByte(',')
+		}
+		if err := b.expr(e); err != nil {
+			return "", errors.Trace(err)
+		}
+	}
+	if msg.GetGroupingCriteria() != nil {
+		b.buf.WriteString(" HAVING ")
+		if err := b.expr(msg.GetGroupingCriteria()); err != nil {
+			return "", errors.Trace(err)
+		}
+	}
+	if err := b.orderBy(msg.GetOrder()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.limit(msg.GetLimit(), true); err != nil {
+		return "", errors.Trace(err)
+	}
+	return b.buf.String(), nil
+}
+
+func (b *crudBuilder) projection(projs []*Mysqlx_Crud.Projection) error {
+	if !b.isDocument {
+		if len(projs) == 0 {
+			b.buf.WriteByte('*')
+			return nil
+		}
+		for i, proj := range projs {
+			if i > 0 {
+				b.buf.WriteByte(',')
+			}
+			if err := b.expr(proj.GetSource()); err != nil {
+				return errors.Trace(err)
+			}
+			if proj.GetAlias() != "" {
+				b.buf.WriteString(" AS ")
+				b.buf.WriteString(quoteIdentifier(proj.GetAlias()))
+			}
+		}
+		return nil
+	}
+
+	if len(projs) == 0 {
+		b.buf.WriteString(docColumn)
+		return nil
+	}
+	// An object expression is the projection of the whole document.
+	if len(projs) == 1 && projs[0].GetAlias() == "" && projs[0].GetSource().GetType() == Mysqlx_Expr.Expr_OBJECT {
+		if err := b.expr(projs[0].GetSource()); err != nil {
+			return errors.Trace(err)
+		}
+		b.buf.WriteString(" AS " + docColumn)
+		return nil
+	}
+	b.buf.WriteString("JSON_OBJECT(")
+	for i, proj := range projs {
+		if i > 0 {
+			b.buf.WriteByte(',')
+		}
+		alias := proj.GetAlias()
+		if alias == "" {
+			// The alias defaults to the last member of the document path.
+			path := proj.GetSource().GetIdentifier().GetDocumentPath()
+			if n := len(path); n > 0 && path[n-1].GetType() == Mysqlx_Expr.DocumentPathItem_MEMBER {
+				alias = path[n-1].GetValue()
+			}
+		}
+		if alias == "" {
+			return errXProjBadKeyName
+		}
+		b.buf.WriteString(quoteString(alias))
+		b.buf.WriteByte(',')
+		if err := b.expr(proj.GetSource()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	b.buf.WriteString(") AS " + docColumn)
+	return nil
+}
+
+// buildInsert translates Crud.Insert into an INSERT statement. The _id member is generated for the documents
+// without it.
+func buildInsert(msg *Mysqlx_Crud.Insert) (string, error) {
+	b := newCrudBuilder(msg.GetArgs(), msg.GetDataModel())
+	b.buf.WriteString("INSERT INTO ")
+	if err := b.collection(msg.GetCollection()); err != nil {
+		return "", errors.Trace(err)
+	}
+	numFields := len(msg.GetProjection())
+	if b.isDocument {
+		if numFields != 0 {
+			return "", errXBadProjection
+		}
+		numFields = 1
+		b.buf.WriteString(" (" + docColumn + ")")
+	} else if numFields > 0 {
+		b.buf.WriteString(" (")
+		for i, col := range msg.GetProjection() {
+			if i > 0 {
+				b.buf.WriteByte(',')
+			}
+			if col.GetName() == "" {
+				return "", errXBadColumnToUpdate
+			}
+			b.buf.WriteString(quoteIdentifier(col.GetName()))
+		}
+		b.buf.WriteByte(')')
+	}
+	if len(msg.GetRow()) == 0 {
+		return "", errXBadInsertData
+	}
+	b.buf.WriteString(" VALUES ")
+	for i, row := range msg.GetRow() {
+		fields := row.GetField()
+		if len(fields) == 0 || numFields > 0 && len(fields) != numFields {
+			return "", errXBadInsertData
+		}
+		if i > 0 {
+			b.buf.WriteByte(',')
+		}
+		b.buf.WriteByte('(')
+		for j, field := range fields {
+			if j > 0 {
+				b.buf.WriteByte(',')
+			}
+			if b.isDocument {
+				if err := b.document(field); err != nil {
+					return "", errors.Trace(err)
+				}
+			} else if err := b.expr(field); err != nil {
+				return "", errors.Trace(err)
+			}
+		}
+		b.buf.WriteByte(')')
+	}
+	return b.buf.String(), nil
+}
+
+// document writes the document to insert, the _id member is set to a UUID if it's missing.
+func (b *crudBuilder) document(e *Mysqlx_Expr.Expr) error {
+	doc, err := newExprGenerator(b.args, b.isDocument).generate(e)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	id := "'$." + idMember + "'"
+	b.buf.WriteString("(SELECT IF(JSON_EXTRACT(d," + id + ") IS NULL, JSON_SET(d," + id + ",REPLACE(UUID(),'-','')), d) FROM (SELECT CAST(")
+	b.buf.WriteString(doc)
+	b.buf.WriteString(" AS JSON) AS d) AS t)")
+	return nil
+}
+
+// buildUpdate translates Crud.Update into an UPDATE statement. In the document data model, the operations are
+// nested JSON function calls on the doc column.
+func buildUpdate(msg *Mysqlx_Crud.Update) (string, error) {
+	b := newCrudBuilder(msg.GetArgs(), msg.GetDataModel())
+	b.buf.WriteString("UPDATE ")
+	if err := b.collection(msg.GetCollection()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(msg.GetOperation()) == 0 {
+		return "", errXInvalidArgument.GenByArgs("no update operations")
+	}
+	// The columns are updated in the order they first appear in the operations.
+	var (
+		columns []string
+		values  = make(map[string]string)
+	)
+	for _, op := range msg.GetOperation() {
+		column, value, err := b.updateOperation(op, values)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		if _, ok := values[column]; !ok {
+			columns = append(columns, column)
+		}
+		values[column] = value
+	}
+	b.buf.WriteString(" SET ")
+	for i, column := range columns {
+		if i > 0 {
+			b.buf.WriteByte(',')
+		}
+		b.buf.WriteString(column)
+		b.buf.WriteByte('=')
+		b.buf.WriteString(values[column])
+	}
+	if err := b.where(msg.GetCriteria()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.orderBy(msg.GetOrder()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.limit(msg.GetLimit(), false); err != nil {
+		return "", errors.Trace(err)
+	}
+	return b.buf.String(), nil
+}
+
+// updateOperation returns the column to update and its new value after the operation,
+// values holds the values of the columns after the previous operations.
+func (b *crudBuilder) updateOperation(op *Mysqlx_Crud.UpdateOperation, values map[string]string) (string, string, error) {
+	source := op.GetSource()
+	var column string
+	if b.isDocument {
+		if source.GetName() != "" || source.GetTableName() != "" || source.GetSchemaName() != "" {
+			return "", "", errXBadColumnToUpdate
+		}
+		column = docColumn
+	} else {
+		if source.GetName() == "" || source.GetTableName() != "" || source.GetSchemaName() != "" {
+			return "", "", errXBadColumnToUpdate
+		}
+		column = quoteIdentifier(source.GetName())
+	}
+	var value string
+	if op.GetOperation() != Mysqlx_Crud.UpdateOperation_ITEM_REMOVE {
+		var err error
+		if value, err = newExprGenerator(b.args, b.isDocument).generate(op.GetValue()); err != nil {
+			return "", "", errors.Trace(err)
+		}
+	}
+
+	if op.GetOperation() == Mysqlx_Crud.UpdateOperation_SET {
+		if b.isDocument || len(source.GetDocumentPath()) > 0 {
+			return "", "", errXBadTypeOfUpdate.GenByArgs("collection")
+		}
+		return column, value, nil
+	}
+
+	items := source.GetDocumentPath()
+	if b.isDocument {
+		if len(items) == 0 && op.GetOperation() != Mysqlx_Crud.UpdateOperation_ITEM_MERGE {
+			return "", "", errXBadDocPath
+		}
+		if len(items) > 0 && items[0].GetType() == Mysqlx_Expr.DocumentPathItem_MEMBER && items[0].GetValue() == idMember {
+			return "", "", errXBadMemberToUpdate.GenByArgs(idMember)
+		}
+	}
+	path, err := documentPath(items)
+	if err != nil {
+		return "", "", errors.Trace(err)
+	}
+	target, ok := values[column]
+	if !ok {
+		target = column
+	}
+	switch op.GetOperation() {
+	case Mysqlx_Crud.UpdateOperation_ITEM_REMOVE:
+		return column, "JSON_REMOVE(" + target + "," + quoteString(path) + ")", nil
+	case Mysqlx_Crud.UpdateOperation_ITEM_MERGE:
+		return column, "JSON_MERGE(" + target + "," + value + ")", nil
+	}
+	var fn string
+	switch op.GetOperation() {
+	case Mysqlx_Crud.UpdateOperation_ITEM_SET:
+		fn = "JSON_SET"
+	case Mysqlx_Crud.UpdateOperation_ITEM_REPLACE:
+		fn = "JSON_REPLACE"
+	case Mysqlx_Crud.UpdateOperation_ARRAY_INSERT:
+		fn = "JSON_ARRAY_INSERT"
+	case Mysqlx_Crud.UpdateOperation_ARRAY_APPEND:
+		fn = "JSON_ARRAY_APPEND"
+	default:
+		return "", "", errXBadTypeOfUpdate.GenByArgs(op.GetOperation().String())
+	}
+	return column, fn + "(" + target + "," + quoteString(path) + "," + value + ")", nil
+}
+
+// buildDelete translates Crud.Delete into a DELETE statement.
+func buildDelete(msg *Mysqlx_Crud.Delete) (string, error) {
+	b := newCrudBuilder(msg.GetArgs(), msg.GetDataModel())
+	b.buf.WriteString("DELETE FROM ")
+	if err := b.collection(msg.GetCollection()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.where(msg.GetCriteria()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.orderBy(msg.GetOrder()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.limit(msg.GetLimit(), false); err != nil {
+		return "", errors.Trace(err)
+	}
+	return b.buf.String(), nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tipb/go-mysqlx/Crud"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Expr"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testCrudSuite{})
+
+type testCrudSuite struct{}
+
+func docPath(members ...string) *Mysqlx_Expr.Expr {
+	id := &Mysqlx_Expr.ColumnIdentifier{}
+	for _, m := range members {
+		id.DocumentPath = append(id.DocumentPath, &Mysqlx_Expr.DocumentPathItem{
+			Type:  Mysqlx_Expr.DocumentPathItem_MEMBER.Enum(),
+			Value: stringPtr(m),
+		})
+	}
+	return &Mysqlx_Expr.Expr{Type: Mysqlx_Expr.Expr_IDENT.Enum(), Identifier: id}
+}
+
+func column(name string) *Mysqlx_Expr.Expr {
+	return &Mysqlx_Expr.Expr{
+		Type:       Mysqlx_Expr.Expr_IDENT.Enum(),
+		Identifier: &Mysqlx_Expr.ColumnIdentifier{Name: stringPtr(name)},
+	}
+}
+
+func literal(s *Mysqlx_Datatypes.Scalar) *Mysqlx_Expr.Expr {
+	return &Mysqlx_Expr.Expr{Type: Mysqlx_Expr.Expr_LITERAL.Enum(), Literal: s}
+}
+
+func intScalar(v int64) *Mysqlx_Datatypes.Scalar {
+	return &Mysqlx_Datatypes.Scalar{Type: Mysqlx_Datatypes.Scalar_V_SINT.Enum(), VSignedInt: &v}
+}
+
+func placeholder(pos uint32) *Mysqlx_Expr.Expr {
+	return &Mysqlx_Expr.Expr{Type: Mysqlx_Expr.Expr_PLACEHOLDER.Enum(), Position: &pos}
+}
+
+func operator(name string, params ...*Mysqlx_Expr.Expr) *Mysqlx_Expr.Expr {
+	return &Mysqlx_Expr.Expr{
+		Type:     Mysqlx_Expr.Expr_OPERATOR.Enum(),
+		Operator: &Mysqlx_Expr.Operator{Name: stringPtr(name), Param: params},
+	}
+}
+
+func (s *testCrudSuite) TestExpr(c *C) {
+	defer testleak.AfterTest(c)()
+	args := []*Mysqlx_Datatypes.Scalar{stringScalar("it's"), intScalar(-3)}
+	tests := []struct {
+		expr *Mysqlx_Expr.Expr
+		sql  string
+	}{
+		{docPath("name"), "JSON_EXTRACT(doc,'$.name')"},
+		{docPath("a b", "c"), `JSON_EXTRACT(doc,'$."a b".c')`},
+		{column("a`b"), "`a``b`"},
+		{literal(stringScalar("a'\\\n")), `'a\'\\\n'`},
+		{literal(boolScalar(true)), "TRUE"},
+		{literal(&Mysqlx_Datatypes.Scalar{Type: Mysqlx_Datatypes.Scalar_V_NULL.Enum()}), "NULL"},
+		{placeholder(0), `'it\'s'`},
+		{operator("==", docPath("age"), placeholder(1)), "(JSON_EXTRACT(doc,'$.age') = -3)"},
+		{operator("&&", operator("not", column("a")), operator("is_not", column("b"), literal(boolScalar(false)))),
+			"((NOT `a`) AND (`b` IS NOT FALSE))"},
+		{operator("in", column("a"), literal(intScalar(1)), literal(intScalar(2))), "(`a` IN (1,2))"},
+		{operator("not_like", column("a"), literal(stringScalar("x%")), literal(stringScalar("!"))), "(`a` NOT LIKE 'x%' ESCAPE '!')"},
+		{operator("between", column("a"), literal(intScalar(1)), literal(intScalar(2))), "(`a` BETWEEN 1 AND 2)"},
+		{operator("cast", column("a"), literal(stringScalar("unsigned integer"))), "CAST(`a` AS UNSIGNED INTEGER)"},
+		{operator("date_add", column("a"), literal(intScalar(1)), literal(stringScalar("day"))), "DATE_ADD(`a`, INTERVAL 1 DAY)"},
+		{&Mysqlx_Expr.Expr{
+			Type: Mysqlx_Expr.Expr_FUNC_CALL.Enum(),
+			FunctionCall: &Mysqlx_Expr.FunctionCall{
+				Name:  &Mysqlx_Expr.Identifier{Name: stringPtr("concat")},
+				Param: []*Mysqlx_Expr.Expr{column("a"), literal(stringScalar("b"))},
+			},
+		}, "CONCAT(`a`,'b')"},
+		{&Mysqlx_Expr.Expr{
+			Type: Mysqlx_Expr.Expr_OBJECT.Enum(),
+			Object: &Mysqlx_Expr.Object{Fld: []*Mysqlx_Expr.Object_ObjectField{
+				{Key: stringPtr("x"), Value: &Mysqlx_Expr.Expr{
+					Type:  Mysqlx_Expr.Expr_ARRAY.Enum(),
+					Array: &Mysqlx_Expr.Array{Value: []*Mysqlx_Expr.Expr{literal(intScalar(1)), docPath("y")}},
+				}},
+			}},
+		}, "JSON_OBJECT('x',JSON_ARRAY(1,JSON_EXTRACT(doc,'$.y')))"},
+	}
+	for _, t := range tests {
+		sql, err := newExprGenerator(args, true).generate(t.expr)
+		c.Assert(err, IsNil)
+		c.Assert(sql, Equals, t.sql)
+	}
+
+	errTests := []struct {
+		expr *Mysqlx_Expr.Expr
+		code terror.ErrCode
+	}{
+		{placeholder(2), codeXExprMissingArg},
+		{operator("==", column("a")), codeXExprBadNumArgs},
+		{operator("nop", column("a")), codeXExprBadOperator},
+		{operator("cast", column("a"), literal(stringScalar("int); drop table t"))), codeXExprBadValue},
+		{&Mysqlx_Expr.Expr{
+			Type:         Mysqlx_Expr.Expr_FUNC_CALL.Enum(),
+			FunctionCall: &Mysqlx_Expr.FunctionCall{Name: &Mysqlx_Expr.Identifier{Name: stringPtr("f()")}},
+		}, codeXExprBadValue},
+	}
+	for _, t := range errTests {
+		_, err := newExprGenerator(args, true).generate(t.expr)
+		c.Assert(terror.ErrorEqual(err, terror.ClassXServer.New(t.code, "")), IsTrue, Commentf("%v", err))
+	}
+}
+
+func (s *testCrudSuite) TestBuildCrud(c *C) {
+	defer testleak.AfterTest(c)()
+	coll := &Mysqlx_Crud.Collection{Schema: stringPtr("test"), Name: stringPtr("coll")}
+	var rowCount, offset uint64 = 10, 5
+
+	sql, err := buildFind(&Mysqlx_Crud.Find{
+		Collection: coll,
+		Projection: []*Mysqlx_Crud.Projection{
+			{Source: docPath("name")},
+			{Source: operator("+", docPath("age"), literal(intScalar(1))), Alias: stringPtr("next")},
+		},
+		Criteria: operator(">", docPath("age"), placeholder(0)),
+		Args:     []*Mysqlx_Datatypes.Scalar{intScalar(18)},
+		Order:    []*Mysqlx_Crud.Order{{Expr: docPath("age"), Direction: Mysqlx_Crud.Order_DESC.Enum()}},
+		Limit:    &Mysqlx_Crud.Limit{RowCount: &rowCount, Offset: &offset},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(sql, Equals, "SELECT JSON_OBJECT('name',JSON_EXTRACT(doc,'$.name'),'next',(JSON_EXTRACT(doc,'$.age') + 1)) AS doc "+
+		"FROM `test`.`coll` WHERE (JSON_EXTRACT(doc,'$.age') > 18) ORDER BY JSON_EXTRACT(doc,'$.age') DESC LIMIT 5,10")
+
+	_, err = buildFind(&Mysqlx_Crud.Find{
+		Collection: coll,
+		Projection: []*Mysqlx_Crud.Projection{{Source: literal(intScalar(1))}},
+	})
+	c.Assert(terror.ErrorEqual(err, errXProjBadKeyName), IsTrue)
+
+	sql, err = buildFind(&Mysqlx_Crud.Find{
+		Collection: &Mysqlx_Crud.Collection{Name: stringPtr("t")},
+		DataModel:  Mysqlx_Crud.DataModel_TABLE.Enum(),
+		Projection: []*Mysqlx_Crud.Projection{{Source: column("a"), Alias: stringPtr("b")}},
+		Grouping:   []*Mysqlx_Expr.Expr{column("a")},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(sql, Equals, "SELECT `a` AS `b` FROM `t` GROUP BY `a`")
+
+	sql, err = buildInsert(&Mysqlx_Crud.Insert{
+		Collection: coll,
+		Row: []*Mysqlx_Crud.Insert_TypedRow{
+			{Field: []*Mysqlx_Expr.Expr{literal(stringScalar(`{"a":1}`))}},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(sql, Equals, "INSERT INTO `test`.`coll` (doc) VALUES ((SELECT IF(JSON_EXTRACT(d,'$._id') IS NULL, "+
+		`JSON_SET(d,'$._id',REPLACE(UUID(),'-','')), d) FROM (SELECT CAST('{"a":1}' AS JSON) AS d) AS t))`)
+
+	sql, err = buildInsert(&Mysqlx_Crud.Insert{
+		Collection: &Mysqlx_Crud.Collection{Name: stringPtr("t")},
+		DataModel:  Mysqlx_Crud.DataModel_TABLE.Enum(),
+		Projection: []*Mysqlx_Crud.Column{{Name: stringPtr("a")}, {Name: stringPtr("b")}},
+		Row: []*Mysqlx_Crud.Insert_TypedRow{
+			{Field: []*Mysqlx_Expr.Expr{literal(intScalar(1)), literal(stringScalar("x"))}},
+			{Field: []*Mysqlx_Expr.Expr{literal(intScalar(2)), literal(stringScalar("y"))}},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(sql, Equals, "INSERT INTO `t` (`a`,`b`) VALUES (1,'x'),(2,'y')")
+
+	_, err = buildInsert(&Mysqlx_Crud.Insert{
+		Collection: &Mysqlx_Crud.Collection{Name: stringPtr("t")},
+		DataModel:  Mysqlx_Crud.DataModel_TABLE.Enum(),
+		Projection: []*Mysqlx_Crud.Column{{Name: stringPtr("a")}, {Name: stringPtr("b")}},
+		Row:        []*Mysqlx_Crud.Insert_TypedRow{{Field: []*Mysqlx_Expr.Expr{literal(intScalar(1))}}},
+	})
+	c.Assert(terror.ErrorEqual(err, errXBadInsertData), IsTrue)
+
+	itemSet := Mysqlx_Crud.UpdateOperation_ITEM_SET
+	itemRemove := Mysqlx_Crud.UpdateOperation_ITEM_REMOVE
+	sql, err = buildUpdate(&Mysqlx_Crud.Update{
+		Collection: coll,
+		Criteria:   operator("==", docPath("_id"), literal(stringScalar("1"))),
+		Operation: []*Mysqlx_Crud.UpdateOperation{
+			{Source: docPath("age").Identifier, Operation: &itemSet, Value: literal(intScalar(10))},
+			{Source: docPath("name").Identifier, Operation: &itemRemove},
+		},
+		Limit: &Mysqlx_Crud.Limit{RowCount: &rowCount},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(sql, Equals, "UPDATE `test`.`coll` SET doc=JSON_REMOVE(JSON_SET(doc,'$.age',10),'$.name') "+
+		"WHERE (JSON_EXTRACT(doc,'$._id') = '1') LIMIT 10")
+
+	_, err = buildUpdate(&Mysqlx_Crud.Update{
+		Collection: coll,
+		Operation: []*Mysqlx_Crud.UpdateOperation{
+			{Source: docPath("_id").Identifier, Operation: &itemSet, Value: literal(intScalar(10))},
+		},
+	})
+	c.Assert(terror.ErrorEqual(err, errXBadMemberToUpdate), IsTrue)
+
+	set := Mysqlx_Crud.UpdateOperation_SET
+	sql, err = buildUpdate(&Mysqlx_Crud.Update{
+		Collection: &Mysqlx_Crud.Collection{Name: stringPtr("t")},
+		DataModel:  Mysqlx_Crud.DataModel_TABLE.Enum(),
+		Operation: []*Mysqlx_Crud.UpdateOperation{
+			{Source: column("a").Identifier, Operation: &set, Value: operator("+", column("a"), literal(intScalar(1)))},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(sql, Equals, "UPDATE `t` SET `a`=(`a` + 1)")
+
+	_, err = buildDelete(&Mysqlx_Crud.Delete{
+		Collection: coll,
+		Limit:      &Mysqlx_Crud.Limit{RowCount: &rowCount, Offset: &offset},
+	})
+	c.Assert(terror.ErrorEqual(err, errXInvalidArgument), IsTrue)
+	sql, err = buildDelete(&Mysqlx_Crud.Delete{
+		Collection: coll,
+		Criteria:   operator("like", docPath("name"), literal(stringScalar("a%"))),
+		Order:      []*Mysqlx_Crud.Order{{Expr: docPath("name")}},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(sql, Equals, "DELETE FROM `test`.`coll` WHERE (JSON_EXTRACT(doc,'$.name') LIKE 'a%') ORDER BY JSON_EXTRACT(doc,'$.name')")
+}
+
+func (s *testCrudSuite) TestBindArgs(c *C) {
+	defer testleak.AfterTest(c)()
+	args := []*Mysqlx_Datatypes.Any{scalarAny(intScalar(1)), scalarAny(stringScalar("x"))}
+	sql, err := bindArgs("select ?, '?', `?`, \"\\\"?\", ?", args)
+	c.Assert(err, IsNil)
+	c.Assert(sql, Equals, "select 1, '?', `?`, \"\\\"?\", 'x'")
+	_, err = bindArgs("select ?", args)
+	c.Assert(terror.ErrorEqual(err, errXCmdNumArguments), IsTrue)
+	_, err = bindArgs("select ?, ?, ?", args)
+	c.Assert(terror.ErrorEqual(err, errXCmdNumArguments), IsTrue)
+}
@@ -0,0 +1,119 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+)
+
+// The error codes of the X Plugin.
+// See https://dev.mysql.com/doc/refman/5.7/en/server-error-reference.html
+const (
+	codeXBadMessage                terror.ErrCode = 5000
+	codeXCapabilitiesPrepareFailed terror.ErrCode = 5001
+	codeXCapabilityNotFound        terror.ErrCode = 5002
+	codeXInvalidProtocolData       terror.ErrCode = 5003
+	codeXInvalidArgument           terror.ErrCode = 5012
+	codeXBadInsertData             terror.ErrCode = 5014
+	codeXCmdNumArguments           terror.ErrCode = 5015
+	codeXCmdArgumentType           terror.ErrCode = 5016
+	codeXBadTypeOfUpdate           terror.ErrCode = 5051
+	codeXBadColumnToUpdate         terror.ErrCode = 5052
+	codeXBadMemberToUpdate         terror.ErrCode = 5053
+	codeXBadProjection             terror.ErrCode = 5114
+	codeXProjBadKeyName            terror.ErrCode = 5120
+	codeXBadDocPath                terror.ErrCode = 5121
+	codeXExprBadOperator           terror.ErrCode = 5150
+	codeXExprBadNumArgs            terror.ErrCode = 5151
+	codeXExprMissingArg            terror.ErrCode = 5152
+	codeXExprBadTypeValue          terror.ErrCode = 5153
+	codeXExprBadValue              terror.ErrCode = 5154
+	codeXInvalidCollection         terror.ErrCode = 5156
+	codeXInvalidAdminCommand       terror.ErrCode = 5157
+	codeXExpectNotOpen             terror.ErrCode = 5158
+	codeXExpectNoErrorFailed       terror.ErrCode = 5159
+	codeXExpectBadCondition        terror.ErrCode = 5160
+	codeXInvalidNamespace          terror.ErrCode = 5162
+	codeXBadNotice                 terror.ErrCode = 5163
+
+	codeAccessDenied = terror.ErrCode(mysql.ErrAccessDenied)
+	codeNotSupported = terror.ErrCode(mysql.ErrNotSupportedYet)
+)
+
+var (
+	errXBadMessage                = terror.ClassXServer.New(codeXBadMessage, "Invalid message")
+	errXCapabilitiesPrepareFailed = terror.ClassXServer.New(codeXCapabilitiesPrepareFailed, "Capability prepare failed for '%s'")
+	errXCapabilityNotFound        = terror.ClassXServer.New(codeXCapabilityNotFound, "Capability '%s' doesn't exist")
+	errXInvalidProtocolData       = terror.ClassXServer.New(codeXInvalidProtocolData, "Invalid protocol data: %s")
+	errXInvalidArgument           = terror.ClassXServer.New(codeXInvalidArgument, "Invalid argument: %s")
+	errXBadInsertData             = terror.ClassXServer.New(codeXBadInsertData, "Wrong number of fields in row being inserted")
+	errXCmdNumArguments           = terror.ClassXServer.New(codeXCmdNumArguments, "Invalid number of arguments, expected %d but got %d")
+	errXCmdArgumentType           = terror.ClassXServer.New(codeXCmdArgumentType, "Invalid type for argument '%s' to %s")
+	errXBadTypeOfUpdate           = terror.ClassXServer.New(codeXBadTypeOfUpdate, "Invalid type of update operation for %s")
+	errXBadColumnToUpdate         = terror.ClassXServer.New(codeXBadColumnToUpdate, "Invalid column name to update")
+	errXBadMemberToUpdate         = terror.ClassXServer.New(codeXBadMemberToUpdate, "Forbidden update operation on '%s' member")
+	errXBadProjection             = terror.ClassXServer.New(codeXBadProjection, "Invalid projection for document operation")
+	errXProjBadKeyName            = terror.ClassXServer.New(codeXProjBadKeyName, "Invalid key name in document projection")
+	errXBadDocPath                = terror.ClassXServer.New(codeXBadDocPath, "Invalid document path")
+	errXExprBadOperator           = terror.ClassXServer.New(codeXExprBadOperator, "Invalid operator %s")
+	errXExprBadNumArgs            = terror.ClassXServer.New(codeXExprBadNumArgs, "Invalid number of arguments for operator %s")
+	errXExprMissingArg            = terror.ClassXServer.New(codeXExprMissingArg, "Invalid value of placeholder %d")
+	errXExprBadTypeValue          = terror.ClassXServer.New(codeXExprBadTypeValue, "Invalid type of %s")
+	errXExprBadValue              = terror.ClassXServer.New(codeXExprBadValue, "Invalid value of %s")
+	errXInvalidCollection         = terror.ClassXServer.New(codeXInvalidCollection, "Invalid collection name")
+	errXInvalidAdminCommand       = terror.ClassXServer.New(codeXInvalidAdminCommand, "Invalid %s command %s")
+	errXExpectNotOpen             = terror.ClassXServer.New(codeXExpectNotOpen, "Expect block currently not open")
+	errXExpectNoErrorFailed       = terror.ClassXServer.New(codeXExpectNoErrorFailed, "Expectation failed: no_error")
+	errXExpectBadCondition        = terror.ClassXServer.New(codeXExpectBadCondition, "Unknown condition key %d")
+	errXInvalidNamespace          = terror.ClassXServer.New(codeXInvalidNamespace, "Unknown namespace %s")
+	errXBadNotice                 = terror.ClassXServer.New(codeXBadNotice, "Invalid notice name %s")
+
+	errAccessDenied = terror.ClassXServer.New(codeAccessDenied, mysql.MySQLErrName[mysql.ErrAccessDenied])
+	errNotSupported = terror.ClassXServer.New(codeNotSupported, "%s is not supported")
+)
+
+func init() {
+	xserverMySQLErrCodes := map[terror.ErrCode]uint16{
+		codeXBadMessage:                uint16(codeXBadMessage),
+		codeXCapabilitiesPrepareFailed: uint16(codeXCapabilitiesPrepareFailed),
+		codeXCapabilityNotFound:        uint16(codeXCapabilityNotFound),
+		codeXInvalidProtocolData:       uint16(codeXInvalidProtocolData),
+		codeXInvalidArgument:           uint16(codeXInvalidArgument),
+		codeXBadInsertData:             uint16(codeXBadInsertData),
+		codeXCmdNumArguments:           uint16(codeXCmdNumArguments),
+		codeXCmdArgumentType:           uint16(codeXCmdArgumentType),
+		codeXBadTypeOfUpdate:           uint16(codeXBadTypeOfUpdate),
+		codeXBadColumnToUpdate:         uint16(codeXBadColumnToUpdate),
+		codeXBadMemberToUpdate:         uint16(codeXBadMemberToUpdate),
+		codeXBadProjection:             uint16(codeXBadProjection),
+		codeXProjBadKeyName:            uint16(codeXProjBadKeyName),
+		codeXBadDocPath:                uint16(codeXBadDocPath),
+		codeXExprBadOperator:           uint16(codeXExprBadOperator),
+		codeXExprBadNumArgs:            uint16(codeXExprBadNumArgs),
+		codeXExprMissingArg:            uint16(codeXExprMissingArg),
+		codeXExprBadTypeValue:          uint16(codeXExprBadTypeValue),
+		codeXExprBadValue:              uint16(codeXExprBadValue),
+		codeXInvalidCollection:         uint16(codeXInvalidCollection),
+		codeXInvalidAdminCommand:       uint16(codeXInvalidAdminCommand),
+		codeXExpectNotOpen:             uint16(codeXExpectNotOpen),
+		codeXExpectNoErrorFailed:       uint16(codeXExpectNoErrorFailed),
+		codeXExpectBadCondition:        uint16(codeXExpectBadCondition),
+		codeXInvalidNamespace:          uint16(codeXInvalidNamespace),
+		codeXBadNotice:                 uint16(codeXBadNotice),
+		codeAccessDenied:               mysql.ErrAccessDenied,
+		codeNotSupported:               mysql.ErrNotSupportedYet,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassXServer] = xserverMySQLErrCodes
+}
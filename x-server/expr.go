@@ -0,0 +1,469 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Expr"
+)
+
+// The content type of Scalar.Octets.
+// See https://dev.mysql.com/doc/internals/en/x-protocol-messages-messages.html
+const contentTypeJSON = 2
+
+// docColumn is the column of collection tables that stores the documents.
+const docColumn = "doc"
+
+// exprGenerator generates the SQL text of X Protocol expressions.
+// In the document data model, identifiers with document paths refer to the members
+// of the documents in the doc column.
+type exprGenerator struct {
+	buf        *bytes.Buffer
+	args       []*Mysqlx_Datatypes.Scalar
+	isDocument bool
+}
+
+func newExprGenerator(args []*Mysqlx_Datatypes.Scalar, isDocument bool) *exprGenerator {
+	return &exprGenerator{
+		buf:        new(bytes.Buffer),
+		args:       args,
+		isDocument: isDocument,
+	}
+}
+
+// binaryOperators maps the binary operators of X Protocol to SQL.
+var binaryOperators = map[string]string{
+	"==":         " = ",
+	"!=":         " != ",
+	">":          " > ",
+	">=":         " >= ",
+	"<":          " < ",
+	"<=":         " <= ",
+	"&":          " & ",
+	"|":          " | ",
+	"^":          " ^ ",
+	"<<":         " << ",
+	">>":         " >> ",
+	"+":          " + ",
+	"-":          " - ",
+	"*":          " * ",
+	"/":          " / ",
+	"div":        " DIV ",
+	"%":          " % ",
+	"is":         " IS ",
+	"is_not":     " IS NOT ",
+	"regexp":     " REGEXP ",
+	"not_regexp": " NOT REGEXP ",
+	"&&":         " AND ",
+	"||":         " OR ",
+	"xor":        " XOR ",
+}
+
+// unaryOperators maps the unary operators of X Protocol to SQL.
+var unaryOperators = map[string]string{
+	"!":          "!",
+	"not":        "NOT ",
+	"sign_plus":  "+",
+	"sign_minus": "-",
+	"~":          "~",
+}
+
+var (
+	castTypePattern = regexp.MustCompile(`(?i)^(BINARY|CHAR|DATE|DATETIME|TIME|DECIMAL|SIGNED|UNSIGNED|JSON)( *\( *[0-9]+( *, *[0-9]+)? *\))?( +INTEGER)?$`)
+	intervalUnits   = map[string]bool{
+		"MICROSECOND": true, "SECOND": true, "MINUTE": true, "HOUR": true, "DAY": true, "WEEK": true, "MONTH": true,
+		"QUARTER": true, "YEAR": true, "SECOND_MICROSECOND": true, "MINUTE_MICROSECOND": true, "MINUTE_SECOND": true,
+		"HOUR_MICROSECOND": true, "HOUR_SECOND": true, "HOUR_MINUTE": true, "DAY_MICROSECOND": true, "DAY_SECOND": true,
+		"DAY_MINUTE": true, "DAY_HOUR": true, "YEAR_MONTH": true,
+	}
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+// generate returns the SQL text of the expression.
+func (g *exprGenerator) generate(e *Mysqlx_Expr.Expr) (string, error) {
+	g.buf.Reset()
+	if err := g.expr(e); err != nil {
+		return "", errors.Trace(err)
+	}
+	return g.buf.String(), nil
+}
+
+func (g *exprGenerator) expr(e *Mysqlx_Expr.Expr) error {
+	switch e.GetType() {
+	case Mysqlx_Expr.Expr_IDENT:
+		return errors.Trace(g.columnIdentifier(e.GetIdentifier()))
+	case Mysqlx_Expr.Expr_LITERAL:
+		return errors.Trace(g.scalar(e.GetLiteral()))
+	case Mysqlx_Expr.Expr_FUNC_CALL:
+		return errors.Trace(g.functionCall(e.GetFunctionCall()))
+	case Mysqlx_Expr.Expr_OPERATOR:
+		return errors.Trace(g.operator(e.GetOperator()))
+	case Mysqlx_Expr.Expr_PLACEHOLDER:
+		pos := e.GetPosition()
+		if int(pos) >= len(g.args) {
+			return errXExprMissingArg.GenByArgs(pos)
+		}
+		return errors.Trace(g.scalar(g.args[pos]))
+	case Mysqlx_Expr.Expr_OBJECT:
+		return errors.Trace(g.object(e.GetObject()))
+	case Mysqlx_Expr.Expr_ARRAY:
+		return errors.Trace(g.list("JSON_ARRAY(", e.GetArray().GetValue(), ")"))
+	case Mysqlx_Expr.Expr_VARIABLE:
+		return errNotSupported.GenByArgs("Variable")
+	}
+	return errXExprBadTypeValue.GenByArgs("expression")
+}
+
+// columnIdentifier writes a column, or the member of the document in the column if the document path is set.
+func (g *exprGenerator) columnIdentifier(id *Mysqlx_Expr.ColumnIdentifier) error {
+	if len(id.GetDocumentPath()) == 0 {
+		if id.GetName() == "" {
+			return errXExprBadValue.GenByArgs("column identifier")
+		}
+		g.qualifiedName(id.GetSchemaName(), id.GetTableName(), id.GetName())
+		return nil
+	}
+	path, err := documentPath(id.GetDocumentPath())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	g.buf.WriteString("JSON_EXTRACT(")
+	if id.GetName() == "" {
+		if !g.isDocument {
+			return errXExprBadValue.GenByArgs("column identifier")
+		}
+		g.buf.WriteString(docColumn)
+	} else {
+		g.qualifiedName(id.GetSchemaName(), id.GetTableName(), id.GetName())
+	}
+	g.buf.WriteByte(',')
+	g.buf.WriteString(quoteString(path))
+	g.buf.WriteByte(')')
+	return nil
+}
+
+func (g *exprGenerator) qualifiedName(names ...string) {
+	first := true
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if !first {
+			g.buf.WriteByte('.')
+		}
+		g.buf.WriteString(quoteIdentifier(name))
+		first = false
+	}
+}
+
+// documentPath returns the JSON path expression of the document path items.
+func documentPath(items []*Mysqlx_Expr.DocumentPathItem) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('$')
+	for _, item := range items {
+		switch item.GetType() {
+		case Mysqlx_Expr.DocumentPathItem_MEMBER:
+			buf.WriteByte('.')
+			if identifierPattern.MatchString(item.GetValue()) {
+				buf.WriteString(item.GetValue())
+			} else {
+				buf.WriteString(strconv.Quote(item.GetValue()))
+			}
+		case Mysqlx_Expr.DocumentPathItem_MEMBER_ASTERISK:
+			buf.WriteString(".*")
+		case Mysqlx_Expr.DocumentPathItem_ARRAY_INDEX:
+			buf.WriteByte('[')
+			buf.WriteString(strconv.FormatUint(uint64(item.GetIndex()), 10))
+			buf.WriteByte(']')
+		case Mysqlx_Expr.DocumentPathItem_ARRAY_INDEX_ASTERISK:
+			buf.WriteString("[*]")
+		case Mysqlx_Expr.DocumentPathItem_DOUBLE_ASTERISK:
+			buf.WriteString("**")
+		default:
+			return "", errXBadDocPath
+		}
+	}
+	return buf.String(), nil
+}
+
+func (g *exprGenerator) scalar(s *Mysqlx_Datatypes.Scalar) error {
+	lit, err := scalarLiteral(s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	g.buf.WriteString(lit)
+	return nil
+}
+
+// scalarLiteral returns the SQL literal of the scalar value.
+func scalarLiteral(s *Mysqlx_Datatypes.Scalar) (string, error) {
+	if s == nil {
+		return "", errXExprBadValue.GenByArgs("literal")
+	}
+	switch s.GetType() {
+	case Mysqlx_Datatypes.Scalar_V_SINT:
+		return strconv.FormatInt(s.GetVSignedInt(), 10), nil
+	case Mysqlx_Datatypes.Scalar_V_UINT:
+		return strconv.FormatUint(s.GetVUnsignedInt(), 10), nil
+	case Mysqlx_Datatypes.Scalar_V_NULL:
+		return "NULL", nil
+	case Mysqlx_Datatypes.Scalar_V_OCTETS:
+		lit := quoteString(string(s.GetVOctets().GetValue()))
+		if s.GetVOctets().GetContentType() == contentTypeJSON {
+			return "CAST(" + lit + " AS JSON)", nil
+		}
+		return lit, nil
+	case Mysqlx_Datatypes.Scalar_V_DOUBLE:
+		return strconv.FormatFloat(s.GetVDouble(), 'g', -1, 64), nil
+	case Mysqlx_Datatypes.Scalar_V_FLOAT:
+		return strconv.FormatFloat(float64(s.GetVFloat()), 'g', -1, 32), nil
+	case Mysqlx_Datatypes.Scalar_V_BOOL:
+		if s.GetVBool() {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case Mysqlx_Datatypes.Scalar_V_STRING:
+		return quoteString(string(s.GetVString().GetValue())), nil
+	}
+	return "", errXExprBadTypeValue.GenByArgs("literal")
+}
+
+func (g *exprGenerator) functionCall(f *Mysqlx_Expr.FunctionCall) error {
+	name := f.GetName()
+	if !identifierPattern.MatchString(name.GetName()) {
+		return errXExprBadValue.GenByArgs("function name")
+	}
+	if name.GetSchemaName() != "" {
+		g.buf.WriteString(quoteIdentifier(name.GetSchemaName()))
+		g.buf.WriteByte('.')
+	}
+	return errors.Trace(g.list(strings.ToUpper(name.GetName())+"(", f.GetParam(), ")"))
+}
+
+// list writes the expressions separated by commas between the prefix and the suffix.
+func (g *exprGenerator) list(prefix string, exprs []*Mysqlx_Expr.Expr, suffix string) error {
+	g.buf.WriteString(prefix)
+	for i, e := range exprs {
+		if i > 0 {
+			g.buf.WriteByte(',')
+		}
+		if err := g.expr(e); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	g.buf.WriteString(suffix)
+	return nil
+}
+
+func (g *exprGenerator) object(o *Mysqlx_Expr.Object) error {
+	g.buf.WriteString("JSON_OBJECT(")
+	for i, fld := range o.GetFld() {
+		if i > 0 {
+			g.buf.WriteByte(',')
+		}
+		g.buf.WriteString(quoteString(fld.GetKey()))
+		g.buf.WriteByte(',')
+		if err := g.expr(fld.GetValue()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	g.buf.WriteByte(')')
+	return nil
+}
+
+func (g *exprGenerator) operator(op *Mysqlx_Expr.Operator) error {
+	name, params := op.GetName(), op.GetParam()
+	checkArgs := func(min, max int) error {
+		if len(params) < min || len(params) > max {
+			return errXExprBadNumArgs.GenByArgs(name)
+		}
+		return nil
+	}
+	if sqlOp, ok := binaryOperators[name]; ok {
+		// "*" without operands is the asterisk of projections.
+		if name == "*" && len(params) == 0 {
+			g.buf.WriteByte('*')
+			return nil
+		}
+		if err := checkArgs(2, 2); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(g.infix(params[0], sqlOp, params[1]))
+	}
+	if sqlOp, ok := unaryOperators[name]; ok {
+		if err := checkArgs(1, 1); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteByte('(')
+		g.buf.WriteString(sqlOp)
+		if err := g.expr(params[0]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteByte(')')
+		return nil
+	}
+
+	switch name {
+	case "in", "not_in":
+		if len(params) < 2 {
+			return errXExprBadNumArgs.GenByArgs(name)
+		}
+		g.buf.WriteByte('(')
+		if err := g.expr(params[0]); err != nil {
+			return errors.Trace(err)
+		}
+		prefix := " IN ("
+		if name == "not_in" {
+			prefix = " NOT IN ("
+		}
+		if err := g.list(prefix, params[1:], ")"); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteByte(')')
+		return nil
+	case "like", "not_like":
+		if err := checkArgs(2, 3); err != nil {
+			return errors.Trace(err)
+		}
+		sqlOp := " LIKE "
+		if name == "not_like" {
+			sqlOp = " NOT LIKE "
+		}
+		if len(params) == 2 {
+			return errors.Trace(g.infix(params[0], sqlOp, params[1]))
+		}
+		return errors.Trace(g.infix(params[0], sqlOp, params[1], " ESCAPE ", params[2]))
+	case "between", "not_between":
+		if err := checkArgs(3, 3); err != nil {
+			return errors.Trace(err)
+		}
+		sqlOp := " BETWEEN "
+		if name == "not_between" {
+			sqlOp = " NOT BETWEEN "
+		}
+		return errors.Trace(g.infix(params[0], sqlOp, params[1], " AND ", params[2]))
+	case "cast":
+		if err := checkArgs(2, 2); err != nil {
+			return errors.Trace(err)
+		}
+		tp, err := octetsLiteral(params[1])
+		if err != nil || !castTypePattern.MatchString(tp) {
+			return errXExprBadValue.GenByArgs("cast type")
+		}
+		g.buf.WriteString("CAST(")
+		if err = g.expr(params[0]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(" AS ")
+		g.buf.WriteString(strings.ToUpper(tp))
+		g.buf.WriteByte(')')
+		return nil
+	case "date_add", "date_sub":
+		if err := checkArgs(3, 3); err != nil {
+			return errors.Trace(err)
+		}
+		unit, err := octetsLiteral(params[2])
+		unit = strings.ToUpper(unit)
+		if err != nil || !intervalUnits[unit] {
+			return errXExprBadValue.GenByArgs("interval unit")
+		}
+		g.buf.WriteString(strings.ToUpper(name))
+		g.buf.WriteByte('(')
+		if err = g.expr(params[0]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(", INTERVAL ")
+		if err = g.expr(params[1]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteByte(' ')
+		g.buf.WriteString(unit)
+		g.buf.WriteByte(')')
+		return nil
+	case "default":
+		if err := checkArgs(0, 0); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString("DEFAULT")
+		return nil
+	}
+	return errXExprBadOperator.GenByArgs(name)
+}
+
+// infix writes the operands and the operators in turn in parentheses, the odd elements are the operators.
+func (g *exprGenerator) infix(elems ...interface{}) error {
+	g.buf.WriteByte('(')
+	for _, elem := range elems {
+		switch x := elem.(type) {
+		case string:
+			g.buf.WriteString(x)
+		case *Mysqlx_Expr.Expr:
+			if err := g.expr(x); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	g.buf.WriteByte(')')
+	return nil
+}
+
+// octetsLiteral returns the string of a literal octets or string expression.
+func octetsLiteral(e *Mysqlx_Expr.Expr) (string, error) {
+	if e.GetType() == Mysqlx_Expr.Expr_LITERAL {
+		switch lit := e.GetLiteral(); lit.GetType() {
+		case Mysqlx_Datatypes.Scalar_V_OCTETS:
+			return string(lit.GetVOctets().GetValue()), nil
+		case Mysqlx_Datatypes.Scalar_V_STRING:
+			return string(lit.GetVString().GetValue()), nil
+		}
+	}
+	return "", errXExprBadTypeValue.GenByArgs("literal")
+}
+
+// quoteIdentifier quotes the identifier with backquotes.
+func quoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// quoteString quotes the string with single quotes, the special characters are escaped.
+func quoteString(s string) string {
+	var buf bytes.Buffer
+	buf.Grow(len(s) + 2)
+	buf.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			buf.WriteString(`\0`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\x1a':
+			buf.WriteString(`\Z`)
+		case '\'', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('\'')
+	return buf.String()
+}
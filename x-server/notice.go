@@ -0,0 +1,96 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Notice"
+)
+
+// The types of the notice frames.
+// See https://dev.mysql.com/doc/internals/en/x-protocol-notices-notices.html
+const (
+	noticeWarning                uint32 = 1
+	noticeSessionVariableChanged uint32 = 2
+	noticeSessionStateChanged    uint32 = 3
+)
+
+// The notices that can be listed by the list_notices admin command, only warnings can be disabled.
+var fixedNotices = []string{"account_expired", "generated_insert_id", "rows_affected", "produced_message"}
+
+const noticeWarnings = "warnings"
+
+func (cc *clientConn) writeNotice(tp uint32, payload message) error {
+	data, err := payload.Marshal()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	frame := &Mysqlx_Notice.Frame{
+		Type:    &tp,
+		Scope:   Mysqlx_Notice.Frame_LOCAL.Enum(),
+		Payload: data,
+	}
+	return errors.Trace(cc.writePacket(Mysqlx.ServerMessages_NOTICE, frame))
+}
+
+func (cc *clientConn) writeSessionStateChanged(param Mysqlx_Notice.SessionStateChanged_Parameter, value *Mysqlx_Datatypes.Scalar) error {
+	msg := &Mysqlx_Notice.SessionStateChanged{Param: param.Enum(), Value: value}
+	return errors.Trace(cc.writeNotice(noticeSessionStateChanged, msg))
+}
+
+// writeWarnings sends the warnings of the last statement as notices if the warnings notice is enabled.
+func (cc *clientConn) writeWarnings() error {
+	if !cc.sendWarnings || cc.ctx.WarningCount() == 0 {
+		return nil
+	}
+	rss, err := cc.ctx.Execute("SHOW WARNINGS")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(rss) == 0 {
+		return nil
+	}
+	defer terror.Call(rss[0].Close)
+	for {
+		row, err := rss[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+		level, code, msg := row[0].GetString(), uint32(row[1].GetInt64()), row[2].GetString()
+		warning := &Mysqlx_Notice.Warning{
+			Level: warningLevel(level).Enum(),
+			Code:  &code,
+			Msg:   &msg,
+		}
+		if err = cc.writeNotice(noticeWarning, warning); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+func warningLevel(level string) Mysqlx_Notice.Warning_Level {
+	switch level {
+	case "Note":
+		return Mysqlx_Notice.Warning_NOTE
+	case "Error":
+		return Mysqlx_Notice.Warning_ERROR
+	}
+	return Mysqlx_Notice.Warning_WARNING
+}
@@ -0,0 +1,347 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Resultset"
+)
+
+// The flags of ColumnMetaData.
+// See https://dev.mysql.com/doc/internals/en/x-protocol-messages-messages.html
+const (
+	columnFlagUintZerofill  = 0x0001
+	columnFlagNotNull       = 0x0010
+	columnFlagPrimaryKey    = 0x0020
+	columnFlagUniqueKey     = 0x0040
+	columnFlagMultipleKey   = 0x0080
+	columnFlagAutoIncrement = 0x0100
+)
+
+// writeResultSets sends the result sets, FetchDoneMoreResultsets is sent between them.
+func (cc *clientConn) writeResultSets(rss []server.ResultSet) error {
+	for i, rs := range rss {
+		if err := cc.writeResultSet(rs); err != nil {
+			return errors.Trace(err)
+		}
+		tp := Mysqlx.ServerMessages_RESULTSET_FETCH_DONE
+		if i < len(rss)-1 {
+			tp = Mysqlx.ServerMessages_RESULTSET_FETCH_DONE_MORE_RESULTSETS
+		}
+		if err := cc.writePacket(tp, nil); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// writeResultSet sends the column metadata and the rows of the result set, the result set is closed.
+func (cc *clientConn) writeResultSet(rs server.ResultSet) error {
+	defer terror.Call(rs.Close)
+	columns, err := rs.Columns()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	metas := make([]*Mysqlx_Resultset.ColumnMetaData, 0, len(columns))
+	for _, col := range columns {
+		meta := columnMetaData(col)
+		metas = append(metas, meta)
+		if err = cc.writePacket(Mysqlx.ServerMessages_RESULTSET_COLUMN_META_DATA, meta); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+		msg := &Mysqlx_Resultset.Row{Field: make([][]byte, 0, len(row))}
+		for i, d := range row {
+			field, err := encodeField(metas[i], d)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			msg.Field = append(msg.Field, field)
+		}
+		if err = cc.writePacket(Mysqlx.ServerMessages_RESULTSET_ROW, msg); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// columnMetaData converts the column to the ColumnMetaData of X Protocol.
+func columnMetaData(col *server.ColumnInfo) *Mysqlx_Resultset.ColumnMetaData {
+	var (
+		tp          Mysqlx_Resultset.ColumnMetaData_FieldType
+		contentType uint32
+		flags       uint32
+		colFlag     = uint(col.Flag)
+	)
+	switch col.Type {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear:
+		tp = Mysqlx_Resultset.ColumnMetaData_SINT
+		if mysql.HasUnsignedFlag(colFlag) {
+			tp = Mysqlx_Resultset.ColumnMetaData_UINT
+			if mysql.HasZerofillFlag(colFlag) {
+				flags |= columnFlagUintZerofill
+			}
+		}
+	case mysql.TypeFloat:
+		tp = Mysqlx_Resultset.ColumnMetaData_FLOAT
+	case mysql.TypeDouble:
+		tp = Mysqlx_Resultset.ColumnMetaData_DOUBLE
+	case mysql.TypeNewDecimal:
+		tp = Mysqlx_Resultset.ColumnMetaData_DECIMAL
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		tp = Mysqlx_Resultset.ColumnMetaData_DATETIME
+	case mysql.TypeDuration:
+		tp = Mysqlx_Resultset.ColumnMetaData_TIME
+	case mysql.TypeEnum:
+		tp = Mysqlx_Resultset.ColumnMetaData_ENUM
+	case mysql.TypeSet:
+		tp = Mysqlx_Resultset.ColumnMetaData_SET
+	case mysql.TypeBit:
+		tp = Mysqlx_Resultset.ColumnMetaData_BIT
+	case mysql.TypeJSON:
+		tp = Mysqlx_Resultset.ColumnMetaData_BYTES
+		contentType = contentTypeJSON
+	default:
+		tp = Mysqlx_Resultset.ColumnMetaData_BYTES
+		if colFlag&mysql.EnumFlag > 0 {
+			tp = Mysqlx_Resultset.ColumnMetaData_ENUM
+		} else if colFlag&mysql.SetFlag > 0 {
+			tp = Mysqlx_Resultset.ColumnMetaData_SET
+		}
+	}
+	if mysql.HasNotNullFlag(colFlag) {
+		flags |= columnFlagNotNull
+	}
+	if mysql.HasPriKeyFlag(colFlag) {
+		flags |= columnFlagPrimaryKey
+	}
+	if mysql.HasUniKeyFlag(colFlag) {
+		flags |= columnFlagUniqueKey
+	}
+	if mysql.HasMultipleKeyFlag(colFlag) {
+		flags |= columnFlagMultipleKey
+	}
+	if mysql.HasAutoIncrementFlag(colFlag) {
+		flags |= columnFlagAutoIncrement
+	}
+	collation := uint64(col.Charset)
+	fractionalDigits := uint32(col.Decimal)
+	length := col.ColumnLength
+	meta := &Mysqlx_Resultset.ColumnMetaData{
+		Type:             tp.Enum(),
+		Name:             []byte(col.Name),
+		OriginalName:     []byte(col.OrgName),
+		Table:            []byte(col.Table),
+		OriginalTable:    []byte(col.OrgTable),
+		Schema:           []byte(col.Schema),
+		Catalog:          []byte("def"),
+		Collation:        &collation,
+		FractionalDigits: &fractionalDigits,
+		Length:           &length,
+		Flags:            &flags,
+	}
+	if contentType != 0 {
+		meta.ContentType = &contentType
+	}
+	return meta
+}
+
+// encodeField encodes the value by the type of the column, NULL is encoded as an empty field.
+// See https://dev.mysql.com/doc/internals/en/x-protocol-messages-messages.html
+func encodeField(meta *Mysqlx_Resultset.ColumnMetaData, d types.Datum) ([]byte, error) {
+	if d.IsNull() {
+		return []byte{}, nil
+	}
+	sc := new(variable.StatementContext)
+	var buf [binary.MaxVarintLen64]byte
+	switch meta.GetType() {
+	case Mysqlx_Resultset.ColumnMetaData_SINT:
+		v, err := d.ToInt64(sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		n := binary.PutVarint(buf[:], v)
+		return append([]byte(nil), buf[:n]...), nil
+	case Mysqlx_Resultset.ColumnMetaData_UINT, Mysqlx_Resultset.ColumnMetaData_BIT:
+		var v uint64
+		switch d.Kind() {
+		case types.KindUint64:
+			v = d.GetUint64()
+		case types.KindMysqlBit, types.KindBinaryLiteral:
+			var err error
+			if v, err = d.GetBinaryLiteral().ToInt(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		default:
+			i, err := d.ToInt64(sc)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			v = uint64(i)
+		}
+		n := binary.PutUvarint(buf[:], v)
+		return append([]byte(nil), buf[:n]...), nil
+	case Mysqlx_Resultset.ColumnMetaData_DOUBLE:
+		v, err := d.ToFloat64(sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+		return b, nil
+	case Mysqlx_Resultset.ColumnMetaData_FLOAT:
+		v, err := d.ToFloat64(sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v)))
+		return b, nil
+	case Mysqlx_Resultset.ColumnMetaData_DECIMAL:
+		v, err := d.ToDecimal(sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return encodeDecimal(v.String()), nil
+	case Mysqlx_Resultset.ColumnMetaData_DATETIME:
+		if d.Kind() != types.KindMysqlTime {
+			break
+		}
+		return encodeDatetime(d.GetMysqlTime()), nil
+	case Mysqlx_Resultset.ColumnMetaData_TIME:
+		if d.Kind() != types.KindMysqlDuration {
+			break
+		}
+		return encodeTime(d.GetMysqlDuration()), nil
+	case Mysqlx_Resultset.ColumnMetaData_SET:
+		s, err := d.ToString()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return encodeSet(s), nil
+	}
+	var s string
+	if d.Kind() == types.KindMysqlJSON {
+		s = d.GetMysqlJSON().String()
+	} else {
+		var err error
+		if s, err = d.ToString(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	// The strings end with 0x00, so that the empty string can be distinguished from NULL.
+	b := make([]byte, 0, len(s)+1)
+	b = append(b, s...)
+	return append(b, 0), nil
+}
+
+// encodeDatetime encodes the date as the varints of year, month, day, the time part is appended for datetime.
+func encodeDatetime(t types.Time) []byte {
+	parts := []int{t.Time.Year(), t.Time.Month(), t.Time.Day()}
+	if t.Type != mysql.TypeDate {
+		parts = append(parts, t.Time.Hour(), t.Time.Minute(), t.Time.Second())
+		if us := t.Time.Microsecond(); us != 0 {
+			parts = append(parts, us)
+		}
+	}
+	return appendUvarints(nil, parts)
+}
+
+// encodeTime encodes the duration as a sign byte followed by the varints of hours, minutes, seconds, microseconds.
+func encodeTime(d types.Duration) []byte {
+	b := []byte{0}
+	dur := d.Duration
+	if dur < 0 {
+		b[0] = 1
+		dur = -dur
+	}
+	us := int64(dur / 1000)
+	parts := []int{int(us / 3600e6), int(us / 60e6 % 60), int(us / 1e6 % 60)}
+	if us%1e6 != 0 {
+		parts = append(parts, int(us%1e6))
+	}
+	return appendUvarints(b, parts)
+}
+
+func appendUvarints(b []byte, parts []int) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	for _, p := range parts {
+		n := binary.PutUvarint(buf[:], uint64(p))
+		b = append(b, buf[:n]...)
+	}
+	return b
+}
+
+// encodeDecimal encodes the decimal string as a scale byte followed by the packed BCD digits and the sign nibble,
+// 0xc for positive and 0xd for negative.
+func encodeDecimal(s string) []byte {
+	sign := byte(0xc)
+	if strings.HasPrefix(s, "-") {
+		sign = 0xd
+		s = s[1:]
+	}
+	var scale int
+	if idx := strings.IndexByte(s, '.'); idx >= 0 {
+		scale = len(s) - idx - 1
+		s = s[:idx] + s[idx+1:]
+	}
+	nibbles := make([]byte, 0, len(s)+2)
+	for i := 0; i < len(s); i++ {
+		nibbles = append(nibbles, s[i]-'0')
+	}
+	nibbles = append(nibbles, sign)
+	if len(nibbles)%2 != 0 {
+		nibbles = append(nibbles, 0)
+	}
+	b := make([]byte, 0, 1+len(nibbles)/2)
+	b = append(b, byte(scale))
+	for i := 0; i < len(nibbles); i += 2 {
+		b = append(b, nibbles[i]<<4|nibbles[i+1])
+	}
+	return b
+}
+
+// encodeSet encodes the comma separated set as the length prefixed elements, the empty set is encoded as 0x01.
+func encodeSet(s string) []byte {
+	if s == "" {
+		return []byte{1}
+	}
+	var (
+		b   []byte
+		buf [binary.MaxVarintLen64]byte
+	)
+	for _, elem := range strings.Split(s, ",") {
+		n := binary.PutUvarint(buf[:], uint64(len(elem)))
+		b = append(b, buf[:n]...)
+		b = append(b, elem...)
+	}
+	return b
+}
@@ -14,6 +14,8 @@
 package xserver
 
 import (
+	"bufio"
+	"crypto/tls"
 	"math/rand"
 	"net"
 	"sync"
@@ -38,6 +40,8 @@ var (
 // Server is the MySQL X protocol server
 type Server struct {
 	cfg               *Config
+	driver            server.IDriver
+	tlsConfig         *tls.Config
 	listener          net.Listener
 	rwlock            *sync.RWMutex
 	concurrentLimiter *server.TokenLimiter
@@ -45,10 +49,11 @@ type Server struct {
 	stopListenerCh chan struct{}
 }
 
-// NewServer creates a new Server.
-func NewServer(cfg *Config) (s *Server, err error) {
+// NewServer creates a new Server, the sessions are opened by the driver.
+func NewServer(cfg *Config, driver server.IDriver) (s *Server, err error) {
 	s = &Server{
 		cfg:               cfg,
+		driver:            driver,
 		concurrentLimiter: server.NewTokenLimiter(tokenLimit),
 		rwlock:            &sync.RWMutex{},
 		stopListenerCh:    make(chan struct{}, 1),
@@ -62,8 +67,16 @@ func NewServer(cfg *Config) (s *Server, err error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if cfg.SSLCert != "" && cfg.SSLKey != "" {
+		tlsCert, err := tls.LoadX509KeyPair(cfg.SSLCert, cfg.SSLKey)
+		if err != nil {
+			terror.Log(errors.Trace(s.listener.Close()))
+			return nil, errors.Trace(err)
+		}
+		s.tlsConfig = &tls.Config{Certificates: []tls.Certificate{tlsCert}}
+	}
 	rand.Seed(time.Now().UTC().UnixNano())
-	log.Infof("Server run MySQL Protocol Listen at [%s]", s.cfg.Addr)
+	log.Infof("Server run MySQL X Protocol Listen at [%s]", s.cfg.Addr)
 	return s, nil
 }
 
@@ -130,10 +143,13 @@ func (s *Server) onConn(c net.Conn) {
 func (s *Server) newConn(conn net.Conn) *clientConn {
 	cc := &clientConn{
 		conn:         conn,
+		bufReader:    bufio.NewReaderSize(conn, defaultReaderSize),
+		bufWriter:    bufio.NewWriterSize(conn, defaultWriterSize),
 		server:       s,
 		connectionID: atomic.AddUint32(&baseConnID, 1),
 		collation:    mysql.DefaultCollationID,
 		alloc:        arena.NewAllocator(32 * 1024),
+		sendWarnings: true,
 	}
 	log.Infof("[%d] new x protocol connection %s", cc.connectionID, conn.RemoteAddr().String())
 	cc.salt = util.RandomBuf(20)
@@ -0,0 +1,378 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Connection"
+	"github.com/pingcap/tipb/go-mysqlx/Crud"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Expect"
+	"github.com/pingcap/tipb/go-mysqlx/Expr"
+	"github.com/pingcap/tipb/go-mysqlx/Notice"
+	"github.com/pingcap/tipb/go-mysqlx/Resultset"
+	"github.com/pingcap/tipb/go-mysqlx/Session"
+	"github.com/pingcap/tipb/go-mysqlx/Sql"
+)
+
+var _ = Suite(&testServerSuite{})
+
+type testServerSuite struct {
+	store  kv.Storage
+	server *Server
+}
+
+func (s *testServerSuite) SetUpSuite(c *C) {
+	store, err := tidb.NewStore("memory:///tmp/tidb_xserver")
+	c.Assert(err, IsNil)
+	s.store = store
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	s.server, err = NewServer(&Config{Addr: "127.0.0.1:0"}, server.NewTiDBDriver(store))
+	c.Assert(err, IsNil)
+	go s.server.Run()
+}
+
+func (s *testServerSuite) TearDownSuite(c *C) {
+	s.server.Close()
+	c.Assert(s.store.Close(), IsNil)
+}
+
+// testClient is a raw X Protocol client.
+type testClient struct {
+	c    *C
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+type unmarshaler interface {
+	Unmarshal([]byte) error
+}
+
+func (s *testServerSuite) newClient(c *C) *testClient {
+	conn, err := net.Dial("tcp", s.server.listener.Addr().String())
+	c.Assert(err, IsNil)
+	return &testClient{c: c, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (tc *testClient) send(tp Mysqlx.ClientMessages_Type, msg message) {
+	data, err := msg.Marshal()
+	tc.c.Assert(err, IsNil)
+	buf := make([]byte, 5, 5+len(data))
+	binary.LittleEndian.PutUint32(buf, uint32(len(data)+1))
+	buf[4] = byte(tp)
+	_, err = tc.conn.Write(append(buf, data...))
+	tc.c.Assert(err, IsNil)
+}
+
+func (tc *testClient) recv() (Mysqlx.ServerMessages_Type, []byte) {
+	var header [5]byte
+	_, err := io.ReadFull(tc.r, header[:])
+	tc.c.Assert(err, IsNil)
+	payload := make([]byte, binary.LittleEndian.Uint32(header[:4])-1)
+	_, err = io.ReadFull(tc.r, payload)
+	tc.c.Assert(err, IsNil)
+	return Mysqlx.ServerMessages_Type(header[4]), payload
+}
+
+// expect receives a message of the type and unmarshals it into msg if it's not nil.
+func (tc *testClient) expect(tp Mysqlx.ServerMessages_Type, msg unmarshaler) {
+	gotTp, payload := tc.recv()
+	if gotTp == Mysqlx.ServerMessages_ERROR && tp != gotTp {
+		var e Mysqlx.Error
+		tc.c.Assert(e.Unmarshal(payload), IsNil)
+		tc.c.Fatalf("unexpected error %d: %s", e.GetCode(), e.GetMsg())
+	}
+	tc.c.Assert(gotTp, Equals, tp)
+	if msg != nil {
+		tc.c.Assert(msg.Unmarshal(payload), IsNil)
+	}
+}
+
+func (tc *testClient) expectError(code uint16) {
+	var e Mysqlx.Error
+	tc.expect(Mysqlx.ServerMessages_ERROR, &e)
+	tc.c.Assert(e.GetCode(), Equals, uint32(code), Commentf("%s", e.GetMsg()))
+}
+
+// expectStmtOk receives the notices until StmtExecuteOk, and returns the rows affected.
+func (tc *testClient) expectStmtOk() uint64 {
+	var rowsAffected uint64
+	for {
+		tp, payload := tc.recv()
+		if tp == Mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK {
+			return rowsAffected
+		}
+		if tp == Mysqlx.ServerMessages_ERROR {
+			var e Mysqlx.Error
+			tc.c.Assert(e.Unmarshal(payload), IsNil)
+			tc.c.Fatalf("unexpected error %d: %s", e.GetCode(), e.GetMsg())
+		}
+		tc.c.Assert(tp, Equals, Mysqlx.ServerMessages_NOTICE)
+		var frame Mysqlx_Notice.Frame
+		tc.c.Assert(frame.Unmarshal(payload), IsNil)
+		if frame.GetType() == noticeSessionStateChanged {
+			var state Mysqlx_Notice.SessionStateChanged
+			tc.c.Assert(state.Unmarshal(frame.GetPayload()), IsNil)
+			if state.GetParam() == Mysqlx_Notice.SessionStateChanged_ROWS_AFFECTED {
+				rowsAffected = state.GetValue().GetVUnsignedInt()
+			}
+		}
+	}
+}
+
+// expectRows receives a result set, and returns the metadata and the rows.
+func (tc *testClient) expectRows() ([]*Mysqlx_Resultset.ColumnMetaData, [][][]byte) {
+	var (
+		metas []*Mysqlx_Resultset.ColumnMetaData
+		rows  [][][]byte
+	)
+	for {
+		tp, payload := tc.recv()
+		switch tp {
+		case Mysqlx.ServerMessages_RESULTSET_COLUMN_META_DATA:
+			meta := &Mysqlx_Resultset.ColumnMetaData{}
+			tc.c.Assert(meta.Unmarshal(payload), IsNil)
+			metas = append(metas, meta)
+		case Mysqlx.ServerMessages_RESULTSET_ROW:
+			var row Mysqlx_Resultset.Row
+			tc.c.Assert(row.Unmarshal(payload), IsNil)
+			rows = append(rows, row.GetField())
+		case Mysqlx.ServerMessages_RESULTSET_FETCH_DONE:
+			tc.expectStmtOk()
+			return metas, rows
+		default:
+			tc.c.Fatalf("unexpected message %d", tp)
+		}
+	}
+}
+
+func (tc *testClient) execute(namespace, stmt string, args ...*Mysqlx_Datatypes.Any) {
+	tc.send(Mysqlx.ClientMessages_SQL_STMT_EXECUTE, &Mysqlx_Sql.StmtExecute{
+		Namespace: stringPtr(namespace),
+		Stmt:      []byte(stmt),
+		Args:      args,
+	})
+}
+
+// authenticate authenticates by MYSQL41 and returns the error code, 0 means success.
+func (tc *testClient) authenticate(schema, user, password string) uint16 {
+	tc.send(Mysqlx.ClientMessages_SESS_AUTHENTICATE_START, &Mysqlx_Session.AuthenticateStart{MechName: stringPtr(authMySQL41)})
+	var challenge Mysqlx_Session.AuthenticateContinue
+	tc.expect(Mysqlx.ServerMessages_SESS_AUTHENTICATE_CONTINUE, &challenge)
+	tc.c.Assert(challenge.GetAuthData(), HasLen, 20)
+	authData := schema + "\x00" + user + "\x00"
+	if password != "" {
+		authData += "*" + strings.ToUpper(hex.EncodeToString(scramblePassword(challenge.GetAuthData(), password)))
+	}
+	tc.send(Mysqlx.ClientMessages_SESS_AUTHENTICATE_CONTINUE, &Mysqlx_Session.AuthenticateContinue{AuthData: []byte(authData)})
+	tp, payload := tc.recv()
+	if tp == Mysqlx.ServerMessages_ERROR {
+		var e Mysqlx.Error
+		tc.c.Assert(e.Unmarshal(payload), IsNil)
+		return uint16(e.GetCode())
+	}
+	tc.c.Assert(tp, Equals, Mysqlx.ServerMessages_NOTICE)
+	tc.expect(Mysqlx.ServerMessages_SESS_AUTHENTICATE_OK, nil)
+	return 0
+}
+
+func (tc *testClient) close() {
+	tc.send(Mysqlx.ClientMessages_CON_CLOSE, &Mysqlx_Connection.Close{})
+	tc.expect(Mysqlx.ServerMessages_OK, nil)
+	tc.c.Assert(tc.conn.Close(), IsNil)
+}
+
+func (s *testServerSuite) TestHandshake(c *C) {
+	tc := s.newClient(c)
+	tc.send(Mysqlx.ClientMessages_CON_CAPABILITIES_GET, &Mysqlx_Connection.CapabilitiesGet{})
+	var caps Mysqlx_Connection.Capabilities
+	tc.expect(Mysqlx.ServerMessages_CONN_CAPABILITIES, &caps)
+	var mechanisms []string
+	for _, capability := range caps.GetCapabilities() {
+		c.Assert(capability.GetName(), Not(Equals), capTLS)
+		if capability.GetName() == capAuthMechanisms {
+			for _, v := range capability.GetValue().GetArray().GetValue() {
+				mechanisms = append(mechanisms, string(v.GetScalar().GetVString().GetValue()))
+			}
+		}
+	}
+	c.Assert(mechanisms, DeepEquals, []string{authMySQL41})
+
+	// TLS isn't configured.
+	tc.send(Mysqlx.ClientMessages_CON_CAPABILITIES_SET, &Mysqlx_Connection.CapabilitiesSet{
+		Capabilities: &Mysqlx_Connection.Capabilities{Capabilities: []*Mysqlx_Connection.Capability{
+			capability(capTLS, boolScalar(true)),
+		}},
+	})
+	tc.expectError(uint16(codeXCapabilitiesPrepareFailed))
+	tc.send(Mysqlx.ClientMessages_CON_CAPABILITIES_SET, &Mysqlx_Connection.CapabilitiesSet{
+		Capabilities: &Mysqlx_Connection.Capabilities{Capabilities: []*Mysqlx_Connection.Capability{
+			capability("unknown", boolScalar(true)),
+		}},
+	})
+	tc.expectError(uint16(codeXCapabilityNotFound))
+	// PLAIN is only allowed on secure connections.
+	tc.send(Mysqlx.ClientMessages_SESS_AUTHENTICATE_START, &Mysqlx_Session.AuthenticateStart{
+		MechName: stringPtr(authPlain),
+		AuthData: []byte("\x00root\x00"),
+	})
+	tc.expectError(mysql.ErrNotSupportedYet)
+
+	tc = s.newClient(c)
+	c.Assert(tc.authenticate("", "root", ""), Equals, uint16(0))
+	tc.execute(namespaceSQL, "CREATE USER 'xuser'@'%' IDENTIFIED BY 'xpass'")
+	tc.expectStmtOk()
+	tc.execute(namespaceSQL, "FLUSH PRIVILEGES")
+	tc.expectStmtOk()
+	tc.close()
+
+	tc = s.newClient(c)
+	c.Assert(tc.authenticate("", "xuser", "wrong"), Equals, uint16(mysql.ErrAccessDenied))
+	tc = s.newClient(c)
+	c.Assert(tc.authenticate("", "xuser", "xpass"), Equals, uint16(0))
+	tc.close()
+}
+
+func (s *testServerSuite) TestStmtExecute(c *C) {
+	tc := s.newClient(c)
+	c.Assert(tc.authenticate("test", "root", ""), Equals, uint16(0))
+
+	tc.execute(namespaceSQL, "SELECT 1, -2, ?, NULL, 1.50, -0.5, CAST('2017-01-02 03:04:05' AS DATETIME), CAST('-01:02:03' AS TIME)",
+		scalarAny(stringScalar("x'y")))
+	metas, rows := tc.expectRows()
+	c.Assert(metas, HasLen, 8)
+	c.Assert(metas[0].GetType(), Equals, Mysqlx_Resultset.ColumnMetaData_SINT)
+	c.Assert(metas[2].GetType(), Equals, Mysqlx_Resultset.ColumnMetaData_BYTES)
+	c.Assert(metas[4].GetType(), Equals, Mysqlx_Resultset.ColumnMetaData_DECIMAL)
+	c.Assert(metas[6].GetType(), Equals, Mysqlx_Resultset.ColumnMetaData_DATETIME)
+	c.Assert(metas[7].GetType(), Equals, Mysqlx_Resultset.ColumnMetaData_TIME)
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0], DeepEquals, [][]byte{
+		{0x02}, {0x03}, []byte("x'y\x00"), {}, {0x02, 0x15, 0x0c}, {0x01, 0x05, 0xd0},
+		{0xe1, 0x0f, 1, 2, 3, 4, 5}, {1, 1, 2, 3},
+	})
+
+	tc.execute(namespaceSQL, "CREATE TABLE t (a INT PRIMARY KEY AUTO_INCREMENT, b VARCHAR(10))")
+	tc.expectStmtOk()
+	tc.execute(namespaceSQL, "INSERT INTO t (b) VALUES ('a'), ('b')")
+	c.Assert(tc.expectStmtOk(), Equals, uint64(2))
+	tc.execute(namespaceSQL, "SELECT * FROM nonexistent")
+	tc.expectError(mysql.ErrNoSuchTable)
+
+	// The messages after an error in a no_error Expect block fail.
+	key := uint32(expectNoError)
+	tc.send(Mysqlx.ClientMessages_EXPECT_OPEN, &Mysqlx_Expect.Open{
+		Cond: []*Mysqlx_Expect.Open_Condition{{ConditionKey: &key}},
+	})
+	tc.expect(Mysqlx.ServerMessages_OK, nil)
+	tc.execute(namespaceSQL, "SELECT * FROM nonexistent")
+	tc.expectError(mysql.ErrNoSuchTable)
+	tc.execute(namespaceSQL, "SELECT 1")
+	tc.expectError(uint16(codeXExpectNoErrorFailed))
+	tc.send(Mysqlx.ClientMessages_EXPECT_CLOSE, &Mysqlx_Expect.Close{})
+	tc.expectError(uint16(codeXExpectNoErrorFailed))
+	tc.send(Mysqlx.ClientMessages_EXPECT_CLOSE, &Mysqlx_Expect.Close{})
+	tc.expectError(uint16(codeXExpectNotOpen))
+
+	tc.execute(namespaceXPlugin, "ping")
+	tc.expectStmtOk()
+	tc.execute(namespaceXPlugin, "unknown")
+	tc.expectError(uint16(codeXInvalidAdminCommand))
+	tc.execute("unknown", "ping")
+	tc.expectError(uint16(codeXInvalidNamespace))
+	tc.close()
+}
+
+func (s *testServerSuite) TestCrud(c *C) {
+	tc := s.newClient(c)
+	c.Assert(tc.authenticate("test", "root", ""), Equals, uint16(0))
+	tc.execute(namespaceXPlugin, "create_collection", scalarAny(stringScalar("test")), scalarAny(stringScalar("coll")))
+	tc.expectStmtOk()
+	tc.execute(namespaceXPlugin, "list_objects", scalarAny(stringScalar("test")), scalarAny(stringScalar("co%")))
+	_, rows := tc.expectRows()
+	c.Assert(rows, DeepEquals, [][][]byte{{[]byte("coll\x00"), []byte("COLLECTION\x00")}})
+
+	coll := &Mysqlx_Crud.Collection{Schema: stringPtr("test"), Name: stringPtr("coll")}
+	tc.send(Mysqlx.ClientMessages_CRUD_INSERT, &Mysqlx_Crud.Insert{
+		Collection: coll,
+		Row: []*Mysqlx_Crud.Insert_TypedRow{
+			{Field: []*Mysqlx_Expr.Expr{literal(stringScalar(`{"_id": "1", "name": "a", "age": 1}`))}},
+			{Field: []*Mysqlx_Expr.Expr{literal(stringScalar(`{"name": "b", "age": 2}`))}},
+		},
+	})
+	c.Assert(tc.expectStmtOk(), Equals, uint64(2))
+
+	tc.send(Mysqlx.ClientMessages_CRUD_FIND, &Mysqlx_Crud.Find{
+		Collection: coll,
+		Criteria:   operator(">", docPath("age"), placeholder(0)),
+		Args:       []*Mysqlx_Datatypes.Scalar{intScalar(1)},
+		Projection: []*Mysqlx_Crud.Projection{{Source: docPath("name")}},
+	})
+	metas, rows := tc.expectRows()
+	c.Assert(metas, HasLen, 1)
+	c.Assert(metas[0].GetContentType(), Equals, uint32(contentTypeJSON))
+	c.Assert(rows, DeepEquals, [][][]byte{{[]byte(`{"name":"b"}` + "\x00")}})
+
+	// The generated _id is a UUID without dashes.
+	tc.execute(namespaceSQL, "SELECT LENGTH(_id) FROM coll WHERE doc->'$.name' = 'b'")
+	_, rows = tc.expectRows()
+	c.Assert(rows, DeepEquals, [][][]byte{{{64}}})
+
+	itemSet := Mysqlx_Crud.UpdateOperation_ITEM_SET
+	tc.send(Mysqlx.ClientMessages_CRUD_UPDATE, &Mysqlx_Crud.Update{
+		Collection: coll,
+		Criteria:   operator("==", docPath("_id"), literal(stringScalar("1"))),
+		Operation: []*Mysqlx_Crud.UpdateOperation{
+			{Source: docPath("age").Identifier, Operation: &itemSet, Value: literal(intScalar(10))},
+		},
+	})
+	c.Assert(tc.expectStmtOk(), Equals, uint64(1))
+	tc.send(Mysqlx.ClientMessages_CRUD_UPDATE, &Mysqlx_Crud.Update{
+		Collection: coll,
+		Operation: []*Mysqlx_Crud.UpdateOperation{
+			{Source: docPath("_id").Identifier, Operation: &itemSet, Value: literal(intScalar(10))},
+		},
+	})
+	tc.expectError(uint16(codeXBadMemberToUpdate))
+
+	tc.send(Mysqlx.ClientMessages_CRUD_FIND, &Mysqlx_Crud.Find{
+		Collection: coll,
+		Criteria:   operator("==", docPath("_id"), literal(stringScalar("1"))),
+	})
+	_, rows = tc.expectRows()
+	c.Assert(rows, DeepEquals, [][][]byte{{[]byte(`{"_id":"1","age":10,"name":"a"}` + "\x00")}})
+
+	tc.send(Mysqlx.ClientMessages_CRUD_DELETE, &Mysqlx_Crud.Delete{
+		Collection: coll,
+		Criteria:   operator("==", docPath("name"), literal(stringScalar("a"))),
+	})
+	c.Assert(tc.expectStmtOk(), Equals, uint64(1))
+
+	tc.execute(namespaceXPlugin, "drop_collection", scalarAny(stringScalar("test")), scalarAny(stringScalar("coll")))
+	tc.expectStmtOk()
+	tc.close()
+}
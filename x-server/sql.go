@@ -0,0 +1,286 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Notice"
+	"github.com/pingcap/tipb/go-mysqlx/Sql"
+)
+
+// The namespaces of StmtExecute, the admin commands are executed in the xplugin and mysqlx namespaces.
+const (
+	namespaceSQL     = "sql"
+	namespaceXPlugin = "xplugin"
+	namespaceMysqlx  = "mysqlx"
+)
+
+// createCollectionSQL creates a collection table, the documents are stored in the doc column
+// and the _id member of the documents is the primary key.
+const createCollectionSQL = "CREATE TABLE %s%s (doc JSON, " +
+	"_id VARCHAR(32) GENERATED ALWAYS AS (JSON_UNQUOTE(JSON_EXTRACT(doc, '$._id'))) STORED NOT NULL, " +
+	"PRIMARY KEY (_id)) CHARSET utf8mb4"
+
+// listObjectsSQL lists the tables of a schema, the tables made up of the doc and _id columns are collections.
+const listObjectsSQL = "SELECT T.TABLE_NAME AS name, " +
+	"IF(SUM(C.COLUMN_NAME = 'doc' AND C.DATA_TYPE = 'json') = 1 AND SUM(C.COLUMN_NAME = '_id') = 1 AND COUNT(C.COLUMN_NAME) = 2, " +
+	"'COLLECTION', IF(T.TABLE_TYPE = 'VIEW', 'VIEW', 'TABLE')) AS type " +
+	"FROM information_schema.TABLES AS T LEFT JOIN information_schema.COLUMNS AS C " +
+	"ON T.TABLE_SCHEMA = C.TABLE_SCHEMA AND T.TABLE_NAME = C.TABLE_NAME " +
+	"WHERE T.TABLE_SCHEMA = %s%s GROUP BY T.TABLE_NAME, T.TABLE_TYPE ORDER BY T.TABLE_NAME"
+
+func (cc *clientConn) handleStmtExecute(payload []byte) error {
+	var msg Mysqlx_Sql.StmtExecute
+	if err := msg.Unmarshal(payload); err != nil {
+		return errXBadMessage
+	}
+	switch msg.GetNamespace() {
+	case namespaceSQL:
+		sql, err := bindArgs(string(msg.GetStmt()), msg.GetArgs())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(cc.executeSQL(sql))
+	case namespaceXPlugin, namespaceMysqlx:
+		return errors.Trace(cc.executeAdminCommand(msg.GetNamespace(), string(msg.GetStmt()), msg.GetArgs()))
+	}
+	return errXInvalidNamespace.GenByArgs(msg.GetNamespace())
+}
+
+// executeSQL executes the statements and sends the result sets followed by the notices and StmtExecuteOk.
+func (cc *clientConn) executeSQL(sql string) error {
+	rss, err := cc.ctx.Execute(sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = cc.writeResultSets(rss); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.writeStmtExecuteOk(len(rss) == 0))
+}
+
+// writeStmtExecuteOk sends the warnings, the affected rows and the last insert id of the statement
+// if it doesn't return result sets, and StmtExecuteOk.
+func (cc *clientConn) writeStmtExecuteOk(sendAffectedRows bool) error {
+	affectedRows, lastInsertID := cc.ctx.AffectedRows(), cc.ctx.LastInsertID()
+	if err := cc.writeWarnings(); err != nil {
+		return errors.Trace(err)
+	}
+	if sendAffectedRows {
+		if err := cc.writeSessionStateChanged(Mysqlx_Notice.SessionStateChanged_ROWS_AFFECTED, uintScalar(affectedRows)); err != nil {
+			return errors.Trace(err)
+		}
+		if lastInsertID > 0 {
+			if err := cc.writeSessionStateChanged(Mysqlx_Notice.SessionStateChanged_GENERATED_INSERT_ID, uintScalar(lastInsertID)); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return errors.Trace(cc.writePacket(Mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK, &Mysqlx_Sql.StmtExecuteOk{}))
+}
+
+// bindArgs replaces the placeholders out of the quoted strings and identifiers with the literals of the arguments.
+func bindArgs(sql string, args []*Mysqlx_Datatypes.Any) (string, error) {
+	if len(args) == 0 {
+		return sql, nil
+	}
+	var (
+		buf   bytes.Buffer
+		quote byte
+		pos   int
+	)
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' && i+1 < len(sql) {
+				buf.WriteByte(c)
+				i++
+				c = sql[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			if pos >= len(args) {
+				return "", errXCmdNumArguments.GenByArgs(len(args), pos+1)
+			}
+			if args[pos].GetType() != Mysqlx_Datatypes.Any_SCALAR {
+				return "", errXInvalidArgument.GenByArgs("only scalar arguments are allowed")
+			}
+			lit, err := scalarLiteral(args[pos].GetScalar())
+			if err != nil {
+				return "", errors.Trace(err)
+			}
+			buf.WriteString(lit)
+			pos++
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	if pos != len(args) {
+		return "", errXCmdNumArguments.GenByArgs(len(args), pos)
+	}
+	return buf.String(), nil
+}
+
+// adminArgs gets the arguments of the admin commands, they are the positional scalars or the fields of an object.
+type adminArgs struct {
+	command string
+	args    []*Mysqlx_Datatypes.Any
+}
+
+// str returns the string argument at the position or of the name, optional arguments default to "".
+func (a *adminArgs) str(pos int, name string, optional bool) (string, error) {
+	var v *Mysqlx_Datatypes.Any
+	if len(a.args) == 1 && a.args[0].GetType() == Mysqlx_Datatypes.Any_OBJECT {
+		for _, fld := range a.args[0].GetObj().GetFld() {
+			if fld.GetKey() == name {
+				v = fld.GetValue()
+			}
+		}
+	} else if pos < len(a.args) {
+		v = a.args[pos]
+	}
+	if v == nil {
+		if optional {
+			return "", nil
+		}
+		return "", errXCmdNumArguments.GenByArgs(pos+1, len(a.args))
+	}
+	s := v.GetScalar()
+	switch {
+	case v.GetType() == Mysqlx_Datatypes.Any_SCALAR && s.GetType() == Mysqlx_Datatypes.Scalar_V_STRING:
+		return string(s.GetVString().GetValue()), nil
+	case v.GetType() == Mysqlx_Datatypes.Any_SCALAR && s.GetType() == Mysqlx_Datatypes.Scalar_V_OCTETS:
+		return string(s.GetVOctets().GetValue()), nil
+	case optional && v.GetType() == Mysqlx_Datatypes.Any_SCALAR && s.GetType() == Mysqlx_Datatypes.Scalar_V_NULL:
+		return "", nil
+	}
+	return "", errXCmdArgumentType.GenByArgs(name, a.command)
+}
+
+// strs returns all the string arguments.
+func (a *adminArgs) strs() ([]string, error) {
+	values := a.args
+	if len(values) == 1 && values[0].GetType() == Mysqlx_Datatypes.Any_ARRAY {
+		values = values[0].GetArray().GetValue()
+	}
+	strs := make([]string, 0, len(values))
+	for i := range values {
+		s, err := (&adminArgs{command: a.command, args: values}).str(i, "notice", false)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+// executeAdminCommand executes the commands of the X Plugin.
+// See https://dev.mysql.com/doc/internals/en/x-protocol-stmtexecute-admin.html
+func (cc *clientConn) executeAdminCommand(namespace, command string, args []*Mysqlx_Datatypes.Any) error {
+	a := &adminArgs{command: command, args: args}
+	switch command {
+	case "ping":
+		return errors.Trace(cc.writeStmtExecuteOk(false))
+	case "create_collection", "ensure_collection", "drop_collection":
+		schema, err := a.str(0, "schema", false)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		name, err := a.str(1, "name", false)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if name == "" {
+			return errXInvalidCollection
+		}
+		table := quoteIdentifier(name)
+		if schema != "" {
+			table = quoteIdentifier(schema) + "." + table
+		}
+		var sql string
+		switch command {
+		case "create_collection":
+			sql = fmt.Sprintf(createCollectionSQL, "", table)
+		case "ensure_collection":
+			sql = fmt.Sprintf(createCollectionSQL, "IF NOT EXISTS ", table)
+		default:
+			sql = "DROP TABLE " + table
+		}
+		if _, err = cc.ctx.Execute(sql); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(cc.writeStmtExecuteOk(true))
+	case "list_objects":
+		schema, err := a.str(0, "schema", true)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		pattern, err := a.str(1, "pattern", true)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		schemaExpr := "DATABASE()"
+		if schema != "" {
+			schemaExpr = quoteString(schema)
+		}
+		var cond string
+		if pattern != "" {
+			cond = " AND T.TABLE_NAME LIKE " + quoteString(pattern)
+		}
+		return errors.Trace(cc.executeSQL(fmt.Sprintf(listObjectsSQL, schemaExpr, cond)))
+	case "enable_notices", "disable_notices":
+		notices, err := a.strs()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, notice := range notices {
+			if notice == noticeWarnings {
+				cc.sendWarnings = command == "enable_notices"
+			} else if !isFixedNotice(notice) {
+				return errXBadNotice.GenByArgs(notice)
+			}
+		}
+		return errors.Trace(cc.writeStmtExecuteOk(false))
+	case "list_notices":
+		enabled := 0
+		if cc.sendWarnings {
+			enabled = 1
+		}
+		sqls := []string{fmt.Sprintf("SELECT '%s' AS notice, %d AS enabled", noticeWarnings, enabled)}
+		for _, notice := range fixedNotices {
+			sqls = append(sqls, fmt.Sprintf("SELECT '%s', 1", notice))
+		}
+		return errors.Trace(cc.executeSQL(strings.Join(sqls, " UNION ALL ")))
+	}
+	return errXInvalidAdminCommand.GenByArgs(namespace, command)
+}
+
+func isFixedNotice(notice string) bool {
+	for _, n := range fixedNotices {
+		if n == notice {
+			return true
+		}
+	}
+	return false
+}